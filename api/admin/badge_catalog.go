@@ -0,0 +1,666 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/shared"
+	"github.com/aiw3/nft-solana-api/tasks"
+	"github.com/aiw3/nft-solana-api/tiers"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+// ==========================================
+// ADMIN BADGE CATALOG HANDLERS
+// ==========================================
+
+// ListCatalogBadges returns the badge catalog with tasks, versions and retired badges (admin)
+func ListCatalogBadges(store *repository.Store) usecase.Interactor {
+	type listCatalogBadgesRequest struct {
+		Authorization  string `header:"Authorization" description:"Bearer token for admin authentication"`
+		NftLevel       int    `query:"nftLevel" description:"Filter by NFT level"`
+		IncludeRetired bool   `query:"includeRetired" description:"Include retired badges"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req listCatalogBadgesRequest, resp *ListCatalogBadgesResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = ListCatalogBadgesResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    ListCatalogBadgesData{Badges: []CatalogBadge{}},
+			}
+			return nil
+		}
+
+		badges, err := loadCatalogBadges(ctx, store, func(def repository.BadgeDefinition) bool {
+			return (req.NftLevel == 0 || def.NftLevel == req.NftLevel) && (req.IncludeRetired || def.RetiredAt == nil)
+		})
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		*resp = ListCatalogBadgesResponse{
+			Code:    200,
+			Message: "Success",
+			Data: ListCatalogBadgesData{
+				Badges:     badges,
+				TotalCount: len(badges),
+			},
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("List Badge Catalog")
+	u.SetDescription("Admin endpoint listing badge definitions with their tasks, requirement rules, order and versions")
+	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// CreateCatalogBadge adds a badge and the task that awards it (admin)
+func CreateCatalogBadge(store *repository.Store, verifiers *tasks.Registry) usecase.Interactor {
+	type createCatalogBadgeRequest struct {
+		Authorization         string               `header:"Authorization" description:"Bearer token for admin authentication"`
+		NftLevel              int                  `json:"nftLevel" required:"true" minimum:"1" description:"NFT level the badge counts towards"`
+		Name                  string               `json:"name" required:"true" description:"Badge name, unique among active badges"`
+		Description           string               `json:"description" description:"Badge description"`
+		Category              string               `json:"category" description:"Badge category"`
+		Level                 *int                 `json:"level" description:"Badge level/tier (defaults to nftLevel)"`
+		IconURL               string               `json:"iconUrl" description:"Badge icon URL"`
+		ContributionValue     *float64             `json:"contributionValue" description:"Points toward NFT upgrades (defaults to 1)"`
+		ActivationWindowHours *int                 `json:"activationWindowHours" minimum:"0" description:"Hours an activation counts towards upgrades; 0 never expires (defaults to the NFT level window)"`
+		Requirements          []CatalogRequirement `json:"requirements" required:"true" description:"Requirement rules; all must be met"`
+		TaskName              string               `json:"taskName" required:"true" description:"Name of the task awarding the badge"`
+		TaskType              string               `json:"taskType" description:"Task type (defaults to the first requirement type)"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req createCatalogBadgeRequest, resp *CatalogBadgeResponse) error {
+		admin, err := extractAdminFromAuthHeader(req.Authorization)
+		if err != nil {
+			*resp = CatalogBadgeResponse{
+				Code:    401,
+				Message: err.Error(),
+			}
+			return nil
+		}
+
+		def := repository.BadgeDefinition{
+			NftLevel:              req.NftLevel,
+			Name:                  strings.TrimSpace(req.Name),
+			Description:           req.Description,
+			Category:              req.Category,
+			Level:                 req.NftLevel,
+			IconURL:               req.IconURL,
+			ContributionValue:     1,
+			Requirements:          fromCatalogRequirements(req.Requirements),
+			Version:               1,
+			ActivationWindowHours: req.ActivationWindowHours,
+		}
+		if req.Level != nil {
+			def.Level = *req.Level
+		}
+		if req.ContributionValue != nil {
+			def.ContributionValue = *req.ContributionValue
+		}
+		task := repository.Task{Name: strings.TrimSpace(req.TaskName), Type: req.TaskType}
+		if task.Type == "" && len(def.Requirements) > 0 {
+			task.Type = def.Requirements[0].Type
+		}
+
+		code, message, err := validateCatalogBadge(ctx, store, verifiers, &def, &task)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if code != 0 {
+			*resp = CatalogBadgeResponse{
+				Code:    code,
+				Message: message,
+			}
+			return nil
+		}
+
+		if def.SortOrder, err = nextSortOrder(ctx, store, def.NftLevel); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		// The badge and its task reference each other: save the badge, then the task, then link them
+		if err := store.Badges.SaveDefinition(ctx, &def); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		task.BadgeID = def.ID
+		if err := store.Tasks.SaveTask(ctx, &task); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		def.TaskID = task.ID
+		if err := store.Badges.SaveDefinition(ctx, &def); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		*resp = CatalogBadgeResponse{
+			Code:    200,
+			Message: fmt.Sprintf("Badge %d created with task %d by admin %s", def.ID, task.ID, admin.Username),
+			Data:    toCatalogBadge(def, &task),
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Create Catalog Badge")
+	u.SetDescription("Admin endpoint to add a badge definition together with the task that awards it")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// UpdateCatalogBadge changes a badge and its task as a new definition version (admin)
+func UpdateCatalogBadge(store *repository.Store, verifiers *tasks.Registry) usecase.Interactor {
+	type updateCatalogBadgeRequest struct {
+		Authorization         string               `header:"Authorization" description:"Bearer token for admin authentication"`
+		ID                    int                  `path:"id" required:"true" description:"Badge ID to update"`
+		NftLevel              *int                 `json:"nftLevel" minimum:"1" description:"NFT level; moving a badge puts it last in the new level"`
+		Name                  *string              `json:"name" description:"Badge name"`
+		Description           *string              `json:"description" description:"Badge description"`
+		Category              *string              `json:"category" description:"Badge category"`
+		Level                 *int                 `json:"level" description:"Badge level/tier"`
+		IconURL               *string              `json:"iconUrl" description:"Badge icon URL"`
+		ContributionValue     *float64             `json:"contributionValue" description:"Points toward NFT upgrades"`
+		ActivationWindowHours *int                 `json:"activationWindowHours" description:"Hours an activation counts towards upgrades; 0 never expires, negative falls back to the NFT level window"`
+		Requirements          []CatalogRequirement `json:"requirements" description:"Replacement requirement rules"`
+		TaskName              *string              `json:"taskName" description:"Name of the task awarding the badge"`
+		TaskType              *string              `json:"taskType" description:"Task type"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req updateCatalogBadgeRequest, resp *CatalogBadgeResponse) error {
+		admin, err := extractAdminFromAuthHeader(req.Authorization)
+		if err != nil {
+			*resp = CatalogBadgeResponse{
+				Code:    401,
+				Message: err.Error(),
+			}
+			return nil
+		}
+
+		current, task, code, message, err := loadCatalogEntry(ctx, store, req.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if code == 0 && current.RetiredAt != nil {
+			code, message = 409, fmt.Sprintf("Badge %d is retired and cannot be changed", req.ID)
+		}
+		if code != 0 {
+			*resp = CatalogBadgeResponse{
+				Code:    code,
+				Message: message,
+			}
+			return nil
+		}
+
+		def := *current
+		def.Requirements = append([]repository.Requirement(nil), current.Requirements...)
+		updated := *task
+		if req.NftLevel != nil {
+			def.NftLevel = *req.NftLevel
+		}
+		if req.Name != nil {
+			def.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Description != nil {
+			def.Description = *req.Description
+		}
+		if req.Category != nil {
+			def.Category = *req.Category
+		}
+		if req.Level != nil {
+			def.Level = *req.Level
+		}
+		if req.IconURL != nil {
+			def.IconURL = *req.IconURL
+		}
+		if req.ContributionValue != nil {
+			def.ContributionValue = *req.ContributionValue
+		}
+		if req.ActivationWindowHours != nil {
+			def.ActivationWindowHours = req.ActivationWindowHours
+			if *req.ActivationWindowHours < 0 {
+				def.ActivationWindowHours = nil
+			}
+		}
+		if req.Requirements != nil {
+			def.Requirements = fromCatalogRequirements(req.Requirements)
+		}
+		if req.TaskName != nil {
+			updated.Name = strings.TrimSpace(*req.TaskName)
+		}
+		if req.TaskType != nil {
+			updated.Type = *req.TaskType
+		}
+
+		if reflect.DeepEqual(def, *current) && updated == *task {
+			*resp = CatalogBadgeResponse{
+				Code:    200,
+				Message: fmt.Sprintf("Badge %d is unchanged at version %d", def.ID, def.Version),
+				Data:    toCatalogBadge(def, task),
+			}
+			return nil
+		}
+
+		code, message, err = validateCatalogBadge(ctx, store, verifiers, &def, &updated)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if code != 0 {
+			*resp = CatalogBadgeResponse{
+				Code:    code,
+				Message: message,
+			}
+			return nil
+		}
+
+		if def.NftLevel != current.NftLevel {
+			if def.SortOrder, err = nextSortOrder(ctx, store, def.NftLevel); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+		}
+		// Holders keep the version they earned; the catalog moves to the next one
+		def.Version = current.Version + 1
+		if updated != *task {
+			if err := store.Tasks.SaveTask(ctx, &updated); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			def.TaskID = updated.ID
+		}
+		if err := store.Badges.SaveDefinition(ctx, &def); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		*resp = CatalogBadgeResponse{
+			Code:    200,
+			Message: fmt.Sprintf("Badge %d updated to version %d by admin %s", def.ID, def.Version, admin.Username),
+			Data:    toCatalogBadge(def, &updated),
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Update Catalog Badge")
+	u.SetDescription("Admin endpoint to change a badge definition and its task; every change creates a new version " +
+		"and users who already earned the badge keep the version they earned it under")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// RetireCatalogBadge stops a badge from being earned; holders keep it (admin)
+func RetireCatalogBadge(store *repository.Store) usecase.Interactor {
+	type retireCatalogBadgeRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		ID            int    `path:"id" required:"true" description:"Badge ID to retire"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req retireCatalogBadgeRequest, resp *CatalogBadgeResponse) error {
+		admin, err := extractAdminFromAuthHeader(req.Authorization)
+		if err != nil {
+			*resp = CatalogBadgeResponse{
+				Code:    401,
+				Message: err.Error(),
+			}
+			return nil
+		}
+
+		def, task, code, message, err := loadCatalogEntry(ctx, store, req.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if code == 0 && def.RetiredAt != nil {
+			code, message = 409, fmt.Sprintf("Badge %d is already retired", req.ID)
+		}
+		if code != 0 {
+			*resp = CatalogBadgeResponse{
+				Code:    code,
+				Message: message,
+			}
+			return nil
+		}
+
+		now := time.Now().UTC()
+		def.RetiredAt = &now
+		if err := store.Badges.SaveDefinition(ctx, def); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		*resp = CatalogBadgeResponse{
+			Code:    200,
+			Message: fmt.Sprintf("Badge %d retired by admin %s", def.ID, admin.Username),
+			Data:    toCatalogBadge(*def, task),
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Retire Catalog Badge")
+	u.SetDescription("Admin endpoint to retire a badge: its task can no longer be completed and the badge leaves the catalog, " +
+		"while users who hold it keep it")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// ReorderCatalogBadges sets the display order of the active badges of an NFT level (admin)
+func ReorderCatalogBadges(store *repository.Store) usecase.Interactor {
+	type reorderCatalogBadgesRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		NftLevel      int    `json:"nftLevel" required:"true" minimum:"1" description:"NFT level to reorder"`
+		BadgeIDs      []int  `json:"badgeIds" required:"true" description:"Every active badge ID of the level, in the new order"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req reorderCatalogBadgesRequest, resp *ReorderCatalogBadgesResponse) error {
+		admin, err := extractAdminFromAuthHeader(req.Authorization)
+		if err != nil {
+			*resp = ReorderCatalogBadgesResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    ListCatalogBadgesData{Badges: []CatalogBadge{}},
+			}
+			return nil
+		}
+
+		defs, err := store.Badges.ListDefinitions(ctx)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		active := map[int]*repository.BadgeDefinition{}
+		retired := []*repository.BadgeDefinition{}
+		for i := range defs {
+			if defs[i].NftLevel != req.NftLevel {
+				continue
+			}
+			if defs[i].RetiredAt != nil {
+				retired = append(retired, &defs[i])
+			} else {
+				active[defs[i].ID] = &defs[i]
+			}
+		}
+
+		seen := map[int]bool{}
+		for _, id := range req.BadgeIDs {
+			if active[id] == nil || seen[id] {
+				break
+			}
+			seen[id] = true
+		}
+		if len(seen) != len(req.BadgeIDs) || len(seen) != len(active) {
+			*resp = ReorderCatalogBadgesResponse{
+				Code: 400,
+				Message: fmt.Sprintf("badgeIds must list each of the %d active level %d badges exactly once",
+					len(active), req.NftLevel),
+				Data: ListCatalogBadgesData{Badges: []CatalogBadge{}},
+			}
+			return nil
+		}
+
+		// Ordering is presentation only and does not create a new version; retired badges go last
+		ordered := make([]*repository.BadgeDefinition, 0, len(active)+len(retired))
+		for _, id := range req.BadgeIDs {
+			ordered = append(ordered, active[id])
+		}
+		for i, def := range append(ordered, retired...) {
+			if def.SortOrder == i+1 {
+				continue
+			}
+			def.SortOrder = i + 1
+			if err := store.Badges.SaveDefinition(ctx, def); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+		}
+
+		badges, err := loadCatalogBadges(ctx, store, func(def repository.BadgeDefinition) bool {
+			return def.NftLevel == req.NftLevel && def.RetiredAt == nil
+		})
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = ReorderCatalogBadgesResponse{
+			Code:    200,
+			Message: fmt.Sprintf("Level %d badges reordered by admin %s", req.NftLevel, admin.Username),
+			Data: ListCatalogBadgesData{
+				Badges:     badges,
+				TotalCount: len(badges),
+			},
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Reorder Catalog Badges")
+	u.SetDescription("Admin endpoint to set the display order of the active badges of an NFT level")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// ListCatalogBadgeVersions returns every version of a badge and how many users hold each (admin)
+func ListCatalogBadgeVersions(store *repository.Store) usecase.Interactor {
+	type listCatalogBadgeVersionsRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		ID            int    `path:"id" required:"true" description:"Badge ID"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req listCatalogBadgeVersionsRequest, resp *ListCatalogBadgeVersionsResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = ListCatalogBadgeVersionsResponse{
+				Code:    401,
+				Message: err.Error(),
+			}
+			return nil
+		}
+
+		def, task, code, message, err := loadCatalogEntry(ctx, store, req.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if code != 0 {
+			*resp = ListCatalogBadgeVersionsResponse{
+				Code:    code,
+				Message: message,
+			}
+			return nil
+		}
+
+		versions, err := store.Badges.ListDefinitionVersions(ctx, def.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		holders, err := store.Badges.ListHolders(ctx, def.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		data := ListCatalogBadgeVersionsData{
+			BadgeID:          def.ID,
+			CurrentVersion:   def.Version,
+			Versions:         make([]CatalogBadge, 0, len(versions)),
+			HoldersByVersion: map[string]int{},
+		}
+		for _, version := range versions {
+			data.Versions = append(data.Versions, toCatalogBadge(version, task))
+		}
+		for _, holder := range holders {
+			version := holder.DefinitionVersion
+			if version == 0 {
+				version = 1
+			}
+			data.HoldersByVersion[strconv.Itoa(version)]++
+		}
+
+		*resp = ListCatalogBadgeVersionsResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    data,
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("List Catalog Badge Versions")
+	u.SetDescription("Admin endpoint returning the version history of a badge definition and its holders per version")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// ==========================================
+// HELPER FUNCTIONS FOR BADGE CATALOG
+// ==========================================
+
+// loadCatalogEntry returns a badge and its task, or a 404 code and message when the badge does not exist
+func loadCatalogEntry(ctx context.Context, store *repository.Store, id int) (*repository.BadgeDefinition, *repository.Task, int, string, error) {
+	def, err := store.Badges.GetDefinition(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, 404, fmt.Sprintf("Badge %d not found", id), nil
+	}
+	if err != nil {
+		return nil, nil, 0, "", err
+	}
+	task, err := store.Tasks.GetTask(ctx, def.TaskID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return def, &repository.Task{BadgeID: def.ID}, 0, "", nil
+	}
+	if err != nil {
+		return nil, nil, 0, "", err
+	}
+	return def, task, 0, "", nil
+}
+
+// loadCatalogBadges returns the badges accepted by keep, in catalog order
+func loadCatalogBadges(ctx context.Context, store *repository.Store, keep func(repository.BadgeDefinition) bool) ([]CatalogBadge, error) {
+	defs, err := store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	taskList, err := store.Tasks.ListTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasksByID := make(map[int]*repository.Task, len(taskList))
+	for i := range taskList {
+		tasksByID[taskList[i].ID] = &taskList[i]
+	}
+
+	badges := []CatalogBadge{}
+	for _, def := range defs {
+		if keep(def) {
+			badges = append(badges, toCatalogBadge(def, tasksByID[def.TaskID]))
+		}
+	}
+	return badges, nil
+}
+
+// validateCatalogBadge checks a badge and its task before they are saved. It returns a response code and
+// message when they are invalid (400) or the name is taken by another active badge (409), 0 otherwise.
+func validateCatalogBadge(ctx context.Context, store *repository.Store, verifiers *tasks.Registry,
+	def *repository.BadgeDefinition, task *repository.Task) (int, string, error) {
+	switch {
+	case def.Name == "" || len(def.Name) > 100:
+		return 400, "Badge name must be 1 to 100 characters", nil
+	case !tiers.Current().Valid(def.NftLevel):
+		return 400, fmt.Sprintf("NFT level %d is not in the tier catalog", def.NftLevel), nil
+	case def.Level < 1:
+		return 400, "Badge level must be at least 1", nil
+	case def.ContributionValue < 0:
+		return 400, "Contribution value must not be negative", nil
+	case def.ActivationWindowHours != nil && *def.ActivationWindowHours < 0:
+		return 400, "Activation window must not be negative", nil
+	case len(def.Requirements) == 0:
+		return 400, "A badge needs at least one requirement", nil
+	case task.Name == "" || len(task.Name) > 100:
+		return 400, "Task name must be 1 to 100 characters", nil
+	}
+
+	taskTypeListed := false
+	for _, requirement := range def.Requirements {
+		if _, ok := verifiers.Lookup(requirement.Type); !ok {
+			return 400, fmt.Sprintf("Requirement type %q has no verifier; known types: %s",
+				requirement.Type, strings.Join(verifiers.Types(), ", ")), nil
+		}
+		if requirement.Value < 1 {
+			return 400, fmt.Sprintf("Requirement %s needs a value of at least 1", requirement.Type), nil
+		}
+		taskTypeListed = taskTypeListed || requirement.Type == task.Type
+	}
+	if !taskTypeListed {
+		return 400, fmt.Sprintf("Task type %q must be one of the badge's requirement types", task.Type), nil
+	}
+
+	defs, err := store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	for _, other := range defs {
+		if other.ID != def.ID && other.RetiredAt == nil && strings.EqualFold(other.Name, def.Name) {
+			return 409, fmt.Sprintf("Badge %d is already named %q", other.ID, other.Name), nil
+		}
+	}
+	return 0, "", nil
+}
+
+// nextSortOrder returns the position after the last badge of an NFT level
+func nextSortOrder(ctx context.Context, store *repository.Store, nftLevel int) (int, error) {
+	defs, err := store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	last := 0
+	for _, def := range defs {
+		if def.NftLevel == nftLevel && def.SortOrder > last {
+			last = def.SortOrder
+		}
+	}
+	return last + 1, nil
+}
+
+// fromCatalogRequirements converts API requirement rules to stored requirements
+func fromCatalogRequirements(requirements []CatalogRequirement) []repository.Requirement {
+	result := make([]repository.Requirement, 0, len(requirements))
+	for _, requirement := range requirements {
+		result = append(result, repository.Requirement{Type: requirement.Type, Value: requirement.Value})
+	}
+	return result
+}
+
+// toCatalogBadge converts a stored definition and its task to the admin representation
+func toCatalogBadge(def repository.BadgeDefinition, task *repository.Task) CatalogBadge {
+	requirements := make([]CatalogRequirement, 0, len(def.Requirements))
+	for _, requirement := range def.Requirements {
+		requirements = append(requirements, CatalogRequirement{Type: requirement.Type, Value: requirement.Value})
+	}
+
+	badge := CatalogBadge{
+		ID:                    def.ID,
+		NftLevel:              def.NftLevel,
+		Name:                  def.Name,
+		Description:           def.Description,
+		Category:              def.Category,
+		Level:                 def.Level,
+		IconURL:               def.IconURL,
+		ContributionValue:     def.ContributionValue,
+		ActivationWindowHours: def.ActivationWindowHours,
+		Requirements:          requirements,
+		Task:                  CatalogTask{ID: def.TaskID},
+		SortOrder:             def.SortOrder,
+		Version:               def.Version,
+		Retired:               def.RetiredAt != nil,
+		RetiredAt:             shared.FormatTimestampPtr(def.RetiredAt),
+	}
+	if task != nil {
+		badge.Task.Name = task.Name
+		badge.Task.Type = task.Type
+	}
+	return badge
+}
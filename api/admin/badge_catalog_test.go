@@ -0,0 +1,250 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/antigaming"
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/badges"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+	"github.com/aiw3/nft-solana-api/repository/sqlite"
+	"github.com/aiw3/nft-solana-api/tasks"
+	"github.com/swaggest/openapi-go/openapi3"
+	"github.com/swaggest/rest/web"
+)
+
+// testStores returns an empty memory store and an empty SQLite store
+func testStores(t *testing.T) map[string]*repository.Store {
+	t.Helper()
+	db, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "aiw3.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]*repository.Store{"memory": memory.NewStore(), "sqlite": sqlite.NewStore(db)}
+}
+
+// catalogService serves the badge catalog endpoints over the store
+func catalogService(store *repository.Store) http.Handler {
+	verifiers := tasks.DefaultRegistry()
+	s := web.NewService(openapi3.NewReflector())
+	s.Get("/api/admin/badges", ListCatalogBadges(store))
+	s.Post("/api/admin/badges", CreateCatalogBadge(store, verifiers))
+	s.Put("/api/admin/badges/{id}", UpdateCatalogBadge(store, verifiers))
+	s.Post("/api/admin/badges/{id}/retire", RetireCatalogBadge(store))
+	s.Get("/api/admin/badges/{id}/versions", ListCatalogBadgeVersions(store))
+	return s
+}
+
+// call sends an admin request and decodes the response envelope into resp
+func call(t *testing.T, handler http.Handler, method, path, body string, resp interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer admin_token_123")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if err := json.Unmarshal(rec.Body.Bytes(), resp); err != nil {
+		t.Fatalf("%s %s: decode response %q: %v", method, path, rec.Body.String(), err)
+	}
+}
+
+// createBadge creates a Level 2 badge awarded by a task of the same name for inviting value friends
+func createBadge(t *testing.T, handler http.Handler, name string, value int) CatalogBadge {
+	t.Helper()
+	var resp CatalogBadgeResponse
+	call(t, handler, http.MethodPost, "/api/admin/badges", fmt.Sprintf(`{"nftLevel":2,"name":%q,"taskName":%q,
+		"requirements":[{"type":%q,"value":%d}]}`, name, name, tasks.TypeInviteFriend, value), &resp)
+	if resp.Code != 200 {
+		t.Fatalf("create %s: code %d (%s), want 200", name, resp.Code, resp.Message)
+	}
+	return resp.Data
+}
+
+// holder stores a user who earned the badge under its current version
+func holder(t *testing.T, store *repository.Store, badgeID int) *repository.User {
+	t.Helper()
+	ctx := context.Background()
+	user := &repository.User{Username: "holder", WalletAddr: "holderWallet"}
+	if err := store.Users.Save(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	def, err := store.Badges.GetDefinition(ctx, badgeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Badges.SaveUserBadge(ctx, badgelifecycle.Earn(user.ID, def, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestEditCreatesNewVersion(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			handler := catalogService(store)
+			badge := createBadge(t, handler, "Invite 2 Friends", 2)
+			if badge.Version != 1 || badge.SortOrder != 1 || badge.Task.ID == 0 {
+				t.Fatalf("created %+v, want version 1 first in its level with a task", badge)
+			}
+			user := holder(t, store, badge.ID)
+
+			var resp CatalogBadgeResponse
+			path := fmt.Sprintf("/api/admin/badges/%d", badge.ID)
+			call(t, handler, http.MethodPut, path,
+				`{"name":"Invite 3 Friends","requirements":[{"type":"invite_friend","value":3}]}`, &resp)
+			if resp.Code != 200 || resp.Data.Version != 2 || resp.Data.Name != "Invite 3 Friends" {
+				t.Fatalf("update: code %d (%s), version %d; want 200 and version 2", resp.Code, resp.Message, resp.Data.Version)
+			}
+			// Sending the current values again changes nothing
+			call(t, handler, http.MethodPut, path, `{"name":"Invite 3 Friends"}`, &resp)
+			if resp.Code != 200 || resp.Data.Version != 2 {
+				t.Errorf("unchanged update: code %d, version %d; want 200 and version 2", resp.Code, resp.Data.Version)
+			}
+
+			var versions ListCatalogBadgeVersionsResponse
+			call(t, handler, http.MethodGet, path+"/versions", "", &versions)
+			if versions.Code != 200 || versions.Data.CurrentVersion != 2 || len(versions.Data.Versions) != 2 {
+				t.Fatalf("versions: code %d, current %d, %d versions; want 200, 2 and 2", versions.Code,
+					versions.Data.CurrentVersion, len(versions.Data.Versions))
+			}
+			if first := versions.Data.Versions[0]; first.Version != 1 || first.Name != "Invite 2 Friends" ||
+				first.Requirements[0].Value != 2 {
+				t.Errorf("first version %+v, want the badge as created", first)
+			}
+			if versions.Data.HoldersByVersion["1"] != 1 || len(versions.Data.HoldersByVersion) != 1 {
+				t.Errorf("holders by version %v, want the holder under version 1", versions.Data.HoldersByVersion)
+			}
+
+			// The holder keeps the requirements they earned the badge under
+			held, err := badges.LoadUserBadges(ctx, store, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(held) != 1 || held[0].Name != "Invite 2 Friends" {
+				t.Errorf("holder sees %+v, want the badge as earned", held)
+			}
+		})
+	}
+}
+
+func TestRetiredBadgeStaysWithHolders(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			handler := catalogService(store)
+			badge := createBadge(t, handler, "Invite 2 Friends", 2)
+			user := holder(t, store, badge.ID)
+
+			var resp CatalogBadgeResponse
+			path := fmt.Sprintf("/api/admin/badges/%d", badge.ID)
+			call(t, handler, http.MethodPost, path+"/retire", "", &resp)
+			if resp.Code != 200 || !resp.Data.Retired || resp.Data.RetiredAt == nil {
+				t.Fatalf("retire: code %d (%s), retired %t; want 200 and retired", resp.Code, resp.Message, resp.Data.Retired)
+			}
+			call(t, handler, http.MethodPost, path+"/retire", "", &resp)
+			if resp.Code != 409 {
+				t.Errorf("second retire: code %d, want 409", resp.Code)
+			}
+			call(t, handler, http.MethodPut, path, `{"name":"Invite 3 Friends"}`, &resp)
+			if resp.Code != 409 {
+				t.Errorf("update of a retired badge: code %d, want 409", resp.Code)
+			}
+
+			var list ListCatalogBadgesResponse
+			call(t, handler, http.MethodGet, "/api/admin/badges", "", &list)
+			if list.Code != 200 || list.Data.TotalCount != 0 {
+				t.Errorf("catalog: code %d, %d badges; want the retired badge left out", list.Code, list.Data.TotalCount)
+			}
+			call(t, handler, http.MethodGet, "/api/admin/badges?includeRetired=true", "", &list)
+			if list.Data.TotalCount != 1 || !list.Data.Badges[0].Retired {
+				t.Errorf("catalog with retired badges: %+v, want the retired badge", list.Data.Badges)
+			}
+			var versions ListCatalogBadgeVersionsResponse
+			call(t, handler, http.MethodGet, path+"/versions", "", &versions)
+			if versions.Code != 200 || len(versions.Data.Versions) != 1 || versions.Data.HoldersByVersion["1"] != 1 {
+				t.Errorf("versions: code %d, %+v; want version 1 with its holder", versions.Code, versions.Data)
+			}
+
+			// Its holder still reads it; other users no longer see it
+			held, err := badges.LoadUserBadges(ctx, store, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(held) != 1 || held[0].Name != "Invite 2 Friends" || !held[0].Retired {
+				t.Errorf("holder sees %+v, want the retired badge", held)
+			}
+			other, err := badges.LoadUserBadges(ctx, store, user.ID+1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(other) != 0 {
+				t.Errorf("other user sees %+v, want no badge", other)
+			}
+
+			// Its name is free for a new badge
+			if created := createBadge(t, handler, "Invite 2 Friends", 2); created.ID == badge.ID || created.SortOrder != 2 {
+				t.Errorf("created %+v, want a new badge after the retired one", created)
+			}
+		})
+	}
+}
+
+func TestPrerequisitesSkipRetiredTasks(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			handler := catalogService(store)
+			inviteOne := createBadge(t, handler, "Invite a Friend", 1)
+			inviteTwo := createBadge(t, handler, "Invite 2 Friends", 2)
+			user := &repository.User{Username: "inviter", WalletAddr: "inviterWallet"}
+			if err := store.Users.Save(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+			guard := antigaming.New(store, antigaming.Rules{})
+			// admit returns the rejection of an attempt at the second invite, nil when it is admitted
+			admit := func() *repository.GuardDecision {
+				t.Helper()
+				task, err := store.Tasks.GetTask(ctx, inviteTwo.Task.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				def, err := store.Badges.GetDefinition(ctx, inviteTwo.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				decision, err := guard.Admit(ctx, antigaming.Attempt{UserID: user.ID, Task: task,
+					Requirements: def.Requirements})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return decision
+			}
+
+			// Inviting one friend comes before inviting two
+			if decision := admit(); decision == nil || decision.Rule != antigaming.RulePrerequisite {
+				t.Fatalf("with the first invite open: %+v, want rejected by %q", decision, antigaming.RulePrerequisite)
+			}
+			var resp CatalogBadgeResponse
+			call(t, handler, http.MethodPost, fmt.Sprintf("/api/admin/badges/%d/retire", inviteOne.ID), "", &resp)
+			if resp.Code != 200 {
+				t.Fatalf("retire: code %d (%s), want 200", resp.Code, resp.Message)
+			}
+			if decision := admit(); decision != nil {
+				t.Errorf("with the first invite retired: %s by %q, want admitted", decision.Outcome, decision.Rule)
+			}
+		})
+	}
+}
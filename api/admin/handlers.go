@@ -2,9 +2,23 @@ package admin
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/antigaming"
+	"github.com/aiw3/nft-solana-api/badges"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
 	"github.com/aiw3/nft-solana-api/shared"
+	"github.com/aiw3/nft-solana-api/volume"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
 )
@@ -59,7 +73,7 @@ func UploadTierImage() usecase.Interactor {
 }
 
 // GetAllUsersNftStatus returns NFT status for all users (admin)
-func GetAllUsersNftStatus() usecase.Interactor {
+func GetAllUsersNftStatus(store *repository.Store) usecase.Interactor {
 	type getAdminUsersNftStatusRequest struct {
 		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
 		Limit         int    `query:"limit" default:"50" description:"Number of users to return"`
@@ -82,26 +96,54 @@ func GetAllUsersNftStatus() usecase.Interactor {
 		// Validate pagination
 		limit, offset := shared.ValidatePaginationParams(req.Limit, req.Offset)
 
-		users := generateMockAdminUserNftStatus()
+		users, err := loadAdminUserNftStatus(ctx, store)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		// Apply status filter if provided
+		if req.Status != "" {
+			filteredUsers := []AdminUserNftStatus{}
+			for _, user := range users {
+				if strings.EqualFold(user.NftStatus, req.Status) {
+					filteredUsers = append(filteredUsers, user)
+				}
+			}
+			users = filteredUsers
+		}
+
+		// Statistics cover every matching user, not just the current page
+		statistics := AdminNftStatistics{TotalUsers: len(users)}
+		totalVolume := money.USDT.Units(0)
+		for _, user := range users {
+			if totalVolume, err = totalVolume.Add(user.TotalTradingVolume); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			if user.CurrentNftLevel == nil {
+				statistics.UsersWithoutNfts++
+				continue
+			}
+			statistics.UsersWithActiveNfts++
+			if *user.CurrentNftLevel > statistics.HighestNftLevel {
+				statistics.HighestNftLevel = *user.CurrentNftLevel
+			}
+		}
+		if len(users) > 0 {
+			statistics.AverageTradingVolume = totalVolume.MulFrac(1, int64(len(users)), money.HalfEven)
+		}
 
 		*resp = GetAdminUsersNftStatusResponse{
 			Code:    200,
 			Message: fmt.Sprintf("Users NFT status retrieved successfully by admin %s", admin.Username),
 			Data: AdminUsersNftStatusData{
-				Users: users,
+				Users: paginate(users, limit, offset),
 				Pagination: Pagination{
 					Total:   len(users),
 					Limit:   limit,
 					Offset:  offset,
-					HasMore: false,
-				},
-				Statistics: AdminNftStatistics{
-					TotalUsers:           len(users),
-					UsersWithActiveNfts:  2,
-					UsersWithoutNfts:     1,
-					AverageTradingVolume: 683333.58,
-					HighestNftLevel:      3,
+					HasMore: offset+limit < len(users),
 				},
+				Statistics: statistics,
 			},
 		}
 		return nil
@@ -116,11 +158,12 @@ func GetAllUsersNftStatus() usecase.Interactor {
 }
 
 // AwardCompetitionNFTs awards competition NFTs to winners (admin)
-func AwardCompetitionNFTs() usecase.Interactor {
+func AwardCompetitionNFTs(store *repository.Store, jobs *coordinator.Coordinator) usecase.Interactor {
 	type awardCompetitionNftRequest struct {
-		Authorization string   `header:"Authorization" description:"Bearer token for admin authentication"`
-		CompetitionID int      `json:"competition_id" required:"true" description:"Competition identifier"`
-		Winners       []Winner `json:"winners" required:"true" description:"List of winners with userID, walletAddress, rank"`
+		Authorization  string   `header:"Authorization" description:"Bearer token for admin authentication"`
+		IdempotencyKey string   `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		CompetitionID  int      `json:"competition_id" required:"true" description:"Competition identifier"`
+		Winners        []Winner `json:"winners" required:"true" description:"List of winners with userID, walletAddress, rank"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req awardCompetitionNftRequest, resp *AwardCompetitionNftsResponse) error {
@@ -135,27 +178,52 @@ func AwardCompetitionNFTs() usecase.Interactor {
 			return nil
 		}
 
+		if req.CompetitionID <= 0 || len(req.Winners) == 0 {
+			*resp = AwardCompetitionNftsResponse{
+				Code:    400,
+				Message: "Competition ID and at least one winner are required",
+				Data:    AwardCompetitionNftsData{},
+			}
+			return nil
+		}
+
 		awardedNfts := []map[string]interface{}{}
+		awardErrors := []map[string]interface{}{}
 		for _, winner := range req.Winners {
-			awardedNfts = append(awardedNfts, map[string]interface{}{
-				"userId":        winner.UserID,
-				"walletAddress": winner.WalletAddress,
-				"rank":          winner.Rank,
-				"nftId":         fmt.Sprintf("comp_%d_%d", req.CompetitionID, winner.UserID),
-				"mintAddress":   fmt.Sprintf("mint_%d_%d", req.CompetitionID, winner.UserID),
-				"transactionId": fmt.Sprintf("tx_award_%d_%d", req.CompetitionID, winner.UserID),
-				"awardedAt":     shared.GetCurrentTimestamp(),
+			var awarded map[string]interface{}
+			var awardError string
+			err := jobs.Do(ctx, coordinator.Job{
+				Kind:   coordinator.JobAward,
+				UserID: winner.UserID,
+				Run: func(ctx context.Context) error {
+					var err error
+					awarded, awardError, err = awardCompetitionNft(ctx, store, req.CompetitionID, winner)
+					return err
+				},
 			})
+			if _, rejected := coordinator.RejectionCode(err); rejected {
+				awardError = err.Error()
+			} else if err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			if awardError != "" {
+				awardErrors = append(awardErrors, map[string]interface{}{
+					"userId": winner.UserID,
+					"error":  awardError,
+				})
+				continue
+			}
+			awardedNfts = append(awardedNfts, awarded)
 		}
 
 		*resp = AwardCompetitionNftsResponse{
 			Code:    200,
-			Message: fmt.Sprintf("Successfully awarded %d Competition NFTs by admin %s", len(req.Winners), admin.Username),
+			Message: fmt.Sprintf("Successfully awarded %d Competition NFTs by admin %s", len(awardedNfts), admin.Username),
 			Data: AwardCompetitionNftsData{
 				CompetitionID: req.CompetitionID,
 				AwardedNfts:   awardedNfts,
-				TotalAwarded:  len(req.Winners),
-				Errors:        []map[string]interface{}{}, // No errors in mock
+				TotalAwarded:  len(awardedNfts),
+				Errors:        awardErrors,
 			},
 		}
 		return nil
@@ -169,8 +237,87 @@ func AwardCompetitionNFTs() usecase.Interactor {
 	return u
 }
 
+// awardCompetitionNft records the winner's competition NFT; it runs as a coordinator job holding the winner's lease.
+// A non-empty message rejects the winner without failing the whole award.
+func awardCompetitionNft(ctx context.Context, store *repository.Store, competitionID int,
+	winner Winner) (map[string]interface{}, string, error) {
+	user, err := store.Users.GetByID(ctx, winner.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, "User not found", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if user.WalletAddr != winner.WalletAddress {
+		return nil, "Wallet address does not match user", nil
+	}
+
+	now := time.Now().UTC()
+	nft := repository.CompetitionNft{
+		UserID:              user.ID,
+		Name:                "Trophy Breeder",
+		MintAddress:         fmt.Sprintf("mint_%d_%d", competitionID, user.ID),
+		TransactionID:       fmt.Sprintf("tx_award_%d_%d", competitionID, user.ID),
+		CompetitionID:       competitionID,
+		CompetitionName:     fmt.Sprintf("Competition %d", competitionID),
+		CompetitionType:     "trading_contest",
+		Rank:                winner.Rank,
+		TradingFeeReduction: 25,
+		MintedAt:            now,
+	}
+	err = store.CompetitionNfts.Create(ctx, &nft)
+	if errors.Is(err, repository.ErrConflict) {
+		return nil, "Competition NFT already awarded to this user", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return map[string]interface{}{
+		"userId":        user.ID,
+		"walletAddress": user.WalletAddr,
+		"rank":          nft.Rank,
+		"nftId":         nft.ID,
+		"mintAddress":   nft.MintAddress,
+		"transactionId": nft.TransactionID,
+		"awardedAt":     shared.FormatTimestamp(nft.MintedAt),
+	}, "", nil
+}
+
+// GetNftJobStats reports the NFT job queue: depth, in-flight claims/upgrades/awards and counters
+func GetNftJobStats(jobs *coordinator.Coordinator) usecase.Interactor {
+	type getNftJobStatsRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req getNftJobStatsRequest, resp *GetNftJobStatsResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = GetNftJobStatsResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    coordinator.Stats{InFlight: []coordinator.JobInfo{}},
+			}
+			return nil
+		}
+
+		*resp = GetNftJobStatsResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    jobs.Stats(),
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Get NFT Job Stats")
+	u.SetDescription("Admin endpoint reporting the NFT job queue depth and in-flight claim, upgrade and award jobs")
+	u.SetExpectedErrors(status.Unauthenticated)
+
+	return u
+}
+
 // GetCompetitionNftLeaderboard returns competition NFT leaderboard (public)
-func GetCompetitionNftLeaderboard() usecase.Interactor {
+func GetCompetitionNftLeaderboard(store *repository.Store) usecase.Interactor {
 	type getCompetitionNftLeaderboardRequest struct {
 		Limit         *int    `query:"limit" description:"Number of entries to return"`
 		Offset        *int    `query:"offset" description:"Number of entries to skip"`
@@ -187,39 +334,51 @@ func GetCompetitionNftLeaderboard() usecase.Interactor {
 			offset = *req.Offset
 		}
 
-		// Generate mock leaderboard data
-		leaderboard := []map[string]interface{}{
-			{
-				"userId":        12345,
-				"walletAddress": "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
-				"username":      "ChampionTrader",
-				"competitionId": "Q1_2024",
-				"rank":          1,
-				"awardedAt":     "2024-01-15T10:30:00.000Z",
-				"nftName":       "Trophy Breeder Champion",
-			},
-			{
-				"userId":        12346,
-				"walletAddress": "8VzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtBWWN",
-				"username":      "SilverTrader",
-				"competitionId": "Q1_2024",
-				"rank":          2,
-				"awardedAt":     "2024-01-15T10:35:00.000Z",
-				"nftName":       "Trophy Breeder Runner-up",
-			},
+		nfts, err := store.CompetitionNfts.List(ctx)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		leaderboard := []map[string]interface{}{}
+		for _, nft := range nfts {
+			if req.CompetitionID != nil && *req.CompetitionID != "" && strconv.Itoa(nft.CompetitionID) != *req.CompetitionID {
+				continue
+			}
+			user, err := store.Users.GetByID(ctx, nft.UserID)
+			if err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			leaderboard = append(leaderboard, map[string]interface{}{
+				"userId":          user.ID,
+				"walletAddress":   user.WalletAddr,
+				"username":        user.Username,
+				"competitionId":   strconv.Itoa(nft.CompetitionID),
+				"competitionName": nft.CompetitionName,
+				"rank":            nft.Rank,
+				"awardedAt":       shared.FormatTimestamp(nft.MintedAt),
+				"nftName":         nft.Name,
+			})
 		}
 
+		// Best rank first, earliest award breaking ties
+		sort.SliceStable(leaderboard, func(i, j int) bool {
+			if leaderboard[i]["rank"].(int) != leaderboard[j]["rank"].(int) {
+				return leaderboard[i]["rank"].(int) < leaderboard[j]["rank"].(int)
+			}
+			return leaderboard[i]["awardedAt"].(string) < leaderboard[j]["awardedAt"].(string)
+		})
+
 		*resp = GetCompetitionNftLeaderboardResponse{
 			Code:    200,
 			Message: "Competition NFT leaderboard retrieved successfully",
 			Data: CompetitionNftLeaderboardData{
-				Leaderboard: leaderboard,
+				Leaderboard: paginate(leaderboard, limit, offset),
 				TotalCount:  len(leaderboard),
 				Pagination: Pagination{
 					Total:   len(leaderboard),
 					Limit:   limit,
 					Offset:  offset,
-					HasMore: false,
+					HasMore: offset+limit < len(leaderboard),
 				},
 			},
 		}
@@ -310,12 +469,12 @@ func mockAdminUserLookup(accessToken string) *AdminUser {
 // ==========================================
 
 // UploadAvatar handles profile avatar upload (admin)
-func UploadAvatar() usecase.Interactor {
+func UploadAvatar(store *repository.Store) usecase.Interactor {
 	type uploadAvatarRequest struct {
-		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
-		ImageFile     string `json:"image_file" required:"true" description:"Base64 encoded image data"`
-		Name          string `json:"name" required:"true" description:"Avatar name"`
-		Category      string `json:"category" description:"Avatar category (default, premium, special)"`
+		Authorization string  `header:"Authorization" description:"Bearer token for admin authentication"`
+		ImageFile     string  `json:"image_file" required:"true" description:"Base64 encoded image data"`
+		Name          string  `json:"name" required:"true" description:"Avatar name"`
+		Category      string  `json:"category" description:"Avatar category (default, premium, special)"`
 		Description   *string `json:"description" description:"Avatar description"`
 		IsActive      *bool   `json:"is_active" description:"Whether avatar is active for use"`
 	}
@@ -352,21 +511,20 @@ func UploadAvatar() usecase.Interactor {
 			isActive = *req.IsActive
 		}
 
-		// Mock avatar creation  
-		avatarID := 1000 + len(req.Name) // Mock ID generation
-		ipfsHash := fmt.Sprintf("QmAvatar%d", avatarID)
-		imageURL := fmt.Sprintf("https://ipfs.io/ipfs/%s", ipfsHash)
-
-		avatar := ProfileAvatar{
-			ID:          avatarID,
+		ipfsHash := mockIpfsHash(req.ImageFile)
+		now := time.Now().UTC()
+		avatar := repository.Avatar{
 			Name:        req.Name,
-			ImageURL:    imageURL,
+			ImageURL:    fmt.Sprintf("https://ipfs.io/ipfs/%s", ipfsHash),
 			IpfsHash:    ipfsHash,
 			Category:    category,
 			Description: req.Description,
 			IsActive:    isActive,
-			CreatedAt:   shared.GetCurrentTimestamp(),
-			UpdatedAt:   shared.GetCurrentTimestamp(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := store.Avatars.Create(ctx, &avatar); err != nil {
+			return status.Wrap(err, status.Internal)
 		}
 
 		*resp = UploadAvatarResponse{
@@ -374,7 +532,7 @@ func UploadAvatar() usecase.Interactor {
 			Message: fmt.Sprintf("Avatar '%s' uploaded successfully by admin %s", req.Name, admin.Username),
 			Data: UploadAvatarData{
 				Success: true,
-				Avatar:  avatar,
+				Avatar:  toProfileAvatar(avatar),
 			},
 		}
 		return nil
@@ -389,7 +547,7 @@ func UploadAvatar() usecase.Interactor {
 }
 
 // ListAvatars returns list of all profile avatars (admin)
-func ListAvatars() usecase.Interactor {
+func ListAvatars(store *repository.Store) usecase.Interactor {
 	type listAvatarsRequest struct {
 		Authorization string  `header:"Authorization" description:"Bearer token for admin authentication"`
 		Category      *string `query:"category" description:"Filter by avatar category"`
@@ -420,8 +578,14 @@ func ListAvatars() usecase.Interactor {
 			offset = *req.Offset
 		}
 
-		// Generate mock avatar list
-		avatars := generateMockProfileAvatars()
+		records, err := store.Avatars.List(ctx)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		avatars := make([]ProfileAvatar, 0, len(records))
+		for _, record := range records {
+			avatars = append(avatars, toProfileAvatar(record))
+		}
 
 		// Apply filters
 		filteredAvatars := []ProfileAvatar{}
@@ -441,7 +605,7 @@ func ListAvatars() usecase.Interactor {
 			Code:    200,
 			Message: fmt.Sprintf("Avatar list retrieved successfully by admin %s", admin.Username),
 			Data: ListAvatarsData{
-				Avatars:    filteredAvatars,
+				Avatars:    paginate(filteredAvatars, limit, offset),
 				TotalCount: len(filteredAvatars),
 				Stats: map[string]interface{}{
 					"totalAvatars":   len(avatars),
@@ -468,7 +632,7 @@ func ListAvatars() usecase.Interactor {
 }
 
 // UpdateAvatar updates an existing profile avatar (admin)
-func UpdateAvatar() usecase.Interactor {
+func UpdateAvatar(store *repository.Store) usecase.Interactor {
 	type updateAvatarRequest struct {
 		Authorization string  `header:"Authorization" description:"Bearer token for admin authentication"`
 		ID            int     `path:"id" required:"true" description:"Avatar ID to update"`
@@ -501,9 +665,8 @@ func UpdateAvatar() usecase.Interactor {
 			return nil
 		}
 
-		// Mock avatar lookup
-		avatar := findMockProfileAvatarByID(req.ID)
-		if avatar == nil {
+		avatar, err := store.Avatars.GetByID(ctx, req.ID)
+		if errors.Is(err, repository.ErrNotFound) {
 			*resp = UpdateAvatarResponse{
 				Code:    404,
 				Message: "Avatar not found",
@@ -511,35 +674,39 @@ func UpdateAvatar() usecase.Interactor {
 			}
 			return nil
 		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
 		// Update fields if provided
-		updatedAvatar := *avatar
 		if req.Name != nil {
-			updatedAvatar.Name = *req.Name
+			avatar.Name = *req.Name
 		}
 		if req.Category != nil {
-			updatedAvatar.Category = *req.Category
+			avatar.Category = *req.Category
 		}
 		if req.Description != nil {
-			updatedAvatar.Description = req.Description
+			avatar.Description = req.Description
 		}
 		if req.IsActive != nil {
-			updatedAvatar.IsActive = *req.IsActive
+			avatar.IsActive = *req.IsActive
 		}
 		if req.ImageFile != nil && *req.ImageFile != "" {
-			// Mock new image upload
-			newHash := fmt.Sprintf("QmAvatarUpdated%d", 12345)
-			updatedAvatar.IpfsHash = newHash
-			updatedAvatar.ImageURL = fmt.Sprintf("https://ipfs.io/ipfs/%s", newHash)
+			avatar.IpfsHash = mockIpfsHash(*req.ImageFile)
+			avatar.ImageURL = fmt.Sprintf("https://ipfs.io/ipfs/%s", avatar.IpfsHash)
+		}
+		avatar.UpdatedAt = time.Now().UTC()
+
+		if err := store.Avatars.Update(ctx, avatar); err != nil {
+			return status.Wrap(err, status.Internal)
 		}
-		updatedAvatar.UpdatedAt = shared.GetCurrentTimestamp()
 
 		*resp = UpdateAvatarResponse{
 			Code:    200,
 			Message: fmt.Sprintf("Avatar ID %d updated successfully by admin %s", req.ID, admin.Username),
 			Data: UpdateAvatarData{
 				Success:       true,
-				UpdatedAvatar: updatedAvatar,
+				UpdatedAvatar: toProfileAvatar(*avatar),
 				Changes: map[string]interface{}{
 					"name":        req.Name != nil,
 					"category":    req.Category != nil,
@@ -561,7 +728,7 @@ func UpdateAvatar() usecase.Interactor {
 }
 
 // DeleteAvatar deletes a profile avatar (admin)
-func DeleteAvatar() usecase.Interactor {
+func DeleteAvatar(store *repository.Store) usecase.Interactor {
 	type deleteAvatarRequest struct {
 		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
 		ID            int    `path:"id" required:"true" description:"Avatar ID to delete"`
@@ -590,9 +757,8 @@ func DeleteAvatar() usecase.Interactor {
 			return nil
 		}
 
-		// Mock avatar lookup
-		avatar := findMockProfileAvatarByID(req.ID)
-		if avatar == nil {
+		avatar, err := store.Avatars.GetByID(ctx, req.ID)
+		if errors.Is(err, repository.ErrNotFound) {
 			*resp = DeleteAvatarResponse{
 				Code:    404,
 				Message: "Avatar not found",
@@ -600,9 +766,19 @@ func DeleteAvatar() usecase.Interactor {
 			}
 			return nil
 		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
-		// Check if avatar is in use (mock check)
-		usersUsingAvatar := mockCheckAvatarUsage(req.ID)
+		// Check which users currently display this avatar
+		users, err := store.Users.ListByAvatar(ctx, req.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		usersUsingAvatar := make([]int, 0, len(users))
+		for _, user := range users {
+			usersUsingAvatar = append(usersUsingAvatar, user.ID)
+		}
 		forceDelete := req.ForceDelete != nil && *req.ForceDelete
 
 		if len(usersUsingAvatar) > 0 && !forceDelete {
@@ -610,19 +786,31 @@ func DeleteAvatar() usecase.Interactor {
 				Code:    409,
 				Message: fmt.Sprintf("Avatar is currently being used by %d user(s). Use force=true to delete anyway.", len(usersUsingAvatar)),
 				Data: DeleteAvatarData{
-					Success:      false,
+					Success:       false,
 					UsersAffected: usersUsingAvatar,
 				},
 			}
 			return nil
 		}
 
+		// Clear the avatar from affected profiles before removing it
+		for i := range users {
+			users[i].ProfileAvatarID = nil
+			users[i].UpdatedAt = time.Now().UTC()
+			if err := store.Users.Save(ctx, &users[i]); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+		}
+		if err := store.Avatars.Delete(ctx, req.ID); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
 		*resp = DeleteAvatarResponse{
 			Code:    200,
 			Message: fmt.Sprintf("Avatar ID %d deleted successfully by admin %s", req.ID, admin.Username),
 			Data: DeleteAvatarData{
 				Success:       true,
-				DeletedAvatar: *avatar,
+				DeletedAvatar: toProfileAvatar(*avatar),
 				UsersAffected: usersUsingAvatar,
 				ForceDeleted:  forceDelete,
 			},
@@ -642,65 +830,25 @@ func DeleteAvatar() usecase.Interactor {
 // HELPER FUNCTIONS FOR AVATAR MANAGEMENT
 // ==========================================
 
-// generateMockProfileAvatars creates mock profile avatar data
-func generateMockProfileAvatars() []ProfileAvatar {
-	return []ProfileAvatar{
-		{
-			ID:          1,
-			Name:        "Golden Trader Avatar",
-			ImageURL:    "https://ipfs.io/ipfs/QmGoldenTrader123",
-			IpfsHash:    "QmGoldenTrader123",
-			Category:    "premium",
-			Description: shared.StringPtr("Exclusive golden trader profile avatar"),
-			IsActive:    true,
-			CreatedAt:   "2024-01-15T10:30:00.000Z",
-			UpdatedAt:   "2024-01-15T10:30:00.000Z",
-		},
-		{
-			ID:          2,
-			Name:        "Default Avatar 1",
-			ImageURL:    "https://ipfs.io/ipfs/QmDefaultAvatar1",
-			IpfsHash:    "QmDefaultAvatar1",
-			Category:    "default",
-			Description: shared.StringPtr("Standard default profile avatar"),
-			IsActive:    true,
-			CreatedAt:   "2024-01-10T09:00:00.000Z",
-			UpdatedAt:   "2024-01-10T09:00:00.000Z",
-		},
-		{
-			ID:          3,
-			Name:        "Competition Winner",
-			ImageURL:    "https://ipfs.io/ipfs/QmCompWinner456",
-			IpfsHash:    "QmCompWinner456",
-			Category:    "special",
-			Description: shared.StringPtr("Special avatar for competition winners"),
-			IsActive:    true,
-			CreatedAt:   "2024-01-20T16:45:00.000Z",
-			UpdatedAt:   "2024-01-20T16:45:00.000Z",
-		},
-		{
-			ID:          4,
-			Name:        "Beta Tester",
-			ImageURL:    "https://ipfs.io/ipfs/QmBetaTester789",
-			IpfsHash:    "QmBetaTester789",
-			Category:    "special",
-			Description: shared.StringPtr("Exclusive beta tester avatar"),
-			IsActive:    false,
-			CreatedAt:   "2023-12-01T08:00:00.000Z",
-			UpdatedAt:   "2024-01-01T12:00:00.000Z",
-		},
+// toProfileAvatar converts a stored avatar to its API representation
+func toProfileAvatar(avatar repository.Avatar) ProfileAvatar {
+	return ProfileAvatar{
+		ID:          avatar.ID,
+		Name:        avatar.Name,
+		ImageURL:    avatar.ImageURL,
+		IpfsHash:    avatar.IpfsHash,
+		Category:    avatar.Category,
+		Description: avatar.Description,
+		IsActive:    avatar.IsActive,
+		CreatedAt:   shared.FormatTimestamp(avatar.CreatedAt),
+		UpdatedAt:   shared.FormatTimestamp(avatar.UpdatedAt),
 	}
 }
 
-// findMockProfileAvatarByID finds a mock profile avatar by ID
-func findMockProfileAvatarByID(avatarID int) *ProfileAvatar {
-	avatars := generateMockProfileAvatars()
-	for _, avatar := range avatars {
-		if avatar.ID == avatarID {
-			return &avatar
-		}
-	}
-	return nil
+// mockIpfsHash derives a stable IPFS-style content hash for uploaded image data
+func mockIpfsHash(imageData string) string {
+	sum := sha256.Sum256([]byte(imageData))
+	return "Qm" + hex.EncodeToString(sum[:])[:44]
 }
 
 // countActiveAvatars counts active avatars
@@ -723,47 +871,776 @@ func getCategoryCounts(avatars []ProfileAvatar) map[string]int {
 	return counts
 }
 
-// mockCheckAvatarUsage checks which users are using the avatar (mock)
-func mockCheckAvatarUsage(avatarID int) []int {
-	// Mock: return list of user IDs using this avatar
-	switch avatarID {
-	case 1:
-		return []int{12345, 67890} // Golden Trader Avatar is popular
-	case 2:
-		return []int{11111, 22222, 33333} // Default avatar used by many
-	case 3:
-		return []int{12345} // Competition Winner used by champion
-	default:
-		return []int{} // Not used by anyone
+// ==========================================
+// HELPER FUNCTIONS FOR USER NFT STATUS
+// ==========================================
+
+// loadAdminUserNftStatus builds the NFT status overview for every user
+func loadAdminUserNftStatus(ctx context.Context, store *repository.Store) ([]AdminUserNftStatus, error) {
+	users, err := store.Users.List(ctx)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// generateMockAdminUserNftStatus creates mock admin user NFT status data
-func generateMockAdminUserNftStatus() []AdminUserNftStatus {
-	return []AdminUserNftStatus{
-		{
-			UserID:             12345,
-			Username:           "crypto_trader_01",
-			WalletAddress:      "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
-			CurrentNftLevel:    shared.IntPtr(3),
-			NftStatus:          "Active",
-			TotalTradingVolume: 1250000.50,
-		},
-		{
-			UserID:             67890,
-			Username:           "defi_master",
-			WalletAddress:      "8XaBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWN",
-			CurrentNftLevel:    shared.IntPtr(2),
-			NftStatus:          "Active",
-			TotalTradingVolume: 750000.25,
-		},
-		{
-			UserID:             11111,
-			Username:           "nft_collector",
-			WalletAddress:      "7YcCbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
-			CurrentNftLevel:    nil,
+	statuses := make([]AdminUserNftStatus, 0, len(users))
+	for _, user := range users {
+		nfts, err := store.TieredNfts.ListByUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := AdminUserNftStatus{
+			UserID:             user.ID,
+			Username:           user.Username,
+			WalletAddress:      user.WalletAddr,
 			NftStatus:          "None",
-			TotalTradingVolume: 50000.00,
-		},
+			TotalTradingVolume: user.TradingVolume,
+		}
+		for _, nft := range nfts {
+			if nft.Status == repository.NftStatusActive {
+				entry.CurrentNftLevel = shared.IntPtr(nft.Level)
+				entry.NftStatus = "Active"
+			}
+		}
+		statuses = append(statuses, entry)
+	}
+	return statuses, nil
+}
+
+// paginate returns the requested page of a slice
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
+
+// ==========================================
+// ADMIN TASK GUARD HANDLERS
+// ==========================================
+
+// ListGuardDecisions returns the anti-gaming decision log; outcome=review&reviewStatus=pending is the review queue (admin)
+func ListGuardDecisions(store *repository.Store) usecase.Interactor {
+	type listGuardDecisionsRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		UserID        int    `query:"userId" description:"Filter by user ID"`
+		TaskID        int    `query:"taskId" description:"Filter by task ID"`
+		Outcome       string `query:"outcome" enum:"allowed,rejected,review" description:"Filter by outcome"`
+		ReviewStatus  string `query:"reviewStatus" enum:"pending,approved,rejected" description:"Filter by review state"`
+		Limit         int    `query:"limit" description:"Maximum number of decisions to return (default 50, max 500)"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req listGuardDecisionsRequest, resp *ListGuardDecisionsResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = ListGuardDecisionsResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    ListGuardDecisionsData{Decisions: []GuardDecision{}},
+			}
+			return nil
+		}
+
+		limit := req.Limit
+		if limit <= 0 {
+			limit = 50
+		} else if limit > 500 {
+			limit = 500
+		}
+		filter := repository.GuardDecisionFilter{
+			UserID:       req.UserID,
+			TaskID:       req.TaskID,
+			Outcome:      req.Outcome,
+			ReviewStatus: req.ReviewStatus,
+		}
+		total, err := store.TaskGuard.Count(ctx, filter)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		filter.Limit = limit
+		decisions, err := store.TaskGuard.List(ctx, filter)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		result := make([]GuardDecision, 0, len(decisions))
+		for _, decision := range decisions {
+			result = append(result, toGuardDecision(decision))
+		}
+		*resp = ListGuardDecisionsResponse{
+			Code:    200,
+			Message: "Success",
+			Data: ListGuardDecisionsData{
+				Decisions:  result,
+				TotalCount: total,
+			},
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("List Task Guard Decisions")
+	u.SetDescription("Admin endpoint listing anti-gaming decisions on badge task completions with their reasons, newest first")
+	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// ResolveGuardReview approves or rejects a task completion held for review; approving awards it (admin)
+func ResolveGuardReview(store *repository.Store) usecase.Interactor {
+	type resolveGuardReviewRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		ID            int    `path:"id" required:"true" description:"Decision ID of the held completion"`
+		Approve       bool   `json:"approve" description:"true completes the task and awards its badge, false rejects it"`
+		Note          string `json:"note" description:"Optional note recorded with the resolution"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req resolveGuardReviewRequest, resp *ResolveGuardReviewResponse) error {
+		admin, err := extractAdminFromAuthHeader(req.Authorization)
+		if err != nil {
+			*resp = ResolveGuardReviewResponse{
+				Code:    401,
+				Message: err.Error(),
+			}
+			return nil
+		}
+
+		decision, err := store.TaskGuard.Get(ctx, req.ID)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && decision.Outcome != repository.GuardOutcomeReview) {
+			*resp = ResolveGuardReviewResponse{
+				Code:    404,
+				Message: fmt.Sprintf("Review %d not found", req.ID),
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		reviewStatus := repository.ReviewRejected
+		if req.Approve {
+			reviewStatus = repository.ReviewApproved
+		}
+		now := time.Now().UTC()
+		err = store.TaskGuard.Resolve(ctx, req.ID, reviewStatus, admin.Username, req.Note, now)
+		if errors.Is(err, repository.ErrConflict) {
+			*resp = ResolveGuardReviewResponse{
+				Code:    409,
+				Message: fmt.Sprintf("Review %d is already %s", req.ID, decision.ReviewStatus),
+				Data:    ResolveGuardReviewData{Decision: toGuardDecision(*decision)},
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		badgeEarned := false
+		if req.Approve {
+			badgeEarned, err = badges.ApproveTaskCompletion(ctx, store, decision.UserID, decision.TaskID, now)
+			if err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+		}
+
+		resolved, err := store.TaskGuard.Get(ctx, req.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = ResolveGuardReviewResponse{
+			Code:    200,
+			Message: fmt.Sprintf("Review %d %s by admin %s", req.ID, reviewStatus, admin.Username),
+			Data: ResolveGuardReviewData{
+				Decision:    toGuardDecision(*resolved),
+				BadgeEarned: badgeEarned,
+			},
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Resolve Task Guard Review")
+	u.SetDescription("Admin endpoint to approve or reject a badge task completion held for review; approval completes the task and awards its badge")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// GetGuardRules returns the anti-gaming rules in force (admin)
+func GetGuardRules(guard *antigaming.Guard) usecase.Interactor {
+	type getGuardRulesRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req getGuardRulesRequest, resp *GetGuardRulesResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = GetGuardRulesResponse{
+				Code:    401,
+				Message: err.Error(),
+			}
+			return nil
+		}
+
+		*resp = GetGuardRulesResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    guard.Rules(),
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Get Task Guard Rules")
+	u.SetDescription("Admin endpoint returning the anti-gaming rules applied to badge task completions")
+	u.SetExpectedErrors(status.Unauthenticated)
+
+	return u
+}
+
+// ==========================================
+// ADMIN AI AGENT USAGE HANDLERS
+// ==========================================
+
+// GetUserAiAgentUsage returns a user's current AI agent quota and usage per quota week (admin)
+func GetUserAiAgentUsage(store *repository.Store, quotas *aiquota.Service) usecase.Interactor {
+	type getUserAiAgentUsageRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		UserID        int    `path:"userId" required:"true" description:"User ID"`
+		Weeks         int    `query:"weeks" description:"Number of most recent weeks with usage to return (default 12, max 104)"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req getUserAiAgentUsageRequest, resp *GetUserAiAgentUsageResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = GetUserAiAgentUsageResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    GetUserAiAgentUsageData{Weeks: []AiAgentUsageWeek{}},
+			}
+			return nil
+		}
+
+		if _, err := store.Users.GetByID(ctx, req.UserID); errors.Is(err, repository.ErrNotFound) {
+			*resp = GetUserAiAgentUsageResponse{
+				Code:    404,
+				Message: fmt.Sprintf("User %d not found", req.UserID),
+				Data:    GetUserAiAgentUsageData{Weeks: []AiAgentUsageWeek{}},
+			}
+			return nil
+		} else if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		weeks := req.Weeks
+		if weeks <= 0 {
+			weeks = 12
+		} else if weeks > 104 {
+			weeks = 104
+		}
+		quota, err := quotas.Get(ctx, req.UserID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		history, err := quotas.History(ctx, req.UserID, weeks)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		data := GetUserAiAgentUsageData{
+			UserID:          req.UserID,
+			NftLevel:        quota.Level,
+			WeeklyQuota:     quota.WeeklyTotal,
+			WeeklyUsed:      quota.Used,
+			WeeklyRemaining: quota.Remaining,
+			ResetsAt:        shared.FormatTimestamp(quota.ResetsAt),
+			ResetPolicy:     quotas.Schedule().String(),
+			Weeks:           make([]AiAgentUsageWeek, 0, len(history)),
+		}
+		for _, usage := range history {
+			data.Weeks = append(data.Weeks, AiAgentUsageWeek{
+				WeekStart: shared.FormatTimestamp(usage.WeekStart),
+				ResetsAt:  shared.FormatTimestamp(quotas.Schedule().NextReset(usage.WeekStart)),
+				NftLevel:  usage.Level,
+				Quota:     usage.Quota,
+				Consumed:  usage.Consumed,
+				Refunded:  usage.Refunded,
+				Used:      usage.Consumed - usage.Refunded,
+				UpdatedAt: shared.FormatTimestamp(usage.UpdatedAt),
+			})
+		}
+		*resp = GetUserAiAgentUsageResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    data,
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Get User AI Agent Usage")
+	u.SetDescription("Admin endpoint returning a user's AI agent quota this week and the uses consumed and refunded per quota week")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// GetUserFeeLedger returns a user's fee savings per platform and their fee ledger entries
+func GetUserFeeLedger(store *repository.Store) usecase.Interactor {
+	type getUserFeeLedgerRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		UserID        int    `path:"userId" required:"true" description:"User ID"`
+		Limit         int    `query:"limit" description:"Number of entries to return (default 20, max 100)"`
+		Offset        int    `query:"offset" description:"Number of entries to skip"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req getUserFeeLedgerRequest, resp *GetUserFeeLedgerResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = GetUserFeeLedgerResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    GetUserFeeLedgerData{Platforms: []PlatformFeeSaved{}, Entries: []FeeLedgerEntry{}},
+			}
+			return nil
+		}
+
+		if _, err := store.Users.GetByID(ctx, req.UserID); errors.Is(err, repository.ErrNotFound) {
+			*resp = GetUserFeeLedgerResponse{
+				Code:    404,
+				Message: fmt.Sprintf("User %d not found", req.UserID),
+				Data:    GetUserFeeLedgerData{Platforms: []PlatformFeeSaved{}, Entries: []FeeLedgerEntry{}},
+			}
+			return nil
+		} else if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		limit, offset := shared.ValidatePaginationParams(req.Limit, req.Offset)
+		totals, err := store.FeeLedger.TotalsByUser(ctx, req.UserID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		total, err := store.FeeLedger.CountByUser(ctx, req.UserID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		entries, err := store.FeeLedger.ListByUser(ctx, req.UserID, limit, offset)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		data := GetUserFeeLedgerData{
+			UserID:     req.UserID,
+			Platforms:  make([]PlatformFeeSaved, 0, len(totals)),
+			Entries:    make([]FeeLedgerEntry, 0, len(entries)),
+			Pagination: Pagination{Total: total, Limit: limit, Offset: offset, HasMore: offset+len(entries) < total},
+		}
+		for _, platform := range totals {
+			if data.TotalSaved, err = data.TotalSaved.Add(platform.FeeSaved); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			data.Platforms = append(data.Platforms, PlatformFeeSaved{
+				Platform:       platform.Platform,
+				PlatformWallet: platform.PlatformWallet,
+				FeeSaved:       platform.FeeSaved,
+				Trades:         platform.Trades,
+			})
+		}
+		for _, entry := range entries {
+			data.Entries = append(data.Entries, FeeLedgerEntry{
+				ID:              entry.ID,
+				Platform:        entry.Platform,
+				TradeID:         entry.TradeID,
+				PlatformWallet:  entry.PlatformWallet,
+				Notional:        entry.Notional,
+				FeeRate:         entry.FeeRate,
+				UndiscountedFee: entry.UndiscountedFee,
+				ChargedFee:      entry.ChargedFee,
+				FeeSaved:        entry.FeeSaved,
+				FeeReduction:    entry.FeeReduction,
+				NftType:         entry.NftType,
+				UserNftID:       entry.NftID,
+				ExecutedAt:      shared.FormatTimestamp(entry.ExecutedAt),
+				RecordedAt:      shared.FormatTimestamp(entry.RecordedAt),
+			})
+		}
+		*resp = GetUserFeeLedgerResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    data,
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Get User Fee Ledger")
+	u.SetDescription("Admin endpoint returning the fee a user saved per platform account and the fee ledger entries behind it, latest trade first")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// GetUserTradingVolume returns a user's qualifying trading volume, its daily rollups and open drifts (admin)
+func GetUserTradingVolume(store *repository.Store) usecase.Interactor {
+	type getUserTradingVolumeRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		UserID        int    `path:"userId" required:"true" description:"User ID"`
+		Days          int    `query:"days" description:"Number of most recent UTC days of rollups to return (default 30, max 366)"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req getUserTradingVolumeRequest, resp *GetUserTradingVolumeResponse) error {
+		empty := GetUserTradingVolumeData{Platforms: []PlatformVolume{}, Daily: []DailyVolume{}, OpenDrifts: []VolumeDrift{}}
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = GetUserTradingVolumeResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    empty,
+			}
+			return nil
+		}
+		user, err := store.Users.GetByID(ctx, req.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			*resp = GetUserTradingVolumeResponse{
+				Code:    404,
+				Message: fmt.Sprintf("User %d not found", req.UserID),
+				Data:    empty,
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		days := req.Days
+		if days <= 0 {
+			days = 30
+		} else if days > 366 {
+			days = 366
+		}
+		rollups, err := store.Volume.ListRollups(ctx, user.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		drifts, err := store.Volume.ListDrifts(ctx, repository.VolumeDriftFilter{UserID: user.ID, OpenOnly: true})
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		data := empty
+		data.UserID = user.ID
+		data.QualifyingVolume = user.TradingVolume
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		since := today.AddDate(0, 0, 1-days)
+		byPlatform := map[string]int{}
+		for i := len(rollups) - 1; i >= 0; i-- {
+			rollup := rollups[i]
+			if j, ok := byPlatform[rollup.Platform]; ok {
+				if data.Platforms[j].Volume, err = data.Platforms[j].Volume.Add(rollup.Volume); err != nil {
+					return status.Wrap(err, status.Internal)
+				}
+				data.Platforms[j].Trades += rollup.Trades
+			} else {
+				byPlatform[rollup.Platform] = len(data.Platforms)
+				data.Platforms = append(data.Platforms, PlatformVolume{Platform: rollup.Platform, Volume: rollup.Volume, Trades: rollup.Trades})
+			}
+			if !rollup.Day.Before(since) {
+				data.Daily = append(data.Daily, DailyVolume{
+					Day:      rollup.Day.Format(time.DateOnly),
+					Platform: rollup.Platform,
+					Volume:   rollup.Volume,
+					Trades:   rollup.Trades,
+				})
+			}
+		}
+		sort.Slice(data.Platforms, func(i, j int) bool { return data.Platforms[i].Platform < data.Platforms[j].Platform })
+		for _, drift := range drifts {
+			data.OpenDrifts = append(data.OpenDrifts, toVolumeDrift(drift))
+		}
+		*resp = GetUserTradingVolumeResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    data,
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Get User Trading Volume")
+	u.SetDescription("Admin endpoint returning a user's qualifying trading volume, volume per platform, recent daily rollups and open reconciliation drifts")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// ListUserVolumeTrades returns a page of the raw trades behind a user's trading volume, each with the asset it
+// settled in and the price it was converted to USDT at (admin)
+func ListUserVolumeTrades(store *repository.Store) usecase.Interactor {
+	type listUserVolumeTradesRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		UserID        int    `path:"userId" required:"true" description:"User ID"`
+		Limit         int    `query:"limit" description:"Number of trades to return (default 20, max 100)"`
+		Offset        int    `query:"offset" description:"Number of trades to skip"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req listUserVolumeTradesRequest, resp *ListUserVolumeTradesResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = ListUserVolumeTradesResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    ListUserVolumeTradesData{Trades: []VolumeTrade{}},
+			}
+			return nil
+		}
+
+		if _, err := store.Users.GetByID(ctx, req.UserID); errors.Is(err, repository.ErrNotFound) {
+			*resp = ListUserVolumeTradesResponse{
+				Code:    404,
+				Message: fmt.Sprintf("User %d not found", req.UserID),
+				Data:    ListUserVolumeTradesData{Trades: []VolumeTrade{}},
+			}
+			return nil
+		} else if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		limit, offset := shared.ValidatePaginationParams(req.Limit, req.Offset)
+		total, err := store.Volume.CountTrades(ctx, req.UserID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		trades, err := store.Volume.ListTrades(ctx, req.UserID, limit, offset)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		data := ListUserVolumeTradesData{
+			UserID:     req.UserID,
+			Trades:     make([]VolumeTrade, 0, len(trades)),
+			Pagination: Pagination{Total: total, Limit: limit, Offset: offset, HasMore: offset+len(trades) < total},
+		}
+		for _, trade := range trades {
+			data.Trades = append(data.Trades, toVolumeTrade(trade))
+		}
+		*resp = ListUserVolumeTradesResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    data,
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("List User Volume Trades")
+	u.SetDescription("Admin endpoint returning the raw trades behind a user's trading volume, latest first, with the asset each settled in and the price it was converted to USDT at")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// RecomputeUserTradingVolume rebuilds a user's rollups and qualifying volume from their raw trades (admin)
+func RecomputeUserTradingVolume(store *repository.Store, volumes *volume.Service) usecase.Interactor {
+	type recomputeUserTradingVolumeRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		UserID        int    `path:"userId" required:"true" description:"User ID"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req recomputeUserTradingVolumeRequest, resp *RecomputeUserTradingVolumeResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = RecomputeUserTradingVolumeResponse{
+				Code:    401,
+				Message: err.Error(),
+			}
+			return nil
+		}
+		user, err := store.Users.GetByID(ctx, req.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			*resp = RecomputeUserTradingVolumeResponse{
+				Code:    404,
+				Message: fmt.Sprintf("User %d not found", req.UserID),
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		recomputed, resolved, err := volumes.Recompute(ctx, user.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = RecomputeUserTradingVolumeResponse{
+			Code:    200,
+			Message: "Trading volume recomputed from raw trades",
+			Data: RecomputeUserTradingVolumeData{
+				UserID:           user.ID,
+				PreviousVolume:   user.TradingVolume,
+				QualifyingVolume: recomputed,
+				ResolvedDrifts:   resolved,
+			},
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Recompute User Trading Volume")
+	u.SetDescription("Admin endpoint rebuilding a user's daily rollups and qualifying trading volume from their raw trades; the user's open drifts are resolved")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// ListVolumeDrifts returns the drifts trading volume reconciliation found (admin)
+func ListVolumeDrifts(store *repository.Store) usecase.Interactor {
+	type listVolumeDriftsRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+		UserID        int    `query:"userId" description:"Filter by user ID"`
+		Status        string `query:"status" enum:"open,all" description:"open (default) lists unresolved drifts; all includes resolved ones"`
+		Limit         int    `query:"limit" description:"Maximum number of drifts to return (default 50, max 500)"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req listVolumeDriftsRequest, resp *ListVolumeDriftsResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = ListVolumeDriftsResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    ListVolumeDriftsData{Drifts: []VolumeDrift{}},
+			}
+			return nil
+		}
+
+		limit := req.Limit
+		if limit <= 0 {
+			limit = 50
+		} else if limit > 500 {
+			limit = 500
+		}
+		drifts, err := store.Volume.ListDrifts(ctx, repository.VolumeDriftFilter{
+			UserID:   req.UserID,
+			OpenOnly: req.Status != "all",
+			Limit:    limit,
+		})
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		result := make([]VolumeDrift, 0, len(drifts))
+		for _, drift := range drifts {
+			result = append(result, toVolumeDrift(drift))
+		}
+		*resp = ListVolumeDriftsResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    ListVolumeDriftsData{Drifts: result},
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("List Trading Volume Drifts")
+	u.SetDescription("Admin endpoint listing differences reconciliation found between trading volume rollups and raw trades, newest first")
+	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// ReconcileVolume runs trading volume reconciliation now instead of waiting for the nightly run (admin)
+func ReconcileVolume(volumes *volume.Service) usecase.Interactor {
+	type reconcileVolumeRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for admin authentication"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req reconcileVolumeRequest, resp *ReconcileVolumeResponse) error {
+		if _, err := extractAdminFromAuthHeader(req.Authorization); err != nil {
+			*resp = ReconcileVolumeResponse{
+				Code:    401,
+				Message: err.Error(),
+			}
+			return nil
+		}
+
+		report, err := volumes.Reconcile(ctx)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = ReconcileVolumeResponse{
+			Code:    200,
+			Message: fmt.Sprintf("Reconciliation found %d new drifts; %d open", report.NewDrifts, report.OpenDrifts),
+			Data:    report,
+		}
+		return nil
+	})
+
+	u.SetTags("Admin")
+	u.SetTitle("Reconcile Trading Volume")
+	u.SetDescription("Admin endpoint comparing every daily rollup and user trading volume with the raw trades now; differences become open drifts and drifts no longer found are resolved")
+	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// toVolumeDrift converts a stored drift to its API representation
+func toVolumeTrade(trade repository.VolumeTrade) VolumeTrade {
+	result := VolumeTrade{
+		ID:            trade.ID,
+		Platform:      trade.Platform,
+		TradeID:       trade.TradeID,
+		Source:        trade.Source,
+		Notional:      trade.Notional,
+		Asset:         trade.Asset,
+		AssetNotional: trade.AssetNotional,
+		Price:         trade.Price,
+		PriceSource:   trade.PriceSource,
+		ExecutedAt:    shared.FormatTimestamp(trade.ExecutedAt),
+		IngestedAt:    shared.FormatTimestamp(trade.IngestedAt),
+	}
+	if trade.PricedAt != nil {
+		pricedAt := shared.FormatTimestamp(*trade.PricedAt)
+		result.PricedAt = &pricedAt
+	}
+	return result
+}
+
+func toVolumeDrift(drift repository.VolumeDrift) VolumeDrift {
+	result := VolumeDrift{
+		ID:           drift.ID,
+		Kind:         drift.Kind,
+		UserID:       drift.UserID,
+		Platform:     drift.Platform,
+		StoredVolume: drift.StoredVolume,
+		RawVolume:    drift.RawVolume,
+		StoredTrades: drift.StoredTrades,
+		RawTrades:    drift.RawTrades,
+		DetectedAt:   shared.FormatTimestamp(drift.DetectedAt),
+	}
+	if !drift.Day.IsZero() {
+		result.Day = drift.Day.Format(time.DateOnly)
+	}
+	if drift.ResolvedAt != nil {
+		resolvedAt := shared.FormatTimestamp(*drift.ResolvedAt)
+		result.ResolvedAt = &resolvedAt
+	}
+	return result
+}
+
+// toGuardDecision converts a stored decision to its API representation
+func toGuardDecision(decision repository.GuardDecision) GuardDecision {
+	result := GuardDecision{
+		ID:           decision.ID,
+		UserID:       decision.UserID,
+		TaskID:       decision.TaskID,
+		Outcome:      decision.Outcome,
+		Rule:         decision.Rule,
+		Reason:       decision.Reason,
+		EvidenceHash: decision.EvidenceHash,
+		Progress:     decision.Progress,
+		ReviewStatus: decision.ReviewStatus,
+		ReviewedBy:   decision.ReviewedBy,
+		ReviewNote:   decision.ReviewNote,
+		CreatedAt:    shared.FormatTimestamp(decision.CreatedAt),
+	}
+	if decision.ReviewedAt != nil {
+		result.ReviewedAt = shared.StringPtr(shared.FormatTimestamp(*decision.ReviewedAt))
 	}
+	return result
 }
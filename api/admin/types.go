@@ -1,5 +1,12 @@
 package admin
 
+import (
+	"github.com/aiw3/nft-solana-api/antigaming"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/volume"
+)
+
 // ==========================================
 // ADMIN TYPES
 // ==========================================
@@ -32,6 +39,13 @@ type AwardCompetitionNftsResponse struct {
 	Data    AwardCompetitionNftsData `json:"data" description:"Competition NFT award operation details"`
 }
 
+// GetNftJobStatsResponse represents the NFT job queue stats response
+type GetNftJobStatsResponse struct {
+	Code    int               `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message string            `json:"message" example:"Success" description:"Human-readable message describing the operation result"`
+	Data    coordinator.Stats `json:"data" description:"Queue depth, in-flight jobs and counters"`
+}
+
 // GetCompetitionNftLeaderboardResponse represents competition leaderboard response
 type GetCompetitionNftLeaderboardResponse struct {
 	Code    int                           `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
@@ -59,12 +73,12 @@ type GetAllUsersNftStatusResponse struct {
 
 // AdminUserNftStatus represents user NFT status for admin
 type AdminUserNftStatus struct {
-	UserID             int     `json:"userId"`
-	Username           string  `json:"username"`
-	WalletAddress      string  `json:"walletAddress"`
-	CurrentNftLevel    *int    `json:"currentNftLevel"`
-	NftStatus          string  `json:"nftStatus"`
-	TotalTradingVolume float64 `json:"totalTradingVolume"`
+	UserID             int          `json:"userId"`
+	Username           string       `json:"username"`
+	WalletAddress      string       `json:"walletAddress"`
+	CurrentNftLevel    *int         `json:"currentNftLevel"`
+	NftStatus          string       `json:"nftStatus"`
+	TotalTradingVolume money.Amount `json:"totalTradingVolume"`
 }
 
 // AdminPagination represents admin pagination
@@ -98,11 +112,11 @@ type AdminUsersNftStatusData struct {
 
 // AdminNftStatistics represents admin NFT statistics
 type AdminNftStatistics struct {
-	TotalUsers           int     `json:"totalUsers"`
-	UsersWithActiveNfts  int     `json:"usersWithActiveNfts"`
-	UsersWithoutNfts     int     `json:"usersWithoutNfts"`
-	AverageTradingVolume float64 `json:"averageTradingVolume"`
-	HighestNftLevel      int     `json:"highestNftLevel"`
+	TotalUsers           int          `json:"totalUsers"`
+	UsersWithActiveNfts  int          `json:"usersWithActiveNfts"`
+	UsersWithoutNfts     int          `json:"usersWithoutNfts"`
+	AverageTradingVolume money.Amount `json:"averageTradingVolume"`
+	HighestNftLevel      int          `json:"highestNftLevel"`
 }
 
 // ==========================================
@@ -213,10 +227,10 @@ type ListAvatarsResponse struct {
 
 // ListAvatarsData represents avatars list data
 type ListAvatarsData struct {
-	Avatars    []ProfileAvatar            `json:"avatars"`
-	TotalCount int                        `json:"totalCount"`
-	Stats      map[string]interface{}     `json:"stats"`
-	Pagination Pagination                 `json:"pagination"`
+	Avatars    []ProfileAvatar        `json:"avatars"`
+	TotalCount int                    `json:"totalCount"`
+	Stats      map[string]interface{} `json:"stats"`
+	Pagination Pagination             `json:"pagination"`
 }
 
 // UpdateAvatarResponse represents avatar update response
@@ -247,3 +261,322 @@ type DeleteAvatarData struct {
 	UsersAffected []int         `json:"usersAffected,omitempty"`
 	ForceDeleted  bool          `json:"forceDeleted,omitempty"`
 }
+
+// ==========================================
+// ADMIN TASK GUARD TYPES
+// ==========================================
+
+// GuardDecision is one recorded anti-gaming decision on a badge task completion
+type GuardDecision struct {
+	ID           int     `json:"id" example:"42" description:"Decision ID; review decisions are resolved by this ID"`
+	UserID       int     `json:"userId" example:"12345" description:"User who submitted the completion"`
+	TaskID       int     `json:"taskId" example:"109" description:"Badge task ID"`
+	Outcome      string  `json:"outcome" enum:"allowed,rejected,review" example:"rejected" description:"Decision taken on the attempt"`
+	Rule         string  `json:"rule,omitempty" example:"rate_limit" description:"Rule that rejected or held the attempt"`
+	Reason       string  `json:"reason,omitempty" example:"5 attempts on task 109 within 1h0m0s; the limit is 5" description:"Human-readable reason"`
+	EvidenceHash string  `json:"evidenceHash,omitempty" description:"SHA-256 of the submitted evidence"`
+	Progress     int     `json:"progress" example:"100" description:"Verified progress of allowed and held attempts"`
+	ReviewStatus string  `json:"reviewStatus,omitempty" enum:"pending,approved,rejected" description:"Review state of held attempts"`
+	ReviewedBy   string  `json:"reviewedBy,omitempty" example:"SuperAdmin" description:"Admin who resolved the review"`
+	ReviewNote   string  `json:"reviewNote,omitempty" description:"Note left by the reviewing admin"`
+	ReviewedAt   *string `json:"reviewedAt,omitempty" description:"When the review was resolved"`
+	CreatedAt    string  `json:"createdAt" description:"When the decision was taken"`
+}
+
+// ListGuardDecisionsResponse represents the task guard decision log response
+type ListGuardDecisionsResponse struct {
+	Code    int                    `json:"code" example:"200"`
+	Message string                 `json:"message" example:"Success"`
+	Data    ListGuardDecisionsData `json:"data"`
+}
+
+// ListGuardDecisionsData represents the task guard decision log
+type ListGuardDecisionsData struct {
+	Decisions  []GuardDecision `json:"decisions" description:"Matching decisions, newest first"`
+	TotalCount int             `json:"totalCount" description:"Number of matching decisions"`
+}
+
+// ResolveGuardReviewResponse represents a review resolution response
+type ResolveGuardReviewResponse struct {
+	Code    int                    `json:"code" example:"200"`
+	Message string                 `json:"message" example:"Review 42 approved by admin SuperAdmin"`
+	Data    ResolveGuardReviewData `json:"data"`
+}
+
+// ResolveGuardReviewData represents a resolved review
+type ResolveGuardReviewData struct {
+	Decision    GuardDecision `json:"decision" description:"The resolved decision"`
+	BadgeEarned bool          `json:"badgeEarned" description:"Whether approving awarded the task's badge"`
+}
+
+// GetGuardRulesResponse represents the task guard rules response
+type GetGuardRulesResponse struct {
+	Code    int              `json:"code" example:"200"`
+	Message string           `json:"message" example:"Success"`
+	Data    antigaming.Rules `json:"data" description:"Rules in force"`
+}
+
+// ==========================================
+// ADMIN AI AGENT USAGE TYPES
+// ==========================================
+
+// AiAgentUsageWeek is a user's AI agent usage in one quota week
+type AiAgentUsageWeek struct {
+	WeekStart string `json:"weekStart" example:"2024-02-19T00:00:00Z" description:"When the quota week started"`
+	ResetsAt  string `json:"resetsAt" example:"2024-02-26T00:00:00Z" description:"When the quota week ended or ends"`
+	NftLevel  int    `json:"nftLevel" example:"3" description:"Active NFT level at the last consume of the week"`
+	Quota     int    `json:"quota" example:"30" description:"Weekly quota of that level"`
+	Consumed  int    `json:"consumed" example:"7" description:"Uses consumed"`
+	Refunded  int    `json:"refunded" example:"2" description:"Uses given back"`
+	Used      int    `json:"used" example:"5" description:"Uses in effect: consumed minus refunded"`
+	UpdatedAt string `json:"updatedAt" description:"Last consume or refund of the week"`
+}
+
+// GetUserAiAgentUsageResponse represents a user's AI agent usage history response
+type GetUserAiAgentUsageResponse struct {
+	Code    int                     `json:"code" example:"200"`
+	Message string                  `json:"message" example:"Success"`
+	Data    GetUserAiAgentUsageData `json:"data"`
+}
+
+// GetUserAiAgentUsageData represents a user's current AI agent quota and usage per week
+type GetUserAiAgentUsageData struct {
+	UserID          int                `json:"userId" example:"12345"`
+	NftLevel        int                `json:"nftLevel" example:"3" description:"Active NFT level the current quota comes from"`
+	WeeklyQuota     int                `json:"weeklyQuota" example:"30" description:"Uses per week granted by the active NFT level"`
+	WeeklyUsed      int                `json:"weeklyUsed" example:"5" description:"Uses in effect this week"`
+	WeeklyRemaining int                `json:"weeklyRemaining" example:"25" description:"Uses left this week"`
+	ResetsAt        string             `json:"resetsAt" example:"2024-02-26T00:00:00Z" description:"When the current week's quota resets"`
+	ResetPolicy     string             `json:"resetPolicy" example:"Monday 00:00 UTC" description:"Weekly moment quotas reset"`
+	Weeks           []AiAgentUsageWeek `json:"weeks" description:"Usage per quota week, newest first; weeks without usage are omitted"`
+}
+
+// FeeLedgerEntry is one executed trade in a user's fee ledger
+type FeeLedgerEntry struct {
+	ID              int          `json:"id" example:"1042"`
+	Platform        string       `json:"platform" example:"okx"`
+	TradeID         string       `json:"tradeId" example:"okx-8837461234"`
+	PlatformWallet  string       `json:"platformWallet" example:"0x8f2a4c1e9b7d3a5f6e0c2b4d8a1f3e5c7b9d0a2e"`
+	Notional        money.Amount `json:"notional" example:"25000" description:"Trade value in USDT"`
+	FeeRate         float64      `json:"feeRate" example:"0.0005" description:"Platform fee rate before the NFT fee reduction"`
+	UndiscountedFee money.Amount `json:"undiscountedFee" example:"12.5"`
+	ChargedFee      money.Amount `json:"chargedFee" example:"9.375"`
+	FeeSaved        money.Amount `json:"feeSaved" example:"3.125"`
+	FeeReduction    int          `json:"feeReduction" example:"25" description:"Fee reduction percentage in effect at trade time"`
+	NftType         string       `json:"nftType,omitempty" example:"tiered" description:"Kind of the NFT providing the reduction; absent when none was in effect"`
+	UserNftID       int          `json:"userNftId,omitempty" example:"456"`
+	ExecutedAt      string       `json:"executedAt" example:"2024-02-20T14:30:00Z"`
+	RecordedAt      string       `json:"recordedAt" example:"2024-02-20T14:30:02Z"`
+}
+
+// PlatformFeeSaved is the fee a user saved on one platform account
+type PlatformFeeSaved struct {
+	Platform       string       `json:"platform" example:"okx"`
+	PlatformWallet string       `json:"platformWallet" example:"0x8f2a4c1e9b7d3a5f6e0c2b4d8a1f3e5c7b9d0a2e"`
+	FeeSaved       money.Amount `json:"feeSaved" example:"900"`
+	Trades         int          `json:"trades" example:"312"`
+}
+
+// GetUserFeeLedgerResponse represents a user's fee ledger response
+type GetUserFeeLedgerResponse struct {
+	Code    int                  `json:"code" example:"200"`
+	Message string               `json:"message" example:"Success"`
+	Data    GetUserFeeLedgerData `json:"data"`
+}
+
+// GetUserFeeLedgerData represents a user's fee savings per platform and a page of ledger entries
+type GetUserFeeLedgerData struct {
+	UserID     int                `json:"userId" example:"12345"`
+	TotalSaved money.Amount       `json:"totalSaved" example:"1250.75" description:"Fee saved across all platforms in USDT"`
+	Platforms  []PlatformFeeSaved `json:"platforms" description:"Fee saved per platform account"`
+	Entries    []FeeLedgerEntry   `json:"entries" description:"Ledger entries, latest trade first"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+// PlatformVolume is a user's trading volume on one platform
+type PlatformVolume struct {
+	Platform string       `json:"platform" example:"okx"`
+	Volume   money.Amount `json:"volume" example:"2500000" description:"Trading volume in USDT"`
+	Trades   int          `json:"trades" example:"412"`
+}
+
+// DailyVolume is a user's trading volume rollup for one platform and UTC day
+type DailyVolume struct {
+	Day      string       `json:"day" example:"2024-02-20" description:"UTC day"`
+	Platform string       `json:"platform" example:"okx"`
+	Volume   money.Amount `json:"volume" example:"125000" description:"Trading volume in USDT"`
+	Trades   int          `json:"trades" example:"18"`
+}
+
+// VolumeDrift is a difference reconciliation found between stored volume and raw trades
+type VolumeDrift struct {
+	ID           int          `json:"id" example:"7"`
+	Kind         string       `json:"kind" example:"rollup" enum:"rollup,qualifying" description:"rollup: a daily rollup differs from its raw trades; qualifying: the user's trading volume differs from all their raw trades"`
+	UserID       int          `json:"userId" example:"12345"`
+	Day          string       `json:"day,omitempty" example:"2024-02-20" description:"UTC day of a rollup drift"`
+	Platform     string       `json:"platform,omitempty" example:"okx" description:"Platform of a rollup drift"`
+	StoredVolume money.Amount `json:"storedVolume" example:"125000"`
+	RawVolume    money.Amount `json:"rawVolume" example:"124000"`
+	StoredTrades int          `json:"storedTrades" example:"18"`
+	RawTrades    int          `json:"rawTrades" example:"17"`
+	DetectedAt   string       `json:"detectedAt" example:"2024-02-21T03:00:01Z"`
+	ResolvedAt   *string      `json:"resolvedAt,omitempty" example:"2024-02-21T09:12:44Z"`
+}
+
+// VolumeTrade is a raw trade counted towards a user's trading volume, with the price it was converted at
+type VolumeTrade struct {
+	ID            int          `json:"id" example:"3051"`
+	Platform      string       `json:"platform" example:"jupiter"`
+	TradeID       string       `json:"tradeId" example:"5VfYmGB8kTz3qW1n"`
+	Source        string       `json:"source" example:"onchain" description:"Adapter the trade came through"`
+	Notional      money.Amount `json:"notional" example:"370.3" description:"Notional counted towards volume, in USDT"`
+	Asset         string       `json:"asset" example:"SOL" description:"Asset the trade settled in"`
+	AssetNotional money.Amount `json:"assetNotional" example:"\"SOL:2.5\"" description:"Notional in the asset the trade settled in, prefixed with it unless it is USDT"`
+	Price         money.Amount `json:"price" example:"148.12" description:"USDT price of one unit of the asset the notional was converted at; 1 for trades in USDT"`
+	PriceSource   string       `json:"priceSource,omitempty" example:"pyth" description:"Oracle that quoted the price; absent for trades in USDT"`
+	PricedAt      *string      `json:"pricedAt,omitempty" example:"2024-02-20T14:30:00Z" description:"When the price was published; absent for trades in USDT"`
+	ExecutedAt    string       `json:"executedAt" example:"2024-02-20T14:30:12Z"`
+	IngestedAt    string       `json:"ingestedAt" example:"2024-02-20T14:31:02Z"`
+}
+
+// ListUserVolumeTradesResponse represents a page of a user's raw volume trades
+type ListUserVolumeTradesResponse struct {
+	Code    int                      `json:"code" example:"200"`
+	Message string                   `json:"message" example:"Success"`
+	Data    ListUserVolumeTradesData `json:"data"`
+}
+
+// ListUserVolumeTradesData represents a page of a user's raw volume trades
+type ListUserVolumeTradesData struct {
+	UserID     int           `json:"userId" example:"12345"`
+	Trades     []VolumeTrade `json:"trades" description:"Raw trades, latest first"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+// GetUserTradingVolumeResponse represents a user's trading volume response
+type GetUserTradingVolumeResponse struct {
+	Code    int                      `json:"code" example:"200"`
+	Message string                   `json:"message" example:"Success"`
+	Data    GetUserTradingVolumeData `json:"data"`
+}
+
+// GetUserTradingVolumeData represents a user's qualifying volume with its rollups and open drifts
+type GetUserTradingVolumeData struct {
+	UserID           int              `json:"userId" example:"12345"`
+	QualifyingVolume money.Amount     `json:"qualifyingVolume" example:"2850000" description:"Trading volume tier decisions use, in USDT"`
+	Platforms        []PlatformVolume `json:"platforms" description:"Volume per platform from the rollups"`
+	Daily            []DailyVolume    `json:"daily" description:"Daily rollups of the requested days, latest first"`
+	OpenDrifts       []VolumeDrift    `json:"openDrifts" description:"Open reconciliation drifts of the user"`
+}
+
+// RecomputeUserTradingVolumeResponse represents the result of recomputing a user's trading volume
+type RecomputeUserTradingVolumeResponse struct {
+	Code    int                            `json:"code" example:"200"`
+	Message string                         `json:"message" example:"Trading volume recomputed from raw trades"`
+	Data    RecomputeUserTradingVolumeData `json:"data"`
+}
+
+// RecomputeUserTradingVolumeData represents a user's trading volume before and after recomputing it
+type RecomputeUserTradingVolumeData struct {
+	UserID           int          `json:"userId" example:"12345"`
+	PreviousVolume   money.Amount `json:"previousVolume" example:"2851000"`
+	QualifyingVolume money.Amount `json:"qualifyingVolume" example:"2850000"`
+	ResolvedDrifts   int          `json:"resolvedDrifts" example:"1"`
+}
+
+// ListVolumeDriftsResponse represents the reconciliation drift list response
+type ListVolumeDriftsResponse struct {
+	Code    int                  `json:"code" example:"200"`
+	Message string               `json:"message" example:"Success"`
+	Data    ListVolumeDriftsData `json:"data"`
+}
+
+// ListVolumeDriftsData represents reconciliation drifts, newest first
+type ListVolumeDriftsData struct {
+	Drifts []VolumeDrift `json:"drifts"`
+}
+
+// ReconcileVolumeResponse represents the outcome of a reconciliation run
+type ReconcileVolumeResponse struct {
+	Code    int           `json:"code" example:"200"`
+	Message string        `json:"message" example:"Reconciliation found 1 new drifts; 2 open"`
+	Data    volume.Report `json:"data"`
+}
+
+// ==========================================
+// ADMIN BADGE CATALOG TYPES
+// ==========================================
+
+// CatalogRequirement is one requirement rule of a catalog badge
+type CatalogRequirement struct {
+	Type  string `json:"type" required:"true" example:"invite_friend" description:"Requirement (task) type with a registered verifier"`
+	Value int    `json:"value" required:"true" example:"2" minimum:"1" description:"Value the user must reach"`
+}
+
+// CatalogTask is the task that awards a catalog badge
+type CatalogTask struct {
+	ID   int    `json:"id" example:"109" description:"Task ID"`
+	Name string `json:"name" example:"Invite 2 Friends" description:"Task name shown to users"`
+	Type string `json:"type" example:"invite_friend" description:"Task type; one of the badge's requirement types"`
+}
+
+// CatalogBadge is a badge definition as managed by admins
+type CatalogBadge struct {
+	ID                    int                  `json:"id" example:"20"`
+	NftLevel              int                  `json:"nftLevel" example:"2" description:"NFT level the badge counts towards"`
+	Name                  string               `json:"name" example:"Community Builder"`
+	Description           string               `json:"description"`
+	Category              string               `json:"category" example:"Community"`
+	Level                 int                  `json:"level" example:"2" description:"Badge level/tier"`
+	IconURL               string               `json:"iconUrl" example:"https://cdn.aiw3.com/badges/badge-20.png"`
+	ContributionValue     float64              `json:"contributionValue" example:"1" description:"Points the badge contributes toward NFT upgrades"`
+	ActivationWindowHours *int                 `json:"activationWindowHours,omitempty" example:"168" description:"Hours an activation counts towards upgrades; 0 never expires, omitted uses the NFT level window"`
+	Requirements          []CatalogRequirement `json:"requirements" description:"Every requirement must be met to earn the badge"`
+	Task                  CatalogTask          `json:"task" description:"Task that awards the badge"`
+	SortOrder             int                  `json:"sortOrder" example:"3" description:"Position within its NFT level"`
+	Version               int                  `json:"version" example:"2" description:"Definition version; bumped by every change"`
+	Retired               bool                 `json:"retired" description:"Retired badges can no longer be earned"`
+	RetiredAt             *string              `json:"retiredAt,omitempty" description:"When the badge was retired"`
+}
+
+// ListCatalogBadgesResponse represents the admin badge catalog response
+type ListCatalogBadgesResponse struct {
+	Code    int                   `json:"code" example:"200"`
+	Message string                `json:"message" example:"Success"`
+	Data    ListCatalogBadgesData `json:"data"`
+}
+
+// ListCatalogBadgesData represents the admin badge catalog
+type ListCatalogBadgesData struct {
+	Badges     []CatalogBadge `json:"badges" description:"Badges ordered by NFT level, then sort order"`
+	TotalCount int            `json:"totalCount"`
+}
+
+// CatalogBadgeResponse represents a created, updated or retired catalog badge
+type CatalogBadgeResponse struct {
+	Code    int          `json:"code" example:"200"`
+	Message string       `json:"message" example:"Badge 20 updated to version 2 by admin SuperAdmin"`
+	Data    CatalogBadge `json:"data"`
+}
+
+// ReorderCatalogBadgesResponse represents a reordered NFT level
+type ReorderCatalogBadgesResponse struct {
+	Code    int                   `json:"code" example:"200"`
+	Message string                `json:"message" example:"Level 2 badges reordered by admin SuperAdmin"`
+	Data    ListCatalogBadgesData `json:"data"`
+}
+
+// ListCatalogBadgeVersionsResponse represents the version history of a catalog badge
+type ListCatalogBadgeVersionsResponse struct {
+	Code    int                          `json:"code" example:"200"`
+	Message string                       `json:"message" example:"Success"`
+	Data    ListCatalogBadgeVersionsData `json:"data"`
+}
+
+// ListCatalogBadgeVersionsData represents the version history of a catalog badge
+type ListCatalogBadgeVersionsData struct {
+	BadgeID          int            `json:"badgeId" example:"20"`
+	CurrentVersion   int            `json:"currentVersion" example:"2"`
+	Versions         []CatalogBadge `json:"versions" description:"Recorded versions, oldest first"`
+	HoldersByVersion map[string]int `json:"holdersByVersion" description:"Number of users holding the badge under each version (keys are versions)"`
+}
@@ -0,0 +1,282 @@
+package aiquota
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
+)
+
+// ==========================================
+// RESET SCHEDULE
+// ==========================================
+
+// Schedule is the weekly moment AI agent quotas reset, as wall-clock time in a timezone
+type Schedule struct {
+	Weekday  time.Weekday
+	Hour     int
+	Minute   int
+	Location *time.Location
+}
+
+// DefaultSchedule resets quotas every Monday at 00:00 UTC
+func DefaultSchedule() Schedule {
+	return Schedule{Weekday: time.Monday, Location: time.UTC}
+}
+
+// ParseSchedule parses "<weekday> <HH:MM> [<IANA timezone>]", e.g. "Monday 00:00 Asia/Shanghai".
+// Weekdays may be abbreviated to three letters; the timezone defaults to UTC.
+func ParseSchedule(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) < 2 || len(fields) > 3 {
+		return Schedule{}, fmt.Errorf("reset schedule %q: want \"<weekday> <HH:MM> [<timezone>]\"", spec)
+	}
+
+	schedule := Schedule{Location: time.UTC}
+	weekday, ok := parseWeekday(fields[0])
+	if !ok {
+		return Schedule{}, fmt.Errorf("reset schedule %q: unknown weekday %q", spec, fields[0])
+	}
+	schedule.Weekday = weekday
+
+	clock, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return Schedule{}, fmt.Errorf("reset schedule %q: time must be HH:MM", spec)
+	}
+	schedule.Hour, schedule.Minute = clock.Hour(), clock.Minute()
+
+	if len(fields) == 3 {
+		if schedule.Location, err = time.LoadLocation(fields[2]); err != nil {
+			return Schedule{}, fmt.Errorf("reset schedule %q: %w", spec, err)
+		}
+	}
+	return schedule, nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(name)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// WeekStart returns the last reset at or before t
+func (s Schedule) WeekStart(t time.Time) time.Time {
+	local := t.In(s.Location)
+	days := (int(local.Weekday()) - int(s.Weekday) + 7) % 7
+	start := time.Date(local.Year(), local.Month(), local.Day()-days, s.Hour, s.Minute, 0, 0, s.Location)
+	if start.After(t) {
+		start = time.Date(local.Year(), local.Month(), local.Day()-days-7, s.Hour, s.Minute, 0, 0, s.Location)
+	}
+	return start
+}
+
+// NextReset returns the first reset after t. Resets follow the wall clock, so a week spanning a
+// daylight saving change is an hour shorter or longer.
+func (s Schedule) NextReset(t time.Time) time.Time {
+	start := s.WeekStart(t)
+	return time.Date(start.Year(), start.Month(), start.Day()+7, s.Hour, s.Minute, 0, 0, s.Location)
+}
+
+// String returns the schedule in the form accepted by ParseSchedule
+func (s Schedule) String() string {
+	return fmt.Sprintf("%s %02d:%02d %s", s.Weekday, s.Hour, s.Minute, s.Location)
+}
+
+// ==========================================
+// QUOTA ERRORS
+// ==========================================
+
+// ErrorCode identifies why a quota operation was refused
+type ErrorCode string
+
+const (
+	ErrCodeInvalidUses     ErrorCode = "AI_QUOTA_INVALID_USES"
+	ErrCodeNotIncluded     ErrorCode = "AI_AGENT_NOT_INCLUDED"
+	ErrCodeExhausted       ErrorCode = "AI_QUOTA_EXHAUSTED"
+	ErrCodeConsumeNotFound ErrorCode = "AI_QUOTA_CONSUME_NOT_FOUND"
+	ErrCodeAlreadyRefunded ErrorCode = "AI_QUOTA_ALREADY_REFUNDED"
+)
+
+// Error is returned when a consume or refund is refused; Quota is the user's quota at that moment
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Quota   *Quota
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// ==========================================
+// QUOTA SERVICE
+// ==========================================
+
+// Quota is a user's AI agent allowance in one quota week
+type Quota struct {
+	UserID      int
+	Level       int // active NFT level, 0 without an active NFT
+	WeeklyTotal int // uses per week granted by the level
+	Used        int // uses in effect: consumed minus refunded
+	Remaining   int
+	WeekStart   time.Time
+	ResetsAt    time.Time
+}
+
+// Service meters AI agent uses against the weekly quota of the user's active NFT level
+type Service struct {
+	store    *repository.Store
+	schedule Schedule
+	now      func() time.Time
+}
+
+// New creates a quota service resetting quotas on schedule
+func New(store *repository.Store, schedule Schedule) *Service {
+	return &Service{store: store, schedule: schedule, now: time.Now}
+}
+
+// Schedule returns the reset schedule in force
+func (s *Service) Schedule() Schedule {
+	return s.schedule
+}
+
+// Get returns the user's quota for the current week
+func (s *Service) Get(ctx context.Context, userID int) (*Quota, error) {
+	level, total, err := s.weeklyQuota(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	weekStart := s.schedule.WeekStart(s.now())
+	usage, err := s.store.AiQuota.Get(ctx, userID, weekStart)
+	if err != nil {
+		return nil, err
+	}
+	return s.quota(usage, level, total), nil
+}
+
+// Consume records uses against the current week and returns the quota left with the ID of the consume,
+// which a refund names. It fails with ErrCodeNotIncluded when the active NFT level has no AI agent quota
+// and with ErrCodeExhausted when the uses do not fit in what is left.
+func (s *Service) Consume(ctx context.Context, userID, uses int) (*Quota, string, error) {
+	if uses < 1 {
+		return nil, "", &Error{Code: ErrCodeInvalidUses, Message: "Uses must be at least 1"}
+	}
+	level, total, err := s.weeklyQuota(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	weekStart := s.schedule.WeekStart(s.now())
+	if total == 0 {
+		usage, err := s.store.AiQuota.Get(ctx, userID, weekStart)
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, "", &Error{
+			Code:    ErrCodeNotIncluded,
+			Message: "AI agent access requires an active NFT whose level includes it",
+			Quota:   s.quota(usage, level, total),
+		}
+	}
+	consumeID, err := newConsumeID()
+	if err != nil {
+		return nil, "", err
+	}
+	usage, ok, err := s.store.AiQuota.Consume(ctx, consumeID, userID, weekStart, uses, level, total)
+	if err != nil {
+		return nil, "", err
+	}
+	quota := s.quota(usage, level, total)
+	if !ok {
+		return nil, "", &Error{
+			Code: ErrCodeExhausted,
+			Message: fmt.Sprintf("%d uses requested but %d of %d weekly uses are left until %s",
+				uses, quota.Remaining, total, quota.ResetsAt.UTC().Format(time.RFC3339)),
+			Quota: quota,
+		}
+	}
+	return quota, consumeID, nil
+}
+
+// Refund gives back the uses of an earlier consume, e.g. after the AI agent failed to answer, and returns
+// the quota of the consume's week. A consume is refunded at most once: it fails with ErrCodeConsumeNotFound
+// when no consume has the ID and with ErrCodeAlreadyRefunded when it was refunded before.
+func (s *Service) Refund(ctx context.Context, consumeID string) (*Quota, error) {
+	usage, ok, err := s.store.AiQuota.Refund(ctx, consumeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, &Error{Code: ErrCodeConsumeNotFound, Message: fmt.Sprintf("No AI agent consume %q", consumeID)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	level, total, err := s.weeklyQuota(ctx, usage.UserID)
+	if err != nil {
+		return nil, err
+	}
+	quota := s.quota(usage, level, total)
+	if !ok {
+		return nil, &Error{
+			Code:    ErrCodeAlreadyRefunded,
+			Message: fmt.Sprintf("AI agent consume %q was already refunded", consumeID),
+			Quota:   quota,
+		}
+	}
+	return quota, nil
+}
+
+// History returns the user's usage per quota week, newest first; weeks 0 returns every recorded week
+func (s *Service) History(ctx context.Context, userID, weeks int) ([]repository.AiAgentUsage, error) {
+	return s.store.AiQuota.ListByUser(ctx, userID, weeks)
+}
+
+// newConsumeID returns a random ID for a consume
+func newConsumeID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// weeklyQuota returns the user's active NFT level and the weekly uses it grants
+func (s *Service) weeklyQuota(ctx context.Context, userID int) (int, int, error) {
+	nfts, err := s.store.TieredNfts.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	level := lifecycle.New(userID, nfts).ActiveLevel()
+	tier, ok := tiers.Current().Tier(level)
+	if !ok {
+		return level, 0, nil
+	}
+	return level, tier.AiAgentWeeklyQuota, nil
+}
+
+// quota reports a week's usage against the quota of the current level. A week in the past reports the
+// quota recorded with it, so an upgrade does not rewrite history.
+func (s *Service) quota(usage *repository.AiAgentUsage, level, total int) *Quota {
+	if usage.WeekStart.Before(s.schedule.WeekStart(s.now())) && usage.Quota > 0 {
+		level, total = usage.Level, usage.Quota
+	}
+	quota := &Quota{
+		UserID:      usage.UserID,
+		Level:       level,
+		WeeklyTotal: total,
+		Used:        usage.Consumed - usage.Refunded,
+		WeekStart:   usage.WeekStart,
+		ResetsAt:    s.schedule.NextReset(usage.WeekStart),
+	}
+	quota.Remaining = max(quota.WeeklyTotal-quota.Used, 0)
+	return quota
+}
@@ -0,0 +1,90 @@
+package aiquota
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+	"github.com/aiw3/nft-solana-api/repository/seed"
+	"github.com/aiw3/nft-solana-api/repository/sqlite"
+)
+
+// seededUser has an active NFT whose level includes AI agent uses
+const seededUser = 12345
+
+// stores returns a seeded memory and SQLite store by name
+func stores(t *testing.T) map[string]*repository.Store {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "aiw3.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	stores := map[string]*repository.Store{"memory": memory.NewStore(), "sqlite": sqlite.NewStore(db)}
+	for name, store := range stores {
+		if err := seed.Load(ctx, store); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	return stores
+}
+
+func errorCode(err error) ErrorCode {
+	var quotaErr *Error
+	if errors.As(err, &quotaErr) {
+		return quotaErr.Code
+	}
+	return ""
+}
+
+func TestRefundGivesBackOneConsumeOnce(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			quotas := New(store, DefaultSchedule())
+			before, err := quotas.Get(ctx, seededUser)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, first, err := quotas.Consume(ctx, seededUser, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			consumed, second, err := quotas.Consume(ctx, seededUser, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first == "" || first == second {
+				t.Fatalf("consume IDs %q and %q, want distinct IDs", first, second)
+			}
+			if consumed.Used != before.Used+3 {
+				t.Fatalf("used %d after consuming 3, want %d", consumed.Used, before.Used+3)
+			}
+
+			refunded, err := quotas.Refund(ctx, first)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refunded.Used != before.Used+1 {
+				t.Errorf("used %d after refunding the first consume, want %d", refunded.Used, before.Used+1)
+			}
+			if _, err := quotas.Refund(ctx, first); errorCode(err) != ErrCodeAlreadyRefunded {
+				t.Errorf("second refund: %v, want %s", err, ErrCodeAlreadyRefunded)
+			}
+			if _, err := quotas.Refund(ctx, "unknown"); errorCode(err) != ErrCodeConsumeNotFound {
+				t.Errorf("refund of an unknown consume: %v, want %s", err, ErrCodeConsumeNotFound)
+			}
+			after, err := quotas.Get(ctx, seededUser)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after.Used != before.Used+1 {
+				t.Errorf("used %d after the refused refunds, want %d", after.Used, before.Used+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package antigaming
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// ANTI-GAMING GUARD
+// ==========================================
+
+// Rules recorded with every rejected or held decision
+const (
+	RuleRateLimit    = "rate_limit"
+	RuleDuplicate    = "duplicate"
+	RuleReplay       = "replay"
+	RulePrerequisite = "prerequisite"
+	RuleOutlier      = "outlier_progress"
+	RuleEvidence     = "evidence"
+)
+
+// Attempt is one badge task completion request
+type Attempt struct {
+	UserID       int
+	Task         *repository.Task
+	Requirements []repository.Requirement
+	Evidence     map[string]interface{}
+}
+
+// Guard sits in front of badge task completion. Admit runs before the task is verified and rejects
+// attempts that are rate limited, duplicated, replayed or too close to their prerequisites; Screen runs
+// after verification and holds outlier progress jumps for review. Every decision is recorded in the store.
+type Guard struct {
+	store *repository.Store
+	rules Rules
+	now   func() time.Time
+}
+
+// New creates a guard enforcing rules
+func New(store *repository.Store, rules Rules) *Guard {
+	return &Guard{store: store, rules: rules, now: time.Now}
+}
+
+// Rules returns the rules in force
+func (g *Guard) Rules() Rules {
+	return g.rules
+}
+
+// Admit checks the attempt before verification. It returns the recorded rejection, or nil when the
+// attempt may be verified.
+func (g *Guard) Admit(ctx context.Context, attempt Attempt) (*repository.GuardDecision, error) {
+	now := g.now()
+	hash, err := evidenceHash(attempt.Task.ID, attempt.Evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, reason, err := g.check(ctx, attempt, hash, now)
+	if err != nil || rule == "" {
+		return nil, err
+	}
+	return g.record(ctx, &repository.GuardDecision{
+		UserID:       attempt.UserID,
+		TaskID:       attempt.Task.ID,
+		Outcome:      repository.GuardOutcomeRejected,
+		Rule:         rule,
+		Reason:       reason,
+		EvidenceHash: hash,
+		CreatedAt:    now,
+	})
+}
+
+// Reject records an attempt rejected after admission, e.g. because its evidence contradicts the server
+func (g *Guard) Reject(ctx context.Context, attempt Attempt, rule, reason string) (*repository.GuardDecision, error) {
+	hash, err := evidenceHash(attempt.Task.ID, attempt.Evidence)
+	if err != nil {
+		return nil, err
+	}
+	return g.record(ctx, &repository.GuardDecision{
+		UserID:       attempt.UserID,
+		TaskID:       attempt.Task.ID,
+		Outcome:      repository.GuardOutcomeRejected,
+		Rule:         rule,
+		Reason:       reason,
+		EvidenceHash: hash,
+		CreatedAt:    g.now(),
+	})
+}
+
+// check returns the first rule the attempt breaks, "" when it breaks none
+func (g *Guard) check(ctx context.Context, attempt Attempt, hash string, now time.Time) (string, string, error) {
+	userID, taskID := attempt.UserID, attempt.Task.ID
+
+	if g.rules.RateLimitAttempts > 0 {
+		window := seconds(g.rules.RateLimitWindowSeconds)
+		attempts, err := g.store.TaskGuard.Count(ctx, repository.GuardDecisionFilter{
+			UserID: userID, TaskID: taskID, Since: now.Add(-window),
+		})
+		if err != nil {
+			return "", "", err
+		}
+		if attempts >= g.rules.RateLimitAttempts {
+			return RuleRateLimit, fmt.Sprintf("%d attempts on task %d within %s; the limit is %d",
+				attempts, taskID, window, g.rules.RateLimitAttempts), nil
+		}
+	}
+
+	progress, err := g.store.Tasks.GetProgress(ctx, userID, taskID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return "", "", err
+	}
+	if progress != nil && progress.Completed {
+		return RuleDuplicate, fmt.Sprintf("Task %d is already completed", taskID), nil
+	}
+	pending, err := g.store.TaskGuard.Count(ctx, repository.GuardDecisionFilter{
+		UserID: userID, TaskID: taskID, ReviewStatus: repository.ReviewPending,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if pending > 0 {
+		return RuleDuplicate, fmt.Sprintf("A completion of task %d is awaiting review", taskID), nil
+	}
+
+	if hash != "" && g.rules.ReplayWindowSeconds > 0 {
+		replays, err := g.store.TaskGuard.Count(ctx, repository.GuardDecisionFilter{
+			UserID: userID, TaskID: taskID, Outcome: repository.GuardOutcomeAllowed, EvidenceHash: hash,
+			Since: now.Add(-seconds(g.rules.ReplayWindowSeconds)),
+		})
+		if err != nil {
+			return "", "", err
+		}
+		if replays > 0 {
+			return RuleReplay, fmt.Sprintf("The same evidence for task %d was already submitted", taskID), nil
+		}
+	}
+
+	return g.checkPrerequisites(ctx, attempt, now)
+}
+
+// checkPrerequisites requires every prerequisite task to be completed at least the minimum interval ago
+func (g *Guard) checkPrerequisites(ctx context.Context, attempt Attempt, now time.Time) (string, string, error) {
+	prerequisites, err := g.prerequisites(ctx, attempt.Task)
+	if err != nil {
+		return "", "", err
+	}
+	interval := seconds(g.rules.PrerequisiteMinIntervalSeconds)
+	for _, prerequisite := range prerequisites {
+		progress, err := g.store.Tasks.GetProgress(ctx, attempt.UserID, prerequisite.ID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return "", "", err
+		}
+		if progress == nil || !progress.Completed || progress.CompletedAt == nil {
+			return RulePrerequisite, fmt.Sprintf("Complete %q before task %d", prerequisite.Name, attempt.Task.ID), nil
+		}
+		if wait := progress.CompletedAt.Add(interval).Sub(now); wait > 0 {
+			return RulePrerequisite, fmt.Sprintf("Task %d opens %s after completing %q; %s left",
+				attempt.Task.ID, interval, prerequisite.Name, wait.Round(time.Second)), nil
+		}
+	}
+	return "", "", nil
+}
+
+// prerequisites returns the configured prerequisites of a task plus the tasks of the same type with a lower
+// requirement value (e.g. "Invite a Friend" before "Invite 2 Friends")
+func (g *Guard) prerequisites(ctx context.Context, task *repository.Task) ([]repository.Task, error) {
+	tasks, err := g.store.Tasks.ListTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := g.store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defsByID := make(map[int]repository.BadgeDefinition, len(defs))
+	for _, def := range defs {
+		defsByID[def.ID] = def
+	}
+	// value returns the target of the task's own requirement, e.g. 2 for "Invite 2 Friends"
+	value := func(t repository.Task) int {
+		for _, requirement := range defsByID[t.BadgeID].Requirements {
+			if requirement.Type == t.Type {
+				return requirement.Value
+			}
+		}
+		return 1
+	}
+
+	configured := map[int]bool{}
+	for _, id := range g.rules.Prerequisites[task.ID] {
+		configured[id] = true
+	}
+	result := []repository.Task{}
+	for _, candidate := range tasks {
+		if candidate.ID == task.ID || defsByID[candidate.BadgeID].RetiredAt != nil {
+			continue
+		}
+		sameTypeLower := candidate.Type == task.Type && value(candidate) < value(*task)
+		if configured[candidate.ID] || sameTypeLower {
+			result = append(result, candidate)
+		}
+	}
+	return result, nil
+}
+
+// Screen records the verified attempt. A multi-step task whose progress jumps by more than MaxProgressJump
+// is held for review and must not be awarded; any other attempt is recorded as allowed.
+func (g *Guard) Screen(ctx context.Context, attempt Attempt, previous, progress int) (*repository.GuardDecision, error) {
+	hash, err := evidenceHash(attempt.Task.ID, attempt.Evidence)
+	if err != nil {
+		return nil, err
+	}
+	decision := &repository.GuardDecision{
+		UserID:       attempt.UserID,
+		TaskID:       attempt.Task.ID,
+		Outcome:      repository.GuardOutcomeAllowed,
+		EvidenceHash: hash,
+		Progress:     progress,
+		CreatedAt:    g.now(),
+	}
+
+	if jump := progress - previous; g.rules.MaxProgressJump > 0 && jump > g.rules.MaxProgressJump && multiStep(attempt.Requirements) {
+		decision.Outcome = repository.GuardOutcomeReview
+		decision.Rule = RuleOutlier
+		decision.Reason = fmt.Sprintf("Progress jumped from %d%% to %d%%; at most %d points are awarded without review",
+			previous, progress, g.rules.MaxProgressJump)
+		decision.ReviewStatus = repository.ReviewPending
+	}
+	return g.record(ctx, decision)
+}
+
+// Code returns the response code for a decision
+func Code(decision *repository.GuardDecision) int {
+	switch {
+	case decision.Outcome == repository.GuardOutcomeReview:
+		return 202
+	case decision.Outcome == repository.GuardOutcomeAllowed:
+		return 200
+	case decision.Rule == RuleRateLimit:
+		return 429
+	case decision.Rule == RulePrerequisite:
+		return 403
+	case decision.Rule == RuleEvidence:
+		return 400
+	default:
+		return 409
+	}
+}
+
+func (g *Guard) record(ctx context.Context, decision *repository.GuardDecision) (*repository.GuardDecision, error) {
+	if err := g.store.TaskGuard.Record(ctx, decision); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// multiStep reports whether a task has a requirement that is reached gradually; single-step tasks
+// (tutorials, profile) always go from 0 to 100 at once
+func multiStep(requirements []repository.Requirement) bool {
+	for _, requirement := range requirements {
+		if requirement.Value > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// evidenceHash identifies a task's evidence; encoding/json sorts map keys, so equal evidence hashes equally
+func evidenceHash(taskID int, evidence map[string]interface{}) (string, error) {
+	if len(evidence) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(evidence)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", taskID, data)))
+	return hex.EncodeToString(sum[:]), nil
+}
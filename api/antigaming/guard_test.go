@@ -0,0 +1,321 @@
+package antigaming
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+)
+
+const user = 1
+
+// Tasks of the test catalog: inviting two friends follows inviting one, the tutorial stands alone
+const (
+	taskInviteOne = 1
+	taskInviteTwo = 2
+	taskTutorial  = 3
+)
+
+// clock is a settable time for the guard
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time                 { return c.now }
+func (c *clock) Advance(d time.Duration)        { c.now = c.now.Add(d) }
+func (c *clock) Ago(d time.Duration) *time.Time { at := c.now.Add(-d); return &at }
+
+// newGuard returns a guard over a memory store holding the test catalog
+func newGuard(t *testing.T, rules Rules) (*Guard, *repository.Store, *clock) {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	catalog := []struct {
+		task        repository.Task
+		requirement repository.Requirement
+	}{
+		{repository.Task{ID: taskInviteOne, Name: "Invite a Friend", Type: "invite", BadgeID: 1},
+			repository.Requirement{Type: "invite", Value: 1}},
+		{repository.Task{ID: taskInviteTwo, Name: "Invite 2 Friends", Type: "invite", BadgeID: 2},
+			repository.Requirement{Type: "invite", Value: 2}},
+		{repository.Task{ID: taskTutorial, Name: "Tutorial", Type: "tutorial", BadgeID: 3},
+			repository.Requirement{Type: "tutorial", Value: 1}},
+	}
+	for _, entry := range catalog {
+		def := &repository.BadgeDefinition{ID: entry.task.BadgeID, NftLevel: 1, Name: entry.task.Name,
+			TaskID: entry.task.ID, Requirements: []repository.Requirement{entry.requirement}}
+		if err := store.Badges.SaveDefinition(ctx, def); err != nil {
+			t.Fatal(err)
+		}
+		task := entry.task
+		if err := store.Tasks.SaveTask(ctx, &task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	guard := New(store, rules)
+	c := &clock{now: time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)}
+	guard.now = c.Now
+	return guard, store, c
+}
+
+// attempt returns an attempt on the task with its catalog requirement
+func attempt(t *testing.T, store *repository.Store, taskID int, evidence map[string]interface{}) Attempt {
+	t.Helper()
+	task, err := store.Tasks.GetTask(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := store.Badges.GetDefinition(context.Background(), task.BadgeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Attempt{UserID: user, Task: task, Requirements: def.Requirements, Evidence: evidence}
+}
+
+// ruleOf returns the rule of a rejection, "" when the attempt was admitted
+func ruleOf(decision *repository.GuardDecision) string {
+	if decision == nil {
+		return ""
+	}
+	return decision.Rule
+}
+
+func TestAdmitRateLimitsAtTheLimit(t *testing.T) {
+	ctx := context.Background()
+	rules := Rules{RateLimitAttempts: 3, RateLimitWindowSeconds: 3600}
+	guard, store, clock := newGuard(t, rules)
+
+	for i := 1; i <= rules.RateLimitAttempts; i++ {
+		a := attempt(t, store, taskTutorial, nil)
+		decision, err := guard.Admit(ctx, a)
+		if err != nil || decision != nil {
+			t.Fatalf("attempt %d: rejected by %q (%v), want it admitted", i, ruleOf(decision), err)
+		}
+		if _, err := guard.Screen(ctx, a, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Minute)
+	}
+	decision, err := guard.Admit(ctx, attempt(t, store, taskTutorial, nil))
+	if err != nil || ruleOf(decision) != RuleRateLimit || Code(decision) != 429 {
+		t.Fatalf("attempt %d: rule %q (%v), want rate_limit with 429", rules.RateLimitAttempts+1, ruleOf(decision), err)
+	}
+	// Another task has its own limit
+	if decision, err := guard.Admit(ctx, attempt(t, store, taskInviteOne, nil)); err != nil || decision != nil {
+		t.Errorf("other task: rejected by %q (%v), want it admitted", ruleOf(decision), err)
+	}
+
+	// The attempts, the rejection included, leave the window
+	clock.Advance(time.Hour)
+	if decision, err := guard.Admit(ctx, attempt(t, store, taskTutorial, nil)); err != nil || decision != nil {
+		t.Errorf("after the window: rejected by %q (%v), want it admitted", ruleOf(decision), err)
+	}
+}
+
+func TestAdmitRejectsDuplicates(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("completed", func(t *testing.T) {
+		guard, store, clock := newGuard(t, DefaultRules())
+		progress := &repository.TaskProgress{UserID: user, TaskID: taskTutorial, Progress: 100, Completed: true,
+			CompletedAt: clock.Ago(time.Hour)}
+		if err := store.Tasks.SaveProgress(ctx, progress); err != nil {
+			t.Fatal(err)
+		}
+		decision, err := guard.Admit(ctx, attempt(t, store, taskTutorial, nil))
+		if err != nil || ruleOf(decision) != RuleDuplicate || Code(decision) != 409 {
+			t.Errorf("rule %q (%v), want duplicate with 409", ruleOf(decision), err)
+		}
+	})
+
+	t.Run("review pending", func(t *testing.T) {
+		guard, store, clock := newGuard(t, DefaultRules())
+		progress := &repository.TaskProgress{UserID: user, TaskID: taskInviteOne, Progress: 100, Completed: true,
+			CompletedAt: clock.Ago(2 * time.Hour)}
+		if err := store.Tasks.SaveProgress(ctx, progress); err != nil {
+			t.Fatal(err)
+		}
+		held, err := guard.Screen(ctx, attempt(t, store, taskInviteTwo, nil), 0, 100)
+		if err != nil || held.ReviewStatus != repository.ReviewPending {
+			t.Fatalf("screened %+v (%v), want it held for review", held, err)
+		}
+		decision, err := guard.Admit(ctx, attempt(t, store, taskInviteTwo, nil))
+		if err != nil || ruleOf(decision) != RuleDuplicate {
+			t.Errorf("rule %q (%v), want duplicate while the review is pending", ruleOf(decision), err)
+		}
+	})
+}
+
+func TestAdmitRejectsReplayedEvidence(t *testing.T) {
+	ctx := context.Background()
+	guard, store, clock := newGuard(t, Rules{ReplayWindowSeconds: 600})
+	evidence := map[string]interface{}{"tweetUrl": "https://x.com/aiw3/status/1"}
+
+	if _, err := guard.Screen(ctx, attempt(t, store, taskTutorial, evidence), 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(5 * time.Minute)
+	decision, err := guard.Admit(ctx, attempt(t, store, taskTutorial, evidence))
+	if err != nil || ruleOf(decision) != RuleReplay || Code(decision) != 409 {
+		t.Errorf("inside the window: rule %q (%v), want replay with 409", ruleOf(decision), err)
+	}
+	other := map[string]interface{}{"tweetUrl": "https://x.com/aiw3/status/2"}
+	if decision, err := guard.Admit(ctx, attempt(t, store, taskTutorial, other)); err != nil || decision != nil {
+		t.Errorf("other evidence: rejected by %q (%v), want it admitted", ruleOf(decision), err)
+	}
+
+	clock.Advance(6 * time.Minute)
+	if decision, err := guard.Admit(ctx, attempt(t, store, taskTutorial, evidence)); err != nil || decision != nil {
+		t.Errorf("outside the window: rejected by %q (%v), want it admitted", ruleOf(decision), err)
+	}
+}
+
+func TestAdmitRequiresPrerequisites(t *testing.T) {
+	ctx := context.Background()
+	rules := Rules{PrerequisiteMinIntervalSeconds: 3600, Prerequisites: map[int][]int{taskTutorial: {taskInviteOne}}}
+	tests := []struct {
+		name        string
+		taskID      int
+		completedAt time.Duration // how long ago the first invite was completed, 0 when it was not
+		want        string
+	}{
+		{"same type, lower value missing", taskInviteTwo, 0, RulePrerequisite},
+		{"inside the minimum interval", taskInviteTwo, 30 * time.Minute, RulePrerequisite},
+		{"after the minimum interval", taskInviteTwo, 2 * time.Hour, ""},
+		{"configured prerequisite missing", taskTutorial, 0, RulePrerequisite},
+		{"configured prerequisite completed", taskTutorial, 2 * time.Hour, ""},
+		{"no prerequisite", taskInviteOne, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard, store, clock := newGuard(t, rules)
+			if tt.completedAt > 0 {
+				progress := &repository.TaskProgress{UserID: user, TaskID: taskInviteOne, Progress: 100, Completed: true,
+					CompletedAt: clock.Ago(tt.completedAt)}
+				if err := store.Tasks.SaveProgress(ctx, progress); err != nil {
+					t.Fatal(err)
+				}
+			}
+			decision, err := guard.Admit(ctx, attempt(t, store, tt.taskID, nil))
+			if err != nil || ruleOf(decision) != tt.want {
+				t.Fatalf("rule %q (%v), want %q", ruleOf(decision), err, tt.want)
+			}
+			if decision != nil && Code(decision) != 403 {
+				t.Errorf("code %d, want 403", Code(decision))
+			}
+		})
+	}
+}
+
+func TestScreenHoldsOutliersOfMultiStepTasks(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name               string
+		taskID             int
+		previous, progress int
+		want               string
+	}{
+		{"multi-step jump above the limit", taskInviteTwo, 0, 100, repository.GuardOutcomeReview},
+		{"multi-step jump at the limit", taskInviteTwo, 50, 100, repository.GuardOutcomeAllowed},
+		{"single-step task", taskTutorial, 0, 100, repository.GuardOutcomeAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard, store, _ := newGuard(t, Rules{MaxProgressJump: 50})
+			decision, err := guard.Screen(ctx, attempt(t, store, tt.taskID, nil), tt.previous, tt.progress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decision.Outcome != tt.want {
+				t.Fatalf("outcome %q, want %q", decision.Outcome, tt.want)
+			}
+			wantCode := 200
+			if tt.want == repository.GuardOutcomeReview {
+				wantCode = 202
+				if decision.Rule != RuleOutlier || decision.ReviewStatus != repository.ReviewPending {
+					t.Errorf("held by %q with review %q, want outlier_progress pending review", decision.Rule,
+						decision.ReviewStatus)
+				}
+			}
+			if Code(decision) != wantCode {
+				t.Errorf("code %d, want %d", Code(decision), wantCode)
+			}
+			recorded, err := store.TaskGuard.Count(ctx, repository.GuardDecisionFilter{UserID: user, TaskID: tt.taskID})
+			if err != nil || recorded != 1 {
+				t.Errorf("%d decisions recorded (%v), want 1", recorded, err)
+			}
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		outcome, rule string
+		want          int
+	}{
+		{repository.GuardOutcomeAllowed, "", 200},
+		{repository.GuardOutcomeReview, RuleOutlier, 202},
+		{repository.GuardOutcomeRejected, RuleRateLimit, 429},
+		{repository.GuardOutcomeRejected, RulePrerequisite, 403},
+		{repository.GuardOutcomeRejected, RuleEvidence, 400},
+		{repository.GuardOutcomeRejected, RuleDuplicate, 409},
+		{repository.GuardOutcomeRejected, RuleReplay, 409},
+	}
+	for _, tt := range tests {
+		if got := Code(&repository.GuardDecision{Outcome: tt.outcome, Rule: tt.rule}); got != tt.want {
+			t.Errorf("%s by %q: code %d, want %d", tt.outcome, tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		file, content string
+		valid         bool
+	}{
+		{"rules.yaml", "rateLimitAttempts: 3\nrateLimitWindowSeconds: 60\nprerequisites:\n  3: [1]\n", true},
+		{"rules.json", `{"replayWindowSeconds": 600, "maxProgressJump": 40}`, true},
+		{"unknown.yaml", "rateLimitAttempts: 3\nrateLimitWindowSeconds: 60\nburstAttempts: 2\n", false},
+		{"unknown.json", `{"replayWindowSeconds": 600, "replayWindow": 600}`, false},
+		{"negative.yaml", "replayWindowSeconds: -1\n", false},
+		{"negative.json", `{"maxProgressJump": -5}`, false},
+		{"rules.toml", "rateLimitAttempts = 3\n", false},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadRules(path); (err == nil) != tt.valid {
+				t.Errorf("error %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		valid bool
+	}{
+		{"default", DefaultRules(), true},
+		{"everything disabled", Rules{}, true},
+		{"negative rate limit", Rules{RateLimitAttempts: -1, RateLimitWindowSeconds: 60}, false},
+		{"negative interval", Rules{PrerequisiteMinIntervalSeconds: -60}, false},
+		{"rate limit without window", Rules{RateLimitAttempts: 3}, false},
+		{"jump above 100", Rules{MaxProgressJump: 101}, false},
+		{"own prerequisite", Rules{Prerequisites: map[int][]int{2: {1, 2}}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.rules.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: error %v, want valid %t", tt.name, err, tt.valid)
+		}
+	}
+}
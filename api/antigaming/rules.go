@@ -0,0 +1,101 @@
+package antigaming
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ==========================================
+// GUARD RULES
+// ==========================================
+
+// Rules configures the anti-gaming guard. A zero limit disables its rule.
+type Rules struct {
+	// At most RateLimitAttempts completion attempts per user and task within RateLimitWindowSeconds
+	RateLimitAttempts      int `json:"rateLimitAttempts" yaml:"rateLimitAttempts"`
+	RateLimitWindowSeconds int `json:"rateLimitWindowSeconds" yaml:"rateLimitWindowSeconds"`
+
+	// Evidence identical to an allowed completion of the same task is rejected within this window
+	ReplayWindowSeconds int `json:"replayWindowSeconds" yaml:"replayWindowSeconds"`
+
+	// Minimum time between completing a prerequisite task and the task requiring it
+	PrerequisiteMinIntervalSeconds int `json:"prerequisiteMinIntervalSeconds" yaml:"prerequisiteMinIntervalSeconds"`
+	// Extra prerequisites by task ID; tasks of the same type with a lower requirement value are always prerequisites
+	Prerequisites map[int][]int `json:"prerequisites,omitempty" yaml:"prerequisites,omitempty"`
+
+	// Progress points one verification of a multi-step task may add before it is held for review
+	MaxProgressJump int `json:"maxProgressJump" yaml:"maxProgressJump"`
+}
+
+// DefaultRules returns the production rules: 5 attempts per hour, a 10 minute replay window,
+// an hour between prerequisite tasks and review for jumps above 50 progress points
+func DefaultRules() Rules {
+	return Rules{
+		RateLimitAttempts:              5,
+		RateLimitWindowSeconds:         3600,
+		ReplayWindowSeconds:            600,
+		PrerequisiteMinIntervalSeconds: 3600,
+		MaxProgressJump:                50,
+	}
+}
+
+// LoadRules reads and validates a rules file; the format is chosen by extension (.yaml, .yml or .json)
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, err
+	}
+
+	var rules Rules
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, &rules)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&rules)
+	default:
+		err = fmt.Errorf("unsupported rules format %q", filepath.Ext(path))
+	}
+	if err == nil {
+		err = rules.Validate()
+	}
+	if err != nil {
+		return Rules{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Validate rejects negative limits and a rate limit without a window
+func (r Rules) Validate() error {
+	if r.RateLimitAttempts < 0 || r.RateLimitWindowSeconds < 0 || r.ReplayWindowSeconds < 0 ||
+		r.PrerequisiteMinIntervalSeconds < 0 || r.MaxProgressJump < 0 {
+		return errors.New("limits must not be negative")
+	}
+	if r.RateLimitAttempts > 0 && r.RateLimitWindowSeconds == 0 {
+		return errors.New("rateLimitWindowSeconds is required with rateLimitAttempts")
+	}
+	if r.MaxProgressJump > 100 {
+		return errors.New("maxProgressJump must be at most 100")
+	}
+	for taskID, prerequisites := range r.Prerequisites {
+		for _, prerequisite := range prerequisites {
+			if prerequisite == taskID {
+				return fmt.Errorf("task %d cannot be its own prerequisite", taskID)
+			}
+		}
+	}
+	return nil
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
@@ -1,31 +1,21 @@
 package auth
 
 import (
+	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
 )
 
 // ==========================================
 // AUTHENTICATION STRUCTURES
 // ==========================================
 
-// User represents an authenticated user (mimics original API user model)
-type User struct {
-	ID                 int    `json:"id"`
-	AccessToken        string `json:"accessToken,omitempty"`
-	TwitterAccessToken string `json:"twitterAccessToken,omitempty"`
-	Nickname           string `json:"nickname"`
-	WalletAddr         string `json:"walletAddr"`
-	Email              string `json:"email,omitempty"`
-	Bio                string `json:"bio,omitempty"`
-	ProfilePhotoURL    string `json:"profilePhotoUrl,omitempty"`
-	BannerURL          string `json:"bannerUrl,omitempty"`
-	TradingVolume      int    `json:"tradingVolume"`
-	CreatedAt          string `json:"createdAt"`
-	UpdatedAt          string `json:"updatedAt"`
-}
-
 // AdminUser represents an authenticated admin user (mimics original AdminUser model)
 type AdminUser struct {
 	ID          int    `json:"id"`
@@ -44,7 +34,7 @@ type AdminUser struct {
 
 // ExtractUserFromAuthHeader extracts and validates user from Authorization header string
 // This mimics the original isAuthenticated.js policy behavior
-func ExtractUserFromAuthHeader(authHeader string) (*User, error) {
+func ExtractUserFromAuthHeader(ctx context.Context, users repository.UserRepository, authHeader string) (*repository.User, error) {
 	// Check if Authorization header exists and has Bearer prefix
 	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
 		return nil, errors.New("Authorization header is missing or invalid.(isAuth1)")
@@ -56,75 +46,18 @@ func ExtractUserFromAuthHeader(authHeader string) (*User, error) {
 		return nil, errors.New("Access token is missing.")
 	}
 
-	// Mock user lookup by token (in real API this would query database)
-	user := mockUserLookup(accessToken)
-	if user == nil {
-		return nil, errors.New("Invalid access token.")
-	}
-
-	return user, nil
-}
-
-// mockUserLookup simulates database lookup of user by access token
-// This mimics the original User.find() logic in isAuthenticated.js
-func mockUserLookup(accessToken string) *User {
-	// Mock user database - in reality this would query the database
-	mockUsers := map[string]*User{
-		"test_token_123": {
-			ID:              12345,
-			AccessToken:     "test_token_123",
-			Nickname:        "TestUser",
-			WalletAddr:      "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
-			Email:           "test@example.com",
-			Bio:             "Test user for mock API",
-			ProfilePhotoURL: "https://cdn.example.com/profiles/test-user.jpg",
-			BannerURL:       "https://cdn.example.com/banners/test-banner.jpg",
-			TradingVolume:   2850000,
-			CreatedAt:       "2024-01-01T00:00:00.000Z",
-			UpdatedAt:       getCurrentTimestamp(),
-		},
-		"admin_token_456": {
-			ID:              99999,
-			AccessToken:     "admin_token_456",
-			Nickname:        "AdminUser",
-			WalletAddr:      "AdminWallet123456789",
-			Email:           "admin@example.com",
-			Bio:             "Admin user for mock API",
-			ProfilePhotoURL: "https://cdn.example.com/profiles/admin-user.jpg",
-			TradingVolume:   10000000,
-			CreatedAt:       "2023-01-01T00:00:00.000Z",
-			UpdatedAt:       getCurrentTimestamp(),
-		},
-		// Support various token formats for testing
-		"twitter_token_789": {
-			ID:                 54321,
-			TwitterAccessToken: "twitter_token_789",
-			Nickname:           "TwitterUser",
-			WalletAddr:         "8VzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtBWWN",
-			Bio:                "Twitter authenticated user",
-			TradingVolume:      1500000,
-			CreatedAt:          "2024-02-01T00:00:00.000Z",
-			UpdatedAt:          getCurrentTimestamp(),
-		},
-	}
-
 	// Look up user by accessToken or twitterAccessToken
 	// This mimics the original isAuthenticated.js logic:
 	// User.find({ where: { or: [{ twitterAccessToken: accessToken }, { accessToken: accessToken }] }})
-	for token, user := range mockUsers {
-		if token == accessToken {
-			return user
-		}
+	user, err := users.GetByAccessToken(ctx, accessToken)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.New("Invalid access token.")
 	}
-
-	// If no direct match, check if any user has this as their twitter token
-	for _, user := range mockUsers {
-		if user.TwitterAccessToken == accessToken {
-			return user
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	return nil // User not found
+	return user, nil
 }
 
 // ExtractAdminFromAuthHeader extracts and validates admin user from Authorization header string
@@ -207,6 +140,76 @@ func mockAdminUserLookup(accessToken string) *AdminUser {
 	return nil // Admin user not found
 }
 
+// ==========================================
+// SERVICE CREDENTIALS
+// ==========================================
+
+// ServiceCredentials authenticates internal services (trading engine, AI agent, ...) calling internal
+// endpoints with a bearer service token instead of a user or admin token
+type ServiceCredentials struct {
+	tokens map[string]string // service name by token
+}
+
+// DevelopmentServiceCredentials returns the well-known tokens used when no service tokens are configured
+func DevelopmentServiceCredentials() *ServiceCredentials {
+	return &ServiceCredentials{tokens: map[string]string{
+		"service_token_trading":   "trading-engine",
+		"service_token_ai_agent":  "ai-agent",
+		"service_token_community": "community",
+		"service_token_strategy":  "strategy",
+	}}
+}
+
+// ParseServiceCredentials parses comma-separated name=token pairs, e.g. "trading-engine=s3cret,ai-agent=t0ken"
+func ParseServiceCredentials(spec string) (*ServiceCredentials, error) {
+	credentials := &ServiceCredentials{tokens: map[string]string{}}
+	for _, pair := range strings.Split(spec, ",") {
+		name, token, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("service credential %q: want name=token", pair)
+		}
+		if _, taken := credentials.tokens[token]; taken {
+			return nil, fmt.Errorf("service credential %q: token is already used by another service", name)
+		}
+		credentials.tokens[token] = name
+	}
+	return credentials, nil
+}
+
+// Services returns the names of the services holding a credential, sorted
+func (c *ServiceCredentials) Services() []string {
+	names := make([]string, 0, len(c.tokens))
+	for _, name := range c.tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExtractServiceFromAuthHeader returns the name of the service whose token is in the Authorization header
+func (c *ServiceCredentials) ExtractServiceFromAuthHeader(authHeader string) (string, error) {
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		return "", errors.New("Missing or invalid Authorization header")
+	}
+	accessToken := strings.TrimPrefix(authHeader, "Bearer ")
+	if accessToken == "" {
+		return "", errors.New("Service token is missing")
+	}
+
+	// Compare every token in constant time so the response time does not reveal partial matches
+	service := ""
+	for token, name := range c.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(accessToken)) == 1 {
+			service = name
+		}
+	}
+	if service == "" {
+		return "", errors.New("Invalid service token")
+	}
+	return service, nil
+}
+
 // getCurrentTimestamp returns current timestamp in ISO format
 func getCurrentTimestamp() string {
 	return time.Now().Format("2006-01-02T15:04:05.000Z")
@@ -0,0 +1,166 @@
+package badgeexpiry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// EXPIRY NOTICES
+// ==========================================
+
+// Notifier tells a user that an activated badge is about to stop counting towards their upgrade
+type Notifier interface {
+	NotifyExpiring(ctx context.Context, badge repository.UserBadge, def *repository.BadgeDefinition) error
+}
+
+// LogNotifier writes expiry notices to the service log
+type LogNotifier struct{}
+
+func (LogNotifier) NotifyExpiring(ctx context.Context, badge repository.UserBadge, def *repository.BadgeDefinition) error {
+	log.Printf("badge expiry: user %d, badge %d (%s) activation expires at %s",
+		badge.UserID, badge.BadgeID, def.Name, badge.ExpiresAt.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// ==========================================
+// SWEEPER
+// ==========================================
+
+// leaseTTL bounds how long a sweep can hold a user's NFT lease if it dies mid-sweep
+const leaseTTL = time.Minute
+
+// Sweeper moves expired badge activations back to owned and sends one notice per activation
+// once it is within the notice period of its expiry
+type Sweeper struct {
+	store        *repository.Store
+	notifier     Notifier
+	notifyBefore time.Duration
+	now          func() time.Time
+}
+
+// New creates a sweeper that notifies users notifyBefore an activation expires
+func New(store *repository.Store, notifier Notifier, notifyBefore time.Duration) *Sweeper {
+	return &Sweeper{store: store, notifier: notifier, notifyBefore: notifyBefore, now: time.Now}
+}
+
+// Sweep expires every activation past its window and notifies the ones about to expire.
+// It returns how many activations were expired and how many notices were sent.
+// Each user's badges are swept under the user's NFT lease, so an upgrade running for the user is
+// never racing the sweep; users whose lease is held are left for the next sweep. Badges committed to
+// an unfinished upgrade are skipped: the upgrade holds their activation until it consumes them.
+func (s *Sweeper) Sweep(ctx context.Context) (expired, notified int, err error) {
+	now := s.now().UTC()
+	badges, err := s.store.Badges.ListExpiringActivations(ctx, now.Add(s.notifyBefore))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	users := []int{}
+	byUser := map[int][]repository.UserBadge{}
+	for _, badge := range badges {
+		if _, ok := byUser[badge.UserID]; !ok {
+			users = append(users, badge.UserID)
+		}
+		byUser[badge.UserID] = append(byUser[badge.UserID], badge)
+	}
+	for _, userID := range users {
+		e, n, err := s.sweepUser(ctx, userID, byUser[userID], now)
+		expired += e
+		notified += n
+		if err != nil {
+			return expired, notified, err
+		}
+	}
+	return expired, notified, nil
+}
+
+// sweepUser sweeps one user's badges while holding the user's NFT lease
+func (s *Sweeper) sweepUser(ctx context.Context, userID int, badges []repository.UserBadge, now time.Time) (expired, notified int, err error) {
+	owner, err := leaseOwner()
+	if err != nil {
+		return 0, 0, err
+	}
+	name := coordinator.UserLease(userID)
+	acquired, err := s.store.Leases.Acquire(ctx, name, owner, leaseTTL)
+	if err != nil || !acquired {
+		return 0, 0, err
+	}
+	defer func() {
+		if err := s.store.Leases.Release(context.Background(), name, owner); err != nil {
+			log.Printf("badge expiry: release %s: %v", name, err)
+		}
+	}()
+
+	upgrades, err := s.store.Upgrades.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	committed := badgelifecycle.Committed(upgrades)
+
+	for i := range badges {
+		badge := &badges[i]
+		if committed[badge.BadgeID] {
+			continue
+		}
+		if badgelifecycle.Expired(badge, now) {
+			if err := badgelifecycle.Expire(badge, now); err != nil {
+				return expired, notified, err
+			}
+			if err := s.store.Badges.SaveUserBadge(ctx, badge); err != nil {
+				return expired, notified, err
+			}
+			expired++
+			continue
+		}
+		if badge.ExpiryNotifiedAt != nil {
+			continue
+		}
+
+		def, err := s.store.Badges.GetDefinition(ctx, badge.BadgeID)
+		if err != nil {
+			return expired, notified, err
+		}
+		if err := s.notifier.NotifyExpiring(ctx, *badge, def); err != nil {
+			return expired, notified, err
+		}
+		badge.ExpiryNotifiedAt = &now
+		if err := s.store.Badges.SaveUserBadge(ctx, badge); err != nil {
+			return expired, notified, err
+		}
+		notified++
+	}
+	return expired, notified, nil
+}
+
+// leaseOwner returns a random token identifying one sweep's hold on a user's lease
+func leaseOwner() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Run sweeps every interval until ctx ends
+func (s *Sweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, _, err := s.Sweep(ctx); err != nil {
+				log.Printf("badge expiry: sweep: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
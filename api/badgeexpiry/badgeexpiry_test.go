@@ -0,0 +1,107 @@
+package badgeexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+)
+
+// activation stores an activated badge of user 1 whose window closed an hour before now
+func activation(t *testing.T, store *repository.Store, badgeID int, now time.Time) {
+	t.Helper()
+	def := &repository.BadgeDefinition{ID: badgeID, NftLevel: 1, Name: "Badge"}
+	if err := store.Badges.SaveDefinition(context.Background(), def); err != nil {
+		t.Fatal(err)
+	}
+	activatedAt, expiresAt := now.Add(-2*time.Hour), now.Add(-time.Hour)
+	badge := &repository.UserBadge{UserID: 1, BadgeID: badgeID, Status: string(badgelifecycle.StatusActivated),
+		ActivatedAt: &activatedAt, ExpiresAt: &expiresAt}
+	if err := store.Badges.SaveUserBadge(context.Background(), badge); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func status(t *testing.T, store *repository.Store, badgeID int) string {
+	t.Helper()
+	badge, err := store.Badges.GetUserBadge(context.Background(), 1, badgeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return badge.Status
+}
+
+func TestSweepSkipsBadgesCommittedToUnfinishedUpgrades(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		upgrade     repository.UpgradeRequest
+		wantExpired bool
+	}{
+		{"pending holds", repository.UpgradeRequest{Status: repository.UpgradeStatusPending}, false},
+		{"burn confirmed holds", repository.UpgradeRequest{Status: repository.UpgradeStatusBurnConfirmed}, false},
+		{"mint confirmed holds", repository.UpgradeRequest{Status: repository.UpgradeStatusMintConfirmed}, false},
+		{"failed after burn holds", repository.UpgradeRequest{Status: repository.UpgradeStatusFailed,
+			BurnTransaction: "burn-1"}, false},
+		{"failed before burn releases", repository.UpgradeRequest{Status: repository.UpgradeStatusFailed}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			activation(t, store, 10, now)
+			activation(t, store, 11, now)
+			upgrade := tt.upgrade
+			upgrade.UserID, upgrade.BadgeIDs = 1, []int{10}
+			if err := store.Upgrades.Create(ctx, &upgrade); err != nil {
+				t.Fatal(err)
+			}
+
+			sweeper := New(store, LogNotifier{}, time.Hour)
+			sweeper.now = func() time.Time { return now }
+			expired, _, err := sweeper.Sweep(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantExpired, wantStatus := 1, string(badgelifecycle.StatusActivated)
+			if tt.wantExpired {
+				wantExpired, wantStatus = 2, string(badgelifecycle.StatusOwned)
+			}
+			if expired != wantExpired {
+				t.Errorf("expired %d, want %d", expired, wantExpired)
+			}
+			if got := status(t, store, 10); got != wantStatus {
+				t.Errorf("committed badge is %s, want %s", got, wantStatus)
+			}
+			if got := status(t, store, 11); got != string(badgelifecycle.StatusOwned) {
+				t.Errorf("uncommitted badge is %s, want owned", got)
+			}
+		})
+	}
+}
+
+func TestSweepLeavesLeasedUsers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := memory.NewStore()
+	activation(t, store, 10, now)
+	if ok, err := store.Leases.Acquire(ctx, coordinator.UserLease(1), "upgrade", time.Hour); err != nil || !ok {
+		t.Fatalf("acquire: %v %v", ok, err)
+	}
+
+	sweeper := New(store, LogNotifier{}, time.Hour)
+	sweeper.now = func() time.Time { return now }
+	if expired, _, err := sweeper.Sweep(ctx); err != nil || expired != 0 {
+		t.Fatalf("sweep while leased: expired %d, err %v", expired, err)
+	}
+	if err := store.Leases.Release(ctx, coordinator.UserLease(1), "upgrade"); err != nil {
+		t.Fatal(err)
+	}
+	if expired, _, err := sweeper.Sweep(ctx); err != nil || expired != 1 {
+		t.Fatalf("sweep after release: expired %d, err %v", expired, err)
+	}
+}
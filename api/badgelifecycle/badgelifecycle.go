@@ -0,0 +1,228 @@
+package badgelifecycle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
+)
+
+// ==========================================
+// BADGE STATES
+// ==========================================
+
+// Status is the lifecycle state of a badge for a user. It is the only badge status vocabulary:
+// the badges and nfts packages report it as is and the store persists it in badge.status.
+type Status string
+
+const (
+	StatusLocked    Status = "locked"    // task not completed, nothing stored for the user
+	StatusOwned     Status = "owned"     // earned by completing its task, not activated yet
+	StatusActivated Status = "activated" // counts towards the next NFT upgrade
+	StatusConsumed  Status = "consumed"  // spent by an NFT upgrade
+)
+
+// transitions lists the allowed moves; every badge goes through them in order and only an expired
+// activation goes back (see Expire)
+var transitions = map[Status]Status{
+	StatusLocked:    StatusOwned,
+	StatusOwned:     StatusActivated,
+	StatusActivated: StatusConsumed,
+}
+
+// CanTransition reports whether a badge may move from one state to the other
+func CanTransition(from, to Status) bool {
+	next, ok := transitions[from]
+	return ok && next == to
+}
+
+// StatusOf returns the current state of a stored badge, StatusLocked when the user has none.
+// Values written before the vocabulary was unified (e.g. "earned") are normalized.
+func StatusOf(userBadge *repository.UserBadge) Status {
+	return StatusAt(userBadge, time.Now())
+}
+
+// StatusAt returns the state of a stored badge at the given time. An activation past its expiry counts
+// as owned even before the sweeper has moved it back.
+func StatusAt(userBadge *repository.UserBadge, now time.Time) Status {
+	if userBadge == nil {
+		return StatusLocked
+	}
+	status, ok := ParseFilter(userBadge.Status)
+	if !ok {
+		return Status(userBadge.Status)
+	}
+	if status == StatusActivated && Expired(userBadge, now) {
+		return StatusOwned
+	}
+	return status
+}
+
+// Expired reports whether the badge's activation window has closed at the given time
+func Expired(userBadge *repository.UserBadge, now time.Time) bool {
+	return userBadge.ExpiresAt != nil && !now.Before(*userBadge.ExpiresAt)
+}
+
+// ActivationWindow returns how long an activation of def counts towards an upgrade: the badge's own
+// window when set, otherwise the window of its NFT level. Zero means the activation never expires.
+func ActivationWindow(def *repository.BadgeDefinition) time.Duration {
+	hours := 0
+	if def.ActivationWindowHours != nil {
+		hours = *def.ActivationWindowHours
+	} else if tier, ok := tiers.Current().Tier(def.NftLevel); ok {
+		hours = tier.BadgeActivationHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// ==========================================
+// TRANSITIONS
+// ==========================================
+
+// Earn returns the record to persist when the user completes the badge's task; the badge keeps the
+// version of def it was earned under
+func Earn(userID int, def *repository.BadgeDefinition, at time.Time) *repository.UserBadge {
+	return &repository.UserBadge{
+		UserID:            userID,
+		BadgeID:           def.ID,
+		Status:            string(StatusOwned),
+		DefinitionVersion: def.Version,
+		EarnedAt:          &at,
+	}
+}
+
+// Activate moves an owned badge to activated for window; a zero window never expires
+func Activate(userBadge *repository.UserBadge, at time.Time, window time.Duration) error {
+	if err := transition(userBadge, StatusActivated); err != nil {
+		return err
+	}
+	userBadge.ActivatedAt = &at
+	userBadge.ExpiresAt = nil
+	userBadge.ExpiryNotifiedAt = nil
+	if window > 0 {
+		expiresAt := at.Add(window)
+		userBadge.ExpiresAt = &expiresAt
+	}
+	return nil
+}
+
+// Expire moves an activation whose window has closed back to owned so it can be activated again
+func Expire(userBadge *repository.UserBadge, at time.Time) error {
+	if userBadge.Status != string(StatusActivated) || !Expired(userBadge, at) {
+		return &Error{
+			Code:    ErrCodeIllegalTransition,
+			Message: "Only an activated badge past its expiry can expire",
+			From:    StatusOf(userBadge),
+			To:      StatusOwned,
+		}
+	}
+	userBadge.Status = string(StatusOwned)
+	userBadge.ActivatedAt = nil
+	userBadge.ExpiresAt = nil
+	userBadge.ExpiryNotifiedAt = nil
+	return nil
+}
+
+// Consume moves an activated badge to consumed
+func Consume(userBadge *repository.UserBadge, at time.Time) error {
+	if err := transition(userBadge, StatusConsumed); err != nil {
+		return err
+	}
+	userBadge.ConsumedAt = &at
+	return nil
+}
+
+// ConsumeCommitted consumes a badge an NFT upgrade committed at committedAt. The upgrade holds the
+// activation from then on, so the badge only has to have been activated when it was committed; an
+// activation whose window closed since is still consumed.
+func ConsumeCommitted(userBadge *repository.UserBadge, committedAt, at time.Time) error {
+	if userBadge.Status != string(StatusActivated) || StatusAt(userBadge, committedAt) != StatusActivated {
+		return &Error{
+			Code:    ErrCodeIllegalTransition,
+			Message: "Only a badge activated when the upgrade was requested can be consumed by it",
+			From:    StatusAt(userBadge, committedAt),
+			To:      StatusConsumed,
+		}
+	}
+	userBadge.Status = string(StatusConsumed)
+	userBadge.ConsumedAt = &at
+	return nil
+}
+
+// Committed returns the IDs of the badges held by the user's unfinished upgrades: pending or part way
+// through, or failed after the old NFT was burned and so still to be resumed. Their activations must not
+// expire before the upgrade consumes them.
+func Committed(upgrades []repository.UpgradeRequest) map[int]bool {
+	committed := map[int]bool{}
+	for _, req := range upgrades {
+		if !req.Unfinished() {
+			continue
+		}
+		for _, badgeID := range req.BadgeIDs {
+			committed[badgeID] = true
+		}
+	}
+	return committed
+}
+
+func transition(userBadge *repository.UserBadge, to Status) error {
+	from := StatusOf(userBadge)
+	if !CanTransition(from, to) {
+		return &Error{
+			Code:    ErrCodeIllegalTransition,
+			Message: fmt.Sprintf("Badge is %s and cannot become %s", from, to),
+			From:    from,
+			To:      to,
+		}
+	}
+	userBadge.Status = string(to)
+	return nil
+}
+
+// ==========================================
+// LEGACY STATUS VALUES
+// ==========================================
+
+// legacyStatuses maps status values used by earlier API versions to the canonical states
+var legacyStatuses = map[string]Status{
+	"earned":     StatusOwned,
+	"available":  StatusOwned,
+	"not_earned": StatusLocked,
+}
+
+// ParseFilter maps a status query value to its state. Canonical values and the legacy ones
+// (earned, available, not_earned, and the capitalized nft-info values) are accepted in any case.
+func ParseFilter(value string) (Status, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if status, ok := legacyStatuses[value]; ok {
+		return status, true
+	}
+	switch status := Status(value); status {
+	case StatusLocked, StatusOwned, StatusActivated, StatusConsumed:
+		return status, true
+	}
+	return "", false
+}
+
+// ==========================================
+// TRANSITION ERRORS
+// ==========================================
+
+// ErrorCode is a stable identifier for a rejected transition, safe to return to clients
+type ErrorCode string
+
+const ErrCodeIllegalTransition ErrorCode = "BADGE_ILLEGAL_TRANSITION"
+
+// Error is returned when a transition is not allowed
+type Error struct {
+	Code    ErrorCode
+	Message string
+	From    Status
+	To      Status
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
@@ -0,0 +1,163 @@
+package badgelifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+func TestConsumeCommitted(t *testing.T) {
+	activatedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := activatedAt.Add(2 * time.Hour)
+	tests := []struct {
+		name        string
+		status      Status
+		committedAt time.Time
+		wantErr     bool
+	}{
+		{"committed while activated", StatusActivated, activatedAt.Add(time.Hour), false},
+		{"committed after the window closed", StatusActivated, expiresAt.Add(time.Minute), true},
+		{"not activated", StatusOwned, activatedAt.Add(time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			badge := &repository.UserBadge{UserID: 1, BadgeID: 1, Status: string(tt.status),
+				ActivatedAt: &activatedAt, ExpiresAt: &expiresAt}
+			// Consumed after the activation expired, as an upgrade resumed late would
+			err := ConsumeCommitted(badge, tt.committedAt, expiresAt.Add(time.Hour))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConsumeCommitted: %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && badge.Status != string(StatusConsumed) {
+				t.Errorf("status %s, want consumed", badge.Status)
+			}
+		})
+	}
+}
+
+func TestLifecycleWalksThroughEveryState(t *testing.T) {
+	// Transitions read the current state at the wall clock, so the activation must still be open now
+	at := time.Now().Add(-time.Hour)
+	def := &repository.BadgeDefinition{ID: 3, NftLevel: 1, Version: 2}
+
+	badge := Earn(1, def, at)
+	if StatusAt(badge, at) != StatusOwned || badge.DefinitionVersion != 2 || !badge.EarnedAt.Equal(at) {
+		t.Fatalf("earned %+v, want it owned under version 2", badge)
+	}
+	if err := Activate(badge, at.Add(time.Hour), 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if StatusAt(badge, at.Add(time.Hour)) != StatusActivated || !badge.ExpiresAt.Equal(at.Add(3*time.Hour)) {
+		t.Fatalf("activated %+v, want it activated until its window closes", badge)
+	}
+	if err := Consume(badge, at.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if badge.Status != string(StatusConsumed) || !badge.ConsumedAt.Equal(at.Add(2*time.Hour)) {
+		t.Errorf("consumed %+v, want it consumed", badge)
+	}
+}
+
+func TestIllegalTransitionsAreRejected(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		status Status
+		move   func(*repository.UserBadge) error
+	}{
+		{"activate a locked badge", StatusLocked, func(b *repository.UserBadge) error { return Activate(b, at, 0) }},
+		{"activate an activated badge", StatusActivated, func(b *repository.UserBadge) error { return Activate(b, at, 0) }},
+		{"activate a consumed badge", StatusConsumed, func(b *repository.UserBadge) error { return Activate(b, at, 0) }},
+		{"consume an owned badge", StatusOwned, func(b *repository.UserBadge) error { return Consume(b, at) }},
+		{"consume a consumed badge", StatusConsumed, func(b *repository.UserBadge) error { return Consume(b, at) }},
+		{"expire an owned badge", StatusOwned, func(b *repository.UserBadge) error { return Expire(b, at) }},
+		{"expire an activation without expiry", StatusActivated, func(b *repository.UserBadge) error { return Expire(b, at) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			badge := &repository.UserBadge{UserID: 1, BadgeID: 1, Status: string(tt.status)}
+			err := tt.move(badge)
+			lifecycleErr, ok := err.(*Error)
+			if !ok || lifecycleErr.Code != ErrCodeIllegalTransition {
+				t.Fatalf("error %v, want %s", err, ErrCodeIllegalTransition)
+			}
+			if badge.Status != string(tt.status) {
+				t.Errorf("status %s after the rejection, want it left %s", badge.Status, tt.status)
+			}
+		})
+	}
+	for from, to := range map[Status]Status{StatusOwned: StatusConsumed, StatusConsumed: StatusActivated,
+		StatusActivated: StatusOwned, StatusLocked: StatusActivated} {
+		if CanTransition(from, to) {
+			t.Errorf("%s -> %s allowed", from, to)
+		}
+	}
+}
+
+func TestExpiredActivationCountsAsOwned(t *testing.T) {
+	activatedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	badge := &repository.UserBadge{UserID: 1, BadgeID: 1, Status: string(StatusOwned)}
+	if err := Activate(badge, activatedAt, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := activatedAt.Add(time.Hour)
+	tests := []struct {
+		at   time.Time
+		want Status
+	}{
+		{expiresAt.Add(-time.Second), StatusActivated},
+		{expiresAt, StatusOwned},
+		{expiresAt.Add(time.Hour), StatusOwned},
+	}
+	for _, tt := range tests {
+		if got := StatusAt(badge, tt.at); got != tt.want {
+			t.Errorf("status at %s: %s, want %s", tt.at.Format(time.TimeOnly), got, tt.want)
+		}
+	}
+	// The stored status stays activated until the sweeper expires it
+	if badge.Status != string(StatusActivated) {
+		t.Errorf("stored status %s, want activated", badge.Status)
+	}
+	if err := Expire(badge, expiresAt); err != nil || badge.Status != string(StatusOwned) || badge.ActivatedAt != nil {
+		t.Errorf("expired %+v (%v), want it owned again", badge, err)
+	}
+	// An activation without a window never expires
+	forever := &repository.UserBadge{Status: string(StatusOwned)}
+	if err := Activate(forever, activatedAt, 0); err != nil || forever.ExpiresAt != nil ||
+		StatusAt(forever, activatedAt.AddDate(10, 0, 0)) != StatusActivated {
+		t.Errorf("activation without window %+v (%v), want it activated for good", forever, err)
+	}
+	if StatusAt(nil, activatedAt) != StatusLocked {
+		t.Errorf("no badge: %s, want locked", StatusAt(nil, activatedAt))
+	}
+}
+
+func TestParseFilterMapsLegacyStatuses(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Status
+		ok    bool
+	}{
+		{"owned", StatusOwned, true},
+		{"Activated", StatusActivated, true},
+		{" consumed ", StatusConsumed, true},
+		{"LOCKED", StatusLocked, true},
+		{"earned", StatusOwned, true},
+		{"available", StatusOwned, true},
+		{"not_earned", StatusLocked, true},
+		{"Earned", StatusOwned, true},
+		{"expired", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseFilter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseFilter(%q) = %q, %t; want %q, %t", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+	// Stored legacy values are read as their state
+	if got := StatusOf(&repository.UserBadge{Status: "earned"}); got != StatusOwned {
+		t.Errorf("stored earned: %s, want owned", got)
+	}
+}
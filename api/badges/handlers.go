@@ -2,10 +2,18 @@ package badges
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
-	"github.com/aiw3/nft-solana-api/public"
+	"github.com/aiw3/nft-solana-api/antigaming"
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/repository"
 	"github.com/aiw3/nft-solana-api/shared"
+	"github.com/aiw3/nft-solana-api/tasks"
+	"github.com/aiw3/nft-solana-api/tiers"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
 )
@@ -15,7 +23,7 @@ import (
 // ==========================================
 
 // GetBadgeStats returns statistics for all badges
-func GetBadgeStats() usecase.Interactor {
+func GetBadgeStats(store *repository.Store) usecase.Interactor {
 	type getBadgeStatsRequest struct {
 		Authorization *string `header:"Authorization" description:"Bearer token for user authentication (optional)"`
 		Limit         *int    `query:"limit" description:"Number of badges to return"`
@@ -34,8 +42,10 @@ func GetBadgeStats() usecase.Interactor {
 			offset = *req.Offset
 		}
 
-		// Generate mock badge stats
-		badgeStats := generateMockBadgeStats()
+		badgeStats, err := loadBadgeStats(ctx, store)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
 		// Apply category filter if provided
 		if req.Category != nil && *req.Category != "" {
@@ -50,21 +60,25 @@ func GetBadgeStats() usecase.Interactor {
 
 		// Calculate totals
 		totalBadges := len(badgeStats)
-		totalUnlockedAcrossAllBadges := 0
+		totalActivated := 0
+		totalContribution := 0.0
 		for _, stat := range badgeStats {
-			totalUnlockedAcrossAllBadges += stat.UnlockedCount
+			for _, levelStat := range stat.LevelStats {
+				totalActivated += levelStat.Activated
+				totalContribution += float64(levelStat.Activated) * stat.Badge.ContributionValue
+			}
 		}
 
 		*resp = GetBadgeStatsResponse{
 			Code:    200,
 			Message: "Badge statistics retrieved successfully",
 			Data: BadgeStatsData{
-				Stats: badgeStats,
-			Summary: BadgeSummary{
-				TotalBadges:            totalBadges,
-				ActivatedBadges:        totalUnlockedAcrossAllBadges,
-				TotalContributionValue: float64(totalUnlockedAcrossAllBadges) / float64(totalBadges),
-			},
+				Stats: paginate(badgeStats, limit, offset),
+				Summary: BadgeSummary{
+					TotalBadges:            totalBadges,
+					ActivatedBadges:        totalActivated,
+					TotalContributionValue: totalContribution,
+				},
 				Pagination: Pagination{
 					Total:   totalBadges,
 					Limit:   limit,
@@ -84,24 +98,46 @@ func GetBadgeStats() usecase.Interactor {
 	return u
 }
 
-// GetUserBadges returns badges for a specific user
-func GetUserBadges() usecase.Interactor {
+// GetUserBadges returns the badge portfolio of the authenticated user
+func GetUserBadges(store *repository.Store) usecase.Interactor {
 	type getUserBadgesRequest struct {
-		UserID        int     `path:"userId" required:"true" description:"User ID to get badges for"`
-		Authorization *string `header:"Authorization" description:"Bearer token for user authentication (optional for public view)"`
-		Status        *string `query:"status" description:"Filter by badge status (earned, available, locked)"`
+		Authorization string  `header:"Authorization" description:"Bearer token for user authentication"`
+		Status        *string `query:"status" description:"Filter by badge status (locked, owned, activated, consumed; legacy earned and available mean owned)"`
 		Category      *string `query:"category" description:"Filter by badge category"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req getUserBadgesRequest, resp *GetUserBadgesResponse) error {
-		// Generate mock user badges
-		userBadges := generateMockUserBadges(req.UserID)
+		// Extract user from Authorization header
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = GetUserBadgesResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    GetUserBadgesData{},
+			}
+			return nil
+		}
+
+		statusFilter, ok := parseStatusFilter(req.Status)
+		if !ok {
+			*resp = GetUserBadgesResponse{
+				Code:    400,
+				Message: fmt.Sprintf("Unknown badge status %q", *req.Status),
+				Data:    GetUserBadgesData{},
+			}
+			return nil
+		}
+
+		userBadges, err := LoadUserBadges(ctx, store, user.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
 		// Apply status filter if provided
-		if req.Status != nil && *req.Status != "" {
+		if statusFilter != "" {
 			filteredBadges := []Badge{}
 			for _, badge := range userBadges {
-				if badge.Status == *req.Status {
+				if badge.Status == string(statusFilter) {
 					filteredBadges = append(filteredBadges, badge)
 				}
 			}
@@ -119,28 +155,21 @@ func GetUserBadges() usecase.Interactor {
 			userBadges = filteredBadges
 		}
 
-		// Calculate summary statistics
-		earnedCount := 0
-		availableCount := 0
-		lockedCount := 0
+		// Group badges for the frontend
+		badgesByCategory := make(map[string][]Badge)
+		badgesByStatus := make(map[string][]Badge)
 		for _, badge := range userBadges {
-			switch badge.Status {
-			case "earned":
-				earnedCount++
-			case "available":
-				availableCount++
-			case "locked":
-				lockedCount++
-			}
+			badgesByCategory[badge.Category] = append(badgesByCategory[badge.Category], badge)
+			badgesByStatus[badge.Status] = append(badgesByStatus[badge.Status], badge)
 		}
 
 		*resp = GetUserBadgesResponse{
 			Code:    200,
-			Message: fmt.Sprintf("Badges for user %d retrieved successfully", req.UserID),
+			Message: fmt.Sprintf("Badges for user %d retrieved successfully", user.ID),
 			Data: GetUserBadgesData{
 				UserBadges:       userBadges,
-				BadgesByCategory: make(map[string][]Badge),
-				BadgesByStatus:   make(map[string][]Badge),
+				BadgesByCategory: badgesByCategory,
+				BadgesByStatus:   badgesByStatus,
 				Pagination: Pagination{
 					Total:   len(userBadges),
 					Limit:   50,
@@ -154,22 +183,23 @@ func GetUserBadges() usecase.Interactor {
 
 	u.SetTags("Badges")
 	u.SetTitle("Get User Badges")
-	u.SetDescription("Get all badges for a specific user with optional filtering")
-	u.SetExpectedErrors(status.NotFound, status.Internal)
+	u.SetDescription("Get all badges for the authenticated user with optional filtering")
+	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
 
 	return u
 }
 
 // ActivateBadge activates a specific badge for a user
-func ActivateBadge() usecase.Interactor {
+func ActivateBadge(store *repository.Store) usecase.Interactor {
 	type activateBadgeRequest struct {
-		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
-		BadgeID       int    `json:"badge_id" required:"true" description:"Badge ID to activate"`
+		Authorization  string `header:"Authorization" description:"Bearer token for user authentication"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		BadgeID        int    `json:"badge_id" required:"true" description:"Badge ID to activate"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req activateBadgeRequest, resp *ActivateBadgeResponse) error {
 		// Extract user from Authorization header
-		user, err := extractUserFromAuthHeader(req.Authorization)
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
 		if err != nil {
 			*resp = ActivateBadgeResponse{
 				Code:    401,
@@ -189,9 +219,9 @@ func ActivateBadge() usecase.Interactor {
 			return nil
 		}
 
-		// Check if badge exists and user has earned it
-		badge := findMockBadgeByID(req.BadgeID)
-		if badge == nil {
+		// Check if badge exists
+		def, err := store.Badges.GetDefinition(ctx, req.BadgeID)
+		if errors.Is(err, repository.ErrNotFound) {
 			*resp = ActivateBadgeResponse{
 				Code:    404,
 				Message: "Badge not found",
@@ -199,18 +229,17 @@ func ActivateBadge() usecase.Interactor {
 			}
 			return nil
 		}
-
-		// Mock check if user has earned this badge
-		userBadges := generateMockUserBadges(user.ID)
-		hasEarnedBadge := false
-		for _, userBadge := range userBadges {
-			if userBadge.ID == req.BadgeID && userBadge.Status == "earned" {
-				hasEarnedBadge = true
-				break
-			}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
 		}
 
-		if !hasEarnedBadge {
+		// Check if user has earned this badge
+		userBadge, err := store.Badges.GetUserBadge(ctx, user.ID, req.BadgeID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return status.Wrap(err, status.Internal)
+		}
+		now := time.Now().UTC()
+		if userBadge == nil || badgelifecycle.Activate(userBadge, now, badgelifecycle.ActivationWindow(def)) != nil {
 			*resp = ActivateBadgeResponse{
 				Code:    403,
 				Message: "Badge not earned yet or not available for activation",
@@ -218,19 +247,31 @@ func ActivateBadge() usecase.Interactor {
 			}
 			return nil
 		}
+		if err := store.Badges.SaveUserBadge(ctx, userBadge); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if def, err = EarnedDefinition(ctx, store, def, userBadge); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		totalActivated, totalValue, err := activatedTotals(ctx, store, user.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
 		*resp = ActivateBadgeResponse{
 			Code:    200,
-			Message: fmt.Sprintf("Badge '%s' activated successfully for user %s", badge.Name, user.Username),
+			Message: fmt.Sprintf("Badge '%s' activated successfully for user %s", def.Name, user.Nickname),
 			Data: ActivateBadgeData{
 				Success:           true,
 				BadgeID:           req.BadgeID,
-				ActivatedAt:       shared.GetCurrentTimestamp(),
-				ContributionValue: badge.ContributionValue,
-				NewTotalValue:     badge.ContributionValue + 3.0, // Mock previous total
+				ActivatedAt:       shared.FormatTimestamp(now),
+				ContributionValue: def.ContributionValue,
+				NewTotalValue:     totalValue,
+				ExpiresAt:         shared.FormatTimestampPtr(userBadge.ExpiresAt),
 				Contributes:       true,
-				NewStatus:         "activated",
-				TotalActivated:    3,
+				NewStatus:         string(badgelifecycle.StatusActivated),
+				TotalActivated:    totalActivated,
 			},
 		}
 		return nil
@@ -244,18 +285,21 @@ func ActivateBadge() usecase.Interactor {
 	return u
 }
 
-// CompleteTask marks a task as completed for badge progress
-func CompleteTask() usecase.Interactor {
+// CompleteTask verifies a task against server-side data, records its progress and awards the linked badge
+// once every requirement of the task is met. Progress is measured by the task type's verifier, never
+// taken from the client, and every attempt passes the anti-gaming guard.
+func CompleteTask(store *repository.Store, verifiers *tasks.Registry, guard *antigaming.Guard) usecase.Interactor {
 	type completeTaskRequest struct {
-		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
-		TaskID        int    `json:"task_id" required:"true" description:"Task ID to mark as completed"`
-		BadgeID       *int   `json:"badge_id" description:"Optional badge ID to track task completion for specific badge"`
-		Progress      *int   `json:"progress" description:"Current progress value for incremental tasks"`
+		Authorization  string `header:"Authorization" description:"Bearer token for user authentication"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		TaskID         int    `json:"task_id" required:"true" description:"Task ID to verify"`
+		BadgeID        *int   `json:"badge_id" description:"Optional badge ID; must be the badge awarded by the task"`
+		CompleteTaskRequest
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req completeTaskRequest, resp *TaskCompletionResponse) error {
 		// Extract user from Authorization header
-		user, err := extractUserFromAuthHeader(req.Authorization)
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
 		if err != nil {
 			*resp = TaskCompletionResponse{
 				Code:    401,
@@ -275,42 +319,203 @@ func CompleteTask() usecase.Interactor {
 			return nil
 		}
 
-		// Mock task completion logic
-		taskName := fmt.Sprintf("Task_%d", req.TaskID)
-		progress := 100 // Default to 100% completion
-		if req.Progress != nil {
-			progress = *req.Progress
+		task, err := store.Tasks.GetTask(ctx, req.TaskID)
+		if errors.Is(err, repository.ErrNotFound) {
+			*resp = TaskCompletionResponse{
+				Code:    404,
+				Message: "Task not found",
+				Data:    TaskCompletionData{},
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		// Each task awards exactly one badge
+		if req.BadgeID != nil && *req.BadgeID != task.BadgeID {
+			*resp = TaskCompletionResponse{
+				Code:    400,
+				Message: fmt.Sprintf("Badge %d is not awarded by task %d", *req.BadgeID, task.ID),
+				Data:    TaskCompletionData{},
+			}
+			return nil
+		}
+
+		if req.TaskType != "" && req.TaskType != task.Type {
+			*resp = TaskCompletionResponse{
+				Code:    400,
+				Message: fmt.Sprintf("Task %d has type %s, not %s", task.ID, task.Type, req.TaskType),
+				Data:    TaskCompletionData{},
+			}
+			return nil
+		}
+
+		// The badge's requirements are the task's conditions; a task without a badge needs its own type once
+		requirements := []repository.Requirement{{Type: task.Type, Value: 1}}
+		if task.BadgeID != 0 {
+			def, err := store.Badges.GetDefinition(ctx, task.BadgeID)
+			if err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			if def.RetiredAt != nil {
+				*resp = TaskCompletionResponse{
+					Code:    410,
+					Message: fmt.Sprintf("Badge '%s' is retired; task %d can no longer be completed", def.Name, task.ID),
+					Data:    TaskCompletionData{},
+				}
+				return nil
+			}
+			if len(def.Requirements) > 0 {
+				requirements = def.Requirements
+			}
+		}
+
+		// The guard rejects rate-limited, duplicated, replayed and premature attempts before verification
+		attempt := antigaming.Attempt{UserID: user.ID, Task: task, Requirements: requirements, Evidence: req.Data}
+		rejection, err := guard.Admit(ctx, attempt)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if rejection != nil {
+			*resp = TaskCompletionResponse{
+				Code:    antigaming.Code(rejection),
+				Message: rejection.Reason,
+				Data:    TaskCompletionData{},
+			}
+			return nil
+		}
+
+		evaluation, err := verifiers.Evaluate(ctx, store, user, requirements, tasks.Evidence(req.Data))
+		var evidenceErr *tasks.EvidenceError
+		switch {
+		case errors.As(err, &evidenceErr):
+			if _, err := guard.Reject(ctx, attempt, antigaming.RuleEvidence, evidenceErr.Error()); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			*resp = TaskCompletionResponse{
+				Code:    400,
+				Message: evidenceErr.Error(),
+				Data:    TaskCompletionData{},
+			}
+			return nil
+		case errors.Is(err, tasks.ErrUnknownTaskType):
+			*resp = TaskCompletionResponse{
+				Code:    400,
+				Message: fmt.Sprintf("Task %d cannot be verified: %v", task.ID, err),
+				Data:    TaskCompletionData{},
+			}
+			return nil
+		case err != nil:
+			return status.Wrap(err, status.Internal)
+		}
+
+		previous, err := store.Tasks.GetProgress(ctx, user.ID, task.ID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return status.Wrap(err, status.Internal)
+		}
+		previousProgress := 0
+		if previous != nil {
+			previousProgress = previous.Progress
 		}
 
+		// Outlier progress jumps wait for an admin instead of being awarded
+		decision, err := guard.Screen(ctx, attempt, previousProgress, evaluation.Progress)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if decision.Outcome == repository.GuardOutcomeReview {
+			*resp = TaskCompletionResponse{
+				Code:    antigaming.Code(decision),
+				Message: decision.Reason,
+				Data: TaskCompletionData{
+					Success:      false,
+					TaskID:       task.ID,
+					TaskName:     task.Name,
+					UserID:       user.ID,
+					Progress:     previousProgress,
+					Requirements: evaluation.Requirements,
+					ReviewID:     shared.IntPtr(decision.ID),
+				},
+			}
+			return nil
+		}
+
+		// Progress never goes back and a completed task stays completed
+		now := time.Now().UTC()
+		taskProgress := repository.TaskProgress{
+			UserID:    user.ID,
+			TaskID:    task.ID,
+			Progress:  evaluation.Progress,
+			Completed: evaluation.Completed,
+			UpdatedAt: now,
+		}
+		if previous != nil && previous.Completed {
+			taskProgress.Progress, taskProgress.Completed = 100, true
+			taskProgress.CompletedAt = previous.CompletedAt
+		} else if previous != nil && previous.Progress > taskProgress.Progress {
+			taskProgress.Progress = previous.Progress
+		}
+		justCompleted := taskProgress.Completed && taskProgress.CompletedAt == nil
+		if justCompleted {
+			taskProgress.CompletedAt = &now
+		}
+		if err := store.Tasks.SaveProgress(ctx, &taskProgress); err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		// Award the linked badge the first time the task is completed
 		badgeEarned := false
-		badgeName := ""
-		newBadgeID := 0
+		var badgeName *string
+		var badgeID *int
+		if taskProgress.Completed && task.BadgeID != 0 {
+			badgeEarned, err = earnTaskBadge(ctx, store, user.ID, task.BadgeID, now)
+			if err != nil {
+				return status.Wrap(err, status.Internal)
+			}
 
-		// Check if this task completion leads to badge earning
-		if req.BadgeID != nil {
-			badge := findMockBadgeByID(*req.BadgeID)
-			if badge != nil && progress >= 100 {
-				badgeEarned = true
-				badgeName = badge.Name
-				newBadgeID = badge.ID
+			def, err := store.Badges.GetDefinition(ctx, task.BadgeID)
+			if err != nil {
+				return status.Wrap(err, status.Internal)
 			}
+			badgeName = shared.StringPtr(def.Name)
+			badgeID = shared.IntPtr(def.ID)
+		}
+
+		nextTasks, err := pendingTaskNames(ctx, store, user.ID, 3)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		message := fmt.Sprintf("Task completed successfully for user %s", user.Nickname)
+		completedAt := ""
+		if taskProgress.Completed {
+			completedAt = shared.FormatTimestamp(*taskProgress.CompletedAt)
+		} else {
+			message = fmt.Sprintf("Task progress updated to %d%% for user %s", taskProgress.Progress, user.Nickname)
+		}
+		xpGained := 0
+		if justCompleted {
+			xpGained = 50
 		}
 
 		*resp = TaskCompletionResponse{
 			Code:    200,
-			Message: fmt.Sprintf("Task completed successfully for user %s", user.Username),
+			Message: message,
 			Data: TaskCompletionData{
-				Success:      true,
-				TaskID:       req.TaskID,
-				TaskName:     taskName,
-				UserID:       user.ID,
-				Progress:     progress,
-				CompletedAt:  shared.GetCurrentTimestamp(),
-				BadgeEarned:  badgeEarned,
-				BadgeName:    shared.StringPtr(badgeName),
-				BadgeID:      shared.IntPtr(newBadgeID),
-				NextTasks:    []string{"Complete 10 trades", "Reach $1000 volume", "Invite a friend"},
-				TotalXpGained: 50,
+				Success:       true,
+				TaskID:        task.ID,
+				TaskName:      task.Name,
+				UserID:        user.ID,
+				Progress:      taskProgress.Progress,
+				Completed:     taskProgress.Completed,
+				Requirements:  evaluation.Requirements,
+				CompletedAt:   completedAt,
+				BadgeEarned:   badgeEarned,
+				BadgeName:     badgeName,
+				BadgeID:       badgeID,
+				NextTasks:     nextTasks,
+				TotalXpGained: xpGained,
 			},
 		}
 		return nil
@@ -318,20 +523,72 @@ func CompleteTask() usecase.Interactor {
 
 	u.SetTags("Badges")
 	u.SetTitle("Complete Task")
-	u.SetDescription("Mark a task as completed and track progress toward badge requirements")
+	u.SetDescription("Verify a task against server-side data, record its progress and award the linked badge once every requirement is met. " +
+		"Rate-limited (429), duplicated or replayed (409) and premature (403) attempts are rejected; outlier progress jumps are held for review (202).")
 	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.NotFound, status.Internal)
 
 	return u
 }
 
+// ApproveTaskCompletion completes a task held for review and awards its linked badge. It reports whether
+// the badge was earned by this call.
+func ApproveTaskCompletion(ctx context.Context, store *repository.Store, userID, taskID int, at time.Time) (bool, error) {
+	task, err := store.Tasks.GetTask(ctx, taskID)
+	if err != nil {
+		return false, err
+	}
+	previous, err := store.Tasks.GetProgress(ctx, userID, taskID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return false, err
+	}
+
+	taskProgress := repository.TaskProgress{
+		UserID:      userID,
+		TaskID:      taskID,
+		Progress:    100,
+		Completed:   true,
+		CompletedAt: &at,
+		UpdatedAt:   at,
+	}
+	if previous != nil && previous.Completed && previous.CompletedAt != nil {
+		taskProgress.CompletedAt = previous.CompletedAt
+	}
+	if err := store.Tasks.SaveProgress(ctx, &taskProgress); err != nil {
+		return false, err
+	}
+	if task.BadgeID == 0 {
+		return false, nil
+	}
+	return earnTaskBadge(ctx, store, userID, task.BadgeID, at)
+}
+
+// earnTaskBadge stores the badge as owned unless the user already has it
+func earnTaskBadge(ctx context.Context, store *repository.Store, userID, badgeID int, at time.Time) (bool, error) {
+	existing, err := store.Badges.GetUserBadge(ctx, userID, badgeID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return false, err
+	}
+	if existing != nil {
+		return false, nil
+	}
+	def, err := store.Badges.GetDefinition(ctx, badgeID)
+	if err != nil {
+		return false, err
+	}
+	if err := store.Badges.SaveUserBadge(ctx, badgelifecycle.Earn(userID, def, at)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // GetBadgeLeaderboard returns leaderboard for badge achievements
-func GetBadgeLeaderboard() usecase.Interactor {
+func GetBadgeLeaderboard(store *repository.Store) usecase.Interactor {
 	type getBadgeLeaderboardRequest struct {
-		BadgeID       *int    `query:"badgeId" description:"Filter by specific badge ID"`
-		Category      *string `query:"category" description:"Filter by badge category"`
-		Limit         *int    `query:"limit" description:"Number of entries to return"`
-		Offset        *int    `query:"offset" description:"Number of entries to skip"`
-		Timeframe     *string `query:"timeframe" description:"Time period filter (daily, weekly, monthly, all-time)"`
+		BadgeID   *int    `query:"badgeId" description:"Filter by specific badge ID"`
+		Category  *string `query:"category" description:"Filter by badge category"`
+		Limit     *int    `query:"limit" description:"Number of entries to return"`
+		Offset    *int    `query:"offset" description:"Number of entries to skip"`
+		Timeframe *string `query:"timeframe" description:"Time period filter (daily, weekly, monthly, all-time)"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req getBadgeLeaderboardRequest, resp *GetBadgeLeaderboardResponse) error {
@@ -345,24 +602,41 @@ func GetBadgeLeaderboard() usecase.Interactor {
 			offset = *req.Offset
 		}
 
-		// Generate mock leaderboard data
-		leaderboard := generateMockBadgeLeaderboard()
-
-		// Apply badge ID filter if provided
-		if req.BadgeID != nil {
-			// Filter logic would be implemented here
+		leaderboard, err := buildBadgeLeaderboard(ctx, store, req.BadgeID, req.Category, req.Timeframe)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
 		}
 
-		// Apply category filter if provided
-		if req.Category != nil && *req.Category != "" {
-			// Filter logic would be implemented here
+		// Summarize before paginating so totals cover every holder
+		totalBadges := 0
+		newestAchievements := 0
+		categoryCounts := map[string]int{}
+		for _, entry := range leaderboard {
+			totalBadges += entry["badgeCount"].(int)
+			newestAchievements += entry["recentCount"].(int)
+			for category, count := range entry["categories"].(map[string]int) {
+				categoryCounts[category] += count
+			}
+			delete(entry, "recentCount")
+			delete(entry, "categories")
+		}
+		averageBadgeCount := 0.0
+		if len(leaderboard) > 0 {
+			averageBadgeCount = float64(totalBadges) / float64(len(leaderboard))
+		}
+		mostActiveCategory := ""
+		for category, count := range categoryCounts {
+			if mostActiveCategory == "" || count > categoryCounts[mostActiveCategory] ||
+				(count == categoryCounts[mostActiveCategory] && category < mostActiveCategory) {
+				mostActiveCategory = category
+			}
 		}
 
 		*resp = GetBadgeLeaderboardResponse{
 			Code:    200,
 			Message: "Badge leaderboard retrieved successfully",
 			Data: BadgeLeaderboardData{
-				Leaderboard: leaderboard,
+				Leaderboard: paginate(leaderboard, limit, offset),
 				TotalCount:  len(leaderboard),
 				Filters: map[string]interface{}{
 					"badgeId":   req.BadgeID,
@@ -377,9 +651,9 @@ func GetBadgeLeaderboard() usecase.Interactor {
 				},
 				Summary: map[string]interface{}{
 					"totalBadgeHolders":       len(leaderboard),
-					"averageBadgeCount":       7.5,
-					"mostActiveBadgeCategory": "Trading",
-					"newestAchievements":      3,
+					"averageBadgeCount":       averageBadgeCount,
+					"mostActiveBadgeCategory": mostActiveCategory,
+					"newestAchievements":      newestAchievements,
 				},
 			},
 		}
@@ -394,246 +668,51 @@ func GetBadgeLeaderboard() usecase.Interactor {
 	return u
 }
 
-// ==========================================
-// AUTHENTICATION HELPER FUNCTIONS
-// ==========================================
-
-// extractUserFromAuthHeader extracts and validates user from Authorization header
-func extractUserFromAuthHeader(authHeader string) (*public.UserBasicInfo, error) {
-	accessToken, err := shared.ExtractTokenFromAuthHeader(authHeader)
-	if err != nil {
-		return nil, err
-	}
-
-	// Mock user lookup by token
-	user := mockUserLookup(accessToken)
-	if user == nil {
-		return nil, fmt.Errorf("Invalid access token")
-	}
-
-	return user, nil
-}
-
-// mockUserLookup simulates database lookup of user by access token
-func mockUserLookup(accessToken string) *public.UserBasicInfo {
-	// Mock user database
-	mockUsers := map[string]*public.UserBasicInfo{
-		"user_token_123": {
-			ID:       12345,
-			Username: "crypto_trader_01",
-			Email:    shared.StringPtr("trader@example.com"),
-			Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar123"),
-		},
-		"user_token_456": {
-			ID:       67890,
-			Username: "defi_master",
-			Email:    shared.StringPtr("defi@example.com"),
-			Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar456"),
-		},
-		"user_token_789": {
-			ID:       11111,
-			Username: "nft_collector",
-			Email:    shared.StringPtr("collector@example.com"),
-			Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar789"),
-		},
-	}
-
-	// Look up user by accessToken
-	for token, user := range mockUsers {
-		if token == accessToken {
-			return user
-		}
-	}
-
-	return nil // User not found
-}
-
-// ==========================================
-// MOCK DATA GENERATION FUNCTIONS
-// ==========================================
-
-// generateMockBadgeStats creates mock badge statistics data
-func generateMockBadgeStats() []BadgeStat {
-	return []BadgeStat{
-		{
-			Badge: Badge{
-				ID:          1,
-				Name:        "First Trade Master",
-				Description: "Complete your first trade successfully",
-				Category:    "Trading",
-				Level:       1,
-				IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon1",
-				Status:      "available",
-				Requirements: []BadgeRequirement{
-					{Type: "trade_count", Value: 1},
-				},
-				UnlockedAt: shared.StringPtr("2024-01-15T09:30:00.000Z"),
-			},
-			UnlockedCount: 5420,
-			LevelStats: []BadgeLevelStat{
-				{Total: 1, Owned: 5420, Activated: 100, Consumed: 50, CanActivateCount: 5370},
-			},
-		},
-		{
-			Badge: Badge{
-				ID:          2,
-				Name:        "Volume King",
-				Description: "Achieve $100K in total trading volume",
-				Category:    "Trading",
-				Level:       3,
-				IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon2",
-				Status:      "earned",
-				Requirements: []BadgeRequirement{
-					{Type: "trading_volume", Value: 100000},
-				},
-				UnlockedAt: shared.StringPtr("2024-01-20T14:45:00.000Z"),
-			},
-			UnlockedCount: 890,
-			LevelStats: []BadgeLevelStat{
-				{Total: 3, Owned: 2100, Activated: 200, Consumed: 100, CanActivateCount: 1900},
-				{Total: 3, Owned: 1450, Activated: 150, Consumed: 75, CanActivateCount: 1300},
-				{Total: 3, Owned: 890, Activated: 90, Consumed: 45, CanActivateCount: 800},
-			},
-		},
-		{
-			Badge: Badge{
-				ID:          3,
-				Name:        "Community Builder",
-				Description: "Invite 10 friends to join the platform",
-				Category:    "Community",
-				Level:       2,
-				IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon3",
-				Status:      "available",
-				Requirements: []BadgeRequirement{
-					{Type: "referral_count", Value: 10},
-				},
-				UnlockedAt: nil,
-			},
-			UnlockedCount: 320,
-			LevelStats: []BadgeLevelStat{
-				{Total: 2, Owned: 750, Activated: 75, Consumed: 25, CanActivateCount: 675},
-				{Total: 2, Owned: 320, Activated: 30, Consumed: 15, CanActivateCount: 290},
-			},
-		},
-	}
-}
-
-// generateMockUserBadges creates mock user badge data
-func generateMockUserBadges(userID int) []Badge {
-	return []Badge{
-		{
-			ID:          1,
-			Name:        "First Trade Master",
-			Description: "Complete your first trade successfully",
-			Category:    "Trading",
-			Level:       1,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon1",
-			Status:      "earned",
-			Requirements: []BadgeRequirement{
-				{Type: "trade_count", Value: 1},
-			},
-			UnlockedAt: shared.StringPtr("2024-01-15T09:30:00.000Z"),
-		},
-		{
-			ID:          2,
-			Name:        "Volume King",
-			Description: "Achieve $100K in total trading volume",
-			Category:    "Trading",
-			Level:       3,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon2",
-			Status:      "available",
-			Requirements: []BadgeRequirement{
-				{Type: "trading_volume", Value: 100000},
-			},
-			UnlockedAt: nil,
-		},
-		{
-			ID:          3,
-			Name:        "Community Builder",
-			Description: "Invite 10 friends to join the platform",
-			Category:    "Community",
-			Level:       2,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon3",
-			Status:      "locked",
-			Requirements: []BadgeRequirement{
-				{Type: "referral_count", Value: 10},
-			},
-			UnlockedAt: nil,
-		},
-	}
-}
-
-// generateMockBadgeLeaderboard creates mock badge leaderboard data
-func generateMockBadgeLeaderboard() []map[string]interface{} {
-	return []map[string]interface{}{
-		{
-			"userId":      12345,
-			"username":    "crypto_trader_01",
-			"badgeCount":  15,
-			"totalPoints": 3750,
-			"rank":        1,
-			"avatar":      "https://ipfs.io/ipfs/QmUserAvatar123",
-			"recentBadges": []map[string]interface{}{
-				{"name": "Volume King", "earnedAt": "2024-01-20T14:45:00.000Z"},
-				{"name": "Trading Veteran", "earnedAt": "2024-01-18T11:30:00.000Z"},
-			},
-		},
-		{
-			"userId":      67890,
-			"username":    "defi_master",
-			"badgeCount":  12,
-			"totalPoints": 3200,
-			"rank":        2,
-			"avatar":      "https://ipfs.io/ipfs/QmUserAvatar456",
-			"recentBadges": []map[string]interface{}{
-				{"name": "Community Builder", "earnedAt": "2024-01-19T16:20:00.000Z"},
-				{"name": "First Trade Master", "earnedAt": "2024-01-15T09:30:00.000Z"},
-			},
-		},
-		{
-			"userId":      11111,
-			"username":    "nft_collector",
-			"badgeCount":  8,
-			"totalPoints": 2100,
-			"rank":        3,
-			"avatar":      "https://ipfs.io/ipfs/QmUserAvatar789",
-			"recentBadges": []map[string]interface{}{
-				{"name": "First Trade Master", "earnedAt": "2024-01-16T12:15:00.000Z"},
-			},
-		},
-	}
-}
-
 // GetBadgesByLevel returns badges filtered by specific level
-func GetBadgesByLevel() usecase.Interactor {
+func GetBadgesByLevel(store *repository.Store) usecase.Interactor {
 	type getBadgesByLevelRequest struct {
 		Level *int `path:"level" required:"true" description:"Badge level to filter by"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req getBadgesByLevelRequest, resp *GetBadgesByLevelResponse) error {
 		// Validate level parameter
-		if req.Level == nil || *req.Level < 1 || *req.Level > 5 {
+		if req.Level == nil || !shared.ValidateNftLevel(*req.Level) {
 			*resp = GetBadgesByLevelResponse{
 				Code:    400,
-				Message: "Invalid badge level. Must be between 1 and 5",
+				Message: fmt.Sprintf("Invalid badge level. Must be between 1 and %d", tiers.Current().MaxLevel()),
 				Data:    GetBadgesByLevelData{},
 			}
 			return nil
 		}
 
-		// Generate mock badges filtered by level
-		allBadges := generateMockBadgesByLevel(*req.Level)
+		stats, err := loadBadgeStats(ctx, store)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		levelBadges := []Badge{}
+		totalUnlocked := 0
+		for _, stat := range stats {
+			if stat.Badge.Level == *req.Level {
+				levelBadges = append(levelBadges, stat.Badge)
+				totalUnlocked += stat.UnlockedCount
+			}
+		}
+		averageUnlocked := 0.0
+		if len(levelBadges) > 0 {
+			averageUnlocked = float64(totalUnlocked) / float64(len(levelBadges))
+		}
 
 		*resp = GetBadgesByLevelResponse{
 			Code:    200,
 			Message: fmt.Sprintf("Level %d badges retrieved successfully", *req.Level),
 			Data: GetBadgesByLevelData{
 				Level:  *req.Level,
-				Badges: allBadges,
-				Count:  len(allBadges),
+				Badges: levelBadges,
+				Count:  len(levelBadges),
 				Stats: map[string]interface{}{
-					"totalAtLevel":     len(allBadges),
-					"averageUnlocked": 45.2,
+					"totalAtLevel":    len(levelBadges),
+					"averageUnlocked": averageUnlocked,
 					"rarity":          getLevelRarity(*req.Level),
 				},
 			},
@@ -650,7 +729,7 @@ func GetBadgesByLevel() usecase.Interactor {
 }
 
 // GetBadgeStatus returns badge status and progress for user
-func GetBadgeStatus() usecase.Interactor {
+func GetBadgeStatus(store *repository.Store) usecase.Interactor {
 	type getBadgeStatusRequest struct {
 		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
 		BadgeID       *int   `query:"badgeId" description:"Specific badge ID to check status for"`
@@ -658,7 +737,7 @@ func GetBadgeStatus() usecase.Interactor {
 
 	u := usecase.NewInteractor(func(ctx context.Context, req getBadgeStatusRequest, resp *GetBadgeStatusResponse) error {
 		// Extract user from Authorization header
-		user, err := extractUserFromAuthHeader(req.Authorization)
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
 		if err != nil {
 			*resp = GetBadgeStatusResponse{
 				Code:    401,
@@ -668,12 +747,26 @@ func GetBadgeStatus() usecase.Interactor {
 			return nil
 		}
 
-		// Generate badge status data
-		badgeStatus := generateMockBadgeStatus(user.ID, req.BadgeID)
+		if req.BadgeID != nil {
+			if _, err := store.Badges.GetDefinition(ctx, *req.BadgeID); errors.Is(err, repository.ErrNotFound) {
+				*resp = GetBadgeStatusResponse{
+					Code:    404,
+					Message: "Badge not found",
+					Data:    BadgeStatusData{},
+				}
+				return nil
+			}
+		}
+
+		// Build badge status data
+		badgeStatus, err := buildBadgeStatus(ctx, store, user, req.BadgeID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
 		*resp = GetBadgeStatusResponse{
 			Code:    200,
-			Message: fmt.Sprintf("Badge status retrieved successfully for user %s", user.Username),
+			Message: fmt.Sprintf("Badge status retrieved successfully for user %s", user.Nickname),
 			Data:    badgeStatus,
 		}
 		return nil
@@ -682,22 +775,23 @@ func GetBadgeStatus() usecase.Interactor {
 	u.SetTags("Badges")
 	u.SetTitle("Get Badge Status")
 	u.SetDescription("Get badge status and progress for authenticated user")
-	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
 
 	return u
 }
 
 // ActivateBadgeForUpgrade activates badge specifically for NFT upgrades
-func ActivateBadgeForUpgrade() usecase.Interactor {
+func ActivateBadgeForUpgrade(store *repository.Store) usecase.Interactor {
 	type activateBadgeForUpgradeRequest struct {
-		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
-		BadgeID       int    `json:"badge_id" required:"true" description:"Badge ID to activate for upgrade"`
-		TargetNftId   *int   `json:"target_nft_id" description:"Target NFT ID for upgrade"`
+		Authorization  string `header:"Authorization" description:"Bearer token for user authentication"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		BadgeID        int    `json:"badge_id" required:"true" description:"Badge ID to activate for upgrade"`
+		TargetNftId    *int   `json:"target_nft_id" description:"Target NFT ID for upgrade"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req activateBadgeForUpgradeRequest, resp *ActivateBadgeForUpgradeResponse) error {
 		// Extract user from Authorization header
-		user, err := extractUserFromAuthHeader(req.Authorization)
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
 		if err != nil {
 			*resp = ActivateBadgeForUpgradeResponse{
 				Code:    401,
@@ -717,9 +811,9 @@ func ActivateBadgeForUpgrade() usecase.Interactor {
 			return nil
 		}
 
-		// Check if badge is available for upgrade activation
-		badge := findMockBadgeByID(req.BadgeID)
-		if badge == nil {
+		// Check if badge exists
+		def, err := store.Badges.GetDefinition(ctx, req.BadgeID)
+		if errors.Is(err, repository.ErrNotFound) {
 			*resp = ActivateBadgeForUpgradeResponse{
 				Code:    404,
 				Message: "Badge not found",
@@ -727,25 +821,49 @@ func ActivateBadgeForUpgrade() usecase.Interactor {
 			}
 			return nil
 		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
-		// Mock upgrade contribution calculation
-		upgradeContribution := calculateUpgradeContribution(req.BadgeID, badge.Level)
-		qualifiedFor := []int{2, 3} // Mock qualified NFT levels
-		if req.TargetNftId != nil {
-			qualifiedFor = append(qualifiedFor, *req.TargetNftId)
+		// Check if badge is available for upgrade activation
+		userBadge, err := store.Badges.GetUserBadge(ctx, user.ID, req.BadgeID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return status.Wrap(err, status.Internal)
+		}
+		current := badgelifecycle.StatusOf(userBadge)
+		if current != badgelifecycle.StatusOwned && current != badgelifecycle.StatusActivated {
+			*resp = ActivateBadgeForUpgradeResponse{
+				Code:    403,
+				Message: "Badge not earned yet or already consumed",
+				Data:    ActivateBadgeForUpgradeData{},
+			}
+			return nil
+		}
+
+		// Activating an already activated badge is a no-op
+		if current == badgelifecycle.StatusOwned {
+			if err := badgelifecycle.Activate(userBadge, time.Now().UTC(), badgelifecycle.ActivationWindow(def)); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			if err := store.Badges.SaveUserBadge(ctx, userBadge); err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+		}
+		if def, err = EarnedDefinition(ctx, store, def, userBadge); err != nil {
+			return status.Wrap(err, status.Internal)
 		}
 
 		*resp = ActivateBadgeForUpgradeResponse{
 			Code:    200,
-			Message: fmt.Sprintf("Badge '%s' activated for NFT upgrade for user %s", badge.Name, user.Username),
+			Message: fmt.Sprintf("Badge '%s' activated for NFT upgrade for user %s", def.Name, user.Nickname),
 			Data: ActivateBadgeForUpgradeData{
 				Success:               true,
 				BadgeID:               req.BadgeID,
-				BadgeName:             badge.Name,
-				ActivatedAt:           shared.GetCurrentTimestamp(),
-				UpgradeContribution:   upgradeContribution,
-				QualifiedForNftLevels: qualifiedFor,
-				ExpiresAt:             shared.StringPtr("2024-02-15T10:30:00.000Z"),
+				BadgeName:             def.Name,
+				ActivatedAt:           shared.FormatTimestamp(*userBadge.ActivatedAt),
+				UpgradeContribution:   def.ContributionValue,
+				QualifiedForNftLevels: []int{def.NftLevel},
+				ExpiresAt:             shared.FormatTimestampPtr(userBadge.ExpiresAt),
 				CanBeConsumed:         true,
 				ActivationType:        "nft_upgrade",
 			},
@@ -756,41 +874,84 @@ func ActivateBadgeForUpgrade() usecase.Interactor {
 	u.SetTags("Badges")
 	u.SetTitle("Activate Badge For Upgrade")
 	u.SetDescription("Activate badge specifically for NFT upgrade purposes")
-	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.NotFound, status.Internal)
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.PermissionDenied, status.NotFound, status.Internal)
 
 	return u
 }
 
 // GetBadgeList returns complete list of all available badges
-func GetBadgeList() usecase.Interactor {
+func GetBadgeList(store *repository.Store) usecase.Interactor {
 	type getBadgeListRequest struct {
+		Authorization *string `header:"Authorization" description:"Bearer token for user authentication (optional, adds per-user status)"`
 		Category      *string `query:"category" description:"Filter by badge category"`
 		Level         *int    `query:"level" description:"Filter by badge level"`
-		Status        *string `query:"status" description:"Filter by status (all, available, earned, locked)"`
+		Status        *string `query:"status" description:"Filter by status (all, locked, owned, activated, consumed; legacy earned and available mean owned)"`
 		Limit         *int    `query:"limit" description:"Number of badges to return"`
 		Offset        *int    `query:"offset" description:"Number of badges to skip"`
 		IncludeStats  *bool   `query:"includeStats" description:"Include badge statistics"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req getBadgeListRequest, resp *GetBadgeListResponse) error {
-		// Generate complete badge list
-		allBadges := generateMockCompleteBadgeList()
+		statusFilter, ok := parseStatusFilter(req.Status)
+		if !ok {
+			*resp = GetBadgeListResponse{
+				Code:    400,
+				Message: fmt.Sprintf("Unknown badge status %q", *req.Status),
+				Data:    BadgeListData{},
+			}
+			return nil
+		}
+
+		// Without a caller every badge is shown as locked; with one, statuses are per user
+		var allBadges []Badge
+		var err error
+		if req.Authorization != nil && *req.Authorization != "" {
+			user, authErr := auth.ExtractUserFromAuthHeader(ctx, store.Users, *req.Authorization)
+			if authErr != nil {
+				*resp = GetBadgeListResponse{
+					Code:    401,
+					Message: authErr.Error(),
+					Data:    BadgeListData{},
+				}
+				return nil
+			}
+			allBadges, err = LoadUserBadges(ctx, store, user.ID)
+		} else {
+			allBadges, err = loadCatalogBadges(ctx, store)
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
 		// Apply filters
-		filteredBadges := applyBadgeFilters(allBadges, req.Category, req.Level, req.Status)
+		filteredBadges := applyBadgeFilters(allBadges, req.Category, req.Level, statusFilter)
 
 		// Include stats if requested (note: stats are currently not used in response)
 		if req.IncludeStats != nil && *req.IncludeStats {
 			_ = generateBadgeListStats(filteredBadges)
 		}
 
+		byLevel := map[string]int{}
+		for _, badge := range filteredBadges {
+			byLevel[fmt.Sprintf("%d", badge.Level)]++
+		}
+
+		limit := 100
+		if req.Limit != nil && *req.Limit > 0 {
+			limit = *req.Limit
+		}
+		offset := 0
+		if req.Offset != nil && *req.Offset > 0 {
+			offset = *req.Offset
+		}
+
 		*resp = GetBadgeListResponse{
 			Code:    200,
 			Message: "Complete badge list retrieved successfully",
 			Data: BadgeListData{
-				Badges:     filteredBadges,
+				Badges:     paginate(filteredBadges, limit, offset),
 				TotalCount: len(filteredBadges),
-				ByLevel:    map[string]int{"1": 2, "2": 1, "3": 2, "4": 1, "5": 1},
+				ByLevel:    byLevel,
 			},
 		}
 		return nil
@@ -799,176 +960,566 @@ func GetBadgeList() usecase.Interactor {
 	u.SetTags("Badges")
 	u.SetTitle("Get Badge List")
 	u.SetDescription("Get complete list of all available badges with filtering options")
-	u.SetExpectedErrors(status.Internal)
+	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
 
 	return u
 }
 
 // ==========================================
-// HELPER FUNCTIONS FOR NEW BADGE HANDLERS
+// ACTIVITY REPORTING HANDLER
 // ==========================================
 
-// generateMockBadgesByLevel creates mock badges filtered by level
-func generateMockBadgesByLevel(level int) []Badge {
-	allBadges := generateMockCompleteBadgeList()
-	filteredBadges := []Badge{}
-	for _, badge := range allBadges {
-		if badge.Level == level {
-			filteredBadges = append(filteredBadges, badge)
+// RecordActivity records activity other services report, e.g. an invite sent or a strategy created, in the
+// counters the badge task verifiers measure. Only internal services may report activity.
+func RecordActivity(store *repository.Store, services *auth.ServiceCredentials) usecase.Interactor {
+	type recordActivityRequest struct {
+		Authorization  string `header:"Authorization" description:"Bearer service token of the reporting service"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		RecordActivityRequest
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req recordActivityRequest, resp *RecordActivityResponse) error {
+		if _, err := services.ExtractServiceFromAuthHeader(req.Authorization); err != nil {
+			*resp = RecordActivityResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    RecordActivityData{},
+			}
+			return nil
+		}
+
+		reject := func(message string) error {
+			*resp = RecordActivityResponse{
+				Code:    400,
+				Message: message,
+				Data:    RecordActivityData{},
+			}
+			return nil
+		}
+		count := 1
+		if req.Count != nil {
+			count = *req.Count
+		}
+		if count < 1 && !(req.Metric == tasks.TypeFollowerCount && count == 0) {
+			return reject("count must be positive")
+		}
+		occurredAt := time.Now()
+		if req.OccurredAt != "" {
+			at, err := time.Parse(time.RFC3339, req.OccurredAt)
+			if err != nil {
+				return reject("occurredAt must be an RFC 3339 timestamp")
+			}
+			occurredAt = at
+		}
+
+		if _, err := store.Users.GetByID(ctx, req.UserID); errors.Is(err, repository.ErrNotFound) {
+			*resp = RecordActivityResponse{
+				Code:    404,
+				Message: "User not found",
+				Data:    RecordActivityData{},
+			}
+			return nil
+		} else if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		value, err := tasks.RecordActivity(ctx, store, req.UserID, req.Metric, count, occurredAt)
+		if errors.Is(err, tasks.ErrUnreportedMetric) {
+			return reject(fmt.Sprintf("metric must be one of %v", tasks.ReportedMetrics()))
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
 		}
+		*resp = RecordActivityResponse{
+			Code:    200,
+			Message: "Activity recorded",
+			Data:    RecordActivityData{UserID: req.UserID, Metric: req.Metric, Value: value},
+		}
+		return nil
+	})
+
+	u.SetTags("Internal")
+	u.SetTitle("Record Activity")
+	u.SetDescription("Add to a user's activity counter (tutorials, invites, referrals, strategies, group joins) or set their follower count; badge tasks are verified against these counters")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// ==========================================
+// BADGE VIEW HELPERS
+// ==========================================
+
+// toBadge builds the API badge from its catalog definition and optional per-user state
+func toBadge(def repository.BadgeDefinition, task *repository.Task, userBadge *repository.UserBadge, progress *repository.TaskProgress) Badge {
+	requirements := make([]BadgeRequirement, 0, len(def.Requirements))
+	for _, r := range def.Requirements {
+		requirements = append(requirements, BadgeRequirement{Type: r.Type, Value: r.Value})
 	}
-	return filteredBadges
+
+	badge := Badge{
+		ID:                def.ID,
+		NftLevel:          def.NftLevel,
+		Name:              def.Name,
+		Description:       def.Description,
+		Category:          def.Category,
+		Level:             def.Level,
+		IconURI:           def.IconURL,
+		IconURL:           def.IconURL,
+		TaskID:            def.TaskID,
+		ContributionValue: def.ContributionValue,
+		Status:            string(badgelifecycle.StatusOf(userBadge)),
+		Requirements:      requirements,
+		Version:           def.Version,
+		Retired:           def.RetiredAt != nil,
+	}
+	if task != nil {
+		badge.TaskName = task.Name
+	}
+	if progress != nil {
+		badge.TaskProgress = progress.Progress
+		badge.TaskCompleted = progress.Completed
+	}
+	if userBadge != nil {
+		badge.EarnedAt = shared.FormatTimestampPtr(userBadge.EarnedAt)
+		badge.ActivatedAt = shared.FormatTimestampPtr(userBadge.ActivatedAt)
+		if badge.Status == string(badgelifecycle.StatusActivated) {
+			badge.ExpiresAt = shared.FormatTimestampPtr(userBadge.ExpiresAt)
+		}
+		badge.ConsumedAt = shared.FormatTimestampPtr(userBadge.ConsumedAt)
+		badge.UnlockedAt = badge.EarnedAt
+		badge.CanActivate = badgelifecycle.CanTransition(badgelifecycle.StatusOf(userBadge), badgelifecycle.StatusActivated)
+	}
+	return badge
 }
 
-// getLevelRarity returns rarity description for badge level
-func getLevelRarity(level int) string {
-	rarities := []string{"", "Common", "Uncommon", "Rare", "Epic", "Legendary"}
-	if level >= 1 && level <= 5 {
-		return rarities[level]
+// loadCatalogBadges returns every catalog badge without user-specific state
+func loadCatalogBadges(ctx context.Context, store *repository.Store) ([]Badge, error) {
+	defs, err := store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return "Unknown"
+	tasks, err := tasksByID(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	badges := make([]Badge, 0, len(defs))
+	for _, def := range defs {
+		if def.RetiredAt != nil {
+			continue
+		}
+		badges = append(badges, toBadge(def, tasks[def.TaskID], nil, nil))
+	}
+	return badges, nil
+}
+
+// LoadUserBadges returns every catalog badge with the given user's status and task progress
+func LoadUserBadges(ctx context.Context, store *repository.Store, userID int) ([]Badge, error) {
+	defs, err := store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := tasksByID(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := store.Badges.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ownedByID := make(map[int]*repository.UserBadge, len(owned))
+	for i := range owned {
+		ownedByID[owned[i].BadgeID] = &owned[i]
+	}
+
+	progress, err := store.Tasks.ListProgressByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	progressByTask := make(map[int]*repository.TaskProgress, len(progress))
+	for i := range progress {
+		progressByTask[progress[i].TaskID] = &progress[i]
+	}
+
+	currentLevel, err := activeNftLevel(ctx, store, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	badges := make([]Badge, 0, len(defs))
+	for _, def := range defs {
+		userBadge := ownedByID[def.ID]
+		// Retired badges stay with their holders only; held badges show the version they were earned under
+		if def.RetiredAt != nil && userBadge == nil {
+			continue
+		}
+		earned, err := EarnedDefinition(ctx, store, &def, userBadge)
+		if err != nil {
+			return nil, err
+		}
+		badge := toBadge(*earned, tasks[def.TaskID], userBadge, progressByTask[def.TaskID])
+		badge.Retired = def.RetiredAt != nil
+		badge.IsRequiredForUpgrade = earned.NftLevel == currentLevel+1
+		badges = append(badges, badge)
+	}
+	return badges, nil
+}
+
+// EarnedDefinition returns the definition a held badge was earned under; def is the current definition,
+// returned as is when the user does not hold the badge or it has not changed since
+func EarnedDefinition(ctx context.Context, store *repository.Store, def *repository.BadgeDefinition,
+	userBadge *repository.UserBadge) (*repository.BadgeDefinition, error) {
+	if userBadge == nil {
+		return def, nil
+	}
+	version := userBadge.DefinitionVersion
+	if version == 0 {
+		version = 1
+	}
+	if version == def.Version {
+		return def, nil
+	}
+	earned, err := store.Badges.GetDefinitionVersion(ctx, def.ID, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		return def, nil
+	}
+	return earned, err
+}
+
+// loadBadgeStats aggregates holder counts for every catalog badge
+func loadBadgeStats(ctx context.Context, store *repository.Store) ([]BadgeStat, error) {
+	badges, err := loadCatalogBadges(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	totalByLevel := map[int]int{}
+	for _, badge := range badges {
+		totalByLevel[badge.NftLevel]++
+	}
+
+	stats := make([]BadgeStat, 0, len(badges))
+	for _, badge := range badges {
+		holders, err := store.Badges.ListHolders(ctx, badge.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		levelStat := BadgeLevelStat{Total: totalByLevel[badge.NftLevel]}
+		for _, holder := range holders {
+			switch badgelifecycle.StatusOf(&holder) {
+			case badgelifecycle.StatusOwned:
+				levelStat.Owned++
+				levelStat.CanActivateCount++
+			case badgelifecycle.StatusActivated:
+				levelStat.Activated++
+			case badgelifecycle.StatusConsumed:
+				levelStat.Consumed++
+			}
+		}
+
+		stats = append(stats, BadgeStat{
+			Badge:         badge,
+			UnlockedCount: len(holders),
+			LevelStats:    []BadgeLevelStat{levelStat},
+		})
+	}
+	return stats, nil
 }
 
-// generateMockBadgeStatus creates mock badge status data
-func generateMockBadgeStatus(userID int, badgeID *int) BadgeStatusData {
+// tasksByID indexes every task definition by ID
+func tasksByID(ctx context.Context, store *repository.Store) (map[int]*repository.Task, error) {
+	tasks, err := store.Tasks.ListTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*repository.Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+	return byID, nil
+}
+
+// activeNftLevel returns the level of the user's active tiered NFT, 0 if none
+func activeNftLevel(ctx context.Context, store *repository.Store, userID int) (int, error) {
+	nfts, err := store.TieredNfts.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, nft := range nfts {
+		if nft.Status == repository.NftStatusActive {
+			return nft.Level, nil
+		}
+	}
+	return 0, nil
+}
+
+// activatedTotals returns the number and total contribution value of the user's activated badges
+func activatedTotals(ctx context.Context, store *repository.Store, userID int) (int, float64, error) {
+	owned, err := store.Badges.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	count := 0
+	total := 0.0
+	for _, userBadge := range owned {
+		if badgelifecycle.StatusOf(&userBadge) != badgelifecycle.StatusActivated {
+			continue
+		}
+		def, err := store.Badges.GetDefinition(ctx, userBadge.BadgeID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if def, err = EarnedDefinition(ctx, store, def, &userBadge); err != nil {
+			return 0, 0, err
+		}
+		count++
+		total += def.ContributionValue
+	}
+	return count, total, nil
+}
+
+// pendingTaskNames returns up to max names of tasks the user has not completed yet
+func pendingTaskNames(ctx context.Context, store *repository.Store, userID, max int) ([]string, error) {
+	tasks, err := store.Tasks.ListTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := store.Tasks.ListProgressByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	completed := map[int]bool{}
+	for _, p := range progress {
+		completed[p.TaskID] = p.Completed
+	}
+	defs, err := store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	retired := map[int]bool{}
+	for _, def := range defs {
+		retired[def.ID] = def.RetiredAt != nil
+	}
+
+	names := []string{}
+	for _, task := range tasks {
+		if len(names) == max {
+			break
+		}
+		if !completed[task.ID] && !retired[task.BadgeID] {
+			names = append(names, task.Name)
+		}
+	}
+	return names, nil
+}
+
+// ==========================================
+// HELPER FUNCTIONS FOR BADGE HANDLERS
+// ==========================================
+
+// requiredBadgesForLevel returns how many activated badges an upgrade to the given level needs
+func requiredBadgesForLevel(level int) int {
+	tier, _ := tiers.Current().Tier(level)
+	return tier.RequiredBadges
+}
+
+// buildBadgeStatus computes badge status and upgrade progress for a user
+func buildBadgeStatus(ctx context.Context, store *repository.Store, user *repository.User, badgeID *int) (BadgeStatusData, error) {
+	badges, err := LoadUserBadges(ctx, store, user.ID)
+	if err != nil {
+		return BadgeStatusData{}, err
+	}
+	currentLevel, err := activeNftLevel(ctx, store, user.ID)
+	if err != nil {
+		return BadgeStatusData{}, err
+	}
+
+	data := BadgeStatusData{
+		UserID:          user.ID,
+		CurrentNftLevel: currentLevel,
+		TotalBadges:     len(badges),
+	}
+	if next, ok := tiers.Current().Next(currentLevel); ok {
+		data.NextNftLevel = next.Level
+	}
+
+	for _, badge := range badges {
+		if badge.TaskCompleted {
+			data.CompletedTasks++
+		} else {
+			data.PendingTasks++
+		}
+		switch badgelifecycle.Status(badge.Status) {
+		case badgelifecycle.StatusActivated:
+			data.ActivatedBadges++
+			data.TotalContributionValue += badge.ContributionValue
+		case badgelifecycle.StatusConsumed:
+			data.ConsumedBadges++
+		}
+	}
+
+	if data.NextNftLevel > 0 {
+		required := requiredBadgesForLevel(data.NextNftLevel)
+		data.RequiredForUpgrade = float64(required)
+		data.CanUpgrade = data.ActivatedBadges >= required
+
+		progress := 100.0
+		if required > 0 {
+			progress = float64(data.ActivatedBadges) / float64(required) * 100
+			if progress > 100 {
+				progress = 100
+			}
+		}
+		data.NextMilestone = BadgeMilestone{
+			Level:          data.NextNftLevel,
+			RequiredBadges: required,
+			RequiredValue:  float64(required),
+			Progress:       progress,
+		}
+	}
+
 	if badgeID != nil {
-		// Specific badge status
-		badge := findMockBadgeByID(*badgeID)
-		if badge == nil {
-			return BadgeStatusData{}
-		}
-
-		progress := (userID * 13) % 100 // Mock progress calculation
-		return BadgeStatusData{
-			UserID:       userID,
-			TotalBadges:  1,
-			ActivatedBadges: 0,
-			CanUpgrade:   false,
-			NextMilestone: BadgeMilestone{
-				Level:     2,
-				Progress:  float64(progress),
-				EstimatedTime: "2024-02-01T00:00:00.000Z",
-			},
+		for _, badge := range badges {
+			if badge.ID == *badgeID {
+				data.Badges = []Badge{badge}
+			}
 		}
 	}
 
-	// Overall badge status for user
-	return BadgeStatusData{
-		UserID:          userID,
-		TotalBadges:     12,
-		ActivatedBadges: 5,
-		CanUpgrade:      false,
-		NextMilestone: BadgeMilestone{
-			Level:     3,
-			Progress:  45.5,
-			EstimatedTime: "2 weeks",
-		},
+	return data, nil
+}
+
+// buildBadgeLeaderboard ranks users by the number of badges they have earned
+func buildBadgeLeaderboard(ctx context.Context, store *repository.Store, badgeID *int, category *string, timeframe *string) ([]map[string]interface{}, error) {
+	defs, err := store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defsByID := make(map[int]repository.BadgeDefinition, len(defs))
+	for _, def := range defs {
+		defsByID[def.ID] = def
+	}
+
+	users, err := store.Users.List(ctx)
+	if err != nil {
+		return nil, err
 	}
+
+	now := time.Now().UTC()
+	since := timeframeStart(now, timeframe)
+	recentSince := now.AddDate(0, 0, -7)
+
+	leaderboard := []map[string]interface{}{}
+	for _, user := range users {
+		owned, err := store.Badges.ListByUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		earned := []repository.UserBadge{}
+		for _, userBadge := range owned {
+			def := defsByID[userBadge.BadgeID]
+			if badgeID != nil && userBadge.BadgeID != *badgeID {
+				continue
+			}
+			if category != nil && *category != "" && def.Category != *category {
+				continue
+			}
+			if userBadge.EarnedAt == nil || userBadge.EarnedAt.Before(since) {
+				continue
+			}
+			earned = append(earned, userBadge)
+		}
+		if len(earned) == 0 {
+			continue
+		}
+
+		// Most recently earned first
+		sort.Slice(earned, func(i, j int) bool { return earned[i].EarnedAt.After(*earned[j].EarnedAt) })
+
+		totalPoints := 0.0
+		recentCount := 0
+		categories := map[string]int{}
+		recentBadges := []map[string]interface{}{}
+		for _, userBadge := range earned {
+			def := defsByID[userBadge.BadgeID]
+			totalPoints += def.ContributionValue
+			categories[def.Category]++
+			if userBadge.EarnedAt.After(recentSince) {
+				recentCount++
+			}
+			if len(recentBadges) < 2 {
+				recentBadges = append(recentBadges, map[string]interface{}{
+					"name":     def.Name,
+					"earnedAt": shared.FormatTimestamp(*userBadge.EarnedAt),
+				})
+			}
+		}
+
+		leaderboard = append(leaderboard, map[string]interface{}{
+			"userId":       user.ID,
+			"username":     user.Username,
+			"badgeCount":   len(earned),
+			"totalPoints":  totalPoints,
+			"avatar":       user.ProfilePhotoURL,
+			"recentBadges": recentBadges,
+			"recentCount":  recentCount,
+			"categories":   categories,
+		})
+	}
+
+	sort.SliceStable(leaderboard, func(i, j int) bool {
+		if leaderboard[i]["badgeCount"].(int) != leaderboard[j]["badgeCount"].(int) {
+			return leaderboard[i]["badgeCount"].(int) > leaderboard[j]["badgeCount"].(int)
+		}
+		return leaderboard[i]["totalPoints"].(float64) > leaderboard[j]["totalPoints"].(float64)
+	})
+	for i, entry := range leaderboard {
+		entry["rank"] = i + 1
+	}
+
+	return leaderboard, nil
 }
 
-// calculateUpgradeContribution calculates badge contribution to NFT upgrade
-func calculateUpgradeContribution(badgeID, level int) float64 {
-	baseContribution := float64(level) * 0.25
-	specialBadgeBonus := 0.0
-	if badgeID == 2 { // Volume King gets bonus
-		specialBadgeBonus = 0.15
+// timeframeStart returns the earliest timestamp included in a leaderboard timeframe
+func timeframeStart(now time.Time, timeframe *string) time.Time {
+	if timeframe == nil {
+		return time.Time{}
+	}
+	switch *timeframe {
+	case "daily":
+		return now.AddDate(0, 0, -1)
+	case "weekly":
+		return now.AddDate(0, 0, -7)
+	case "monthly":
+		return now.AddDate(0, -1, 0)
+	default:
+		return time.Time{}
 	}
-	return baseContribution + specialBadgeBonus
 }
 
-// generateMockCompleteBadgeList creates complete list of all badges
-func generateMockCompleteBadgeList() []Badge {
-	return []Badge{
-		{
-			ID:          1,
-			Name:        "First Trade Master",
-			Description: "Complete your first trade successfully",
-			Category:    "Trading",
-			Level:       1,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon1",
-			Status:      "available",
-			Requirements: []BadgeRequirement{
-				{Type: "trade_count", Value: 1},
-			},
-			ContributionValue: 0.5,
-			UnlockedAt: shared.StringPtr("2024-01-15T09:30:00.000Z"),
-		},
-		{
-			ID:          2,
-			Name:        "Volume King",
-			Description: "Achieve $100K in total trading volume",
-			Category:    "Trading",
-			Level:       3,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon2",
-			Status:      "available",
-			Requirements: []BadgeRequirement{
-				{Type: "trading_volume", Value: 100000},
-			},
-			ContributionValue: 2.0,
-			UnlockedAt: nil,
-		},
-		{
-			ID:          3,
-			Name:        "Community Builder",
-			Description: "Invite 10 friends to join the platform",
-			Category:    "Community",
-			Level:       2,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon3",
-			Status:      "available",
-			Requirements: []BadgeRequirement{
-				{Type: "referral_count", Value: 10},
-			},
-			ContributionValue: 1.0,
-			UnlockedAt: nil,
-		},
-		{
-			ID:          4,
-			Name:        "Achievement Hunter",
-			Description: "Unlock 5 different badges",
-			Category:    "Achievement",
-			Level:       3,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon4",
-			Status:      "locked",
-			Requirements: []BadgeRequirement{
-				{Type: "badge_count", Value: 5},
-			},
-			ContributionValue: 1.5,
-			UnlockedAt: nil,
-		},
-		{
-			ID:          5,
-			Name:        "Elite Trader",
-			Description: "Maintain consistent trading for 30 days",
-			Category:    "Trading",
-			Level:       4,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon5",
-			Status:      "locked",
-			Requirements: []BadgeRequirement{
-				{Type: "consecutive_days", Value: 30},
-				{Type: "daily_volume", Value: 1000},
-			},
-			ContributionValue: 3.0,
-			UnlockedAt: nil,
-		},
-		{
-			ID:          6,
-			Name:        "Competition Champion",
-			Description: "Win a trading competition",
-			Category:    "Competition",
-			Level:       5,
-			IconURL:     "https://ipfs.io/ipfs/QmBadgeIcon6",
-			Status:      "locked",
-			Requirements: []BadgeRequirement{
-				{Type: "competition_win", Value: 1},
-			},
-			ContributionValue: 5.0,
-			UnlockedAt: nil,
-		},
+// getLevelRarity returns rarity description for badge level
+func getLevelRarity(level int) string {
+	if tier, ok := tiers.Current().Tier(level); ok {
+		return tier.Rarity
 	}
+	return "Unknown"
+}
+
+// parseStatusFilter maps a status query value to its badge state; "" means no filter
+func parseStatusFilter(value *string) (badgelifecycle.Status, bool) {
+	if value == nil || *value == "" || *value == "all" {
+		return "", true
+	}
+	return badgelifecycle.ParseFilter(*value)
 }
 
-// applyBadgeFilters applies filtering to badge list
-func applyBadgeFilters(badges []Badge, category *string, level *int, status *string) []Badge {
+// applyBadgeFilters applies filtering to badge list; an empty status matches every badge
+func applyBadgeFilters(badges []Badge, category *string, level *int, status badgelifecycle.Status) []Badge {
 	filteredBadges := []Badge{}
 	for _, badge := range badges {
 		// Category filter
@@ -980,7 +1531,7 @@ func applyBadgeFilters(badges []Badge, category *string, level *int, status *str
 			continue
 		}
 		// Status filter
-		if status != nil && *status != "" && *status != "all" && badge.Status != *status {
+		if status != "" && badge.Status != string(status) {
 			continue
 		}
 		filteredBadges = append(filteredBadges, badge)
@@ -1001,24 +1552,22 @@ func generateBadgeListStats(badges []Badge) map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"totalBadges":     len(badges),
-		"byCategory":      categoryCounts,
-		"byLevel":         levelCounts,
-		"byStatus":        statusCounts,
-		"averageLevel":    2.8,
-		"highestValue":    5.0,
-		"mostPopular":     "Trading",
-		"generatedAt":     shared.GetCurrentTimestamp(),
+		"totalBadges": len(badges),
+		"byCategory":  categoryCounts,
+		"byLevel":     levelCounts,
+		"byStatus":    statusCounts,
+		"generatedAt": shared.GetCurrentTimestamp(),
 	}
 }
 
-// findMockBadgeByID finds a mock badge by ID
-func findMockBadgeByID(badgeID int) *Badge {
-	badges := generateMockBadgeStats()
-	for _, stat := range badges {
-		if stat.Badge.ID == badgeID {
-			return &stat.Badge
-		}
+// paginate returns the requested page of a slice
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
 	}
-	return nil
+	return items[offset:end]
 }
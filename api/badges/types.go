@@ -1,5 +1,7 @@
 package badges
 
+import "github.com/aiw3/nft-solana-api/tasks"
+
 // ==========================================
 // BADGE TYPES
 // ==========================================
@@ -7,7 +9,7 @@ package badges
 // Badge represents a badge with user-specific data
 type Badge struct {
 	ID                   int                `json:"id" example:"1" description:"Unique badge identifier"`
-	NftLevel             int                `json:"nftLevel" example:"3" description:"NFT level required to earn this badge (1-5)" minimum:"1" maximum:"5"`
+	NftLevel             int                `json:"nftLevel" example:"3" description:"NFT level required to earn this badge (1-5)" minimum:"1"`
 	Name                 string             `json:"name" example:"The Contract Enlightener" description:"Display name of the badge" maxLength:"100"`
 	Description          string             `json:"description" example:"Complete the contract novice guidance tutorial" description:"Detailed description of what the badge represents" maxLength:"500"`
 	Category             string             `json:"category" example:"Trading" description:"Badge category" maxLength:"100"`
@@ -17,9 +19,10 @@ type Badge struct {
 	TaskID               int                `json:"taskId" example:"101" description:"Associated task identifier for earning this badge"`
 	TaskName             string             `json:"taskName" example:"Contract Tutorial" description:"Name of the task required to earn this badge" maxLength:"100"`
 	ContributionValue    float64            `json:"contributionValue" example:"1.5" description:"Points this badge contributes toward NFT upgrades" minimum:"0"`
-	Status               string             `json:"status" example:"available" description:"Current status of this badge for the user" enum:"[not_earned,available,activated,consumed]"`
+	Status               string             `json:"status" example:"owned" description:"Current status of this badge for the user" enum:"[locked,owned,activated,consumed]"`
 	EarnedAt             *string            `json:"earnedAt,omitempty" example:"2024-01-10T08:30:00.000Z" description:"ISO timestamp when badge was earned (null if not earned)" format:"date-time"`
 	ActivatedAt          *string            `json:"activatedAt,omitempty" example:"2024-01-12T10:15:00.000Z" description:"ISO timestamp when badge was activated (null if not activated)" format:"date-time"`
+	ExpiresAt            *string            `json:"expiresAt,omitempty" example:"2024-02-11T10:15:00.000Z" description:"ISO timestamp when the activation stops counting towards upgrades (null if activated without expiry)" format:"date-time"`
 	ConsumedAt           *string            `json:"consumedAt,omitempty" example:"2024-01-20T16:30:00.000Z" description:"ISO timestamp when badge was consumed for upgrade (null if not consumed)" format:"date-time"`
 	UnlockedAt           *string            `json:"unlockedAt,omitempty" example:"2024-01-12T10:15:00.000Z" description:"ISO timestamp when badge was unlocked" format:"date-time"`
 	CanActivate          bool               `json:"canActivate" example:"true" description:"Whether user can currently activate this badge (only for available badges)"`
@@ -27,6 +30,8 @@ type Badge struct {
 	Requirements         []BadgeRequirement `json:"requirements" description:"Array of requirements to earn this badge"`
 	TaskProgress         int                `json:"taskProgress" example:"100" description:"Current progress on the associated task (0-100)" minimum:"0" maximum:"100"`
 	TaskCompleted        bool               `json:"taskCompleted" example:"true" description:"Whether the associated task is completed (task can be completed without badge being earned)"`
+	Version              int                `json:"version" example:"1" description:"Definition version shown; a held badge keeps the version it was earned under"`
+	Retired              bool               `json:"retired" example:"false" description:"Whether the badge is retired and can no longer be earned"`
 }
 
 // BadgeStats represents badge statistics
@@ -37,7 +42,7 @@ type BadgeStats struct {
 	ConsumedBadges          int                       `json:"consumedBadges" example:"1" description:"Number of badges consumed for NFT upgrades" minimum:"0"`
 	TotalContributionValue  float64                   `json:"totalContributionValue" example:"1.0" description:"Total points from activated badges towards upgrades" minimum:"0"`
 	ByLevel                 map[string]BadgeLevelStat `json:"byLevel" description:"Badge statistics grouped by NFT level (keys: '1','2','3','4','5')"`
-	CurrentNftLevel         int                       `json:"currentNftLevel" example:"3" description:"User's current NFT level" minimum:"0" maximum:"5"`
+	CurrentNftLevel         int                       `json:"currentNftLevel" example:"3" description:"User's current NFT level" minimum:"0"`
 	NextLevelRequiredBadges int                       `json:"nextLevelRequiredBadges" example:"0" description:"Number of additional badges needed for next level" minimum:"0"`
 }
 
@@ -94,8 +99,23 @@ type ActivateBadgeRequest struct {
 
 // CompleteTaskRequest represents task completion request
 type CompleteTaskRequest struct {
-	TaskType string                 `json:"task_type" example:"tutorial_complete" description:"Type of task being completed" required:"true"`
-	Data     map[string]interface{} `json:"data,omitempty" description:"Additional task-specific completion data (varies by task type)"`
+	TaskType string                 `json:"task_type,omitempty" example:"tutorial_complete" description:"Type of task being completed; must match the task's type when given"`
+	Data     map[string]interface{} `json:"data,omitempty" description:"Task-specific evidence checked against server-side data (e.g. count, volume, days)"`
+}
+
+// RecordActivityRequest reports a user's activity on the platform
+type RecordActivityRequest struct {
+	UserID     int    `json:"userId" example:"12345" description:"User the activity belongs to" minimum:"1" required:"true"`
+	Metric     string `json:"metric" example:"invite_friend" description:"Activity counter" enum:"follower_count,group_join,group_tutorial_complete,invite_friend,referral_first_trade,strategy_create,strategy_tutorial_complete,tutorial_complete" required:"true"`
+	Count      *int   `json:"count,omitempty" example:"1" description:"Occurrences to add, 1 when omitted; for follower_count, the current number of followers" minimum:"0"`
+	OccurredAt string `json:"occurredAt,omitempty" example:"2024-02-20T14:30:00Z" description:"When the activity happened, now when omitted; the first group join starts group membership" format:"date-time"`
+}
+
+// RecordActivityData holds the counter after the activity was recorded
+type RecordActivityData struct {
+	UserID int    `json:"userId" example:"12345" description:"User the activity belongs to"`
+	Metric string `json:"metric" example:"invite_friend" description:"Activity counter"`
+	Value  int    `json:"value" example:"4" description:"Counter value after recording the activity"`
 }
 
 // CompleteTaskData represents task completion data structure
@@ -115,8 +135,8 @@ type CompleteTaskData struct {
 // BadgeStatusData represents badge status information
 type BadgeStatusData struct {
 	UserID                 int                    `json:"userId" example:"12345" description:"Unique user identifier" minimum:"1"`
-	CurrentNftLevel        int                    `json:"currentNftLevel" example:"3" description:"User's current NFT level (0-5)" minimum:"0" maximum:"5"`
-	NextNftLevel           int                    `json:"nextNftLevel" example:"4" description:"Next NFT level user can upgrade to (0-5)" minimum:"0" maximum:"5"`
+	CurrentNftLevel        int                    `json:"currentNftLevel" example:"3" description:"User's current NFT level (0-5)" minimum:"0"`
+	NextNftLevel           int                    `json:"nextNftLevel" example:"4" description:"Next NFT level user can upgrade to (0-5)" minimum:"0"`
 	TotalBadges            int                    `json:"totalBadges" example:"12" description:"Total number of badges available to the user" minimum:"0"`
 	CompletedTasks         int                    `json:"completedTasks" example:"8" description:"Number of tasks the user has completed" minimum:"0"`
 	PendingTasks           int                    `json:"pendingTasks" example:"4" description:"Number of tasks still pending completion" minimum:"0"`
@@ -133,7 +153,7 @@ type BadgeStatusData struct {
 
 // BadgeMilestone represents next milestone information
 type BadgeMilestone struct {
-	Level          int     `json:"level" example:"4" description:"Next NFT level that can be reached (1-5)" minimum:"1" maximum:"5"`
+	Level          int     `json:"level" example:"4" description:"Next NFT level that can be reached (1-5)" minimum:"1"`
 	RequiredBadges int     `json:"requiredBadges" example:"3" description:"Number of badges required to reach this milestone" minimum:"0"`
 	RequiredValue  float64 `json:"requiredValue" example:"6.0" description:"Total contribution value needed for this milestone" minimum:"0"`
 	Progress       float64 `json:"progress" example:"75.0" description:"Current progress towards this milestone as percentage (0-100)" minimum:"0" maximum:"100"`
@@ -147,6 +167,7 @@ type ActivateBadgeData struct {
 	ActivatedAt       string  `json:"activatedAt" example:"2024-02-20T14:30:00.000Z" description:"ISO timestamp when badge was activated" format:"date-time"`
 	ContributionValue float64 `json:"contributionValue" example:"1.5" description:"Contribution value this badge provides toward upgrades" minimum:"0"`
 	NewTotalValue     float64 `json:"newTotalValue" example:"4.5" description:"User's new total contribution value after activation" minimum:"0"`
+	ExpiresAt         *string `json:"expiresAt,omitempty" example:"2024-03-21T14:30:00.000Z" description:"ISO timestamp when the activation expires (omitted if it never expires)" format:"date-time"`
 	Contributes       bool    `json:"contributes,omitempty" example:"true" description:"Whether this badge contributes to upgrade requirements (optional field)"`
 	NewStatus         string  `json:"newStatus,omitempty" example:"activated" description:"New status of the badge after activation (optional field)" enum:"[activated,consumed]"`
 	TotalActivated    int     `json:"totalActivated,omitempty" example:"3" description:"Total number of badges user has activated (optional field)" minimum:"0"`
@@ -200,6 +221,13 @@ type CompleteTaskResponse struct {
 	Data    CompleteTaskData `json:"data"`
 }
 
+// RecordActivityResponse represents wrapped activity recording response
+type RecordActivityResponse struct {
+	Code    int                `json:"code"`
+	Message string             `json:"message"`
+	Data    RecordActivityData `json:"data"`
+}
+
 // GetBadgeStatusResponse represents wrapped badge status response
 type GetBadgeStatusResponse struct {
 	Code    int             `json:"code"`
@@ -269,17 +297,20 @@ type TaskCompletionResponse struct {
 
 // TaskCompletionData represents task completion response data
 type TaskCompletionData struct {
-	Success       bool     `json:"success"`
-	TaskID        int      `json:"taskId"`
-	TaskName      string   `json:"taskName"`
-	UserID        int      `json:"userId"`
-	Progress      int      `json:"progress"`
-	CompletedAt   string   `json:"completedAt"`
-	BadgeEarned   bool     `json:"badgeEarned"`
-	BadgeName     *string  `json:"badgeName,omitempty"`
-	BadgeID       *int     `json:"badgeId,omitempty"`
-	NextTasks     []string `json:"nextTasks"`
-	TotalXpGained int      `json:"totalXpGained"`
+	Success       bool                        `json:"success"`
+	TaskID        int                         `json:"taskId"`
+	TaskName      string                      `json:"taskName"`
+	UserID        int                         `json:"userId"`
+	Progress      int                         `json:"progress"`
+	Completed     bool                        `json:"completed"`
+	Requirements  []tasks.RequirementProgress `json:"requirements"`
+	CompletedAt   string                      `json:"completedAt"`
+	BadgeEarned   bool                        `json:"badgeEarned"`
+	BadgeName     *string                     `json:"badgeName,omitempty"`
+	BadgeID       *int                        `json:"badgeId,omitempty"`
+	NextTasks     []string                    `json:"nextTasks"`
+	TotalXpGained int                         `json:"totalXpGained"`
+	ReviewID      *int                        `json:"reviewId,omitempty" description:"Set when the completion is held for admin review"`
 }
 
 // GetBadgeLeaderboardResponse represents badge leaderboard response
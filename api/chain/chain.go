@@ -0,0 +1,153 @@
+package chain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// ==========================================
+// CHAIN CLIENT
+// ==========================================
+
+// MintRequest describes an NFT to mint into the owner's wallet
+type MintRequest struct {
+	// IdempotencyKey identifies the mint across retries; repeating a key returns the original mint
+	IdempotencyKey string
+	OwnerWallet    string
+	Name           string // on-chain name, e.g. AIW3-L1-Chicken-#12
+	Symbol         string
+	Level          int
+}
+
+// MintResult holds the on-chain accounts and metadata created by a mint
+type MintResult struct {
+	MintAddress   string
+	ATAAddress    string
+	MetadataPDA   string
+	MetadataURI   string
+	ImageURI      string
+	TransactionID string
+}
+
+// BurnRequest describes an NFT to burn from the owner's wallet
+type BurnRequest struct {
+	OwnerWallet string
+	MintAddress string
+}
+
+// BurnResult holds the burn transaction
+type BurnResult struct {
+	TransactionID string
+}
+
+// Client mints and burns NFTs on Solana (uploading metadata to IPFS before minting).
+// Both operations are idempotent so an interrupted upgrade can safely repeat them:
+// Mint with a known IdempotencyKey and Burn of an already burned mint return the original result.
+type Client interface {
+	Mint(ctx context.Context, req MintRequest) (*MintResult, error)
+	Burn(ctx context.Context, req BurnRequest) (*BurnResult, error)
+}
+
+// ==========================================
+// MOCK CLIENT
+// ==========================================
+
+// MockClient simulates successful mints and burns with random, well-formed Solana addresses and signatures
+type MockClient struct {
+	mu    sync.Mutex
+	mints map[string]MintResult // by idempotency key
+	burns map[string]BurnResult // by mint address
+}
+
+// NewMockClient returns a Client that never touches the network
+func NewMockClient() *MockClient {
+	return &MockClient{
+		mints: map[string]MintResult{},
+		burns: map[string]BurnResult{},
+	}
+}
+
+func (c *MockClient) Mint(ctx context.Context, req MintRequest) (*MintResult, error) {
+	if req.OwnerWallet == "" {
+		return nil, fmt.Errorf("mint %s: owner wallet is required", req.Name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if result, ok := c.mints[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return &result, nil
+	}
+
+	mintAddress, err := randomBase58(44)
+	if err != nil {
+		return nil, err
+	}
+	ataAddress, err := randomBase58(44)
+	if err != nil {
+		return nil, err
+	}
+	metadataPDA, err := randomBase58(44)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := randomBase58(88)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(mintAddress))
+	cid := "Qm" + hex.EncodeToString(hash[:])[:44]
+	result := MintResult{
+		MintAddress:   mintAddress,
+		ATAAddress:    ataAddress,
+		MetadataPDA:   metadataPDA,
+		MetadataURI:   "https://ipfs.io/ipfs/" + cid + "/metadata.json",
+		ImageURI:      "https://ipfs.io/ipfs/" + cid + "/image.png",
+		TransactionID: signature,
+	}
+	if req.IdempotencyKey != "" {
+		c.mints[req.IdempotencyKey] = result
+	}
+	return &result, nil
+}
+
+func (c *MockClient) Burn(ctx context.Context, req BurnRequest) (*BurnResult, error) {
+	if req.MintAddress == "" {
+		return nil, fmt.Errorf("burn: mint address is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if result, ok := c.burns[req.MintAddress]; ok {
+		return &result, nil
+	}
+
+	signature, err := randomBase58(88)
+	if err != nil {
+		return nil, err
+	}
+	result := BurnResult{TransactionID: signature}
+	c.burns[req.MintAddress] = result
+	return &result, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// randomBase58 returns a random string of the given length in the Bitcoin/Solana base58 alphabet
+func randomBase58(length int) (string, error) {
+	max := big.NewInt(int64(len(base58Alphabet)))
+	out := make([]byte, length)
+	for i := range out {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		out[i] = base58Alphabet[n.Int64()]
+	}
+	return string(out), nil
+}
@@ -0,0 +1,474 @@
+package coordinator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// NFT JOB COORDINATOR
+// ==========================================
+
+// JobKind identifies the NFT operation a job performs
+type JobKind string
+
+const (
+	JobClaim    JobKind = "claim"
+	JobUpgrade  JobKind = "upgrade"
+	JobAward    JobKind = "award"
+	JobActivate JobKind = "activate"
+)
+
+// Job states reported by Stats
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobBackoff = "backoff"
+)
+
+var (
+	// ErrBusy rejects a job while another NFT job of the same user is queued or running
+	ErrBusy = errors.New("Another NFT operation is already in progress for this user")
+	// ErrQueueFull rejects a job when every queue slot is taken
+	ErrQueueFull = errors.New("The NFT job queue is full; try again shortly")
+	// ErrStopped rejects jobs submitted after Stop
+	ErrStopped = errors.New("The NFT job queue is shutting down; try again shortly")
+	// ErrLeaseLost ends a job whose user's lease could not be renewed, as another job may now hold it
+	ErrLeaseLost = errors.New("The NFT operation lost its lock on the user; try again shortly")
+)
+
+// Job is a unit of NFT work for one user. Run receives the coordinator's context rather than the
+// request's, so a client that disconnects mid-job does not interrupt an on-chain step. The context is
+// cancelled when the job loses its user's lease.
+type Job struct {
+	Kind   JobKind
+	UserID int
+	Run    func(ctx context.Context) error
+}
+
+// retryableError marks a transient failure that is worth running the job again for
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// Retry marks err as transient: the coordinator runs the job again after a backoff
+// until Config.MaxAttempts is reached. Other errors end the job immediately.
+func Retry(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// attemptKey carries the run of its job a context belongs to
+type attemptKey struct{}
+
+// Attempt returns which run of its job ctx belongs to: 1 for the first run, more for the runs retrying an
+// error marked with Retry, and 0 outside a coordinator job
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// IsRetryable reports whether err was marked with Retry
+func IsRetryable(err error) bool {
+	var retryable *retryableError
+	return errors.As(err, &retryable)
+}
+
+// RejectionCode returns the envelope code for a job the coordinator refused to run or stopped when it lost
+// the user's lease, false for other errors
+func RejectionCode(err error) (int, bool) {
+	switch {
+	case errors.Is(err, ErrBusy):
+		return 409, true
+	case errors.Is(err, ErrQueueFull), errors.Is(err, ErrStopped), errors.Is(err, ErrLeaseLost):
+		return 503, true
+	}
+	return 0, false
+}
+
+// Config tunes the worker pool (mirrors the ConcurrentUpgradeManager queue options)
+type Config struct {
+	Workers     int           // jobs run in parallel
+	QueueSize   int           // jobs waiting for a worker before submissions are rejected
+	LeaseTTL    time.Duration // per-user lock lifetime; renewed while the job runs, expires after a crash
+	MaxAttempts int           // runs of a job whose error is marked with Retry
+	BaseBackoff time.Duration // delay before the first retry, doubled for every further one
+	MaxBackoff  time.Duration
+}
+
+// DefaultConfig returns the production settings: 10 workers, 3 attempts, 2s exponential backoff
+func DefaultConfig() Config {
+	return Config{
+		Workers:     10,
+		QueueSize:   100,
+		LeaseTTL:    5 * time.Minute,
+		MaxAttempts: 3,
+		BaseBackoff: 2 * time.Second,
+		MaxBackoff:  30 * time.Second,
+	}
+}
+
+// JobInfo describes a queued or running job
+type JobInfo struct {
+	ID          string    `json:"id" example:"upgrade-67890-3" description:"Job identifier"`
+	Kind        JobKind   `json:"kind" example:"upgrade" description:"NFT operation: claim, upgrade, award or activate"`
+	UserID      int       `json:"userId" example:"67890" description:"User the job belongs to"`
+	State       string    `json:"state" example:"running" description:"queued, running or backoff"`
+	Attempt     int       `json:"attempt" example:"1" description:"Current attempt, 0 while queued"`
+	SubmittedAt time.Time `json:"submittedAt" description:"When the job was accepted"`
+}
+
+// Stats is a snapshot of the worker pool
+type Stats struct {
+	Workers       int       `json:"workers" example:"10" description:"Jobs that can run in parallel"`
+	QueueDepth    int       `json:"queueDepth" example:"2" description:"Jobs waiting for a worker"`
+	QueueCapacity int       `json:"queueCapacity" example:"100" description:"Jobs that can wait before submissions are rejected"`
+	InFlight      []JobInfo `json:"inFlight" description:"Queued and running jobs"`
+	Completed     int64     `json:"completed" example:"42" description:"Jobs finished without error since start"`
+	Failed        int64     `json:"failed" example:"1" description:"Jobs finished with an error since start"`
+	Retried       int64     `json:"retried" example:"3" description:"Retries scheduled since start"`
+	Rejected      int64     `json:"rejected" example:"5" description:"Submissions refused because the user was busy or the queue was full"`
+}
+
+// task is a submitted job with its bookkeeping
+type task struct {
+	info  JobInfo
+	job   Job
+	owner string // lease owner token
+	done  chan error
+}
+
+// Coordinator runs NFT jobs on a bounded worker pool. Every job holds its user's lease from submission
+// until it finishes, so a user has at most one claim, upgrade, award or activation in flight: a double-clicked
+// upgrade is rejected with ErrBusy instead of running twice, on this instance or any other sharing the store.
+type Coordinator struct {
+	leases repository.LeaseRepository
+	cfg    Config
+	queue  chan *task
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	stopped  bool
+	inFlight map[string]*task
+	seq      int
+	stats    Stats
+}
+
+// New starts a coordinator with cfg.Workers workers; zero fields of cfg take their DefaultConfig value
+func New(leases repository.LeaseRepository, cfg Config) *Coordinator {
+	defaults := DefaultConfig()
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaults.Workers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaults.QueueSize
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = defaults.LeaseTTL
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaults.BaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaults.MaxBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Coordinator{
+		leases:   leases,
+		cfg:      cfg,
+		queue:    make(chan *task, cfg.QueueSize),
+		ctx:      ctx,
+		cancel:   cancel,
+		inFlight: map[string]*task{},
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		c.wg.Add(1)
+		go c.work()
+	}
+	return c
+}
+
+// Submit takes the user's lease and queues the job. The returned channel receives the job's final error
+// (nil on success). It fails with ErrBusy, ErrQueueFull or ErrStopped when the job is not accepted.
+func (c *Coordinator) Submit(ctx context.Context, job Job) (<-chan error, error) {
+	owner, err := leaseOwner()
+	if err != nil {
+		return nil, err
+	}
+	name := UserLease(job.UserID)
+	acquired, err := c.leases.Acquire(ctx, name, owner, c.cfg.LeaseTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		c.reject()
+		return nil, ErrBusy
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var rejection error
+	if c.stopped {
+		rejection = ErrStopped
+	}
+	c.seq++
+	t := &task{
+		info: JobInfo{
+			ID:          fmt.Sprintf("%s-%d-%d", job.Kind, job.UserID, c.seq),
+			Kind:        job.Kind,
+			UserID:      job.UserID,
+			State:       JobQueued,
+			SubmittedAt: time.Now().UTC(),
+		},
+		job:   job,
+		owner: owner,
+		done:  make(chan error, 1),
+	}
+	if rejection == nil {
+		select {
+		case c.queue <- t:
+			c.inFlight[t.info.ID] = t
+			return t.done, nil
+		default:
+			rejection = ErrQueueFull
+		}
+	}
+
+	c.stats.Rejected++
+	if err := c.leases.Release(ctx, name, owner); err != nil {
+		log.Printf("coordinator: release lease %s: %v", name, err)
+	}
+	return nil, rejection
+}
+
+// Do submits the job and waits for it to finish. When ctx ends first the job keeps running
+// and Do returns ctx.Err().
+func (c *Coordinator) Do(ctx context.Context, job Job) error {
+	done, err := c.Submit(ctx, job)
+	if err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the queue, the in-flight jobs and the counters since start
+func (c *Coordinator) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Workers = c.cfg.Workers
+	stats.QueueDepth = len(c.queue)
+	stats.QueueCapacity = cap(c.queue)
+	stats.InFlight = make([]JobInfo, 0, len(c.inFlight))
+	for _, t := range c.inFlight {
+		stats.InFlight = append(stats.InFlight, t.info)
+	}
+	sort.Slice(stats.InFlight, func(i, j int) bool {
+		return stats.InFlight[i].SubmittedAt.Before(stats.InFlight[j].SubmittedAt)
+	})
+	return stats
+}
+
+// Stop rejects new jobs and waits for the accepted ones to finish. When ctx ends first the running
+// jobs are cancelled; their leases expire on their own.
+func (c *Coordinator) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.stopped {
+		c.stopped = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		c.cancel()
+		return nil
+	case <-ctx.Done():
+		c.cancel()
+		return ctx.Err()
+	}
+}
+
+// ==========================================
+// WORKERS
+// ==========================================
+
+func (c *Coordinator) work() {
+	defer c.wg.Done()
+	for t := range c.queue {
+		c.execute(t)
+	}
+}
+
+// execute runs the task, retrying errors marked with Retry, then releases the user's lease. A lost lease
+// cancels the job and ends it with ErrLeaseLost, marked with Retry: the job no longer excludes the user's
+// other jobs, so it stops rather than running on.
+func (c *Coordinator) execute(t *task) {
+	name := UserLease(t.info.UserID)
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+	var lost atomic.Bool
+	stopRenewal := c.renewLease(name, t.owner, func() {
+		lost.Store(true)
+		cancel()
+	})
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		c.setState(t, JobRunning, attempt)
+		err = c.run(ctx, t, attempt)
+		if lost.Load() {
+			err = Retry(ErrLeaseLost)
+			break
+		}
+		if err == nil || !IsRetryable(err) || attempt >= c.cfg.MaxAttempts {
+			break
+		}
+
+		c.setState(t, JobBackoff, attempt)
+		select {
+		case <-time.After(c.backoff(attempt)):
+		case <-ctx.Done():
+		}
+		if lost.Load() {
+			err = Retry(ErrLeaseLost)
+			break
+		}
+		if c.ctx.Err() != nil {
+			err = c.ctx.Err()
+			break
+		}
+	}
+
+	stopRenewal()
+	if releaseErr := c.leases.Release(context.Background(), name, t.owner); releaseErr != nil {
+		log.Printf("coordinator: release lease %s: %v", name, releaseErr)
+	}
+
+	c.mu.Lock()
+	delete(c.inFlight, t.info.ID)
+	if err == nil {
+		c.stats.Completed++
+	} else {
+		c.stats.Failed++
+	}
+	c.mu.Unlock()
+	t.done <- err
+}
+
+// run calls the job, turning a panic into an error so a bad job cannot take a worker down
+func (c *Coordinator) run(ctx context.Context, t *task, attempt int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", t.info.ID, r)
+		}
+	}()
+	return t.job.Run(context.WithValue(ctx, attemptKey{}, attempt))
+}
+
+func (c *Coordinator) setState(t *task, state string, attempt int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if state == JobBackoff {
+		c.stats.Retried++
+	}
+	t.info.State = state
+	t.info.Attempt = attempt
+}
+
+// backoff returns BaseBackoff * 2^(attempt-1), capped at MaxBackoff
+func (c *Coordinator) backoff(attempt int) time.Duration {
+	delay := c.cfg.BaseBackoff
+	for i := 1; i < attempt && delay < c.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.cfg.MaxBackoff {
+		delay = c.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// renewLease keeps the lease alive while a long job runs, calling lost and giving up when a renewal fails;
+// the returned function stops renewing
+func (c *Coordinator) renewLease(name, owner string, lost func()) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(c.cfg.LeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				renewed, err := c.leases.Renew(context.Background(), name, owner, c.cfg.LeaseTTL)
+				if err != nil || !renewed {
+					log.Printf("coordinator: lease %s lost (renewed=%t, err=%v)", name, renewed, err)
+					lost()
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+func (c *Coordinator) reject() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Rejected++
+}
+
+// UserLease is the lease serializing a user's NFT jobs. Other writers of the user's NFT and badge
+// state, such as the badge expiry sweeper, take it too.
+func UserLease(userID int) string {
+	return fmt.Sprintf("nft-user:%d", userID)
+}
+
+// leaseOwner returns a random token identifying one job's hold on a lease
+func leaseOwner() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
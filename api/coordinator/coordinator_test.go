@@ -0,0 +1,195 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+)
+
+// newTestCoordinator starts a coordinator with millisecond backoffs, stopped when the test ends
+func newTestCoordinator(t *testing.T, leases repository.LeaseRepository, cfg Config) *Coordinator {
+	t.Helper()
+	if cfg.BaseBackoff == 0 {
+		cfg.BaseBackoff, cfg.MaxBackoff = time.Millisecond, time.Millisecond
+	}
+	c := New(leases, cfg)
+	t.Cleanup(func() { c.Stop(context.Background()) })
+	return c
+}
+
+// blockingJob returns a job of user that runs until release is closed
+func blockingJob(userID int, release <-chan struct{}) Job {
+	return Job{Kind: JobUpgrade, UserID: userID, Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}}
+}
+
+// lostLeases is a lease store whose renewals all fail, as when the lease expired and another owner took it
+type lostLeases struct {
+	repository.LeaseRepository
+}
+
+func (lostLeases) Renew(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+func TestSubmitRejectsBusyUser(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCoordinator(t, memory.NewStore().Leases, Config{Workers: 2})
+
+	release := make(chan struct{})
+	done, err := c.Submit(ctx, blockingJob(1, release))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Submit(ctx, blockingJob(1, release)); !errors.Is(err, ErrBusy) {
+		t.Errorf("second job of the user: %v, want ErrBusy", err)
+	}
+	otherDone, err := c.Submit(ctx, blockingJob(2, release))
+	if err != nil {
+		t.Errorf("job of another user: %v, want it accepted", err)
+	}
+	if code, ok := RejectionCode(ErrBusy); !ok || code != 409 {
+		t.Errorf("ErrBusy code %d, want 409", code)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if otherDone != nil {
+		<-otherDone
+	}
+	// The finished job released the lease
+	if err := c.Do(ctx, blockingJob(1, release)); err != nil {
+		t.Errorf("job after the first finished: %v, want it run", err)
+	}
+	if stats := c.Stats(); stats.Rejected != 1 || stats.Completed != 3 {
+		t.Errorf("%d rejected and %d completed, want 1 and 3", stats.Rejected, stats.Completed)
+	}
+}
+
+func TestExpiredLeaseIsTakenOver(t *testing.T) {
+	ctx := context.Background()
+	leases := memory.NewStore().Leases
+	c := newTestCoordinator(t, leases, Config{Workers: 1})
+
+	// An instance that crashed mid-job left the user's lease behind
+	if _, err := leases.Acquire(ctx, UserLease(1), "crashed-instance", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	ran := false
+	job := Job{Kind: JobClaim, UserID: 1, Run: func(ctx context.Context) error {
+		ran = true
+		return nil
+	}}
+	if err := c.Do(ctx, job); !errors.Is(err, ErrBusy) {
+		t.Fatalf("job while the lease is held: %v, want ErrBusy", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := c.Do(ctx, job); err != nil || !ran {
+		t.Errorf("job after the lease expired: ran %t, %v; want it run", ran, err)
+	}
+	active, err := leases.ListActive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 0 {
+		t.Errorf("active leases %v, want the job's released", active)
+	}
+}
+
+func TestRetriesOnlyRetryableErrorsUpToMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	transient := errors.New("rpc node unavailable")
+	tests := []struct {
+		name      string
+		failures  int   // runs failing before one succeeds
+		err       error // what the failing runs return
+		wantRuns  int
+		wantErr   bool
+		retryable bool
+	}{
+		{"succeeds first", 0, Retry(transient), 1, false, false},
+		{"retried then succeeds", 2, Retry(transient), 3, false, false},
+		{"retries run out", 5, Retry(transient), 3, true, true},
+		{"permanent error", 5, transient, 1, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCoordinator(t, memory.NewStore().Leases, Config{Workers: 1, MaxAttempts: 3})
+			var attempts []int
+			err := c.Do(ctx, Job{Kind: JobUpgrade, UserID: 1, Run: func(ctx context.Context) error {
+				attempts = append(attempts, Attempt(ctx))
+				if len(attempts) <= tt.failures {
+					return tt.err
+				}
+				return nil
+			}})
+			if (err != nil) != tt.wantErr || IsRetryable(err) != tt.retryable {
+				t.Errorf("error %v (retryable %t), want error %t (retryable %t)", err, IsRetryable(err), tt.wantErr,
+					tt.retryable)
+			}
+			if tt.wantErr && !errors.Is(err, transient) {
+				t.Errorf("error %v, want the job's", err)
+			}
+			if len(attempts) != tt.wantRuns {
+				t.Fatalf("%d runs, want %d", len(attempts), tt.wantRuns)
+			}
+			for i, attempt := range attempts {
+				if attempt != i+1 {
+					t.Errorf("run %d saw attempt %d", i+1, attempt)
+				}
+			}
+			if retried := c.Stats().Retried; retried != int64(tt.wantRuns-1) {
+				t.Errorf("%d retries counted, want %d", retried, tt.wantRuns-1)
+			}
+		})
+	}
+	if Attempt(ctx) != 0 {
+		t.Errorf("attempt %d outside a job, want 0", Attempt(ctx))
+	}
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	c := &Coordinator{cfg: Config{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}}
+	want := map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second,
+		10: 5 * time.Second}
+	for attempt, delay := range want {
+		if got := c.backoff(attempt); got != delay {
+			t.Errorf("attempt %d: backoff %s, want %s", attempt, got, delay)
+		}
+	}
+}
+
+func TestLostLeaseCancelsJob(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCoordinator(t, lostLeases{memory.NewStore().Leases}, Config{Workers: 1, MaxAttempts: 3,
+		LeaseTTL: 30 * time.Millisecond})
+
+	runs := 0
+	err := c.Do(ctx, Job{Kind: JobUpgrade, UserID: 1, Run: func(ctx context.Context) error {
+		runs++
+		select {
+		case <-ctx.Done():
+			return Retry(ctx.Err())
+		case <-time.After(time.Second):
+			return errors.New("job ran on after its lease was lost")
+		}
+	}})
+	if !errors.Is(err, ErrLeaseLost) || !IsRetryable(err) {
+		t.Errorf("error %v, want ErrLeaseLost marked retryable", err)
+	}
+	if runs != 1 {
+		t.Errorf("%d runs, want the job not run again without its lease", runs)
+	}
+	if code, ok := RejectionCode(err); !ok || code != 503 {
+		t.Errorf("code %d, want 503", code)
+	}
+}
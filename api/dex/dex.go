@@ -0,0 +1,407 @@
+// Package dex finds the swaps in confirmed Solana transactions, so trades on Raydium, Orca and Jupiter
+// count towards trading volume. DEXes have no trade feed: a transaction, as returned by getTransaction,
+// is a swap when it calls a DEX program's swap instruction, and what was swapped is read from how the
+// signer's token and SOL balances changed. A swap is valued by its leg in the steadiest asset it has,
+// USDT first; the volume pipeline converts legs in other assets to USDT at their price at block time.
+package dex
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/volume"
+)
+
+// Platforms swaps are credited to; they match the nfts TradingPlatform values
+const (
+	PlatformRaydium = "raydium"
+	PlatformOrca    = "orca"
+	PlatformJupiter = "jupiter"
+	// PlatformSolana is credited when one transaction swaps through several DEXes without Jupiter
+	PlatformSolana = "solana"
+)
+
+// Mints swaps are valued or netted by
+const (
+	MintUSDC = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	MintUSDT = "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"
+	// MintWrappedSOL is the SPL token of SOL; wrapped and native SOL are netted into one leg
+	MintWrappedSOL = "So11111111111111111111111111111111111111112"
+	MintJUP        = "JUPyiwrYJFskUPiHa7hkeR8VUtAeFLoSphV8R4C2drK"
+	MintBONK       = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+)
+
+// valuedBy are the assets swaps are valued by, steadiest first, and their mints
+var valuedBy = []struct {
+	asset money.Currency
+	mint  string
+}{
+	{money.USDT, MintUSDT},
+	{money.USDC, MintUSDC},
+	{"SOL", MintWrappedSOL},
+	{"JUP", MintJUP},
+	{"BONK", MintBONK},
+}
+
+// solDecimals is the decimals of SOL: a lamport is 10^-9 SOL
+const solDecimals = 9
+
+// ErrNotSwap is returned for transactions that are well-formed but do not swap
+var ErrNotSwap = errors.New("not a swap")
+
+// ==========================================
+// DEX PROGRAMS
+// ==========================================
+
+// program is a DEX program and how its swap instructions are recognised
+type program struct {
+	platform string
+	// isSwap reports whether instruction data is one of the program's swap instructions
+	isSwap func(data []byte) bool
+}
+
+// programs are the DEX programs swaps are found through, by program ID
+var programs = map[string]program{
+	// Raydium AMM v4: SwapBaseIn and SwapBaseOut
+	"675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8": {PlatformRaydium, tagged(9, 11)},
+	// Raydium CLMM
+	"CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK": {PlatformRaydium, anchor("swap", "swap_v2", "swap_router_base_in")},
+	// Raydium CPMM
+	"CPMMoo8L3F4NbTegBCKVNunggL7H1ZpdTHKxQB5qKP1C": {PlatformRaydium, anchor("swap_base_input", "swap_base_output")},
+	// Orca Whirlpool
+	"whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc": {PlatformOrca,
+		anchor("swap", "swap_v2", "two_hop_swap", "two_hop_swap_v2")},
+	// Orca token swap, v2 and v1: Swap
+	"9W959DqEETiGZocYWCQPaJ6sBmUzgfxXfqGeTEdp3aP":  {PlatformOrca, tagged(1)},
+	"DjVE6JNiYqPL2QXyCUUh8rNjHrbz9hXHNYt99MQ59qw1": {PlatformOrca, tagged(1)},
+	// Jupiter aggregator v6
+	"JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QkUtfFUfP": {PlatformJupiter,
+		anchor("route", "shared_accounts_route", "exact_out_route", "shared_accounts_exact_out_route",
+			"route_with_token_ledger", "shared_accounts_route_with_token_ledger")},
+}
+
+// tagged recognises instructions by their first byte, as in native programs
+func tagged(tags ...byte) func([]byte) bool {
+	return func(data []byte) bool {
+		if len(data) == 0 {
+			return false
+		}
+		for _, tag := range tags {
+			if data[0] == tag {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// anchor recognises Anchor instructions by their discriminator, the first 8 bytes of the SHA-256 of
+// "global:<name>"
+func anchor(names ...string) func([]byte) bool {
+	discriminators := make([][8]byte, 0, len(names))
+	for _, name := range names {
+		sum := sha256.Sum256([]byte("global:" + name))
+		var discriminator [8]byte
+		copy(discriminator[:], sum[:8])
+		discriminators = append(discriminators, discriminator)
+	}
+	return func(data []byte) bool {
+		if len(data) < 8 {
+			return false
+		}
+		for _, discriminator := range discriminators {
+			if string(data[:8]) == string(discriminator[:]) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ==========================================
+// SWAPS
+// ==========================================
+
+// Leg is a token the swapper gave up or received. Amount is in whole tokens and always positive, in the
+// asset swaps are valued by for its mints and in the mint otherwise. SOL's lamports are rounded to 8 decimals.
+type Leg struct {
+	Mint   string
+	Amount money.Amount
+}
+
+// Swap is what a transaction swapped for its signer
+type Swap struct {
+	Signature string
+	Platform  string
+	Wallet    string // signer whose balances swapped
+	Sold      Leg
+	Bought    Leg
+	// Notional is the amount of the leg the swap is valued by, in that leg's asset; zero when neither leg
+	// is in an asset swaps are valued by
+	Notional   money.Amount
+	ExecutedAt time.Time
+}
+
+// Valued reports whether the swap has a leg it can be valued by
+func (s Swap) Valued() bool {
+	return s.Notional.Sign() > 0
+}
+
+// Parse reads a confirmed transaction as returned by getTransaction, bare or in its JSON-RPC envelope,
+// in the json or jsonParsed encoding. A transaction that failed or does not swap returns an error
+// wrapping ErrNotSwap, with the signature set on the swap.
+func Parse(data []byte) (Swap, error) {
+	tx, err := decodeTransaction(data)
+	if err != nil {
+		return Swap{}, err
+	}
+	swap := Swap{Signature: tx.signature()}
+	if len(tx.Meta.Err) > 0 && string(tx.Meta.Err) != "null" {
+		return swap, fmt.Errorf("%w: transaction failed", ErrNotSwap)
+	}
+	if tx.BlockTime == nil {
+		return swap, errors.New("transaction: missing blockTime")
+	}
+	swap.ExecutedAt = time.Unix(*tx.BlockTime, 0).UTC()
+
+	accounts := tx.accounts()
+	if swap.Platform, err = venue(tx, accounts); err != nil {
+		return swap, err
+	}
+
+	// The swapper is the signer whose balances show one token given up and another received; the fee
+	// payer is tried first, then any other signer (e.g. when a relayer pays the fee)
+	var firstErr error
+	for _, signer := range tx.signers() {
+		sold, bought, err := legs(tx, signer, accounts)
+		if err == nil {
+			swap.Wallet, swap.Sold, swap.Bought = signer, sold, bought
+			swap.Notional = notional(sold, bought)
+			return swap, nil
+		}
+		if !errors.Is(err, ErrNotSwap) {
+			return swap, err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		return swap, fmt.Errorf("%w: transaction has no signer", ErrNotSwap)
+	}
+	return swap, firstErr
+}
+
+// venue names the platform the transaction swapped on. Each top-level instruction that swaps counts
+// once: through its own program when that is a DEX, otherwise through the DEXes it called. Jupiter
+// routes through other DEXes, so a call reaching Jupiter counts as Jupiter.
+func venue(tx *transaction, accounts []string) (string, error) {
+	inner := map[int][]instruction{}
+	for _, group := range tx.Meta.InnerInstructions {
+		inner[group.Index] = append(inner[group.Index], group.Instructions...)
+	}
+
+	venues := map[string]bool{}
+	for i, ix := range tx.Transaction.Message.Instructions {
+		swapped, err := swaps(tx, ix, accounts)
+		if err != nil {
+			return "", err
+		}
+		if swapped != nil {
+			venues[swapped.platform] = true
+			continue
+		}
+		called := map[string]bool{}
+		for _, innerIx := range inner[i] {
+			swapped, err := swaps(tx, innerIx, accounts)
+			if err != nil {
+				return "", err
+			}
+			if swapped != nil {
+				called[swapped.platform] = true
+			}
+		}
+		switch {
+		case called[PlatformJupiter]:
+			venues[PlatformJupiter] = true
+		case len(called) == 1:
+			for platform := range called {
+				venues[platform] = true
+			}
+		case len(called) > 1:
+			venues[PlatformSolana] = true
+		}
+	}
+
+	switch len(venues) {
+	case 0:
+		return "", fmt.Errorf("%w: no Raydium, Orca or Jupiter swap instruction", ErrNotSwap)
+	case 1:
+		for platform := range venues {
+			return platform, nil
+		}
+	}
+	return PlatformSolana, nil
+}
+
+// swaps returns the DEX program when the instruction is one of its swap instructions
+func swaps(tx *transaction, ix instruction, accounts []string) (*program, error) {
+	p, ok := programs[tx.programID(ix, accounts)]
+	if !ok || ix.Data == "" {
+		return nil, nil
+	}
+	data, err := decodeBase58(ix.Data)
+	if err != nil {
+		return nil, fmt.Errorf("transaction: instruction data: %w", err)
+	}
+	if !p.isSwap(data) {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// legs reads what the owner gave up and received. Native and wrapped SOL are one leg, since swaps
+// wrap and unwrap SOL in the same transaction. The SOL leg is only considered when the token balances
+// alone do not show a swap: otherwise it is rent and tips paid alongside a token-for-token swap.
+func legs(tx *transaction, owner string, accounts []string) (Leg, Leg, error) {
+	deltas, decimals, err := tx.tokenDeltas(owner)
+	if err != nil {
+		return Leg{}, Leg{}, err
+	}
+	sol := new(big.Int).Add(tx.lamportDelta(owner, accounts), zeroIfNil(deltas[MintWrappedSOL]))
+	delete(deltas, MintWrappedSOL)
+
+	sold, bought, err := split(deltas, decimals)
+	if err != nil {
+		return Leg{}, Leg{}, err
+	}
+	if len(sold) != 1 || len(bought) != 1 {
+		if sol.Sign() != 0 {
+			deltas[MintWrappedSOL] = sol
+			decimals[MintWrappedSOL] = solDecimals
+		}
+		if sold, bought, err = split(deltas, decimals); err != nil {
+			return Leg{}, Leg{}, err
+		}
+	}
+	if len(sold) != 1 || len(bought) != 1 {
+		return Leg{}, Leg{}, fmt.Errorf("%w: %s gave up %d tokens and received %d", ErrNotSwap, owner,
+			len(sold), len(bought))
+	}
+	return sold[0], bought[0], nil
+}
+
+// split sorts non-zero deltas into the legs given up and the legs received
+func split(deltas map[string]*big.Int, decimals map[string]int) ([]Leg, []Leg, error) {
+	mints := make([]string, 0, len(deltas))
+	for mint := range deltas {
+		mints = append(mints, mint)
+	}
+	sort.Strings(mints)
+
+	var sold, bought []Leg
+	for _, mint := range mints {
+		delta := deltas[mint]
+		if delta.Sign() == 0 {
+			continue
+		}
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals[mint])), nil)
+		amount, err := asset(mint).FromRat(new(big.Rat).SetFrac(new(big.Int).Abs(delta), scale))
+		if err != nil {
+			return nil, nil, fmt.Errorf("transaction: %s amount: %w", shortMint(mint), err)
+		}
+		if delta.Sign() < 0 {
+			sold = append(sold, Leg{Mint: mint, Amount: amount})
+		} else {
+			bought = append(bought, Leg{Mint: mint, Amount: amount})
+		}
+	}
+	return sold, bought, nil
+}
+
+func zeroIfNil(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
+
+// notional values a swap by its leg in the steadiest asset
+func notional(sold, bought Leg) money.Amount {
+	for _, valued := range valuedBy {
+		for _, leg := range []Leg{sold, bought} {
+			if leg.Mint == valued.mint {
+				return leg.Amount
+			}
+		}
+	}
+	return money.Amount{}
+}
+
+// asset is the currency a mint's amounts are counted in: the asset's code for the mints swaps are valued
+// by, the mint itself for other tokens
+func asset(mint string) money.Currency {
+	for _, valued := range valuedBy {
+		if mint == valued.mint {
+			return valued.asset
+		}
+	}
+	return money.Currency(mint)
+}
+
+// ==========================================
+// TRADING VOLUME
+// ==========================================
+
+// Trade converts a confirmed transaction into the trade trading volume records. The signature is the
+// trade ID and the swapper's wallet identifies the user; the notional is in the asset of the leg the
+// swap is valued by. Transactions that are not swaps, or swaps with no leg to value them by, are returned
+// with Invalid set.
+func Trade(data []byte) volume.Trade {
+	swap, err := Parse(data)
+	trade := volume.Trade{
+		Platform:   swap.Platform,
+		TradeID:    swap.Signature,
+		WalletAddr: swap.Wallet,
+		Notional:   swap.Notional,
+		ExecutedAt: swap.ExecutedAt,
+	}
+	switch {
+	case err != nil:
+		trade.Invalid = err.Error()
+	case !swap.Valued():
+		trade.Invalid = fmt.Sprintf("swap of %s for %s has no USDT, USDC, SOL, JUP or BONK leg to value it by",
+			shortMint(swap.Sold.Mint), shortMint(swap.Bought.Mint))
+	}
+	if trade.Platform == "" {
+		trade.Platform = PlatformSolana
+	}
+	return trade
+}
+
+// Trades converts confirmed transactions, in order
+func Trades(transactions [][]byte) []volume.Trade {
+	trades := make([]volume.Trade, 0, len(transactions))
+	for _, data := range transactions {
+		trades = append(trades, Trade(data))
+	}
+	return trades
+}
+
+// shortMint names the well-known mints and shortens the others for messages
+func shortMint(mint string) string {
+	for _, valued := range valuedBy {
+		if mint == valued.mint {
+			return string(valued.asset)
+		}
+	}
+	if len(mint) > 8 {
+		return mint[:4] + "…" + mint[len(mint)-4:]
+	}
+	return mint
+}
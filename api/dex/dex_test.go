@@ -0,0 +1,143 @@
+package dex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+const (
+	swapper = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	relayer = "RLYv2ubRMDLcGG2UyvPmnPmkfuQTsMbg4Jtygc7dmnq"
+)
+
+// Currencies of the valued tokens other than the stablecoins
+const (
+	sol  money.Currency = "SOL"
+	jup  money.Currency = "JUP"
+	bonk money.Currency = "BONK"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file     string
+		platform string
+		wallet   string
+		sold     Leg
+		bought   Leg
+		notional money.Amount
+		at       string
+	}{
+		// Native SOL is wrapped into a temporary account, swapped and the account closed
+		{"raydium-amm-sol-usdc", PlatformRaydium, swapper,
+			Leg{MintWrappedSOL, sol.MustParse("2")}, Leg{MintUSDC, money.USDC.MustParse("291.374512")},
+			money.USDC.MustParse("291.374512"), "2024-11-27T13:00:55Z"},
+		// The wrapped SOL bought is unwrapped into the wallet by closing the temporary account
+		{"raydium-amm-usdc-sol", PlatformRaydium, swapper,
+			Leg{MintUSDC, money.USDC.MustParse("291.374512")}, Leg{MintWrappedSOL, sol.MustParse("2")},
+			money.USDC.MustParse("291.374512"), "2024-11-27T13:10:12Z"},
+		{"orca-whirlpool-usdt-bonk", PlatformOrca, swapper,
+			Leg{MintUSDT, money.USDT.MustParse("500")}, Leg{MintBONK, bonk.MustParse("21845702.11542")},
+			money.USDT.Whole(500), "2024-11-27T13:53:51Z"},
+		// A relayer signs first and pays the fee; the swap is the second signer's
+		{"orca-whirlpool-relayer-paid", PlatformOrca, swapper,
+			Leg{MintUSDT, money.USDT.MustParse("500")}, Leg{MintBONK, bonk.MustParse("21845702.11542")},
+			money.USDT.Whole(500), "2024-11-27T14:03:53Z"},
+		{"jupiter-route-usdc-jup", PlatformJupiter, "8VzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtBWWN",
+			Leg{MintUSDC, money.USDC.MustParse("1250")}, Leg{MintJUP, jup.MustParse("1402.114508")},
+			money.USDC.Whole(1250), "2024-11-27T14:50:12Z"},
+		{"jupiter-route-sol-bonk", PlatformJupiter, swapper,
+			Leg{MintWrappedSOL, sol.MustParse("0.75")}, Leg{MintBONK, bonk.MustParse("4112903.7712")},
+			sol.MustParse("0.75"), "2024-11-27T15:53:27Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			swap, err := Parse(readTestdata(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if swap.Signature == "" || swap.Platform != tt.platform || swap.Wallet != tt.wallet {
+				t.Errorf("swap %q on %s by %s, want a signed swap on %s by %s", swap.Signature, swap.Platform,
+					swap.Wallet, tt.platform, tt.wallet)
+			}
+			if swap.Sold != tt.sold || swap.Bought != tt.bought {
+				t.Errorf("sold %+v for %+v, want %+v for %+v", swap.Sold, swap.Bought, tt.sold, tt.bought)
+			}
+			if swap.Notional != tt.notional || swap.Notional.Currency() != tt.notional.Currency() {
+				t.Errorf("notional %s %s, want %s %s", swap.Notional, swap.Notional.Currency(), tt.notional,
+					tt.notional.Currency())
+			}
+			if want, _ := time.Parse(time.RFC3339, tt.at); !swap.ExecutedAt.Equal(want) {
+				t.Errorf("executed at %s, want %s", swap.ExecutedAt, tt.at)
+			}
+		})
+	}
+}
+
+func TestParseFailedSwap(t *testing.T) {
+	swap, err := Parse(readTestdata(t, "raydium-amm-failed"))
+	if !errors.Is(err, ErrNotSwap) {
+		t.Errorf("error %v, want ErrNotSwap", err)
+	}
+	if swap.Signature == "" {
+		t.Error("signature not set on the failed swap")
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	transfer := `{"blockTime":1732712455,"meta":{"err":null,"fee":5000,"preBalances":[100,0,1],
+		"postBalances":[95,0,1]},"transaction":{"signatures":["TransferSignature"],"message":{
+		"accountKeys":["` + swapper + `","Recipient","11111111111111111111111111111111"],
+		"header":{"numRequiredSignatures":1},"instructions":[{"programIdIndex":2,"data":"3Bxs4h24hBtQy9rw"}]}}}`
+	tests := []struct {
+		name     string
+		data     string
+		notSwap  bool
+		contains string
+	}{
+		{"RPC error", `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid param"},"id":1}`, false, "Invalid param"},
+		{"not found", `{"jsonrpc":"2.0","result":null,"id":1}`, false, "not found"},
+		{"not JSON", `<html>`, false, "transaction"},
+		{"no meta", `{"transaction":{"signatures":["Signature"],"message":{"accountKeys":["` + swapper + `"]}}}`,
+			false, "missing meta"},
+		{"no block time", strings.Replace(transfer, `"blockTime":1732712455,`, "", 1), false, "blockTime"},
+		{"no DEX instruction", transfer, true, "no Raydium, Orca or Jupiter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || errors.Is(err, ErrNotSwap) != tt.notSwap || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("error %v, want one containing %q (not a swap: %v)", err, tt.contains, tt.notSwap)
+			}
+		})
+	}
+}
+
+func TestTrade(t *testing.T) {
+	trade := Trade(readTestdata(t, "orca-whirlpool-relayer-paid"))
+	if trade.Invalid != "" || trade.Platform != PlatformOrca || trade.WalletAddr != swapper {
+		t.Errorf("trade %+v, want a valid Orca trade by the swapper rather than the relayer %s", trade, relayer)
+	}
+	if trade.TradeID == "" || trade.Notional != money.USDT.Whole(500) {
+		t.Errorf("trade %q of %s, want the signature and 500 USDT", trade.TradeID, trade.Notional)
+	}
+
+	failed := Trade(readTestdata(t, "raydium-amm-failed"))
+	if failed.Invalid == "" || failed.Platform != PlatformSolana || failed.TradeID == "" {
+		t.Errorf("failed trade %+v, want it invalid on solana with its signature", failed)
+	}
+}
@@ -0,0 +1,250 @@
+package dex
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ==========================================
+// CONFIRMED TRANSACTION JSON
+// ==========================================
+
+// rpcResponse is the JSON-RPC envelope a getTransaction call answers with
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// transaction is the result of getTransaction in the json or jsonParsed encoding, legacy or version 0
+type transaction struct {
+	Slot        uint64 `json:"slot"`
+	BlockTime   *int64 `json:"blockTime"`
+	Meta        *meta  `json:"meta"`
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			// AccountKeys are base58 strings in the json encoding and objects in jsonParsed
+			AccountKeys []accountKey `json:"accountKeys"`
+			Header      struct {
+				NumRequiredSignatures int `json:"numRequiredSignatures"`
+			} `json:"header"`
+			Instructions []instruction `json:"instructions"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+type meta struct {
+	Err               json.RawMessage    `json:"err"`
+	Fee               uint64             `json:"fee"`
+	PreBalances       []uint64           `json:"preBalances"`
+	PostBalances      []uint64           `json:"postBalances"`
+	PreTokenBalances  []tokenBalance     `json:"preTokenBalances"`
+	PostTokenBalances []tokenBalance     `json:"postTokenBalances"`
+	InnerInstructions []innerInstruction `json:"innerInstructions"`
+	// LoadedAddresses are the accounts a version 0 transaction loads from lookup tables; the json
+	// encoding lists them here only, after the message's account keys
+	LoadedAddresses *struct {
+		Writable []string `json:"writable"`
+		Readonly []string `json:"readonly"`
+	} `json:"loadedAddresses"`
+}
+
+// accountKey is a message account, as a plain address or a jsonParsed object
+type accountKey struct {
+	Pubkey string `json:"pubkey"`
+	Signer bool   `json:"signer"`
+	// parsed reports that the key came as a jsonParsed object, so Signer is known
+	parsed bool
+}
+
+func (k *accountKey) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		return json.Unmarshal(data, &k.Pubkey)
+	}
+	type plain accountKey
+	if err := json.Unmarshal(data, (*plain)(k)); err != nil {
+		return err
+	}
+	k.parsed = true
+	return nil
+}
+
+// instruction is a compiled instruction in the json encoding (programIdIndex) or a jsonParsed one
+// (programId). Instructions jsonParsed decodes, such as token transfers, carry no data.
+type instruction struct {
+	ProgramIDIndex *int   `json:"programIdIndex"`
+	ProgramID      string `json:"programId"`
+	Data           string `json:"data"`
+}
+
+type innerInstruction struct {
+	Index        int           `json:"index"` // of the top-level instruction that made the calls
+	Instructions []instruction `json:"instructions"`
+}
+
+type tokenBalance struct {
+	AccountIndex  int    `json:"accountIndex"`
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UITokenAmount struct {
+		Amount   string `json:"amount"` // raw amount in the mint's smallest unit
+		Decimals int    `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+// decodeTransaction reads a getTransaction result, bare or in its JSON-RPC envelope
+func decodeTransaction(data []byte) (*transaction, error) {
+	var envelope rpcResponse
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("transaction: %w", err)
+	}
+	if envelope.Error != nil {
+		return nil, fmt.Errorf("transaction: RPC error %d: %s", envelope.Error.Code, envelope.Error.Message)
+	}
+	if envelope.Result != nil {
+		data = envelope.Result
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, errors.New("transaction: not found")
+	}
+
+	var tx transaction
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return nil, fmt.Errorf("transaction: %w", err)
+	}
+	if len(tx.Transaction.Signatures) == 0 || len(tx.Transaction.Message.AccountKeys) == 0 {
+		return nil, errors.New("transaction: missing signatures or account keys")
+	}
+	if tx.Meta == nil {
+		return nil, errors.New("transaction: missing meta")
+	}
+	return &tx, nil
+}
+
+// signature is the transaction's first signature, which identifies it
+func (tx *transaction) signature() string {
+	return tx.Transaction.Signatures[0]
+}
+
+// accounts lists every account an instruction or balance index refers to: the message's keys, then
+// the addresses loaded from lookup tables, writable first
+func (tx *transaction) accounts() []string {
+	keys := tx.Transaction.Message.AccountKeys
+	accounts := make([]string, 0, len(keys))
+	parsed := false
+	for _, key := range keys {
+		accounts = append(accounts, key.Pubkey)
+		parsed = parsed || key.parsed
+	}
+	// jsonParsed lists loaded addresses among the account keys already
+	if !parsed && tx.Meta.LoadedAddresses != nil {
+		accounts = append(accounts, tx.Meta.LoadedAddresses.Writable...)
+		accounts = append(accounts, tx.Meta.LoadedAddresses.Readonly...)
+	}
+	return accounts
+}
+
+// signers lists the accounts that signed the transaction; the fee payer comes first
+func (tx *transaction) signers() []string {
+	message := tx.Transaction.Message
+	signers := []string{}
+	for i, key := range message.AccountKeys {
+		if key.parsed && key.Signer || !key.parsed && i < message.Header.NumRequiredSignatures {
+			signers = append(signers, key.Pubkey)
+		}
+	}
+	return signers
+}
+
+// programID resolves the program an instruction calls
+func (tx *transaction) programID(ix instruction, accounts []string) string {
+	if ix.ProgramID != "" {
+		return ix.ProgramID
+	}
+	if ix.ProgramIDIndex != nil && *ix.ProgramIDIndex >= 0 && *ix.ProgramIDIndex < len(accounts) {
+		return accounts[*ix.ProgramIDIndex]
+	}
+	return ""
+}
+
+// tokenDeltas sums, per mint, how the balances of the owner's token accounts changed. Accounts opened
+// or closed by the transaction are missing from the pre or post balances and count as empty there.
+func (tx *transaction) tokenDeltas(owner string) (map[string]*big.Int, map[string]int, error) {
+	deltas := map[string]*big.Int{}
+	decimals := map[string]int{}
+	add := func(balances []tokenBalance, sign int) error {
+		for _, balance := range balances {
+			if balance.Owner != owner {
+				continue
+			}
+			amount, ok := new(big.Int).SetString(balance.UITokenAmount.Amount, 10)
+			if !ok {
+				return fmt.Errorf("transaction: token amount %q is not an integer", balance.UITokenAmount.Amount)
+			}
+			if deltas[balance.Mint] == nil {
+				deltas[balance.Mint] = new(big.Int)
+			}
+			if sign < 0 {
+				amount.Neg(amount)
+			}
+			deltas[balance.Mint].Add(deltas[balance.Mint], amount)
+			decimals[balance.Mint] = balance.UITokenAmount.Decimals
+		}
+		return nil
+	}
+	if err := add(tx.Meta.PreTokenBalances, -1); err != nil {
+		return nil, nil, err
+	}
+	if err := add(tx.Meta.PostTokenBalances, 1); err != nil {
+		return nil, nil, err
+	}
+	return deltas, decimals, nil
+}
+
+// lamportDelta is how the account's SOL balance changed, the transaction fee excluded
+func (tx *transaction) lamportDelta(account string, accounts []string) *big.Int {
+	delta := new(big.Int)
+	for i, key := range accounts {
+		if key != account || i >= len(tx.Meta.PreBalances) || i >= len(tx.Meta.PostBalances) {
+			continue
+		}
+		delta.SetUint64(tx.Meta.PostBalances[i])
+		delta.Sub(delta, new(big.Int).SetUint64(tx.Meta.PreBalances[i]))
+		if i == 0 {
+			delta.Add(delta, new(big.Int).SetUint64(tx.Meta.Fee))
+		}
+		break
+	}
+	return delta
+}
+
+// ==========================================
+// BASE58
+// ==========================================
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// decodeBase58 decodes instruction data as the json encoding renders it
+func decodeBase58(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(s); i++ {
+		digit := bytes.IndexByte([]byte(base58Alphabet), s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("base58: invalid character %q", s[i])
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
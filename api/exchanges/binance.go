@@ -0,0 +1,246 @@
+package exchanges
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// BINANCE
+// ==========================================
+
+const (
+	binanceFuturesBaseURL = "https://fapi.binance.com"
+	binanceSpotBaseURL    = "https://api.binance.com"
+	// binanceTradesLimit is the most trades userTrades returns per request
+	binanceTradesLimit = 1000
+	// binanceWindow is the longest time range userTrades accepts
+	binanceWindow     = 7 * 24 * time.Hour
+	binanceRecvWindow = "5000"
+)
+
+// DefaultBinanceSymbols are the USDⓈ-M perpetuals Binance trades are read for when Config names none
+var DefaultBinanceSymbols = []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}
+
+// binanceAuthCodes are the error codes of an invalid API key, signature or permission
+var binanceAuthCodes = map[int]bool{-1022: true, -2014: true, -2015: true}
+
+// Binance reads USDⓈ-M futures trades through the Binance futures API. Its trade history is per
+// symbol, so the configured symbols are read one request each and the cursor keeps a position per symbol.
+type Binance struct {
+	config Config
+}
+
+// NewBinance creates the Binance adapter
+func NewBinance(config Config) *Binance {
+	config = config.withDefaults(binanceFuturesBaseURL)
+	if config.SpotBaseURL == "" {
+		config.SpotBaseURL = binanceSpotBaseURL
+	}
+	config.SpotBaseURL = strings.TrimSuffix(config.SpotBaseURL, "/")
+	if len(config.Symbols) == 0 {
+		config.Symbols = DefaultBinanceSymbols
+	}
+	return &Binance{config: config}
+}
+
+func (a *Binance) Platform() string { return PlatformBinance }
+
+// binanceError is the body of a Binance error response
+type binanceError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// binanceTrade is an entry of GET /fapi/v1/userTrades
+type binanceTrade struct {
+	ID              int64  `json:"id"`
+	Symbol          string `json:"symbol"`
+	Side            string `json:"side"` // BUY or SELL
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Maker           bool   `json:"maker"`
+	Time            int64  `json:"time"`
+}
+
+// binanceCommissionRate is the body of GET /fapi/v1/commissionRate
+type binanceCommissionRate struct {
+	Symbol              string `json:"symbol"`
+	MakerCommissionRate string `json:"makerCommissionRate"`
+	TakerCommissionRate string `json:"takerCommissionRate"`
+}
+
+// binanceAccount is the part of GET /api/v3/account the adapter reads
+type binanceAccount struct {
+	UID int64 `json:"uid"`
+}
+
+// TradeHistory reads the next trades of every configured symbol. The cursor maps each symbol to
+// "id:N", the trade ID to continue from, or "t:MS" while the symbol has no trades yet and its history is
+// read a seven-day window at a time.
+func (a *Binance) TradeHistory(ctx context.Context, creds Credentials, query TradeQuery) (TradePage, error) {
+	cursor, err := url.ParseQuery(query.Cursor)
+	if err != nil {
+		return TradePage{}, fmt.Errorf("binance: malformed cursor %q", query.Cursor)
+	}
+	limit := query.Limit
+	if limit <= 0 || limit > binanceTradesLimit {
+		limit = binanceTradesLimit
+	}
+	now := a.config.now()
+
+	page := TradePage{}
+	next := url.Values{}
+	for _, symbol := range a.config.Symbols {
+		position := cursor.Get(symbol)
+		if position == "" {
+			position = "t:" + strconv.FormatInt(query.Since.UnixMilli(), 10)
+		}
+		mode, value, _ := strings.Cut(position, ":")
+		from, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || (mode != "id" && mode != "t") {
+			return TradePage{}, fmt.Errorf("binance: malformed cursor %q for %s", position, symbol)
+		}
+
+		params := url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(limit)}}
+		end := time.UnixMilli(from).Add(binanceWindow)
+		if mode == "id" {
+			params.Set("fromId", value)
+		} else {
+			if end.After(now) {
+				end = now
+			}
+			params.Set("startTime", value)
+			params.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+		}
+		var trades []binanceTrade
+		if err := a.signedGet(ctx, creds, a.config.BaseURL, "/fapi/v1/userTrades", params, &trades); err != nil {
+			return TradePage{}, err
+		}
+
+		for _, t := range trades {
+			fill, err := toBinanceFill(t)
+			if err != nil {
+				return TradePage{}, err
+			}
+			page.Fills = append(page.Fills, fill)
+		}
+		switch {
+		case len(trades) > 0:
+			next.Set(symbol, "id:"+strconv.FormatInt(trades[len(trades)-1].ID+1, 10))
+			page.More = page.More || len(trades) == limit
+		case mode == "t" && end.Before(now):
+			next.Set(symbol, "t:"+strconv.FormatInt(end.UnixMilli(), 10))
+			page.More = true
+		case mode == "t":
+			resume := end.Add(-syncOverlap)
+			if resume.UnixMilli() < from {
+				resume = time.UnixMilli(from)
+			}
+			next.Set(symbol, "t:"+strconv.FormatInt(resume.UnixMilli(), 10))
+		default:
+			next.Set(symbol, position)
+		}
+	}
+	page.Cursor = next.Encode()
+	return page, nil
+}
+
+func toBinanceFill(t binanceTrade) (Fill, error) {
+	quote := quoteOf(t.Symbol)
+	n, err := amounts(PlatformBinance, map[string]decimal{"price": {t.Price, quote}, "qty": {t.Qty, baseOf(t.Symbol)},
+		"quoteQty": {t.QuoteQty, quote}, "commission": {t.Commission, t.CommissionAsset}})
+	if err != nil {
+		return Fill{}, err
+	}
+	return Fill{
+		TradeID:       t.Symbol + ":" + strconv.FormatInt(t.ID, 10),
+		Symbol:        t.Symbol,
+		Side:          strings.ToLower(t.Side),
+		Price:         n["price"],
+		Quantity:      n["qty"],
+		Notional:      n["quoteQty"],
+		QuoteCurrency: quote,
+		Fee:           n["commission"],
+		FeeCurrency:   t.CommissionAsset,
+		Maker:         t.Maker,
+		ExecutedAt:    time.UnixMilli(t.Time).UTC(),
+	}, nil
+}
+
+func (a *Binance) FeeSchedule(ctx context.Context, creds Credentials, symbol string) (FeeSchedule, error) {
+	if symbol == "" {
+		symbol = a.config.Symbols[0]
+	}
+	var rate binanceCommissionRate
+	params := url.Values{"symbol": {symbol}}
+	if err := a.signedGet(ctx, creds, a.config.BaseURL, "/fapi/v1/commissionRate", params, &rate); err != nil {
+		return FeeSchedule{}, err
+	}
+	n, err := numbers(PlatformBinance, map[string]string{"makerCommissionRate": rate.MakerCommissionRate,
+		"takerCommissionRate": rate.TakerCommissionRate})
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	return FeeSchedule{Maker: n["makerCommissionRate"], Taker: n["takerCommissionRate"]}, nil
+}
+
+// BindAccount reads the account UID from the spot API; futures accounts share it
+func (a *Binance) BindAccount(ctx context.Context, creds Credentials) (Account, error) {
+	if creds.APIKey == "" || creds.APISecret == "" {
+		return Account{}, fmt.Errorf("binance: API key and secret are required: %w", ErrUnauthorized)
+	}
+	var account binanceAccount
+	params := url.Values{"omitZeroBalances": {"true"}}
+	if err := a.signedGet(ctx, creds, a.config.SpotBaseURL, "/api/v3/account", params, &account); err != nil {
+		return Account{}, err
+	}
+	if account.UID == 0 {
+		return Account{}, fmt.Errorf("binance: account has no uid")
+	}
+	return Account{ID: strconv.FormatInt(account.UID, 10)}, nil
+}
+
+// signedGet calls a USER_DATA endpoint: the query carries a timestamp and its HMAC signature
+func (a *Binance) signedGet(ctx context.Context, creds Credentials, baseURL, path string, params url.Values,
+	out any) error {
+	params.Set("recvWindow", binanceRecvWindow)
+	params.Set("timestamp", strconv.FormatInt(a.config.now().UnixMilli(), 10))
+	queryString := params.Encode()
+	mac := hmac.New(sha256.New, []byte(creds.APISecret))
+	mac.Write([]byte(queryString))
+	queryString += "&signature=" + hex.EncodeToString(mac.Sum(nil))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path+"?"+queryString, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-MBX-APIKEY", creds.APIKey)
+
+	err = doJSON(a.config.HTTPClient, req, PlatformBinance, out, decodeBinanceError)
+	if apiErr, ok := err.(*APIError); ok {
+		code, _ := strconv.Atoi(apiErr.Code)
+		apiErr.Unauthorized = binanceAuthCodes[code]
+	}
+	return err
+}
+
+func decodeBinanceError(body []byte) (string, string) {
+	var e binanceError
+	if json.Unmarshal(body, &e) != nil || e.Msg == "" {
+		return "", ""
+	}
+	return strconv.Itoa(e.Code), e.Msg
+}
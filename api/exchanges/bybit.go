@@ -0,0 +1,235 @@
+package exchanges
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// BYBIT
+// ==========================================
+
+const (
+	bybitBaseURL = "https://api.bybit.com"
+	// bybitExecutionLimit is the most executions execution/list returns per request
+	bybitExecutionLimit = 100
+	// bybitWindow is the longest time range execution/list accepts
+	bybitWindow = 7 * 24 * time.Hour
+	// bybitCategory limits the history to USDT and USDC perpetuals
+	bybitCategory   = "linear"
+	bybitRecvWindow = "5000"
+)
+
+// Bybit reads linear perpetual executions through the Bybit v5 API, a seven-day window at a time
+type Bybit struct {
+	config Config
+}
+
+// NewBybit creates the Bybit adapter
+func NewBybit(config Config) *Bybit {
+	return &Bybit{config: config.withDefaults(bybitBaseURL)}
+}
+
+func (a *Bybit) Platform() string { return PlatformBybit }
+
+// bybitAuthCodes are the retCodes of an invalid, expired or wrongly signed API key
+var bybitAuthCodes = map[int]bool{10003: true, 10004: true, 10005: true, 10007: true, 33004: true}
+
+// bybitResponse is the envelope of every Bybit v5 response; retCode 0 is success
+type bybitResponse struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// bybitExecution is an entry of GET /v5/execution/list
+type bybitExecution struct {
+	Symbol    string `json:"symbol"`
+	ExecID    string `json:"execId"`
+	Side      string `json:"side"` // Buy or Sell
+	ExecPrice string `json:"execPrice"`
+	ExecQty   string `json:"execQty"`
+	ExecValue string `json:"execValue"`
+	ExecFee   string `json:"execFee"`
+	ExecType  string `json:"execType"` // Trade, Funding, BustTrade, ...
+	IsMaker   bool   `json:"isMaker"`
+	ExecTime  string `json:"execTime"`
+}
+
+type bybitExecutionList struct {
+	NextPageCursor string           `json:"nextPageCursor"`
+	List           []bybitExecution `json:"list"`
+}
+
+// bybitFeeRate is an entry of GET /v5/account/fee-rate
+type bybitFeeRate struct {
+	Symbol       string `json:"symbol"`
+	TakerFeeRate string `json:"takerFeeRate"`
+	MakerFeeRate string `json:"makerFeeRate"`
+}
+
+// bybitAPIKey is the result of GET /v5/user/query-api
+type bybitAPIKey struct {
+	UserID int64 `json:"userID"`
+}
+
+func (a *Bybit) TradeHistory(ctx context.Context, creds Credentials, query TradeQuery) (TradePage, error) {
+	now := a.config.now()
+	cursor, err := parseWindowCursor(PlatformBybit, query, now, bybitWindow)
+	if err != nil {
+		return TradePage{}, err
+	}
+	limit := query.Limit
+	if limit <= 0 || limit > bybitExecutionLimit {
+		limit = bybitExecutionLimit
+	}
+
+	params := url.Values{
+		"category":  {bybitCategory},
+		"startTime": {strconv.FormatInt(cursor.start.UnixMilli(), 10)},
+		"endTime":   {strconv.FormatInt(cursor.end.UnixMilli(), 10)},
+		"limit":     {strconv.Itoa(limit)},
+	}
+	if cursor.page != "" {
+		params.Set("cursor", cursor.page)
+	}
+	var executions bybitExecutionList
+	if err := a.get(ctx, creds, "/v5/execution/list", params, &executions); err != nil {
+		return TradePage{}, err
+	}
+
+	page := TradePage{Fills: make([]Fill, 0, len(executions.List))}
+	for _, e := range executions.List {
+		// Funding and liquidation executions are not trades the user placed
+		if e.ExecType != "Trade" {
+			continue
+		}
+		fill, err := toBybitFill(e)
+		if err != nil {
+			return TradePage{}, err
+		}
+		page.Fills = append(page.Fills, fill)
+	}
+	page.Cursor, page.More = cursor.next(executions.NextPageCursor)
+	return page, nil
+}
+
+func toBybitFill(e bybitExecution) (Fill, error) {
+	quote := bybitQuote(e.Symbol)
+	n, err := amounts(PlatformBybit, map[string]decimal{"execPrice": {e.ExecPrice, quote},
+		"execQty": {e.ExecQty, baseOf(e.Symbol)}, "execValue": {e.ExecValue, quote}, "execFee": {e.ExecFee, quote}})
+	if err != nil {
+		return Fill{}, err
+	}
+	executedAt, err := millis(e.ExecTime)
+	if err != nil {
+		return Fill{}, fmt.Errorf("bybit: execTime %q is not Unix milliseconds", e.ExecTime)
+	}
+	return Fill{
+		TradeID:       e.ExecID,
+		Symbol:        e.Symbol,
+		Side:          strings.ToLower(e.Side),
+		Price:         n["execPrice"],
+		Quantity:      n["execQty"],
+		Notional:      n["execValue"],
+		QuoteCurrency: quote,
+		Fee:           n["execFee"],
+		FeeCurrency:   quote,
+		Maker:         e.IsMaker,
+		ExecutedAt:    executedAt,
+	}, nil
+}
+
+// bybitQuote returns the settle currency of a linear symbol; USDC perpetuals are named like BTCPERP
+func bybitQuote(symbol string) string {
+	if strings.HasSuffix(symbol, "PERP") {
+		return "USDC"
+	}
+	return quoteOf(symbol)
+}
+
+func (a *Bybit) FeeSchedule(ctx context.Context, creds Credentials, symbol string) (FeeSchedule, error) {
+	params := url.Values{"category": {bybitCategory}}
+	if symbol != "" {
+		params.Set("symbol", symbol)
+	}
+	var rates struct {
+		List []bybitFeeRate `json:"list"`
+	}
+	if err := a.get(ctx, creds, "/v5/account/fee-rate", params, &rates); err != nil {
+		return FeeSchedule{}, err
+	}
+	if len(rates.List) == 0 {
+		return FeeSchedule{}, fmt.Errorf("bybit: no fee rates for %q", symbol)
+	}
+	n, err := numbers(PlatformBybit, map[string]string{"makerFeeRate": rates.List[0].MakerFeeRate,
+		"takerFeeRate": rates.List[0].TakerFeeRate})
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	return FeeSchedule{Maker: n["makerFeeRate"], Taker: n["takerFeeRate"]}, nil
+}
+
+func (a *Bybit) BindAccount(ctx context.Context, creds Credentials) (Account, error) {
+	if creds.APIKey == "" || creds.APISecret == "" {
+		return Account{}, fmt.Errorf("bybit: API key and secret are required: %w", ErrUnauthorized)
+	}
+	var key bybitAPIKey
+	if err := a.get(ctx, creds, "/v5/user/query-api", nil, &key); err != nil {
+		return Account{}, err
+	}
+	if key.UserID == 0 {
+		return Account{}, fmt.Errorf("bybit: API key info has no userID")
+	}
+	return Account{ID: strconv.FormatInt(key.UserID, 10)}, nil
+}
+
+// get calls a signed GET endpoint and decodes the envelope's result into out
+func (a *Bybit) get(ctx context.Context, creds Credentials, path string, params url.Values, out any) error {
+	queryString := params.Encode()
+	target := a.config.BaseURL + path
+	if queryString != "" {
+		target += "?" + queryString
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(a.config.now().UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(creds.APISecret))
+	mac.Write([]byte(timestamp + creds.APIKey + bybitRecvWindow + queryString))
+	req.Header.Set("X-BAPI-API-KEY", creds.APIKey)
+	req.Header.Set("X-BAPI-SIGN", hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", bybitRecvWindow)
+
+	var envelope bybitResponse
+	if err := doJSON(a.config.HTTPClient, req, PlatformBybit, &envelope, decodeBybitError); err != nil {
+		return err
+	}
+	if envelope.RetCode != 0 {
+		return &APIError{Platform: PlatformBybit, StatusCode: http.StatusOK, Code: strconv.Itoa(envelope.RetCode),
+			Message: envelope.RetMsg, Unauthorized: bybitAuthCodes[envelope.RetCode]}
+	}
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("bybit: decode result: %w", err)
+	}
+	return nil
+}
+
+func decodeBybitError(body []byte) (string, string) {
+	var envelope bybitResponse
+	if json.Unmarshal(body, &envelope) != nil || envelope.RetMsg == "" {
+		return "", ""
+	}
+	return strconv.Itoa(envelope.RetCode), envelope.RetMsg
+}
@@ -0,0 +1,86 @@
+package exchanges
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ==========================================
+// CREDENTIAL SEALING
+// ==========================================
+
+// developmentKey seals credentials when no key is configured; it is public, so only for development
+var developmentKey = []byte("aiw3-development-exchange-key-32")
+
+// Sealer encrypts API credentials with AES-256-GCM before they are stored
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer creates a sealer with a 32-byte key
+func NewSealer(key []byte) (*Sealer, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("exchange key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// ParseSealerKey creates a sealer from a base64-encoded 32-byte key
+func ParseSealerKey(value string) (*Sealer, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("exchange key must be base64")
+	}
+	return NewSealer(key)
+}
+
+// DevelopmentSealer creates a sealer with the public development key
+func DevelopmentSealer() *Sealer {
+	sealer, err := NewSealer(developmentKey)
+	if err != nil {
+		panic(err)
+	}
+	return sealer
+}
+
+// Seal encrypts the credentials; the result is base64 of the nonce followed by the ciphertext
+func (s *Sealer) Seal(creds Credentials) (string, error) {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Open decrypts sealed credentials; it fails when they were sealed with another key
+func (s *Sealer) Open(sealed string) (Credentials, error) {
+	var creds Credentials
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return creds, errors.New("sealed credentials are malformed")
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return creds, errors.New("sealed credentials cannot be opened with this key")
+	}
+	err = json.Unmarshal(plaintext, &creds)
+	return creds, err
+}
@@ -0,0 +1,384 @@
+// Package exchanges talks to the exchanges users trade on. An ExchangeAdapter per exchange pages
+// through an account's trade history, looks up its fee schedule and resolves the account its API key
+// belongs to; the syncer pulls every bound account's new fills into trading volume and the fee ledger.
+// Adapters parse each exchange's documented JSON and take their base URL from Config, so they can be
+// pointed at the exchangetest stand-in, which serves recorded payloads.
+package exchanges
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// Platforms adapters exist for; they match the nfts TradingPlatform values
+const (
+	PlatformOKX         = "okx"
+	PlatformBybit       = "bybit"
+	PlatformBinance     = "binance"
+	PlatformGate        = "gate"
+	PlatformHyperliquid = "hyperliquid"
+)
+
+const (
+	// requestTimeout bounds one call to an exchange when Config has no HTTP client
+	requestTimeout = 15 * time.Second
+	// maxErrorBody is how much of an error response is read for its message
+	maxErrorBody = 4096
+)
+
+// ErrUnauthorized is returned when the exchange refuses the credentials
+var ErrUnauthorized = errors.New("exchange refused the API credentials")
+
+// ==========================================
+// ADAPTER INTERFACE
+// ==========================================
+
+// ExchangeAdapter reads an account's data from one exchange
+type ExchangeAdapter interface {
+	Platform() string
+	// TradeHistory returns one page of the account's fills. With an empty query cursor the history starts
+	// at Since; otherwise it resumes from the cursor of an earlier page. The returned cursor continues the
+	// history: while More is set it fetches the next page, afterwards it is where the next sync resumes.
+	TradeHistory(ctx context.Context, creds Credentials, query TradeQuery) (TradePage, error)
+	// FeeSchedule returns the account's fee rates for a symbol as named in its fills
+	FeeSchedule(ctx context.Context, creds Credentials, symbol string) (FeeSchedule, error)
+	// BindAccount returns the account the credentials belong to; ErrUnauthorized when they are refused
+	BindAccount(ctx context.Context, creds Credentials) (Account, error)
+}
+
+// Credentials authenticate with an exchange. Hyperliquid reads fills by wallet and needs only Wallet;
+// OKX also needs the API key's passphrase.
+type Credentials struct {
+	APIKey     string `json:"apiKey,omitempty"`
+	APISecret  string `json:"apiSecret,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`
+	Wallet     string `json:"wallet,omitempty"`
+}
+
+// TradeQuery selects a page of trade history
+type TradeQuery struct {
+	Since  time.Time // where the history starts when Cursor is empty
+	Cursor string
+	Limit  int // fills per request; 0 uses the exchange's maximum
+}
+
+// TradePage is a page of trade history
+type TradePage struct {
+	Fills  []Fill
+	Cursor string
+	More   bool
+}
+
+// Fill is one execution of an order. Amounts are parsed from the exchange's decimal strings, so they
+// are exact up to money.Scale decimals.
+type Fill struct {
+	// TradeID is unique within the account; IDs exchanges number per symbol are prefixed with the symbol.
+	// Both sides of a trade may share it, so it is not unique on the platform.
+	TradeID       string
+	Symbol        string
+	Side          string       // buy or sell
+	Price         money.Amount // in QuoteCurrency
+	Quantity      money.Amount // in the base asset
+	Notional      money.Amount // price times quantity, in QuoteCurrency
+	QuoteCurrency string       // e.g. USDT, "" when unknown
+	Fee           money.Amount // fee paid in FeeCurrency, negative for a rebate
+	FeeCurrency   string
+	Maker         bool
+	ExecutedAt    time.Time
+}
+
+// FeeSchedule holds an account's fee rates as fractions of notional; a negative rate is a rebate
+type FeeSchedule struct {
+	Maker float64
+	Taker float64
+	Tier  string // the exchange's name for the account's fee tier, "" when it has none
+}
+
+// Rate returns the rate a fill was charged at
+func (s FeeSchedule) Rate(maker bool) float64 {
+	if maker {
+		return s.Maker
+	}
+	return s.Taker
+}
+
+// Account identifies an exchange account
+type Account struct {
+	ID string // account UID, or the wallet address for Hyperliquid
+}
+
+// Config points an adapter at an exchange. Empty fields use the exchange's production values.
+type Config struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Symbols Binance reads trades for, since its trade history is per symbol
+	Symbols []string
+	// SpotBaseURL is Binance's spot API, where account UIDs are read
+	SpotBaseURL string
+	now         func() time.Time
+}
+
+func (c Config) withDefaults(baseURL string) Config {
+	if c.BaseURL == "" {
+		c.BaseURL = baseURL
+	}
+	c.BaseURL = strings.TrimSuffix(c.BaseURL, "/")
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: requestTimeout}
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+	return c
+}
+
+// ==========================================
+// REGISTRY
+// ==========================================
+
+// Registry holds an adapter per platform
+type Registry struct {
+	adapters map[string]ExchangeAdapter
+}
+
+// NewRegistry creates a registry of the adapters
+func NewRegistry(adapters ...ExchangeAdapter) *Registry {
+	registry := &Registry{adapters: map[string]ExchangeAdapter{}}
+	for _, adapter := range adapters {
+		registry.adapters[adapter.Platform()] = adapter
+	}
+	return registry
+}
+
+// DefaultRegistry creates an adapter for every supported exchange with config; with a BaseURL every
+// adapter talks to it, which is how the exchangetest stand-in is used
+func DefaultRegistry(config Config) *Registry {
+	binance := config
+	if binance.Symbols == nil {
+		binance.Symbols = DefaultBinanceSymbols
+	}
+	if config.BaseURL != "" && binance.SpotBaseURL == "" {
+		binance.SpotBaseURL = config.BaseURL
+	}
+	return NewRegistry(NewOKX(config), NewBybit(config), NewBinance(binance), NewGate(config), NewHyperliquid(config))
+}
+
+// Get returns the platform's adapter
+func (r *Registry) Get(platform string) (ExchangeAdapter, bool) {
+	adapter, ok := r.adapters[platform]
+	return adapter, ok
+}
+
+// Platforms lists the platforms with an adapter
+func (r *Registry) Platforms() []string {
+	platforms := make([]string, 0, len(r.adapters))
+	for platform := range r.adapters {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// ==========================================
+// HTTP HELPERS
+// ==========================================
+
+// APIError is an error response of an exchange
+type APIError struct {
+	Platform   string
+	StatusCode int
+	Code       string // the exchange's error code, "" when it sent none
+	Message    string
+	// Unauthorized is set for error codes that refuse the credentials in a 200 response
+	Unauthorized bool
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s: %s (code %s, HTTP %d)", e.Platform, e.Message, e.Code, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (HTTP %d)", e.Platform, e.Message, e.StatusCode)
+}
+
+// Unwrap reports refused credentials as ErrUnauthorized
+func (e *APIError) Unwrap() error {
+	if e.Unauthorized || e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// doJSON sends req and decodes a 2xx response into out. Other responses become an APIError with the
+// message decode finds in the body.
+func doJSON(client *http.Client, req *http.Request, platform string, out any,
+	decodeError func(body []byte) (code, message string)) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", platform, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		apiErr := &APIError{Platform: platform, StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		if code, message := decodeError(body); message != "" {
+			apiErr.Code, apiErr.Message = code, message
+		}
+		return apiErr
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s: decode response: %w", platform, err)
+	}
+	return nil
+}
+
+// parseNumber parses the decimal strings exchanges send numbers as; "" is zero
+func parseNumber(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// numbers parses decimal strings, naming the first malformed one
+func numbers(platform string, values map[string]string) (map[string]float64, error) {
+	parsed := make(map[string]float64, len(values))
+	for name, value := range values {
+		number, err := parseNumber(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s %q is not a number", platform, name, value)
+		}
+		parsed[name] = number
+	}
+	return parsed, nil
+}
+
+// decimal is a decimal string an exchange sent and the currency it is in
+type decimal struct {
+	value    string
+	currency string
+}
+
+// amounts parses decimal strings into amounts of their currencies, naming the first malformed one; ""
+// is zero
+func amounts(platform string, values map[string]decimal) (map[string]money.Amount, error) {
+	parsed := make(map[string]money.Amount, len(values))
+	for name, value := range values {
+		currency := money.Currency(value.currency)
+		if value.value == "" {
+			parsed[name] = currency.Units(0)
+			continue
+		}
+		amount, err := currency.Parse(value.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s %q is not a decimal amount", platform, name, value.value)
+		}
+		parsed[name] = amount
+	}
+	return parsed, nil
+}
+
+// quoteOf returns the quote currency of symbols such as BTCUSDT, BTC_USDT or BTC-USDT-SWAP
+func quoteOf(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	for _, quote := range []string{"USDT", "USDC"} {
+		if strings.HasSuffix(symbol, quote) || strings.Contains(symbol, "-"+quote+"-") {
+			return quote
+		}
+	}
+	return ""
+}
+
+// baseOf returns the base asset of symbols such as BTCUSDT, BTC_USDT, BTC-USDT-SWAP or BTCPERP; a coin
+// such as BTC is its own base
+func baseOf(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	if i := strings.IndexAny(symbol, "-_"); i > 0 {
+		return symbol[:i]
+	}
+	for _, quote := range []string{"USDT", "USDC", "PERP"} {
+		if base := strings.TrimSuffix(symbol, quote); base != symbol && base != "" {
+			return base
+		}
+	}
+	return symbol
+}
+
+// millis parses Unix milliseconds sent as a string
+func millis(value string) (time.Time, error) {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms).UTC(), nil
+}
+
+// ==========================================
+// TIME WINDOW CURSOR
+// ==========================================
+
+// syncOverlap is how far back of the last window the next sync starts, for fills an exchange
+// publishes a little after they executed; the fills read again are duplicates
+const syncOverlap = 10 * time.Minute
+
+// windowCursor pages through exchanges whose trade history is requested per time window. It is
+// "start" between syncs and "start:end:page" inside a window, page being the exchange's own cursor.
+type windowCursor struct {
+	start  time.Time
+	end    time.Time
+	page   string
+	window time.Duration
+}
+
+func parseWindowCursor(platform string, query TradeQuery, now time.Time, window time.Duration) (windowCursor, error) {
+	c := windowCursor{start: query.Since.UTC(), window: window}
+	if query.Cursor != "" {
+		parts := strings.SplitN(query.Cursor, ":", 3)
+		start, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || len(parts) == 2 {
+			return c, fmt.Errorf("%s: malformed cursor %q", platform, query.Cursor)
+		}
+		c.start = time.UnixMilli(start).UTC()
+		if len(parts) == 3 {
+			end, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				return c, fmt.Errorf("%s: malformed cursor %q", platform, query.Cursor)
+			}
+			c.end, c.page = time.UnixMilli(end).UTC(), parts[2]
+		}
+	}
+	if c.end.IsZero() {
+		c.end = c.start.Add(window)
+		if c.end.After(now) {
+			c.end = now.UTC().Truncate(time.Millisecond)
+		}
+	}
+	return c, nil
+}
+
+// next returns the cursor after a page the exchange continued with page, "" at the end of the window.
+// A full-length window is followed by the next one; a shorter one was cut at the time it was first
+// read, so the history is caught up.
+func (c windowCursor) next(page string) (string, bool) {
+	if page != "" {
+		return fmt.Sprintf("%d:%d:%s", c.start.UnixMilli(), c.end.UnixMilli(), page), true
+	}
+	if !c.end.Before(c.start.Add(c.window)) {
+		return strconv.FormatInt(c.end.UnixMilli(), 10), true
+	}
+	resume := c.end.Add(-syncOverlap)
+	if resume.Before(c.start) {
+		resume = c.start
+	}
+	return strconv.FormatInt(resume.UnixMilli(), 10), false
+}
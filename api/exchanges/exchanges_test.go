@@ -0,0 +1,135 @@
+package exchanges
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/exchanges/exchangetest"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+)
+
+var standinCreds = Credentials{APIKey: "standin-key", APISecret: "standin-secret", Passphrase: "standin-passphrase",
+	Wallet: exchangetest.HyperliquidWallet}
+
+func TestTradeHistoryReadsDecimalAmounts(t *testing.T) {
+	server := exchangetest.NewServer()
+	defer server.Close()
+	registry := DefaultRegistry(Config{BaseURL: server.URL, HTTPClient: server.Client()})
+
+	tests := []struct {
+		platform string
+		price    string
+		quantity string
+		notional string
+		fee      string
+	}{
+		// Amounts are "<currency>:<decimal>" unless in USDT
+		{PlatformOKX, "51840.2", "BTC:0.12", "6220.824", "3.1104"},
+		{PlatformBybit, "51880", "BTC:0.4", "20752", "12.4512"},
+		{PlatformBinance, "51892.5", "BTC:0.2", "10378.5", "4.1514"},
+		{PlatformGate, "108.35", "SOL:120", "13002", "SOL:0.24"},
+		{PlatformHyperliquid, "USDC:2938.6", "ETH:2.5", "USDC:7346.5", "USDC:2.314148"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			adapter, _ := registry.Get(tt.platform)
+			page, err := adapter.TradeHistory(context.Background(), standinCreds,
+				TradeQuery{Since: time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Fills) == 0 {
+				t.Fatal("no fills")
+			}
+			fill := page.Fills[0]
+			for name, got := range map[string]struct {
+				amount money.Amount
+				want   string
+			}{
+				"price":    {fill.Price, tt.price},
+				"quantity": {fill.Quantity, tt.quantity},
+				"notional": {fill.Notional, tt.notional},
+				"fee":      {fill.Fee, tt.fee},
+			} {
+				var want money.Amount
+				if err := want.UnmarshalJSON([]byte(strconv.Quote(got.want))); err != nil {
+					t.Fatal(err)
+				}
+				if got.amount != want {
+					t.Errorf("%s %s %s, want %s", name, got.amount, got.amount.Currency(), got.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBaseOf(t *testing.T) {
+	for symbol, want := range map[string]string{
+		"BTCUSDT":       "BTC",
+		"BTC-USDT-SWAP": "BTC",
+		"sol_usdt":      "SOL",
+		"ETHPERP":       "ETH",
+		"ETH":           "ETH",
+		"USDT":          "USDT",
+	} {
+		if got := baseOf(symbol); got != want {
+			t.Errorf("baseOf(%q) = %q, want %q", symbol, got, want)
+		}
+	}
+}
+
+// recordingSink keeps the fills it is given
+type recordingSink struct {
+	fills []SyncedFill
+}
+
+func (s *recordingSink) Record(ctx context.Context, account repository.ExchangeAccount, fills []SyncedFill) error {
+	s.fills = append(s.fills, fills...)
+	return nil
+}
+
+func TestSyncFailsOnMalformedAmount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"coin":"ETH","px":"2,938.6","sz":"2.5","side":"B","time":1708439700000,` +
+			`"crossed":true,"fee":"2.314148","feeToken":"USDC","tid":918364721006548}]`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	store := memory.NewStore()
+	sealer := DevelopmentSealer()
+	sealed, err := sealer.Seal(Credentials{Wallet: exchangetest.HyperliquidWallet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := &repository.ExchangeAccount{UserID: 1, Platform: PlatformHyperliquid,
+		AccountID: exchangetest.HyperliquidWallet, Credentials: sealed, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := store.Exchanges.Create(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+
+	sink := &recordingSink{}
+	registry := NewRegistry(NewHyperliquid(Config{BaseURL: server.URL, HTTPClient: server.Client()}))
+	synced, err := NewSyncer(store, registry, sealer, sink).SyncAccount(ctx, account)
+	if err == nil || !strings.Contains(err.Error(), `px "2,938.6"`) {
+		t.Errorf("sync error %v, want the malformed price named", err)
+	}
+	if synced != 0 || len(sink.fills) != 0 {
+		t.Errorf("%d fills synced, %d recorded; want none", synced, len(sink.fills))
+	}
+	stored, err := store.Exchanges.Get(ctx, 1, PlatformHyperliquid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.SyncError == "" || stored.SyncedAt != nil {
+		t.Errorf("sync error %q, synced at %v; want the failure saved on the account", stored.SyncError, stored.SyncedAt)
+	}
+}
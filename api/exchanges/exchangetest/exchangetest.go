@@ -0,0 +1,183 @@
+// Package exchangetest provides a stand-in for the exchanges the exchanges adapters talk to. It serves
+// payloads recorded from each exchange's documented API, so adapters, the account sync and the binding
+// endpoints can be exercised without exchange accounts.
+package exchangetest
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+)
+
+//go:embed payloads
+var payloads embed.FS
+
+// RefusedAPIKey is an API key every exchange of the stand-in refuses
+const RefusedAPIKey = "refused-key"
+
+// Account IDs the recorded payloads bind to
+const (
+	OKXAccountID     = "44705892343619584"
+	BybitAccountID   = "24617703"
+	BinanceAccountID = "354937868"
+	GateAccountID    = "10001984"
+	// HyperliquidWallet binds a Hyperliquid account; the recorded fills are served for any address
+	HyperliquidWallet = "0x8f2a4c1e9b7d3a5f6e0c2b4d8a1f3e5c7b9d0a2e"
+)
+
+// BybitSecondPageCursor is the cursor of the second page of recorded Bybit executions
+const BybitSecondPageCursor = "132766%3A2%2C132766%3A2"
+
+// NewServer starts a stand-in serving every exchange; point the adapters' BaseURL at its URL.
+// Signed requests need an API key header, which is not otherwise checked.
+func NewServer() *httptest.Server {
+	return httptest.NewServer(Handler())
+}
+
+// Handler routes each exchange's endpoints to their recorded payloads
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// OKX v5
+	okx := requireKey("OK-ACCESS-KEY", "okx/unauthorized.json")
+	mux.Handle("/api/v5/trade/fills-history", okx(func(w http.ResponseWriter, r *http.Request) {
+		// The recorded fills are a single page: paging back past its last bill finds nothing older
+		if r.URL.Query().Get("after") != "" {
+			writeJSON(w, http.StatusOK, map[string]any{"code": "0", "msg": "", "data": []any{}})
+			return
+		}
+		serve(w, "okx/fills-history.json")
+	}))
+	mux.HandleFunc("/api/v5/public/instruments", func(w http.ResponseWriter, r *http.Request) {
+		var recorded struct {
+			Data []map[string]any `json:"data"`
+		}
+		if !decode(w, "okx/instruments.json", &recorded) {
+			return
+		}
+		instruments := []map[string]any{}
+		for _, instrument := range recorded.Data {
+			if id := r.URL.Query().Get("instId"); id == "" || instrument["instId"] == id {
+				instruments = append(instruments, instrument)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"code": "0", "msg": "", "data": instruments})
+	})
+	mux.Handle("/api/v5/account/trade-fee", okx(servePayload("okx/trade-fee.json")))
+	mux.Handle("/api/v5/account/config", okx(servePayload("okx/account-config.json")))
+
+	// Bybit v5; executions come in two pages
+	bybit := requireKey("X-BAPI-API-KEY", "bybit/unauthorized.json")
+	mux.Handle("/v5/execution/list", bybit(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == BybitSecondPageCursor {
+			serve(w, "bybit/execution-list-2.json")
+			return
+		}
+		serve(w, "bybit/execution-list-1.json")
+	}))
+	mux.Handle("/v5/account/fee-rate", bybit(servePayload("bybit/fee-rate.json")))
+	mux.Handle("/v5/user/query-api", bybit(servePayload("bybit/query-api.json")))
+
+	// Binance USDⓈ-M futures and spot; trades are recorded for BTCUSDT
+	binance := requireKey("X-MBX-APIKEY", "binance/unauthorized.json")
+	mux.Handle("/fapi/v1/userTrades", binance(func(w http.ResponseWriter, r *http.Request) {
+		var recorded []map[string]any
+		if !decode(w, "binance/user-trades.json", &recorded) {
+			return
+		}
+		fromID, _ := strconv.ParseFloat(r.URL.Query().Get("fromId"), 64)
+		trades := []map[string]any{}
+		for _, trade := range recorded {
+			if trade["symbol"] == r.URL.Query().Get("symbol") && trade["id"].(float64) >= fromID {
+				trades = append(trades, trade)
+			}
+		}
+		writeJSON(w, http.StatusOK, trades)
+	}))
+	mux.Handle("/fapi/v1/commissionRate", binance(servePayload("binance/commission-rate.json")))
+	mux.Handle("/api/v3/account", binance(servePayload("binance/account.json")))
+
+	// Gate v4; the recorded trades are a single page
+	gate := requireKey("KEY", "gate/unauthorized.json")
+	mux.Handle("/api/v4/spot/my_trades", gate(func(w http.ResponseWriter, r *http.Request) {
+		if page := r.URL.Query().Get("page"); page != "" && page != "1" {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		serve(w, "gate/my-trades.json")
+	}))
+	mux.Handle("/api/v4/wallet/fee", gate(servePayload("gate/wallet-fee.json")))
+
+	// Hyperliquid info endpoint; fills are recorded for every address
+	mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Type string `json:"type"`
+			User string `json:"user"`
+		}
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&request) != nil {
+			http.Error(w, "Failed to deserialize the JSON body into the target type", http.StatusUnprocessableEntity)
+			return
+		}
+		switch request.Type {
+		case "userFillsByTime":
+			serve(w, "hyperliquid/user-fills-by-time.json")
+		case "userFees":
+			serve(w, "hyperliquid/user-fees.json")
+		default:
+			http.Error(w, "Failed to deserialize the JSON body into the target type", http.StatusUnprocessableEntity)
+		}
+	})
+
+	return mux
+}
+
+// requireKey refuses requests without the exchange's API key header, or with RefusedAPIKey, with the
+// exchange's recorded unauthorized response
+func requireKey(header, unauthorized string) func(http.HandlerFunc) http.Handler {
+	return func(next http.HandlerFunc) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key := r.Header.Get(header); key == "" || key == RefusedAPIKey {
+				body, _ := payloads.ReadFile("payloads/" + unauthorized)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write(body)
+				return
+			}
+			next(w, r)
+		})
+	}
+}
+
+func servePayload(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) { serve(w, name) }
+}
+
+func serve(w http.ResponseWriter, name string) {
+	body, err := payloads.ReadFile("payloads/" + name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func decode(w http.ResponseWriter, name string, out any) bool {
+	body, err := payloads.ReadFile("payloads/" + name)
+	if err == nil {
+		err = json.Unmarshal(body, out)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
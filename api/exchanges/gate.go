@@ -0,0 +1,209 @@
+package exchanges
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// ==========================================
+// GATE
+// ==========================================
+
+const (
+	gateBaseURL = "https://api.gateio.ws"
+	// gateTradesLimit is the most trades my_trades returns per page
+	gateTradesLimit = 1000
+	// gateWindow keeps each request well inside the time range my_trades accepts
+	gateWindow = 7 * 24 * time.Hour
+)
+
+// Gate reads spot trades through the Gate v4 API, a seven-day window at a time
+type Gate struct {
+	config Config
+}
+
+// NewGate creates the Gate adapter
+func NewGate(config Config) *Gate {
+	return &Gate{config: config.withDefaults(gateBaseURL)}
+}
+
+func (a *Gate) Platform() string { return PlatformGate }
+
+// gateError is the body of a Gate error response
+type gateError struct {
+	Label   string `json:"label"`
+	Message string `json:"message"`
+}
+
+// gateTrade is an entry of GET /api/v4/spot/my_trades
+type gateTrade struct {
+	ID           string `json:"id"`
+	CreateTimeMs string `json:"create_time_ms"` // milliseconds with a fraction, e.g. 1548000000123.456
+	CurrencyPair string `json:"currency_pair"`
+	Side         string `json:"side"`
+	Role         string `json:"role"` // taker or maker
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	Fee          string `json:"fee"`
+	FeeCurrency  string `json:"fee_currency"`
+}
+
+// gateFee is the body of GET /api/v4/wallet/fee
+type gateFee struct {
+	UserID   int64  `json:"user_id"`
+	TakerFee string `json:"taker_fee"`
+	MakerFee string `json:"maker_fee"`
+}
+
+func (a *Gate) TradeHistory(ctx context.Context, creds Credentials, query TradeQuery) (TradePage, error) {
+	now := a.config.now()
+	cursor, err := parseWindowCursor(PlatformGate, query, now, gateWindow)
+	if err != nil {
+		return TradePage{}, err
+	}
+	pageNumber := 1
+	if cursor.page != "" {
+		if pageNumber, err = strconv.Atoi(cursor.page); err != nil || pageNumber < 1 {
+			return TradePage{}, fmt.Errorf("gate: malformed cursor %q", query.Cursor)
+		}
+	}
+	limit := query.Limit
+	if limit <= 0 || limit > gateTradesLimit {
+		limit = gateTradesLimit
+	}
+
+	params := url.Values{
+		"from":  {strconv.FormatInt(cursor.start.Unix(), 10)},
+		"to":    {strconv.FormatInt(cursor.end.Unix(), 10)},
+		"limit": {strconv.Itoa(limit)},
+		"page":  {strconv.Itoa(pageNumber)},
+	}
+	var trades []gateTrade
+	if err := a.get(ctx, creds, "/api/v4/spot/my_trades", params, &trades); err != nil {
+		return TradePage{}, err
+	}
+
+	page := TradePage{Fills: make([]Fill, 0, len(trades))}
+	for _, t := range trades {
+		fill, err := toGateFill(t)
+		if err != nil {
+			return TradePage{}, err
+		}
+		page.Fills = append(page.Fills, fill)
+	}
+	nextPage := ""
+	if len(trades) == limit {
+		nextPage = strconv.Itoa(pageNumber + 1)
+	}
+	page.Cursor, page.More = cursor.next(nextPage)
+	return page, nil
+}
+
+func toGateFill(t gateTrade) (Fill, error) {
+	quote := gateQuote(t.CurrencyPair)
+	n, err := amounts(PlatformGate, map[string]decimal{"amount": {t.Amount, baseOf(t.CurrencyPair)},
+		"price": {t.Price, quote}, "fee": {t.Fee, t.FeeCurrency}})
+	if err != nil {
+		return Fill{}, err
+	}
+	createdAt, err := parseNumber(t.CreateTimeMs)
+	if err != nil {
+		return Fill{}, fmt.Errorf("gate: create_time_ms %q is not a number", t.CreateTimeMs)
+	}
+	return Fill{
+		TradeID:       t.CurrencyPair + ":" + t.ID,
+		Symbol:        t.CurrencyPair,
+		Side:          strings.ToLower(t.Side),
+		Price:         n["price"],
+		Quantity:      n["amount"],
+		Notional:      n["amount"].Convert(n["price"], money.HalfEven),
+		QuoteCurrency: quote,
+		Fee:           n["fee"],
+		FeeCurrency:   t.FeeCurrency,
+		Maker:         t.Role == "maker",
+		ExecutedAt:    time.UnixMilli(int64(math.Floor(createdAt))).UTC(),
+	}, nil
+}
+
+// gateQuote returns the quote currency of a spot pair such as SOL_USDT or ETH_BTC
+func gateQuote(pair string) string {
+	if i := strings.LastIndex(pair, "_"); i >= 0 {
+		return strings.ToUpper(pair[i+1:])
+	}
+	return quoteOf(pair)
+}
+
+func (a *Gate) FeeSchedule(ctx context.Context, creds Credentials, symbol string) (FeeSchedule, error) {
+	params := url.Values{}
+	if symbol != "" {
+		params.Set("currency_pair", symbol)
+	}
+	var fee gateFee
+	if err := a.get(ctx, creds, "/api/v4/wallet/fee", params, &fee); err != nil {
+		return FeeSchedule{}, err
+	}
+	n, err := numbers(PlatformGate, map[string]string{"maker_fee": fee.MakerFee, "taker_fee": fee.TakerFee})
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	return FeeSchedule{Maker: n["maker_fee"], Taker: n["taker_fee"]}, nil
+}
+
+// BindAccount reads the user ID Gate returns with the account's fee rates
+func (a *Gate) BindAccount(ctx context.Context, creds Credentials) (Account, error) {
+	if creds.APIKey == "" || creds.APISecret == "" {
+		return Account{}, fmt.Errorf("gate: API key and secret are required: %w", ErrUnauthorized)
+	}
+	var fee gateFee
+	if err := a.get(ctx, creds, "/api/v4/wallet/fee", url.Values{}, &fee); err != nil {
+		return Account{}, err
+	}
+	if fee.UserID == 0 {
+		return Account{}, fmt.Errorf("gate: fee response has no user_id")
+	}
+	return Account{ID: strconv.FormatInt(fee.UserID, 10)}, nil
+}
+
+// get calls a signed GET endpoint. The signature covers method, path, query, the SHA-512 of the empty
+// body and the timestamp.
+func (a *Gate) get(ctx context.Context, creds Credentials, path string, params url.Values, out any) error {
+	queryString := params.Encode()
+	target := a.config.BaseURL + path
+	if queryString != "" {
+		target += "?" + queryString
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(a.config.now().Unix(), 10)
+	bodyHash := sha512.Sum512(nil)
+	mac := hmac.New(sha512.New, []byte(creds.APISecret))
+	mac.Write([]byte(strings.Join([]string{http.MethodGet, path, queryString, hex.EncodeToString(bodyHash[:]),
+		timestamp}, "\n")))
+	req.Header.Set("KEY", creds.APIKey)
+	req.Header.Set("Timestamp", timestamp)
+	req.Header.Set("SIGN", hex.EncodeToString(mac.Sum(nil)))
+
+	return doJSON(a.config.HTTPClient, req, PlatformGate, out, decodeGateError)
+}
+
+func decodeGateError(body []byte) (string, string) {
+	var e gateError
+	if json.Unmarshal(body, &e) != nil || e.Message == "" {
+		return "", ""
+	}
+	return e.Label, e.Message
+}
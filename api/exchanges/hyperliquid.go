@@ -0,0 +1,168 @@
+package exchanges
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// ==========================================
+// HYPERLIQUID
+// ==========================================
+
+const (
+	hyperliquidBaseURL = "https://api.hyperliquid.xyz"
+	// hyperliquidFillsLimit is the most fills userFillsByTime returns per request
+	hyperliquidFillsLimit = 2000
+	// hyperliquidQuote is the currency Hyperliquid perpetuals are margined and settled in
+	hyperliquidQuote = "USDC"
+)
+
+// hyperliquidAddress matches the EVM address a Hyperliquid account is identified by
+var hyperliquidAddress = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// Hyperliquid reads fills through the public info endpoint, which takes the account's wallet address
+// and no API key. Binding therefore proves only that the address is well formed, not that the user
+// controls it; an address can be bound to one user only.
+type Hyperliquid struct {
+	config Config
+}
+
+// NewHyperliquid creates the Hyperliquid adapter
+func NewHyperliquid(config Config) *Hyperliquid {
+	return &Hyperliquid{config: config.withDefaults(hyperliquidBaseURL)}
+}
+
+func (a *Hyperliquid) Platform() string { return PlatformHyperliquid }
+
+// hyperliquidFill is an entry of the userFillsByTime info response
+type hyperliquidFill struct {
+	Coin     string `json:"coin"`
+	Px       string `json:"px"`
+	Sz       string `json:"sz"`
+	Side     string `json:"side"` // B bid (buy) or A ask (sell)
+	Time     int64  `json:"time"`
+	Crossed  bool   `json:"crossed"` // true when the fill took liquidity
+	Fee      string `json:"fee"`
+	FeeToken string `json:"feeToken"`
+	Tid      int64  `json:"tid"`
+}
+
+// hyperliquidFees is the part of the userFees info response the adapter reads
+type hyperliquidFees struct {
+	UserCrossRate string `json:"userCrossRate"` // taker
+	UserAddRate   string `json:"userAddRate"`   // maker
+}
+
+// TradeHistory reads fills from the cursor's Unix milliseconds on; fills of the newest millisecond are
+// read again by the next page or sync and come back as duplicates
+func (a *Hyperliquid) TradeHistory(ctx context.Context, creds Credentials, query TradeQuery) (TradePage, error) {
+	start := query.Since.UnixMilli()
+	if query.Cursor != "" {
+		var err error
+		if start, err = strconv.ParseInt(query.Cursor, 10, 64); err != nil {
+			return TradePage{}, fmt.Errorf("hyperliquid: malformed cursor %q", query.Cursor)
+		}
+	}
+	var fills []hyperliquidFill
+	request := map[string]any{"type": "userFillsByTime", "user": strings.ToLower(creds.Wallet), "startTime": start}
+	if err := a.info(ctx, request, &fills); err != nil {
+		return TradePage{}, err
+	}
+
+	page := TradePage{Fills: make([]Fill, 0, len(fills))}
+	newest := start
+	for _, f := range fills {
+		fill, err := toHyperliquidFill(f)
+		if err != nil {
+			return TradePage{}, err
+		}
+		if f.Time > newest {
+			newest = f.Time
+		}
+		page.Fills = append(page.Fills, fill)
+	}
+	if len(fills) >= hyperliquidFillsLimit {
+		page.More = true
+		if newest == start {
+			// A full page within one millisecond; skip past it rather than read it forever
+			newest++
+		}
+	}
+	page.Cursor = strconv.FormatInt(newest, 10)
+	return page, nil
+}
+
+func toHyperliquidFill(f hyperliquidFill) (Fill, error) {
+	n, err := amounts(PlatformHyperliquid, map[string]decimal{"px": {f.Px, hyperliquidQuote}, "sz": {f.Sz, f.Coin},
+		"fee": {f.Fee, f.FeeToken}})
+	if err != nil {
+		return Fill{}, err
+	}
+	side := "buy"
+	if f.Side == "A" {
+		side = "sell"
+	}
+	return Fill{
+		TradeID:       strconv.FormatInt(f.Tid, 10),
+		Symbol:        f.Coin,
+		Side:          side,
+		Price:         n["px"],
+		Quantity:      n["sz"],
+		Notional:      n["sz"].Convert(n["px"], money.HalfEven),
+		QuoteCurrency: hyperliquidQuote,
+		Fee:           n["fee"],
+		FeeCurrency:   f.FeeToken,
+		Maker:         !f.Crossed,
+		ExecutedAt:    time.UnixMilli(f.Time).UTC(),
+	}, nil
+}
+
+// FeeSchedule returns the account's rates; Hyperliquid has one schedule for every perpetual
+func (a *Hyperliquid) FeeSchedule(ctx context.Context, creds Credentials, symbol string) (FeeSchedule, error) {
+	var fees hyperliquidFees
+	request := map[string]any{"type": "userFees", "user": strings.ToLower(creds.Wallet)}
+	if err := a.info(ctx, request, &fees); err != nil {
+		return FeeSchedule{}, err
+	}
+	n, err := numbers(PlatformHyperliquid, map[string]string{"userAddRate": fees.UserAddRate,
+		"userCrossRate": fees.UserCrossRate})
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	return FeeSchedule{Maker: n["userAddRate"], Taker: n["userCrossRate"]}, nil
+}
+
+// BindAccount returns the wallet address as the account; see Hyperliquid for what binding proves
+func (a *Hyperliquid) BindAccount(ctx context.Context, creds Credentials) (Account, error) {
+	if !hyperliquidAddress.MatchString(creds.Wallet) {
+		return Account{}, fmt.Errorf("hyperliquid: wallet must be a 0x-prefixed 40 hex digit address: %w",
+			ErrUnauthorized)
+	}
+	return Account{ID: strings.ToLower(creds.Wallet)}, nil
+}
+
+// info posts a request to the info endpoint
+func (a *Hyperliquid) info(ctx context.Context, request map[string]any, out any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.BaseURL+"/info", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(a.config.HTTPClient, req, PlatformHyperliquid, out, func(body []byte) (string, string) {
+		// Errors come back as plain text
+		return "", strings.TrimSpace(string(body))
+	})
+}
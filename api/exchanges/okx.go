@@ -0,0 +1,303 @@
+package exchanges
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// ==========================================
+// OKX
+// ==========================================
+
+const (
+	okxBaseURL = "https://www.okx.com"
+	// okxFillsLimit is the most fills fills-history returns per request
+	okxFillsLimit = 100
+	// okxInstType limits the history to perpetual swaps, the derivatives AIW3 users trade
+	okxInstType = "SWAP"
+)
+
+// OKX reads perpetual swap fills through the OKX v5 API. Fills are sized in contracts and converted
+// with the instrument's contract value; fee rates are negative when charged.
+type OKX struct {
+	config Config
+
+	mu          sync.Mutex
+	instruments map[string]okxInstrument
+}
+
+// NewOKX creates the OKX adapter
+func NewOKX(config Config) *OKX {
+	return &OKX{config: config.withDefaults(okxBaseURL), instruments: map[string]okxInstrument{}}
+}
+
+func (a *OKX) Platform() string { return PlatformOKX }
+
+// okxAuthCodes are the error codes of a missing, invalid or expired API key, signature or passphrase
+var okxAuthCodes = map[string]bool{"50100": true, "50105": true, "50111": true, "50113": true, "50119": true}
+
+// okxResponse is the envelope of every OKX v5 response; code "0" is success
+type okxResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// okxFill is an entry of GET /api/v5/trade/fills-history
+type okxFill struct {
+	InstID   string `json:"instId"`
+	TradeID  string `json:"tradeId"`
+	BillID   string `json:"billId"`
+	FillPx   string `json:"fillPx"`
+	FillSz   string `json:"fillSz"`
+	Side     string `json:"side"`
+	ExecType string `json:"execType"` // T taker, M maker
+	Fee      string `json:"fee"`      // negative when charged
+	FeeCcy   string `json:"feeCcy"`
+	Ts       string `json:"ts"`
+}
+
+// okxInstrument is an entry of GET /api/v5/public/instruments
+type okxInstrument struct {
+	InstID    string `json:"instId"`
+	CtVal     string `json:"ctVal"`
+	SettleCcy string `json:"settleCcy"`
+}
+
+// okxTradeFee is the entry of GET /api/v5/account/trade-fee
+type okxTradeFee struct {
+	Level     string `json:"level"`
+	MakerU    string `json:"makerU"` // USDT-margined contracts
+	TakerU    string `json:"takerU"`
+	MakerUSDC string `json:"makerUSDC"` // USDC-margined contracts
+	TakerUSDC string `json:"takerUSDC"`
+}
+
+// okxAccountConfig is the entry of GET /api/v5/account/config
+type okxAccountConfig struct {
+	UID string `json:"uid"`
+}
+
+// okxCursor is "begin" between syncs and "begin:billId:newest" while paging back from the newest
+// fill, since fills-history returns the newest fills first
+type okxCursor struct {
+	begin  int64 // Unix milliseconds the history starts at
+	after  string
+	newest int64
+}
+
+func parseOKXCursor(cursor string) (okxCursor, error) {
+	parts := strings.Split(cursor, ":")
+	var c okxCursor
+	var err error
+	if c.begin, err = strconv.ParseInt(parts[0], 10, 64); err != nil || (len(parts) != 1 && len(parts) != 3) {
+		return c, fmt.Errorf("okx: malformed cursor %q", cursor)
+	}
+	if len(parts) == 3 {
+		c.after = parts[1]
+		if c.newest, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+			return c, fmt.Errorf("okx: malformed cursor %q", cursor)
+		}
+	}
+	return c, nil
+}
+
+func (a *OKX) TradeHistory(ctx context.Context, creds Credentials, query TradeQuery) (TradePage, error) {
+	cursor := okxCursor{begin: query.Since.UnixMilli()}
+	if query.Cursor != "" {
+		var err error
+		if cursor, err = parseOKXCursor(query.Cursor); err != nil {
+			return TradePage{}, err
+		}
+	}
+	limit := query.Limit
+	if limit <= 0 || limit > okxFillsLimit {
+		limit = okxFillsLimit
+	}
+
+	params := url.Values{"instType": {okxInstType}, "begin": {strconv.FormatInt(cursor.begin, 10)},
+		"limit": {strconv.Itoa(limit)}}
+	if cursor.after != "" {
+		params.Set("after", cursor.after)
+	}
+	var fills []okxFill
+	if err := a.get(ctx, creds, "/api/v5/trade/fills-history", params, &fills); err != nil {
+		return TradePage{}, err
+	}
+
+	page := TradePage{Fills: make([]Fill, 0, len(fills))}
+	for _, f := range fills {
+		fill, err := a.toFill(ctx, f)
+		if err != nil {
+			return TradePage{}, err
+		}
+		if ms := fill.ExecutedAt.UnixMilli(); ms > cursor.newest {
+			cursor.newest = ms
+		}
+		page.Fills = append(page.Fills, fill)
+	}
+
+	if len(fills) == limit {
+		page.More = true
+		page.Cursor = fmt.Sprintf("%d:%s:%d", cursor.begin, fills[len(fills)-1].BillID, cursor.newest)
+		return page, nil
+	}
+	// The next sync starts at the newest fill; fills of that millisecond come again as duplicates
+	next := cursor.begin
+	if cursor.newest > next {
+		next = cursor.newest
+	}
+	page.Cursor = strconv.FormatInt(next, 10)
+	return page, nil
+}
+
+func (a *OKX) toFill(ctx context.Context, f okxFill) (Fill, error) {
+	instrument, err := a.instrument(ctx, f.InstID)
+	if err != nil {
+		return Fill{}, err
+	}
+	base := baseOf(f.InstID)
+	n, err := amounts(PlatformOKX, map[string]decimal{"fillPx": {f.FillPx, instrument.SettleCcy},
+		"fillSz": {f.FillSz, base}, "ctVal": {instrument.CtVal, base}, "fee": {f.Fee, f.FeeCcy}})
+	if err != nil {
+		return Fill{}, err
+	}
+	executedAt, err := millis(f.Ts)
+	if err != nil {
+		return Fill{}, fmt.Errorf("okx: ts %q is not Unix milliseconds", f.Ts)
+	}
+	// fillSz counts contracts of ctVal base asset each
+	quantity := n["fillSz"].Convert(n["ctVal"], money.HalfEven)
+	return Fill{
+		TradeID:       f.InstID + ":" + f.TradeID,
+		Symbol:        f.InstID,
+		Side:          strings.ToLower(f.Side),
+		Price:         n["fillPx"],
+		Quantity:      quantity,
+		Notional:      quantity.Convert(n["fillPx"], money.HalfEven),
+		QuoteCurrency: instrument.SettleCcy,
+		Fee:           n["fee"].Neg(),
+		FeeCurrency:   f.FeeCcy,
+		Maker:         f.ExecType == "M",
+		ExecutedAt:    executedAt,
+	}, nil
+}
+
+// instrument returns a swap's contract value, read once per instrument
+func (a *OKX) instrument(ctx context.Context, instID string) (okxInstrument, error) {
+	a.mu.Lock()
+	instrument, ok := a.instruments[instID]
+	a.mu.Unlock()
+	if ok {
+		return instrument, nil
+	}
+
+	var instruments []okxInstrument
+	params := url.Values{"instType": {okxInstType}, "instId": {instID}}
+	if err := a.get(ctx, Credentials{}, "/api/v5/public/instruments", params, &instruments); err != nil {
+		return instrument, err
+	}
+	if len(instruments) == 0 {
+		return instrument, fmt.Errorf("okx: unknown instrument %q", instID)
+	}
+	instrument = instruments[0]
+	a.mu.Lock()
+	a.instruments[instID] = instrument
+	a.mu.Unlock()
+	return instrument, nil
+}
+
+func (a *OKX) FeeSchedule(ctx context.Context, creds Credentials, symbol string) (FeeSchedule, error) {
+	params := url.Values{"instType": {okxInstType}}
+	// Swap fee rates are per instrument family, BTC-USDT for BTC-USDT-SWAP
+	family := strings.TrimSuffix(symbol, "-"+okxInstType)
+	if family != "" {
+		params.Set("instFamily", family)
+	}
+	var fees []okxTradeFee
+	if err := a.get(ctx, creds, "/api/v5/account/trade-fee", params, &fees); err != nil {
+		return FeeSchedule{}, err
+	}
+	if len(fees) == 0 {
+		return FeeSchedule{}, fmt.Errorf("okx: no fee rates for %q", symbol)
+	}
+	fee := fees[0]
+	maker, taker := fee.MakerU, fee.TakerU
+	if quoteOf(symbol) == "USDC" {
+		maker, taker = fee.MakerUSDC, fee.TakerUSDC
+	}
+	n, err := numbers(PlatformOKX, map[string]string{"maker": maker, "taker": taker})
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+	// OKX reports charged rates as negative numbers
+	return FeeSchedule{Maker: -n["maker"], Taker: -n["taker"], Tier: fee.Level}, nil
+}
+
+func (a *OKX) BindAccount(ctx context.Context, creds Credentials) (Account, error) {
+	if creds.APIKey == "" || creds.APISecret == "" || creds.Passphrase == "" {
+		return Account{}, fmt.Errorf("okx: API key, secret and passphrase are required: %w", ErrUnauthorized)
+	}
+	var configs []okxAccountConfig
+	if err := a.get(ctx, creds, "/api/v5/account/config", nil, &configs); err != nil {
+		return Account{}, err
+	}
+	if len(configs) == 0 || configs[0].UID == "" {
+		return Account{}, fmt.Errorf("okx: account config has no uid")
+	}
+	return Account{ID: configs[0].UID}, nil
+}
+
+// get calls a GET endpoint and decodes the envelope's data into out; credentials sign the request
+// when they hold an API key
+func (a *OKX) get(ctx context.Context, creds Credentials, path string, params url.Values, out any) error {
+	requestPath := path
+	if len(params) > 0 {
+		requestPath += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.config.BaseURL+requestPath, nil)
+	if err != nil {
+		return err
+	}
+	if creds.APIKey != "" {
+		timestamp := a.config.now().UTC().Format("2006-01-02T15:04:05.000Z")
+		mac := hmac.New(sha256.New, []byte(creds.APISecret))
+		mac.Write([]byte(timestamp + http.MethodGet + requestPath))
+		req.Header.Set("OK-ACCESS-KEY", creds.APIKey)
+		req.Header.Set("OK-ACCESS-SIGN", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+		req.Header.Set("OK-ACCESS-PASSPHRASE", creds.Passphrase)
+	}
+
+	var envelope okxResponse
+	if err := doJSON(a.config.HTTPClient, req, PlatformOKX, &envelope, decodeOKXError); err != nil {
+		return err
+	}
+	if envelope.Code != "0" {
+		return &APIError{Platform: PlatformOKX, StatusCode: http.StatusOK, Code: envelope.Code, Message: envelope.Msg,
+			Unauthorized: okxAuthCodes[envelope.Code]}
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("okx: decode data: %w", err)
+	}
+	return nil
+}
+
+func decodeOKXError(body []byte) (string, string) {
+	var envelope okxResponse
+	if json.Unmarshal(body, &envelope) != nil {
+		return "", ""
+	}
+	return envelope.Code, envelope.Msg
+}
@@ -0,0 +1,182 @@
+package exchanges
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// ACCOUNT SYNC
+// ==========================================
+
+const (
+	// maxPagesPerSync bounds the pages one sync reads from an account; the rest follow next sync
+	maxPagesPerSync = 20
+	// maxSyncErrorLength fits the exchangeaccount table
+	maxSyncErrorLength = 500
+	// syncLease keeps two instances from syncing the same accounts at once
+	syncLease = "exchanges:sync"
+)
+
+// SyncedFill is a fill with the rate its account's fee schedule charges for it
+type SyncedFill struct {
+	Fill
+	FeeRate float64
+}
+
+// Sink records the fills synced from an account
+type Sink interface {
+	Record(ctx context.Context, account repository.ExchangeAccount, fills []SyncedFill) error
+}
+
+// SyncReport sums up a sync of every bound account
+type SyncReport struct {
+	Accounts int
+	Fills    int
+	Failed   int
+}
+
+// Syncer pulls the new fills of bound accounts into a sink
+type Syncer struct {
+	store    *repository.Store
+	adapters *Registry
+	sealer   *Sealer
+	sink     Sink
+	now      func() time.Time
+}
+
+// NewSyncer creates a syncer; sealer opens the accounts' stored credentials
+func NewSyncer(store *repository.Store, adapters *Registry, sealer *Sealer, sink Sink) *Syncer {
+	return &Syncer{store: store, adapters: adapters, sealer: sealer, sink: sink, now: time.Now}
+}
+
+// SyncAccount reads the account's fills from its cursor on, the bind time at first, and records them
+// page by page. The cursor advances past recorded pages even when a later page fails; the error is
+// saved on the account and returned. It returns how many fills were recorded.
+func (s *Syncer) SyncAccount(ctx context.Context, account *repository.ExchangeAccount) (int, error) {
+	synced, err := s.syncAccount(ctx, account)
+
+	now := s.now().UTC()
+	account.SyncError = ""
+	if err != nil {
+		account.SyncError = err.Error()
+		if len(account.SyncError) > maxSyncErrorLength {
+			account.SyncError = account.SyncError[:maxSyncErrorLength]
+		}
+	} else {
+		account.SyncedAt = &now
+	}
+	account.UpdatedAt = now
+	if updateErr := s.store.Exchanges.UpdateSync(ctx, account); updateErr != nil {
+		return synced, errors.Join(err, updateErr)
+	}
+	return synced, err
+}
+
+func (s *Syncer) syncAccount(ctx context.Context, account *repository.ExchangeAccount) (int, error) {
+	adapter, ok := s.adapters.Get(account.Platform)
+	if !ok {
+		return 0, fmt.Errorf("no adapter for platform %q", account.Platform)
+	}
+	creds, err := s.sealer.Open(account.Credentials)
+	if err != nil {
+		return 0, err
+	}
+
+	schedules := map[string]FeeSchedule{}
+	synced := 0
+	for pages := 0; pages < maxPagesPerSync; pages++ {
+		page, err := adapter.TradeHistory(ctx, creds, TradeQuery{Since: account.CreatedAt, Cursor: account.Cursor})
+		if err != nil {
+			return synced, err
+		}
+		fills := make([]SyncedFill, 0, len(page.Fills))
+		for _, fill := range page.Fills {
+			schedule, ok := schedules[fill.Symbol]
+			if !ok {
+				if schedule, err = adapter.FeeSchedule(ctx, creds, fill.Symbol); err != nil {
+					return synced, err
+				}
+				schedules[fill.Symbol] = schedule
+			}
+			fills = append(fills, SyncedFill{Fill: fill, FeeRate: schedule.Rate(fill.Maker)})
+		}
+		if len(fills) > 0 {
+			if err := s.sink.Record(ctx, *account, fills); err != nil {
+				return synced, err
+			}
+		}
+		synced += len(fills)
+		account.Cursor = page.Cursor
+		if !page.More {
+			break
+		}
+	}
+	return synced, nil
+}
+
+// SyncAll syncs every bound account. An account that fails is counted and logged; the others still sync.
+func (s *Syncer) SyncAll(ctx context.Context) (SyncReport, error) {
+	var report SyncReport
+	accounts, err := s.store.Exchanges.List(ctx)
+	if err != nil {
+		return report, err
+	}
+	for i := range accounts {
+		account := &accounts[i]
+		report.Accounts++
+		synced, err := s.SyncAccount(ctx, account)
+		report.Fills += synced
+		if err != nil {
+			report.Failed++
+			log.Printf("exchange sync: %s account %s of user %d: %v", account.Platform, account.AccountID,
+				account.UserID, err)
+		}
+	}
+	return report, nil
+}
+
+// Run syncs every bound account each interval until ctx ends. Instances sharing a store take a lease
+// first, so only one of them syncs each round.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		log.Printf("exchange sync: lease owner: %v", err)
+		return
+	}
+	owner := hex.EncodeToString(buf)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		acquired, err := s.store.Leases.Acquire(ctx, syncLease, owner, interval)
+		if err != nil {
+			log.Printf("exchange sync: take lease: %v", err)
+			continue
+		}
+		if !acquired {
+			continue
+		}
+		report, err := s.SyncAll(ctx)
+		if err != nil {
+			log.Printf("exchange sync: %v", err)
+			continue
+		}
+		if report.Accounts > 0 {
+			log.Printf("exchange sync: %d accounts, %d fills, %d failed", report.Accounts, report.Fills, report.Failed)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// IDEMPOTENCY-KEY MIDDLEWARE
+// ==========================================
+
+const (
+	// HeaderKey carries the client-chosen key identifying one logical request across retries
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set to "true" on responses replayed from the store
+	HeaderReplayed = "Idempotent-Replayed"
+	// DefaultTTL is how long a response is replayed for its key
+	DefaultTTL = 24 * time.Hour
+	// maxKeyLength bounds the key so it fits the idempotencykey table
+	maxKeyLength = 255
+)
+
+// envelope is the {code, message, data} response written when the middleware rejects a request
+type envelope struct {
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	Data    struct{} `json:"data"`
+}
+
+// Middleware makes a route safe to retry. The first response to a request sent with an Idempotency-Key
+// is stored by caller and key for ttl and replayed to every retry with the same key, so a retried claim,
+// upgrade or award never runs twice. Reusing a key for a different request, or retrying while the first
+// request is still running, is rejected with a 409 envelope. Requests without the header pass through.
+// Failures a retry may get past are not stored, so the request can be retried with the same key: see
+// retryable.
+func Middleware(records repository.IdempotencyRepository, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				writeEnvelope(w, 400, fmt.Sprintf("%s must be at most %d characters", HeaderKey, maxKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeEnvelope(w, 400, "Failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			record := &repository.IdempotencyRecord{
+				Scope:       hash([]byte(r.Header.Get("Authorization"))),
+				Key:         key,
+				RequestHash: hash([]byte(r.Method), []byte(r.URL.Path), body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(ttl),
+			}
+			err = records.Reserve(r.Context(), record)
+			if errors.Is(err, repository.ErrConflict) {
+				replay(w, r, records, record)
+				return
+			}
+			if err != nil {
+				log.Printf("idempotency: reserve key: %v", err)
+				writeEnvelope(w, 500, "Failed to record the Idempotency-Key")
+				return
+			}
+
+			// Persist the outcome even if the client hangs up, so its retry is replayed
+			ctx := context.WithoutCancel(r.Context())
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			stored := false
+			defer func() {
+				if !stored {
+					if err := records.Delete(ctx, record.Scope, record.Key); err != nil {
+						log.Printf("idempotency: release key: %v", err)
+					}
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if retryable(recorder.status, recorder.body.Bytes()) {
+				return
+			}
+			record.StatusCode = recorder.status
+			record.ContentType = recorder.Header().Get("Content-Type")
+			record.Body = recorder.body.Bytes()
+			if err := records.Complete(ctx, record); err != nil {
+				log.Printf("idempotency: store response: %v", err)
+				return
+			}
+			stored = true
+		})
+	}
+}
+
+// retryable reports whether a response is a failure a retry may get past, so it must not be replayed.
+// Handlers answer HTTP 200 with the outcome in the envelope code, so both are checked: server errors,
+// conflicts (e.g. an upgrade still in progress), refused credentials and rate limits are retryable.
+func retryable(status int, body []byte) bool {
+	code := status
+	var response struct {
+		Code int `json:"code"`
+	}
+	if status < http.StatusInternalServerError && json.Unmarshal(body, &response) == nil && response.Code != 0 {
+		code = response.Code
+	}
+	switch {
+	case code >= http.StatusInternalServerError:
+		return true
+	case code == http.StatusConflict, code == http.StatusUnauthorized, code == http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+// replay answers a request whose key is already recorded
+func replay(w http.ResponseWriter, r *http.Request, records repository.IdempotencyRepository,
+	request *repository.IdempotencyRecord) {
+	existing, err := records.Get(r.Context(), request.Scope, request.Key)
+	if errors.Is(err, repository.ErrNotFound) {
+		// The first request failed and released the key in the meantime
+		writeEnvelope(w, 409, "A request with this Idempotency-Key did not complete; retry it")
+		return
+	}
+	if err != nil {
+		log.Printf("idempotency: load key: %v", err)
+		writeEnvelope(w, 500, "Failed to load the Idempotency-Key")
+		return
+	}
+
+	switch {
+	case existing.RequestHash != request.RequestHash:
+		writeEnvelope(w, 409, "This Idempotency-Key was already used for a different request")
+	case existing.StatusCode == 0:
+		writeEnvelope(w, 409, "A request with this Idempotency-Key is still being processed")
+	default:
+		if existing.ContentType != "" {
+			w.Header().Set("Content-Type", existing.ContentType)
+		}
+		w.Header().Set(HeaderReplayed, "true")
+		w.WriteHeader(existing.StatusCode)
+		w.Write(existing.Body)
+	}
+}
+
+// ==========================================
+// IDEMPOTENCY FOR OTHER TRANSPORTS
+// ==========================================
+
+// Errors returned by Do when a key cannot be used; the middleware answers the same cases with a 409 envelope
+var (
+	ErrKeyTooLong = fmt.Errorf("%s must be at most %d characters", HeaderKey, maxKeyLength)
+	ErrKeyReused  = fmt.Errorf("this %s was already used for a different request", HeaderKey)
+	ErrInProgress = fmt.Errorf("a request with this %s is still being processed", HeaderKey)
+	ErrIncomplete = fmt.Errorf("a request with this %s did not complete; retry it", HeaderKey)
+)
+
+// Do is the Middleware for transports other than HTTP, such as gRPC. It runs fn at most once per caller
+// credentials and key: the first successful response is stored for ttl and returned, with replayed set,
+// to every retry of the same request. When fn fails the key is released so the request can be retried.
+func Do(ctx context.Context, records repository.IdempotencyRepository, ttl time.Duration, credentials, key string,
+	request []byte, fn func() ([]byte, error)) (response []byte, replayed bool, err error) {
+	if len(key) > maxKeyLength {
+		return nil, false, ErrKeyTooLong
+	}
+
+	now := time.Now()
+	record := &repository.IdempotencyRecord{
+		Scope:       hash([]byte(credentials)),
+		Key:         key,
+		RequestHash: hash(request),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	err = records.Reserve(ctx, record)
+	if errors.Is(err, repository.ErrConflict) {
+		existing, err := records.Get(ctx, record.Scope, record.Key)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, false, ErrIncomplete
+		case err != nil:
+			return nil, false, fmt.Errorf("load key: %w", err)
+		case existing.RequestHash != record.RequestHash:
+			return nil, false, ErrKeyReused
+		case existing.StatusCode == 0:
+			return nil, false, ErrInProgress
+		}
+		return existing.Body, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reserve key: %w", err)
+	}
+
+	// Persist the outcome even if the caller goes away, so its retry is replayed
+	ctx = context.WithoutCancel(ctx)
+	stored := false
+	defer func() {
+		if !stored {
+			if err := records.Delete(ctx, record.Scope, record.Key); err != nil {
+				log.Printf("idempotency: release key: %v", err)
+			}
+		}
+	}()
+
+	response, err = fn()
+	if err != nil {
+		return nil, false, err
+	}
+	record.StatusCode = http.StatusOK
+	record.Body = response
+	if err := records.Complete(ctx, record); err != nil {
+		log.Printf("idempotency: store response: %v", err)
+		return response, false, nil
+	}
+	stored = true
+	return response, false, nil
+}
+
+// RunSweeper deletes expired keys every interval until ctx ends
+func RunSweeper(ctx context.Context, records repository.IdempotencyRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := records.DeleteExpired(ctx, time.Now()); err != nil {
+				log.Printf("idempotency: delete expired keys: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// responseRecorder passes the response through while keeping a copy of its status and body
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func writeEnvelope(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(envelope{Code: code, Message: message})
+}
+
+// hash returns the hex SHA-256 of the parts, each prefixed with its length so boundaries cannot shift
+func hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
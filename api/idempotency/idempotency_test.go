@@ -0,0 +1,122 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository/memory"
+)
+
+// envelopes answers each request with the next envelope code, counting the requests it served
+type envelopes struct {
+	codes  []int
+	served int
+}
+
+func (e *envelopes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	code := e.codes[e.served]
+	e.served++
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"code":%d,"message":"attempt %d","data":{}}`, code, e.served)
+}
+
+func post(t *testing.T, handler http.Handler, key string) (int, bool) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/user/nft/upgrade", strings.NewReader(`{"targetLevel":2}`))
+	req.Header.Set("Authorization", "Bearer test_token_123")
+	req.Header.Set(HeaderKey, key)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var response envelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return response.Code, rec.Header().Get(HeaderReplayed) == "true"
+}
+
+func TestMiddlewareRetriesEnvelopeFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		firstCode  int
+		retryCode  int
+		retryRuns  bool // whether the retry reaches the handler rather than being replayed
+		wantStatus int
+	}{
+		{"internal error resumes", 500, 200, true, 200},
+		{"unavailable resumes", 503, 200, true, 200},
+		{"conflict resumes", 409, 200, true, 200},
+		{"unauthorized resumes", 401, 200, true, 200},
+		{"rate limited resumes", 429, 200, true, 200},
+		{"rejection is replayed", 400, 200, false, 400},
+		{"forbidden is replayed", 403, 200, false, 403},
+		{"success is replayed", 200, 500, false, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			next := &envelopes{codes: []int{tt.firstCode, tt.retryCode}}
+			handler := Middleware(store.Idempotency, time.Hour)(next)
+
+			if code, replayed := post(t, handler, "key-1"); code != tt.firstCode || replayed {
+				t.Fatalf("first attempt: code %d replayed %v, want %d not replayed", code, replayed, tt.firstCode)
+			}
+			code, replayed := post(t, handler, "key-1")
+			if code != tt.wantStatus {
+				t.Errorf("retry: code %d, want %d", code, tt.wantStatus)
+			}
+			if replayed == tt.retryRuns {
+				t.Errorf("retry: replayed %v, want %v", replayed, !tt.retryRuns)
+			}
+			wantServed := 1
+			if tt.retryRuns {
+				wantServed = 2
+			}
+			if next.served != wantServed {
+				t.Errorf("handler ran %d times, want %d", next.served, wantServed)
+			}
+		})
+	}
+}
+
+func TestMiddlewareReplaysRetriedSuccess(t *testing.T) {
+	store := memory.NewStore()
+	next := &envelopes{codes: []int{500, 200}}
+	handler := Middleware(store.Idempotency, time.Hour)(next)
+
+	post(t, handler, "key-1")
+	post(t, handler, "key-1")
+	code, replayed := post(t, handler, "key-1")
+	if code != 200 || !replayed {
+		t.Errorf("third attempt: code %d replayed %v, want the stored 200 replayed", code, replayed)
+	}
+	if next.served != 2 {
+		t.Errorf("handler ran %d times, want 2", next.served)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{200, `{"code":200}`, false},
+		{200, `{"code":500}`, true},
+		{200, `{"code":409}`, true},
+		{200, `{"code":404}`, false},
+		{200, `not json`, false},
+		{502, `{"code":200}`, true},
+		{429, ``, true},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.status, []byte(tt.body)); got != tt.want {
+			t.Errorf("retryable(%d, %q) = %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
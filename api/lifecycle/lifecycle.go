@@ -0,0 +1,270 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
+)
+
+// ==========================================
+// TIERED NFT STATES
+// ==========================================
+
+// Status is the lifecycle state of a tiered NFT level for a user
+type Status string
+
+const (
+	StatusLocked     Status = "Locked"     // requirements not met, nothing minted
+	StatusUnlockable Status = "Unlockable" // requirements met, not minted yet
+	StatusActive     Status = "Active"     // minted and held by the user
+	StatusBurned     Status = "Burned"     // minted, then burned to upgrade to the next level
+)
+
+// StatusOf returns the lifecycle state of a stored NFT (stored NFTs are always Active or Burned)
+func StatusOf(nft *repository.TieredNft) Status {
+	if nft.Status == repository.NftStatusBurned {
+		return StatusBurned
+	}
+	return StatusActive
+}
+
+// ==========================================
+// STATE MACHINE
+// ==========================================
+
+// Machine owns the allowed transitions of one user's tiered NFTs.
+// It enforces the Sequential Progression Rule: every user starts at Level 1 whatever their
+// trading volume, each higher level is minted only after the level below it was burned,
+// at most one NFT is Active at a time and the highest level can never be burned.
+type Machine struct {
+	UserID  int
+	catalog *tiers.TierCatalog
+	nfts    []repository.TieredNft
+	now     func() time.Time
+}
+
+// New creates a machine over the user's stored NFTs using the current tier catalog
+func New(userID int, nfts []repository.TieredNft) *Machine {
+	return &Machine{
+		UserID:  userID,
+		catalog: tiers.Current(),
+		nfts:    append([]repository.TieredNft(nil), nfts...),
+		now:     time.Now,
+	}
+}
+
+// Load creates a machine from the user's NFTs in the store
+func Load(ctx context.Context, store *repository.Store, userID int) (*Machine, error) {
+	nfts, err := store.TieredNfts.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return New(userID, nfts), nil
+}
+
+// Active returns the user's Active NFT, nil if there is none
+func (m *Machine) Active() *repository.TieredNft {
+	for i := range m.nfts {
+		if m.nfts[i].Status == repository.NftStatusActive {
+			return &m.nfts[i]
+		}
+	}
+	return nil
+}
+
+// ActiveLevel returns the level of the Active NFT, 0 if there is none
+func (m *Machine) ActiveLevel() int {
+	if active := m.Active(); active != nil {
+		return active.Level
+	}
+	return 0
+}
+
+// HighestLevel returns the highest level ever minted by the user, 0 if none
+func (m *Machine) HighestLevel() int {
+	highest := 0
+	for _, nft := range m.nfts {
+		if nft.Level > highest {
+			highest = nft.Level
+		}
+	}
+	return highest
+}
+
+// Pending reports whether the highest minted NFT was burned without its successor being minted,
+// i.e. an upgrade stopped between its burn and mint steps
+func (m *Machine) Pending() bool {
+	return m.HighestLevel() > 0 && m.Active() == nil
+}
+
+// NextLevel returns the only level the user may mint next, 0 once the highest level is reached
+func (m *Machine) NextLevel() int {
+	next := m.HighestLevel() + 1
+	if !m.catalog.Valid(next) {
+		return 0
+	}
+	return next
+}
+
+// CanMint checks whether minting the given level is a legal transition
+func (m *Machine) CanMint(level int) error {
+	if !m.catalog.Valid(level) {
+		return newError(ErrCodeInvalidLevel, "NFT level %d is not defined", level)
+	}
+	if active := m.Active(); active != nil {
+		if active.Level == level {
+			return newError(ErrCodeAlreadyOwned, "Level %d NFT is already active", level)
+		}
+		return newError(ErrCodeActiveNftExists, "Level %d NFT is still active and must be burned first", active.Level)
+	}
+	highest := m.HighestLevel()
+	if level <= highest {
+		return newError(ErrCodeAlreadyOwned, "Level %d NFT has already been minted", level)
+	}
+	if level != highest+1 {
+		return newError(ErrCodeSequenceViolation, "Level %d NFT requires Level %d first", level, highest+1)
+	}
+	return nil
+}
+
+// Mint moves a level from Locked/Unlockable to Active and returns the record to persist.
+// The caller fills in the on-chain fields before saving it.
+func (m *Machine) Mint(level int) (*repository.TieredNft, NftMinted, error) {
+	if err := m.CanMint(level); err != nil {
+		return nil, NftMinted{}, err
+	}
+	tier, _ := m.catalog.Tier(level)
+
+	now := m.now()
+	nft := repository.TieredNft{
+		UserID:   m.UserID,
+		Level:    level,
+		Name:     tier.Name,
+		Status:   repository.NftStatusActive,
+		MintedAt: now,
+	}
+	m.nfts = append(m.nfts, nft)
+
+	return &nft, NftMinted{UserID: m.UserID, Level: level, At: now}, nil
+}
+
+// CanBurn checks whether burning the NFT is a legal transition
+func (m *Machine) CanBurn(nftID int) error {
+	nft := m.find(nftID)
+	if nft == nil {
+		return newError(ErrCodeNftNotFound, "NFT %d does not belong to user %d", nftID, m.UserID)
+	}
+	if nft.Status != repository.NftStatusActive {
+		return newError(ErrCodeNotActive, "Level %d NFT is not active", nft.Level)
+	}
+	if nft.Level >= m.catalog.MaxLevel() {
+		return newError(ErrCodeMaxLevelBurn, "Level %d is the highest level and cannot be burned", nft.Level)
+	}
+	return nil
+}
+
+// Burn moves an Active NFT to Burned and returns the updated record to persist
+func (m *Machine) Burn(nftID int) (*repository.TieredNft, NftBurned, error) {
+	if err := m.CanBurn(nftID); err != nil {
+		return nil, NftBurned{}, err
+	}
+	nft := m.find(nftID)
+
+	now := m.now()
+	nft.Status = repository.NftStatusBurned
+	nft.BurnedAt = &now
+	nft.BenefitsActivated = false
+
+	burned := *nft
+	return &burned, NftBurned{UserID: m.UserID, NftID: nftID, Level: nft.Level, At: now}, nil
+}
+
+func (m *Machine) find(nftID int) *repository.TieredNft {
+	for i := range m.nfts {
+		if m.nfts[i].ID == nftID {
+			return &m.nfts[i]
+		}
+	}
+	return nil
+}
+
+// ==========================================
+// DOMAIN EVENTS
+// ==========================================
+
+// EventType identifies a lifecycle event
+type EventType string
+
+const (
+	EventNftMinted EventType = "nft.minted"
+	EventNftBurned EventType = "nft.burned"
+)
+
+// Event is emitted by every successful transition; callers deliver it once the transition is persisted
+type Event interface {
+	Type() EventType
+	OccurredAt() time.Time
+	// String describes the transition for logs
+	String() string
+}
+
+// NftMinted is emitted when a level moves to Active
+type NftMinted struct {
+	UserID int
+	Level  int
+	At     time.Time
+}
+
+func (e NftMinted) Type() EventType       { return EventNftMinted }
+func (e NftMinted) OccurredAt() time.Time { return e.At }
+func (e NftMinted) String() string {
+	return fmt.Sprintf("Level %d NFT of user %d minted", e.Level, e.UserID)
+}
+
+// NftBurned is emitted when an Active NFT moves to Burned
+type NftBurned struct {
+	UserID int
+	NftID  int
+	Level  int
+	At     time.Time
+}
+
+func (e NftBurned) Type() EventType       { return EventNftBurned }
+func (e NftBurned) OccurredAt() time.Time { return e.At }
+func (e NftBurned) String() string {
+	return fmt.Sprintf("Level %d NFT %d of user %d burned", e.Level, e.NftID, e.UserID)
+}
+
+// ==========================================
+// TRANSITION ERRORS
+// ==========================================
+
+// ErrorCode is a stable identifier for a rejected transition, safe to return to clients
+type ErrorCode string
+
+const (
+	ErrCodeInvalidLevel      ErrorCode = "NFT_INVALID_LEVEL"
+	ErrCodeNftNotFound       ErrorCode = "NFT_NOT_FOUND"
+	ErrCodeAlreadyOwned      ErrorCode = "NFT_ALREADY_OWNED"
+	ErrCodeActiveNftExists   ErrorCode = "NFT_ACTIVE_EXISTS"
+	ErrCodeSequenceViolation ErrorCode = "NFT_SEQUENCE_VIOLATION"
+	ErrCodeNotActive         ErrorCode = "NFT_NOT_ACTIVE"
+	ErrCodeMaxLevelBurn      ErrorCode = "NFT_MAX_LEVEL_BURN"
+)
+
+// Error is returned when a transition is not allowed
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func newError(code ErrorCode, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
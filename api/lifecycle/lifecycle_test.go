@@ -0,0 +1,143 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+const userID = 7
+
+// history returns the NFTs of a user who minted levels 1 to highest, each burned to mint the next; the
+// highest is burned too when topBurned is set. An NFT's ID is its level.
+func history(highest int, topBurned bool) []repository.TieredNft {
+	nfts := []repository.TieredNft{}
+	for level := 1; level <= highest; level++ {
+		status := repository.NftStatusBurned
+		if level == highest && !topBurned {
+			status = repository.NftStatusActive
+		}
+		nfts = append(nfts, repository.TieredNft{ID: level, UserID: userID, Level: level, Status: status})
+	}
+	return nfts
+}
+
+// codeOf returns the ErrorCode of a rejected transition, "" for nil
+func codeOf(t *testing.T, err error) ErrorCode {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var lifecycleErr *Error
+	if !errors.As(err, &lifecycleErr) {
+		t.Fatalf("error %v is not a lifecycle error", err)
+	}
+	return lifecycleErr.Code
+}
+
+func TestMachineState(t *testing.T) {
+	tests := []struct {
+		name        string
+		nfts        []repository.TieredNft
+		activeLevel int
+		pending     bool
+		nextLevel   int
+	}{
+		{"no NFT", nil, 0, false, 1},
+		{"Level 1 active", history(1, false), 1, false, 2},
+		{"Level 2 active", history(2, false), 2, false, 3},
+		{"burned before the mint", history(2, true), 0, true, 3},
+		{"highest level active", history(5, false), 5, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(userID, tt.nfts)
+			if m.ActiveLevel() != tt.activeLevel || m.Pending() != tt.pending || m.NextLevel() != tt.nextLevel {
+				t.Errorf("active level %d, pending %t, next level %d; want %d, %t and %d", m.ActiveLevel(), m.Pending(),
+					m.NextLevel(), tt.activeLevel, tt.pending, tt.nextLevel)
+			}
+		})
+	}
+}
+
+func TestMachineMint(t *testing.T) {
+	tests := []struct {
+		name  string
+		nfts  []repository.TieredNft
+		level int
+		want  ErrorCode
+	}{
+		{"first level", nil, 1, ""},
+		{"level skipped from none", nil, 2, ErrCodeSequenceViolation},
+		{"next level after the burn", history(1, true), 2, ""},
+		{"level skipped after a burn", history(1, true), 3, ErrCodeSequenceViolation},
+		{"mint while active", history(1, false), 2, ErrCodeActiveNftExists},
+		{"active level again", history(1, false), 1, ErrCodeAlreadyOwned},
+		{"burned level re-minted", history(2, true), 1, ErrCodeAlreadyOwned},
+		{"undefined level", nil, 6, ErrCodeInvalidLevel},
+		{"level zero", nil, 0, ErrCodeInvalidLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(userID, tt.nfts)
+			if got := codeOf(t, m.CanMint(tt.level)); got != tt.want {
+				t.Errorf("CanMint(%d) = %q, want %q", tt.level, got, tt.want)
+			}
+			nft, event, err := m.Mint(tt.level)
+			if got := codeOf(t, err); got != tt.want {
+				t.Fatalf("Mint(%d) = %q, want %q", tt.level, got, tt.want)
+			}
+			if tt.want != "" {
+				return
+			}
+			if nft.Level != tt.level || nft.UserID != userID || nft.Status != repository.NftStatusActive ||
+				event.Level != tt.level || event.UserID != userID {
+				t.Errorf("minted %+v with event %+v, want an active Level %d NFT of user %d", nft, event, tt.level, userID)
+			}
+			// The minted NFT is now the active one
+			if m.ActiveLevel() != tt.level || codeOf(t, m.CanMint(tt.level)) != ErrCodeAlreadyOwned {
+				t.Errorf("after the mint: active level %d, want %d and no second mint", m.ActiveLevel(), tt.level)
+			}
+		})
+	}
+}
+
+func TestMachineBurn(t *testing.T) {
+	tests := []struct {
+		name  string
+		nfts  []repository.TieredNft
+		nftID int
+		want  ErrorCode
+	}{
+		{"active NFT", history(1, false), 1, ""},
+		{"active NFT of a higher level", history(4, false), 4, ""},
+		{"highest level", history(5, false), 5, ErrCodeMaxLevelBurn},
+		{"burned NFT", history(2, false), 1, ErrCodeNotActive},
+		{"unknown NFT", history(1, false), 99, ErrCodeNftNotFound},
+		{"no NFT", nil, 1, ErrCodeNftNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(userID, tt.nfts)
+			if got := codeOf(t, m.CanBurn(tt.nftID)); got != tt.want {
+				t.Errorf("CanBurn(%d) = %q, want %q", tt.nftID, got, tt.want)
+			}
+			burned, event, err := m.Burn(tt.nftID)
+			if got := codeOf(t, err); got != tt.want {
+				t.Fatalf("Burn(%d) = %q, want %q", tt.nftID, got, tt.want)
+			}
+			if tt.want != "" {
+				return
+			}
+			if burned.Status != repository.NftStatusBurned || burned.BurnedAt == nil || burned.BenefitsActivated ||
+				event.NftID != tt.nftID {
+				t.Errorf("burned %+v with event %+v, want NFT %d burned without benefits", burned, event, tt.nftID)
+			}
+			// The burn leaves the upgrade pending until the next level is minted
+			if !m.Pending() || codeOf(t, m.CanBurn(tt.nftID)) != ErrCodeNotActive || m.CanMint(burned.Level+1) != nil {
+				t.Errorf("after the burn: pending %t, want the next level mintable and no second burn", m.Pending())
+			}
+		})
+	}
+}
@@ -1,10 +1,34 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/antigaming"
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/badgeexpiry"
+	"github.com/aiw3/nft-solana-api/chain"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/exchanges"
+	"github.com/aiw3/nft-solana-api/idempotency"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/nfts"
+	"github.com/aiw3/nft-solana-api/prices"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+	"github.com/aiw3/nft-solana-api/repository/seed"
+	"github.com/aiw3/nft-solana-api/repository/sqlite"
+	"github.com/aiw3/nft-solana-api/rpc"
+	"github.com/aiw3/nft-solana-api/tasks"
+	"github.com/aiw3/nft-solana-api/tiers"
+	"github.com/aiw3/nft-solana-api/volume"
 	"github.com/swaggest/openapi-go/openapi3"
 	"github.com/swaggest/rest/response/gzip"
 	"github.com/swaggest/rest/web"
@@ -16,7 +40,222 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// getEnv returns the value of an environment variable or a fallback when it is unset
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// openStore opens the configured data store.
+// AIW3_STORE=memory keeps everything in process memory; otherwise data is persisted
+// to the SQLite file at AIW3_DB_PATH (default aiw3-nft.db) and survives restarts.
+func openStore(ctx context.Context) (*repository.Store, func()) {
+	if getEnv("AIW3_STORE", "sqlite") == "memory" {
+		fmt.Println("💾 Using in-memory data store")
+		return memory.NewStore(), func() {}
+	}
+
+	path := getEnv("AIW3_DB_PATH", "aiw3-nft.db")
+	db, err := sqlite.Open(ctx, path)
+	if err != nil {
+		log.Fatal("Failed to open database:", err)
+	}
+	fmt.Printf("💾 Using SQLite data store at %s\n", path)
+	return sqlite.NewStore(db), func() { db.Close() }
+}
+
+// loadTierCatalog replaces the embedded tier catalog with the file at AIW3_TIER_CATALOG when set.
+// An invalid catalog stops the service before it starts serving requests.
+func loadTierCatalog() {
+	path := getEnv("AIW3_TIER_CATALOG", "")
+	if path == "" {
+		fmt.Printf("🏷️  Using built-in tier catalog (%d levels)\n", tiers.Current().MaxLevel())
+		return
+	}
+
+	catalog, err := tiers.Load(path)
+	if err != nil {
+		log.Fatal("Invalid tier catalog:", err)
+	}
+	tiers.SetCurrent(catalog)
+	fmt.Printf("🏷️  Using tier catalog at %s (%d levels)\n", path, catalog.MaxLevel())
+}
+
+// loadTaskGuardRules returns the anti-gaming rules at AIW3_TASK_GUARD_RULES, or the defaults when unset.
+// Invalid rules stop the service before it starts serving requests.
+func loadTaskGuardRules() antigaming.Rules {
+	path := getEnv("AIW3_TASK_GUARD_RULES", "")
+	if path == "" {
+		fmt.Println("🛡️  Using built-in task guard rules")
+		return antigaming.DefaultRules()
+	}
+
+	rules, err := antigaming.LoadRules(path)
+	if err != nil {
+		log.Fatal("Invalid task guard rules:", err)
+	}
+	fmt.Printf("🛡️  Using task guard rules at %s\n", path)
+	return rules
+}
+
+// badgeExpiryNotice returns how long before an activated badge expires its holder is notified,
+// AIW3_BADGE_EXPIRY_NOTICE (e.g. 72h) or 72 hours when unset
+func badgeExpiryNotice() time.Duration {
+	value := getEnv("AIW3_BADGE_EXPIRY_NOTICE", "72h")
+	notice, err := time.ParseDuration(value)
+	if err != nil || notice < 0 {
+		log.Fatalf("Invalid AIW3_BADGE_EXPIRY_NOTICE %q", value)
+	}
+	return notice
+}
+
+// aiQuotaSchedule returns when weekly AI agent quotas reset, AIW3_AI_QUOTA_RESET
+// (e.g. "Monday 00:00 Asia/Shanghai") or Monday 00:00 UTC when unset
+func aiQuotaSchedule() aiquota.Schedule {
+	value := getEnv("AIW3_AI_QUOTA_RESET", "")
+	if value == "" {
+		return aiquota.DefaultSchedule()
+	}
+	schedule, err := aiquota.ParseSchedule(value)
+	if err != nil {
+		log.Fatal("Invalid AIW3_AI_QUOTA_RESET:", err)
+	}
+	return schedule
+}
+
+// volumeReconcileTime returns the UTC time of day trading volume is reconciled, AIW3_VOLUME_RECONCILE_AT
+// (e.g. "03:00") or 03:00 UTC when unset
+func volumeReconcileTime() time.Duration {
+	value := getEnv("AIW3_VOLUME_RECONCILE_AT", "03:00")
+	at, err := volume.ParseReconcileTime(value)
+	if err != nil {
+		log.Fatal("Invalid AIW3_VOLUME_RECONCILE_AT:", err)
+	}
+	return at
+}
+
+// configureMoneyJSON sets how volumes, fees and savings are written in JSON, AIW3_MONEY_JSON: "number"
+// (the default) or "string", for clients that would lose precision reading decimals as floats
+func configureMoneyJSON() {
+	format, err := money.ParseJSONFormat(getEnv("AIW3_MONEY_JSON", "number"))
+	if err != nil {
+		log.Fatal("Invalid AIW3_MONEY_JSON:", err)
+	}
+	money.SetJSONFormat(format)
+}
+
+// exchangeSealer returns the sealer exchange API credentials are stored with, AIW3_EXCHANGE_KEY
+// (a base64 32-byte key) or the public development key when unset
+func exchangeSealer() *exchanges.Sealer {
+	value := getEnv("AIW3_EXCHANGE_KEY", "")
+	if value == "" {
+		if !developmentMode() {
+			log.Fatal("AIW3_EXCHANGE_KEY is not set; set AIW3_DEV=true to use the development key locally")
+		}
+		fmt.Println("🔑 Using development exchange credential key (AIW3_DEV)")
+		return exchanges.DevelopmentSealer()
+	}
+	sealer, err := exchanges.ParseSealerKey(value)
+	if err != nil {
+		log.Fatal("Invalid AIW3_EXCHANGE_KEY:", err)
+	}
+	return sealer
+}
+
+// exchangeSyncInterval returns how often bound exchange accounts sync, AIW3_EXCHANGE_SYNC_INTERVAL
+// (e.g. 5m) or every 5 minutes when unset
+func exchangeSyncInterval() time.Duration {
+	value := getEnv("AIW3_EXCHANGE_SYNC_INTERVAL", "5m")
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		log.Fatalf("Invalid AIW3_EXCHANGE_SYNC_INTERVAL %q", value)
+	}
+	return interval
+}
+
+// exchangeAdapters returns the exchange adapters; with AIW3_EXCHANGE_STANDIN=true they talk to a local
+// stand-in serving recorded payloads instead of the exchanges, which only builds with -tags dev
+func exchangeAdapters() *exchanges.Registry {
+	if getEnv("AIW3_EXCHANGE_STANDIN", "") != "true" {
+		return exchanges.DefaultRegistry(exchanges.Config{})
+	}
+	standIn, ok := exchangeStandIn()
+	if !ok {
+		log.Fatal("AIW3_EXCHANGE_STANDIN needs a build with -tags dev")
+	}
+	fmt.Printf("🧪 Exchanges replaced by the recorded stand-in at %s\n", standIn)
+	return exchanges.DefaultRegistry(exchanges.Config{BaseURL: standIn})
+}
+
+// priceOracle returns the oracle trades settled in other assets than USDT are priced with,
+// AIW3_PRICE_ORACLE: "pyth" (the default) asks the Pyth price service at AIW3_PYTH_URL, Hermes when unset;
+// "standin" a local stand-in serving recorded prices (built with -tags dev only); "csv" reads the prices recorded in AIW3_PRICES_CSV.
+// USDC counts at par when the oracle has no price for it.
+func priceOracle() prices.PriceOracle {
+	var oracle prices.PriceOracle
+	switch value := getEnv("AIW3_PRICE_ORACLE", "pyth"); value {
+	case "pyth":
+		baseURL := getEnv("AIW3_PYTH_URL", "https://hermes.pyth.network")
+		oracle = prices.NewPyth(prices.PythConfig{BaseURL: baseURL})
+		fmt.Printf("💱 Pricing trades with the Pyth price service at %s\n", baseURL)
+	case "standin":
+		standIn, ok := pythStandIn()
+		if !ok {
+			log.Fatal("AIW3_PRICE_ORACLE=standin needs a build with -tags dev")
+		}
+		oracle = prices.NewPyth(prices.PythConfig{BaseURL: standIn})
+		fmt.Printf("🧪 Pyth replaced by the recorded stand-in at %s\n", standIn)
+	case "csv":
+		path := getEnv("AIW3_PRICES_CSV", "")
+		if path == "" {
+			log.Fatal("AIW3_PRICE_ORACLE=csv needs AIW3_PRICES_CSV")
+		}
+		recorded, err := prices.LoadCSV(path)
+		if err != nil {
+			log.Fatal("Invalid AIW3_PRICES_CSV:", err)
+		}
+		oracle = recorded
+		fmt.Printf("💱 Pricing trades with the %d assets recorded in %s\n", len(recorded.Assets()), path)
+	default:
+		log.Fatalf("Invalid AIW3_PRICE_ORACLE %q", value)
+	}
+	return prices.Chain(oracle, prices.Par(money.USDC))
+}
+
+// serviceCredentials returns the tokens internal services authenticate with, AIW3_SERVICE_TOKENS
+// (e.g. "trading-engine=s3cret,ai-agent=t0ken"). Unset, the server does not start unless AIW3_DEV
+// allows the public development tokens.
+func serviceCredentials() *auth.ServiceCredentials {
+	value := getEnv("AIW3_SERVICE_TOKENS", "")
+	if value == "" {
+		if !developmentMode() {
+			log.Fatal("AIW3_SERVICE_TOKENS is not set; set AIW3_DEV=true to use the development tokens locally")
+		}
+		credentials := auth.DevelopmentServiceCredentials()
+		fmt.Printf("🔒 Using development service tokens (%s)\n", strings.Join(credentials.Services(), ", "))
+		return credentials
+	}
+	credentials, err := auth.ParseServiceCredentials(value)
+	if err != nil {
+		log.Fatal("Invalid AIW3_SERVICE_TOKENS:", err)
+	}
+	fmt.Printf("🔒 Using service tokens for %s\n", strings.Join(credentials.Services(), ", "))
+	return credentials
+}
+
+// developmentMode reports whether AIW3_DEV=true allows the development service tokens and exchange
+// credential key, which are public and must never protect a deployed server
+func developmentMode() bool {
+	return getEnv("AIW3_DEV", "") == "true"
+}
+
 func main() {
+	// Load the tier catalog before anything reads level definitions
+	loadTierCatalog()
+	configureMoneyJSON()
+
 	// Create service with OpenAPI documentation
 	service := web.NewService(openapi3.NewReflector())
 
@@ -63,15 +302,92 @@ func main() {
 		},
 	)
 
+	// Create the data store and load development seed data on first start
+	store, closeStore := openStore(context.Background())
+	defer closeStore()
+	seeded, err := seed.LoadIfEmpty(context.Background(), store)
+	if err != nil {
+		log.Fatal("Failed to seed data store:", err)
+	}
+	if seeded {
+		fmt.Println("🌱 Loaded development seed data")
+	}
+
+	// Finish upgrades interrupted by a previous shutdown before serving requests
+	chainClient := chain.NewMockClient()
+	resumed, err := nfts.ResumeInFlightUpgrades(context.Background(), store, chainClient)
+	if err != nil {
+		log.Fatal("Failed to resume upgrades:", err)
+	}
+	if resumed > 0 {
+		fmt.Printf("♻️  Resumed %d interrupted NFT upgrades\n", resumed)
+	}
+
+	// Claims, upgrades and awards run on a bounded worker pool, one job per user at a time
+	jobs := coordinator.New(store.Leases, coordinator.DefaultConfig())
+	defer jobs.Stop(context.Background())
+
+	// Idempotency-Key responses are replayed for 24 hours, then swept
+	go idempotency.RunSweeper(context.Background(), store.Idempotency, time.Hour)
+
+	// Badge activations past their window go back to owned; holders are notified before that
+	expirySweeper := badgeexpiry.New(store, badgeexpiry.LogNotifier{}, badgeExpiryNotice())
+	go expirySweeper.Run(context.Background(), 5*time.Minute)
+
+	// Badge tasks are verified against server-side data by the verifier of their task type
+	verifiers := tasks.DefaultRegistry()
+
+	// Task completions pass the anti-gaming guard, which records every decision
+	guard := antigaming.New(store, loadTaskGuardRules())
+
+	// AI agent uses are metered against the active NFT level's weekly quota
+	quotas := aiquota.New(store, aiQuotaSchedule())
+	fmt.Printf("🤖 AI agent quotas reset %s\n", quotas.Schedule())
+
+	// Internal services read entitlements with their own service tokens
+	services := serviceCredentials()
+
+	// Trades ingested through the platform adapters make up users' trading volume, converted to USDT at
+	// trade time when settled in other assets; rollups are reconciled with the raw trades every night
+	volumes := volume.New(store, nfts.IsTradingPlatform, priceOracle())
+	go volumes.RunNightly(context.Background(), volumeReconcileTime())
+	if inbox := getEnv("AIW3_VOLUME_INBOX", ""); inbox != "" {
+		go volume.NewInbox(inbox, volumes, volume.CSVColumns{}).Run(context.Background(), time.Minute)
+		fmt.Printf("📥 Importing trade files from %s\n", inbox)
+	}
+
+	// Bound exchange accounts sync their new trades into trading volume and the fee ledger
+	adapters := exchangeAdapters()
+	sealer := exchangeSealer()
+	syncer := exchanges.NewSyncer(store, adapters, sealer, nfts.ExchangeSink(store, volumes))
+	syncInterval := exchangeSyncInterval()
+	go syncer.Run(context.Background(), syncInterval)
+	fmt.Printf("🔄 Syncing exchange accounts every %s\n", syncInterval)
+
 	// Register NFT and Badge endpoints
-	setupAPIRoutes(service)
+	setupAPIRoutes(service, store, chainClient, jobs, verifiers, guard, quotas, services, volumes, adapters, sealer)
 
 	// Swagger UI endpoint at /docs
 	service.Docs("/docs", swgui.New)
 
+	// gRPC mirror of the NFT status, entitlements and AI agent quota endpoints for internal Go services
+	grpcAddr := getEnv("AIW3_GRPC_ADDR", ":9090")
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatal("Failed to listen for gRPC:", err)
+	}
+	grpcServer := rpc.NewGRPCServer(store, quotas, services)
+	defer grpcServer.GracefulStop()
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatal("gRPC server failed:", err)
+		}
+	}()
+
 	// Start server
 	fmt.Println("🚀 Starting AIW3 NFT API server on :8080")
 	fmt.Println("📚 API Documentation: http://localhost:8080/docs")
+	fmt.Printf("📡 gRPC NftService on %s\n", grpcAddr)
 
 	if err := http.ListenAndServe(":8080", service); err != nil {
 		log.Fatal("Server failed to start:", err)
@@ -0,0 +1,518 @@
+// Package money keeps trading volumes, fees and savings as fixed-point decimals. An Amount is an integer
+// count of 10^-8 of its currency, the precision exchanges settle USDT fees in, so sums are exact and
+// Level 5's 50,000,000 USDT threshold is far from the ±92 billion an Amount holds.
+//
+// Rounding is explicit: parsing and float conversion round extra decimals half to even, and every
+// multiplication names its RoundingMode. Fees round HalfUp; qualifying volume compared with thresholds is
+// never rounded.
+//
+// Amounts of different currencies never mix: adding, subtracting or comparing them returns an error
+// wrapping ErrCurrencyMismatch. Encoded amounts keep their currency: USDT amounts are written as plain
+// decimals (or integer units in SQL), other currencies as "<currency>:<decimal>", e.g. "SOL:1.5".
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/swaggest/jsonschema-go"
+)
+
+// Currency is the code of the asset an amount is counted in
+type Currency string
+
+// Currencies amounts are kept in; volumes, fees and savings are in USDT
+const (
+	USDT Currency = "USDT"
+	USDC Currency = "USDC"
+)
+
+// Scale is how many decimal places an Amount keeps
+const Scale = 8
+
+// unitsPerWhole is 10^Scale
+const unitsPerWhole = 100_000_000
+
+// decimalPattern matches the decimals Parse reads: an optional sign, digits and an optional fraction
+var decimalPattern = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+
+// ErrRange is returned for values an Amount cannot hold: NaN, infinities and magnitudes beyond ±92 billion
+var ErrRange = errors.New("money: value out of range")
+
+// ErrCurrencyMismatch is returned when amounts of different currencies are combined or compared
+var ErrCurrencyMismatch = errors.New("money: currencies differ")
+
+// currencySeparator separates the currency from the decimal of an encoded non-USDT amount, e.g. "SOL:1.5"
+const currencySeparator = ":"
+
+// RoundingMode says how a result with more than Scale decimals is rounded
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest unit, ties to the even one; parsing and conversions use it
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest unit, ties away from zero; fees use it
+	HalfUp
+	// Down truncates towards zero
+	Down
+)
+
+// Amount is a fixed-point decimal in a currency. The zero Amount is zero with no currency yet: it adds
+// to and compares with amounts of any currency, so it can start a sum.
+type Amount struct {
+	units    int64
+	currency Currency
+}
+
+// Units returns the amount of n 10^-8 units of the currency
+func (c Currency) Units(n int64) Amount {
+	return Amount{units: n, currency: c}
+}
+
+// Whole returns the amount of n whole units of the currency
+func (c Currency) Whole(n int64) Amount {
+	if n > math.MaxInt64/unitsPerWhole || n < math.MinInt64/unitsPerWhole {
+		panic(ErrRange)
+	}
+	return Amount{units: n * unitsPerWhole, currency: c}
+}
+
+// Parse reads a decimal such as "1250.75" or "-3", rounding decimals beyond Scale half to even
+func (c Currency) Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Amount{}, fmt.Errorf("money: %q is not a decimal", s)
+	}
+	r, _ := new(big.Rat).SetString(s)
+	return c.fromRat(r, HalfEven)
+}
+
+// MustParse is Parse for constants; it panics when s is not a decimal
+func (c Currency) MustParse(s string) Amount {
+	a, err := c.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// FromFloat converts a float, rounding half to even at Scale. Floats rarely hold decimals exactly; the
+// rounding turns 0.1000000000000000055 back into 0.1.
+func (c Currency) FromFloat(f float64) (Amount, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Amount{}, ErrRange
+	}
+	return c.fromRat(new(big.Rat).SetFloat64(f), HalfEven)
+}
+
+// FromRat converts an exact fraction, such as a token's base units over 10^decimals, rounding half to even
+// at Scale
+func (c Currency) FromRat(r *big.Rat) (Amount, error) {
+	return c.fromRat(r, HalfEven)
+}
+
+// fromRat rounds r to Scale decimals
+func (c Currency) fromRat(r *big.Rat, mode RoundingMode) (Amount, error) {
+	units := round(new(big.Rat).Mul(r, new(big.Rat).SetInt64(unitsPerWhole)), mode)
+	if !units.IsInt64() {
+		return Amount{}, ErrRange
+	}
+	return Amount{units: units.Int64(), currency: c}, nil
+}
+
+// round rounds r to an integer
+func round(r *big.Rat, mode RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() == 0 || mode == Down {
+		return quo
+	}
+	// Compare twice the remainder with the denominator to find which side of the half r is on
+	half := new(big.Int).Abs(rem)
+	half.Lsh(half, 1)
+	away := false
+	switch cmp := half.Cmp(r.Denom()); {
+	case cmp > 0:
+		away = true
+	case cmp == 0 && mode == HalfUp:
+		away = true
+	case cmp == 0 && mode == HalfEven:
+		away = quo.Bit(0) == 1
+	}
+	if away {
+		quo.Add(quo, big.NewInt(int64(r.Sign())))
+	}
+	return quo
+}
+
+// Currency returns the amount's currency, "" for the zero Amount
+func (a Amount) Currency() Currency {
+	return a.currency
+}
+
+// Units returns the amount in 10^-8 units of its currency
+func (a Amount) Units() int64 {
+	return a.units
+}
+
+// common returns the currency of an operation on a and b, an error wrapping ErrCurrencyMismatch when
+// they differ
+func (a Amount) common(b Amount) (Currency, error) {
+	switch {
+	case a.currency == "":
+		return b.currency, nil
+	case b.currency == "" || a.currency == b.currency:
+		return a.currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s amounts cannot be combined", ErrCurrencyMismatch, a.currency, b.currency)
+}
+
+// Add returns a + b; it fails when their currencies differ or the sum is out of range
+func (a Amount) Add(b Amount) (Amount, error) {
+	currency, err := a.common(b)
+	if err != nil {
+		return Amount{}, err
+	}
+	sum := a.units + b.units
+	if (b.units > 0 && sum < a.units) || (b.units < 0 && sum > a.units) {
+		return Amount{}, ErrRange
+	}
+	return Amount{units: sum, currency: currency}, nil
+}
+
+// Sub returns a - b; it fails when their currencies differ or the difference is out of range
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b.units == math.MinInt64 {
+		return Amount{}, ErrRange
+	}
+	return a.Add(Amount{units: -b.units, currency: b.currency})
+}
+
+// Neg returns -a; it panics for the one amount whose negation is out of range
+func (a Amount) Neg() Amount {
+	if a.units == math.MinInt64 {
+		panic(ErrRange)
+	}
+	return Amount{units: -a.units, currency: a.currency}
+}
+
+// Mul returns a multiplied by a factor such as a fee rate, rounded to Scale with mode
+func (a Amount) Mul(factor float64, mode RoundingMode) Amount {
+	if math.IsNaN(factor) || math.IsInf(factor, 0) {
+		panic(ErrRange)
+	}
+	return a.mulRat(new(big.Rat).SetFloat64(factor), mode)
+}
+
+// MulFrac returns a * num / den, rounded to Scale with mode; e.g. MulFrac(75, 100, HalfUp) is 75%
+func (a Amount) MulFrac(num, den int64, mode RoundingMode) Amount {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	return a.mulRat(big.NewRat(num, den), mode)
+}
+
+// Convert values a at price, what one whole unit of a's currency is worth in another currency. The result
+// is in price's currency, rounded to Scale with mode: 2.5 SOL at a price of 148.12 USDT is 370.3 USDT.
+func (a Amount) Convert(price Amount, mode RoundingMode) Amount {
+	converted := a.mulRat(big.NewRat(price.units, unitsPerWhole), mode)
+	converted.currency = price.currency
+	return converted
+}
+
+func (a Amount) mulRat(r *big.Rat, mode RoundingMode) Amount {
+	units := round(r.Mul(r, new(big.Rat).SetInt64(a.units)), mode)
+	if !units.IsInt64() {
+		panic(ErrRange)
+	}
+	return Amount{units: units.Int64(), currency: a.currency}
+}
+
+// Round returns a rounded to places decimals (0 to Scale) with mode
+func (a Amount) Round(places int, mode RoundingMode) Amount {
+	if places < 0 || places >= Scale {
+		return a
+	}
+	step := big.NewInt(int64(math.Pow10(Scale - places)))
+	steps := round(new(big.Rat).SetFrac(big.NewInt(a.units), step), mode)
+	units := steps.Mul(steps, step)
+	if !units.IsInt64() {
+		panic(ErrRange)
+	}
+	return Amount{units: units.Int64(), currency: a.currency}
+}
+
+// Whole returns the whole units of a, truncated towards zero
+func (a Amount) Whole() int64 {
+	return a.units / unitsPerWhole
+}
+
+// Cmp compares a and b: -1 when a < b, 0 when equal, +1 when a > b. It fails when their currencies differ.
+func (a Amount) Cmp(b Amount) (int, error) {
+	if _, err := a.common(b); err != nil {
+		return 0, err
+	}
+	switch {
+	case a.units < b.units:
+		return -1, nil
+	case a.units > b.units:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Sign returns -1, 0 or +1 by the sign of a
+func (a Amount) Sign() int {
+	switch {
+	case a.units < 0:
+		return -1
+	case a.units > 0:
+		return 1
+	}
+	return 0
+}
+
+// IsZero reports whether a is zero, whatever its currency
+func (a Amount) IsZero() bool {
+	return a.units == 0
+}
+
+// Ratio returns a / b as a float, for percentages; 0 when b is zero. It fails when their currencies differ.
+func (a Amount) Ratio(b Amount) (float64, error) {
+	if _, err := a.common(b); err != nil {
+		return 0, err
+	}
+	if b.units == 0 {
+		return 0, nil
+	}
+	ratio, _ := big.NewRat(a.units, b.units).Float64()
+	return ratio, nil
+}
+
+// Float64 returns the nearest float, for APIs and maths that need one
+func (a Amount) Float64() float64 {
+	f, _ := big.NewRat(a.units, unitsPerWhole).Float64()
+	return f
+}
+
+// String formats a as a plain decimal without trailing zeros, e.g. "1250.75" or "-3"
+func (a Amount) String() string {
+	units := a.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(units)).String()
+	if len(abs) <= Scale {
+		abs = strings.Repeat("0", Scale-len(abs)+1) + abs
+	}
+	whole, fraction := abs[:len(abs)-Scale], strings.TrimRight(abs[len(abs)-Scale:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// ==========================================
+// ENCODING
+// ==========================================
+
+// JSONFormat is how amounts are written in JSON
+type JSONFormat int
+
+const (
+	// JSONNumber writes amounts as numbers with their exact decimals, e.g. 1250.75
+	JSONNumber JSONFormat = iota
+	// JSONString writes amounts as strings, e.g. "1250.75", for clients that read numbers as doubles
+	JSONString
+)
+
+// jsonFormat is set once at startup, before any amount is encoded
+var jsonFormat = JSONNumber
+
+// SetJSONFormat sets how amounts are written in JSON. Amounts are read from numbers and strings alike.
+func SetJSONFormat(format JSONFormat) {
+	jsonFormat = format
+}
+
+// ParseJSONFormat reads a JSONFormat by name: number or string
+func ParseJSONFormat(name string) (JSONFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "number":
+		return JSONNumber, nil
+	case "string":
+		return JSONString, nil
+	}
+	return JSONNumber, fmt.Errorf("money: JSON format %q: want number or string", name)
+}
+
+// MarshalJSON writes USDT amounts as numbers or strings by the JSON format, and amounts of other currencies
+// as strings with their currency, e.g. "SOL:1.5"
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if a.labeled() {
+		return []byte(strconv.Quote(a.encode())), nil
+	}
+	if jsonFormat == JSONString {
+		return []byte(strconv.Quote(a.String())), nil
+	}
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a number or a decimal string as USDT, and "<currency>:<decimal>" in its currency
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	amount, err := decode(s)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// MarshalYAML writes amounts like MarshalJSON writes them as strings
+func (a Amount) MarshalYAML() (any, error) {
+	return a.encode(), nil
+}
+
+// UnmarshalYAML reads amounts like UnmarshalJSON, e.g. tier thresholds
+func (a *Amount) UnmarshalYAML(unmarshal func(any) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	amount, err := decode(s)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// USDTAmount is an Amount that only decodes from USDT: a number, a decimal string or "USDT:<decimal>".
+// Request and config fields documented as USDT use it, so an amount labeled with another currency is
+// refused when it is read instead of being added to USDT totals.
+type USDTAmount struct {
+	Amount
+}
+
+// UnmarshalJSON reads a USDT amount like Amount.UnmarshalJSON, an error wrapping ErrCurrencyMismatch for
+// another currency
+func (a *USDTAmount) UnmarshalJSON(data []byte) error {
+	var amount Amount
+	if err := amount.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	return a.set(amount)
+}
+
+// UnmarshalYAML reads a USDT amount like Amount.UnmarshalYAML
+func (a *USDTAmount) UnmarshalYAML(unmarshal func(any) error) error {
+	var amount Amount
+	if err := amount.UnmarshalYAML(unmarshal); err != nil {
+		return err
+	}
+	return a.set(amount)
+}
+
+func (a *USDTAmount) set(amount Amount) error {
+	if amount.currency != USDT {
+		return fmt.Errorf("%w: %s is not a USDT amount", ErrCurrencyMismatch, amount.encode())
+	}
+	a.Amount = amount
+	return nil
+}
+
+// labeled reports whether a is encoded with its currency: amounts in USDT, or in no currency yet, are not
+func (a Amount) labeled() bool {
+	return a.currency != "" && a.currency != USDT
+}
+
+// encode writes a as a decimal, prefixed with its currency unless it is in USDT
+func (a Amount) encode() string {
+	if a.labeled() {
+		return string(a.currency) + currencySeparator + a.String()
+	}
+	return a.String()
+}
+
+// decode reads what encode writes; a decimal without a currency is USDT
+func decode(s string) (Amount, error) {
+	currency, number := USDT, strings.TrimSpace(s)
+	if code, rest, ok := strings.Cut(number, currencySeparator); ok {
+		currency, number = Currency(strings.ToUpper(strings.TrimSpace(code))), rest
+		if currency == "" {
+			return Amount{}, fmt.Errorf("money: %q has no currency before %q", s, currencySeparator)
+		}
+	}
+	return currency.parseNumber(number)
+}
+
+// parseNumber reads a decimal that may use an exponent, as JSON and YAML numbers can
+func (c Currency) parseNumber(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if !strings.ContainsAny(s, "eE") {
+		return c.Parse(s)
+	}
+	f, ok := new(big.Float).SetPrec(256).SetString(s)
+	if !ok {
+		return Amount{}, fmt.Errorf("money: %q is not a decimal", s)
+	}
+	r, _ := f.Rat(nil)
+	if r == nil {
+		return Amount{}, ErrRange
+	}
+	return c.fromRat(r, HalfEven)
+}
+
+// JSONSchema describes amounts as numbers or decimal strings, optionally prefixed with their currency
+func (Amount) JSONSchema() (jsonschema.Schema, error) {
+	var asString jsonschema.Schema
+	asString.WithType(jsonschema.String.Type()).WithPattern(`^([A-Za-z0-9]+:)?` + decimalPattern.String()[1:])
+	var schema jsonschema.Schema
+	schema.WithDescription("Decimal with up to 8 places, written as a number or a decimal string; amounts in another currency than USDT are strings prefixed with it, e.g. \"SOL:1.5\"")
+	schema.WithOneOf(jsonschema.Number.ToSchemaOrBool(), asString.ToSchemaOrBool())
+	return schema, nil
+}
+
+// Value stores a USDT amount as its integer units, so SQL can sum it, and an amount of another currency
+// as text with its currency, e.g. "SOL:1.5"
+func (a Amount) Value() (driver.Value, error) {
+	if a.labeled() {
+		return a.encode(), nil
+	}
+	return a.units, nil
+}
+
+// Scan reads integer units as USDT and text as written by Value
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*a = USDT.Units(v)
+	case nil:
+		*a = USDT.Units(0)
+	case string:
+		return a.scanText(v)
+	case []byte:
+		return a.scanText(string(v))
+	default:
+		return fmt.Errorf("money: cannot scan %T into an Amount", src)
+	}
+	return nil
+}
+
+func (a *Amount) scanText(s string) error {
+	amount, err := decode(s)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
@@ -0,0 +1,208 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const SOL Currency = "SOL"
+
+func TestEncodingRoundTrips(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Amount
+		json   string
+		yaml   string
+		sql    any
+	}{
+		{"usdt", USDT.MustParse("1250.75"), `1250.75`, `"1250.75"` + "\n", int64(125075000000)},
+		{"negative usdt", USDT.MustParse("-3"), `-3`, `"-3"` + "\n", int64(-300000000)},
+		{"sol", SOL.MustParse("1.5"), `"SOL:1.5"`, "SOL:1.5\n", "SOL:1.5"},
+		{"usdc", USDC.Units(1), `"USDC:0.00000001"`, "USDC:0.00000001\n", "USDC:0.00000001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.amount)
+			if err != nil || string(data) != tt.json {
+				t.Errorf("JSON %s, %v; want %s", data, err, tt.json)
+			}
+			var fromJSON Amount
+			if err := json.Unmarshal(data, &fromJSON); err != nil || fromJSON != tt.amount {
+				t.Errorf("JSON read back %v %s, %v; want %v %s", fromJSON, fromJSON.Currency(), err, tt.amount, tt.amount.Currency())
+			}
+
+			data, err = yaml.Marshal(tt.amount)
+			if err != nil || string(data) != tt.yaml {
+				t.Errorf("YAML %q, %v; want %q", data, err, tt.yaml)
+			}
+			var fromYAML Amount
+			if err := yaml.Unmarshal(data, &fromYAML); err != nil || fromYAML != tt.amount {
+				t.Errorf("YAML read back %v %s, %v; want %v %s", fromYAML, fromYAML.Currency(), err, tt.amount, tt.amount.Currency())
+			}
+
+			value, err := tt.amount.Value()
+			if err != nil || value != tt.sql {
+				t.Errorf("SQL value %#v, %v; want %#v", value, err, tt.sql)
+			}
+			var scanned Amount
+			if err := scanned.Scan(value); err != nil || scanned != tt.amount {
+				t.Errorf("SQL read back %v %s, %v; want %v %s", scanned, scanned.Currency(), err, tt.amount, tt.amount.Currency())
+			}
+			if text, ok := value.(string); ok {
+				if err := scanned.Scan([]byte(text)); err != nil || scanned != tt.amount {
+					t.Errorf("SQL read back from bytes %v, %v; want %v", scanned, err, tt.amount)
+				}
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "1250.75", want: USDT.MustParse("1250.75")},
+		{in: "1.5e3", want: USDT.Whole(1500)},
+		{in: "USDT:2", want: USDT.Whole(2)},
+		{in: "sol: 1.5", want: SOL.MustParse("1.5")},
+		{in: "SOL:2e-8", want: SOL.Units(2)},
+		{in: ":1.5", wantErr: true},
+		{in: "SOL:", wantErr: true},
+		{in: "SOL:one", wantErr: true},
+		{in: "1,5", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := decode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("decode(%q) = %v %s, %v; want %v %s, error %v", tt.in, got, got.Currency(), err, tt.want,
+				tt.want.Currency(), tt.wantErr)
+		}
+	}
+}
+
+func TestJSONStringFormat(t *testing.T) {
+	SetJSONFormat(JSONString)
+	defer SetJSONFormat(JSONNumber)
+	for amount, want := range map[Amount]string{USDT.MustParse("0.1"): `"0.1"`, SOL.MustParse("0.1"): `"SOL:0.1"`} {
+		if data, err := json.Marshal(amount); err != nil || string(data) != want {
+			t.Errorf("JSON %s, %v; want %s", data, err, want)
+		}
+	}
+}
+
+func TestUSDTAmountRefusesOtherCurrencies(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: `25000`, want: USDT.Whole(25000)},
+		{in: `"0.5"`, want: USDT.MustParse("0.5")},
+		{in: `"USDT:2"`, want: USDT.Whole(2)},
+		{in: `"SOL:5"`, wantErr: true},
+		{in: `"USDC:5"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		var fromJSON USDTAmount
+		err := json.Unmarshal([]byte(tt.in), &fromJSON)
+		if (err != nil) != tt.wantErr || fromJSON.Amount != tt.want {
+			t.Errorf("JSON %s: %v, %v; want %v, error %v", tt.in, fromJSON.Amount, err, tt.want, tt.wantErr)
+		}
+		if tt.wantErr && !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf("JSON %s: %v, want ErrCurrencyMismatch", tt.in, err)
+		}
+		var fromYAML USDTAmount
+		err = yaml.Unmarshal([]byte(tt.in), &fromYAML)
+		if (err != nil) != tt.wantErr || fromYAML.Amount != tt.want {
+			t.Errorf("YAML %s: %v, %v; want %v, error %v", tt.in, fromYAML.Amount, err, tt.want, tt.wantErr)
+		}
+	}
+
+	// Encoded like the Amount it holds
+	if data, err := json.Marshal(USDTAmount{USDT.MustParse("12.5")}); err != nil || string(data) != `12.5` {
+		t.Errorf("JSON %s, %v; want 12.5", data, err)
+	}
+}
+
+func TestCurrencyMismatch(t *testing.T) {
+	usdt, sol := USDT.Whole(2), SOL.Whole(1)
+	operations := map[string]func(a, b Amount) error{
+		"Add": func(a, b Amount) error {
+			_, err := a.Add(b)
+			return err
+		},
+		"Sub": func(a, b Amount) error {
+			_, err := a.Sub(b)
+			return err
+		},
+		"Cmp": func(a, b Amount) error {
+			_, err := a.Cmp(b)
+			return err
+		},
+		"Ratio": func(a, b Amount) error {
+			_, err := a.Ratio(b)
+			return err
+		},
+	}
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			if err := operation(usdt, sol); !errors.Is(err, ErrCurrencyMismatch) {
+				t.Errorf("USDT with SOL: %v, want ErrCurrencyMismatch", err)
+			}
+			if err := operation(usdt, USDT.Whole(1)); err != nil {
+				t.Errorf("USDT with USDT: %v", err)
+			}
+			// The zero Amount has no currency yet and combines with any
+			if err := operation(Amount{}, sol); err != nil {
+				t.Errorf("zero with SOL: %v", err)
+			}
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	sum, err := USDT.MustParse("0.1").Add(USDT.MustParse("0.2"))
+	if err != nil || sum != USDT.MustParse("0.3") {
+		t.Errorf("0.1 + 0.2 = %v, %v; want 0.3", sum, err)
+	}
+	start, err := Amount{}.Add(SOL.Whole(1))
+	if err != nil || start.Currency() != SOL {
+		t.Errorf("zero + 1 SOL = %v %s, %v; want SOL", start, start.Currency(), err)
+	}
+	if _, err := USDT.Units(1 << 62).Add(USDT.Units(1 << 62)); !errors.Is(err, ErrRange) {
+		t.Errorf("overflowing sum: %v, want ErrRange", err)
+	}
+	if cmp, err := USDT.Whole(1).Cmp(USDT.Whole(2)); err != nil || cmp != -1 {
+		t.Errorf("1 cmp 2 = %d, %v; want -1", cmp, err)
+	}
+	if neg := SOL.Whole(3).Neg(); neg != SOL.Whole(-3) {
+		t.Errorf("-(3 SOL) = %v %s, want -3 SOL", neg, neg.Currency())
+	}
+}
+
+func TestFromRatRoundsHalfEven(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		want     string
+	}{
+		{291374512, 1_000_000, "291.374512"},
+		{123456785, 1_000_000_000, "0.12345678"}, // lamports beyond Scale, a tie rounded to even
+		{123456795, 1_000_000_000, "0.1234568"},
+		{1, 3, "0.33333333"},
+	}
+	for _, tt := range tests {
+		got, err := SOL.FromRat(big.NewRat(tt.num, tt.den))
+		if err != nil || got != SOL.MustParse(tt.want) {
+			t.Errorf("%d/%d = %v, %v; want %s", tt.num, tt.den, got, err, tt.want)
+		}
+	}
+	if _, err := SOL.FromRat(big.NewRat(1<<62, 1)); !errors.Is(err, ErrRange) {
+		t.Errorf("2^62 whole units: %v, want ErrRange", err)
+	}
+}
@@ -0,0 +1,262 @@
+package nfts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+// ActivateNft activates (equips) the benefits of one of the caller's NFTs.
+// A user has at most one activated tiered NFT and one activated competition NFT: activating an NFT
+// deactivates the previously activated NFT of the same kind. Only active (not burned) NFTs can be activated.
+func ActivateNft(store *repository.Store, jobs *coordinator.Coordinator) usecase.Interactor {
+	type activateNftRequest struct {
+		Authorization  string `header:"Authorization" description:"Bearer token for user authentication"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		ActivateNftRequest
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req activateNftRequest, resp *ActivateNftResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = ActivateNftResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    ActivateNftData{},
+			}
+			return nil
+		}
+
+		nftType := req.NftType
+		if nftType == "" {
+			nftType = NftTypeTiered
+		}
+		if nftType != NftTypeTiered && nftType != NftTypeCompetition {
+			*resp = ActivateNftResponse{
+				Code:    400,
+				Message: fmt.Sprintf("Unknown NFT type %q; use %s or %s", nftType, NftTypeTiered, NftTypeCompetition),
+				Data:    ActivateNftData{},
+			}
+			return nil
+		}
+
+		var result ActivateNftResponse
+		err = jobs.Do(ctx, coordinator.Job{
+			Kind:   coordinator.JobActivate,
+			UserID: user.ID,
+			Run: func(ctx context.Context) error {
+				var err error
+				result, err = activateNftBenefits(ctx, store, user, nftType, req.UserNftID)
+				return err
+			},
+		})
+		if code, ok := coordinator.RejectionCode(err); ok {
+			*resp = ActivateNftResponse{
+				Code:    code,
+				Message: err.Error(),
+				Data:    ActivateNftData{},
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = result
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Activate NFT Benefits")
+	u.SetDescription("Activate a tiered or competition NFT's benefits; the previously activated NFT of the same kind is deactivated and the best fee reduction applies")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.NotFound, status.FailedPrecondition, status.Internal)
+
+	return u
+}
+
+// ==========================================
+// NFT BENEFITS ACTIVATION
+// ==========================================
+
+// activateNftBenefits activates the NFT and deactivates the other NFTs of its kind;
+// it runs as a coordinator job so it cannot interleave with an upgrade burning the same NFT
+func activateNftBenefits(ctx context.Context, store *repository.Store, user *repository.User, nftType string,
+	nftID int) (ActivateNftResponse, error) {
+	tiered, err := store.TieredNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return ActivateNftResponse{}, err
+	}
+	competition, err := store.CompetitionNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return ActivateNftResponse{}, err
+	}
+
+	deactivated := []int{}
+	switch nftType {
+	case NftTypeTiered:
+		target := -1
+		for i := range tiered {
+			if tiered[i].ID == nftID {
+				target = i
+			}
+		}
+		if target < 0 {
+			return activationRejected(404, fmt.Sprintf("NFT %d not found", nftID)), nil
+		}
+		if tiered[target].Status != repository.NftStatusActive {
+			return activationRejected(409, fmt.Sprintf("Only active NFTs can be activated; Level %d NFT %d is %s",
+				tiered[target].Level, nftID, tiered[target].Status)), nil
+		}
+		for i := range tiered {
+			nft := &tiered[i]
+			if nft.BenefitsActivated == (i == target) {
+				continue
+			}
+			if i != target {
+				deactivated = append(deactivated, nft.ID)
+			}
+			nft.BenefitsActivated = i == target
+			if err := store.TieredNfts.Update(ctx, nft); err != nil {
+				return ActivateNftResponse{}, err
+			}
+		}
+	case NftTypeCompetition:
+		target := -1
+		for i := range competition {
+			if competition[i].ID == nftID {
+				target = i
+			}
+		}
+		if target < 0 {
+			return activationRejected(404, fmt.Sprintf("Competition NFT %d not found", nftID)), nil
+		}
+		for i := range competition {
+			nft := &competition[i]
+			if nft.BenefitsActivated == (i == target) {
+				continue
+			}
+			if i != target {
+				deactivated = append(deactivated, nft.ID)
+			}
+			nft.BenefitsActivated = i == target
+			if err := store.CompetitionNfts.Update(ctx, nft); err != nil {
+				return ActivateNftResponse{}, err
+			}
+		}
+	}
+
+	benefits := newBenefitsState(tiered, competition)
+	data := ActivateNftData{
+		Success:                      true,
+		NftType:                      nftType,
+		UserNftID:                    nftID,
+		DeactivatedNftIDs:            deactivated,
+		EffectiveTradingFeeReduction: benefits.EffectiveFeeReduction,
+	}
+	if nftType == NftTypeTiered {
+		for _, nft := range tiered {
+			if nft.ID != nftID {
+				continue
+			}
+			if tier, ok := tiers.Current().Tier(nft.Level); ok {
+				stats := newTieredBenefitsStats(tier, benefits.activation(NftTypeTiered, nft.ID, true))
+				data.TieredBenefits = &stats
+			}
+		}
+	} else {
+		for _, nft := range competition {
+			if nft.ID == nftID {
+				stats := newCompetitionBenefitsStats(nft, benefits.activation(NftTypeCompetition, nft.ID, true))
+				data.CompetitionBenefits = &stats
+			}
+		}
+	}
+
+	return ActivateNftResponse{
+		Code:    200,
+		Message: fmt.Sprintf("NFT benefits activated; effective trading fee reduction is %d%%", benefits.EffectiveFeeReduction),
+		Data:    data,
+	}, nil
+}
+
+func activationRejected(code int, message string) ActivateNftResponse {
+	return ActivateNftResponse{
+		Code:    code,
+		Message: message,
+		Data:    ActivateNftData{},
+	}
+}
+
+// benefitsState is the outcome of the user's activated NFTs. Fee reductions do not stack:
+// the best reduction among the activated tiered and competition NFTs applies.
+type benefitsState struct {
+	EffectiveFeeReduction int
+	sourceType            string // kind of the NFT providing the effective reduction, "" when none is activated
+	sourceID              int
+}
+
+// newBenefitsState finds the best fee reduction among activated NFTs; a tiered NFT wins a tie
+func newBenefitsState(tiered []repository.TieredNft, competition []repository.CompetitionNft) benefitsState {
+	state := benefitsState{}
+	for _, nft := range tiered {
+		if !nft.BenefitsActivated || nft.Status != repository.NftStatusActive {
+			continue
+		}
+		tier, ok := tiers.Current().Tier(nft.Level)
+		if ok && (state.sourceType == "" || tier.TradingFeeReduction > state.EffectiveFeeReduction) {
+			state = benefitsState{EffectiveFeeReduction: tier.TradingFeeReduction, sourceType: NftTypeTiered, sourceID: nft.ID}
+		}
+	}
+	for _, nft := range competition {
+		if nft.BenefitsActivated && (state.sourceType == "" || nft.TradingFeeReduction > state.EffectiveFeeReduction) {
+			state = benefitsState{EffectiveFeeReduction: nft.TradingFeeReduction, sourceType: NftTypeCompetition, sourceID: nft.ID}
+		}
+	}
+	return state
+}
+
+// newBenefitsStateAt finds the best fee reduction at a past moment: among the NFTs the user held then
+// (minted and not yet burned), those whose benefits were activated then, by the recorded intervals, apply
+func newBenefitsStateAt(tiered []repository.TieredNft, competition []repository.CompetitionNft,
+	intervals []repository.BenefitsInterval, at time.Time) benefitsState {
+	activatedAt := func(nftType string, nftID int) bool {
+		for _, interval := range intervals {
+			if interval.NftType == nftType && interval.NftID == nftID && interval.Covers(at) {
+				return true
+			}
+		}
+		return false
+	}
+	heldTiered := []repository.TieredNft{}
+	for _, nft := range tiered {
+		if nft.MintedAt.After(at) || (nft.BurnedAt != nil && !nft.BurnedAt.After(at)) {
+			continue
+		}
+		nft.Status = repository.NftStatusActive // burned later, but active at that moment
+		nft.BenefitsActivated = activatedAt(NftTypeTiered, nft.ID)
+		heldTiered = append(heldTiered, nft)
+	}
+	heldCompetition := []repository.CompetitionNft{}
+	for _, nft := range competition {
+		if !nft.MintedAt.After(at) {
+			nft.BenefitsActivated = activatedAt(NftTypeCompetition, nft.ID)
+			heldCompetition = append(heldCompetition, nft)
+		}
+	}
+	return newBenefitsState(heldTiered, heldCompetition)
+}
+
+// activation reports an NFT's activation together with the user's effective fee reduction
+func (s benefitsState) activation(nftType string, nftID int, activated bool) BenefitsActivation {
+	return BenefitsActivation{
+		Activated:                    activated,
+		FeeReductionInEffect:         s.sourceType == nftType && s.sourceID == nftID,
+		EffectiveTradingFeeReduction: s.EffectiveFeeReduction,
+	}
+}
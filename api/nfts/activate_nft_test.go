@@ -0,0 +1,146 @@
+package nfts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+func TestNewBenefitsStatePicksBestReduction(t *testing.T) {
+	tiered := func(id, level int, activated bool, status string) repository.TieredNft {
+		return repository.TieredNft{ID: id, Level: level, Status: status, BenefitsActivated: activated}
+	}
+	competition := func(id, reduction int, activated bool) repository.CompetitionNft {
+		return repository.CompetitionNft{ID: id, TradingFeeReduction: reduction, BenefitsActivated: activated}
+	}
+	nftActive, nftBurned := repository.NftStatusActive, repository.NftStatusBurned
+	tests := []struct {
+		name        string
+		tiered      []repository.TieredNft
+		competition []repository.CompetitionNft
+		want        int
+		sourceType  string
+		sourceID    int
+	}{
+		{"nothing activated", []repository.TieredNft{tiered(1, 2, false, nftActive)},
+			[]repository.CompetitionNft{competition(7, 25, false)}, 0, "", 0},
+		{"tiered only", []repository.TieredNft{tiered(1, 2, true, nftActive)}, nil, 20, NftTypeTiered, 1},
+		{"competition only", nil, []repository.CompetitionNft{competition(7, 25, true)}, 25, NftTypeCompetition, 7},
+		{"competition beats tiered", []repository.TieredNft{tiered(1, 2, true, nftActive)},
+			[]repository.CompetitionNft{competition(7, 25, true)}, 25, NftTypeCompetition, 7},
+		{"tiered beats competition", []repository.TieredNft{tiered(3, 3, true, nftActive)},
+			[]repository.CompetitionNft{competition(7, 25, true)}, 30, NftTypeTiered, 3},
+		{"tiered wins a tie", []repository.TieredNft{tiered(1, 2, true, nftActive)},
+			[]repository.CompetitionNft{competition(7, 20, true)}, 20, NftTypeTiered, 1},
+		{"burned tiered ignored", []repository.TieredNft{tiered(1, 4, true, nftBurned), tiered(2, 5, false, nftActive)},
+			[]repository.CompetitionNft{competition(7, 15, true)}, 15, NftTypeCompetition, 7},
+		{"no reduction still activated", nil, []repository.CompetitionNft{competition(7, 0, true)}, 0,
+			NftTypeCompetition, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newBenefitsState(tt.tiered, tt.competition)
+			if state.EffectiveFeeReduction != tt.want || state.sourceType != tt.sourceType || state.sourceID != tt.sourceID {
+				t.Errorf("reduction %d%% from %s %d, want %d%% from %s %d", state.EffectiveFeeReduction, state.sourceType,
+					state.sourceID, tt.want, tt.sourceType, tt.sourceID)
+			}
+		})
+	}
+}
+
+func TestActivateNftAppliesBestReduction(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			user := &repository.User{Username: "holder", WalletAddr: "HolderWallet", TradingVolume: money.USDT.Whole(600000)}
+			if err := store.Users.Save(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+			levelOne := &repository.TieredNft{UserID: user.ID, Level: 1, Name: "Tech Chicken", Status: repository.NftStatusBurned,
+				MintAddress: "Level1Mint", SerialNumber: 1, MintedAt: now.Add(-2 * time.Hour), BurnedAt: &now}
+			levelTwo := &repository.TieredNft{UserID: user.ID, Level: 2, Name: "Quant Ape", Status: repository.NftStatusActive,
+				MintAddress: "Level2Mint", SerialNumber: 1, MintedAt: now.Add(-time.Hour)}
+			for _, nft := range []*repository.TieredNft{levelOne, levelTwo} {
+				if err := store.TieredNfts.Create(ctx, nft); err != nil {
+					t.Fatal(err)
+				}
+			}
+			champion := &repository.CompetitionNft{UserID: user.ID, Name: "Champion", MintAddress: "ChampionMint",
+				CompetitionID: 1, Rank: 1, TradingFeeReduction: 25, MintedAt: now}
+			runnerUp := &repository.CompetitionNft{UserID: user.ID, Name: "Runner-up", MintAddress: "RunnerUpMint",
+				CompetitionID: 2, Rank: 2, TradingFeeReduction: 15, MintedAt: now}
+			for _, nft := range []*repository.CompetitionNft{champion, runnerUp} {
+				if err := store.CompetitionNfts.Create(ctx, nft); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			steps := []struct {
+				name        string
+				nftType     string
+				nftID       int
+				code        int
+				reduction   int   // effective reduction after the step
+				inEffect    bool  // whether the activated NFT provides it
+				deactivated []int // NFTs of the same kind deactivated by the step
+			}{
+				{"Level 2", NftTypeTiered, levelTwo.ID, 200, 20, true, nil},
+				{"better competition NFT", NftTypeCompetition, champion.ID, 200, 25, true, nil},
+				{"worse competition NFT", NftTypeCompetition, runnerUp.ID, 200, 20, false, []int{champion.ID}},
+				{"burned Level 1", NftTypeTiered, levelOne.ID, 409, 0, false, nil},
+				{"unknown competition NFT", NftTypeCompetition, 999, 404, 0, false, nil},
+			}
+			for _, step := range steps {
+				resp, err := activateNftBenefits(ctx, store, user, step.nftType, step.nftID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.Code != step.code {
+					t.Fatalf("%s: code %d (%s), want %d", step.name, resp.Code, resp.Message, step.code)
+				}
+				if step.code != 200 {
+					continue
+				}
+				data := resp.Data
+				var activation BenefitsActivation
+				if data.TieredBenefits != nil {
+					activation = data.TieredBenefits.BenefitsActivation
+				} else if data.CompetitionBenefits != nil {
+					activation = data.CompetitionBenefits.BenefitsActivation
+				}
+				if data.EffectiveTradingFeeReduction != step.reduction || !activation.Activated ||
+					activation.FeeReductionInEffect != step.inEffect {
+					t.Errorf("%s: reduction %d%%, %+v; want %d%% in effect %t", step.name, data.EffectiveTradingFeeReduction,
+						activation, step.reduction, step.inEffect)
+				}
+				if len(data.DeactivatedNftIDs) != len(step.deactivated) ||
+					(len(step.deactivated) > 0 && data.DeactivatedNftIDs[0] != step.deactivated[0]) {
+					t.Errorf("%s: deactivated %v, want %v", step.name, data.DeactivatedNftIDs, step.deactivated)
+				}
+			}
+
+			// Both kinds stay activated; the NFT info shows which one's reduction applies
+			info, err := BuildUserNftInfo(ctx, store, aiquota.New(store, aiquota.DefaultSchedule()), user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			levelTwoStats := info.TieredNfts[1].BenefitsStats
+			if levelTwoStats == nil || !levelTwoStats.Activated || !levelTwoStats.FeeReductionInEffect {
+				t.Errorf("Level 2 benefits %+v, want activated and in effect", levelTwoStats)
+			}
+			for _, nft := range info.CompetitionNfts {
+				stats := nft.BenefitsStats
+				if stats.Activated != (nft.ID == int64(runnerUp.ID)) || stats.FeeReductionInEffect ||
+					stats.EffectiveTradingFeeReduction != 20 {
+					t.Errorf("competition NFT %d benefits %+v, want only the runner-up activated, not in effect", nft.ID,
+						stats.BenefitsActivation)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/shared"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+// GetAiAgentQuota returns the caller's AI agent quota for the current week
+func GetAiAgentQuota(store *repository.Store, quotas *aiquota.Service) usecase.Interactor {
+	type getAiAgentQuotaRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req getAiAgentQuotaRequest, resp *AiAgentQuotaResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = aiAgentQuotaRejected(401, err.Error(), quotas, nil)
+			return nil
+		}
+
+		quota, err := quotas.Get(ctx, user.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = AiAgentQuotaResponse{
+			Code:    200,
+			Message: "Success",
+			Data:    AiAgentQuotaData{Quota: toAiAgentQuota(quota), ResetPolicy: quotas.Schedule().String()},
+		}
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Get AI Agent Quota")
+	u.SetDescription("Get the AI agent uses granted by the active NFT level, used and left this week, and when the quota resets")
+	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// ConsumeAiAgentQuota records AI agent uses against the caller's weekly quota; the AI agent service calls it
+// with the user's token before answering
+func ConsumeAiAgentQuota(store *repository.Store, quotas *aiquota.Service) usecase.Interactor {
+	type consumeAiAgentQuotaRequest struct {
+		Authorization  string `header:"Authorization" description:"Bearer token of the user the AI agent acts for"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		ConsumeAiAgentQuotaRequest
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req consumeAiAgentQuotaRequest, resp *AiAgentQuotaResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = aiAgentQuotaRejected(401, err.Error(), quotas, nil)
+			return nil
+		}
+
+		uses := req.Uses
+		if uses == 0 {
+			uses = 1
+		}
+		quota, consumeID, err := quotas.Consume(ctx, user.ID, uses)
+		if aiAgentQuotaError(resp, err, quotas) {
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = AiAgentQuotaResponse{
+			Code:    200,
+			Message: "AI agent uses consumed",
+			Data: AiAgentQuotaData{ConsumeID: consumeID, Quota: toAiAgentQuota(quota),
+				ResetPolicy: quotas.Schedule().String()},
+		}
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Consume AI Agent Quota")
+	u.SetDescription("Atomically consume AI agent uses from the weekly quota of the active NFT level; refused with 429 when not enough uses are left")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.PermissionDenied, status.ResourceExhausted, status.Internal)
+
+	return u
+}
+
+// RefundAiAgentQuota gives back the uses of an earlier consume, e.g. when the AI agent failed to answer.
+// Only internal services may refund, and each consume is refunded at most once.
+func RefundAiAgentQuota(store *repository.Store, quotas *aiquota.Service, services *auth.ServiceCredentials) usecase.Interactor {
+	type refundAiAgentQuotaRequest struct {
+		Authorization  string `header:"Authorization" description:"Bearer service token of the calling service"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		RefundAiAgentQuotaRequest
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req refundAiAgentQuotaRequest, resp *AiAgentQuotaResponse) error {
+		if _, err := services.ExtractServiceFromAuthHeader(req.Authorization); err != nil {
+			*resp = aiAgentQuotaRejected(401, err.Error(), quotas, nil)
+			return nil
+		}
+		if req.ConsumeID == "" {
+			*resp = aiAgentQuotaRejected(400, "consumeId is required", quotas, nil)
+			return nil
+		}
+
+		quota, err := quotas.Refund(ctx, req.ConsumeID)
+		if aiAgentQuotaError(resp, err, quotas) {
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = AiAgentQuotaResponse{
+			Code:    200,
+			Message: "AI agent uses refunded",
+			Data: AiAgentQuotaData{ConsumeID: req.ConsumeID, Quota: toAiAgentQuota(quota),
+				ResetPolicy: quotas.Schedule().String()},
+		}
+		return nil
+	})
+
+	u.SetTags("Internal")
+	u.SetTitle("Refund AI Agent Quota")
+	u.SetDescription("Atomically give back the uses of an earlier consume, named by the consumeId it returned; a consume is refunded at most once")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.NotFound, status.FailedPrecondition, status.Internal)
+
+	return u
+}
+
+// ==========================================
+// AI AGENT QUOTA HELPERS
+// ==========================================
+
+// aiAgentQuotaCodes maps refused quota operations to envelope codes
+var aiAgentQuotaCodes = map[aiquota.ErrorCode]int{
+	aiquota.ErrCodeInvalidUses:     400,
+	aiquota.ErrCodeNotIncluded:     403,
+	aiquota.ErrCodeExhausted:       429,
+	aiquota.ErrCodeConsumeNotFound: 404,
+	aiquota.ErrCodeAlreadyRefunded: 409,
+}
+
+// aiAgentQuotaError writes the response of a refused quota operation and reports whether err was one
+func aiAgentQuotaError(resp *AiAgentQuotaResponse, err error, quotas *aiquota.Service) bool {
+	var quotaErr *aiquota.Error
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+	*resp = aiAgentQuotaRejected(aiAgentQuotaCodes[quotaErr.Code], quotaErr.Message, quotas, quotaErr.Quota)
+	resp.Data.ErrorCode = string(quotaErr.Code)
+	return true
+}
+
+func aiAgentQuotaRejected(code int, message string, quotas *aiquota.Service, quota *aiquota.Quota) AiAgentQuotaResponse {
+	return AiAgentQuotaResponse{
+		Code:    code,
+		Message: message,
+		Data:    AiAgentQuotaData{Quota: toAiAgentQuota(quota), ResetPolicy: quotas.Schedule().String()},
+	}
+}
+
+// toAiAgentQuota converts a quota to the API shape, nil when there is none
+func toAiAgentQuota(quota *aiquota.Quota) *AiAgentQuota {
+	if quota == nil {
+		return nil
+	}
+	return &AiAgentQuota{
+		NftLevel:             quota.Level,
+		WeeklyTotalAvailable: quota.WeeklyTotal,
+		WeeklyUsed:           quota.Used,
+		WeeklyRemaining:      quota.Remaining,
+		WeekStart:            shared.FormatTimestamp(quota.WeekStart),
+		ResetsAt:             shared.FormatTimestamp(quota.ResetsAt),
+	}
+}
@@ -0,0 +1,83 @@
+package nfts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+// CanUpgradeNFT reports whether the caller can upgrade to the next level and how far each requirement is from being met
+// (matches lastmemefi-api UserNftController.canUpgradeNFT)
+func CanUpgradeNFT(store *repository.Store) usecase.Interactor {
+	type canUpgradeNftRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
+		TargetLevel   *int   `query:"targetLevel" description:"Level to check; only the next level can be upgraded to"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req canUpgradeNftRequest, resp *CanUpgradeNftResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = CanUpgradeNftResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    CanUpgradeNftData{},
+			}
+			return nil
+		}
+
+		machine, err := lifecycle.Load(ctx, store, user.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		eligibility, err := evaluateUpgrade(ctx, store, user, machine)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		if req.TargetLevel != nil && *req.TargetLevel != eligibility.TargetLevel {
+			message := fmt.Sprintf("Level %d is not the next level; the next upgrade is to Level %d", *req.TargetLevel, eligibility.TargetLevel)
+			if eligibility.TargetLevel == 0 {
+				message = fmt.Sprintf("Level %d is not the next level; the highest level has been reached", *req.TargetLevel)
+			}
+			*resp = CanUpgradeNftResponse{
+				Code:    400,
+				Message: message,
+				Data:    CanUpgradeNftData{},
+			}
+			return nil
+		}
+
+		data := CanUpgradeNftData{
+			CanUpgrade:          eligibility.Eligible,
+			CurrentLevel:        eligibility.CurrentLevel,
+			PendingUpgrade:      eligibility.Pending,
+			Requirements:        eligibility.Requirements,
+			MissingRequirements: eligibility.Missing,
+		}
+		if eligibility.TargetLevel > 0 {
+			data.TargetLevel = &eligibility.TargetLevel
+		}
+		if eligibility.CurrentNft != nil {
+			data.CurrentUserNftID = &eligibility.CurrentNft.ID
+		}
+
+		*resp = CanUpgradeNftResponse{
+			Code:    200,
+			Message: "Upgrade eligibility checked",
+			Data:    data,
+		}
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Check NFT Upgrade Eligibility")
+	u.SetDescription("Check the trading volume, badge and burn requirements of the next NFT upgrade")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.Internal)
+
+	return u
+}
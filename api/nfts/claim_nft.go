@@ -0,0 +1,198 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/chain"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/shared"
+	"github.com/aiw3/nft-solana-api/tiers"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+// ClaimNft mints the caller's Level 1 NFT once their trading volume reaches the Level 1 threshold.
+// Higher levels are only reachable through the upgrade flow.
+func ClaimNft(store *repository.Store, chainClient chain.Client, jobs *coordinator.Coordinator) usecase.Interactor {
+	type claimNftRequest struct {
+		Authorization  string `header:"Authorization" description:"Bearer token for user authentication"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		ClaimNftRequest
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req claimNftRequest, resp *ClaimNftResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = ClaimNftResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    ClaimNftData{},
+			}
+			return nil
+		}
+
+		var result ClaimNftResponse
+		err = jobs.Do(ctx, coordinator.Job{
+			Kind:   coordinator.JobClaim,
+			UserID: user.ID,
+			Run: func(ctx context.Context) error {
+				var err error
+				result, err = claimTieredNft(ctx, store, chainClient, user, req.NftDefinitionID)
+				return err
+			},
+		})
+		if code, ok := coordinator.RejectionCode(err); ok {
+			*resp = ClaimNftResponse{
+				Code:    code,
+				Message: err.Error(),
+				Data:    ClaimNftData{},
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = result
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Claim NFT")
+	u.SetDescription("Claim the Level 1 tiered NFT once the trading volume threshold is met")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.PermissionDenied, status.AlreadyExists, status.Internal)
+
+	return u
+}
+
+// claimTieredNft mints the user's Level 1 NFT; it runs as a coordinator job holding the user's lease.
+// The serial is assigned to the claim's mint key, so a claim retried by the coordinator or by a later request
+// keeps the serial the chain minted under when it replays the first mint.
+func claimTieredNft(ctx context.Context, store *repository.Store, chainClient chain.Client, user *repository.User,
+	level int) (ClaimNftResponse, error) {
+	if level != 1 {
+		return ClaimNftResponse{
+			Code:    400,
+			Message: "Only the Level 1 NFT can be claimed; higher levels are reached by upgrading",
+			Data:    ClaimNftData{},
+		}, nil
+	}
+	if user.WalletAddr == "" {
+		return ClaimNftResponse{
+			Code:    400,
+			Message: "A connected wallet is required to claim an NFT",
+			Data:    ClaimNftData{},
+		}, nil
+	}
+
+	machine, err := lifecycle.Load(ctx, store, user.ID)
+	if err != nil {
+		return ClaimNftResponse{}, err
+	}
+	if err := machine.CanMint(level); err != nil {
+		return ClaimNftResponse{
+			Code:    409,
+			Message: err.Error(),
+			Data:    ClaimNftData{},
+		}, nil
+	}
+
+	tier, _ := tiers.Current().Tier(level)
+	if cmp, err := user.TradingVolume.Cmp(tier.TradingVolumeThreshold.Amount); err != nil {
+		return ClaimNftResponse{}, err
+	} else if cmp < 0 {
+		return ClaimNftResponse{
+			Code: 403,
+			Message: fmt.Sprintf("Trading volume of %s USDT is below the %s USDT required for Level %d",
+				user.TradingVolume, tier.TradingVolumeThreshold, tier.Level),
+			Data: ClaimNftData{},
+		}, nil
+	}
+
+	mintKey := claimMintKey(user.ID, tier.Level)
+	serial, err := store.Sequences.Assign(ctx, serialSequence(tier.Level), mintKey)
+	if err != nil {
+		return ClaimNftResponse{}, err
+	}
+	nft, minted, err := mintTieredNft(ctx, store, chainClient, machine, user, tier, serial, mintKey)
+	if errors.Is(err, repository.ErrConflict) {
+		return ClaimNftResponse{
+			Code:    409,
+			Message: "Level 1 NFT has already been claimed",
+			Data:    ClaimNftData{},
+		}, nil
+	}
+	if err != nil {
+		// The mint is idempotent by key, so the coordinator can safely run the claim again
+		return ClaimNftResponse{}, coordinator.Retry(err)
+	}
+	// Claims have no step log, so the event goes to the server log
+	log.Printf("nft lifecycle: %s", minted)
+
+	return ClaimNftResponse{
+		Code:    200,
+		Message: fmt.Sprintf("Level %d NFT claimed successfully", nft.Level),
+		Data: ClaimNftData{
+			Success:       true,
+			UserNftID:     nft.ID,
+			NftLevel:      nft.Level,
+			MintAddress:   nft.MintAddress,
+			TransactionID: nft.TransactionID,
+			ClaimedAt:     shared.FormatTimestamp(nft.MintedAt),
+			OnChainInfo: &OnChainNFTInfo{
+				MintAddress: nft.MintAddress,
+				ATAAddress:  nft.ATAAddress,
+				MetadataPDA: nft.MetadataPDA,
+				MetadataURI: nft.MetadataURI,
+				ImageURI:    nft.ImageURI,
+				Name:        onChainName(tier, nft.SerialNumber),
+				Symbol:      nftSymbol,
+			},
+		},
+	}, nil
+}
+
+// mintTieredNft mints the tier on chain under the given serial number and records the new NFT, returning
+// the minted event for the caller to deliver. The idempotency key lets a retried mint return the NFT
+// created by an earlier attempt.
+func mintTieredNft(ctx context.Context, store *repository.Store, chainClient chain.Client, machine *lifecycle.Machine,
+	user *repository.User, tier tiers.Tier, serial int, idempotencyKey string) (*repository.TieredNft,
+	lifecycle.NftMinted, error) {
+	nft, event, err := machine.Mint(tier.Level)
+	if err != nil {
+		return nil, lifecycle.NftMinted{}, err
+	}
+
+	minted, err := chainClient.Mint(ctx, chain.MintRequest{
+		IdempotencyKey: idempotencyKey,
+		OwnerWallet:    user.WalletAddr,
+		Name:           onChainName(tier, serial),
+		Symbol:         nftSymbol,
+		Level:          tier.Level,
+	})
+	if err != nil {
+		return nil, lifecycle.NftMinted{}, err
+	}
+
+	nft.SerialNumber = serial
+	nft.MintAddress = minted.MintAddress
+	nft.ATAAddress = minted.ATAAddress
+	nft.MetadataPDA = minted.MetadataPDA
+	nft.MetadataURI = minted.MetadataURI
+	nft.ImageURI = minted.ImageURI
+	nft.TransactionID = minted.TransactionID
+	if err := store.TieredNfts.Create(ctx, nft); err != nil {
+		return nil, lifecycle.NftMinted{}, err
+	}
+	return nft, event, nil
+}
+
+// claimMintKey is the chain idempotency key of a user's claim
+func claimMintKey(userID, level int) string {
+	return fmt.Sprintf("claim-%d-level-%d", userID, level)
+}
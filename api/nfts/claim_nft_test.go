@@ -0,0 +1,125 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aiw3/nft-solana-api/chain"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// lostResponseChain mints on chain but loses the response of the first lost mints, recording the names minted
+type lostResponseChain struct {
+	chain.Client
+	lost  int
+	names map[string]string // by mint address
+}
+
+func (c *lostResponseChain) Mint(ctx context.Context, req chain.MintRequest) (*chain.MintResult, error) {
+	minted, err := c.Client.Mint(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.names[minted.MintAddress]; !ok {
+		c.names[minted.MintAddress] = req.Name
+	}
+	if c.lost > 0 {
+		c.lost--
+		return nil, errors.New("rpc timeout")
+	}
+	return minted, nil
+}
+
+// claimer stores a user whose trading volume reaches the Level 1 threshold
+func claimer(t *testing.T, store *repository.Store, name string) *repository.User {
+	t.Helper()
+	user := &repository.User{Username: name, WalletAddr: name + "Wallet", TradingVolume: money.USDT.Whole(150000)}
+	if err := store.Users.Save(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestClaimMintsLevelOneOnce(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			user := claimer(t, store, "claimer")
+			client := &lostResponseChain{Client: chain.NewMockClient(), names: map[string]string{}}
+
+			resp, err := claimTieredNft(ctx, store, client, user, 1)
+			if err != nil || resp.Code != 200 {
+				t.Fatalf("claim: code %d (%s), %v; want 200", resp.Code, resp.Message, err)
+			}
+			nft, err := store.TieredNfts.GetByID(ctx, resp.Data.UserNftID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if nft.Level != 1 || nft.SerialNumber != 1 || nft.Status != repository.NftStatusActive {
+				t.Errorf("claimed Level %d #%d %s, want the active Level 1 #1", nft.Level, nft.SerialNumber, nft.Status)
+			}
+			if got := client.names[nft.MintAddress]; got != resp.Data.OnChainInfo.Name || got != "AIW3-L1-Chicken-#1" {
+				t.Errorf("minted %q, reported %q; want AIW3-L1-Chicken-#1", got, resp.Data.OnChainInfo.Name)
+			}
+
+			// The user already owns the Level 1 NFT
+			resp, err = claimTieredNft(ctx, store, client, user, 1)
+			if err != nil || resp.Code != 409 {
+				t.Errorf("second claim: code %d (%s), %v; want 409", resp.Code, resp.Message, err)
+			}
+			if len(client.names) != 1 {
+				t.Errorf("%d NFTs minted on chain, want 1", len(client.names))
+			}
+		})
+	}
+}
+
+func TestClaimRetriedInNewRequestKeepsSerial(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			user := claimer(t, store, "claimer")
+			other := claimer(t, store, "other")
+			client := &lostResponseChain{Client: chain.NewMockClient(), lost: 1, names: map[string]string{}}
+			jobs := coordinator.New(store.Leases, coordinator.Config{Workers: 1, MaxAttempts: 1})
+			t.Cleanup(func() { jobs.Stop(context.Background()) })
+			claim := func(user *repository.User) (ClaimNftResponse, error) {
+				var resp ClaimNftResponse
+				err := jobs.Do(ctx, coordinator.Job{Kind: coordinator.JobClaim, UserID: user.ID,
+					Run: func(ctx context.Context) error {
+						var err error
+						resp, err = claimTieredNft(ctx, store, client, user, 1)
+						return err
+					}})
+				return resp, err
+			}
+
+			// The first mint lands on chain but its response is lost, and another user claims in between
+			if _, err := claim(user); !coordinator.IsRetryable(err) {
+				t.Fatalf("claim with the response lost: %v, want a retryable error", err)
+			}
+			if resp, err := claim(other); err != nil || resp.Code != 200 {
+				t.Fatalf("other claim: code %d, %v; want 200", resp.Code, err)
+			}
+
+			resp, err := claim(user)
+			if err != nil || resp.Code != 200 {
+				t.Fatalf("retried claim: code %d (%s), %v; want 200", resp.Code, resp.Message, err)
+			}
+			nft, err := store.TieredNfts.GetByID(ctx, resp.Data.UserNftID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if nft.SerialNumber != 1 || client.names[nft.MintAddress] != "AIW3-L1-Chicken-#1" {
+				t.Errorf("stored #%d for the mint named %q, want the first mint's #1", nft.SerialNumber,
+					client.names[nft.MintAddress])
+			}
+			if len(client.names) != 2 {
+				t.Errorf("%d NFTs minted on chain, want one per user", len(client.names))
+			}
+		})
+	}
+}
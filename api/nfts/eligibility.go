@@ -0,0 +1,121 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/badges"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
+)
+
+// ==========================================
+// UPGRADE ELIGIBILITY ENGINE
+// ==========================================
+
+// upgradeEligibility is the verdict on a user's next upgrade. It backs both /api/user/nft/can-upgrade
+// and the upgradeEligible flag of /api/user/nft-info, so the two endpoints never disagree.
+type upgradeEligibility struct {
+	CurrentLevel int
+	CurrentNft   *repository.TieredNft // the active NFT, nil when there is none
+	TargetLevel  int                   // 0 once the highest level is reached
+	Pending      bool                  // an upgrade stopped between its burn and mint steps
+	Requirements CanUpgradeNftRequirements
+	Missing      []string // human-readable reasons the upgrade is not possible yet
+	Eligible     bool
+}
+
+// badgeRequirementType identifies the activated-badges requirement of an upgrade
+const badgeRequirementType = "activated_badges"
+
+// evaluateUpgrade checks the upgrade from the user's active NFT to the next level in the tier catalog:
+// trading volume, activated badges and whether the active NFT can be burned
+func evaluateUpgrade(ctx context.Context, store *repository.Store, user *repository.User,
+	machine *lifecycle.Machine) (*upgradeEligibility, error) {
+	userBadges, err := badges.LoadUserBadges(ctx, store, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &upgradeEligibility{
+		CurrentLevel: machine.ActiveLevel(),
+		CurrentNft:   machine.Active(),
+		TargetLevel:  machine.NextLevel(),
+		Pending:      machine.Pending(),
+		Missing:      []string{},
+	}
+	next, hasNext := tiers.Current().Tier(result.TargetLevel)
+
+	// Trading volume towards the next level
+	if hasNext {
+		if result.Requirements.TradingVolume, err = newTradingVolumeRequirement(next, user.TradingVolume); err != nil {
+			return nil, err
+		}
+	} else {
+		result.Requirements.TradingVolume = TradingVolumeRequirement{Current: user.TradingVolume}
+	}
+
+	// Activated badges, and the earned ones that could still be activated
+	requirement := badges.BadgeRequirement{
+		Type:            badgeRequirementType,
+		ActivatedBadges: []badges.Badge{},
+		AvailableBadges: []badges.Badge{},
+	}
+	for _, badge := range userBadges {
+		switch {
+		case badge.Status == string(badgelifecycle.StatusActivated):
+			requirement.ActivatedBadges = append(requirement.ActivatedBadges, badge)
+		case badge.CanActivate:
+			requirement.AvailableBadges = append(requirement.AvailableBadges, badge)
+		}
+	}
+	requirement.Activated = len(requirement.ActivatedBadges)
+	if hasNext {
+		requirement.Value = next.RequiredBadges
+		requirement.Required = next.RequiredBadges
+		requirement.Met = requirement.Activated >= requirement.Required
+		if !requirement.Met {
+			shortfall := requirement.Required - requirement.Activated
+			requirement.Shortfall = &shortfall
+		}
+	}
+	result.Requirements.Badges = requirement
+
+	// The active NFT is burned by the upgrade
+	burnErr := errors.New("An active NFT is required")
+	if result.CurrentNft != nil {
+		burnErr = machine.CanBurn(result.CurrentNft.ID)
+	}
+	result.Requirements.NftBurn = NftBurnRequirement{
+		Required:                true,
+		CurrentNftBurnable:      burnErr == nil,
+		Met:                     burnErr == nil,
+		BurnTransactionRequired: true,
+	}
+
+	switch {
+	case result.Pending:
+		result.Missing = append(result.Missing, "An unfinished upgrade must be resumed first")
+	case result.CurrentNft == nil:
+		result.Missing = append(result.Missing, "An active NFT is required; claim the Level 1 NFT first")
+	case !hasNext:
+		result.Missing = append(result.Missing, fmt.Sprintf("Level %d is the highest level", result.CurrentLevel))
+	default:
+		if burnErr != nil {
+			result.Missing = append(result.Missing, burnErr.Error())
+		}
+		if volume := result.Requirements.TradingVolume; !volume.Met {
+			result.Missing = append(result.Missing, fmt.Sprintf("Trade %s more USDT to reach the %s USDT required for Level %d",
+				*volume.Shortfall, volume.Required, next.Level))
+		}
+		if !requirement.Met {
+			result.Missing = append(result.Missing, fmt.Sprintf("Activate %d more badges; Level %d requires %d",
+				*requirement.Shortfall, next.Level, requirement.Required))
+		}
+	}
+	result.Eligible = len(result.Missing) == 0
+	return result, nil
+}
@@ -0,0 +1,190 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/shared"
+	"github.com/aiw3/nft-solana-api/tiers"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+const (
+	// MaxEntitlementLookups caps the user IDs and wallets of one batch lookup
+	MaxEntitlementLookups = 100
+	// entitlementsMaxAge is how long services may cache entitlements; activations apply within it
+	entitlementsMaxAge = 30 * time.Second
+)
+
+// GetEntitlements returns what users are entitled to right now, for internal services (trading engine,
+// AI agent, community, strategy) authenticated with a service token
+func GetEntitlements(store *repository.Store, quotas *aiquota.Service, services *auth.ServiceCredentials) usecase.Interactor {
+	type getEntitlementsRequest struct {
+		Authorization string   `header:"Authorization" description:"Bearer service token of the calling service"`
+		UserIDs       []int    `query:"userId" description:"User IDs to look up; repeat the parameter for several users"`
+		Wallets       []string `query:"wallet" description:"Wallet addresses to look up; repeat the parameter for several wallets"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req getEntitlementsRequest, resp *GetEntitlementsResponse) error {
+		if _, err := services.ExtractServiceFromAuthHeader(req.Authorization); err != nil {
+			*resp = entitlementsRejected(401, err.Error())
+			return nil
+		}
+		lookups := len(req.UserIDs) + len(req.Wallets)
+		if lookups == 0 {
+			*resp = entitlementsRejected(400, "At least one userId or wallet is required")
+			return nil
+		}
+		if lookups > MaxEntitlementLookups {
+			*resp = entitlementsRejected(400, fmt.Sprintf("At most %d users can be looked up at once", MaxEntitlementLookups))
+			return nil
+		}
+
+		data, maxAge, err := LookupEntitlements(ctx, store, quotas, req.UserIDs, req.Wallets)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		*resp = GetEntitlementsResponse{
+			Code:         200,
+			Message:      "Success",
+			Data:         data,
+			CacheControl: fmt.Sprintf("private, max-age=%d", int(maxAge/time.Second)),
+		}
+		return nil
+	})
+
+	u.SetTags("Internal")
+	u.SetTitle("Get User Entitlements")
+	u.SetDescription("Batch lookup, by user ID or wallet, of the benefits internal services enforce: effective trading fee reduction, AI agent quota left, exclusive background, strategy recommendation and priority, and community top pin. Requires a service token; responses may be cached for the max-age in Cache-Control, which never outlasts the next AI agent quota reset")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// LookupEntitlements resolves the entitlements of the users with the given IDs and wallets, in that order,
+// listing lookups matching no user in NotFound. It also returns how long the answer may be cached.
+func LookupEntitlements(ctx context.Context, store *repository.Store, quotas *aiquota.Service,
+	userIDs []int, wallets []string) (GetEntitlementsData, time.Duration, error) {
+	data := GetEntitlementsData{Entitlements: []UserEntitlements{}, NotFound: []string{}}
+	seen := map[int]bool{}
+	maxAge := entitlementsMaxAge
+	add := func(user *repository.User) error {
+		if seen[user.ID] {
+			return nil
+		}
+		seen[user.ID] = true
+		entitlements, resetsAt, err := ResolveEntitlements(ctx, store, quotas, user)
+		if err != nil {
+			return err
+		}
+		data.Entitlements = append(data.Entitlements, entitlements)
+		maxAge = min(maxAge, time.Until(resetsAt))
+		return nil
+	}
+
+	for _, id := range userIDs {
+		user, err := store.Users.GetByID(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			data.NotFound = append(data.NotFound, "userId:"+strconv.Itoa(id))
+			continue
+		}
+		if err == nil {
+			err = add(user)
+		}
+		if err != nil {
+			return GetEntitlementsData{}, 0, err
+		}
+	}
+	for _, wallet := range wallets {
+		user, err := store.Users.GetByWallet(ctx, wallet)
+		if errors.Is(err, repository.ErrNotFound) {
+			data.NotFound = append(data.NotFound, "wallet:"+wallet)
+			continue
+		}
+		if err == nil {
+			err = add(user)
+		}
+		if err != nil {
+			return GetEntitlementsData{}, 0, err
+		}
+	}
+	return data, max(maxAge, 0), nil
+}
+
+// ResolveEntitlements computes a user's entitlements from their activated NFTs and AI agent quota.
+// It also returns when the AI agent quota resets, after which the entitlements are stale.
+func ResolveEntitlements(ctx context.Context, store *repository.Store, quotas *aiquota.Service, user *repository.User) (UserEntitlements, time.Time, error) {
+	tiered, err := store.TieredNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return UserEntitlements{}, time.Time{}, err
+	}
+	competition, err := store.CompetitionNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return UserEntitlements{}, time.Time{}, err
+	}
+	quota, err := quotas.Get(ctx, user.ID)
+	if err != nil {
+		return UserEntitlements{}, time.Time{}, err
+	}
+
+	benefits := newBenefitsState(tiered, competition)
+	entitlements := UserEntitlements{
+		UserID:              user.ID,
+		WalletAddr:          user.WalletAddr,
+		ActiveNftLevel:      lifecycle.New(user.ID, tiered).ActiveLevel(),
+		TradingFeeReduction: benefits.EffectiveFeeReduction,
+		ComputedAt:          shared.GetCurrentTimestamp(),
+	}
+	if benefits.sourceType != "" {
+		entitlements.FeeReductionSource = &EntitlementSource{NftType: benefits.sourceType, UserNftID: benefits.sourceID}
+	}
+
+	// Extra benefits only apply once the NFT granting them is activated
+	for _, nft := range tiered {
+		if !nft.BenefitsActivated || nft.Status != repository.NftStatusActive {
+			continue
+		}
+		tier, ok := tiers.Current().Tier(nft.Level)
+		if !ok {
+			continue
+		}
+		extras := newTieredBenefitsStats(tier, BenefitsActivation{}).ExtraBenefits
+		entitlements.ExclusiveBackground = entitlements.ExclusiveBackground || extras.ExclusiveBackground != nil
+		entitlements.StrategyRecommendation = entitlements.StrategyRecommendation || extras.StrategyRecommendation != nil
+		entitlements.StrategyPriority = entitlements.StrategyPriority || extras.StrategyPriority != nil
+	}
+	for _, nft := range competition {
+		if nft.BenefitsActivated {
+			entitlements.CommunityTopPin = newCompetitionBenefitsStats(nft, BenefitsActivation{}).ExtraBenefits.CommunityTopPin
+		}
+	}
+
+	// The AI agent quota follows the active NFT level, as metered by the quota service
+	if quota.WeeklyTotal > 0 {
+		entitlements.AiAgent = &AiAgentEntitlement{
+			WeeklyTotalAvailable: quota.WeeklyTotal,
+			WeeklyUsed:           quota.Used,
+			WeeklyRemaining:      quota.Remaining,
+			ResetsAt:             shared.FormatTimestamp(quota.ResetsAt),
+		}
+	}
+	return entitlements, quota.ResetsAt, nil
+}
+
+func entitlementsRejected(code int, message string) GetEntitlementsResponse {
+	return GetEntitlementsResponse{
+		Code:         code,
+		Message:      message,
+		Data:         GetEntitlementsData{Entitlements: []UserEntitlements{}, NotFound: []string{}},
+		CacheControl: "no-store",
+	}
+}
@@ -0,0 +1,258 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/exchanges"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/shared"
+	"github.com/aiw3/nft-solana-api/volume"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+// BindExchangeAccount binds the caller's account on an exchange. The exchange is asked which account
+// the credentials belong to before they are sealed and stored; from then on the account's trades are
+// synced into trading volume and the fee ledger.
+func BindExchangeAccount(store *repository.Store, adapters *exchanges.Registry, sealer *exchanges.Sealer) usecase.Interactor {
+	type bindExchangeAccountRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
+		BindExchangeAccountRequest
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req bindExchangeAccountRequest, resp *ExchangeAccountResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = ExchangeAccountResponse{Code: 401, Message: err.Error()}
+			return nil
+		}
+		adapter, ok := adapters.Get(string(req.Platform))
+		if !ok {
+			*resp = ExchangeAccountResponse{Code: 400, Message: fmt.Sprintf("Accounts on %q cannot be bound", req.Platform)}
+			return nil
+		}
+
+		creds := exchanges.Credentials{APIKey: req.APIKey, APISecret: req.APISecret, Passphrase: req.Passphrase,
+			Wallet: req.WalletAddr}
+		bound, err := adapter.BindAccount(ctx, creds)
+		if errors.Is(err, exchanges.ErrUnauthorized) {
+			*resp = ExchangeAccountResponse{Code: 400, Message: fmt.Sprintf("Exchange refused the credentials: %v", err)}
+			return nil
+		}
+		if err != nil {
+			*resp = ExchangeAccountResponse{Code: 502, Message: fmt.Sprintf("Exchange unavailable: %v", err)}
+			return nil
+		}
+		sealed, err := sealer.Seal(creds)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		now := time.Now().UTC()
+		account := repository.ExchangeAccount{
+			UserID:      user.ID,
+			Platform:    adapter.Platform(),
+			AccountID:   bound.ID,
+			Credentials: sealed,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		err = store.Exchanges.Create(ctx, &account)
+		if errors.Is(err, repository.ErrConflict) {
+			*resp = ExchangeAccountResponse{
+				Code:    409,
+				Message: fmt.Sprintf("An account on %s is already bound to you, or this account is bound to another user", req.Platform),
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		*resp = ExchangeAccountResponse{
+			Code:    200,
+			Message: "Exchange account bound; its trades sync from now on",
+			Data:    toExchangeAccountInfo(account),
+		}
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Bind Exchange Account")
+	u.SetDescription("Bind the caller's OKX, Bybit, Binance, Gate or Hyperliquid account with a read-only API key (or the wallet address for Hyperliquid) so its trades count towards trading volume and the fee ledger. One account per exchange; an account can be bound to one user")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.AlreadyExists, status.Unavailable, status.Internal)
+
+	return u
+}
+
+// ListExchangeAccounts returns the caller's bound exchange accounts and their sync state
+func ListExchangeAccounts(store *repository.Store) usecase.Interactor {
+	type listExchangeAccountsRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req listExchangeAccountsRequest, resp *ListExchangeAccountsResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = ListExchangeAccountsResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    ListExchangeAccountsData{Accounts: []ExchangeAccountInfo{}},
+			}
+			return nil
+		}
+
+		accounts, err := store.Exchanges.ListByUser(ctx, user.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		data := ListExchangeAccountsData{Accounts: make([]ExchangeAccountInfo, 0, len(accounts))}
+		for _, account := range accounts {
+			data.Accounts = append(data.Accounts, toExchangeAccountInfo(account))
+		}
+		*resp = ListExchangeAccountsResponse{Code: 200, Message: "Success", Data: data}
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("List Exchange Accounts")
+	u.SetDescription("List the caller's bound exchange accounts with when each last synced and why its last sync failed, if it did")
+	u.SetExpectedErrors(status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// UnbindExchangeAccount unbinds the caller's account on an exchange and discards its credentials.
+// Trades already synced stay in trading volume and the fee ledger.
+func UnbindExchangeAccount(store *repository.Store) usecase.Interactor {
+	type unbindExchangeAccountRequest struct {
+		Authorization string          `header:"Authorization" description:"Bearer token for user authentication"`
+		Platform      TradingPlatform `path:"platform" required:"true" description:"Exchange of the account to unbind" enum:"okx,bybit,binance,gate,hyperliquid"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req unbindExchangeAccountRequest, resp *ExchangeAccountResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = ExchangeAccountResponse{Code: 401, Message: err.Error()}
+			return nil
+		}
+
+		account, err := store.Exchanges.Get(ctx, user.ID, string(req.Platform))
+		if errors.Is(err, repository.ErrNotFound) {
+			*resp = ExchangeAccountResponse{Code: 404, Message: fmt.Sprintf("No account on %s is bound", req.Platform)}
+			return nil
+		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		err = store.Exchanges.Delete(ctx, user.ID, string(req.Platform))
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return status.Wrap(err, status.Internal)
+		}
+
+		*resp = ExchangeAccountResponse{Code: 200, Message: "Exchange account unbound", Data: toExchangeAccountInfo(*account)}
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Unbind Exchange Account")
+	u.SetDescription("Unbind the caller's account on an exchange and discard its API credentials. Trades already synced keep counting")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+func toExchangeAccountInfo(account repository.ExchangeAccount) ExchangeAccountInfo {
+	info := ExchangeAccountInfo{
+		Platform:  TradingPlatform(account.Platform),
+		AccountID: account.AccountID,
+		BoundAt:   shared.FormatTimestamp(account.CreatedAt),
+		SyncError: account.SyncError,
+	}
+	if account.SyncedAt != nil {
+		syncedAt := shared.FormatTimestamp(*account.SyncedAt)
+		info.SyncedAt = &syncedAt
+	}
+	return info
+}
+
+// ==========================================
+// EXCHANGE SYNC SINK
+// ==========================================
+
+// exchangeSink records synced fills in trading volume and the fee ledger
+type exchangeSink struct {
+	store   *repository.Store
+	volumes *volume.Service
+}
+
+// ExchangeSink creates the sink exchange account syncs record fills with. Fills are converted from their
+// quote currency to USDT at its price at fill time, since trading volume and fees are kept in USDT; fills
+// whose quote currency is unknown are skipped.
+func ExchangeSink(store *repository.Store, volumes *volume.Service) exchanges.Sink {
+	return exchangeSink{store: store, volumes: volumes}
+}
+
+func (s exchangeSink) Record(ctx context.Context, account repository.ExchangeAccount, fills []exchanges.SyncedFill) error {
+	trades := make([]volume.Trade, 0, len(fills))
+	rejected := 0
+	for _, fill := range fills {
+		if fill.QuoteCurrency == "" {
+			continue
+		}
+		// Both sides of a trade may carry the same trade ID, so it is qualified by the account
+		tradeID := account.AccountID + ":" + fill.TradeID
+		trade, err := s.volumes.Normalize(ctx, volume.Trade{
+			Platform:   account.Platform,
+			TradeID:    tradeID,
+			UserID:     account.UserID,
+			Notional:   fill.Notional,
+			ExecutedAt: fill.ExecutedAt,
+		})
+		if err != nil {
+			return err
+		}
+		trades = append(trades, trade)
+		// Trading volume reports a fill there is no price for as rejected
+		if trade.Invalid != "" {
+			continue
+		}
+
+		// Maker rebates are not fees; the ledger records them as a zero rate
+		result, err := ingestTrade(ctx, s.store, ExecutedTrade{
+			Platform:       TradingPlatform(account.Platform),
+			TradeID:        tradeID,
+			UserID:         account.UserID,
+			PlatformWallet: account.AccountID,
+			Notional:       money.USDTAmount{Amount: trade.Notional},
+			FeeRate:        math.Max(fill.FeeRate, 0),
+			ExecutedAt:     fill.ExecutedAt.Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			return err
+		}
+		if result.Status == TradeRejected {
+			rejected++
+		}
+	}
+	if len(trades) == 0 {
+		return nil
+	}
+
+	result, err := s.volumes.Ingest(ctx, volume.SourceExchange, trades)
+	if err != nil {
+		return err
+	}
+	if rejected > 0 || result.Rejected > 0 {
+		log.Printf("exchange sync: %s account %s: %d fills rejected by the fee ledger, %d by trading volume",
+			account.Platform, account.AccountID, rejected, result.Rejected)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+const (
+	// maxIngestedTrades caps the trades of one ingestion request
+	maxIngestedTrades = 500
+	// maxTradeClockSkew tolerates trading services whose clocks run slightly ahead
+	maxTradeClockSkew = 5 * time.Minute
+)
+
+// Outcomes of an ingested trade
+const (
+	TradeRecorded  = "recorded"
+	TradeDuplicate = "duplicate"
+	TradeRejected  = "rejected"
+)
+
+// tradingPlatforms are the platforms trades can be reported for
+var tradingPlatforms = map[TradingPlatform]bool{
+	PlatformOKX: true, PlatformBybit: true, PlatformBinance: true, PlatformHyperliquid: true, PlatformGate: true,
+	PlatformRaydium: true, PlatformOrca: true, PlatformJupiter: true, PlatformSolana: true, PlatformOther: true,
+}
+
+// IsTradingPlatform reports whether platform is a platform trades can be reported for
+func IsTradingPlatform(platform string) bool {
+	return tradingPlatforms[TradingPlatform(platform)]
+}
+
+// IngestTrades records executed trades in the fee ledger. Trading services report each trade with the
+// platform's fee rate; the fee reduction of the user's NFT benefits at trade time gives the fee charged
+// and the fee saved. Trades already recorded are reported as duplicates, so a batch can be resent.
+func IngestTrades(store *repository.Store, services *auth.ServiceCredentials) usecase.Interactor {
+	type ingestTradesRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer service token of the calling service"`
+		IngestTradesRequest
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req ingestTradesRequest, resp *IngestTradesResponse) error {
+		if _, err := services.ExtractServiceFromAuthHeader(req.Authorization); err != nil {
+			*resp = tradesRejected(401, err.Error())
+			return nil
+		}
+		if len(req.Trades) == 0 {
+			*resp = tradesRejected(400, "At least one trade is required")
+			return nil
+		}
+		if len(req.Trades) > maxIngestedTrades {
+			*resp = tradesRejected(400, fmt.Sprintf("At most %d trades can be ingested at once", maxIngestedTrades))
+			return nil
+		}
+
+		data := IngestTradesData{Results: make([]IngestTradeResult, 0, len(req.Trades))}
+		for _, trade := range req.Trades {
+			result, err := ingestTrade(ctx, store, trade)
+			if err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			switch result.Status {
+			case TradeRecorded:
+				data.Recorded++
+			case TradeDuplicate:
+				data.Duplicates++
+			default:
+				data.Rejected++
+			}
+			data.Results = append(data.Results, result)
+		}
+
+		*resp = IngestTradesResponse{
+			Code:    200,
+			Message: fmt.Sprintf("%d trades recorded, %d duplicates, %d rejected", data.Recorded, data.Duplicates, data.Rejected),
+			Data:    data,
+		}
+		return nil
+	})
+
+	u.SetTags("Internal")
+	u.SetTitle("Ingest Executed Trades")
+	u.SetDescription("Record executed trades in the append-only fee ledger with the fee charged after the NFT fee reduction in effect at trade time and the fee saved. Requires a service token; each trade is recorded once per platform and trade ID")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// ingestTrade validates a trade and appends its ledger entry
+func ingestTrade(ctx context.Context, store *repository.Store, trade ExecutedTrade) (IngestTradeResult, error) {
+	result := IngestTradeResult{Platform: trade.Platform, TradeID: trade.TradeID, Status: TradeRejected}
+	reject := func(reason string) (IngestTradeResult, error) {
+		result.Reason = reason
+		return result, nil
+	}
+
+	switch {
+	case !tradingPlatforms[trade.Platform]:
+		return reject(fmt.Sprintf("Unknown platform %q", trade.Platform))
+	case trade.TradeID == "" || len(trade.TradeID) > 128:
+		return reject("tradeId must be 1 to 128 characters")
+	case len(trade.PlatformWallet) > 64:
+		return reject("platformWallet must be at most 64 characters")
+	case trade.Notional.Sign() <= 0:
+		return reject("notional must be positive")
+	case trade.Notional.Currency() != money.USDT:
+		// The ledger sums fees per user, which only adds up when every entry is in USDT
+		return reject("notional must be USDT")
+	case !(trade.FeeRate >= 0 && trade.FeeRate <= 0.1):
+		return reject("feeRate must be between 0 and 0.1")
+	}
+	executedAt, err := time.Parse(time.RFC3339, trade.ExecutedAt)
+	if err != nil {
+		return reject("executedAt must be an RFC 3339 timestamp")
+	}
+	if executedAt.After(time.Now().Add(maxTradeClockSkew)) {
+		return reject("executedAt is in the future")
+	}
+
+	var user *repository.User
+	switch {
+	case trade.UserID != 0:
+		user, err = store.Users.GetByID(ctx, trade.UserID)
+	case trade.WalletAddr != "":
+		user, err = store.Users.GetByWallet(ctx, trade.WalletAddr)
+	default:
+		return reject("userId or walletAddr is required")
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return reject("User not found")
+	}
+	if err != nil {
+		return result, err
+	}
+	if trade.UserID != 0 && trade.WalletAddr != "" && trade.WalletAddr != user.WalletAddr {
+		return reject("walletAddr does not belong to userId")
+	}
+
+	tiered, err := store.TieredNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return result, err
+	}
+	competition, err := store.CompetitionNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return result, err
+	}
+	intervals, err := store.BenefitsHistory.ListByUser(ctx, user.ID)
+	if err != nil {
+		return result, err
+	}
+	benefits := newBenefitsStateAt(tiered, competition, intervals, executedAt)
+
+	entry, err := newFeeSaving(trade.Notional.Amount, trade.FeeRate, benefits)
+	if err != nil {
+		return result, err
+	}
+	entry.UserID = user.ID
+	entry.Platform = string(trade.Platform)
+	entry.TradeID = trade.TradeID
+	entry.PlatformWallet = trade.PlatformWallet
+	if entry.PlatformWallet == "" {
+		entry.PlatformWallet = user.WalletAddr
+	}
+	entry.ExecutedAt = executedAt.UTC()
+	entry.RecordedAt = time.Now().UTC()
+
+	err = store.FeeLedger.Append(ctx, &entry)
+	if errors.Is(err, repository.ErrConflict) {
+		result.Status = TradeDuplicate
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Status = TradeRecorded
+	result.FeeReduction = entry.FeeReduction
+	result.UndiscountedFee = entry.UndiscountedFee
+	result.ChargedFee = entry.ChargedFee
+	result.FeeSaved = entry.FeeSaved
+	return result, nil
+}
+
+// ==========================================
+// FEE DISCOUNT CALCULATOR
+// ==========================================
+
+// newFeeSaving computes the fee of a trade with and without the effective fee reduction.
+// Fees are rounded half up to 8 decimals, the precision USDT is settled in; the saving is their exact
+// difference, so charged plus saved always adds up to the undiscounted fee.
+func newFeeSaving(notional money.Amount, feeRate float64, benefits benefitsState) (repository.FeeSaving, error) {
+	undiscounted := notional.Mul(feeRate, money.HalfUp)
+	charged := undiscounted.MulFrac(int64(100-benefits.EffectiveFeeReduction), 100, money.HalfUp)
+	saved, err := undiscounted.Sub(charged)
+	if err != nil {
+		return repository.FeeSaving{}, err
+	}
+	return repository.FeeSaving{
+		Notional:        notional,
+		FeeRate:         feeRate,
+		UndiscountedFee: undiscounted,
+		ChargedFee:      charged,
+		FeeSaved:        saved,
+		FeeReduction:    benefits.EffectiveFeeReduction,
+		NftType:         benefits.sourceType,
+		NftID:           benefits.sourceID,
+	}, nil
+}
+
+// newFeeSavedBasicInfo aggregates the user's fee ledger per platform account
+func newFeeSavedBasicInfo(totals []repository.PlatformFeeSaved) (FeeSavedBasicInfo, error) {
+	info := FeeSavedBasicInfo{PlatformBasics: make([]PlatformFeeBasic, 0, len(totals))}
+	for _, total := range totals {
+		saved, err := info.TotalSaved.Add(total.FeeSaved)
+		if err != nil {
+			return FeeSavedBasicInfo{}, err
+		}
+		info.TotalSaved = saved
+		info.PlatformBasics = append(info.PlatformBasics, PlatformFeeBasic{
+			Platform:      TradingPlatform(total.Platform),
+			WalletAddress: total.PlatformWallet,
+			FeeSaved:      total.FeeSaved,
+		})
+	}
+	return info, nil
+}
+
+func tradesRejected(code int, message string) IngestTradesResponse {
+	return IngestTradesResponse{
+		Code:    code,
+		Message: message,
+		Data:    IngestTradesData{Results: []IngestTradeResult{}},
+	}
+}
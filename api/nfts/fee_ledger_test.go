@@ -0,0 +1,166 @@
+package nfts
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+	"github.com/aiw3/nft-solana-api/repository/sqlite"
+)
+
+func testStores(t *testing.T) map[string]*repository.Store {
+	t.Helper()
+	db, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "aiw3.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]*repository.Store{"memory": memory.NewStore(), "sqlite": sqlite.NewStore(db)}
+}
+
+// TestIngestTradeUsesActivationAtExecution reports trades after the NFT that was activated when they
+// executed has been deactivated and burned
+func TestIngestTradeUsesActivationAtExecution(t *testing.T) {
+	ctx := context.Background()
+	mintedAt := time.Now().Add(-72 * time.Hour).UTC().Truncate(time.Second)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			user := &repository.User{Username: "trader", WalletAddr: "TraderWallet"}
+			if err := store.Users.Save(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+			nft := &repository.TieredNft{UserID: user.ID, Level: 2, Name: "Quant Ape", Status: repository.NftStatusActive,
+				MintAddress: "QuantApeMint", BenefitsActivated: true, MintedAt: mintedAt}
+			if err := store.TieredNfts.Create(ctx, nft); err != nil {
+				t.Fatal(err)
+			}
+
+			// Deactivated a day after the mint and activated again the next day
+			nft.BenefitsActivated = false
+			if err := store.TieredNfts.Update(ctx, nft); err != nil {
+				t.Fatal(err)
+			}
+			deactivatedAt := time.Now().UTC()
+			nft.BenefitsActivated = true
+			if err := store.TieredNfts.Update(ctx, nft); err != nil {
+				t.Fatal(err)
+			}
+
+			// Burned by an upgrade before the trades are reported
+			held, err := store.TieredNfts.ListByUser(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			burned, _, err := lifecycle.New(user.ID, held).Burn(nft.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.TieredNfts.Update(ctx, burned); err != nil {
+				t.Fatal(err)
+			}
+
+			intervals, err := store.BenefitsHistory.ListByUser(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(intervals) != 2 || intervals[1].DeactivatedAt == nil {
+				t.Fatalf("intervals %+v, want two closed intervals", intervals)
+			}
+			if !intervals[0].ActivatedAt.Equal(mintedAt) || intervals[0].DeactivatedAt.After(deactivatedAt) {
+				t.Errorf("first interval %+v, want it from the mint to the deactivation", intervals[0])
+			}
+
+			tests := []struct {
+				tradeID    string
+				executedAt time.Time
+				want       int
+			}{
+				{"before-mint", mintedAt.Add(-time.Hour), 0},
+				{"while-activated", mintedAt.Add(time.Hour), 20},
+			}
+			for _, tt := range tests {
+				result, err := ingestTrade(ctx, store, ExecutedTrade{Platform: "okx", TradeID: tt.tradeID,
+					UserID: user.ID, Notional: money.USDTAmount{Amount: money.USDT.Whole(1000)}, FeeRate: 0.001,
+					ExecutedAt: tt.executedAt.Format(time.RFC3339)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Status != TradeRecorded || result.FeeReduction != tt.want {
+					t.Errorf("%s: %s with %d%% reduction (%s), want recorded with %d%%", tt.tradeID, result.Status,
+						result.FeeReduction, result.Reason, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIngestTradeRejectsNotionalsNotInUSDT(t *testing.T) {
+	ctx := context.Background()
+	executedAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			user := &repository.User{Username: "trader", WalletAddr: "TraderWallet"}
+			if err := store.Users.Save(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+			trades := []ExecutedTrade{
+				{Platform: "okx", TradeID: "okx-usdt", UserID: user.ID,
+					Notional: money.USDTAmount{Amount: money.USDT.Whole(1000)}, FeeRate: 0.001, ExecutedAt: executedAt},
+				// Decoding refuses a SOL notional; one set in code reaches ingestTrade
+				{Platform: "okx", TradeID: "okx-sol", UserID: user.ID,
+					Notional: money.USDTAmount{Amount: money.Currency("SOL").Whole(5)}, FeeRate: 0.001, ExecutedAt: executedAt},
+			}
+			for _, trade := range trades {
+				if _, err := ingestTrade(ctx, store, trade); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := ingestTrade(ctx, store, trades[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != TradeRejected || result.Reason != "notional must be USDT" {
+				t.Errorf("SOL notional %s (%s), want rejected as not USDT", result.Status, result.Reason)
+			}
+			// The user's totals still add up
+			totals, err := store.FeeLedger.TotalsByUser(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(totals) != 1 || totals[0].FeeSaved.Currency() != money.USDT {
+				t.Errorf("totals %+v, want the USDT trade only", totals)
+			}
+		})
+	}
+}
+
+func TestNewBenefitsStateAtFollowsIntervals(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 3, 1, hour, 0, 0, 0, time.UTC) }
+	deactivatedAt := at(12)
+	tiered := []repository.TieredNft{{ID: 1, Level: 2, Status: repository.NftStatusActive, MintedAt: at(0)}}
+	competition := []repository.CompetitionNft{{ID: 7, TradingFeeReduction: 25, MintedAt: at(0)}}
+	intervals := []repository.BenefitsInterval{
+		{NftType: NftTypeTiered, NftID: 1, ActivatedAt: at(1), DeactivatedAt: &deactivatedAt},
+		{NftType: NftTypeCompetition, NftID: 7, ActivatedAt: at(18)},
+	}
+	tests := []struct {
+		hour int
+		want int
+	}{
+		{0, 0},   // held, not activated yet
+		{1, 20},  // tiered activated
+		{12, 0},  // tiered deactivated at that moment
+		{18, 25}, // competition activated
+	}
+	for _, tt := range tests {
+		if got := newBenefitsStateAt(tiered, competition, intervals, at(tt.hour)).EffectiveFeeReduction; got != tt.want {
+			t.Errorf("at %02d:00: reduction %d%%, want %d%%", tt.hour, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,14 @@ package nfts
 import (
 	"context"
 
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/badges"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
 )
@@ -20,33 +28,42 @@ type GetUserNftInfoResponse struct {
 type GetUserNftInfoData struct {
 	UserBasicInfo UserBasicInfo `json:"userBasicInfo" description:"Basic user profile information including wallet address and NFT avatar"`
 
-	TieredNfts      []TieredNft      `json:"tieredNfts" description:"List of tiered NFT levels (1-5). For owned/previously owned levels: includes full NFT details, benefits, and requirements. For unowned levels: includes only basic info (level, name) and badge management data, without exposing benefits, requirements, or NFT artwork." minItems:"5" maxItems:"5"`
+	TieredNfts      []TieredNft      `json:"tieredNfts" description:"List of tiered NFT levels, one entry per level in the tier catalog. For owned/previously owned levels: includes full NFT details, benefits, and requirements. For unowned levels: includes only basic info (level, name) and badge management data, without exposing benefits, requirements, or NFT artwork."`
 	CompetitionNfts []CompetitionNft `json:"competitionNfts" description:"List of competition NFTs currently owned by the user"`
 
 	// Fee Saved Information - Basic fee savings info (for detailed analytics, use dedicated endpoint)
 	FeeSavedInfo FeeSavedBasicInfo `json:"feeSavedInfo" description:"Basic fee savings information showing total saved and platform breakdown"`
 
-	ActiveNftLevel int  `json:"activeNftLevel" example:"3" description:"Level of currently active NFT (1-5), 0 if no active NFT" minimum:"0" maximum:"5"`
-	NextNftLevel   *int `json:"nextNftLevel,omitempty" example:"4" description:"Target level for the next upgrade; null if not applicable (e.g., current level is 5 )" minimum:"1" maximum:"5"`
+	ActiveNftLevel int  `json:"activeNftLevel" example:"3" description:"Level of currently active NFT (1-5), 0 if no active NFT" minimum:"0"`
+	NextNftLevel   *int `json:"nextNftLevel,omitempty" example:"4" description:"Target level for the next upgrade; null if not applicable (e.g., current level is 5 )" minimum:"1"`
 
 	// NFT Upgrade Information
 	UpgradeEligible bool `json:"upgradeEligible" example:"true" description:"Whether user can upgrade to the next NFT level (business requirements met: trading volume threshold and required activated badges)"`
 	PendingUpgrade  bool `json:"pendingUpgrade" example:"false" description:"Whether user has a pending upgrade (NFT burned but higher level not yet minted). When true, user can resume/retry the upgrade process"`
 }
 
-func GetUserNftInfo() usecase.Interactor {
+// GetUserNftInfo returns the caller's tiered and competition NFTs together with upgrade state
+func GetUserNftInfo(store *repository.Store, quotas *aiquota.Service) usecase.Interactor {
 	u := usecase.NewInteractor(func(ctx context.Context, req GetUserNftInfoRequest, resp *GetUserNftInfoResponse) error {
-		// TODO: Implement user extraction and NFT info logic
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = GetUserNftInfoResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    GetUserNftInfoData{},
+			}
+			return nil
+		}
+
+		data, err := BuildUserNftInfo(ctx, store, quotas, user)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
 		*resp = GetUserNftInfoResponse{
 			Code:    200,
 			Message: "Success",
-			Data: GetUserNftInfoData{
-				TieredNfts:      []TieredNft{},      // Empty array instead of nil
-				CompetitionNfts: []CompetitionNft{}, // Empty array instead of nil
-				ActiveNftLevel:  0,
-				UpgradeEligible: false,
-				PendingUpgrade:  false,
-			},
+			Data:    data,
 		}
 		return nil
 	})
@@ -58,3 +75,223 @@ func GetUserNftInfo() usecase.Interactor {
 
 	return u
 }
+
+// ==========================================
+// HELPER FUNCTIONS FOR NFT INFO
+// ==========================================
+
+// BuildUserNftInfo composes the NFT info response from the user's stored NFTs and badges
+func BuildUserNftInfo(ctx context.Context, store *repository.Store, quotas *aiquota.Service,
+	user *repository.User) (GetUserNftInfoData, error) {
+	owned, err := store.TieredNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return GetUserNftInfoData{}, err
+	}
+	badgesByLevel, activatedBadges, err := loadLevelBadges(ctx, store, user.ID)
+	if err != nil {
+		return GetUserNftInfoData{}, err
+	}
+	competitionRecords, err := store.CompetitionNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return GetUserNftInfoData{}, err
+	}
+	benefits := newBenefitsState(owned, competitionRecords)
+	feeTotals, err := store.FeeLedger.TotalsByUser(ctx, user.ID)
+	if err != nil {
+		return GetUserNftInfoData{}, err
+	}
+
+	// Latest mint per level; a level can only be re-minted after its previous instance was burned
+	ownedByLevel := map[int]*repository.TieredNft{}
+	for i := range owned {
+		nft := &owned[i]
+		if prev := ownedByLevel[nft.Level]; prev == nil || nft.MintedAt.After(prev.MintedAt) {
+			ownedByLevel[nft.Level] = nft
+		}
+	}
+	machine := lifecycle.New(user.ID, owned)
+
+	data := GetUserNftInfoData{
+		UserBasicInfo: UserBasicInfo{
+			UserID:       int64(user.ID),
+			WalletAddr:   user.WalletAddr,
+			NftAvatarURL: user.ProfilePhotoURL,
+		},
+		CompetitionNfts: toCompetitionNfts(competitionRecords, benefits),
+	}
+	if data.FeeSavedInfo, err = newFeeSavedBasicInfo(feeTotals); err != nil {
+		return GetUserNftInfoData{}, err
+	}
+	catalog := tiers.Current()
+	data.ActiveNftLevel = machine.ActiveLevel()
+	if tier, ok := catalog.Tier(data.ActiveNftLevel); ok {
+		data.UserBasicInfo.NftAvatarURL = tierImageURL(tier)
+	}
+
+	// The burn step of an upgrade finished but the mint did not
+	data.PendingUpgrade = machine.Pending()
+
+	eligibility, err := evaluateUpgrade(ctx, store, user, machine)
+	if err != nil {
+		return GetUserNftInfoData{}, err
+	}
+	data.UpgradeEligible = eligibility.Eligible
+	next, hasNext := catalog.Tier(eligibility.TargetLevel)
+	if hasNext {
+		data.NextNftLevel = &next.Level
+	}
+
+	data.TieredNfts = make([]TieredNft, 0, catalog.MaxLevel())
+	for _, tier := range catalog.Tiers {
+		entry := newTieredNft(tier)
+		if levelBadges := badgesByLevel[tier.Level]; levelBadges != nil {
+			entry.Badges = levelBadges
+		}
+
+		if nft := ownedByLevel[tier.Level]; nft != nil {
+			if err := applyOwnedNft(&entry, tier, nft, user.TradingVolume, activatedBadges, benefits); err != nil {
+				return GetUserNftInfoData{}, err
+			}
+		} else if hasNext && tier.Level == next.Level &&
+			eligibility.Requirements.TradingVolume.Met && eligibility.Requirements.Badges.Met {
+			entry.Status = string(lifecycle.StatusUnlockable)
+		}
+		data.TieredNfts = append(data.TieredNfts, entry)
+	}
+
+	// AI agent uses this week count against the active level's quota
+	for i := range data.TieredNfts {
+		entry := &data.TieredNfts[i]
+		if entry.Level != data.ActiveNftLevel || entry.BenefitsStats == nil || entry.BenefitsStats.ExtraBenefits.AiAgent == nil {
+			continue
+		}
+		quota, err := quotas.Get(ctx, user.ID)
+		if err != nil {
+			return GetUserNftInfoData{}, err
+		}
+		entry.BenefitsStats.ExtraBenefits.AiAgent.WeeklyUsed = quota.Used
+	}
+
+	return data, nil
+}
+
+// applyOwnedNft fills in the details only exposed for owned or previously owned levels
+func applyOwnedNft(entry *TieredNft, tier tiers.Tier, nft *repository.TieredNft, tradingVolume money.Amount,
+	activatedBadges int, benefits benefitsState) error {
+	id := nft.ID
+	mintedAt := nft.MintedAt
+	imgURL := tierImageURL(tier)
+	levelImgURL := tierLevelImageURL(tier.Level)
+
+	entry.ID = &id
+	entry.NftImgURL = &imgURL
+	entry.NftLevelImgURL = &levelImgURL
+	entry.MintedAt = &mintedAt
+	entry.Status = string(lifecycle.StatusOf(nft))
+	entry.BurnedAt = nft.BurnedAt
+	entry.OnChainInfo = &OnChainNFTInfo{
+		MintAddress: nft.MintAddress,
+		ATAAddress:  nft.ATAAddress,
+		MetadataPDA: nft.MetadataPDA,
+		MetadataURI: nft.MetadataURI,
+		ImageURI:    nft.ImageURI,
+		Name:        nft.Name,
+		Symbol:      nftSymbol,
+	}
+	if nft.SerialNumber > 0 {
+		entry.OnChainInfo.Name = onChainName(tier, nft.SerialNumber)
+	}
+
+	volume, err := newTradingVolumeRequirement(tier, tradingVolume)
+	if err != nil {
+		return err
+	}
+	entry.TradingVolumeThreshold = &volume.Required
+	entry.TradingVolumeQualified = &volume.Current
+	entry.TradingVolumeProgress = &volume.Percentage
+
+	required := tier.RequiredBadges
+	current := activatedBadges
+	progress := 100.0
+	if required > 0 {
+		progress = float64(current) / float64(required) * 100
+	}
+	entry.ActivatedBadgesRequired = &required
+	entry.ActivatedBadgesCurrent = &current
+	entry.ActivatedBadgesProgress = &progress
+
+	stats := newTieredBenefitsStats(tier, benefits.activation(NftTypeTiered, nft.ID, nft.BenefitsActivated))
+	entry.BenefitsStats = &stats
+	return nil
+}
+
+// loadLevelBadges groups the user's badges by NFT level and counts the activated ones
+func loadLevelBadges(ctx context.Context, store *repository.Store, userID int) (map[int][]Badge, int, error) {
+	defs, err := store.Badges.ListDefinitions(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	owned, err := store.Badges.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	ownedByID := make(map[int]repository.UserBadge, len(owned))
+	for _, badge := range owned {
+		ownedByID[badge.BadgeID] = badge
+	}
+
+	byLevel := map[int][]Badge{}
+	activated := 0
+	for _, def := range defs {
+		userBadge, ok := ownedByID[def.ID]
+		if !ok {
+			continue
+		}
+		earned, err := badges.EarnedDefinition(ctx, store, &def, &userBadge)
+		if err != nil {
+			return nil, 0, err
+		}
+		def := *earned
+		badge := Badge{
+			ID:     def.ID,
+			Name:   def.Name,
+			Url:    def.IconURL,
+			Status: string(badgelifecycle.StatusOf(&userBadge)),
+		}
+		if badge.Status == string(badgelifecycle.StatusActivated) {
+			activated++
+		}
+		byLevel[def.NftLevel] = append(byLevel[def.NftLevel], badge)
+	}
+	return byLevel, activated, nil
+}
+
+// toCompetitionNfts converts the user's competition NFTs to the API shape
+func toCompetitionNfts(records []repository.CompetitionNft, benefits benefitsState) []CompetitionNft {
+	result := make([]CompetitionNft, 0, len(records))
+	for _, nft := range records {
+		result = append(result, CompetitionNft{
+			ID:        int64(nft.ID),
+			Name:      nft.Name,
+			NftImgURL: nft.ImageURL,
+			MintedAt:  nft.MintedAt,
+			OnChainInfo: OnChainNFTInfo{
+				MintAddress: nft.MintAddress,
+				ATAAddress:  nft.ATAAddress,
+				MetadataPDA: nft.MetadataPDA,
+				MetadataURI: nft.MetadataURI,
+				ImageURI:    nft.ImageURI,
+				Name:        nft.Name,
+				Symbol:      nftSymbol,
+			},
+			CompetitionInfo: CompetitionInfo{
+				ID:   int64(nft.CompetitionID),
+				Name: nft.CompetitionName,
+				Type: nft.CompetitionType,
+				Rank: nft.Rank,
+			},
+			BenefitsStats: newCompetitionBenefitsStats(nft, benefits.activation(NftTypeCompetition, nft.ID, nft.BenefitsActivated)),
+		})
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package nfts
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+const (
+	locked     = string(lifecycle.StatusLocked)
+	unlockable = string(lifecycle.StatusUnlockable)
+	active     = string(lifecycle.StatusActive)
+	burned     = string(lifecycle.StatusBurned)
+)
+
+func TestUserNftInfoStatusPerLevel(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		volume  int64 // USDT traded
+		level   int   // the highest NFT minted, burned below it
+		burned  bool  // the highest NFT was burned too, by an upgrade that stopped before its mint
+		badges  int   // activated Level 2 badges
+		want    []string
+		pending bool
+	}{
+		{"new user", 0, 0, false, 0, []string{locked, locked, locked, locked, locked}, false},
+		{"Level 1 claimable", 150000, 0, false, 0, []string{unlockable, locked, locked, locked, locked}, false},
+		{"Level 1 held", 150000, 1, false, 0, []string{active, locked, locked, locked, locked}, false},
+		{"badges short", 600000, 1, false, 1, []string{active, locked, locked, locked, locked}, false},
+		{"trading volume short", 400000, 1, false, 2, []string{active, locked, locked, locked, locked}, false},
+		{"Level 2 upgradable", 600000, 1, false, 2, []string{active, unlockable, locked, locked, locked}, false},
+		{"Level 2 held", 600000, 2, false, 2, []string{burned, active, locked, locked, locked}, false},
+		{"upgrade stopped after the burn", 600000, 1, true, 2, []string{burned, unlockable, locked, locked, locked}, true},
+	}
+	for _, tt := range tests {
+		for name, store := range testStores(t) {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				user := &repository.User{Username: "holder", WalletAddr: "HolderWallet",
+					TradingVolume: money.USDT.Whole(tt.volume)}
+				if err := store.Users.Save(ctx, user); err != nil {
+					t.Fatal(err)
+				}
+				now := time.Now()
+				for level := 1; level <= tt.level; level++ {
+					nft := &repository.TieredNft{UserID: user.ID, Level: level, Name: "NFT", Status: repository.NftStatusActive,
+						MintAddress: fmt.Sprintf("Level%dMint", level), SerialNumber: 1, MintedAt: now.Add(-time.Hour)}
+					if level < tt.level || tt.burned {
+						nft.Status, nft.BurnedAt = repository.NftStatusBurned, &now
+					}
+					if err := store.TieredNfts.Create(ctx, nft); err != nil {
+						t.Fatal(err)
+					}
+				}
+				for id := 1; id <= tt.badges; id++ {
+					def := &repository.BadgeDefinition{ID: id, NftLevel: 2, Name: fmt.Sprintf("Badge %d", id)}
+					if err := store.Badges.SaveDefinition(ctx, def); err != nil {
+						t.Fatal(err)
+					}
+					badge := &repository.UserBadge{UserID: user.ID, BadgeID: id, Status: string(badgelifecycle.StatusActivated),
+						ActivatedAt: &now}
+					if err := store.Badges.SaveUserBadge(ctx, badge); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				data, err := BuildUserNftInfo(ctx, store, aiquota.New(store, aiquota.DefaultSchedule()), user)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(data.TieredNfts) != len(tt.want) {
+					t.Fatalf("%d levels, want %d", len(data.TieredNfts), len(tt.want))
+				}
+				for i, entry := range data.TieredNfts {
+					if entry.Level != i+1 || entry.Status != tt.want[i] {
+						t.Errorf("level %d: %s, want %s", entry.Level, entry.Status, tt.want[i])
+					}
+					minted := entry.Level <= tt.level
+					details := entry.ID != nil && entry.NftImgURL != nil && entry.MintedAt != nil &&
+						entry.OnChainInfo != nil && entry.TradingVolumeThreshold != nil &&
+						entry.ActivatedBadgesRequired != nil && entry.BenefitsStats != nil
+					hidden := entry.ID == nil && entry.NftImgURL == nil && entry.NftLevelImgURL == nil &&
+						entry.MintedAt == nil && entry.OnChainInfo == nil && entry.TradingVolumeThreshold == nil &&
+						entry.ActivatedBadgesRequired == nil && entry.BenefitsStats == nil
+					if minted && !details {
+						t.Errorf("level %d: minted but its details are missing: %+v", entry.Level, entry)
+					}
+					if !minted && !hidden {
+						t.Errorf("level %d: never minted but details are exposed: %+v", entry.Level, entry)
+					}
+					if (entry.BurnedAt != nil) != (entry.Status == burned) {
+						t.Errorf("level %d: %s with burned at %v", entry.Level, entry.Status, entry.BurnedAt)
+					}
+				}
+				// Badges show under their level whether or not it was minted
+				if got := len(data.TieredNfts[1].Badges); got != tt.badges {
+					t.Errorf("%d Level 2 badges, want %d", got, tt.badges)
+				}
+				if data.PendingUpgrade != tt.pending {
+					t.Errorf("pending upgrade %t, want %t", data.PendingUpgrade, tt.pending)
+				}
+				wantActive := 0
+				if tt.level > 0 && !tt.burned {
+					wantActive = tt.level
+				}
+				if data.ActiveNftLevel != wantActive {
+					t.Errorf("active level %d, want %d", data.ActiveNftLevel, wantActive)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package nfts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
+)
+
+// nftSymbol is the collection symbol shared by all AIW3 NFTs
+const nftSymbol = "AIW3"
+
+// ==========================================
+// TIER CATALOG MAPPING
+// ==========================================
+
+// newTieredNft returns the catalog entry for a level as a locked, unowned NFT
+func newTieredNft(tier tiers.Tier) TieredNft {
+	return TieredNft{
+		Level:  tier.Level,
+		Name:   tier.Name,
+		Status: string(lifecycle.StatusLocked),
+		Badges: []Badge{},
+	}
+}
+
+// newTieredBenefitsStats returns the benefits a tier grants; extra benefits are only set when the tier includes them
+func newTieredBenefitsStats(tier tiers.Tier, activation BenefitsActivation) TieredBenefitsStats {
+	stats := TieredBenefitsStats{
+		BenefitsActivation:  activation,
+		TradingFeeReduction: tier.TradingFeeReduction,
+	}
+	if tier.AiAgentWeeklyQuota > 0 {
+		stats.ExtraBenefits.AiAgent = &AiAgentBenefit{WeeklyTotalAvailable: tier.AiAgentWeeklyQuota}
+	}
+	if tier.ExclusiveBackground {
+		stats.ExtraBenefits.ExclusiveBackground = boolPtr(true)
+	}
+	if tier.StrategyRecommendation {
+		stats.ExtraBenefits.StrategyRecommendation = boolPtr(true)
+	}
+	if tier.StrategyPriority {
+		stats.ExtraBenefits.StrategyPriority = boolPtr(true)
+	}
+	return stats
+}
+
+// newCompetitionBenefitsStats returns the benefits of a competition NFT; community top pin is always included
+func newCompetitionBenefitsStats(nft repository.CompetitionNft, activation BenefitsActivation) CompetitionBenefitsStats {
+	return CompetitionBenefitsStats{
+		BenefitsActivation:  activation,
+		TradingFeeReduction: nft.TradingFeeReduction,
+		ExtraBenefits:       ExtraCompetitionNFTBenefitItems{CommunityTopPin: true},
+	}
+}
+
+// newTradingVolumeRequirement compares a user's trading volume against a tier's threshold
+func newTradingVolumeRequirement(tier tiers.Tier, current money.Amount) (TradingVolumeRequirement, error) {
+	threshold := tier.TradingVolumeThreshold.Amount
+	cmp, err := current.Cmp(threshold)
+	if err != nil {
+		return TradingVolumeRequirement{}, err
+	}
+	requirement := TradingVolumeRequirement{
+		Required:   threshold,
+		Current:    current,
+		Met:        cmp >= 0,
+		Percentage: 100,
+	}
+	if threshold.Sign() > 0 {
+		ratio, err := current.Ratio(threshold)
+		if err != nil {
+			return TradingVolumeRequirement{}, err
+		}
+		requirement.Percentage = ratio * 100
+	}
+	if !requirement.Met {
+		shortfall, err := threshold.Sub(current)
+		if err != nil {
+			return TradingVolumeRequirement{}, err
+		}
+		requirement.Shortfall = &shortfall
+	}
+	return requirement, nil
+}
+
+// onChainName returns the on-chain NFT name, e.g. AIW3-L3-Hunter-#1234
+func onChainName(tier tiers.Tier, serial int) string {
+	return fmt.Sprintf("%s-L%d-%s-#%d", nftSymbol, tier.Level, tier.ShortName, serial)
+}
+
+// serialSequence names the counter that numbers a level's NFTs
+func serialSequence(level int) string {
+	return fmt.Sprintf("tiered_nft_level_%d", level)
+}
+
+// tierImageURL returns the CDN artwork URL for a tier, e.g. .../tiered/on-chain-hunter-level3.jpg
+func tierImageURL(tier tiers.Tier) string {
+	slug := strings.ToLower(strings.Join(strings.Fields(tier.Name), "-"))
+	return fmt.Sprintf("https://cdn.aiw3.com/nfts/tiered/%s-level%d.jpg", slug, tier.Level)
+}
+
+// tierLevelImageURL returns the CDN URL of a tier's level indicator image
+func tierLevelImageURL(level int) string {
+	return fmt.Sprintf("https://cdn.aiw3.com/nfts/badges/level%d-badge.png", level)
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
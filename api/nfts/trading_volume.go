@@ -0,0 +1,108 @@
+package nfts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/dex"
+	"github.com/aiw3/nft-solana-api/volume"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+// IngestVolumeWebhook records the trades a platform posts for trading volume. Each trade is added to its
+// user's daily rollup for the platform, and the qualifying volume tier decisions use is refreshed.
+// Trades already recorded are reported as duplicates, so a platform can redeliver a payload.
+func IngestVolumeWebhook(volumes *volume.Service, services *auth.ServiceCredentials) usecase.Interactor {
+	type ingestVolumeWebhookRequest struct {
+		Authorization string          `header:"Authorization" description:"Bearer service token of the calling service"`
+		Platform      TradingPlatform `path:"platform" required:"true" description:"Trading platform the trades were executed on" enum:"okx,bybit,binance,hyperliquid,gate,raydium,orca,jupiter,solana,other"`
+		volume.WebhookPayload
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req ingestVolumeWebhookRequest, resp *IngestVolumeResponse) error {
+		if _, err := services.ExtractServiceFromAuthHeader(req.Authorization); err != nil {
+			*resp = volumeRejected(401, err.Error())
+			return nil
+		}
+		if !IsTradingPlatform(string(req.Platform)) {
+			*resp = volumeRejected(400, fmt.Sprintf("Unknown platform %q", req.Platform))
+			return nil
+		}
+		if len(req.Trades) > maxIngestedTrades {
+			*resp = volumeRejected(400, fmt.Sprintf("At most %d trades can be ingested at once", maxIngestedTrades))
+			return nil
+		}
+
+		result, err := volumes.Ingest(ctx, volume.SourceWebhook, req.WebhookPayload.Decode(string(req.Platform)))
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = IngestVolumeResponse{
+			Code:    200,
+			Message: fmt.Sprintf("%d trades recorded, %d duplicates, %d rejected", result.Recorded, result.Duplicates, result.Rejected),
+			Data:    result,
+		}
+		return nil
+	})
+
+	u.SetTags("Internal")
+	u.SetTitle("Ingest Trading Volume Webhook")
+	u.SetDescription("Record trades a platform delivers for trading volume. Requires a service token; each trade is recorded once per platform and trade ID and counts towards the user's qualifying volume")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+// IngestSolanaTransactions records the DEX swaps in confirmed Solana transactions for trading volume.
+// Raydium, Orca and Jupiter have no trade feed, so the indexer watching users' wallets posts their
+// transactions; each swap is credited to its signer's user on the DEX it went through. Transactions that
+// are not swaps, or swaps with no leg to value them by, are reported as rejected.
+func IngestSolanaTransactions(volumes *volume.Service, services *auth.ServiceCredentials) usecase.Interactor {
+	type ingestSolanaTransactionsRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer service token of the calling service"`
+		IngestSolanaTransactionsRequest
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req ingestSolanaTransactionsRequest, resp *IngestVolumeResponse) error {
+		if _, err := services.ExtractServiceFromAuthHeader(req.Authorization); err != nil {
+			*resp = volumeRejected(401, err.Error())
+			return nil
+		}
+		if len(req.Transactions) > maxIngestedTrades {
+			*resp = volumeRejected(400, fmt.Sprintf("At most %d transactions can be ingested at once", maxIngestedTrades))
+			return nil
+		}
+
+		transactions := make([][]byte, 0, len(req.Transactions))
+		for _, transaction := range req.Transactions {
+			transactions = append(transactions, transaction)
+		}
+		result, err := volumes.Ingest(ctx, volume.SourceOnChain, dex.Trades(transactions))
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = IngestVolumeResponse{
+			Code:    200,
+			Message: fmt.Sprintf("%d swaps recorded, %d duplicates, %d rejected", result.Recorded, result.Duplicates, result.Rejected),
+			Data:    result,
+		}
+		return nil
+	})
+
+	u.SetTags("Internal")
+	u.SetTitle("Ingest Solana Transactions")
+	u.SetDescription("Record the Raydium, Orca and Jupiter swaps in confirmed Solana transactions for trading volume. Requires a service token; each swap is valued by its USDT, USDC, SOL, JUP or BONK leg, converted to USDT at the asset's price at block time, credited to the user of the signing wallet and recorded once per signature")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.Internal)
+
+	return u
+}
+
+func volumeRejected(code int, message string) IngestVolumeResponse {
+	return IngestVolumeResponse{
+		Code:    code,
+		Message: message,
+		Data:    volume.Result{Outcomes: []volume.Outcome{}},
+	}
+}
@@ -1,9 +1,13 @@
 package nfts
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/aiw3/nft-solana-api/badges"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/volume"
 )
 
 // ==========================================
@@ -17,6 +21,9 @@ type UserBasicInfo struct {
 
 type BenefitsActivation struct {
 	Activated bool `json:"benefitsActivated" example:"true" description:"Whether the benefits are currently activated by the user. Note: Activation only affects the use of benefits, won't affect NFT upgrade eligibility"`
+	// Fee reductions of activated NFTs do not add up: the best one applies
+	FeeReductionInEffect         bool `json:"feeReductionInEffect" example:"true" description:"Whether this NFT's trading fee reduction is the one applied to the user's trades (the best among activated NFTs)"`
+	EffectiveTradingFeeReduction int  `json:"effectiveTradingFeeReduction" example:"25" description:"Trading fee reduction percentage applied to the user's trades: the best among the activated tiered and competition NFTs, not their sum" minimum:"0" maximum:"100"`
 	// ActivatedAt *time.Time `json:"benefitsActivatedAt,omitempty" example:"2024-02-15T10:30:00.000Z" description:"Timestamp when benefits were activated; null if not activated" format:"date-time"`
 }
 
@@ -41,7 +48,7 @@ type Badge struct {
 	ID     int    `json:"id" example:"1" description:"Unique identifier for the badge"`
 	Name   string `json:"name" example:"Trading Master" description:"Display name of the badge" maxLength:"100"`
 	Url    string `json:"url" example:"https://cdn.aiw3.com/badges/trading-master.png" description:"CDN URL for the badge image (for frontend display)" format:"uri"`
-	Status string `json:"status" example:"activated" description:"Current status of the badge" enum:"[Available,Activated,Consumed]"`
+	Status string `json:"status" example:"activated" description:"Current status of the badge" enum:"[owned,activated,consumed]"`
 }
 
 // TieredBenefitsStats represents benefits available for a tiered NFT level
@@ -49,7 +56,7 @@ type TieredBenefitsStats struct {
 	BenefitsActivation
 
 	// Common benefits (available at multiple levels)
-	TradingFeeReduction int `json:"tradingFeeReduction" example:"25" description:"Trading fee reduction percentage for each NFT level" minimum:"0" maximum:"100" enum:"[10,20,30,40,55]"`
+	TradingFeeReduction int `json:"tradingFeeReduction" example:"25" description:"Trading fee reduction percentage for each NFT level" minimum:"0" maximum:"100"`
 
 	// Level-specific benefits (grouped for frontend clarity)
 	ExtraBenefits ExtraTieredNFTBenefitItems `json:"extraBenefits" description:"Level-specific benefits available for this tiered NFT"`
@@ -85,7 +92,7 @@ type ActiveExtraCompetitionNFTBenefitItems struct {
 // TieredNft represents NFT level information
 type TieredNft struct {
 	ID             *int       `json:"id,omitempty" example:"3" description:"Unique database identifier for this NFT instance. Only present when NFT has been minted (status: 'Active' or 'Burned')"`
-	Level          int        `json:"level" example:"3" description:"NFT tier level (1-5), higher levels provide better benefits" minimum:"1" maximum:"5"`
+	Level          int        `json:"level" example:"3" description:"NFT tier level (1-5), higher levels provide better benefits" minimum:"1"`
 	Name           string     `json:"name" example:"On-chain Hunter" description:"Display name for this NFT tier (L1=Tech Chicken, L2=Quant Ape, L3=On-chain Hunter, L4=Alpha Alchemist, L5=Quantum Alchemist)" maxLength:"100"`
 	NftImgURL      *string    `json:"nftImgUrl,omitempty" example:"https://cdn.aiw3.com/nfts/tiered/on-chain-hunter-level3.jpg" description:"CDN URL for optimized NFT artwork image (for frontend display). Only present for owned/previously owned NFT levels" format:"uri"`
 	NftLevelImgURL *string    `json:"nftLevelImgUrl,omitempty" example:"https://cdn.aiw3.com/nfts/badges/level3-badge.png" description:"CDN URL for optimized level-specific badge/indicator image (for frontend display). Only present for owned/previously owned NFT levels" format:"uri"`
@@ -97,12 +104,12 @@ type TieredNft struct {
 	OnChainInfo *OnChainNFTInfo `json:"onChainInfo,omitempty" description:"On-chain NFT information including Solana addresses and IPFS storage details. Only present when NFT has been minted (status: 'Active' or 'Burned')"`
 
 	// Trading Volume Requirements (only present for owned/previously owned levels)
-	TradingVolumeThreshold *int     `json:"tradingVolumeThreshold,omitempty" example:"1000000" description:"Trading volume threshold to unlock this level in USDT. Only present for owned/previously owned NFT levels" minimum:"0"`
-	TradingVolumeQualified *int     `json:"tradingVolumeQualified,omitempty" example:"1050000" description:"User's current trading volume that qualified/qualifies for this level in USDT. Only present for owned/previously owned NFT levels" minimum:"0"`
-	TradingVolumeProgress  *float64 `json:"tradingVolumeProgress,omitempty" example:"105.0" description:"Progress towards meeting the trading volume threshold as percentage. Only present for owned/previously owned NFT levels" minimum:"0"`
+	TradingVolumeThreshold *money.Amount `json:"tradingVolumeThreshold,omitempty" example:"1000000" description:"Trading volume threshold to unlock this level in USDT. Only present for owned/previously owned NFT levels" minimum:"0"`
+	TradingVolumeQualified *money.Amount `json:"tradingVolumeQualified,omitempty" example:"1050000" description:"User's current trading volume that qualified/qualifies for this level in USDT. Only present for owned/previously owned NFT levels" minimum:"0"`
+	TradingVolumeProgress  *float64      `json:"tradingVolumeProgress,omitempty" example:"105.0" description:"Progress towards meeting the trading volume threshold as percentage. Only present for owned/previously owned NFT levels" minimum:"0"`
 
 	// Badge Requirements (only present for owned/previously owned levels)
-	ActivatedBadgesRequired *int     `json:"activatedBadgesRequired,omitempty" example:"2" description:"Number of badges required to be activated to unlock this NFT level. Only present for owned/previously owned NFT levels" minimum:"0" enum:"[0,2,4,5,6]"`
+	ActivatedBadgesRequired *int     `json:"activatedBadgesRequired,omitempty" example:"2" description:"Number of badges required to be activated to unlock this NFT level. Only present for owned/previously owned NFT levels" minimum:"0"`
 	ActivatedBadgesCurrent  *int     `json:"activatedBadgesCurrent,omitempty" example:"1" description:"Number of badges user has currently activated toward this level. Only present for owned/previously owned NFT levels" minimum:"0"`
 	ActivatedBadgesProgress *float64 `json:"activatedBadgesProgress,omitempty" example:"50.0" description:"Progress toward meeting badge requirements as percentage. Only present for owned/previously owned NFT levels" minimum:"0"`
 
@@ -176,22 +183,96 @@ const (
 type PlatformFeeBasic struct {
 	Platform      TradingPlatform `json:"platform" example:"okx" description:"Trading platform identifier" enum:"[okx,bybit,binance,hyperliquid,gate,raydium,orca,jupiter,solana,other]"`
 	WalletAddress string          `json:"walletAddress" example:"9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM" description:"Platform-specific wallet address" minLength:"32" maxLength:"64"`
-	FeeSaved      float64         `json:"feeSaved" example:"900.00" description:"Total fee amount saved on this platform in USDT" minimum:"0"`
+	FeeSaved      money.Amount    `json:"feeSaved" example:"900" description:"Total fee amount saved on this platform in USDT" minimum:"0"`
 }
 
 // FeeSavedBasicInfo represents basic fee savings information for NFT info endpoint
 type FeeSavedBasicInfo struct {
-	TotalSaved     float64            `json:"totalSaved" example:"1250.75" description:"Total fee savings across all platforms in USDT" minimum:"0"`
+	TotalSaved     money.Amount       `json:"totalSaved" example:"1250.75" description:"Total fee savings across all platforms in USDT" minimum:"0"`
 	PlatformBasics []PlatformFeeBasic `json:"platformBasics" description:"Basic fee savings per platform (wallet address + saved amount only)"`
 }
 
+// ExecutedTrade is a trade reported by a trading service for the fee ledger
+type ExecutedTrade struct {
+	Platform       TradingPlatform  `json:"platform" required:"true" example:"okx" description:"Trading platform the trade was executed on" enum:"okx,bybit,binance,hyperliquid,gate,raydium,orca,jupiter,solana,other"`
+	TradeID        string           `json:"tradeId" required:"true" example:"okx-8837461234" description:"The platform's trade identifier; a trade is recorded once per platform" maxLength:"128"`
+	UserID         int              `json:"userId,omitempty" example:"12345" description:"User who made the trade; either userId or walletAddr is required"`
+	WalletAddr     string           `json:"walletAddr,omitempty" example:"9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM" description:"AIW3 wallet of the user who made the trade"`
+	PlatformWallet string           `json:"platformWallet,omitempty" example:"0x8f2a4c1e9b7d3a5f6e0c2b4d8a1f3e5c7b9d0a2e" description:"Account or wallet the trade was made from on the platform; defaults to the user's AIW3 wallet" maxLength:"64"`
+	Notional       money.USDTAmount `json:"notional" required:"true" example:"25000" description:"Trade value in USDT, a number or a decimal string" exclusiveMinimum:"0"`
+	FeeRate        float64          `json:"feeRate" required:"true" example:"0.0005" description:"The platform's fee rate before any NFT fee reduction" minimum:"0" maximum:"0.1"`
+	ExecutedAt     string           `json:"executedAt" required:"true" example:"2024-02-20T14:30:00Z" description:"When the trade was executed; the fee reduction in effect then applies" format:"date-time"`
+}
+
+// IngestTradesRequest represents a batch of executed trades
+type IngestTradesRequest struct {
+	Trades []ExecutedTrade `json:"trades" required:"true" description:"Executed trades, at most 500 per request" maxItems:"500"`
+}
+
+// IngestTradesResponse represents wrapped trade ingestion Response
+type IngestTradesResponse struct {
+	Code    int              `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message string           `json:"message" example:"3 trades recorded" description:"Human-readable message describing the operation result"`
+	Data    IngestTradesData `json:"data" description:"Outcome of each trade"`
+}
+
+// IngestTradesData represents the outcome of a batch of executed trades
+type IngestTradesData struct {
+	Recorded   int                 `json:"recorded" example:"3" description:"Trades added to the fee ledger"`
+	Duplicates int                 `json:"duplicates" example:"0" description:"Trades already in the fee ledger, left unchanged"`
+	Rejected   int                 `json:"rejected" example:"0" description:"Trades refused as invalid"`
+	Results    []IngestTradeResult `json:"results" description:"One result per trade, in request order"`
+}
+
+// IngestTradeResult represents the outcome of one executed trade
+type IngestTradeResult struct {
+	Platform        TradingPlatform `json:"platform" example:"okx"`
+	TradeID         string          `json:"tradeId" example:"okx-8837461234"`
+	Status          string          `json:"status" example:"recorded" enum:"recorded,duplicate,rejected"`
+	Reason          string          `json:"reason,omitempty" description:"Why the trade was rejected"`
+	FeeReduction    int             `json:"feeReduction" example:"25" description:"Fee reduction percentage in effect at trade time" minimum:"0" maximum:"100"`
+	UndiscountedFee money.Amount    `json:"undiscountedFee" example:"12.5" description:"Fee without the NFT fee reduction in USDT"`
+	ChargedFee      money.Amount    `json:"chargedFee" example:"9.375" description:"Fee charged with the NFT fee reduction in USDT"`
+	FeeSaved        money.Amount    `json:"feeSaved" example:"3.125" description:"Fee saved in USDT"`
+}
+
+// IngestSolanaTransactionsRequest represents confirmed Solana transactions posted for DEX swap attribution
+type IngestSolanaTransactionsRequest struct {
+	Transactions []json.RawMessage `json:"transactions" required:"true" minItems:"1" description:"Confirmed transactions as returned by getTransaction (json or jsonParsed encoding, maxSupportedTransactionVersion 0), bare or in their JSON-RPC response"`
+}
+
+// IngestVolumeResponse represents wrapped trading volume ingestion Response
+type IngestVolumeResponse struct {
+	Code    int           `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message string        `json:"message" example:"2 trades recorded, 1 duplicates, 0 rejected" description:"Human-readable message describing the operation result"`
+	Data    volume.Result `json:"data" description:"Outcome of each trade, in payload order"`
+}
+
 // ==========================================
 // NFT ACTION REQUEST/RESPONSE TYPES
 // ==========================================
 
 // ClaimNftRequest represents NFT claim Request
 type ClaimNftRequest struct {
-	NftDefinitionID int `json:"nft_definition_id" example:"3" description:"NFT definition ID to claim (corresponds to tier level)" minimum:"1" maximum:"5" required:"true"`
+	NftDefinitionID int `json:"nft_definition_id" example:"3" description:"NFT definition ID to claim (corresponds to tier level)" minimum:"1" required:"true"`
+}
+
+// ClaimNftResponse represents wrapped NFT claim Response
+type ClaimNftResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    ClaimNftData `json:"data"`
+}
+
+// ClaimNftData represents a successfully claimed NFT
+type ClaimNftData struct {
+	Success       bool            `json:"success" example:"true" description:"Whether the NFT was minted and recorded"`
+	UserNftID     int             `json:"userNftId,omitempty" example:"456" description:"Database identifier of the new NFT instance"`
+	NftLevel      int             `json:"nftLevel,omitempty" example:"1" description:"Level of the claimed NFT"`
+	MintAddress   string          `json:"mintAddress,omitempty" example:"7XzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM" description:"Solana mint address of the new NFT"`
+	TransactionID string          `json:"transactionId,omitempty" example:"5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW" description:"Solana signature of the mint transaction"`
+	ClaimedAt     string          `json:"claimedAt,omitempty" example:"2024-01-15T23:59:59.000Z" description:"Timestamp when the NFT was minted" format:"date-time"`
+	OnChainInfo   *OnChainNFTInfo `json:"onChainInfo,omitempty" description:"On-chain addresses and IPFS URIs of the minted NFT"`
 }
 
 // UpgradeNftRequest represents NFT upgrade Request
@@ -200,9 +281,90 @@ type UpgradeNftRequest struct {
 	BadgeIDs  []int `json:"badge_ids" description:"Array of badge IDs to consume for the upgrade" required:"true"`
 }
 
+// UpgradeNftResponse represents wrapped NFT upgrade Response
+type UpgradeNftResponse struct {
+	Code    int            `json:"code"`
+	Message string         `json:"message"`
+	Data    UpgradeNftData `json:"data"`
+}
+
+// UpgradeNftData represents the state of an upgrade after the request was processed
+type UpgradeNftData struct {
+	Success bool            `json:"success" example:"true" description:"Whether the upgrade completed"`
+	Upgrade *UpgradeRequest `json:"upgrade,omitempty" description:"Upgrade request with its step log; absent when the request was rejected before an upgrade started"`
+}
+
+// UpgradeRequest represents a burn-then-mint upgrade and the steps completed so far
+type UpgradeRequest struct {
+	ID                int           `json:"upgradeRequestId" example:"42" description:"Upgrade request identifier"`
+	Status            string        `json:"status" example:"completed" description:"Saga status" enum:"[pending,burn_confirmed,mint_confirmed,completed,failed]"`
+	FromLevel         int           `json:"fromLevel" example:"2" description:"Level of the burned NFT"`
+	ToLevel           int           `json:"toLevel" example:"3" description:"Level of the minted NFT"`
+	OldUserNftID      int           `json:"oldUserNftId" example:"123" description:"NFT instance burned by the upgrade"`
+	NewUserNftID      int           `json:"newUserNftId,omitempty" example:"124" description:"NFT instance minted by the upgrade, once minted"`
+	NewMintAddress    string        `json:"newMintAddress,omitempty" example:"7XzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM" description:"Solana mint address of the new NFT, once minted"`
+	BurnTransactionID string        `json:"burnTransactionId,omitempty" description:"Solana signature of the burn transaction, once burned"`
+	MintTransactionID string        `json:"mintTransactionId,omitempty" description:"Solana signature of the mint transaction, once minted"`
+	BadgeIDs          []int         `json:"badgeIds" description:"Activated badges consumed when the upgrade completes"`
+	RetryCount        int           `json:"retryCount" example:"0" description:"Number of times a failed upgrade was resumed" minimum:"0"`
+	CanResume         bool          `json:"canResume" example:"false" description:"Whether submitting the upgrade again resumes this request from its last completed step"`
+	ErrorMessage      string        `json:"errorMessage,omitempty" description:"Reason of the last failure"`
+	Steps             []UpgradeStep `json:"steps" description:"Durable step log in the order the steps completed"`
+}
+
+// UpgradeStep represents one entry of an upgrade's step log
+type UpgradeStep struct {
+	Step   string `json:"step" example:"burn_confirmed" description:"Step name" enum:"[started,resumed,nft_burned,burn_confirmed,nft_minted,mint_confirmed,badges_consumed,completed,failed]"`
+	Detail string `json:"detail,omitempty" description:"Step details such as transaction signatures or the failure reason"`
+	At     string `json:"at" example:"2024-02-20T14:30:00.000Z" description:"When the step was recorded" format:"date-time"`
+}
+
+// NFT kinds whose benefits can be activated
+const (
+	NftTypeTiered      = repository.NftTypeTiered
+	NftTypeCompetition = repository.NftTypeCompetition
+)
+
 // ActivateNftRequest represents NFT activation Request
 type ActivateNftRequest struct {
-	UserNftID int `json:"user_nft_id" example:"456" description:"NFT instance ID to activate/equip" minimum:"1" required:"true"`
+	UserNftID int    `json:"user_nft_id" example:"456" description:"NFT instance ID to activate/equip" minimum:"1" required:"true"`
+	NftType   string `json:"nft_type,omitempty" example:"tiered" description:"Kind of NFT to activate; defaults to tiered" enum:"tiered,competition"`
+}
+
+// ActivateNftResponse represents NFT activation Response
+type ActivateNftResponse struct {
+	Code    int             `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message string          `json:"message" example:"NFT benefits activated" description:"Human-readable message describing the operation result"`
+	Data    ActivateNftData `json:"data" description:"Activated NFT and the resulting benefits"`
+}
+
+// ActivateNftData represents NFT activation data
+type ActivateNftData struct {
+	Success                      bool                      `json:"success" example:"true" description:"Whether the NFT's benefits are activated"`
+	NftType                      string                    `json:"nftType" example:"tiered" description:"Kind of the activated NFT" enum:"tiered,competition"`
+	UserNftID                    int                       `json:"userNftId,omitempty" example:"456" description:"Activated NFT instance ID"`
+	DeactivatedNftIDs            []int                     `json:"deactivatedNftIds" description:"NFTs of the same kind whose benefits were deactivated by this activation"`
+	EffectiveTradingFeeReduction int                       `json:"effectiveTradingFeeReduction" example:"25" description:"Trading fee reduction percentage now applied: the best among activated NFTs" minimum:"0" maximum:"100"`
+	TieredBenefits               *TieredBenefitsStats      `json:"tieredBenefits,omitempty" description:"Resulting benefits of the activated tiered NFT"`
+	CompetitionBenefits          *CompetitionBenefitsStats `json:"competitionBenefits,omitempty" description:"Resulting benefits of the activated competition NFT"`
+}
+
+// CanUpgradeNftResponse represents upgrade eligibility Response
+type CanUpgradeNftResponse struct {
+	Code    int               `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message string            `json:"message" example:"Upgrade eligibility checked" description:"Human-readable message describing the operation result"`
+	Data    CanUpgradeNftData `json:"data" description:"Upgrade eligibility with every requirement"`
+}
+
+// CanUpgradeNftData represents upgrade eligibility data
+type CanUpgradeNftData struct {
+	CanUpgrade          bool                      `json:"canUpgrade" example:"false" description:"Whether every upgrade requirement is met; matches upgradeEligible in /api/user/nft-info"`
+	CurrentLevel        int                       `json:"currentLevel" example:"1" description:"Level of the active NFT, 0 if no active NFT" minimum:"0"`
+	TargetLevel         *int                      `json:"targetLevel,omitempty" example:"2" description:"Level the next upgrade mints; null once the highest level is reached" minimum:"1"`
+	CurrentUserNftID    *int                      `json:"currentUserNftId,omitempty" example:"123" description:"Active NFT instance burned by the upgrade"`
+	PendingUpgrade      bool                      `json:"pendingUpgrade" example:"false" description:"Whether an unfinished upgrade has to be resumed first"`
+	Requirements        CanUpgradeNftRequirements `json:"requirements" description:"Trading volume, badge and burn requirements of the next upgrade"`
+	MissingRequirements []string                  `json:"missingRequirements" description:"Reasons the upgrade is not possible yet; empty when canUpgrade is true"`
 }
 
 // CanUpgradeNftRequirements represents upgrade requirements
@@ -214,11 +376,11 @@ type CanUpgradeNftRequirements struct {
 
 // TradingVolumeRequirement represents trading volume requirements
 type TradingVolumeRequirement struct {
-	Required   int     `json:"required" example:"2500000" description:"Required trading volume in USDT" minimum:"0"`
-	Current    int     `json:"current" example:"2850000" description:"User's current trading volume in USDT" minimum:"0"`
-	Met        bool    `json:"met" example:"true" description:"Whether the trading volume requirement is met"`
-	Percentage float64 `json:"percentage" example:"114.0" description:"Percentage of requirement met (can exceed 100%)" minimum:"0"`
-	Shortfall  *int    `json:"shortfall,omitempty" example:"null" description:"Amount still needed in USDT (null if requirement met)" minimum:"0"`
+	Required   money.Amount  `json:"required" example:"2500000" description:"Required trading volume in USDT" minimum:"0"`
+	Current    money.Amount  `json:"current" example:"2850000" description:"User's current trading volume in USDT" minimum:"0"`
+	Met        bool          `json:"met" example:"true" description:"Whether the trading volume requirement is met"`
+	Percentage float64       `json:"percentage" example:"114.0" description:"Percentage of requirement met (can exceed 100%)" minimum:"0"`
+	Shortfall  *money.Amount `json:"shortfall,omitempty" example:"null" description:"Amount still needed in USDT (null if requirement met)" minimum:"0"`
 }
 
 // NftBurnRequirement represents NFT burn requirements
@@ -229,6 +391,129 @@ type NftBurnRequirement struct {
 	BurnTransactionRequired bool `json:"burnTransactionRequired" example:"true" description:"Whether a blockchain burn transaction is required"`
 }
 
+// ConsumeAiAgentQuotaRequest represents an AI agent quota consume Request
+type ConsumeAiAgentQuotaRequest struct {
+	Uses int `json:"uses,omitempty" example:"1" description:"AI agent uses to consume; defaults to 1" minimum:"1"`
+}
+
+// RefundAiAgentQuotaRequest represents an AI agent quota refund Request
+type RefundAiAgentQuotaRequest struct {
+	ConsumeID string `json:"consumeId" required:"true" example:"9f86d081884c7d659a2feaa0c55ad015" description:"ID of the consume to give back, as returned by the consume; each consume is refunded at most once" minLength:"1"`
+}
+
+// AiAgentQuotaResponse represents wrapped AI agent quota Response
+type AiAgentQuotaResponse struct {
+	Code    int              `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message string           `json:"message" example:"Success" description:"Human-readable message describing the operation result"`
+	Data    AiAgentQuotaData `json:"data" description:"AI agent quota after the operation"`
+}
+
+// AiAgentQuotaData represents the AI agent quota of the current week
+type AiAgentQuotaData struct {
+	ErrorCode   string        `json:"errorCode,omitempty" example:"AI_QUOTA_EXHAUSTED" description:"Reason the operation was refused" enum:"AI_QUOTA_INVALID_USES,AI_AGENT_NOT_INCLUDED,AI_QUOTA_EXHAUSTED,AI_QUOTA_CONSUME_NOT_FOUND,AI_QUOTA_ALREADY_REFUNDED"`
+	ConsumeID   string        `json:"consumeId,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015" description:"ID of the consume, which a refund names; set by a successful consume"`
+	Quota       *AiAgentQuota `json:"quota,omitempty" description:"Quota of the week the operation applied to"`
+	ResetPolicy string        `json:"resetPolicy" example:"Monday 00:00 UTC" description:"Weekly moment quotas reset"`
+}
+
+// AiAgentQuota represents a user's AI agent uses in one quota week
+type AiAgentQuota struct {
+	NftLevel             int    `json:"nftLevel" example:"3" description:"Active NFT level the quota comes from, 0 without an active NFT" minimum:"0"`
+	WeeklyTotalAvailable int    `json:"weeklyTotalAvailable" example:"30" description:"AI agent uses per week granted by the NFT level" minimum:"0"`
+	WeeklyUsed           int    `json:"weeklyUsed" example:"5" description:"Uses consumed this week, net of refunds" minimum:"0"`
+	WeeklyRemaining      int    `json:"weeklyRemaining" example:"25" description:"Uses left this week" minimum:"0"`
+	WeekStart            string `json:"weekStart" example:"2024-02-19T00:00:00Z" description:"When the quota week started" format:"date-time"`
+	ResetsAt             string `json:"resetsAt" example:"2024-02-26T00:00:00Z" description:"When the quota resets" format:"date-time"`
+}
+
+// ==========================================
+// INTERNAL ENTITLEMENT TYPES
+// ==========================================
+
+// GetEntitlementsResponse represents wrapped entitlements lookup Response
+type GetEntitlementsResponse struct {
+	Code         int                 `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message      string              `json:"message" example:"Success" description:"Human-readable message describing the operation result"`
+	Data         GetEntitlementsData `json:"data" description:"Entitlements of the users found"`
+	CacheControl string              `header:"Cache-Control" json:"-" description:"How long the answer may be cached; no-store for rejected lookups"`
+}
+
+// GetEntitlementsData represents the entitlements of a batch of users
+type GetEntitlementsData struct {
+	Entitlements []UserEntitlements `json:"entitlements" description:"One entry per user found, in the order requested (user IDs first, then wallets); duplicates are returned once"`
+	NotFound     []string           `json:"notFound" description:"Lookups matching no user, as userId:<id> or wallet:<address>"`
+}
+
+// UserEntitlements represents what a user is entitled to right now
+type UserEntitlements struct {
+	UserID                 int                 `json:"userId" example:"12345"`
+	WalletAddr             string              `json:"walletAddr" example:"7XzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"`
+	ActiveNftLevel         int                 `json:"activeNftLevel" example:"3" description:"Level of the active tiered NFT, 0 without one" minimum:"0"`
+	TradingFeeReduction    int                 `json:"tradingFeeReduction" example:"30" description:"Effective trading fee reduction percentage: the best among activated NFTs, 0 when none is activated" minimum:"0" maximum:"100"`
+	FeeReductionSource     *EntitlementSource  `json:"feeReductionSource,omitempty" description:"Activated NFT providing the fee reduction"`
+	AiAgent                *AiAgentEntitlement `json:"aiAgent,omitempty" description:"AI agent quota of the active NFT level; absent when the level includes no AI agent access"`
+	ExclusiveBackground    bool                `json:"exclusiveBackground" description:"Whether the activated tiered NFT grants the exclusive background"`
+	StrategyRecommendation bool                `json:"strategyRecommendation" description:"Whether the activated tiered NFT grants strategy recommendations"`
+	StrategyPriority       bool                `json:"strategyPriority" description:"Whether the activated tiered NFT grants strategy priority"`
+	CommunityTopPin        bool                `json:"communityTopPin" description:"Whether an activated competition NFT grants the community top pin"`
+	ComputedAt             string              `json:"computedAt" example:"2024-02-20T14:30:00Z" description:"When the entitlements were computed" format:"date-time"`
+}
+
+// EntitlementSource identifies the NFT an entitlement comes from
+type EntitlementSource struct {
+	NftType   string `json:"nftType" example:"tiered" enum:"tiered,competition"`
+	UserNftID int    `json:"userNftId" example:"456"`
+}
+
+// AiAgentEntitlement represents the AI agent uses a user has left this quota week
+type AiAgentEntitlement struct {
+	WeeklyTotalAvailable int    `json:"weeklyTotalAvailable" example:"30" minimum:"0"`
+	WeeklyUsed           int    `json:"weeklyUsed" example:"5" minimum:"0"`
+	WeeklyRemaining      int    `json:"weeklyRemaining" example:"25" minimum:"0"`
+	ResetsAt             string `json:"resetsAt" example:"2024-02-26T00:00:00Z" format:"date-time"`
+}
+
+// ==========================================
+// EXCHANGE ACCOUNT TYPES
+// ==========================================
+
+// BindExchangeAccountRequest represents the credentials an exchange account is bound with
+type BindExchangeAccountRequest struct {
+	Platform   TradingPlatform `json:"platform" required:"true" example:"okx" description:"Exchange the account is on" enum:"okx,bybit,binance,gate,hyperliquid"`
+	APIKey     string          `json:"apiKey,omitempty" example:"5f1c9a3e-7b2d-4e8f-a6c0-91d3b7e2f458" description:"Read-only API key; required except for Hyperliquid" maxLength:"256"`
+	APISecret  string          `json:"apiSecret,omitempty" description:"Secret of the API key; required except for Hyperliquid" maxLength:"256"`
+	Passphrase string          `json:"passphrase,omitempty" description:"Passphrase of the API key; OKX only" maxLength:"256"`
+	WalletAddr string          `json:"walletAddr,omitempty" example:"0x8f2a4c1e9b7d3a5f6e0c2b4d8a1f3e5c7b9d0a2e" description:"Trading wallet; Hyperliquid only" maxLength:"64"`
+}
+
+// ExchangeAccountInfo represents a bound exchange account; credentials are never returned
+type ExchangeAccountInfo struct {
+	Platform  TradingPlatform `json:"platform" example:"okx" enum:"[okx,bybit,binance,gate,hyperliquid]"`
+	AccountID string          `json:"accountId" example:"44705892343619584" description:"The exchange's account UID, or the wallet address for Hyperliquid"`
+	BoundAt   string          `json:"boundAt" example:"2024-02-20T14:30:00Z" description:"When the account was bound; trades from then on are synced" format:"date-time"`
+	SyncedAt  *string         `json:"syncedAt" example:"2024-02-20T14:35:00Z" description:"When the account last synced without error, null before its first sync" format:"date-time"`
+	SyncError string          `json:"syncError,omitempty" example:"okx: Invalid OK-ACCESS-KEY (code 50111, HTTP 401)" description:"Why the last sync failed"`
+}
+
+// ExchangeAccountResponse represents wrapped exchange account Response
+type ExchangeAccountResponse struct {
+	Code    int                 `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message string              `json:"message" example:"Exchange account bound" description:"Human-readable message describing the operation result"`
+	Data    ExchangeAccountInfo `json:"data"`
+}
+
+// ListExchangeAccountsResponse represents wrapped exchange accounts Response
+type ListExchangeAccountsResponse struct {
+	Code    int                      `json:"code" example:"200" description:"HTTP status code indicating the result of the operation"`
+	Message string                   `json:"message" example:"Success" description:"Human-readable message describing the operation result"`
+	Data    ListExchangeAccountsData `json:"data"`
+}
+
+// ListExchangeAccountsData represents the caller's bound exchange accounts
+type ListExchangeAccountsData struct {
+	Accounts []ExchangeAccountInfo `json:"accounts" description:"Bound accounts, one per exchange"`
+}
+
 // // ==========================================
 // // NFT RESPONSE TYPES
 // // ==========================================
@@ -527,7 +812,7 @@ type NftBurnRequirement struct {
 // // Metadata represents additional metadata
 // type Metadata struct {
 // 	TotalNfts              int     `json:"totalNfts" example:"2" description:"Total number of NFTs owned by user (tiered + competition)" minimum:"0"`
-// 	HighestTierLevel       int     `json:"highestTierLevel" example:"3" description:"Highest NFT tier level achieved by user" minimum:"0" maximum:"5"`
+// 	HighestTierLevel       int     `json:"highestTierLevel" example:"3" description:"Highest NFT tier level achieved by user" minimum:"0"`
 // 	TotalBadges            int     `json:"totalBadges" example:"5" description:"Total badges available to user across all levels" minimum:"0"`
 // 	ActivatedBadges        int     `json:"activatedBadges" example:"1" description:"Number of badges currently activated" minimum:"0"`
 // 	TotalContributionValue float64 `json:"totalContributionValue" example:"1.0" description:"Total contribution value from all activated badges" minimum:"0"`
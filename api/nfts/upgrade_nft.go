@@ -0,0 +1,333 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/chain"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/shared"
+	"github.com/aiw3/nft-solana-api/tiers"
+	"github.com/swaggest/usecase"
+	"github.com/swaggest/usecase/status"
+)
+
+// UpgradeNft burns the caller's current NFT, mints the next level and consumes the given activated badges.
+// Submitting it again while an upgrade is unfinished resumes that upgrade from its last completed step.
+func UpgradeNft(store *repository.Store, chainClient chain.Client, jobs *coordinator.Coordinator) usecase.Interactor {
+	type upgradeNftRequest struct {
+		Authorization  string `header:"Authorization" description:"Bearer token for user authentication"`
+		IdempotencyKey string `header:"Idempotency-Key" description:"Optional key; retries with the same key replay the first response"`
+		UpgradeNftRequest
+	}
+
+	saga := &upgradeSaga{store: store, chain: chainClient}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req upgradeNftRequest, resp *UpgradeNftResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = UpgradeNftResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    UpgradeNftData{},
+			}
+			return nil
+		}
+
+		var result UpgradeNftResponse
+		startedID := 0 // the upgrade the job's first run started, resumed by its retries
+		err = jobs.Do(ctx, coordinator.Job{
+			Kind:   coordinator.JobUpgrade,
+			UserID: user.ID,
+			Run: func(ctx context.Context) error {
+				return upgradeTieredNft(ctx, store, saga, user, req.UpgradeNftRequest, &startedID, &result)
+			},
+		})
+		if code, ok := coordinator.RejectionCode(err); ok {
+			*resp = UpgradeNftResponse{
+				Code:    code,
+				Message: err.Error(),
+				Data:    UpgradeNftData{},
+			}
+			return nil
+		}
+		if err != nil && !coordinator.IsRetryable(err) {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = result // a step that failed on every attempt is reported in the envelope
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Upgrade NFT")
+	u.SetDescription("Burn the current tiered NFT, mint the next level and consume activated badges; resumes an unfinished upgrade")
+	u.SetExpectedErrors(status.InvalidArgument, status.Unauthenticated, status.PermissionDenied, status.NotFound, status.AlreadyExists, status.Internal)
+
+	return u
+}
+
+// GetUpgradeStatus returns the caller's latest upgrade with its step log
+func GetUpgradeStatus(store *repository.Store) usecase.Interactor {
+	type getUpgradeStatusRequest struct {
+		Authorization string `header:"Authorization" description:"Bearer token for user authentication"`
+	}
+
+	u := usecase.NewInteractor(func(ctx context.Context, req getUpgradeStatusRequest, resp *UpgradeNftResponse) error {
+		user, err := auth.ExtractUserFromAuthHeader(ctx, store.Users, req.Authorization)
+		if err != nil {
+			*resp = UpgradeNftResponse{
+				Code:    401,
+				Message: err.Error(),
+				Data:    UpgradeNftData{},
+			}
+			return nil
+		}
+
+		requests, err := store.Upgrades.ListByUser(ctx, user.ID)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		if len(requests) == 0 {
+			*resp = UpgradeNftResponse{
+				Code:    404,
+				Message: "No upgrade found",
+				Data:    UpgradeNftData{},
+			}
+			return nil
+		}
+
+		latest := requests[len(requests)-1]
+		upgrade, err := toUpgradeRequest(ctx, store, &latest)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		*resp = UpgradeNftResponse{
+			Code:    200,
+			Message: "Success",
+			Data: UpgradeNftData{
+				Success: latest.Status == repository.UpgradeStatusCompleted,
+				Upgrade: upgrade,
+			},
+		}
+		return nil
+	})
+
+	u.SetTags("User NFTs")
+	u.SetTitle("Get Upgrade Status")
+	u.SetDescription("Get the latest NFT upgrade and its step log")
+	u.SetExpectedErrors(status.Unauthenticated, status.NotFound, status.Internal)
+
+	return u
+}
+
+// ==========================================
+// HELPER FUNCTIONS FOR NFT UPGRADES
+// ==========================================
+
+// upgradeTieredNft starts or resumes the user's upgrade; it runs as a coordinator job holding the user's lease.
+// A failed saga step is reported in resp and returned as retryable, so the coordinator resumes it after a backoff.
+// startedID keeps the upgrade started by the job across its runs: a retry resumes it even when it failed before
+// the burn, rather than starting another upgrade with a new serial.
+func upgradeTieredNft(ctx context.Context, store *repository.Store, saga *upgradeSaga, user *repository.User,
+	req UpgradeNftRequest, startedID *int, resp *UpgradeNftResponse) error {
+	pending, err := unfinishedUpgrade(ctx, store, user.ID)
+	if err != nil {
+		return err
+	}
+	if pending == nil && *startedID != 0 {
+		if pending, err = store.Upgrades.Get(ctx, *startedID); err != nil {
+			return err
+		}
+	}
+	if pending != nil {
+		if pending.OldNftID != req.UserNftID {
+			*resp = UpgradeNftResponse{
+				Code:    409,
+				Message: fmt.Sprintf("The upgrade of NFT %d is unfinished; submit it again to resume it", pending.OldNftID),
+				Data:    UpgradeNftData{},
+			}
+			return nil
+		}
+		if spendsRetry(ctx, pending) && pending.RetryCount >= maxUpgradeRetries {
+			*resp = UpgradeNftResponse{
+				Code:    409,
+				Message: fmt.Sprintf("Upgrade %d failed %d times and needs support to complete", pending.ID, pending.RetryCount+1),
+				Data:    UpgradeNftData{},
+			}
+			return nil
+		}
+		stepErr := saga.resume(ctx, user, pending)
+		return writeUpgradeResult(ctx, store, pending, stepErr, resp)
+	}
+
+	nft, err := store.TieredNfts.GetByID(ctx, req.UserNftID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && nft.UserID != user.ID) {
+		*resp = UpgradeNftResponse{
+			Code:    404,
+			Message: fmt.Sprintf("NFT %d not found", req.UserNftID),
+			Data:    UpgradeNftData{},
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	tier, badgeIDs, code, message, err := validateUpgrade(ctx, store, user, nft, req.BadgeIDs)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		*resp = UpgradeNftResponse{
+			Code:    code,
+			Message: message,
+			Data:    UpgradeNftData{},
+		}
+		return nil
+	}
+
+	upgrade, stepErr := saga.start(ctx, user, nft, tier, badgeIDs)
+	if upgrade == nil {
+		return stepErr
+	}
+	*startedID = upgrade.ID
+	return writeUpgradeResult(ctx, store, upgrade, stepErr, resp)
+}
+
+// validateUpgrade checks the upgrade requirements with the eligibility engine behind /can-upgrade, then the
+// badges requested. A non-zero code rejects the request with message; otherwise it returns the target tier
+// and the de-duplicated badges to consume.
+func validateUpgrade(ctx context.Context, store *repository.Store, user *repository.User, nft *repository.TieredNft,
+	requestedBadgeIDs []int) (tiers.Tier, []int, int, string, error) {
+	machine, err := lifecycle.Load(ctx, store, user.ID)
+	if err != nil {
+		return tiers.Tier{}, nil, 0, "", err
+	}
+	eligibility, err := evaluateUpgrade(ctx, store, user, machine)
+	if err != nil {
+		return tiers.Tier{}, nil, 0, "", err
+	}
+	if eligibility.CurrentNft == nil || eligibility.CurrentNft.ID != nft.ID {
+		message := fmt.Sprintf("NFT %d is not the active NFT", nft.ID)
+		if err := machine.CanBurn(nft.ID); err != nil {
+			message = err.Error()
+		}
+		return tiers.Tier{}, nil, 409, message, nil
+	}
+	if code := upgradeRejectionCode(eligibility); code != 0 {
+		return tiers.Tier{}, nil, code, strings.Join(eligibility.Missing, "; "), nil
+	}
+	tier, _ := tiers.Current().Tier(eligibility.TargetLevel)
+
+	badgeIDs := []int{}
+	seen := map[int]bool{}
+	for _, id := range requestedBadgeIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		badge, err := store.Badges.GetUserBadge(ctx, user.ID, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			return tier, nil, 400, fmt.Sprintf("Badge %d is not owned by the user", id), nil
+		}
+		if err != nil {
+			return tier, nil, 0, "", err
+		}
+		if badgelifecycle.StatusOf(badge) != badgelifecycle.StatusActivated {
+			return tier, nil, 400, fmt.Sprintf("Badge %d is %s; only activated badges can be consumed", id, badge.Status), nil
+		}
+		badgeIDs = append(badgeIDs, id)
+	}
+	if len(badgeIDs) < tier.RequiredBadges {
+		return tier, nil, 403, fmt.Sprintf("Level %d requires %d activated badges, %d provided",
+			tier.Level, tier.RequiredBadges, len(badgeIDs)), nil
+	}
+	return tier, badgeIDs, 0, "", nil
+}
+
+// upgradeRejectionCode maps an eligibility verdict to the code rejecting the upgrade, 0 when it is eligible:
+// 409 when the NFTs do not allow it (an unfinished upgrade, no active NFT or the highest level) and 403 when
+// the trading volume or activated badges fall short
+func upgradeRejectionCode(eligibility *upgradeEligibility) int {
+	switch {
+	case eligibility.Eligible:
+		return 0
+	case eligibility.Pending, eligibility.TargetLevel == 0, !eligibility.Requirements.NftBurn.Met:
+		return 409
+	}
+	return 403
+}
+
+// writeUpgradeResult reports the upgrade's state; a failed step is reported with the request so it can be resumed
+// and returned as retryable
+func writeUpgradeResult(ctx context.Context, store *repository.Store, req *repository.UpgradeRequest, stepErr error,
+	resp *UpgradeNftResponse) error {
+	upgrade, err := toUpgradeRequest(ctx, store, req)
+	if err != nil {
+		return err
+	}
+
+	if stepErr != nil {
+		message := fmt.Sprintf("Upgrade failed: %v", stepErr)
+		if upgrade.CanResume {
+			message += "; submit the upgrade again to resume it"
+		}
+		*resp = UpgradeNftResponse{
+			Code:    500,
+			Message: message,
+			Data:    UpgradeNftData{Upgrade: upgrade},
+		}
+		return coordinator.Retry(stepErr)
+	}
+
+	*resp = UpgradeNftResponse{
+		Code:    200,
+		Message: fmt.Sprintf("NFT upgraded to Level %d successfully", req.ToLevel),
+		Data: UpgradeNftData{
+			Success: true,
+			Upgrade: upgrade,
+		},
+	}
+	return nil
+}
+
+// toUpgradeRequest converts a stored upgrade and its step log to the API shape
+func toUpgradeRequest(ctx context.Context, store *repository.Store, req *repository.UpgradeRequest) (*UpgradeRequest, error) {
+	steps, err := store.Upgrades.ListSteps(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	upgrade := &UpgradeRequest{
+		ID:                req.ID,
+		Status:            req.Status,
+		FromLevel:         req.FromLevel,
+		ToLevel:           req.ToLevel,
+		OldUserNftID:      req.OldNftID,
+		NewUserNftID:      req.NewNftID,
+		NewMintAddress:    req.NewNftMint,
+		BurnTransactionID: req.BurnTransaction,
+		MintTransactionID: req.MintTransaction,
+		BadgeIDs:          append([]int{}, req.BadgeIDs...),
+		RetryCount:        req.RetryCount,
+		CanResume: req.Status == repository.UpgradeStatusFailed && req.BurnTransaction != "" &&
+			req.RetryCount < maxUpgradeRetries,
+		ErrorMessage: req.ErrorMessage,
+		Steps:        make([]UpgradeStep, 0, len(steps)),
+	}
+	for _, step := range steps {
+		upgrade.Steps = append(upgrade.Steps, UpgradeStep{
+			Step:   step.Step,
+			Detail: step.Detail,
+			At:     shared.FormatTimestamp(step.CreatedAt),
+		})
+	}
+	return upgrade, nil
+}
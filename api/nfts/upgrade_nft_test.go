@@ -0,0 +1,94 @@
+package nfts
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/chain"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// TestCanUpgradeAgreesWithUpgrade checks /can-upgrade reports an upgrade as possible exactly when /upgrade
+// performs it
+func TestCanUpgradeAgreesWithUpgrade(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		volume int64 // USDT traded
+		level  int   // the highest NFT minted, burned below it
+		burned bool  // the highest NFT was burned too, by an upgrade that stopped before its mint
+		badges int   // activated badges, all requested
+		code   int
+	}{
+		{"eligible", 600000, 1, false, 2, 200},
+		{"trading volume short", 400000, 1, false, 2, 403},
+		{"badges short", 600000, 1, false, 1, 403},
+		{"highest level", 500000000, 5, false, 10, 409},
+		{"unfinished upgrade", 600000, 1, true, 2, 409},
+	}
+	for _, tt := range tests {
+		for name, store := range testStores(t) {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				user := &repository.User{Username: "upgrader", WalletAddr: "UpgraderWallet",
+					TradingVolume: money.USDT.Whole(tt.volume)}
+				if err := store.Users.Save(ctx, user); err != nil {
+					t.Fatal(err)
+				}
+				now := time.Now()
+				var nft *repository.TieredNft
+				for level := 1; level <= tt.level; level++ {
+					nft = &repository.TieredNft{UserID: user.ID, Level: level, Name: "NFT", Status: repository.NftStatusActive,
+						MintAddress: fmt.Sprintf("Level%dMint", level), SerialNumber: 1, MintedAt: now.Add(-time.Hour)}
+					if level < tt.level || tt.burned {
+						nft.Status, nft.BurnedAt = repository.NftStatusBurned, &now
+					}
+					if err := store.TieredNfts.Create(ctx, nft); err != nil {
+						t.Fatal(err)
+					}
+				}
+				badgeIDs := []int{}
+				for id := 1; id <= tt.badges; id++ {
+					if err := store.Badges.SaveDefinition(ctx, &repository.BadgeDefinition{ID: id, NftLevel: 1, Name: "Badge"}); err != nil {
+						t.Fatal(err)
+					}
+					badge := &repository.UserBadge{UserID: user.ID, BadgeID: id, Status: string(badgelifecycle.StatusActivated),
+						ActivatedAt: &now}
+					if err := store.Badges.SaveUserBadge(ctx, badge); err != nil {
+						t.Fatal(err)
+					}
+					badgeIDs = append(badgeIDs, id)
+				}
+
+				machine, err := lifecycle.Load(ctx, store, user.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				eligibility, err := evaluateUpgrade(ctx, store, user, machine)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var resp UpgradeNftResponse
+				startedID := 0
+				saga := &upgradeSaga{store: store, chain: chain.NewMockClient()}
+				err = upgradeTieredNft(ctx, store, saga, user, UpgradeNftRequest{UserNftID: nft.ID, BadgeIDs: badgeIDs},
+					&startedID, &resp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.Code != tt.code {
+					t.Errorf("upgrade: code %d (%s), want %d", resp.Code, resp.Message, tt.code)
+				}
+				if eligibility.Eligible != (resp.Code == 200) {
+					t.Errorf("can-upgrade says eligible %t (missing %v), upgrade answered %d (%s)", eligibility.Eligible,
+						eligibility.Missing, resp.Code, resp.Message)
+				}
+			})
+		}
+	}
+}
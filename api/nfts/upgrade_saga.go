@@ -0,0 +1,297 @@
+package nfts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/chain"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/lifecycle"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
+)
+
+// ==========================================
+// UPGRADE SAGA
+// ==========================================
+
+// Upgrade step log entries
+const (
+	upgradeStepStarted        = "started"
+	upgradeStepResumed        = "resumed"
+	upgradeStepNftBurned      = "nft_burned" // lifecycle.NftBurned
+	upgradeStepBurnConfirmed  = "burn_confirmed"
+	upgradeStepNftMinted      = "nft_minted" // lifecycle.NftMinted
+	upgradeStepMintConfirmed  = "mint_confirmed"
+	upgradeStepBadgesConsumed = "badges_consumed"
+	upgradeStepCompleted      = "completed"
+	upgradeStepFailed         = "failed"
+)
+
+// maxUpgradeRetries bounds how many times a failed upgrade can be resumed before support has to step in
+const maxUpgradeRetries = 3
+
+// upgradeSaga runs an upgrade as burn -> mint -> consume badges. Every completed step is persisted on the
+// request and appended to its step log before the next one starts, so a crashed or failed upgrade resumes
+// after its last completed step: the NFT is never burned twice and badges are only consumed after the mint.
+type upgradeSaga struct {
+	store *repository.Store
+	chain chain.Client
+}
+
+// start records a new upgrade of nft to the given tier and runs it
+func (s *upgradeSaga) start(ctx context.Context, user *repository.User, nft *repository.TieredNft, tier tiers.Tier,
+	badgeIDs []int) (*repository.UpgradeRequest, error) {
+	serial, err := s.store.Sequences.Next(ctx, serialSequence(tier.Level))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	req := &repository.UpgradeRequest{
+		UserID:       user.ID,
+		FromLevel:    nft.Level,
+		ToLevel:      tier.Level,
+		OldNftID:     nft.ID,
+		OldNftMint:   nft.MintAddress,
+		SerialNumber: serial,
+		BadgeIDs:     badgeIDs,
+		Status:       repository.UpgradeStatusPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.store.Upgrades.Create(ctx, req); err != nil {
+		return nil, err
+	}
+	if err := s.logStep(ctx, req, upgradeStepStarted, fmt.Sprintf("Level %d -> %d, badges %v", req.FromLevel, req.ToLevel, badgeIDs)); err != nil {
+		return nil, err
+	}
+	return req, s.run(ctx, user, req)
+}
+
+// resume continues an unfinished upgrade from its last completed step
+func (s *upgradeSaga) resume(ctx context.Context, user *repository.User, req *repository.UpgradeRequest) error {
+	if spendsRetry(ctx, req) {
+		req.RetryCount++
+	}
+	if err := s.logStep(ctx, req, upgradeStepResumed, fmt.Sprintf("from %s, retry %d", req.Status, req.RetryCount)); err != nil {
+		return err
+	}
+	return s.run(ctx, user, req)
+}
+
+// run executes the remaining steps and marks the request failed if one of them does not succeed
+func (s *upgradeSaga) run(ctx context.Context, user *repository.User, req *repository.UpgradeRequest) error {
+	stepErr := s.advance(ctx, user, req)
+	if stepErr == nil {
+		return nil
+	}
+
+	req.Status = repository.UpgradeStatusFailed
+	req.ErrorMessage = stepErr.Error()
+	req.UpdatedAt = time.Now()
+	if err := s.store.Upgrades.Update(ctx, req); err != nil {
+		return err
+	}
+	if err := s.logStep(ctx, req, upgradeStepFailed, stepErr.Error()); err != nil {
+		return err
+	}
+	return stepErr
+}
+
+func (s *upgradeSaga) advance(ctx context.Context, user *repository.User, req *repository.UpgradeRequest) error {
+	if req.BurnTransaction == "" {
+		if err := s.burn(ctx, user, req); err != nil {
+			return fmt.Errorf("burn Level %d NFT: %w", req.FromLevel, err)
+		}
+	}
+	if req.NewNftID == 0 {
+		if err := s.mint(ctx, user, req); err != nil {
+			return fmt.Errorf("mint Level %d NFT: %w", req.ToLevel, err)
+		}
+	}
+	if req.Status != repository.UpgradeStatusCompleted {
+		if err := s.consumeBadges(ctx, req); err != nil {
+			return fmt.Errorf("consume badges: %w", err)
+		}
+	}
+	return nil
+}
+
+// burn burns the current NFT on chain and records it as Burned
+func (s *upgradeSaga) burn(ctx context.Context, user *repository.User, req *repository.UpgradeRequest) error {
+	machine, err := lifecycle.Load(ctx, s.store, req.UserID)
+	if err != nil {
+		return err
+	}
+	old, err := s.store.TieredNfts.GetByID(ctx, req.OldNftID)
+	if err != nil {
+		return err
+	}
+
+	// Burning is idempotent on chain, so repeating it after a crash returns the original signature
+	result, err := s.chain.Burn(ctx, chain.BurnRequest{OwnerWallet: user.WalletAddr, MintAddress: req.OldNftMint})
+	if err != nil {
+		return err
+	}
+	if old.Status == repository.NftStatusActive {
+		burned, event, err := machine.Burn(old.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.store.TieredNfts.Update(ctx, burned); err != nil {
+			return err
+		}
+		if err := s.logEvent(ctx, req, upgradeStepNftBurned, event); err != nil {
+			return err
+		}
+	}
+
+	req.BurnTransaction = result.TransactionID
+	req.Status = repository.UpgradeStatusBurnConfirmed
+	req.UpdatedAt = time.Now()
+	if err := s.store.Upgrades.Update(ctx, req); err != nil {
+		return err
+	}
+	return s.logStep(ctx, req, upgradeStepBurnConfirmed, result.TransactionID)
+}
+
+// mint mints the next level; an NFT recorded by an interrupted earlier attempt is adopted instead
+func (s *upgradeSaga) mint(ctx context.Context, user *repository.User, req *repository.UpgradeRequest) error {
+	machine, err := lifecycle.Load(ctx, s.store, req.UserID)
+	if err != nil {
+		return err
+	}
+	tier, ok := tiers.Current().Tier(req.ToLevel)
+	if !ok {
+		return fmt.Errorf("Level %d is not in the tier catalog", req.ToLevel)
+	}
+
+	nft := machine.Active()
+	if nft == nil || nft.Level != req.ToLevel {
+		var event lifecycle.NftMinted
+		nft, event, err = mintTieredNft(ctx, s.store, s.chain, machine, user, tier, req.SerialNumber, upgradeMintKey(req.ID))
+		if err != nil {
+			return err
+		}
+		if err := s.logEvent(ctx, req, upgradeStepNftMinted, event); err != nil {
+			return err
+		}
+	}
+
+	req.NewNftID = nft.ID
+	req.NewNftMint = nft.MintAddress
+	req.MintTransaction = nft.TransactionID
+	req.Status = repository.UpgradeStatusMintConfirmed
+	req.UpdatedAt = time.Now()
+	if err := s.store.Upgrades.Update(ctx, req); err != nil {
+		return err
+	}
+	return s.logStep(ctx, req, upgradeStepMintConfirmed, nft.TransactionID)
+}
+
+// consumeBadges consumes the badges committed to the upgrade and completes it
+func (s *upgradeSaga) consumeBadges(ctx context.Context, req *repository.UpgradeRequest) error {
+	now := time.Now()
+	for _, badgeID := range req.BadgeIDs {
+		badge, err := s.store.Badges.GetUserBadge(ctx, req.UserID, badgeID)
+		if err != nil {
+			return err
+		}
+		if badgelifecycle.StatusOf(badge) == badgelifecycle.StatusConsumed {
+			continue
+		}
+		if err := badgelifecycle.ConsumeCommitted(badge, req.CreatedAt, now); err != nil {
+			return err
+		}
+		if err := s.store.Badges.SaveUserBadge(ctx, badge); err != nil {
+			return err
+		}
+	}
+	if err := s.logStep(ctx, req, upgradeStepBadgesConsumed, fmt.Sprintf("%v", req.BadgeIDs)); err != nil {
+		return err
+	}
+
+	req.Status = repository.UpgradeStatusCompleted
+	req.ErrorMessage = ""
+	req.UpdatedAt = time.Now()
+	if err := s.store.Upgrades.Update(ctx, req); err != nil {
+		return err
+	}
+	return s.logStep(ctx, req, upgradeStepCompleted, "")
+}
+
+func (s *upgradeSaga) logStep(ctx context.Context, req *repository.UpgradeRequest, step, detail string) error {
+	return s.store.Upgrades.AppendStep(ctx, &repository.UpgradeStep{
+		UpgradeRequestID: req.ID,
+		Step:             step,
+		Detail:           detail,
+		CreatedAt:        time.Now(),
+	})
+}
+
+// logEvent delivers a lifecycle event of the upgrade to its step log, at the time of the transition
+func (s *upgradeSaga) logEvent(ctx context.Context, req *repository.UpgradeRequest, step string, event lifecycle.Event) error {
+	return s.store.Upgrades.AppendStep(ctx, &repository.UpgradeStep{
+		UpgradeRequestID: req.ID,
+		Step:             step,
+		Detail:           event.String(),
+		CreatedAt:        event.OccurredAt(),
+	})
+}
+
+// upgradeMintKey is the chain idempotency key of an upgrade's mint
+func upgradeMintKey(upgradeRequestID int) string {
+	return fmt.Sprintf("upgrade-%d", upgradeRequestID)
+}
+
+// spendsRetry reports whether resuming req now spends one of its maxUpgradeRetries: it failed, and this is
+// not the coordinator running the job that failed it again. A job's retries after a backoff are part of the
+// same attempt as its first run.
+func spendsRetry(ctx context.Context, req *repository.UpgradeRequest) bool {
+	return req.Status == repository.UpgradeStatusFailed && coordinator.Attempt(ctx) <= 1
+}
+
+// unfinishedUpgrade returns the user's upgrade that still has to be resumed, nil if there is none.
+// A request that failed before burning changed nothing and is left behind for a fresh attempt.
+func unfinishedUpgrade(ctx context.Context, store *repository.Store, userID int) (*repository.UpgradeRequest, error) {
+	requests, err := store.Upgrades.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(requests) - 1; i >= 0; i-- {
+		if requests[i].Unfinished() {
+			return &requests[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ResumeInFlightUpgrades resumes unfinished upgrades, e.g. interrupted by a server crash or failed after
+// the burn, while they have retries left. It returns the number of upgrades completed.
+func ResumeInFlightUpgrades(ctx context.Context, store *repository.Store, chainClient chain.Client) (int, error) {
+	requests, err := store.Upgrades.ListInFlight(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	saga := &upgradeSaga{store: store, chain: chainClient}
+	completed := 0
+	for i := range requests {
+		req := &requests[i]
+		if spendsRetry(ctx, req) && req.RetryCount >= maxUpgradeRetries {
+			continue // needs support to complete
+		}
+		user, err := store.Users.GetByID(ctx, req.UserID)
+		if err != nil {
+			return completed, err
+		}
+		if err := saga.resume(ctx, user, req); err != nil {
+			continue // recorded as failed on the request; the user can retry it
+		}
+		completed++
+	}
+	return completed, nil
+}
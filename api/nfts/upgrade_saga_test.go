@@ -0,0 +1,290 @@
+package nfts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/badgelifecycle"
+	"github.com/aiw3/nft-solana-api/chain"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tiers"
+)
+
+// downChain fails every mint, counting the calls
+type downChain struct {
+	chain.Client
+	mints int
+}
+
+func (c *downChain) Mint(ctx context.Context, req chain.MintRequest) (*chain.MintResult, error) {
+	c.mints++
+	return nil, errors.New("rpc node unavailable")
+}
+
+// burnedUpgrade stores a user whose Level 1 NFT was burned by an upgrade that then failed to mint
+func burnedUpgrade(t *testing.T, store *repository.Store) (*repository.User, *repository.UpgradeRequest) {
+	t.Helper()
+	ctx := context.Background()
+	user := &repository.User{Username: "upgrader", WalletAddr: "UpgraderWallet"}
+	if err := store.Users.Save(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	nft := &repository.TieredNft{UserID: user.ID, Level: 1, Name: "Tech Chicken", Status: repository.NftStatusBurned,
+		MintAddress: "TechChickenMint", MintedAt: now.Add(-time.Hour), BurnedAt: &now}
+	if err := store.TieredNfts.Create(ctx, nft); err != nil {
+		t.Fatal(err)
+	}
+	req := &repository.UpgradeRequest{UserID: user.ID, FromLevel: 1, ToLevel: 2, OldNftID: nft.ID,
+		OldNftMint: nft.MintAddress, SerialNumber: 1, Status: repository.UpgradeStatusFailed,
+		BurnTransaction: "BurnSignature", ErrorMessage: "mint Level 2 NFT: timeout", CreatedAt: now, UpdatedAt: now}
+	if err := store.Upgrades.Create(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	return user, req
+}
+
+func TestCoordinatorRetriesSpendOneUpgradeRetry(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			user, req := burnedUpgrade(t, store)
+			down := &downChain{Client: chain.NewMockClient()}
+			saga := &upgradeSaga{store: store, chain: down}
+			jobs := coordinator.New(store.Leases, coordinator.Config{Workers: 1, MaxAttempts: 3,
+				BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+			t.Cleanup(func() { jobs.Stop(context.Background()) })
+
+			submit := func() UpgradeNftResponse {
+				var resp UpgradeNftResponse
+				startedID := 0
+				err := jobs.Do(ctx, coordinator.Job{
+					Kind:   coordinator.JobUpgrade,
+					UserID: user.ID,
+					Run: func(ctx context.Context) error {
+						return upgradeTieredNft(ctx, store, saga, user, UpgradeNftRequest{UserNftID: req.OldNftID},
+							&startedID, &resp)
+					},
+				})
+				if err != nil && !coordinator.IsRetryable(err) {
+					t.Fatal(err)
+				}
+				return resp
+			}
+
+			for submission := 1; submission <= maxUpgradeRetries; submission++ {
+				resp := submit()
+				if resp.Code != 500 {
+					t.Fatalf("submission %d: code %d (%s), want the failed mint reported", submission, resp.Code, resp.Message)
+				}
+				if down.mints != 3*submission {
+					t.Errorf("submission %d: %d mints, want 3 per submission", submission, down.mints)
+				}
+				stored, err := store.Upgrades.Get(ctx, req.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stored.RetryCount != submission {
+					t.Errorf("submission %d: retry count %d, want %d", submission, stored.RetryCount, submission)
+				}
+			}
+
+			if resp := submit(); resp.Code != 409 {
+				t.Errorf("after %d retries: code %d (%s), want 409 needs support", maxUpgradeRetries, resp.Code, resp.Message)
+			}
+			if down.mints != 3*maxUpgradeRetries {
+				t.Errorf("%d mints after the retries ran out, want %d", down.mints, 3*maxUpgradeRetries)
+			}
+		})
+	}
+}
+
+// flakyBurnChain fails the first burns, counting the calls
+type flakyBurnChain struct {
+	chain.Client
+	failures int
+	burns    int
+}
+
+func (c *flakyBurnChain) Burn(ctx context.Context, req chain.BurnRequest) (*chain.BurnResult, error) {
+	c.burns++
+	if c.burns <= c.failures {
+		return nil, errors.New("rpc node unavailable")
+	}
+	return c.Client.Burn(ctx, req)
+}
+
+// upgradeableUser stores a user meeting the Level 2 requirements, with an active Level 1 NFT and two
+// activated badges
+func upgradeableUser(t *testing.T, store *repository.Store) (*repository.User, *repository.TieredNft, []int) {
+	t.Helper()
+	ctx := context.Background()
+	user := &repository.User{Username: "upgrader", WalletAddr: "UpgraderWallet", TradingVolume: money.USDT.Whole(600000)}
+	if err := store.Users.Save(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	nft := &repository.TieredNft{UserID: user.ID, Level: 1, Name: "Tech Chicken", Status: repository.NftStatusActive,
+		MintAddress: "TechChickenMint", SerialNumber: 1, MintedAt: now.Add(-time.Hour)}
+	if err := store.TieredNfts.Create(ctx, nft); err != nil {
+		t.Fatal(err)
+	}
+	activatedAt := now.Add(-time.Hour)
+	badgeIDs := []int{1, 2}
+	for _, id := range badgeIDs {
+		if err := store.Badges.SaveDefinition(ctx, &repository.BadgeDefinition{ID: id, NftLevel: 1, Name: "Badge"}); err != nil {
+			t.Fatal(err)
+		}
+		badge := &repository.UserBadge{UserID: user.ID, BadgeID: id, Status: string(badgelifecycle.StatusActivated),
+			ActivatedAt: &activatedAt}
+		if err := store.Badges.SaveUserBadge(ctx, badge); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return user, nft, badgeIDs
+}
+
+func TestCoordinatorRetriesResumeUpgradeFailedBeforeBurn(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			user, nft, badgeIDs := upgradeableUser(t, store)
+			flaky := &flakyBurnChain{Client: chain.NewMockClient(), failures: 2}
+			saga := &upgradeSaga{store: store, chain: flaky}
+			jobs := coordinator.New(store.Leases, coordinator.Config{Workers: 1, MaxAttempts: 3,
+				BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+			t.Cleanup(func() { jobs.Stop(context.Background()) })
+
+			var resp UpgradeNftResponse
+			startedID := 0
+			err := jobs.Do(ctx, coordinator.Job{
+				Kind:   coordinator.JobUpgrade,
+				UserID: user.ID,
+				Run: func(ctx context.Context) error {
+					return upgradeTieredNft(ctx, store, saga, user, UpgradeNftRequest{UserNftID: nft.ID, BadgeIDs: badgeIDs},
+						&startedID, &resp)
+				},
+			})
+			if err != nil || resp.Code != 200 {
+				t.Fatalf("code %d (%s), error %v; want the third attempt to complete the upgrade", resp.Code, resp.Message, err)
+			}
+			if flaky.burns != 3 {
+				t.Errorf("%d burns, want 3", flaky.burns)
+			}
+
+			requests, err := store.Upgrades.ListByUser(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(requests) != 1 || requests[0].Status != repository.UpgradeStatusCompleted {
+				t.Fatalf("upgrade requests %+v, want one completed request", requests)
+			}
+			if requests[0].SerialNumber != 1 || requests[0].RetryCount != 0 {
+				t.Errorf("serial %d with retry count %d, want serial 1 and no retry spent", requests[0].SerialNumber,
+					requests[0].RetryCount)
+			}
+			minted, err := store.TieredNfts.GetByID(ctx, requests[0].NewNftID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if minted.SerialNumber != 1 {
+				t.Errorf("minted serial %d, want 1", minted.SerialNumber)
+			}
+			// The failed attempts took no serials of their own
+			if next, err := store.Sequences.Next(ctx, serialSequence(2)); err != nil || next != 2 {
+				t.Errorf("next Level 2 serial %d (%v), want 2", next, err)
+			}
+		})
+	}
+}
+
+func TestResumeInFlightUpgradesResumesFailedAfterBurn(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			_, req := burnedUpgrade(t, store)
+
+			// A request that failed before burning changed nothing and is not resumed
+			untouched := &repository.UpgradeRequest{UserID: req.UserID, FromLevel: 1, ToLevel: 2, OldNftID: req.OldNftID,
+				OldNftMint: req.OldNftMint, SerialNumber: 2, Status: repository.UpgradeStatusFailed,
+				CreatedAt: req.CreatedAt, UpdatedAt: req.UpdatedAt}
+			if err := store.Upgrades.Create(ctx, untouched); err != nil {
+				t.Fatal(err)
+			}
+
+			inFlight, err := store.Upgrades.ListInFlight(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(inFlight) != 1 || inFlight[0].ID != req.ID {
+				t.Fatalf("in flight %+v, want only upgrade %d", inFlight, req.ID)
+			}
+
+			completed, err := ResumeInFlightUpgrades(ctx, store, chain.NewMockClient())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if completed != 1 {
+				t.Errorf("completed %d upgrades, want 1", completed)
+			}
+			resumed, err := store.Upgrades.Get(ctx, req.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resumed.Status != repository.UpgradeStatusCompleted || resumed.RetryCount != 1 {
+				t.Errorf("upgrade %s with retry count %d, want completed after one retry", resumed.Status, resumed.RetryCount)
+			}
+		})
+	}
+}
+
+func TestUpgradeDeliversLifecycleEventsToStepLog(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			user := &repository.User{Username: "upgrader", WalletAddr: "UpgraderWallet"}
+			if err := store.Users.Save(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+			nft := &repository.TieredNft{UserID: user.ID, Level: 1, Name: "Tech Chicken", Status: repository.NftStatusActive,
+				MintAddress: "TechChickenMint", MintedAt: time.Now().Add(-time.Hour)}
+			if err := store.TieredNfts.Create(ctx, nft); err != nil {
+				t.Fatal(err)
+			}
+			tier, _ := tiers.Current().Tier(2)
+
+			saga := &upgradeSaga{store: store, chain: chain.NewMockClient()}
+			req, err := saga.start(ctx, user, nft, tier, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			steps, err := store.Upgrades.ListSteps(ctx, req.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			details := map[string]string{}
+			for _, step := range steps {
+				names = append(names, step.Step)
+				details[step.Step] = step.Detail
+			}
+			want := []string{upgradeStepStarted, upgradeStepNftBurned, upgradeStepBurnConfirmed, upgradeStepNftMinted,
+				upgradeStepMintConfirmed, upgradeStepBadgesConsumed, upgradeStepCompleted}
+			if strings.Join(names, ",") != strings.Join(want, ",") {
+				t.Errorf("steps %v, want %v", names, want)
+			}
+			wantBurned := fmt.Sprintf("Level 1 NFT %d of user %d burned", nft.ID, user.ID)
+			wantMinted := fmt.Sprintf("Level 2 NFT of user %d minted", user.ID)
+			if details[upgradeStepNftBurned] != wantBurned || details[upgradeStepNftMinted] != wantMinted {
+				t.Errorf("events %q and %q, want %q and %q", details[upgradeStepNftBurned],
+					details[upgradeStepNftMinted], wantBurned, wantMinted)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package prices
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// CSV quotes prices recorded in a CSV file with a header row and asset, time and price columns: the
+// asset's code (SOL), when the price was published (RFC 3339 or Unix milliseconds) and its value in USDT.
+// An asset's price at a time is the last one published at or before it.
+type CSV struct {
+	series map[money.Currency][]Price // by asset, oldest first
+}
+
+// LoadCSV reads the prices of a CSV file
+func LoadCSV(path string) (*CSV, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseCSV(file)
+}
+
+// ParseCSV reads CSV prices; a malformed row fails the whole file, naming its line
+func ParseCSV(r io.Reader) (*CSV, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("prices csv: missing header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("prices csv: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	asset, published, value := -1, -1, -1
+	if i, ok := index["asset"]; ok {
+		asset = i
+	}
+	if i, ok := index["time"]; ok {
+		published = i
+	}
+	if i, ok := index["price"]; ok {
+		value = i
+	}
+	if asset < 0 || published < 0 || value < 0 {
+		return nil, errors.New("prices csv: header needs asset, time and price columns")
+	}
+
+	oracle := &CSV{series: map[money.Currency][]Price{}}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("prices csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		price := Price{Asset: money.Currency(strings.ToUpper(strings.TrimSpace(record[asset]))), Source: SourceCSV}
+		if price.Asset == "" {
+			return nil, fmt.Errorf("prices csv: line %d: asset is empty", line)
+		}
+		if price.At, err = parseTime(strings.TrimSpace(record[published])); err != nil {
+			return nil, fmt.Errorf("prices csv: line %d: time %q must be RFC 3339 or Unix milliseconds", line,
+				record[published])
+		}
+		if price.Value, err = money.USDT.Parse(record[value]); err != nil || price.Value.Sign() <= 0 {
+			return nil, fmt.Errorf("prices csv: line %d: price %q is not a positive decimal", line, record[value])
+		}
+		oracle.series[price.Asset] = append(oracle.series[price.Asset], price)
+	}
+	for _, series := range oracle.series {
+		sort.SliceStable(series, func(i, j int) bool { return series[i].At.Before(series[j].At) })
+	}
+	return oracle, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t.UTC(), err
+}
+
+// Assets lists the assets the file has prices for
+func (o *CSV) Assets() []money.Currency {
+	assets := make([]money.Currency, 0, len(o.series))
+	for asset := range o.series {
+		assets = append(assets, asset)
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i] < assets[j] })
+	return assets
+}
+
+func (o *CSV) PriceAt(ctx context.Context, asset money.Currency, at time.Time) (Price, error) {
+	series := o.series[asset]
+	// The first price published after the time; the one before it is the price at the time
+	i := sort.Search(len(series), func(i int) bool { return series[i].At.After(at) })
+	if i == 0 || at.Sub(series[i-1].At) > MaxAge {
+		return Price{}, noPrice(asset, at)
+	}
+	return series[i-1], nil
+}
@@ -0,0 +1,89 @@
+// Package prices values assets in USDT at a point in time, so trades settled in SOL, USDC or other
+// assets count towards trading volume, whose thresholds are all in USDT. A PriceOracle quotes historical
+// prices: CSV reads recorded prices from a file (fixtures, backfills), Pyth asks a Pyth-style price
+// service, which the pythtest stand-in replaces locally. Par quotes stablecoins at one USDT.
+package prices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// Sources a price can come from
+const (
+	SourceCSV  = "csv"
+	SourcePyth = "pyth"
+	SourcePar  = "par"
+)
+
+// MaxAge is how far from the time asked for a price may have been published and still be used
+const MaxAge = time.Hour
+
+// ErrNoPrice is returned when an oracle has no price for the asset close enough to the time asked for
+var ErrNoPrice = errors.New("no price")
+
+// Price is what one whole unit of an asset was worth in USDT
+type Price struct {
+	Asset  money.Currency
+	Value  money.Amount // in USDT
+	At     time.Time    // when the price was published
+	Source string       // the oracle that quoted it
+}
+
+// PriceOracle quotes historical prices
+type PriceOracle interface {
+	// PriceAt returns the asset's price at the time, published within MaxAge of it. An error wrapping
+	// ErrNoPrice means the oracle has no such price; other errors mean it could not be asked.
+	PriceAt(ctx context.Context, asset money.Currency, at time.Time) (Price, error)
+}
+
+// noPrice returns ErrNoPrice for the asset at the time
+func noPrice(asset money.Currency, at time.Time) error {
+	return fmt.Errorf("%w for %s at %s", ErrNoPrice, asset, at.UTC().Format(time.RFC3339))
+}
+
+// ==========================================
+// PAR AND CHAIN
+// ==========================================
+
+// par quotes its assets at one USDT
+type par map[money.Currency]bool
+
+// Par quotes the assets, stablecoins, at one USDT at any time, and has no price for other assets
+func Par(assets ...money.Currency) PriceOracle {
+	quoted := par{}
+	for _, asset := range assets {
+		quoted[asset] = true
+	}
+	return quoted
+}
+
+func (p par) PriceAt(ctx context.Context, asset money.Currency, at time.Time) (Price, error) {
+	if !p[asset] {
+		return Price{}, noPrice(asset, at)
+	}
+	return Price{Asset: asset, Value: money.USDT.Whole(1), At: at, Source: SourcePar}, nil
+}
+
+// chain asks its oracles in turn
+type chain []PriceOracle
+
+// Chain asks the oracles in order and returns the first price found. An oracle that cannot be asked
+// fails the chain rather than falling through, so a price is never taken from a later oracle by accident.
+func Chain(oracles ...PriceOracle) PriceOracle {
+	return chain(oracles)
+}
+
+func (c chain) PriceAt(ctx context.Context, asset money.Currency, at time.Time) (Price, error) {
+	for _, oracle := range c {
+		price, err := oracle.PriceAt(ctx, asset, at)
+		if !errors.Is(err, ErrNoPrice) {
+			return price, err
+		}
+	}
+	return Price{}, noPrice(asset, at)
+}
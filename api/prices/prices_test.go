@@ -0,0 +1,165 @@
+package prices_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/prices"
+	"github.com/aiw3/nft-solana-api/prices/pythtest"
+)
+
+const sol money.Currency = "SOL"
+
+func at(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func csvOracle(t *testing.T) *prices.CSV {
+	t.Helper()
+	oracle, err := prices.ParseCSV(strings.NewReader(`asset,time,price
+SOL,2024-02-20T13:30:00Z,150.5
+sol,2024-02-20T12:00:00Z,148.12
+JUP,1708430400000,0.58
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oracle
+}
+
+func TestCSVPriceAt(t *testing.T) {
+	oracle := csvOracle(t)
+	tests := []struct {
+		name  string
+		asset money.Currency
+		at    string
+		want  string // "" for no price
+	}{
+		{"before the first price", sol, "2024-02-20T11:59:59Z", ""},
+		{"at the first price", sol, "2024-02-20T12:00:00Z", "148.12"},
+		{"MaxAge after a price", sol, "2024-02-20T13:00:00Z", "148.12"},
+		{"beyond MaxAge", sol, "2024-02-20T13:00:01Z", ""},
+		{"at a later price", sol, "2024-02-20T13:30:00Z", "150.5"},
+		{"the last price within MaxAge", sol, "2024-02-20T14:29:59Z", "150.5"},
+		{"Unix milliseconds", "JUP", "2024-02-20T12:30:00Z", "0.58"},
+		{"unknown asset", "BONK", "2024-02-20T12:00:00Z", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := oracle.PriceAt(context.Background(), tt.asset, at(tt.at))
+			if tt.want == "" {
+				if !errors.Is(err, prices.ErrNoPrice) {
+					t.Errorf("price %v, error %v; want ErrNoPrice", price.Value, err)
+				}
+				return
+			}
+			if err != nil || price.Value != money.USDT.MustParse(tt.want) || price.Source != prices.SourceCSV {
+				t.Errorf("price %v from %s, error %v; want %s from csv", price.Value, price.Source, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVRejectsMalformedRows(t *testing.T) {
+	for name, file := range map[string]string{
+		"missing column": "asset,time\nSOL,2024-02-20T12:00:00Z\n",
+		"bad time":       "asset,time,price\nSOL,yesterday,148\n",
+		"zero price":     "asset,time,price\nSOL,2024-02-20T12:00:00Z,0\n",
+		"empty asset":    "asset,time,price\n,2024-02-20T12:00:00Z,148\n",
+		"empty file":     "",
+	} {
+		if _, err := prices.ParseCSV(strings.NewReader(file)); err == nil {
+			t.Errorf("%s: parsed, want an error", name)
+		}
+	}
+}
+
+// failing is an oracle that cannot be asked
+type failing struct{}
+
+func (failing) PriceAt(ctx context.Context, asset money.Currency, at time.Time) (prices.Price, error) {
+	return prices.Price{}, errors.New("price service unreachable")
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	oracle := prices.Chain(prices.Par(money.USDC), csvOracle(t))
+
+	price, err := oracle.PriceAt(ctx, money.USDC, at("2024-02-20T12:00:00Z"))
+	if err != nil || price.Source != prices.SourcePar || price.Value != money.USDT.Whole(1) {
+		t.Errorf("USDC: %+v, %v; want 1 at par", price, err)
+	}
+	price, err = oracle.PriceAt(ctx, sol, at("2024-02-20T12:00:00Z"))
+	if err != nil || price.Source != prices.SourceCSV {
+		t.Errorf("SOL: %+v, %v; want the csv price after par has none", price, err)
+	}
+	if _, err := oracle.PriceAt(ctx, "BONK", at("2024-02-20T12:00:00Z")); !errors.Is(err, prices.ErrNoPrice) {
+		t.Errorf("BONK: %v, want ErrNoPrice when no oracle has a price", err)
+	}
+
+	// An oracle that cannot be asked fails the chain instead of falling through
+	broken := prices.Chain(prices.Par(money.USDC), failing{}, csvOracle(t))
+	if _, err := broken.PriceAt(ctx, sol, at("2024-02-20T12:00:00Z")); err == nil || errors.Is(err, prices.ErrNoPrice) {
+		t.Errorf("SOL past a failing oracle: %v, want its error", err)
+	}
+	if _, err := broken.PriceAt(ctx, money.USDC, at("2024-02-20T12:00:00Z")); err != nil {
+		t.Errorf("USDC before a failing oracle: %v", err)
+	}
+}
+
+func TestPyth(t *testing.T) {
+	server := pythtest.NewServer()
+	defer server.Close()
+	oracle := prices.NewPyth(prices.PythConfig{BaseURL: server.URL, HTTPClient: server.Client()})
+
+	tests := []struct {
+		name  string
+		asset money.Currency
+		at    string
+		want  string // "" for no price
+	}{
+		// 108.2 USD divided by USDT's 1.0002 USD, rounded half to even
+		{"converted to USDT", sol, "2024-02-20T12:00:00Z", "108.17836433"},
+		{"USDT without asking", money.USDT, "2030-01-01T00:00:00Z", "1"},
+		{"first update more than MaxAge later", sol, "2024-02-20T10:00:00Z", ""},
+		{"after the last update", sol, "2030-01-01T00:00:00Z", ""},
+		{"asset without a feed", "DOGE", "2024-02-20T12:00:00Z", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := oracle.PriceAt(context.Background(), tt.asset, at(tt.at))
+			if tt.want == "" {
+				if !errors.Is(err, prices.ErrNoPrice) {
+					t.Errorf("price %v, error %v; want ErrNoPrice", price.Value, err)
+				}
+				return
+			}
+			if err != nil || price.Value != money.USDT.MustParse(tt.want) || price.Source != prices.SourcePyth {
+				t.Errorf("price %v from %s, error %v; want %s from pyth", price.Value, price.Source, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPythServiceErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	oracle := prices.NewPyth(prices.PythConfig{BaseURL: server.URL, HTTPClient: server.Client()})
+
+	_, err := oracle.PriceAt(context.Background(), sol, at("2024-02-20T12:00:00Z"))
+	if err == nil || errors.Is(err, prices.ErrNoPrice) || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("error %v, want the service's error so the trade is retried", err)
+	}
+}
@@ -0,0 +1,162 @@
+package prices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+const (
+	// pythBaseURL is Pyth's public Hermes price service
+	pythBaseURL = "https://hermes.pyth.network"
+	// pythTimeout bounds one call to the price service when PythConfig has no HTTP client
+	pythTimeout = 10 * time.Second
+	// maxErrorBody is how much of an error response is read for its message
+	maxErrorBody = 4096
+)
+
+// usd is the currency Pyth prices are quoted in
+const usd money.Currency = "USD"
+
+// DefaultPythFeeds are the IDs of Pyth's <asset>/USD price feeds. USDT/USD converts the other feeds'
+// USD prices to USDT, so it is always needed.
+var DefaultPythFeeds = map[money.Currency]string{
+	"BTC":  "e62df6c8b4a85fe1a67db44dc12de5db330f7ac66b72dc658afedf0f4a415b43",
+	"ETH":  "ff61491a931112ddf1bd8147cd1b641375f79f5825126d665480874634fd0ace",
+	"SOL":  "ef0d8b6fda2ceba41da15d4095d1da392a0d2f8ed0c6c7bc0f4cfac8c280b56d",
+	"USDC": "eaa020c61cc479712813461ce153894a96a6c00b21ed0cfc2798d1f9a9e9c94a",
+	"USDT": "2b89b9dc8fdf9f34709a5b106b472f0f39bb6ca9ce04b0fd7f2e971688e2e53b",
+	"JUP":  "0a0408d619e9380abad35060f9192039ed5042fa6f82301d0e48bb52be830996",
+	"BONK": "72b021217ca3fe68922a19aaf990109cb9d84e9ad004b4d2025ad6f529314419",
+}
+
+// PythConfig points the Pyth oracle at a price service. Empty fields use Hermes and DefaultPythFeeds.
+type PythConfig struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Feeds      map[money.Currency]string
+}
+
+// Pyth quotes prices from a Pyth Hermes-style price service. GET /v2/updates/price/{unix seconds} with
+// ids[] returns each feed's first price update published at or after that time. Pyth prices are in USD;
+// an asset's USDT price is its USD price divided by USDT's, both from the same request.
+type Pyth struct {
+	config PythConfig
+}
+
+// NewPyth creates a Pyth oracle
+func NewPyth(config PythConfig) *Pyth {
+	if config.BaseURL == "" {
+		config.BaseURL = pythBaseURL
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: pythTimeout}
+	}
+	if config.Feeds == nil {
+		config.Feeds = DefaultPythFeeds
+	}
+	return &Pyth{config: config}
+}
+
+// pythUpdates is the response of /v2/updates/price, parsed=true; the binary update data is not read
+type pythUpdates struct {
+	Parsed []struct {
+		ID    string `json:"id"`
+		Price struct {
+			Price       string `json:"price"`
+			Expo        int    `json:"expo"`
+			PublishTime int64  `json:"publish_time"`
+		} `json:"price"`
+	} `json:"parsed"`
+}
+
+func (p *Pyth) PriceAt(ctx context.Context, asset money.Currency, at time.Time) (Price, error) {
+	if asset == money.USDT {
+		return Price{Asset: asset, Value: money.USDT.Whole(1), At: at, Source: SourcePyth}, nil
+	}
+	feed, ok := p.config.Feeds[asset]
+	usdtFeed := p.config.Feeds[money.USDT]
+	if !ok || usdtFeed == "" {
+		return Price{}, noPrice(asset, at)
+	}
+
+	query := url.Values{"ids[]": {feed, usdtFeed}, "parsed": {"true"}}
+	endpoint := fmt.Sprintf("%s/v2/updates/price/%d?%s", p.config.BaseURL, at.Unix(), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Price{}, err
+	}
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return Price{}, fmt.Errorf("pyth: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		// Hermes has no update for feeds that did not exist yet, or for times it has not reached
+		return Price{}, noPrice(asset, at)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return Price{}, fmt.Errorf("pyth: %s (HTTP %d)", strings.TrimSpace(string(body)), resp.StatusCode)
+	}
+	var updates pythUpdates
+	if err := json.NewDecoder(resp.Body).Decode(&updates); err != nil {
+		return Price{}, fmt.Errorf("pyth: decode response: %w", err)
+	}
+
+	prices := map[string]money.Amount{}
+	var published time.Time
+	for _, update := range updates.Parsed {
+		id := strings.TrimPrefix(strings.ToLower(update.ID), "0x")
+		publishedAt := time.Unix(update.Price.PublishTime, 0).UTC()
+		if publishedAt.Sub(at).Abs() > MaxAge {
+			continue
+		}
+		price, err := pythPrice(update.Price.Price, update.Price.Expo)
+		if err != nil {
+			return Price{}, fmt.Errorf("pyth: feed %s: %w", id, err)
+		}
+		prices[id] = price
+		if id == feed {
+			published = publishedAt
+		}
+	}
+	value, usdt := prices[feed], prices[usdtFeed]
+	if value.Sign() <= 0 || usdt.Sign() <= 0 {
+		return Price{}, noPrice(asset, at)
+	}
+	inUSDT := value.MulFrac(money.USDT.Whole(1).Units(), usdt.Units(), money.HalfEven)
+	return Price{Asset: asset, Value: money.USDT.Units(inUSDT.Units()), At: published, Source: SourcePyth}, nil
+}
+
+// pythPrice reads a Pyth price, an integer scaled by 10^expo, as a USD amount
+func pythPrice(value string, expo int) (money.Amount, error) {
+	mantissa, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return money.Amount{}, fmt.Errorf("price %q is not an integer", value)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(expo))), nil)
+	r := new(big.Rat).SetInt(mantissa)
+	if expo < 0 {
+		r.Quo(r, new(big.Rat).SetInt(scale))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(scale))
+	}
+	return usd.Parse(r.FloatString(max(-expo, 0)))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
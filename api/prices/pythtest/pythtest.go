@@ -0,0 +1,113 @@
+// Package pythtest provides a stand-in for a Pyth Hermes price service. It serves price updates built from
+// recorded USD prices of the assets of prices.DefaultPythFeeds, every 15 minutes around the times of the
+// repository's recorded trades, so the Pyth oracle and the volume pipeline can be exercised offline.
+package pythtest
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/prices"
+)
+
+//go:embed usd-prices.csv
+var recorded string
+
+// update is a recorded price: the price scaled by 10^-expo, as Pyth publishes it
+type update struct {
+	price       string
+	expo        int
+	publishTime int64
+}
+
+// NewServer starts a stand-in price service; point the Pyth oracle's BaseURL at its URL
+func NewServer() *httptest.Server {
+	return httptest.NewServer(Handler())
+}
+
+// Handler serves /v2/updates/price/{publish_time}: each requested feed's first recorded update published
+// at or after that time. Unknown feeds, and times after the recorded updates, are answered 404 like Hermes.
+func Handler() http.Handler {
+	updates := load()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/updates/price/", func(w http.ResponseWriter, r *http.Request) {
+		at, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/v2/updates/price/"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid publish_time", http.StatusBadRequest)
+			return
+		}
+		ids := r.URL.Query()["ids[]"]
+		if len(ids) == 0 {
+			http.Error(w, "Missing ids[]", http.StatusBadRequest)
+			return
+		}
+
+		parsed := []map[string]any{}
+		for _, id := range ids {
+			id = strings.TrimPrefix(strings.ToLower(id), "0x")
+			series, ok := updates[id]
+			if !ok {
+				http.Error(w, "Price ids not found: "+id, http.StatusNotFound)
+				return
+			}
+			i := sort.Search(len(series), func(i int) bool { return series[i].publishTime >= at })
+			if i == len(series) {
+				http.Error(w, fmt.Sprintf("No price update for %s at or after %d", id, at), http.StatusNotFound)
+				return
+			}
+			price := map[string]any{"price": series[i].price, "conf": "0", "expo": series[i].expo,
+				"publish_time": series[i].publishTime}
+			parsed = append(parsed, map[string]any{"id": id, "price": price, "ema_price": price,
+				"metadata": map[string]any{"slot": 0, "proof_available_time": series[i].publishTime,
+					"prev_publish_time": series[i].publishTime - 1}})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"binary": map[string]any{"encoding": "hex", "data": []string{}},
+			"parsed": parsed,
+		})
+	})
+	return mux
+}
+
+// load reads the recorded prices into updates by feed ID, oldest first. The file is embedded, so a
+// malformed row is a bug and panics.
+func load() map[string][]update {
+	rows, err := csv.NewReader(strings.NewReader(recorded)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("pythtest: %v", err))
+	}
+	updates := map[string][]update{}
+	for _, row := range rows[1:] {
+		feed, ok := prices.DefaultPythFeeds[money.Currency(row[0])]
+		if !ok {
+			panic(fmt.Sprintf("pythtest: no feed for %s", row[0]))
+		}
+		published, err := time.Parse(time.RFC3339, row[1])
+		if err != nil {
+			panic(fmt.Sprintf("pythtest: %v", err))
+		}
+		expo, err := strconv.Atoi(row[3])
+		if err != nil {
+			panic(fmt.Sprintf("pythtest: %v", err))
+		}
+		value, ok := new(big.Rat).SetString(row[2])
+		if !ok {
+			panic(fmt.Sprintf("pythtest: price %q is not a decimal", row[2]))
+		}
+		scaled := value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-expo)), nil)))
+		updates[feed] = append(updates[feed], update{price: scaled.FloatString(0), expo: expo,
+			publishTime: published.Unix()})
+	}
+	return updates
+}
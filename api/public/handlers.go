@@ -2,8 +2,15 @@ package public
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/aiw3/nft-solana-api/badges"
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
 	"github.com/aiw3/nft-solana-api/shared"
 	"github.com/swaggest/usecase"
 	"github.com/swaggest/usecase/status"
@@ -72,15 +79,14 @@ func GetPlatformHealth() usecase.Interactor {
 }
 
 // GetUserProfile returns public user profile information
-func GetUserProfile() usecase.Interactor {
+func GetUserProfile(store *repository.Store) usecase.Interactor {
 	type getUserProfileRequest struct {
 		UserID int `path:"userId" required:"true" description:"User ID to get profile for"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req getUserProfileRequest, resp *UserProfileResponse) error {
-		// Generate mock user profile
-		profile := generateMockUserProfile(req.UserID)
-		if profile == nil {
+		user, err := store.Users.GetByID(ctx, req.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
 			*resp = UserProfileResponse{
 				Code:    404,
 				Message: "User not found",
@@ -88,11 +94,19 @@ func GetUserProfile() usecase.Interactor {
 			}
 			return nil
 		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		profile, err := buildUserProfile(ctx, store, user)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
 		*resp = UserProfileResponse{
 			Code:    200,
 			Message: fmt.Sprintf("User profile for user %d retrieved successfully", req.UserID),
-			Data:    *profile,
+			Data:    profile,
 		}
 		return nil
 	})
@@ -173,7 +187,7 @@ func GetLeaderboard() usecase.Interactor {
 }
 
 // SearchUsers returns public user search results
-func SearchUsers() usecase.Interactor {
+func SearchUsers(store *repository.Store) usecase.Interactor {
 	type searchUsersRequest struct {
 		Query  string `query:"q" required:"true" description:"Search query (username, email, etc.)"`
 		Limit  *int   `query:"limit" description:"Number of results to return"`
@@ -201,18 +215,35 @@ func SearchUsers() usecase.Interactor {
 			offset = *req.Offset
 		}
 
-		// Generate mock search results
-		users := generateMockUserSearchResults(req.Query)
+		started := time.Now()
+		allUsers, err := store.Users.List(ctx)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		users := []UserBasicInfo{}
+		usernames := []string{}
+		for _, user := range allUsers {
+			usernames = append(usernames, user.Username)
+			if !containsIgnoreCase(user.Username, req.Query) && !containsIgnoreCase(user.Nickname, req.Query) && !containsIgnoreCase(user.Email, req.Query) {
+				continue
+			}
+			info, err := toUserBasicInfo(ctx, store, &user)
+			if err != nil {
+				return status.Wrap(err, status.Internal)
+			}
+			users = append(users, info)
+		}
 
 		*resp = SearchUsersResponse{
 			Code:    200,
 			Message: fmt.Sprintf("Search results for '%s' retrieved successfully", req.Query),
 			Data: SearchUsersData{
 				Query:       req.Query,
-				Users:       users,
+				Users:       paginate(users, limit, offset),
 				TotalCount:  len(users),
-				SearchTime:  "0.045s",
-				Suggestions: generateSearchSuggestions(req.Query),
+				SearchTime:  fmt.Sprintf("%.3fs", time.Since(started).Seconds()),
+				Suggestions: generateSearchSuggestions(usernames, req.Query),
 				Pagination: Pagination{
 					Total:   len(users),
 					Limit:   limit,
@@ -268,7 +299,7 @@ func GetApiInfo() usecase.Interactor {
 // ==========================================
 
 // AuthenticateUser handles user authentication
-func AuthenticateUser() usecase.Interactor {
+func AuthenticateUser(store *repository.Store) usecase.Interactor {
 	type authenticateUserRequest struct {
 		WalletAddress string `json:"wallet_address" required:"true" description:"Solana wallet address"`
 		Signature     string `json:"signature" required:"true" description:"Signed message for authentication"`
@@ -297,21 +328,22 @@ func AuthenticateUser() usecase.Interactor {
 			return nil
 		}
 
-		// Mock user lookup or creation
-		user := mockGetOrCreateUser(req.WalletAddress)
-		accessToken := generateMockAccessToken(user.ID)
+		// Look up the wallet owner, registering a new user on first sign-in
+		user, isNewUser, err := getOrCreateUser(ctx, store, req.WalletAddress)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+
+		data, err := issueTokens(ctx, store, user)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
+		data.IsNewUser = isNewUser
 
 		*resp = AuthenticationResponse{
 			Code:    200,
 			Message: "Authentication successful",
-			Data: AuthenticationData{
-				Success:      true,
-				AccessToken:  accessToken,
-				RefreshToken: generateMockRefreshToken(user.ID),
-				ExpiresIn:    3600, // 1 hour
-				User:         user,
-				IsNewUser:    user.ID > 50000, // Mock logic for new users
-			},
+			Data:    data,
 		}
 		return nil
 	})
@@ -325,14 +357,14 @@ func AuthenticateUser() usecase.Interactor {
 }
 
 // RefreshToken handles token refresh
-func RefreshToken() usecase.Interactor {
+func RefreshToken(store *repository.Store) usecase.Interactor {
 	type refreshTokenRequest struct {
 		RefreshToken string `json:"refresh_token" required:"true" description:"Refresh token for getting new access token"`
 	}
 
 	u := usecase.NewInteractor(func(ctx context.Context, req refreshTokenRequest, resp *AuthenticationResponse) error {
 		// Validate refresh token
-		userID, valid := mockValidateRefreshToken(req.RefreshToken)
+		userID, valid := parseRefreshToken(req.RefreshToken)
 		if !valid {
 			*resp = AuthenticationResponse{
 				Code:    401,
@@ -342,9 +374,8 @@ func RefreshToken() usecase.Interactor {
 			return nil
 		}
 
-		// Generate new tokens
-		user := mockGetUserByID(userID)
-		if user == nil {
+		user, err := store.Users.GetByID(ctx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
 			*resp = AuthenticationResponse{
 				Code:    404,
 				Message: "User not found",
@@ -352,21 +383,20 @@ func RefreshToken() usecase.Interactor {
 			}
 			return nil
 		}
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
-		accessToken := generateMockAccessToken(user.ID)
-		refreshToken := generateMockRefreshToken(user.ID)
+		// Generate new tokens
+		data, err := issueTokens(ctx, store, user)
+		if err != nil {
+			return status.Wrap(err, status.Internal)
+		}
 
 		*resp = AuthenticationResponse{
 			Code:    200,
 			Message: "Token refreshed successfully",
-			Data: AuthenticationData{
-				Success:      true,
-				AccessToken:  accessToken,
-				RefreshToken: refreshToken,
-				ExpiresIn:    3600, // 1 hour
-			User:         *user,
-				IsNewUser:    false,
-			},
+			Data:    data,
 		}
 		return nil
 	})
@@ -387,39 +417,39 @@ func RefreshToken() usecase.Interactor {
 func generateMockPublicStats(timeframe *string, category *string) PublicStatsData {
 	return PublicStatsData{
 		Platform: PlatformStats{
-			TotalUsers:           15420,
-			ActiveUsers:          8950,
-			TotalTradingVolume:   12500000.75,
-			TotalNftsIssued:      87350,
-			TotalBadgesEarned:    156780,
-			CompetitionsHeld:     48,
-			AverageUserRating:    4.7,
-			PlatformFees:         125000.50,
+			TotalUsers:         15420,
+			ActiveUsers:        8950,
+			TotalTradingVolume: money.USDT.MustParse("12500000.75"),
+			TotalNftsIssued:    87350,
+			TotalBadgesEarned:  156780,
+			CompetitionsHeld:   48,
+			AverageUserRating:  4.7,
+			PlatformFees:       money.USDT.MustParse("125000.5"),
 		},
 		RecentActivity: []map[string]interface{}{
 			{
-				"type":        "user_registration",
-				"count":       234,
-				"timeframe":   "24h",
-				"growth":      "+12%",
+				"type":      "user_registration",
+				"count":     234,
+				"timeframe": "24h",
+				"growth":    "+12%",
 			},
 			{
-				"type":        "nft_claims",
-				"count":       89,
-				"timeframe":   "24h",
-				"growth":      "+25%",
+				"type":      "nft_claims",
+				"count":     89,
+				"timeframe": "24h",
+				"growth":    "+25%",
 			},
 			{
-				"type":        "badge_unlocks",
-				"count":       156,
-				"timeframe":   "24h",
-				"growth":      "+8%",
+				"type":      "badge_unlocks",
+				"count":     156,
+				"timeframe": "24h",
+				"growth":    "+8%",
 			},
 			{
-				"type":        "trading_volume",
-				"amount":      45000.75,
-				"timeframe":   "24h",
-				"growth":      "+18%",
+				"type":      "trading_volume",
+				"amount":    45000.75,
+				"timeframe": "24h",
+				"growth":    "+18%",
 			},
 		},
 		TopCategories: []map[string]interface{}{
@@ -429,8 +459,8 @@ func generateMockPublicStats(timeframe *string, category *string) PublicStatsDat
 			{"name": "Competition", "users": 5432, "percentage": 35.2},
 		},
 		Growth: map[string]interface{}{
-			"userGrowth":    map[string]float64{"daily": 2.3, "weekly": 15.7, "monthly": 47.2},
-			"volumeGrowth":  map[string]float64{"daily": 5.8, "weekly": 23.4, "monthly": 89.1},
+			"userGrowth":       map[string]float64{"daily": 2.3, "weekly": 15.7, "monthly": 47.2},
+			"volumeGrowth":     map[string]float64{"daily": 5.8, "weekly": 23.4, "monthly": 89.1},
 			"engagementGrowth": map[string]float64{"daily": 1.9, "weekly": 12.3, "monthly": 38.7},
 		},
 		LastUpdated: shared.GetCurrentTimestamp(),
@@ -481,155 +511,63 @@ func generateMockPlatformHealth(checkType string) PlatformHealthData {
 	return health
 }
 
-// generateMockUserProfile creates mock user profile data
-func generateMockUserProfile(userID int) *UserProfileData {
-	// Mock user database lookup
-	profiles := map[int]UserProfileData{
-		12345: {
-			User: UserBasicInfo{
-				ID:       12345,
-				Username: "crypto_trader_01",
-				Email:    shared.StringPtr("trader@example.com"),
-				Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar123"),
-			},
-			Stats: UserStats{
-				TradingVolume:    1250000.50,
-				NftCount:         5,
-				BadgeCount:       15,
-				CompetitionWins:  3,
-				JoinedDate:       "2023-06-15T10:30:00.000Z",
-				LastActiveDate:   "2024-01-20T15:45:00.000Z",
-				ProfileViews:     1247,
-				Rank:             42,
-			},
-			Achievements: []map[string]interface{}{
-				{
-					"type":        "badge",
-					"name":        "Volume King",
-					"description": "Achieved $100K in trading volume",
-					"earnedAt":    "2024-01-20T14:45:00.000Z",
-					"rarity":      "Legendary",
-				},
-				{
-					"type":        "nft",
-					"name":        "Golden Trader",
-					"description": "Level 3 Tiered NFT",
-					"claimedAt":   "2024-01-15T10:30:00.000Z",
-					"level":       3,
-				},
-				{
-					"type":        "competition",
-					"name":        "Q1 Trading Champion",
-					"description": "1st place in Q1 2024 Trading Championship",
-					"awardedAt":   "2024-01-31T20:00:00.000Z",
-					"rank":        1,
-				},
-			},
-			Preferences: map[string]interface{}{
-				"publicProfile":    true,
-				"showTradingStats": true,
-				"showNftCollection": true,
-				"showBadges":       true,
-			},
-		},
-		67890: {
-			User: UserBasicInfo{
-				ID:       67890,
-				Username: "defi_master",
-				Email:    shared.StringPtr("defi@example.com"),
-				Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar456"),
-			},
-			Stats: UserStats{
-				TradingVolume:    750000.25,
-				NftCount:         3,
-				BadgeCount:       12,
-				CompetitionWins:  1,
-				JoinedDate:       "2023-08-20T14:20:00.000Z",
-				LastActiveDate:   "2024-01-19T12:30:00.000Z",
-				ProfileViews:     892,
-				Rank:             78,
-			},
-			Achievements: []map[string]interface{}{
-				{
-					"type":        "badge",
-					"name":        "Community Builder",
-					"description": "Invited 10+ friends to the platform",
-					"earnedAt":    "2024-01-19T16:20:00.000Z",
-					"rarity":      "Rare",
-				},
-			},
-			Preferences: map[string]interface{}{
-				"publicProfile":    true,
-				"showTradingStats": false,
-				"showNftCollection": true,
-				"showBadges":       true,
-			},
-		},
-	}
-
-	if profile, exists := profiles[userID]; exists {
-		return &profile
-	}
-	return nil
-}
-
 // generateMockLeaderboard creates mock leaderboard data
 func generateMockLeaderboard(leaderboardType, timeframe string) []map[string]interface{} {
 	baseLeaderboard := []map[string]interface{}{
 		{
-			"userId":       12345,
-			"username":     "crypto_trader_01",
-			"avatar":       "https://ipfs.io/ipfs/QmUserAvatar123",
-			"rank":         1,
-			"score":        125000.50,
-			"change":       "+2",
-			"badges":       15,
-			"nftLevel":     3,
-			"winStreak":    7,
+			"userId":    12345,
+			"username":  "crypto_trader_01",
+			"avatar":    "https://ipfs.io/ipfs/QmUserAvatar123",
+			"rank":      1,
+			"score":     125000.50,
+			"change":    "+2",
+			"badges":    15,
+			"nftLevel":  3,
+			"winStreak": 7,
 		},
 		{
-			"userId":       67890,
-			"username":     "defi_master",
-			"avatar":       "https://ipfs.io/ipfs/QmUserAvatar456",
-			"rank":         2,
-			"score":        98750.25,
-			"change":       "+1",
-			"badges":       12,
-			"nftLevel":     2,
-			"winStreak":    3,
+			"userId":    67890,
+			"username":  "defi_master",
+			"avatar":    "https://ipfs.io/ipfs/QmUserAvatar456",
+			"rank":      2,
+			"score":     98750.25,
+			"change":    "+1",
+			"badges":    12,
+			"nftLevel":  2,
+			"winStreak": 3,
 		},
 		{
-			"userId":       11111,
-			"username":     "nft_collector",
-			"avatar":       "https://ipfs.io/ipfs/QmUserAvatar789",
-			"rank":         3,
-			"score":        87500.75,
-			"change":       "-1",
-			"badges":       8,
-			"nftLevel":     1,
-			"winStreak":    1,
+			"userId":    11111,
+			"username":  "nft_collector",
+			"avatar":    "https://ipfs.io/ipfs/QmUserAvatar789",
+			"rank":      3,
+			"score":     87500.75,
+			"change":    "-1",
+			"badges":    8,
+			"nftLevel":  1,
+			"winStreak": 1,
 		},
 		{
-			"userId":       22222,
-			"username":     "trading_pro",
-			"avatar":       "https://ipfs.io/ipfs/QmUserAvatar999",
-			"rank":         4,
-			"score":        76250.00,
-			"change":       "=",
-			"badges":       10,
-			"nftLevel":     2,
-			"winStreak":    0,
+			"userId":    22222,
+			"username":  "trading_pro",
+			"avatar":    "https://ipfs.io/ipfs/QmUserAvatar999",
+			"rank":      4,
+			"score":     76250.00,
+			"change":    "=",
+			"badges":    10,
+			"nftLevel":  2,
+			"winStreak": 0,
 		},
 		{
-			"userId":       33333,
-			"username":     "volume_hunter",
-			"avatar":       "https://ipfs.io/ipfs/QmUserAvatar555",
-			"rank":         5,
-			"score":        65000.80,
-			"change":       "+3",
-			"badges":       6,
-			"nftLevel":     1,
-			"winStreak":    2,
+			"userId":    33333,
+			"username":  "volume_hunter",
+			"avatar":    "https://ipfs.io/ipfs/QmUserAvatar555",
+			"rank":      5,
+			"score":     65000.80,
+			"change":    "+3",
+			"badges":    6,
+			"nftLevel":  1,
+			"winStreak": 2,
 		},
 	}
 
@@ -660,59 +598,11 @@ func generateMockLeaderboard(leaderboardType, timeframe string) []map[string]int
 	return baseLeaderboard
 }
 
-// generateMockUserSearchResults creates mock user search results
-func generateMockUserSearchResults(query string) []UserBasicInfo {
-	allUsers := []UserBasicInfo{
-		{
-			ID:       12345,
-			Username: "crypto_trader_01",
-			Email:    shared.StringPtr("trader@example.com"),
-			Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar123"),
-		},
-		{
-			ID:       67890,
-			Username: "defi_master",
-			Email:    shared.StringPtr("defi@example.com"),
-			Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar456"),
-		},
-		{
-			ID:       11111,
-			Username: "nft_collector",
-			Email:    shared.StringPtr("collector@example.com"),
-			Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar789"),
-		},
-		{
-			ID:       22222,
-			Username: "trading_pro",
-			Email:    shared.StringPtr("pro@example.com"),
-			Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar999"),
-		},
-	}
-
-	// Simple search filtering (in real implementation, would use proper search)
-	results := []UserBasicInfo{}
-	for _, user := range allUsers {
-		if containsIgnoreCase(user.Username, query) {
-			results = append(results, user)
-		}
-	}
-
-	return results
-}
-
-// generateSearchSuggestions creates search suggestions
-func generateSearchSuggestions(query string) []string {
-	suggestions := []string{
-		"crypto_trader_01",
-		"defi_master",
-		"nft_collector",
-		"trading_pro",
-		"volume_hunter",
-	}
-
+// generateSearchSuggestions creates search suggestions from known usernames
+func generateSearchSuggestions(usernames []string, query string) []string {
 	// Filter suggestions based on query
 	filtered := []string{}
-	for _, suggestion := range suggestions {
+	for _, suggestion := range usernames {
 		if containsIgnoreCase(suggestion, query) && suggestion != query {
 			filtered = append(filtered, suggestion)
 		}
@@ -735,15 +625,15 @@ func generateApiInfo(includeEndpoints bool) ApiInfoData {
 		Status:      "operational",
 		Uptime:      "99.97%",
 		Documentation: map[string]interface{}{
-			"swagger":    "/swagger",
-			"redoc":      "/redoc",
-			"postman":    "/api/postman-collection.json",
-			"github":     "https://github.com/aiw3/nft-solana-api",
+			"swagger": "/swagger",
+			"redoc":   "/redoc",
+			"postman": "/api/postman-collection.json",
+			"github":  "https://github.com/aiw3/nft-solana-api",
 		},
 		RateLimits: map[string]interface{}{
 			"authenticated":   "1000 requests per hour",
 			"unauthenticated": "100 requests per hour",
-			"burst":          "50 requests per minute",
+			"burst":           "50 requests per minute",
 		},
 		Features: []string{
 			"Tiered NFT System",
@@ -758,10 +648,10 @@ func generateApiInfo(includeEndpoints bool) ApiInfoData {
 	if includeEndpoints {
 		info.Endpoints = map[string]interface{}{
 			"authentication": []string{"/auth/login", "/auth/refresh"},
-			"nfts":          []string{"/nfts/portfolio/{userId}", "/nfts/claim", "/nfts/upgrade"},
-			"badges":        []string{"/badges/stats", "/badges/user/{userId}", "/badges/activate"},
-			"admin":         []string{"/admin/nft/upload", "/admin/users/status", "/admin/competition/award"},
-			"public":        []string{"/public/stats", "/public/health", "/public/leaderboard"},
+			"nfts":           []string{"/nfts/portfolio/{userId}", "/nfts/claim", "/nfts/upgrade"},
+			"badges":         []string{"/badges/stats", "/badges/user/{userId}", "/badges/activate"},
+			"admin":          []string{"/admin/nft/upload", "/admin/users/status", "/admin/competition/award"},
+			"public":         []string{"/public/stats", "/public/health", "/public/leaderboard"},
 		}
 	}
 
@@ -776,63 +666,226 @@ func mockVerifySignature(walletAddress, signature, message string) bool {
 	return len(signature) > 50 && len(message) > 10
 }
 
-// mockGetOrCreateUser simulates user lookup or creation
-func mockGetOrCreateUser(walletAddress string) UserBasicInfo {
-	// Mock user creation/lookup logic
-	userID := len(walletAddress) * 1000 // Simple hash-like ID generation
-	return UserBasicInfo{
-		ID:       userID,
-		Username: fmt.Sprintf("user_%s", walletAddress[0:8]),
-		Email:    nil, // New users might not have email initially
-		Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmDefaultAvatar"),
+// getOrCreateUser returns the user owning a wallet, registering a new one if none exists
+func getOrCreateUser(ctx context.Context, store *repository.Store, walletAddress string) (*repository.User, bool, error) {
+	user, err := store.Users.GetByWallet(ctx, walletAddress)
+	if err == nil {
+		return user, false, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, false, err
 	}
-}
 
-// generateMockAccessToken creates a mock access token
-func generateMockAccessToken(userID int) string {
-	return fmt.Sprintf("access_token_%d_%d", userID, shared.GetCurrentTimestamp())
+	now := time.Now().UTC()
+	user = &repository.User{
+		Username:        fmt.Sprintf("user_%s", walletAddress[0:8]),
+		Nickname:        fmt.Sprintf("user_%s", walletAddress[0:8]),
+		WalletAddr:      walletAddress,
+		ProfilePhotoURL: "https://ipfs.io/ipfs/QmDefaultAvatar",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := store.Users.Save(ctx, user); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
 }
 
-// generateMockRefreshToken creates a mock refresh token
-func generateMockRefreshToken(userID int) string {
-	return fmt.Sprintf("refresh_token_%d_%d", userID, shared.GetCurrentTimestamp())
+// issueTokens stores a fresh access token for the user and returns the authentication payload
+func issueTokens(ctx context.Context, store *repository.Store, user *repository.User) (AuthenticationData, error) {
+	now := time.Now().UTC()
+	user.AccessToken = fmt.Sprintf("access_token_%d_%d", user.ID, now.Unix())
+	user.UpdatedAt = now
+	if err := store.Users.Save(ctx, user); err != nil {
+		return AuthenticationData{}, err
+	}
+
+	info, err := toUserBasicInfo(ctx, store, user)
+	if err != nil {
+		return AuthenticationData{}, err
+	}
+
+	return AuthenticationData{
+		Success:      true,
+		AccessToken:  user.AccessToken,
+		RefreshToken: fmt.Sprintf("refresh_token_%d_%d", user.ID, now.Unix()),
+		ExpiresIn:    3600, // 1 hour
+		User:         info,
+	}, nil
 }
 
-// mockValidateRefreshToken validates a refresh token
-func mockValidateRefreshToken(refreshToken string) (int, bool) {
-	// Extract user ID from token (mock implementation)
-	if len(refreshToken) < 20 {
+// parseRefreshToken extracts the user ID from a refresh_token_<userId>_<issuedAt> token
+func parseRefreshToken(refreshToken string) (int, bool) {
+	parts := strings.Split(strings.TrimPrefix(refreshToken, "refresh_token_"), "_")
+	if !strings.HasPrefix(refreshToken, "refresh_token_") || len(parts) != 2 {
+		return 0, false
+	}
+
+	userID, err := strconv.Atoi(parts[0])
+	if err != nil || userID <= 0 {
+		return 0, false
+	}
+	issuedAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Since(time.Unix(issuedAt, 0)) > refreshTokenTTL {
 		return 0, false
 	}
-	
-	// Mock validation logic
-	userID := 12345 // Would parse from actual token
 	return userID, true
 }
 
-// mockGetUserByID gets user by ID
-func mockGetUserByID(userID int) *UserBasicInfo {
-	users := map[int]UserBasicInfo{
-		12345: {
-			ID:       12345,
-			Username: "crypto_trader_01",
-			Email:    shared.StringPtr("trader@example.com"),
-			Avatar:   shared.StringPtr("https://ipfs.io/ipfs/QmUserAvatar123"),
-		},
+// refreshTokenTTL is how long a refresh token stays valid after it is issued
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// toUserBasicInfo converts a stored user to its public representation
+func toUserBasicInfo(ctx context.Context, store *repository.Store, user *repository.User) (UserBasicInfo, error) {
+	info := UserBasicInfo{
+		ID:              user.ID,
+		UserID:          user.ID,
+		Username:        user.Username,
+		WalletAddr:      user.WalletAddr,
+		Nickname:        user.Nickname,
+		Bio:             user.Bio,
+		ProfilePhotoURL: user.ProfilePhotoURL,
+		BannerURL:       user.BannerURL,
+		AvatarURI:       user.ProfilePhotoURL,
+	}
+	if user.Email != "" {
+		info.Email = shared.StringPtr(user.Email)
+	}
+	if user.ProfilePhotoURL != "" {
+		info.Avatar = shared.StringPtr(user.ProfilePhotoURL)
+	}
+
+	nfts, err := store.TieredNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return UserBasicInfo{}, err
+	}
+	for _, nft := range nfts {
+		if nft.Status == repository.NftStatusActive {
+			info.HasActiveNft = true
+			info.ActiveNftLevel = nft.Level
+			info.ActiveNftName = nft.Name
+			info.NftAvatarURI = nft.ImageURI
+		}
+	}
+	return info, nil
+}
+
+// buildUserProfile assembles the public profile of a user from stored data
+func buildUserProfile(ctx context.Context, store *repository.Store, user *repository.User) (UserProfileData, error) {
+	info, err := toUserBasicInfo(ctx, store, user)
+	if err != nil {
+		return UserProfileData{}, err
 	}
-	
-	if user, exists := users[userID]; exists {
-		return &user
+
+	tieredNfts, err := store.TieredNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return UserProfileData{}, err
+	}
+	competitionNfts, err := store.CompetitionNfts.ListByUser(ctx, user.ID)
+	if err != nil {
+		return UserProfileData{}, err
 	}
-	return nil
+	userBadges, err := store.Badges.ListByUser(ctx, user.ID)
+	if err != nil {
+		return UserProfileData{}, err
+	}
+
+	// Rank users by trading volume
+	allUsers, err := store.Users.List(ctx)
+	if err != nil {
+		return UserProfileData{}, err
+	}
+	rank := 1
+	for _, other := range allUsers {
+		higher, err := other.TradingVolume.Cmp(user.TradingVolume)
+		if err != nil {
+			return UserProfileData{}, err
+		}
+		if higher > 0 {
+			rank++
+		}
+	}
+
+	achievements := []map[string]interface{}{}
+	for _, userBadge := range userBadges {
+		def, err := store.Badges.GetDefinition(ctx, userBadge.BadgeID)
+		if err != nil {
+			return UserProfileData{}, err
+		}
+		if def, err = badges.EarnedDefinition(ctx, store, def, &userBadge); err != nil {
+			return UserProfileData{}, err
+		}
+		achievements = append(achievements, map[string]interface{}{
+			"type":        "badge",
+			"name":        def.Name,
+			"description": def.Description,
+			"earnedAt":    shared.FormatTimestampPtr(userBadge.EarnedAt),
+		})
+	}
+	for _, nft := range tieredNfts {
+		if nft.Status != repository.NftStatusActive {
+			continue
+		}
+		achievements = append(achievements, map[string]interface{}{
+			"type":        "nft",
+			"name":        nft.Name,
+			"description": fmt.Sprintf("Level %d Tiered NFT", nft.Level),
+			"claimedAt":   shared.FormatTimestamp(nft.MintedAt),
+			"level":       nft.Level,
+		})
+	}
+	competitionWins := 0
+	for _, nft := range competitionNfts {
+		if nft.Rank == 1 {
+			competitionWins++
+		}
+		achievements = append(achievements, map[string]interface{}{
+			"type":        "competition",
+			"name":        nft.Name,
+			"description": fmt.Sprintf("Rank %d in %s", nft.Rank, nft.CompetitionName),
+			"awardedAt":   shared.FormatTimestamp(nft.MintedAt),
+			"rank":        nft.Rank,
+		})
+	}
+
+	return UserProfileData{
+		User: info,
+		Stats: UserStats{
+			TradingVolume:   user.TradingVolume,
+			NftCount:        len(tieredNfts) + len(competitionNfts),
+			BadgeCount:      len(userBadges),
+			CompetitionWins: competitionWins,
+			JoinedDate:      shared.FormatTimestamp(user.CreatedAt),
+			LastActiveDate:  shared.FormatTimestamp(user.UpdatedAt),
+			Rank:            rank,
+		},
+		Achievements: achievements,
+		Preferences: map[string]interface{}{
+			"publicProfile":     true,
+			"showTradingStats":  true,
+			"showNftCollection": true,
+			"showBadges":        true,
+		},
+	}, nil
 }
 
 // Utility functions
 
 // containsIgnoreCase checks if a string contains a substring (case insensitive)
 func containsIgnoreCase(s, substr string) bool {
-	// Simple implementation - would use strings.ToLower in real code
-	return len(s) > 0 && len(substr) > 0
+	return substr != "" && strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// paginate returns the requested page of a slice
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
 }
 
 // calculateAverageScore calculates average score from leaderboard
@@ -840,7 +893,7 @@ func calculateAverageScore(leaderboard []map[string]interface{}) float64 {
 	if len(leaderboard) == 0 {
 		return 0.0
 	}
-	
+
 	total := 0.0
 	count := 0
 	for _, entry := range leaderboard {
@@ -849,7 +902,7 @@ func calculateAverageScore(leaderboard []map[string]interface{}) float64 {
 			count++
 		}
 	}
-	
+
 	if count == 0 {
 		return 0.0
 	}
@@ -1,5 +1,7 @@
 package public
 
+import "github.com/aiw3/nft-solana-api/money"
+
 // ==========================================
 // COMMON RESPONSE TYPES
 // ==========================================
@@ -39,7 +41,7 @@ type UserBasicInfo struct {
 	Avatar          *string `json:"avatar,omitempty" example:"https://cdn.example.com/avatars/user.jpg" description:"User's avatar image URL" format:"uri"`
 	NftAvatarURI    string  `json:"nftAvatarUri" example:"https://cdn.example.com/nfts/quantum-alchemist.jpg" description:"NFT-based avatar image URL (may be same as avatarUri)" format:"uri"`
 	HasActiveNft    bool    `json:"hasActiveNft" example:"true" description:"Whether user currently has an active/equipped NFT"`
-	ActiveNftLevel  int     `json:"activeNftLevel" example:"3" description:"Level of currently active NFT (1-5), 0 if no active NFT" minimum:"0" maximum:"5"`
+	ActiveNftLevel  int     `json:"activeNftLevel" example:"3" description:"Level of currently active NFT (1-5), 0 if no active NFT" minimum:"0"`
 	ActiveNftName   string  `json:"activeNftName" example:"On-chain Hunter" description:"Name of currently active NFT, empty if no active NFT" maxLength:"100"`
 	IsOwnProfile    bool    `json:"isOwnProfile" example:"true" description:"Whether this profile belongs to the requesting user (affects UI permissions)"`
 	CanFollow       bool    `json:"canFollow" example:"false" description:"Whether the requesting user can follow this profile (false for own profile)"`
@@ -66,7 +68,7 @@ type Pagination struct {
 // Metadata represents additional metadata
 type Metadata struct {
 	TotalNfts              int     `json:"totalNfts" example:"2" description:"Total number of NFTs owned by user (tiered + competition)" minimum:"0"`
-	HighestTierLevel       int     `json:"highestTierLevel" example:"3" description:"Highest NFT tier level achieved by user" minimum:"0" maximum:"5"`
+	HighestTierLevel       int     `json:"highestTierLevel" example:"3" description:"Highest NFT tier level achieved by user" minimum:"0"`
 	TotalBadges            int     `json:"totalBadges" example:"5" description:"Total badges available to user across all levels" minimum:"0"`
 	ActivatedBadges        int     `json:"activatedBadges" example:"1" description:"Number of badges currently activated" minimum:"0"`
 	TotalContributionValue float64 `json:"totalContributionValue" example:"1.0" description:"Total contribution value from all activated badges" minimum:"0"`
@@ -88,13 +90,13 @@ type GetPublicNftStatsResponse struct {
 type PublicNftStatsData struct {
 	TotalNftHolders       int            `json:"totalNftHolders" example:"1250" description:"Total number of users who own at least one NFT" minimum:"0"`
 	TotalNftsMinted       int            `json:"totalNftsMinted" example:"3500" description:"Total number of NFTs minted across all tiers and competitions" minimum:"0"`
-	TotalTradingVolume    float64        `json:"totalTradingVolume" example:"15750000.50" description:"Combined trading volume of all NFT holders in USD" minimum:"0"`
+	TotalTradingVolume    money.Amount   `json:"totalTradingVolume" example:"15750000.5" description:"Combined trading volume of all NFT holders in USDT" minimum:"0"`
 	TotalBadgesEarned     int            `json:"totalBadgesEarned" example:"8750" description:"Total number of badges earned by all users" minimum:"0"`
 	CompetitionNftHolders int            `json:"competitionNftHolders" example:"320" description:"Number of users who own competition NFTs" minimum:"0"`
 	AverageNftLevel       float64        `json:"averageNftLevel" example:"2.8" description:"Average NFT level across all holders (1.0-5.0)" minimum:"1.0" maximum:"5.0"`
 	TopTierHolders        int            `json:"topTierHolders" example:"150" description:"Number of users who own level 5 (highest tier) NFTs" minimum:"0"`
 	ActiveUpgrades        int            `json:"activeUpgrades" example:"85" description:"Number of NFT upgrade operations in progress" minimum:"0"`
-	TotalFeesSaved        float64        `json:"totalFeesSaved" example:"125000.75" description:"Total fees saved by all NFT holders through benefits (in USD)" minimum:"0"`
+	TotalFeesSaved        money.Amount   `json:"totalFeesSaved" example:"125000.75" description:"Total fees saved by all NFT holders through benefits (in USDT)" minimum:"0"`
 	LastUpdated           string         `json:"lastUpdated" example:"2024-02-20T16:30:00.000Z" description:"ISO timestamp when statistics were last calculated" format:"date-time"`
 	Distribution          map[string]int `json:"distribution" description:"Distribution of NFTs by level (keys: '1','2','3','4','5')" example:"{\"1\":500,\"2\":800,\"3\":600,\"4\":400,\"5\":200}"`
 }
@@ -127,18 +129,18 @@ type GetCanUpgradeQuery struct {
 
 // User represents an authenticated user (mimics original API user model)
 type User struct {
-	ID                 int    `json:"id"`
-	AccessToken        string `json:"accessToken,omitempty"`
-	TwitterAccessToken string `json:"twitterAccessToken,omitempty"`
-	Nickname           string `json:"nickname"`
-	WalletAddr         string `json:"walletAddr"`
-	Email              string `json:"email,omitempty"`
-	Bio                string `json:"bio,omitempty"`
-	ProfilePhotoURL    string `json:"profilePhotoUrl,omitempty"`
-	BannerURL          string `json:"bannerUrl,omitempty"`
-	TradingVolume      int    `json:"tradingVolume"`
-	CreatedAt          string `json:"createdAt"`
-	UpdatedAt          string `json:"updatedAt"`
+	ID                 int          `json:"id"`
+	AccessToken        string       `json:"accessToken,omitempty"`
+	TwitterAccessToken string       `json:"twitterAccessToken,omitempty"`
+	Nickname           string       `json:"nickname"`
+	WalletAddr         string       `json:"walletAddr"`
+	Email              string       `json:"email,omitempty"`
+	Bio                string       `json:"bio,omitempty"`
+	ProfilePhotoURL    string       `json:"profilePhotoUrl,omitempty"`
+	BannerURL          string       `json:"bannerUrl,omitempty"`
+	TradingVolume      money.Amount `json:"tradingVolume"`
+	CreatedAt          string       `json:"createdAt"`
+	UpdatedAt          string       `json:"updatedAt"`
 }
 
 // ==========================================
@@ -163,14 +165,14 @@ type PublicStatsData struct {
 
 // PlatformStats represents platform statistics
 type PlatformStats struct {
-	TotalUsers         int     `json:"totalUsers"`
-	ActiveUsers        int     `json:"activeUsers"`
-	TotalTradingVolume float64 `json:"totalTradingVolume"`
-	TotalNftsIssued    int     `json:"totalNftsIssued"`
-	TotalBadgesEarned  int     `json:"totalBadgesEarned"`
-	CompetitionsHeld   int     `json:"competitionsHeld"`
-	AverageUserRating  float64 `json:"averageUserRating"`
-	PlatformFees       float64 `json:"platformFees"`
+	TotalUsers         int          `json:"totalUsers"`
+	ActiveUsers        int          `json:"activeUsers"`
+	TotalTradingVolume money.Amount `json:"totalTradingVolume"`
+	TotalNftsIssued    int          `json:"totalNftsIssued"`
+	TotalBadgesEarned  int          `json:"totalBadgesEarned"`
+	CompetitionsHeld   int          `json:"competitionsHeld"`
+	AverageUserRating  float64      `json:"averageUserRating"`
+	PlatformFees       money.Amount `json:"platformFees"`
 }
 
 // PlatformHealthResponse represents platform health response
@@ -209,14 +211,14 @@ type UserProfileData struct {
 
 // UserStats represents user statistics
 type UserStats struct {
-	TradingVolume   float64 `json:"tradingVolume"`
-	NftCount        int     `json:"nftCount"`
-	BadgeCount      int     `json:"badgeCount"`
-	CompetitionWins int     `json:"competitionWins"`
-	JoinedDate      string  `json:"joinedDate"`
-	LastActiveDate  string  `json:"lastActiveDate"`
-	ProfileViews    int     `json:"profileViews"`
-	Rank            int     `json:"rank"`
+	TradingVolume   money.Amount `json:"tradingVolume"`
+	NftCount        int          `json:"nftCount"`
+	BadgeCount      int          `json:"badgeCount"`
+	CompetitionWins int          `json:"competitionWins"`
+	JoinedDate      string       `json:"joinedDate"`
+	LastActiveDate  string       `json:"lastActiveDate"`
+	ProfileViews    int          `json:"profileViews"`
+	Rank            int          `json:"rank"`
 }
 
 // LeaderboardResponse represents leaderboard response
@@ -0,0 +1,320 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+	"github.com/aiw3/nft-solana-api/repository/sqlite"
+)
+
+// The tests in this file are the contract every store implements: they run unchanged over the memory
+// and the SQLite store, so the two agree on which writes conflict and which reads find nothing.
+
+// contract runs test as a subtest over a fresh memory and SQLite store
+func contract(t *testing.T, test func(t *testing.T, store *repository.Store)) {
+	t.Helper()
+	db, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "aiw3.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for name, store := range map[string]*repository.Store{"memory": memory.NewStore(), "sqlite": sqlite.NewStore(db)} {
+		t.Run(name, func(t *testing.T) { test(t, store) })
+	}
+}
+
+// saveUser stores a user the other records can belong to
+func saveUser(t *testing.T, store *repository.Store, wallet string) *repository.User {
+	t.Helper()
+	user := &repository.User{Username: wallet, WalletAddr: wallet}
+	if err := store.Users.Save(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestUnknownRecordsAreNotFound(t *testing.T) {
+	ctx := context.Background()
+	contract(t, func(t *testing.T, store *repository.Store) {
+		for name, get := range map[string]func() error{
+			"user by ID":           func() error { _, err := store.Users.GetByID(ctx, 404); return err },
+			"user by access token": func() error { _, err := store.Users.GetByAccessToken(ctx, "unknown"); return err },
+			"user by wallet":       func() error { _, err := store.Users.GetByWallet(ctx, "UnknownWallet"); return err },
+			"tiered NFT":           func() error { _, err := store.TieredNfts.GetByID(ctx, 404); return err },
+			"upgrade request":      func() error { _, err := store.Upgrades.Get(ctx, 404); return err },
+			"avatar":               func() error { _, err := store.Avatars.GetByID(ctx, 404); return err },
+			"guard decision":       func() error { _, err := store.TaskGuard.Get(ctx, 404); return err },
+			"exchange account":     func() error { _, err := store.Exchanges.Get(ctx, 404, "okx"); return err },
+			"exchange unbind":      func() error { return store.Exchanges.Delete(ctx, 404, "okx") },
+			"idempotency key":      func() error { _, err := store.Idempotency.Get(ctx, "scope", "unknown"); return err },
+			"AI agent refund":      func() error { _, _, err := store.AiQuota.Refund(ctx, "unknown"); return err },
+		} {
+			if err := get(); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("%s: %v, want ErrNotFound", name, err)
+			}
+		}
+	})
+}
+
+func TestUniqueRecordsConflict(t *testing.T) {
+	ctx := context.Background()
+	contract(t, func(t *testing.T, store *repository.Store) {
+		user := saveUser(t, store, "TraderWallet")
+		other := saveUser(t, store, "OtherWallet")
+		now := time.Now().UTC().Truncate(time.Second)
+
+		if err := store.Users.Save(ctx, &repository.User{Username: "copy", WalletAddr: user.WalletAddr}); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("second user with a wallet: %v, want ErrConflict", err)
+		}
+
+		active := &repository.TieredNft{UserID: user.ID, Level: 1, Name: "Tech Chicken", Status: repository.NftStatusActive,
+			MintAddress: "FirstMint", MintedAt: now}
+		if err := store.TieredNfts.Create(ctx, active); err != nil {
+			t.Fatal(err)
+		}
+		second := &repository.TieredNft{UserID: user.ID, Level: 2, Name: "Quant Ape", Status: repository.NftStatusActive,
+			MintAddress: "SecondMint", MintedAt: now}
+		if err := store.TieredNfts.Create(ctx, second); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("second active NFT of a user: %v, want ErrConflict", err)
+		}
+		copied := &repository.TieredNft{UserID: other.ID, Level: 1, Name: "Tech Chicken", Status: repository.NftStatusActive,
+			MintAddress: active.MintAddress, MintedAt: now}
+		if err := store.TieredNfts.Create(ctx, copied); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("NFT with a minted address: %v, want ErrConflict", err)
+		}
+
+		fee := func(userID int) *repository.FeeSaving {
+			return &repository.FeeSaving{UserID: userID, Platform: "okx", TradeID: "T-1", PlatformWallet: "OKX-1",
+				Notional: money.USDT.Whole(1000), FeeRate: 0.0005, UndiscountedFee: money.USDT.MustParse("0.5"),
+				ChargedFee: money.USDT.MustParse("0.5"), FeeSaved: money.USDT.Whole(0), ExecutedAt: now, RecordedAt: now}
+		}
+		if err := store.FeeLedger.Append(ctx, fee(user.ID)); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.FeeLedger.Append(ctx, fee(other.ID)); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("fee saving of a recorded trade: %v, want ErrConflict", err)
+		}
+
+		trade := func(userID int) *repository.VolumeTrade {
+			return &repository.VolumeTrade{UserID: userID, Platform: "okx", TradeID: "T-1", Source: "webhook",
+				Notional: money.USDT.Whole(1000), ExecutedAt: now, IngestedAt: now, Asset: "USDT",
+				AssetNotional: money.USDT.Whole(1000), Price: money.USDT.Whole(1)}
+		}
+		if err := store.Volume.RecordTrade(ctx, trade(user.ID)); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Volume.RecordTrade(ctx, trade(other.ID)); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("volume of a recorded trade: %v, want ErrConflict", err)
+		}
+		if count, err := store.Volume.CountTrades(ctx, other.ID); err != nil || count != 0 {
+			t.Errorf("%d trades, %v for the conflicting user; want the trade left out", count, err)
+		}
+
+		account := func(userID int, accountID string) *repository.ExchangeAccount {
+			return &repository.ExchangeAccount{UserID: userID, Platform: "okx", AccountID: accountID,
+				Credentials: "sealed", CreatedAt: now, UpdatedAt: now}
+		}
+		if err := store.Exchanges.Create(ctx, account(user.ID, "OKX-1")); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Exchanges.Create(ctx, account(user.ID, "OKX-2")); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("second account of a user on a platform: %v, want ErrConflict", err)
+		}
+		if err := store.Exchanges.Create(ctx, account(other.ID, "OKX-1")); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("account bound to another user: %v, want ErrConflict", err)
+		}
+		if err := store.Exchanges.Delete(ctx, user.ID, "okx"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Exchanges.Create(ctx, account(other.ID, "OKX-1")); err != nil {
+			t.Errorf("account bound after it was unbound: %v", err)
+		}
+	})
+}
+
+func TestResolveClosesPendingReviewsOnce(t *testing.T) {
+	ctx := context.Background()
+	contract(t, func(t *testing.T, store *repository.Store) {
+		user := saveUser(t, store, "ReviewedWallet")
+		now := time.Now().UTC().Truncate(time.Second)
+		held := &repository.GuardDecision{UserID: user.ID, TaskID: 1, Outcome: repository.GuardOutcomeReview,
+			Rule: "burst", ReviewStatus: repository.ReviewPending, CreatedAt: now}
+		allowed := &repository.GuardDecision{UserID: user.ID, TaskID: 2, Outcome: repository.GuardOutcomeAllowed, CreatedAt: now}
+		for _, decision := range []*repository.GuardDecision{held, allowed} {
+			if err := store.TaskGuard.Record(ctx, decision); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := store.TaskGuard.Resolve(ctx, held.ID, repository.ReviewApproved, "admin", "", now); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.TaskGuard.Resolve(ctx, held.ID, repository.ReviewRejected, "admin", "", now); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("second resolve: %v, want ErrConflict", err)
+		}
+		if err := store.TaskGuard.Resolve(ctx, allowed.ID, repository.ReviewApproved, "admin", "", now); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("resolve of a decision never held: %v, want ErrConflict", err)
+		}
+		stored, err := store.TaskGuard.Get(ctx, held.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.ReviewStatus != repository.ReviewApproved {
+			t.Errorf("review %s, want the first resolution kept", stored.ReviewStatus)
+		}
+	})
+}
+
+func TestIdempotencyKeysExpire(t *testing.T) {
+	ctx := context.Background()
+	contract(t, func(t *testing.T, store *repository.Store) {
+		now := time.Now().UTC().Truncate(time.Second)
+		record := func(key string, expiresAt time.Time) *repository.IdempotencyRecord {
+			return &repository.IdempotencyRecord{Scope: "scope", Key: key, RequestHash: "hash",
+				CreatedAt: now, ExpiresAt: expiresAt}
+		}
+
+		if err := store.Idempotency.Reserve(ctx, record("live", now.Add(time.Hour))); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Idempotency.Reserve(ctx, record("live", now.Add(time.Hour))); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("second reserve of a key: %v, want ErrConflict", err)
+		}
+		completed := record("live", now.Add(time.Hour))
+		completed.StatusCode, completed.ContentType, completed.Body = 200, "application/json", []byte(`{"code":200}`)
+		if err := store.Idempotency.Complete(ctx, completed); err != nil {
+			t.Fatal(err)
+		}
+		stored, err := store.Idempotency.Get(ctx, "scope", "live")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.StatusCode != 200 || string(stored.Body) != `{"code":200}` {
+			t.Errorf("stored response %d %s, want the completed one", stored.StatusCode, stored.Body)
+		}
+
+		// An expired key is not found and can be reserved again
+		if err := store.Idempotency.Reserve(ctx, record("expired", now.Add(-time.Second))); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Idempotency.Get(ctx, "scope", "expired"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expired key: %v, want ErrNotFound", err)
+		}
+		if err := store.Idempotency.Reserve(ctx, record("expired", now.Add(time.Hour))); err != nil {
+			t.Errorf("reserve of an expired key: %v", err)
+		}
+
+		if err := store.Idempotency.Reserve(ctx, record("stale", now.Add(-time.Second))); err != nil {
+			t.Fatal(err)
+		}
+		if removed, err := store.Idempotency.DeleteExpired(ctx, now); err != nil || removed != 1 {
+			t.Errorf("removed %d, %v; want the stale key only", removed, err)
+		}
+		if err := store.Idempotency.Delete(ctx, "scope", "live"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Idempotency.Get(ctx, "scope", "live"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("deleted key: %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestLeasesExpire(t *testing.T) {
+	ctx := context.Background()
+	const ttl = 50 * time.Millisecond
+	contract(t, func(t *testing.T, store *repository.Store) {
+		acquire := func(owner string, ttl time.Duration) bool {
+			t.Helper()
+			ok, err := store.Leases.Acquire(ctx, "user:1", owner, ttl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return ok
+		}
+
+		if !acquire("first", ttl) {
+			t.Fatal("free lease not acquired")
+		}
+		if !acquire("first", ttl) {
+			t.Error("lease not reacquired by its owner")
+		}
+		if acquire("second", time.Hour) {
+			t.Error("held lease acquired by another owner")
+		}
+		if err := store.Leases.Release(ctx, "user:1", "second"); err != nil {
+			t.Fatal(err)
+		}
+		if active, err := store.Leases.ListActive(ctx); err != nil || len(active) != 1 || active[0].Owner != "first" {
+			t.Errorf("active leases %+v, %v; want the first owner's after another owner released it", active, err)
+		}
+
+		time.Sleep(2 * ttl)
+		if active, err := store.Leases.ListActive(ctx); err != nil || len(active) != 0 {
+			t.Errorf("active leases %+v, %v; want none after the TTL", active, err)
+		}
+		if renewed, err := store.Leases.Renew(ctx, "user:1", "first", ttl); err != nil || renewed {
+			t.Errorf("renew of an expired lease: %v, %v; want it lost", renewed, err)
+		}
+		if !acquire("second", time.Hour) {
+			t.Fatal("expired lease not acquired by another owner")
+		}
+		if renewed, err := store.Leases.Renew(ctx, "user:1", "first", ttl); err != nil || renewed {
+			t.Errorf("renew by the previous owner: %v, %v; want it lost", renewed, err)
+		}
+		if renewed, err := store.Leases.Renew(ctx, "user:1", "second", time.Hour); err != nil || !renewed {
+			t.Errorf("renew by the owner: %v, %v", renewed, err)
+		}
+
+		if err := store.Leases.Release(ctx, "user:1", "second"); err != nil {
+			t.Fatal(err)
+		}
+		if !acquire("first", ttl) {
+			t.Error("released lease not acquired")
+		}
+	})
+}
+
+func TestSequencesCountFromOne(t *testing.T) {
+	ctx := context.Background()
+	contract(t, func(t *testing.T, store *repository.Store) {
+		for _, want := range []int{1, 2, 3} {
+			if got, err := store.Sequences.Next(ctx, "serial:1"); err != nil || got != want {
+				t.Errorf("next %d, %v; want %d", got, err, want)
+			}
+		}
+		if got, err := store.Sequences.Next(ctx, "serial:2"); err != nil || got != 1 {
+			t.Errorf("next of another counter %d, %v; want 1", got, err)
+		}
+	})
+}
+
+func TestSequenceAssignmentsKeepTheirValue(t *testing.T) {
+	ctx := context.Background()
+	contract(t, func(t *testing.T, store *repository.Store) {
+		if got, err := store.Sequences.Next(ctx, "serial:1"); err != nil || got != 1 {
+			t.Fatalf("next %d, %v; want 1", got, err)
+		}
+		// Repeating a key returns its value without taking another
+		assignments := []struct {
+			key  string
+			want int
+		}{{"claim-1", 2}, {"claim-2", 3}, {"claim-1", 2}, {"claim-2", 3}}
+		for _, a := range assignments {
+			if got, err := store.Sequences.Assign(ctx, "serial:1", a.key); err != nil || got != a.want {
+				t.Errorf("assign %s: %d, %v; want %d", a.key, got, err, a.want)
+			}
+		}
+		if got, err := store.Sequences.Next(ctx, "serial:1"); err != nil || got != 4 {
+			t.Errorf("next after the assignments %d, %v; want 4", got, err)
+		}
+		if got, err := store.Sequences.Assign(ctx, "serial:2", "claim-1"); err != nil || got != 1 {
+			t.Errorf("key assigned in another counter %d, %v; want 1", got, err)
+		}
+	})
+}
@@ -0,0 +1,1616 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// IN-MEMORY STORE
+// ==========================================
+
+// NewStore returns a repository.Store backed by process memory.
+// All data is lost when the process exits.
+func NewStore() *repository.Store {
+	users := &userRepository{users: map[int]repository.User{}}
+	history := &benefitsHistoryRepository{}
+	return &repository.Store{
+		Users:      users,
+		TieredNfts: &tieredNftRepository{nfts: map[int]repository.TieredNft{}, history: history},
+		Upgrades: &upgradeRepository{
+			requests: map[int]repository.UpgradeRequest{},
+			steps:    map[int]repository.UpgradeStep{},
+		},
+		CompetitionNfts: &competitionNftRepository{nfts: map[int]repository.CompetitionNft{}, history: history},
+		BenefitsHistory: history,
+		Badges: &badgeRepository{
+			definitions: map[int]repository.BadgeDefinition{},
+			versions:    map[int][]repository.BadgeDefinition{},
+			userBadges:  map[userBadgeKey]repository.UserBadge{},
+		},
+		Tasks: &taskRepository{
+			tasks:    map[int]repository.Task{},
+			progress: map[userTaskKey]repository.TaskProgress{},
+		},
+		Activity:  &activityRepository{counters: map[activityKey]repository.Activity{}},
+		TaskGuard: &taskGuardRepository{decisions: map[int]repository.GuardDecision{}},
+		AiQuota: &aiQuotaRepository{
+			usage:    map[aiQuotaKey]repository.AiAgentUsage{},
+			consumes: map[string]repository.AiAgentConsume{},
+		},
+		FeeLedger: &feeLedgerRepository{entries: map[int]repository.FeeSaving{}},
+		Volume: &volumeRepository{
+			users:   users,
+			trades:  map[int]repository.VolumeTrade{},
+			rollups: map[volumeRollupKey]repository.VolumeRollup{},
+			drifts:  map[int]repository.VolumeDrift{},
+		},
+		Exchanges: &exchangeAccountRepository{accounts: map[int]repository.ExchangeAccount{}},
+		Avatars:   &avatarRepository{avatars: map[int]repository.Avatar{}},
+		Sequences: &sequenceRepository{values: map[string]int{}, assignments: map[sequenceAssignmentKey]int{}},
+		Leases:    &leaseRepository{leases: map[string]repository.Lease{}},
+		Idempotency: &idempotencyRepository{
+			records: map[idempotencyKey]repository.IdempotencyRecord{},
+		},
+	}
+}
+
+// nextID returns the next free identifier for a map keyed by int
+func nextID[T any](records map[int]T) int {
+	max := 0
+	for id := range records {
+		if id > max {
+			max = id
+		}
+	}
+	return max + 1
+}
+
+// sortedValues returns map values ordered by key
+func sortedValues[T any](records map[int]T) []T {
+	ids := make([]int, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	values := make([]T, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, records[id])
+	}
+	return values
+}
+
+// ==========================================
+// USER REPOSITORY
+// ==========================================
+
+type userRepository struct {
+	mu    sync.RWMutex
+	users map[int]repository.User
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id int) (*repository.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByAccessToken(ctx context.Context, accessToken string) (*repository.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Mirrors isAuthenticated.js: match either accessToken or twitterAccessToken
+	for _, user := range r.users {
+		if accessToken != "" && (user.AccessToken == accessToken || user.TwitterAccessToken == accessToken) {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *userRepository) GetByWallet(ctx context.Context, walletAddr string) (*repository.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.WalletAddr == walletAddr {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *userRepository) List(ctx context.Context) ([]repository.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sortedValues(r.users), nil
+}
+
+func (r *userRepository) ListByAvatar(ctx context.Context, avatarID int) ([]repository.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := []repository.User{}
+	for _, user := range sortedValues(r.users) {
+		if user.ProfileAvatarID != nil && *user.ProfileAvatarID == avatarID {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *userRepository) Save(ctx context.Context, user *repository.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.users {
+		if id != user.ID && user.WalletAddr != "" && existing.WalletAddr == user.WalletAddr {
+			return repository.ErrConflict
+		}
+	}
+
+	if user.ID == 0 {
+		user.ID = nextID(r.users)
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+// ==========================================
+// TIERED NFT REPOSITORY
+// ==========================================
+
+type tieredNftRepository struct {
+	mu      sync.RWMutex
+	nfts    map[int]repository.TieredNft
+	history *benefitsHistoryRepository
+}
+
+func (r *tieredNftRepository) GetByID(ctx context.Context, id int) (*repository.TieredNft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nft, ok := r.nfts[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &nft, nil
+}
+
+func (r *tieredNftRepository) ListByUser(ctx context.Context, userID int) ([]repository.TieredNft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nfts := []repository.TieredNft{}
+	for _, nft := range sortedValues(r.nfts) {
+		if nft.UserID == userID {
+			nfts = append(nfts, nft)
+		}
+	}
+	return nfts, nil
+}
+
+func (r *tieredNftRepository) Create(ctx context.Context, nft *repository.TieredNft) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.nfts {
+		if nft.MintAddress != "" && existing.MintAddress == nft.MintAddress {
+			return repository.ErrConflict
+		}
+		if conflictingActive(existing, nft) {
+			return repository.ErrConflict
+		}
+	}
+
+	nft.ID = nextID(r.nfts)
+	r.nfts[nft.ID] = *nft
+	if nft.BenefitsActivated {
+		r.history.record(nft.UserID, repository.NftTypeTiered, nft.ID, true, nft.MintedAt)
+	}
+	return nil
+}
+
+func (r *tieredNftRepository) Update(ctx context.Context, nft *repository.TieredNft) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.nfts[nft.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	for _, existing := range r.nfts {
+		if existing.ID != nft.ID && conflictingActive(existing, nft) {
+			return repository.ErrConflict
+		}
+	}
+	r.nfts[nft.ID] = *nft
+	if stored.BenefitsActivated != nft.BenefitsActivated {
+		at := time.Now().UTC()
+		if !nft.BenefitsActivated && nft.BurnedAt != nil {
+			at = *nft.BurnedAt
+		}
+		r.history.record(nft.UserID, repository.NftTypeTiered, nft.ID, nft.BenefitsActivated, at)
+	}
+	return nil
+}
+
+// conflictingActive reports whether saving nft would give its user a second active NFT
+func conflictingActive(existing repository.TieredNft, nft *repository.TieredNft) bool {
+	return existing.UserID == nft.UserID &&
+		existing.Status == repository.NftStatusActive && nft.Status == repository.NftStatusActive
+}
+
+// ==========================================
+// UPGRADE REPOSITORY
+// ==========================================
+
+type upgradeRepository struct {
+	mu       sync.RWMutex
+	requests map[int]repository.UpgradeRequest
+	steps    map[int]repository.UpgradeStep
+}
+
+// copyUpgrade detaches the badge ID slice so callers cannot mutate stored requests
+func copyUpgrade(req repository.UpgradeRequest) repository.UpgradeRequest {
+	req.BadgeIDs = append([]int(nil), req.BadgeIDs...)
+	return req
+}
+
+func (r *upgradeRepository) Get(ctx context.Context, id int) (*repository.UpgradeRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req, ok := r.requests[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	req = copyUpgrade(req)
+	return &req, nil
+}
+
+func (r *upgradeRepository) ListByUser(ctx context.Context, userID int) ([]repository.UpgradeRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	requests := []repository.UpgradeRequest{}
+	for _, req := range sortedValues(r.requests) {
+		if req.UserID == userID {
+			requests = append(requests, copyUpgrade(req))
+		}
+	}
+	return requests, nil
+}
+
+func (r *upgradeRepository) ListInFlight(ctx context.Context) ([]repository.UpgradeRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	requests := []repository.UpgradeRequest{}
+	for _, req := range sortedValues(r.requests) {
+		if req.Unfinished() {
+			requests = append(requests, copyUpgrade(req))
+		}
+	}
+	return requests, nil
+}
+
+func (r *upgradeRepository) Create(ctx context.Context, req *repository.UpgradeRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	req.ID = nextID(r.requests)
+	r.requests[req.ID] = copyUpgrade(*req)
+	return nil
+}
+
+func (r *upgradeRepository) Update(ctx context.Context, req *repository.UpgradeRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.requests[req.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.requests[req.ID] = copyUpgrade(*req)
+	return nil
+}
+
+func (r *upgradeRepository) AppendStep(ctx context.Context, step *repository.UpgradeStep) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.requests[step.UpgradeRequestID]; !ok {
+		return repository.ErrNotFound
+	}
+	step.ID = nextID(r.steps)
+	r.steps[step.ID] = *step
+	return nil
+}
+
+func (r *upgradeRepository) ListSteps(ctx context.Context, upgradeRequestID int) ([]repository.UpgradeStep, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	steps := []repository.UpgradeStep{}
+	for _, step := range sortedValues(r.steps) {
+		if step.UpgradeRequestID == upgradeRequestID {
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+// ==========================================
+// COMPETITION NFT REPOSITORY
+// ==========================================
+
+type competitionNftRepository struct {
+	mu      sync.RWMutex
+	nfts    map[int]repository.CompetitionNft
+	history *benefitsHistoryRepository
+}
+
+func (r *competitionNftRepository) GetByID(ctx context.Context, id int) (*repository.CompetitionNft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nft, ok := r.nfts[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &nft, nil
+}
+
+func (r *competitionNftRepository) List(ctx context.Context) ([]repository.CompetitionNft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sortedValues(r.nfts), nil
+}
+
+func (r *competitionNftRepository) ListByUser(ctx context.Context, userID int) ([]repository.CompetitionNft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nfts := []repository.CompetitionNft{}
+	for _, nft := range sortedValues(r.nfts) {
+		if nft.UserID == userID {
+			nfts = append(nfts, nft)
+		}
+	}
+	return nfts, nil
+}
+
+func (r *competitionNftRepository) Create(ctx context.Context, nft *repository.CompetitionNft) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.nfts {
+		if nft.MintAddress != "" && existing.MintAddress == nft.MintAddress {
+			return repository.ErrConflict
+		}
+	}
+
+	nft.ID = nextID(r.nfts)
+	r.nfts[nft.ID] = *nft
+	if nft.BenefitsActivated {
+		r.history.record(nft.UserID, repository.NftTypeCompetition, nft.ID, true, nft.MintedAt)
+	}
+	return nil
+}
+
+func (r *competitionNftRepository) Update(ctx context.Context, nft *repository.CompetitionNft) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.nfts[nft.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	r.nfts[nft.ID] = *nft
+	if stored.BenefitsActivated != nft.BenefitsActivated {
+		r.history.record(nft.UserID, repository.NftTypeCompetition, nft.ID, nft.BenefitsActivated, time.Now().UTC())
+	}
+	return nil
+}
+
+// ==========================================
+// BENEFITS HISTORY REPOSITORY
+// ==========================================
+
+type benefitsHistoryRepository struct {
+	mu        sync.RWMutex
+	intervals []repository.BenefitsInterval
+}
+
+// record opens an interval for the NFT when its benefits were activated and closes its open one when
+// they were deactivated
+func (r *benefitsHistoryRepository) record(userID int, nftType string, nftID int, activated bool, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if activated {
+		r.intervals = append(r.intervals, repository.BenefitsInterval{ID: len(r.intervals) + 1, UserID: userID,
+			NftType: nftType, NftID: nftID, ActivatedAt: at.UTC()})
+		return
+	}
+	for i := range r.intervals {
+		interval := &r.intervals[i]
+		if interval.NftType == nftType && interval.NftID == nftID && interval.DeactivatedAt == nil {
+			deactivatedAt := at.UTC()
+			interval.DeactivatedAt = &deactivatedAt
+		}
+	}
+}
+
+func (r *benefitsHistoryRepository) ListByUser(ctx context.Context, userID int) ([]repository.BenefitsInterval, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	intervals := []repository.BenefitsInterval{}
+	for _, interval := range r.intervals {
+		if interval.UserID == userID {
+			intervals = append(intervals, interval)
+		}
+	}
+	sort.SliceStable(intervals, func(i, j int) bool {
+		return intervals[i].ActivatedAt.Before(intervals[j].ActivatedAt)
+	})
+	return intervals, nil
+}
+
+// ==========================================
+// BADGE REPOSITORY
+// ==========================================
+
+type userBadgeKey struct {
+	userID  int
+	badgeID int
+}
+
+type badgeRepository struct {
+	mu          sync.RWMutex
+	definitions map[int]repository.BadgeDefinition
+	versions    map[int][]repository.BadgeDefinition // by definition ID, oldest first
+	userBadges  map[userBadgeKey]repository.UserBadge
+}
+
+func (r *badgeRepository) ListDefinitions(ctx context.Context) ([]repository.BadgeDefinition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := sortedValues(r.definitions)
+	for i := range defs {
+		defs[i].Requirements = append([]repository.Requirement(nil), defs[i].Requirements...)
+	}
+	sort.SliceStable(defs, func(i, j int) bool {
+		if defs[i].NftLevel != defs[j].NftLevel {
+			return defs[i].NftLevel < defs[j].NftLevel
+		}
+		return defs[i].SortOrder < defs[j].SortOrder
+	})
+	return defs, nil
+}
+
+func (r *badgeRepository) GetDefinition(ctx context.Context, id int) (*repository.BadgeDefinition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.definitions[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	def.Requirements = append([]repository.Requirement(nil), def.Requirements...)
+	return &def, nil
+}
+
+func (r *badgeRepository) SaveDefinition(ctx context.Context, def *repository.BadgeDefinition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if def.ID == 0 {
+		def.ID = nextID(r.definitions)
+	}
+	if def.Version == 0 {
+		def.Version = 1
+	}
+	stored := *def
+	stored.Requirements = append([]repository.Requirement(nil), def.Requirements...)
+	r.definitions[def.ID] = stored
+
+	// Record the version; saving the same version again replaces it
+	snapshot := stored
+	snapshot.RetiredAt = nil
+	versions := r.versions[def.ID]
+	if n := len(versions); n > 0 && versions[n-1].Version == def.Version {
+		versions[n-1] = snapshot
+	} else {
+		r.versions[def.ID] = append(versions, snapshot)
+	}
+	return nil
+}
+
+func (r *badgeRepository) ListDefinitionVersions(ctx context.Context, id int) ([]repository.BadgeDefinition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := append([]repository.BadgeDefinition{}, r.versions[id]...)
+	for i := range defs {
+		defs[i].Requirements = append([]repository.Requirement(nil), defs[i].Requirements...)
+	}
+	return defs, nil
+}
+
+func (r *badgeRepository) GetDefinitionVersion(ctx context.Context, id, version int) (*repository.BadgeDefinition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, def := range r.versions[id] {
+		if def.Version == version {
+			def.Requirements = append([]repository.Requirement(nil), def.Requirements...)
+			return &def, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *badgeRepository) ListByUser(ctx context.Context, userID int) ([]repository.UserBadge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	badges := []repository.UserBadge{}
+	for key, badge := range r.userBadges {
+		if key.userID == userID {
+			badges = append(badges, badge)
+		}
+	}
+	sort.Slice(badges, func(i, j int) bool { return badges[i].BadgeID < badges[j].BadgeID })
+	return badges, nil
+}
+
+func (r *badgeRepository) ListHolders(ctx context.Context, badgeID int) ([]repository.UserBadge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	badges := []repository.UserBadge{}
+	for key, badge := range r.userBadges {
+		if key.badgeID == badgeID {
+			badges = append(badges, badge)
+		}
+	}
+	sort.Slice(badges, func(i, j int) bool { return badges[i].UserID < badges[j].UserID })
+	return badges, nil
+}
+
+func (r *badgeRepository) GetUserBadge(ctx context.Context, userID, badgeID int) (*repository.UserBadge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	badge, ok := r.userBadges[userBadgeKey{userID: userID, badgeID: badgeID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &badge, nil
+}
+
+func (r *badgeRepository) SaveUserBadge(ctx context.Context, badge *repository.UserBadge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.definitions[badge.BadgeID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.userBadges[userBadgeKey{userID: badge.UserID, badgeID: badge.BadgeID}] = *badge
+	return nil
+}
+
+func (r *badgeRepository) ListExpiringActivations(ctx context.Context, before time.Time) ([]repository.UserBadge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	badges := []repository.UserBadge{}
+	for _, badge := range r.userBadges {
+		if badge.Status == "activated" && badge.ExpiresAt != nil && !badge.ExpiresAt.After(before) {
+			badges = append(badges, badge)
+		}
+	}
+	sort.Slice(badges, func(i, j int) bool {
+		if !badges[i].ExpiresAt.Equal(*badges[j].ExpiresAt) {
+			return badges[i].ExpiresAt.Before(*badges[j].ExpiresAt)
+		}
+		if badges[i].UserID != badges[j].UserID {
+			return badges[i].UserID < badges[j].UserID
+		}
+		return badges[i].BadgeID < badges[j].BadgeID
+	})
+	return badges, nil
+}
+
+// ==========================================
+// TASK REPOSITORY
+// ==========================================
+
+type userTaskKey struct {
+	userID int
+	taskID int
+}
+
+type taskRepository struct {
+	mu       sync.RWMutex
+	tasks    map[int]repository.Task
+	progress map[userTaskKey]repository.TaskProgress
+}
+
+func (r *taskRepository) ListTasks(ctx context.Context) ([]repository.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sortedValues(r.tasks), nil
+}
+
+func (r *taskRepository) GetTask(ctx context.Context, id int) (*repository.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &task, nil
+}
+
+func (r *taskRepository) SaveTask(ctx context.Context, task *repository.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if task.ID == 0 {
+		task.ID = nextID(r.tasks)
+	}
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *taskRepository) ListProgressByUser(ctx context.Context, userID int) ([]repository.TaskProgress, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	progress := []repository.TaskProgress{}
+	for key, p := range r.progress {
+		if key.userID == userID {
+			progress = append(progress, p)
+		}
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i].TaskID < progress[j].TaskID })
+	return progress, nil
+}
+
+func (r *taskRepository) GetProgress(ctx context.Context, userID, taskID int) (*repository.TaskProgress, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.progress[userTaskKey{userID: userID, taskID: taskID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *taskRepository) SaveProgress(ctx context.Context, progress *repository.TaskProgress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[progress.TaskID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.progress[userTaskKey{userID: progress.UserID, taskID: progress.TaskID}] = *progress
+	return nil
+}
+
+// ==========================================
+// ACTIVITY REPOSITORY
+// ==========================================
+
+type activityKey struct {
+	userID int
+	metric string
+}
+
+type activityRepository struct {
+	mu       sync.RWMutex
+	counters map[activityKey]repository.Activity
+}
+
+func (r *activityRepository) ListByUser(ctx context.Context, userID int) ([]repository.Activity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	activity := []repository.Activity{}
+	for key, counter := range r.counters {
+		if key.userID == userID {
+			activity = append(activity, counter)
+		}
+	}
+	sort.Slice(activity, func(i, j int) bool { return activity[i].Metric < activity[j].Metric })
+	return activity, nil
+}
+
+func (r *activityRepository) Get(ctx context.Context, userID int, metric string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.counters[activityKey{userID: userID, metric: metric}].Value, nil
+}
+
+func (r *activityRepository) Add(ctx context.Context, userID int, metric string, delta int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := activityKey{userID: userID, metric: metric}
+	counter := r.counters[key]
+	counter.UserID, counter.Metric = userID, metric
+	counter.Value += delta
+	counter.UpdatedAt = time.Now().UTC()
+	r.counters[key] = counter
+	return counter.Value, nil
+}
+
+func (r *activityRepository) Set(ctx context.Context, userID int, metric string, value int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counters[activityKey{userID: userID, metric: metric}] = repository.Activity{
+		UserID:    userID,
+		Metric:    metric,
+		Value:     value,
+		UpdatedAt: time.Now().UTC(),
+	}
+	return nil
+}
+
+// ==========================================
+// TASK GUARD REPOSITORY
+// ==========================================
+
+type taskGuardRepository struct {
+	mu        sync.RWMutex
+	decisions map[int]repository.GuardDecision
+}
+
+func (r *taskGuardRepository) Record(ctx context.Context, decision *repository.GuardDecision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	decision.ID = nextID(r.decisions)
+	r.decisions[decision.ID] = *decision
+	return nil
+}
+
+func (r *taskGuardRepository) Get(ctx context.Context, id int) (*repository.GuardDecision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decision, ok := r.decisions[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &decision, nil
+}
+
+func (r *taskGuardRepository) List(ctx context.Context, filter repository.GuardDecisionFilter) ([]repository.GuardDecision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decisions := []repository.GuardDecision{}
+	for _, decision := range sortedValues(r.decisions) {
+		if matchesGuardFilter(decision, filter) {
+			decisions = append(decisions, decision)
+		}
+	}
+	sort.SliceStable(decisions, func(i, j int) bool { return decisions[i].ID > decisions[j].ID })
+	if filter.Limit > 0 && len(decisions) > filter.Limit {
+		decisions = decisions[:filter.Limit]
+	}
+	return decisions, nil
+}
+
+func (r *taskGuardRepository) Count(ctx context.Context, filter repository.GuardDecisionFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, decision := range r.decisions {
+		if matchesGuardFilter(decision, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *taskGuardRepository) Resolve(ctx context.Context, id int, reviewStatus, reviewedBy, note string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	decision, ok := r.decisions[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if decision.ReviewStatus != repository.ReviewPending {
+		return repository.ErrConflict
+	}
+	decision.ReviewStatus = reviewStatus
+	decision.ReviewedBy = reviewedBy
+	decision.ReviewNote = note
+	decision.ReviewedAt = &at
+	r.decisions[id] = decision
+	return nil
+}
+
+func matchesGuardFilter(decision repository.GuardDecision, filter repository.GuardDecisionFilter) bool {
+	return (filter.UserID == 0 || decision.UserID == filter.UserID) &&
+		(filter.TaskID == 0 || decision.TaskID == filter.TaskID) &&
+		(filter.Outcome == "" || decision.Outcome == filter.Outcome) &&
+		(filter.ReviewStatus == "" || decision.ReviewStatus == filter.ReviewStatus) &&
+		(filter.EvidenceHash == "" || decision.EvidenceHash == filter.EvidenceHash) &&
+		!decision.CreatedAt.Before(filter.Since)
+}
+
+// ==========================================
+// AI QUOTA REPOSITORY
+// ==========================================
+
+type aiQuotaKey struct {
+	userID    int
+	weekStart int64
+}
+
+type aiQuotaRepository struct {
+	mu       sync.RWMutex
+	usage    map[aiQuotaKey]repository.AiAgentUsage
+	consumes map[string]repository.AiAgentConsume
+}
+
+// week returns the stored usage of a week, with zero counts when nothing was consumed that week
+func (r *aiQuotaRepository) week(userID int, weekStart time.Time) repository.AiAgentUsage {
+	if usage, ok := r.usage[aiQuotaKey{userID: userID, weekStart: weekStart.UnixMilli()}]; ok {
+		return usage
+	}
+	return repository.AiAgentUsage{UserID: userID, WeekStart: weekStart.UTC()}
+}
+
+func (r *aiQuotaRepository) Consume(ctx context.Context, consumeID string, userID int, weekStart time.Time, uses, level, quota int) (*repository.AiAgentUsage, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.consumes[consumeID]; ok {
+		return nil, false, repository.ErrConflict
+	}
+	usage := r.week(userID, weekStart)
+	if usage.Consumed-usage.Refunded+uses > quota {
+		return &usage, false, nil
+	}
+	now := time.Now().UTC()
+	usage.Consumed += uses
+	usage.Level, usage.Quota = level, quota
+	usage.UpdatedAt = now
+	r.usage[aiQuotaKey{userID: userID, weekStart: weekStart.UnixMilli()}] = usage
+	r.consumes[consumeID] = repository.AiAgentConsume{ID: consumeID, UserID: userID, WeekStart: weekStart.UTC(),
+		Uses: uses, CreatedAt: now}
+	return &usage, true, nil
+}
+
+func (r *aiQuotaRepository) Refund(ctx context.Context, consumeID string) (*repository.AiAgentUsage, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	consume, ok := r.consumes[consumeID]
+	if !ok {
+		return nil, false, repository.ErrNotFound
+	}
+	usage := r.week(consume.UserID, consume.WeekStart)
+	if consume.RefundedAt != nil {
+		return &usage, false, nil
+	}
+	now := time.Now().UTC()
+	consume.RefundedAt = &now
+	r.consumes[consumeID] = consume
+	usage.Refunded += consume.Uses
+	usage.UpdatedAt = now
+	r.usage[aiQuotaKey{userID: consume.UserID, weekStart: consume.WeekStart.UnixMilli()}] = usage
+	return &usage, true, nil
+}
+
+func (r *aiQuotaRepository) Get(ctx context.Context, userID int, weekStart time.Time) (*repository.AiAgentUsage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usage := r.week(userID, weekStart)
+	return &usage, nil
+}
+
+func (r *aiQuotaRepository) ListByUser(ctx context.Context, userID, limit int) ([]repository.AiAgentUsage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	weeks := []repository.AiAgentUsage{}
+	for key, usage := range r.usage {
+		if key.userID == userID {
+			weeks = append(weeks, usage)
+		}
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].WeekStart.After(weeks[j].WeekStart) })
+	if limit > 0 && len(weeks) > limit {
+		weeks = weeks[:limit]
+	}
+	return weeks, nil
+}
+
+// ==========================================
+// FEE LEDGER REPOSITORY
+// ==========================================
+
+type feeLedgerRepository struct {
+	mu      sync.RWMutex
+	entries map[int]repository.FeeSaving
+}
+
+func (r *feeLedgerRepository) Append(ctx context.Context, entry *repository.FeeSaving) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.entries {
+		if existing.Platform == entry.Platform && existing.TradeID == entry.TradeID {
+			return repository.ErrConflict
+		}
+	}
+	entry.ID = nextID(r.entries)
+	r.entries[entry.ID] = *entry
+	return nil
+}
+
+func (r *feeLedgerRepository) ListByUser(ctx context.Context, userID, limit, offset int) ([]repository.FeeSaving, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []repository.FeeSaving{}
+	for _, entry := range sortedValues(r.entries) {
+		if entry.UserID == userID {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].ExecutedAt.Equal(entries[j].ExecutedAt) {
+			return entries[i].ExecutedAt.After(entries[j].ExecutedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	if limit > 0 {
+		entries = entries[min(offset, len(entries)):min(offset+limit, len(entries))]
+	}
+	return entries, nil
+}
+
+func (r *feeLedgerRepository) CountByUser(ctx context.Context, userID int) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, entry := range r.entries {
+		if entry.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *feeLedgerRepository) TotalsByUser(ctx context.Context, userID int) ([]repository.PlatformFeeSaved, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byAccount := map[[2]string]*repository.PlatformFeeSaved{}
+	totals := []*repository.PlatformFeeSaved{}
+	for _, entry := range sortedValues(r.entries) {
+		if entry.UserID != userID {
+			continue
+		}
+		key := [2]string{entry.Platform, entry.PlatformWallet}
+		total, ok := byAccount[key]
+		if !ok {
+			total = &repository.PlatformFeeSaved{Platform: entry.Platform, PlatformWallet: entry.PlatformWallet}
+			byAccount[key] = total
+			totals = append(totals, total)
+		}
+		saved, err := total.FeeSaved.Add(entry.FeeSaved)
+		if err != nil {
+			return nil, err
+		}
+		total.FeeSaved = saved
+		total.Trades++
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Platform != totals[j].Platform {
+			return totals[i].Platform < totals[j].Platform
+		}
+		return totals[i].PlatformWallet < totals[j].PlatformWallet
+	})
+
+	result := make([]repository.PlatformFeeSaved, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	return result, nil
+}
+
+// ==========================================
+// VOLUME REPOSITORY
+// ==========================================
+
+type volumeRollupKey struct {
+	userID   int
+	day      int64
+	platform string
+}
+
+type volumeRepository struct {
+	mu      sync.RWMutex
+	users   *userRepository // the qualifying volume is kept on the user, like the SQLite store does
+	trades  map[int]repository.VolumeTrade
+	rollups map[volumeRollupKey]repository.VolumeRollup
+	drifts  map[int]repository.VolumeDrift
+}
+
+func rollupKey(userID int, executedAt time.Time, platform string) volumeRollupKey {
+	day := time.Date(executedAt.Year(), executedAt.Month(), executedAt.Day(), 0, 0, 0, 0, time.UTC)
+	return volumeRollupKey{userID: userID, day: day.UnixMilli(), platform: platform}
+}
+
+func (r *volumeRepository) RecordTrade(ctx context.Context, trade *repository.VolumeTrade) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.trades {
+		if existing.Platform == trade.Platform && existing.TradeID == trade.TradeID {
+			return repository.ErrConflict
+		}
+	}
+	key := rollupKey(trade.UserID, trade.ExecutedAt.UTC(), trade.Platform)
+	rollup := r.rollups[key]
+	volume, err := rollup.Volume.Add(trade.Notional)
+	if err != nil {
+		return err
+	}
+	trade.ID = nextID(r.trades)
+	r.trades[trade.ID] = *trade
+
+	rollup.UserID, rollup.Day, rollup.Platform = key.userID, time.UnixMilli(key.day).UTC(), key.platform
+	rollup.Volume = volume
+	rollup.Trades++
+	rollup.UpdatedAt = trade.IngestedAt
+	r.rollups[key] = rollup
+	return nil
+}
+
+func (r *volumeRepository) ListTrades(ctx context.Context, userID, limit, offset int) ([]repository.VolumeTrade, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trades := []repository.VolumeTrade{}
+	for _, trade := range sortedValues(r.trades) {
+		if trade.UserID == userID {
+			trades = append(trades, trade)
+		}
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		if !trades[i].ExecutedAt.Equal(trades[j].ExecutedAt) {
+			return trades[i].ExecutedAt.After(trades[j].ExecutedAt)
+		}
+		return trades[i].ID > trades[j].ID
+	})
+	if limit > 0 {
+		trades = trades[min(offset, len(trades)):min(offset+limit, len(trades))]
+	}
+	return trades, nil
+}
+
+func (r *volumeRepository) CountTrades(ctx context.Context, userID int) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, trade := range r.trades {
+		if trade.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *volumeRepository) RefreshQualifyingVolume(ctx context.Context, userID int) (money.Amount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.refreshLocked(userID)
+}
+
+// refreshLocked sets the user's TradingVolume to their rollup total; the caller holds r.mu
+func (r *volumeRepository) refreshLocked(userID int) (money.Amount, error) {
+	volume := money.USDT.Units(0)
+	for key, rollup := range r.rollups {
+		if key.userID != userID {
+			continue
+		}
+		var err error
+		if volume, err = volume.Add(rollup.Volume); err != nil {
+			return money.Amount{}, err
+		}
+	}
+
+	r.users.mu.Lock()
+	defer r.users.mu.Unlock()
+	user, ok := r.users.users[userID]
+	if !ok {
+		return money.Amount{}, repository.ErrNotFound
+	}
+	user.TradingVolume = volume
+	user.UpdatedAt = time.Now().UTC()
+	r.users.users[userID] = user
+	return volume, nil
+}
+
+func (r *volumeRepository) Rebuild(ctx context.Context, userID int) (money.Amount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sums, err := r.sumLocked(userID)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	for key := range r.rollups {
+		if key.userID == userID {
+			delete(r.rollups, key)
+		}
+	}
+	now := time.Now().UTC()
+	for _, rollup := range sums {
+		rollup.UpdatedAt = now
+		r.rollups[volumeRollupKey{userID: userID, day: rollup.Day.UnixMilli(), platform: rollup.Platform}] = rollup
+	}
+	return r.refreshLocked(userID)
+}
+
+func (r *volumeRepository) ListRollups(ctx context.Context, userID int) ([]repository.VolumeRollup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rollups := []repository.VolumeRollup{}
+	for key, rollup := range r.rollups {
+		if userID == 0 || key.userID == userID {
+			rollups = append(rollups, rollup)
+		}
+	}
+	sortRollups(rollups)
+	return rollups, nil
+}
+
+func (r *volumeRepository) SumTrades(ctx context.Context, userID int) ([]repository.VolumeRollup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sumLocked(userID)
+}
+
+// sumLocked sums raw trades per user, day and platform; the caller holds r.mu
+func (r *volumeRepository) sumLocked(userID int) ([]repository.VolumeRollup, error) {
+	sums := map[volumeRollupKey]*repository.VolumeRollup{}
+	for _, trade := range sortedValues(r.trades) {
+		if userID != 0 && trade.UserID != userID {
+			continue
+		}
+		key := rollupKey(trade.UserID, trade.ExecutedAt.UTC(), trade.Platform)
+		sum, ok := sums[key]
+		if !ok {
+			sum = &repository.VolumeRollup{UserID: key.userID, Day: time.UnixMilli(key.day).UTC(), Platform: key.platform}
+			sums[key] = sum
+		}
+		volume, err := sum.Volume.Add(trade.Notional)
+		if err != nil {
+			return nil, err
+		}
+		sum.Volume = volume
+		sum.Trades++
+	}
+
+	rollups := make([]repository.VolumeRollup, 0, len(sums))
+	for _, sum := range sums {
+		rollups = append(rollups, *sum)
+	}
+	sortRollups(rollups)
+	return rollups, nil
+}
+
+func sortRollups(rollups []repository.VolumeRollup) {
+	sort.Slice(rollups, func(i, j int) bool {
+		a, b := rollups[i], rollups[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		return a.Platform < b.Platform
+	})
+}
+
+func (r *volumeRepository) AddDrift(ctx context.Context, drift *repository.VolumeDrift) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	drift.ID = nextID(r.drifts)
+	r.drifts[drift.ID] = *drift
+	return nil
+}
+
+func (r *volumeRepository) ListDrifts(ctx context.Context, filter repository.VolumeDriftFilter) ([]repository.VolumeDrift, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	drifts := []repository.VolumeDrift{}
+	values := sortedValues(r.drifts)
+	for i := len(values) - 1; i >= 0; i-- {
+		drift := values[i]
+		if (filter.UserID != 0 && drift.UserID != filter.UserID) || (filter.OpenOnly && drift.ResolvedAt != nil) {
+			continue
+		}
+		drifts = append(drifts, drift)
+		if filter.Limit > 0 && len(drifts) == filter.Limit {
+			break
+		}
+	}
+	return drifts, nil
+}
+
+func (r *volumeRepository) ResolveDrift(ctx context.Context, id int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	drift, ok := r.drifts[id]
+	if !ok || drift.ResolvedAt != nil {
+		return nil
+	}
+	drift.ResolvedAt = &at
+	r.drifts[id] = drift
+	return nil
+}
+
+// ==========================================
+// EXCHANGE ACCOUNT REPOSITORY
+// ==========================================
+
+type exchangeAccountRepository struct {
+	mu       sync.RWMutex
+	accounts map[int]repository.ExchangeAccount
+}
+
+func (r *exchangeAccountRepository) Create(ctx context.Context, account *repository.ExchangeAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.accounts {
+		if existing.Platform != account.Platform {
+			continue
+		}
+		if existing.UserID == account.UserID || existing.AccountID == account.AccountID {
+			return repository.ErrConflict
+		}
+	}
+	account.ID = nextID(r.accounts)
+	r.accounts[account.ID] = *account
+	return nil
+}
+
+func (r *exchangeAccountRepository) Get(ctx context.Context, userID int, platform string) (*repository.ExchangeAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, account := range r.accounts {
+		if account.UserID == userID && account.Platform == platform {
+			return &account, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *exchangeAccountRepository) ListByUser(ctx context.Context, userID int) ([]repository.ExchangeAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := []repository.ExchangeAccount{}
+	for _, account := range sortedValues(r.accounts) {
+		if account.UserID == userID {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (r *exchangeAccountRepository) List(ctx context.Context) ([]repository.ExchangeAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sortedValues(r.accounts), nil
+}
+
+func (r *exchangeAccountRepository) UpdateSync(ctx context.Context, account *repository.ExchangeAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.accounts[account.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	existing.Cursor = account.Cursor
+	existing.SyncedAt = account.SyncedAt
+	existing.SyncError = account.SyncError
+	existing.UpdatedAt = account.UpdatedAt
+	r.accounts[account.ID] = existing
+	return nil
+}
+
+func (r *exchangeAccountRepository) Delete(ctx context.Context, userID int, platform string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, account := range r.accounts {
+		if account.UserID == userID && account.Platform == platform {
+			delete(r.accounts, id)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// ==========================================
+// AVATAR REPOSITORY
+// ==========================================
+
+type avatarRepository struct {
+	mu      sync.RWMutex
+	avatars map[int]repository.Avatar
+}
+
+func (r *avatarRepository) List(ctx context.Context) ([]repository.Avatar, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sortedValues(r.avatars), nil
+}
+
+func (r *avatarRepository) GetByID(ctx context.Context, id int) (*repository.Avatar, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	avatar, ok := r.avatars[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &avatar, nil
+}
+
+func (r *avatarRepository) Create(ctx context.Context, avatar *repository.Avatar) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if avatar.ID == 0 {
+		avatar.ID = nextID(r.avatars)
+	} else if _, exists := r.avatars[avatar.ID]; exists {
+		return repository.ErrConflict
+	}
+	r.avatars[avatar.ID] = *avatar
+	return nil
+}
+
+func (r *avatarRepository) Update(ctx context.Context, avatar *repository.Avatar) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.avatars[avatar.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.avatars[avatar.ID] = *avatar
+	return nil
+}
+
+func (r *avatarRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.avatars[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.avatars, id)
+	return nil
+}
+
+// ==========================================
+// SEQUENCE REPOSITORY
+// ==========================================
+
+type sequenceAssignmentKey struct {
+	name string
+	key  string
+}
+
+type sequenceRepository struct {
+	mu          sync.Mutex
+	values      map[string]int
+	assignments map[sequenceAssignmentKey]int
+}
+
+func (r *sequenceRepository) Next(ctx context.Context, name string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values[name]++
+	return r.values[name], nil
+}
+
+func (r *sequenceRepository) Assign(ctx context.Context, name, key string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if value, ok := r.assignments[sequenceAssignmentKey{name, key}]; ok {
+		return value, nil
+	}
+	r.values[name]++
+	r.assignments[sequenceAssignmentKey{name, key}] = r.values[name]
+	return r.values[name], nil
+}
+
+// ==========================================
+// LEASE REPOSITORY
+// ==========================================
+
+type leaseRepository struct {
+	mu     sync.Mutex
+	leases map[string]repository.Lease
+}
+
+func (r *leaseRepository) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if lease, ok := r.leases[name]; ok && lease.Owner != owner && lease.ExpiresAt.After(now) {
+		return false, nil
+	}
+	r.leases[name] = repository.Lease{Name: name, Owner: owner, ExpiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (r *leaseRepository) Renew(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	lease, ok := r.leases[name]
+	if !ok || lease.Owner != owner || !lease.ExpiresAt.After(now) {
+		return false, nil
+	}
+	lease.ExpiresAt = now.Add(ttl)
+	r.leases[name] = lease
+	return true, nil
+}
+
+func (r *leaseRepository) Release(ctx context.Context, name, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lease, ok := r.leases[name]; ok && lease.Owner == owner {
+		delete(r.leases, name)
+	}
+	return nil
+}
+
+func (r *leaseRepository) ListActive(ctx context.Context) ([]repository.Lease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	leases := []repository.Lease{}
+	for _, lease := range r.leases {
+		if lease.ExpiresAt.After(now) {
+			leases = append(leases, lease)
+		}
+	}
+	sort.Slice(leases, func(i, j int) bool { return leases[i].Name < leases[j].Name })
+	return leases, nil
+}
+
+// ==========================================
+// IDEMPOTENCY REPOSITORY
+// ==========================================
+
+type idempotencyKey struct {
+	scope string
+	key   string
+}
+
+type idempotencyRepository struct {
+	mu      sync.Mutex
+	records map[idempotencyKey]repository.IdempotencyRecord
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *repository.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := idempotencyKey{scope: record.Scope, key: record.Key}
+	if existing, ok := r.records[key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return repository.ErrConflict
+	}
+	r.records[key] = copyIdempotencyRecord(*record)
+	return nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, scope, key string) (*repository.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[idempotencyKey{scope: scope, key: key}]
+	if !ok || !record.ExpiresAt.After(time.Now()) {
+		return nil, repository.ErrNotFound
+	}
+	record = copyIdempotencyRecord(record)
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *repository.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := idempotencyKey{scope: record.Scope, key: record.Key}
+	if _, ok := r.records[key]; !ok {
+		return repository.ErrNotFound
+	}
+	r.records[key] = copyIdempotencyRecord(*record)
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, idempotencyKey{scope: scope, key: key})
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := 0
+	for key, record := range r.records {
+		if !record.ExpiresAt.After(now) {
+			delete(r.records, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// copyIdempotencyRecord copies the body so callers cannot modify the stored response
+func copyIdempotencyRecord(record repository.IdempotencyRecord) repository.IdempotencyRecord {
+	record.Body = append([]byte(nil), record.Body...)
+	return record
+}
@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// ==========================================
+// USER RECORDS
+// ==========================================
+
+// User represents a platform user (mirrors the lastmemefi-api User model)
+type User struct {
+	ID                 int
+	AccessToken        string
+	TwitterAccessToken string
+	Username           string
+	Nickname           string
+	WalletAddr         string
+	Email              string
+	Bio                string
+	ProfilePhotoURL    string
+	BannerURL          string
+	ProfileAvatarID    *int
+	TradingVolume      money.Amount // qualifying trading volume, the user's rollup total
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// ==========================================
+// NFT RECORDS
+// ==========================================
+
+// Tiered NFT database statuses (Locked/Unlockable are derived, never stored)
+const (
+	NftStatusActive = "active"
+	NftStatusBurned = "burned"
+)
+
+// TieredNft represents a minted tiered NFT instance (mirrors the UserNft model)
+type TieredNft struct {
+	ID                int
+	UserID            int
+	Level             int
+	Name              string
+	Status            string
+	MintAddress       string
+	ATAAddress        string
+	MetadataPDA       string
+	MetadataURI       string
+	ImageURI          string
+	SerialNumber      int // per-level mint number shown in the on-chain name, 0 for NFTs minted before numbering
+	TransactionID     string
+	BenefitsActivated bool
+	MintedAt          time.Time
+	BurnedAt          *time.Time
+}
+
+// NFT kinds whose benefits can be activated
+const (
+	NftTypeTiered      = "tiered"
+	NftTypeCompetition = "competition"
+)
+
+// BenefitsInterval is a span during which an NFT's benefits were activated; DeactivatedAt is nil while
+// they still are
+type BenefitsInterval struct {
+	ID            int
+	UserID        int
+	NftType       string // NftTypeTiered or NftTypeCompetition
+	NftID         int
+	ActivatedAt   time.Time
+	DeactivatedAt *time.Time
+}
+
+// Covers reports whether the benefits were activated at the given moment
+func (i BenefitsInterval) Covers(at time.Time) bool {
+	return !at.Before(i.ActivatedAt) && (i.DeactivatedAt == nil || at.Before(*i.DeactivatedAt))
+}
+
+// NFT upgrade request statuses. A request moves pending -> burn_confirmed -> mint_confirmed -> completed;
+// failed keeps the completed steps so the upgrade can be resumed from where it stopped.
+const (
+	UpgradeStatusPending       = "pending"
+	UpgradeStatusBurnConfirmed = "burn_confirmed"
+	UpgradeStatusMintConfirmed = "mint_confirmed"
+	UpgradeStatusCompleted     = "completed"
+	UpgradeStatusFailed        = "failed"
+)
+
+// UpgradeRequest is a burn-then-mint upgrade saga (mirrors the NFTUpgradeRequest model)
+type UpgradeRequest struct {
+	ID              int
+	UserID          int
+	FromLevel       int
+	ToLevel         int
+	OldNftID        int
+	OldNftMint      string
+	NewNftID        int
+	NewNftMint      string
+	SerialNumber    int
+	BadgeIDs        []int
+	BurnTransaction string
+	MintTransaction string
+	Status          string
+	RetryCount      int
+	ErrorMessage    string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Unfinished reports whether the upgrade still has to be resumed: it is pending or part way through, or it
+// failed after the old NFT was burned. A request that failed before burning changed nothing.
+func (r UpgradeRequest) Unfinished() bool {
+	switch r.Status {
+	case UpgradeStatusCompleted:
+		return false
+	case UpgradeStatusFailed:
+		return r.BurnTransaction != ""
+	}
+	return true
+}
+
+// UpgradeStep is an append-only entry in an upgrade request's step log
+type UpgradeStep struct {
+	ID               int
+	UpgradeRequestID int
+	Step             string
+	Detail           string
+	CreatedAt        time.Time
+}
+
+// CompetitionNft represents a competition NFT awarded to a winner
+type CompetitionNft struct {
+	ID                  int
+	UserID              int
+	Name                string
+	ImageURL            string
+	MintAddress         string
+	ATAAddress          string
+	MetadataPDA         string
+	MetadataURI         string
+	ImageURI            string
+	TransactionID       string
+	CompetitionID       int
+	CompetitionName     string
+	CompetitionType     string
+	Rank                int
+	TradingFeeReduction int
+	BenefitsActivated   bool
+	MintedAt            time.Time
+}
+
+// ==========================================
+// BADGE AND TASK RECORDS
+// ==========================================
+
+// Requirement is a single condition expressed as a type/value pair
+type Requirement struct {
+	Type  string
+	Value int
+}
+
+// BadgeDefinition represents a badge in the catalog
+type BadgeDefinition struct {
+	ID                int
+	NftLevel          int
+	Name              string
+	Description       string
+	Category          string
+	Level             int
+	IconURL           string
+	TaskID            int
+	ContributionValue float64
+	Requirements      []Requirement
+	SortOrder         int        // position within its NFT level; catalogs list by level, then SortOrder
+	Version           int        // bumped by every change to the definition, starting at 1
+	RetiredAt         *time.Time // retired badges can no longer be earned but stay with their holders
+	// How long an activation lasts, overriding the NFT level's window; nil uses the level's, 0 never expires
+	ActivationWindowHours *int
+}
+
+// UserBadge represents a badge owned by a user together with its lifecycle timestamps
+type UserBadge struct {
+	UserID            int
+	BadgeID           int
+	Status            string
+	DefinitionVersion int // version of the definition the badge was earned under, 0 for version 1
+	EarnedAt          *time.Time
+	ActivatedAt       *time.Time
+	ConsumedAt        *time.Time
+	ExpiresAt         *time.Time // when an activation returns to owned, nil if it does not expire
+	ExpiryNotifiedAt  *time.Time // when the holder was told the activation is about to expire
+}
+
+// Task represents a badge task definition (each task awards exactly one badge)
+type Task struct {
+	ID      int
+	Name    string
+	Type    string
+	BadgeID int
+}
+
+// TaskProgress represents a user's progress on a task
+type TaskProgress struct {
+	UserID      int
+	TaskID      int
+	Progress    int
+	Completed   bool
+	CompletedAt *time.Time
+	UpdatedAt   time.Time
+}
+
+// Activity is a per-user counter recorded by the platform (invites sent, strategies created, followers, ...)
+// that task verifiers read instead of trusting client-reported progress
+type Activity struct {
+	UserID    int
+	Metric    string
+	Value     int
+	UpdatedAt time.Time
+}
+
+// Task guard decision outcomes
+const (
+	GuardOutcomeAllowed  = "allowed"
+	GuardOutcomeRejected = "rejected"
+	GuardOutcomeReview   = "review" // held in the review queue instead of being awarded
+)
+
+// Review states of a decision held for review
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// GuardDecision records why the anti-gaming guard allowed, rejected or held a task completion
+type GuardDecision struct {
+	ID           int
+	UserID       int
+	TaskID       int
+	Outcome      string
+	Rule         string // rule that rejected or held the completion, "" when allowed
+	Reason       string
+	EvidenceHash string // hash of the task and its evidence, "" without evidence
+	Progress     int    // measured progress, awarded when a held completion is approved
+	ReviewStatus string // "" unless Outcome is GuardOutcomeReview
+	ReviewedBy   string
+	ReviewNote   string
+	ReviewedAt   *time.Time
+	CreatedAt    time.Time
+}
+
+// GuardDecisionFilter selects decisions; zero fields match everything
+type GuardDecisionFilter struct {
+	UserID       int
+	TaskID       int
+	Outcome      string
+	ReviewStatus string
+	EvidenceHash string
+	Since        time.Time // decisions made at or after Since
+	Limit        int
+}
+
+// ==========================================
+// AI AGENT USAGE RECORDS
+// ==========================================
+
+// AiAgentUsage is a user's AI agent usage in one quota week; uses in effect are Consumed - Refunded
+type AiAgentUsage struct {
+	UserID    int
+	WeekStart time.Time // reset that opened the quota week
+	Level     int       // active NFT level at the last consume
+	Quota     int       // weekly quota of that level
+	Consumed  int
+	Refunded  int
+	UpdatedAt time.Time
+}
+
+// AiAgentConsume is one recorded consume of AI agent uses; a refund names it and gives its uses back once
+type AiAgentConsume struct {
+	ID         string
+	UserID     int
+	WeekStart  time.Time
+	Uses       int
+	CreatedAt  time.Time
+	RefundedAt *time.Time
+}
+
+// ==========================================
+// FEE LEDGER RECORDS
+// ==========================================
+
+// FeeSaving is an append-only fee ledger entry: what one executed trade cost with the user's NFT fee
+// reduction in effect at trade time, against what it would have cost without it. Amounts are in USDT;
+// FeeSaved is exactly UndiscountedFee less ChargedFee.
+type FeeSaving struct {
+	ID              int
+	UserID          int
+	Platform        string // trading platform, e.g. okx or jupiter
+	TradeID         string // the platform's trade identifier; a trade is recorded once per platform
+	PlatformWallet  string // account or wallet the trade was made from on the platform
+	Notional        money.Amount
+	FeeRate         float64 // the platform's fee rate before the reduction, e.g. 0.0005
+	UndiscountedFee money.Amount
+	ChargedFee      money.Amount
+	FeeSaved        money.Amount
+	FeeReduction    int    // reduction percentage in effect at trade time
+	NftType         string // kind of the NFT providing the reduction, "" when none was in effect
+	NftID           int
+	ExecutedAt      time.Time
+	RecordedAt      time.Time
+}
+
+// PlatformFeeSaved is the fee saved on one platform account
+type PlatformFeeSaved struct {
+	Platform       string
+	PlatformWallet string
+	FeeSaved       money.Amount
+	Trades         int
+}
+
+// ==========================================
+// TRADING VOLUME RECORDS
+// ==========================================
+
+// VolumeTrade is a raw trade ingested for trading volume. Raw trades are kept as ingested so the rollups
+// and the users' qualifying volume can be recomputed from them. Notional is in USDT.
+type VolumeTrade struct {
+	ID         int
+	UserID     int
+	Platform   string // trading platform, e.g. okx or jupiter
+	TradeID    string // the platform's trade identifier; a trade is ingested once per platform
+	Source     string // adapter the trade came through, e.g. csv or webhook
+	Notional   money.Amount
+	ExecutedAt time.Time
+	IngestedAt time.Time
+	// Notional is in USDT. Asset is what the trade settled in and AssetNotional its notional in it; Price
+	// is what one unit of the asset was worth in USDT at trade time. Trades in USDT have a price of 1.
+	Asset         string
+	AssetNotional money.Amount
+	Price         money.Amount
+	PriceSource   string     // oracle the price came from, e.g. pyth; "" for trades in USDT
+	PricedAt      *time.Time // when the price was published; nil for trades in USDT
+}
+
+// VolumeRollup is a user's trading volume on one platform and UTC day
+type VolumeRollup struct {
+	UserID    int
+	Day       time.Time // midnight UTC
+	Platform  string
+	Volume    money.Amount
+	Trades    int
+	UpdatedAt time.Time
+}
+
+// Kinds of volume drift found by reconciliation
+const (
+	VolumeDriftRollup     = "rollup"     // a day's rollup differs from the sum of its raw trades
+	VolumeDriftQualifying = "qualifying" // the user's trading volume differs from the sum of their raw trades
+)
+
+// VolumeDrift is a difference between stored volume and the raw trades it is computed from. A drift stays
+// open until reconciliation no longer finds it or the user's volume is recomputed.
+type VolumeDrift struct {
+	ID           int
+	Kind         string
+	UserID       int
+	Day          time.Time // zero for qualifying drift
+	Platform     string    // "" for qualifying drift
+	StoredVolume money.Amount
+	RawVolume    money.Amount
+	StoredTrades int // zero for qualifying drift
+	RawTrades    int
+	DetectedAt   time.Time
+	ResolvedAt   *time.Time
+}
+
+// VolumeDriftFilter selects drifts; zero fields match everything
+type VolumeDriftFilter struct {
+	UserID   int
+	OpenOnly bool
+	Limit    int
+}
+
+// ==========================================
+// EXCHANGE ACCOUNT RECORDS
+// ==========================================
+
+// ExchangeAccount binds a user to their account on a centralized exchange or perp DEX, so the account's
+// trades can be pulled into trading volume and the fee ledger. A user binds one account per platform
+// and an account is bound to one user.
+type ExchangeAccount struct {
+	ID          int
+	UserID      int
+	Platform    string // e.g. okx or hyperliquid
+	AccountID   string // the exchange's account UID, or the wallet address for Hyperliquid
+	Credentials string // sealed API credentials; never returned by the API
+	Cursor      string // position in the account's trade history the next sync resumes from
+	SyncedAt    *time.Time
+	SyncError   string // error of the last sync, "" when it succeeded
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ==========================================
+// AVATAR RECORDS
+// ==========================================
+
+// Avatar represents an admin-managed profile avatar
+type Avatar struct {
+	ID          int
+	Name        string
+	ImageURL    string
+	IpfsHash    string
+	Category    string
+	Description *string
+	IsActive    bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ==========================================
+// LEASE RECORDS
+// ==========================================
+
+// Lease is a time-limited exclusive claim on a named resource, such as a user's NFT operations
+type Lease struct {
+	Name      string
+	Owner     string // random token of the holder, so only the holder can renew or release it
+	ExpiresAt time.Time
+}
+
+// ==========================================
+// IDEMPOTENCY RECORDS
+// ==========================================
+
+// IdempotencyRecord is the first response to a request sent with an Idempotency-Key.
+// StatusCode is 0 while the first request is still being processed.
+type IdempotencyRecord struct {
+	Scope       string // hash of the caller's credentials
+	Key         string
+	RequestHash string // hash of method, path and body
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// ==========================================
+// REPOSITORY ERRORS
+// ==========================================
+
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned when a write would violate a uniqueness constraint
+var ErrConflict = errors.New("record already exists")
+
+// ==========================================
+// REPOSITORY INTERFACES
+// ==========================================
+
+// UserRepository provides access to platform users
+type UserRepository interface {
+	GetByID(ctx context.Context, id int) (*User, error)
+	GetByAccessToken(ctx context.Context, accessToken string) (*User, error)
+	GetByWallet(ctx context.Context, walletAddr string) (*User, error)
+	List(ctx context.Context) ([]User, error)
+	ListByAvatar(ctx context.Context, avatarID int) ([]User, error)
+	// Save creates the user when ID is zero (assigning a new ID) and updates it otherwise
+	Save(ctx context.Context, user *User) error
+}
+
+// TieredNftRepository provides access to tiered NFT instances minted for users
+type TieredNftRepository interface {
+	GetByID(ctx context.Context, id int) (*TieredNft, error)
+	ListByUser(ctx context.Context, userID int) ([]TieredNft, error)
+	Create(ctx context.Context, nft *TieredNft) error
+	Update(ctx context.Context, nft *TieredNft) error
+}
+
+// UpgradeRepository provides access to NFT upgrade requests and their step logs
+type UpgradeRepository interface {
+	Get(ctx context.Context, id int) (*UpgradeRequest, error)
+	ListByUser(ctx context.Context, userID int) ([]UpgradeRequest, error)
+	// ListInFlight returns the unfinished requests (see UpgradeRequest.Unfinished): stopped between steps,
+	// e.g. by a crash, or failed after the burn
+	ListInFlight(ctx context.Context) ([]UpgradeRequest, error)
+	Create(ctx context.Context, req *UpgradeRequest) error
+	Update(ctx context.Context, req *UpgradeRequest) error
+
+	AppendStep(ctx context.Context, step *UpgradeStep) error
+	ListSteps(ctx context.Context, upgradeRequestID int) ([]UpgradeStep, error)
+}
+
+// CompetitionNftRepository provides access to competition NFTs awarded to users
+type CompetitionNftRepository interface {
+	GetByID(ctx context.Context, id int) (*CompetitionNft, error)
+	List(ctx context.Context) ([]CompetitionNft, error)
+	ListByUser(ctx context.Context, userID int) ([]CompetitionNft, error)
+	Create(ctx context.Context, nft *CompetitionNft) error
+	Update(ctx context.Context, nft *CompetitionNft) error
+}
+
+// BenefitsHistoryRepository lists when NFT benefits were activated. The NFT repositories record it:
+// creating an NFT with benefits activated opens an interval at its mint, and saving one whose benefits
+// are activated or deactivated opens or closes its interval, at the burn for a burned NFT.
+type BenefitsHistoryRepository interface {
+	// ListByUser returns the user's activation intervals, oldest first
+	ListByUser(ctx context.Context, userID int) ([]BenefitsInterval, error)
+}
+
+// BadgeRepository provides access to the badge catalog and per-user badge state
+type BadgeRepository interface {
+	ListDefinitions(ctx context.Context) ([]BadgeDefinition, error)
+	GetDefinition(ctx context.Context, id int) (*BadgeDefinition, error)
+	// SaveDefinition creates the definition when ID is zero, updates it otherwise, and records it as
+	// version def.Version (1 when unset)
+	SaveDefinition(ctx context.Context, def *BadgeDefinition) error
+	// ListDefinitionVersions returns every recorded version of a definition, oldest first
+	ListDefinitionVersions(ctx context.Context, id int) ([]BadgeDefinition, error)
+	GetDefinitionVersion(ctx context.Context, id, version int) (*BadgeDefinition, error)
+
+	ListByUser(ctx context.Context, userID int) ([]UserBadge, error)
+	ListHolders(ctx context.Context, badgeID int) ([]UserBadge, error)
+	GetUserBadge(ctx context.Context, userID, badgeID int) (*UserBadge, error)
+	SaveUserBadge(ctx context.Context, badge *UserBadge) error
+	// ListExpiringActivations returns activated badges whose activation expires at or before the given time,
+	// soonest first
+	ListExpiringActivations(ctx context.Context, before time.Time) ([]UserBadge, error)
+}
+
+// TaskRepository provides access to badge task definitions and per-user task progress
+type TaskRepository interface {
+	ListTasks(ctx context.Context) ([]Task, error)
+	GetTask(ctx context.Context, id int) (*Task, error)
+	SaveTask(ctx context.Context, task *Task) error
+
+	ListProgressByUser(ctx context.Context, userID int) ([]TaskProgress, error)
+	GetProgress(ctx context.Context, userID, taskID int) (*TaskProgress, error)
+	SaveProgress(ctx context.Context, progress *TaskProgress) error
+}
+
+// ActivityRepository keeps per-user activity counters; a counter that was never recorded is 0
+type ActivityRepository interface {
+	ListByUser(ctx context.Context, userID int) ([]Activity, error)
+	Get(ctx context.Context, userID int, metric string) (int, error)
+	// Add increments the counter by delta and returns its new value
+	Add(ctx context.Context, userID int, metric string, delta int) (int, error)
+	// Set overwrites the counter, e.g. with a follower count or a Unix timestamp
+	Set(ctx context.Context, userID int, metric string, value int) error
+}
+
+// TaskGuardRepository is the log of anti-gaming decisions and the review queue built on it
+type TaskGuardRepository interface {
+	Record(ctx context.Context, decision *GuardDecision) error
+	Get(ctx context.Context, id int) (*GuardDecision, error)
+	// List returns the matching decisions, newest first
+	List(ctx context.Context, filter GuardDecisionFilter) ([]GuardDecision, error)
+	Count(ctx context.Context, filter GuardDecisionFilter) (int, error)
+	// Resolve closes a pending review; it returns ErrConflict when the review is not pending
+	Resolve(ctx context.Context, id int, reviewStatus, reviewedBy, note string, at time.Time) error
+}
+
+// AiQuotaRepository meters AI agent uses per user and quota week
+type AiQuotaRepository interface {
+	// Consume adds uses to the week's usage when the uses in effect stay within quota, recording the level
+	// and quota with them and the consume under consumeID. It returns the week's usage and whether the uses
+	// were recorded.
+	Consume(ctx context.Context, consumeID string, userID int, weekStart time.Time, uses, level, quota int) (*AiAgentUsage, bool, error)
+	// Refund gives back the uses of the consume recorded under consumeID unless it was refunded before. It
+	// returns the usage of the consume's week and whether the refund was recorded; ErrNotFound when no
+	// consume has the ID.
+	Refund(ctx context.Context, consumeID string) (*AiAgentUsage, bool, error)
+	// Get returns the week's usage, with zero counts when nothing was consumed that week
+	Get(ctx context.Context, userID int, weekStart time.Time) (*AiAgentUsage, error)
+	// ListByUser returns the user's weekly usage, newest week first; limit 0 returns every week
+	ListByUser(ctx context.Context, userID, limit int) ([]AiAgentUsage, error)
+}
+
+// FeeLedgerRepository is the append-only ledger of fees saved on executed trades
+type FeeLedgerRepository interface {
+	// Append records an entry; it returns ErrConflict when the platform's trade is already recorded
+	Append(ctx context.Context, entry *FeeSaving) error
+	// ListByUser returns the user's entries, latest trade first; limit 0 returns every entry
+	ListByUser(ctx context.Context, userID, limit, offset int) ([]FeeSaving, error)
+	// CountByUser returns how many entries the user has
+	CountByUser(ctx context.Context, userID int) (int, error)
+	// TotalsByUser returns the fee saved per platform account, ordered by platform and wallet
+	TotalsByUser(ctx context.Context, userID int) ([]PlatformFeeSaved, error)
+}
+
+// VolumeRepository stores raw trades, their per-day and per-platform rollups and reconciliation findings
+type VolumeRepository interface {
+	// RecordTrade stores a raw trade and adds it to its day's rollup in one write. It returns ErrConflict
+	// when the platform's trade ID was already recorded.
+	RecordTrade(ctx context.Context, trade *VolumeTrade) error
+	// ListTrades returns the user's raw trades, latest first; limit 0 returns every trade
+	ListTrades(ctx context.Context, userID, limit, offset int) ([]VolumeTrade, error)
+	// CountTrades returns how many raw trades the user has
+	CountTrades(ctx context.Context, userID int) (int, error)
+	// RefreshQualifyingVolume sets the user's TradingVolume to their rollup total and returns it
+	RefreshQualifyingVolume(ctx context.Context, userID int) (money.Amount, error)
+	// Rebuild replaces the user's rollups with the sums of their raw trades, refreshes their qualifying
+	// volume and returns it
+	Rebuild(ctx context.Context, userID int) (money.Amount, error)
+	// ListRollups returns rollups ordered by user, day and platform; userID 0 lists every user
+	ListRollups(ctx context.Context, userID int) ([]VolumeRollup, error)
+	// SumTrades returns raw trades summed like rollups, without UpdatedAt; userID 0 sums every user
+	SumTrades(ctx context.Context, userID int) ([]VolumeRollup, error)
+
+	AddDrift(ctx context.Context, drift *VolumeDrift) error
+	ListDrifts(ctx context.Context, filter VolumeDriftFilter) ([]VolumeDrift, error)
+	// ResolveDrift closes an open drift; a drift already resolved is left untouched
+	ResolveDrift(ctx context.Context, id int, at time.Time) error
+}
+
+// ExchangeAccountRepository stores users' bound exchange accounts and their sync state
+type ExchangeAccountRepository interface {
+	// Create binds an account; it returns ErrConflict when the user already bound an account on the
+	// platform or the account is bound to another user
+	Create(ctx context.Context, account *ExchangeAccount) error
+	Get(ctx context.Context, userID int, platform string) (*ExchangeAccount, error)
+	ListByUser(ctx context.Context, userID int) ([]ExchangeAccount, error)
+	List(ctx context.Context) ([]ExchangeAccount, error)
+	// UpdateSync saves the cursor, SyncedAt and SyncError of an account
+	UpdateSync(ctx context.Context, account *ExchangeAccount) error
+	// Delete unbinds the user's account on the platform; it returns ErrNotFound when there is none
+	Delete(ctx context.Context, userID int, platform string) error
+}
+
+// AvatarRepository provides access to admin-managed profile avatars
+type AvatarRepository interface {
+	List(ctx context.Context) ([]Avatar, error)
+	GetByID(ctx context.Context, id int) (*Avatar, error)
+	Create(ctx context.Context, avatar *Avatar) error
+	Update(ctx context.Context, avatar *Avatar) error
+	Delete(ctx context.Context, id int) error
+}
+
+// SequenceRepository allocates gap-free counters such as per-level NFT serial numbers
+type SequenceRepository interface {
+	// Next increments the named counter and returns its new value, starting at 1
+	Next(ctx context.Context, name string) (int, error)
+	// Assign returns the value of the named counter assigned to key, taking the next value the first time
+	// key is seen, so a retried operation keeps the number its first attempt took
+	Assign(ctx context.Context, name, key string) (int, error)
+}
+
+// LeaseRepository grants expiring exclusive leases. A lease that is not renewed expires,
+// so a lock held by a crashed process frees itself after its TTL.
+type LeaseRepository interface {
+	// Acquire takes the lease until now+ttl when it is free, expired or already held by owner.
+	// It returns false when another owner holds an unexpired lease.
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Renew extends owner's unexpired lease; it returns false when the lease was lost
+	Renew(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Release drops owner's lease; a lease held by another owner is left untouched
+	Release(ctx context.Context, name, owner string) error
+	// ListActive returns the unexpired leases
+	ListActive(ctx context.Context) ([]Lease, error)
+}
+
+// IdempotencyRepository stores responses by caller scope and Idempotency-Key until they expire
+type IdempotencyRepository interface {
+	// Reserve records a key whose request is being processed, replacing an expired record.
+	// It returns ErrConflict when an unexpired record exists for the scope and key.
+	Reserve(ctx context.Context, record *IdempotencyRecord) error
+	// Get returns the unexpired record for the scope and key, ErrNotFound otherwise
+	Get(ctx context.Context, scope, key string) (*IdempotencyRecord, error)
+	// Complete saves the response of a reserved key
+	Complete(ctx context.Context, record *IdempotencyRecord) error
+	// Delete drops a key so its request can be processed again
+	Delete(ctx context.Context, scope, key string) error
+	// DeleteExpired removes the records expired at now and returns how many were removed
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// ==========================================
+// STORE
+// ==========================================
+
+// Store groups every repository so handlers can receive their dependencies in one value
+type Store struct {
+	Users           UserRepository
+	TieredNfts      TieredNftRepository
+	Upgrades        UpgradeRepository
+	CompetitionNfts CompetitionNftRepository
+	BenefitsHistory BenefitsHistoryRepository
+	Badges          BadgeRepository
+	Tasks           TaskRepository
+	Activity        ActivityRepository
+	TaskGuard       TaskGuardRepository
+	AiQuota         AiQuotaRepository
+	FeeLedger       FeeLedgerRepository
+	Volume          VolumeRepository
+	Exchanges       ExchangeAccountRepository
+	Avatars         AvatarRepository
+	Sequences       SequenceRepository
+	Leases          LeaseRepository
+	Idempotency     IdempotencyRepository
+}
@@ -0,0 +1,487 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/volume"
+)
+
+// ==========================================
+// DEMO DATA SEEDING
+// ==========================================
+
+// Load populates an empty store with the demo users, badges, tasks, NFTs and avatars
+// that the frontend team uses for local testing. It only relies on the repository
+// interfaces, so it works for every store implementation.
+func Load(ctx context.Context, store *repository.Store) error {
+	steps := []struct {
+		name string
+		fn   func(context.Context, *repository.Store) error
+	}{
+		{"avatars", loadAvatars},
+		{"users", loadUsers},
+		{"trading volume", loadTradingVolume},
+		{"badges", loadBadgeCatalog},
+		{"tiered nfts", loadTieredNfts},
+		{"competition nfts", loadCompetitionNfts},
+		{"user badges", loadUserBadges},
+		{"activity", loadActivity},
+	}
+
+	for _, step := range steps {
+		if err := step.fn(ctx, store); err != nil {
+			return fmt.Errorf("seed %s: %w", step.name, err)
+		}
+	}
+	return nil
+}
+
+// LoadIfEmpty seeds the store only when it has no users yet, so persistent stores
+// keep their data across restarts. It reports whether seeding ran.
+func LoadIfEmpty(ctx context.Context, store *repository.Store) (bool, error) {
+	users, err := store.Users.List(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(users) > 0 {
+		return false, nil
+	}
+	return true, Load(ctx, store)
+}
+
+// mustParse parses an ISO timestamp used in the seed data
+func mustParse(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func timePtr(value string) *time.Time {
+	t := mustParse(value)
+	return &t
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// ==========================================
+// USERS AND AVATARS
+// ==========================================
+
+func loadAvatars(ctx context.Context, store *repository.Store) error {
+	avatars := []repository.Avatar{
+		{
+			ID:          1,
+			Name:        "Golden Trader Avatar",
+			ImageURL:    "https://ipfs.io/ipfs/QmGoldenTrader123",
+			IpfsHash:    "QmGoldenTrader123",
+			Category:    "premium",
+			Description: stringPtr("Exclusive golden trader profile avatar"),
+			IsActive:    true,
+			CreatedAt:   mustParse("2024-01-15T10:30:00Z"),
+			UpdatedAt:   mustParse("2024-01-15T10:30:00Z"),
+		},
+		{
+			ID:          2,
+			Name:        "Default Avatar 1",
+			ImageURL:    "https://ipfs.io/ipfs/QmDefaultAvatar1",
+			IpfsHash:    "QmDefaultAvatar1",
+			Category:    "default",
+			Description: stringPtr("Standard default profile avatar"),
+			IsActive:    true,
+			CreatedAt:   mustParse("2024-01-10T09:00:00Z"),
+			UpdatedAt:   mustParse("2024-01-10T09:00:00Z"),
+		},
+		{
+			ID:          3,
+			Name:        "Competition Winner",
+			ImageURL:    "https://ipfs.io/ipfs/QmCompWinner456",
+			IpfsHash:    "QmCompWinner456",
+			Category:    "special",
+			Description: stringPtr("Special avatar for competition winners"),
+			IsActive:    true,
+			CreatedAt:   mustParse("2024-01-20T16:45:00Z"),
+			UpdatedAt:   mustParse("2024-01-20T16:45:00Z"),
+		},
+		{
+			ID:          4,
+			Name:        "Beta Tester",
+			ImageURL:    "https://ipfs.io/ipfs/QmBetaTester789",
+			IpfsHash:    "QmBetaTester789",
+			Category:    "special",
+			Description: stringPtr("Exclusive beta tester avatar"),
+			IsActive:    false,
+			CreatedAt:   mustParse("2023-12-01T08:00:00Z"),
+			UpdatedAt:   mustParse("2024-01-01T12:00:00Z"),
+		},
+	}
+
+	for i := range avatars {
+		if err := store.Avatars.Create(ctx, &avatars[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadUsers(ctx context.Context, store *repository.Store) error {
+	now := time.Now().UTC()
+	users := []repository.User{
+		{
+			ID:              12345,
+			AccessToken:     "test_token_123",
+			Username:        "crypto_trader_01",
+			Nickname:        "TestUser",
+			WalletAddr:      "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+			Email:           "test@example.com",
+			Bio:             "Test user for mock API",
+			ProfilePhotoURL: "https://cdn.example.com/profiles/test-user.jpg",
+			BannerURL:       "https://cdn.example.com/banners/test-banner.jpg",
+			ProfileAvatarID: intPtr(1),
+			TradingVolume:   money.USDT.Whole(2850000),
+			CreatedAt:       mustParse("2024-01-01T00:00:00Z"),
+		},
+		{
+			ID:                 54321,
+			TwitterAccessToken: "twitter_token_789",
+			Username:           "twitter_user",
+			Nickname:           "TwitterUser",
+			WalletAddr:         "8VzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtBWWN",
+			Bio:                "Twitter authenticated user",
+			TradingVolume:      money.USDT.Whole(1500000),
+			CreatedAt:          mustParse("2024-02-01T00:00:00Z"),
+		},
+		{
+			ID:              99999,
+			AccessToken:     "admin_token_456",
+			Username:        "admin_user",
+			Nickname:        "AdminUser",
+			WalletAddr:      "AdminWallet123456789",
+			Email:           "admin@example.com",
+			Bio:             "Admin user for mock API",
+			ProfilePhotoURL: "https://cdn.example.com/profiles/admin-user.jpg",
+			TradingVolume:   money.USDT.Whole(10000000),
+			CreatedAt:       mustParse("2023-01-01T00:00:00Z"),
+		},
+		{
+			ID:              67890,
+			AccessToken:     "user_token_456",
+			Username:        "defi_master",
+			Nickname:        "DefiMaster",
+			WalletAddr:      "8XaBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWN",
+			Email:           "defi@example.com",
+			ProfilePhotoURL: "https://ipfs.io/ipfs/QmUserAvatar456",
+			ProfileAvatarID: intPtr(1),
+			TradingVolume:   money.USDT.Whole(750000),
+			CreatedAt:       mustParse("2023-08-20T14:20:00Z"),
+		},
+		{
+			ID:              11111,
+			AccessToken:     "user_token_789",
+			Username:        "nft_collector",
+			Nickname:        "NftCollector",
+			WalletAddr:      "7YcCbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+			Email:           "collector@example.com",
+			ProfilePhotoURL: "https://ipfs.io/ipfs/QmUserAvatar789",
+			ProfileAvatarID: intPtr(2),
+			TradingVolume:   money.USDT.Whole(50000),
+			CreatedAt:       mustParse("2023-10-05T08:00:00Z"),
+		},
+		{
+			ID:              22222,
+			Username:        "trading_pro",
+			Nickname:        "TradingPro",
+			WalletAddr:      "6ZdDbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWP",
+			Email:           "pro@example.com",
+			ProfilePhotoURL: "https://ipfs.io/ipfs/QmUserAvatar999",
+			ProfileAvatarID: intPtr(2),
+			TradingVolume:   money.USDT.Whole(300000),
+			CreatedAt:       mustParse("2023-11-11T11:00:00Z"),
+		},
+		{
+			ID:              33333,
+			Username:        "volume_hunter",
+			Nickname:        "VolumeHunter",
+			WalletAddr:      "5AeEbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWQ",
+			ProfilePhotoURL: "https://ipfs.io/ipfs/QmUserAvatar555",
+			ProfileAvatarID: intPtr(2),
+			TradingVolume:   money.USDT.Whole(120000),
+			CreatedAt:       mustParse("2023-12-24T18:30:00Z"),
+		},
+	}
+
+	for i := range users {
+		users[i].UpdatedAt = now
+		if err := store.Users.Save(ctx, &users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTradingVolume records each demo user's volume as an opening-balance trade, as migration 0017 does
+// for existing databases, so rollups and reconciliation agree with the seeded volume
+func loadTradingVolume(ctx context.Context, store *repository.Store) error {
+	users, err := store.Users.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.TradingVolume.Sign() <= 0 {
+			continue
+		}
+		if err := store.Volume.RecordTrade(ctx, &repository.VolumeTrade{
+			UserID:        user.ID,
+			Platform:      "other",
+			TradeID:       fmt.Sprintf("opening-balance-%d", user.ID),
+			Source:        volume.SourceOpeningBalance,
+			Notional:      user.TradingVolume,
+			ExecutedAt:    user.CreatedAt,
+			IngestedAt:    user.UpdatedAt,
+			Asset:         string(money.USDT),
+			AssetNotional: user.TradingVolume,
+			Price:         money.USDT.Whole(1),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==========================================
+// BADGE CATALOG AND TASKS
+// ==========================================
+
+// badgeSeed describes a catalog badge together with the task that awards it
+type badgeSeed struct {
+	nftLevel    int
+	name        string
+	description string
+	category    string
+	taskName    string
+	taskType    string
+	value       int
+}
+
+// badgeCatalog follows the medal table in AIW3-NFT-Business-Rules-and-Flows.md
+var badgeCatalog = []badgeSeed{
+	// Level 2 badges (2 required for upgrade)
+	{2, "The Contract Enlightener", "Complete the contract novice guidance", "Trading", "Contract Tutorial", "tutorial_complete", 1},
+	{2, "Platform Enlighteners", "Improve personal data", "Profile", "Complete Profile", "profile_complete", 1},
+
+	// Level 3 badges (4 required for upgrade)
+	{3, "Strategic Enlighteners", "Complete the strategy novice guidance", "Strategy", "Strategy Tutorial", "strategy_tutorial_complete", 1},
+	{3, "Newcomers", "Invite one friend to register", "Community", "Invite a Friend", "invite_friend", 1},
+	{3, "Strategy creator", "Complete the creation of 1 strategy", "Strategy", "Create a Strategy", "strategy_create", 1},
+	{3, "Transaction Facilitator", "Invite one of the users to complete the first transaction", "Community", "Referral First Trade", "referral_first_trade", 1},
+
+	// Level 4 badges (5 required for upgrade)
+	{4, "Referral Master", "Invite 2 friends to register", "Community", "Invite 2 Friends", "invite_friend", 2},
+	{4, "Strategic experts", "Create two strategies", "Strategy", "Create 2 Strategies", "strategy_create", 2},
+	{4, "The Enlightenment of the Trading Group", "Complete the guidance for beginners in the group", "Community", "Group Tutorial", "group_tutorial_complete", 1},
+	{4, "Transaction Mentor", "Invite one of the users to complete the first transaction", "Community", "Mentor First Trade", "referral_first_trade", 1},
+	{4, "Ambassador of the trading group", "Join a trading group", "Community", "Join a Trading Group", "group_join", 1},
+
+	// Level 5 badges (6 required for upgrade)
+	{5, "Recruitment General", "Accumulated invitation ≥ 10 people", "Community", "Invite 10 People", "invite_friend", 10},
+	{5, "Strategic Messenger", "Create 5 strategies", "Strategy", "Create 5 Strategies", "strategy_create", 5},
+	{5, "Fission Messenger", "Invite 10 friends to register", "Community", "Invite 10 Friends", "invite_friend", 10},
+	{5, "Transaction Commander", "Invite 5 of the users to complete the first transaction", "Community", "Referral 5 First Trades", "referral_first_trade", 5},
+	{5, "Trading Group Veteran", "Join a group for 3 months", "Community", "Group Membership 90 Days", "group_membership_days", 90},
+	{5, "Influence Talent", "The number of fans in the station is greater than or equal to 25", "Community", "Reach 25 Followers", "follower_count", 25},
+}
+
+func loadBadgeCatalog(ctx context.Context, store *repository.Store) error {
+	for i, entry := range badgeCatalog {
+		id := i + 1
+		taskID := 100 + id
+
+		def := repository.BadgeDefinition{
+			ID:                id,
+			NftLevel:          entry.nftLevel,
+			Name:              entry.name,
+			Description:       entry.description,
+			Category:          entry.category,
+			Level:             entry.nftLevel,
+			IconURL:           fmt.Sprintf("https://cdn.aiw3.com/badges/badge-%d.png", id),
+			TaskID:            taskID,
+			ContributionValue: 1.0,
+			SortOrder:         id,
+			Requirements:      []repository.Requirement{{Type: entry.taskType, Value: entry.value}},
+		}
+		if err := store.Badges.SaveDefinition(ctx, &def); err != nil {
+			return err
+		}
+
+		task := repository.Task{
+			ID:      taskID,
+			Name:    entry.taskName,
+			Type:    entry.taskType,
+			BadgeID: id,
+		}
+		if err := store.Tasks.SaveTask(ctx, &task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==========================================
+// NFTS
+// ==========================================
+
+func loadTieredNfts(ctx context.Context, store *repository.Store) error {
+	nfts := []repository.TieredNft{
+		{
+			UserID:      12345,
+			Level:       1,
+			Name:        "Tech Chicken",
+			Status:      repository.NftStatusBurned,
+			MintAddress: "4HzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtA1L1",
+			ATAAddress:  "5JzXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtA1L1",
+			MetadataPDA: "6KzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtA1L1",
+			MetadataURI: "https://ipfs.io/ipfs/QmTechChickenMetadata0001",
+			ImageURI:    "https://ipfs.io/ipfs/QmTechChickenImage0001",
+			MintedAt:    mustParse("2024-01-15T23:59:59Z"),
+			BurnedAt:    timePtr("2024-02-20T14:30:00Z"),
+		},
+		{
+			UserID:            12345,
+			Level:             2,
+			Name:              "Quant Ape",
+			Status:            repository.NftStatusActive,
+			MintAddress:       "7XzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+			ATAAddress:        "8YzXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWN",
+			MetadataPDA:       "9AzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+			MetadataURI:       "https://ipfs.io/ipfs/QmQuantApeMetadata0001",
+			ImageURI:          "https://ipfs.io/ipfs/QmQuantApeImage0001",
+			BenefitsActivated: true,
+			MintedAt:          mustParse("2024-02-20T14:31:00Z"),
+		},
+		{
+			UserID:      67890,
+			Level:       1,
+			Name:        "Tech Chicken",
+			Status:      repository.NftStatusActive,
+			MintAddress: "3GzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtA1L2",
+			ATAAddress:  "2FzXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtA1L2",
+			MetadataPDA: "1EzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtA1L2",
+			MetadataURI: "https://ipfs.io/ipfs/QmTechChickenMetadata0002",
+			ImageURI:    "https://ipfs.io/ipfs/QmTechChickenImage0002",
+			MintedAt:    mustParse("2024-03-02T09:15:00Z"),
+		},
+	}
+
+	for i := range nfts {
+		if err := store.TieredNfts.Create(ctx, &nfts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadCompetitionNfts(ctx context.Context, store *repository.Store) error {
+	nft := repository.CompetitionNft{
+		UserID:              12345,
+		Name:                "Trophy Breeder",
+		ImageURL:            "https://cdn.aiw3.com/nfts/competition/trophy-breeder-001.jpg",
+		MintAddress:         "AbzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtC001",
+		ATAAddress:          "BczXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtC001",
+		MetadataPDA:         "CdzYwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtC001",
+		MetadataURI:         "https://ipfs.io/ipfs/QmTrophyBreederMetadata001",
+		ImageURI:            "https://ipfs.io/ipfs/QmTrophyBreederImage001",
+		TransactionID:       "tx_award_1_12345",
+		CompetitionID:       1,
+		CompetitionName:     "Q4 2024 Trading Championship",
+		CompetitionType:     "trading_contest",
+		Rank:                1,
+		TradingFeeReduction: 25,
+		BenefitsActivated:   true,
+		MintedAt:            mustParse("2024-01-15T10:30:00Z"),
+	}
+	return store.CompetitionNfts.Create(ctx, &nft)
+}
+
+// ==========================================
+// USER BADGES AND TASK PROGRESS
+// ==========================================
+
+func loadUserBadges(ctx context.Context, store *repository.Store) error {
+	userBadges := []repository.UserBadge{
+		// crypto_trader_01 consumed both Level 2 badges when upgrading to Quant Ape
+		{UserID: 12345, BadgeID: 1, Status: "consumed", EarnedAt: timePtr("2024-01-20T08:30:00Z"), ActivatedAt: timePtr("2024-01-22T10:15:00Z"), ConsumedAt: timePtr("2024-02-20T14:30:00Z")},
+		{UserID: 12345, BadgeID: 2, Status: "consumed", EarnedAt: timePtr("2024-01-21T08:30:00Z"), ActivatedAt: timePtr("2024-01-22T10:16:00Z"), ConsumedAt: timePtr("2024-02-20T14:30:00Z")},
+		{UserID: 12345, BadgeID: 3, Status: "activated", EarnedAt: timePtr("2024-03-01T12:00:00Z"), ActivatedAt: timePtr("2024-03-02T12:00:00Z")},
+		{UserID: 12345, BadgeID: 4, Status: "owned", EarnedAt: timePtr("2024-03-05T16:45:00Z")},
+
+		// defi_master is collecting Level 2 badges for the first upgrade
+		{UserID: 67890, BadgeID: 1, Status: "activated", EarnedAt: timePtr("2024-03-10T09:00:00Z"), ActivatedAt: timePtr("2024-03-11T09:00:00Z")},
+		{UserID: 67890, BadgeID: 2, Status: "owned", EarnedAt: timePtr("2024-03-12T09:00:00Z")},
+	}
+
+	for i := range userBadges {
+		badge := userBadges[i]
+		if err := store.Badges.SaveUserBadge(ctx, &badge); err != nil {
+			return err
+		}
+
+		def, err := store.Badges.GetDefinition(ctx, badge.BadgeID)
+		if err != nil {
+			return err
+		}
+		progress := repository.TaskProgress{
+			UserID:      badge.UserID,
+			TaskID:      def.TaskID,
+			Progress:    100,
+			Completed:   true,
+			CompletedAt: badge.EarnedAt,
+			UpdatedAt:   *badge.EarnedAt,
+		}
+		if err := store.Tasks.SaveProgress(ctx, &progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==========================================
+// USER ACTIVITY
+// ==========================================
+
+// loadActivity records the platform activity the badge task verifiers measure
+func loadActivity(ctx context.Context, store *repository.Store) error {
+	activity := []repository.Activity{
+		// crypto_trader_01 has created a strategy but not completed "Create a Strategy" yet
+		{UserID: 12345, Metric: "tutorial_complete", Value: 1},
+		{UserID: 12345, Metric: "strategy_tutorial_complete", Value: 1},
+		{UserID: 12345, Metric: "invite_friend", Value: 3},
+		{UserID: 12345, Metric: "strategy_create", Value: 1},
+		{UserID: 12345, Metric: "trade_count", Value: 214},
+		{UserID: 12345, Metric: "follower_count", Value: 18},
+		{UserID: 12345, Metric: "group_join", Value: 1},
+		{UserID: 12345, Metric: "group_joined_at", Value: int(mustParse("2024-02-01T00:00:00Z").Unix())},
+
+		// defi_master invited a friend but has not completed "Invite a Friend" yet
+		{UserID: 67890, Metric: "tutorial_complete", Value: 1},
+		{UserID: 67890, Metric: "invite_friend", Value: 1},
+		{UserID: 67890, Metric: "trade_count", Value: 37},
+	}
+
+	for _, counter := range activity {
+		if err := store.Activity.Set(ctx, counter.UserID, counter.Metric, counter.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+func TestMigrationsAreNumberedWithoutGaps(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migration %s has version %d, want %d", m.Name, m.Version, i+1)
+		}
+	}
+}
+
+func TestOpenAppliesEveryMigrationOnce(t *testing.T) {
+	ctx := context.Background()
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := migrations[len(migrations)-1].Version
+	path := filepath.Join(t.TempDir(), "aiw3.db")
+
+	db, err := Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := SchemaVersion(ctx, db); err != nil || version != latest {
+		t.Fatalf("schema version %d, %v; want %d", version, err, latest)
+	}
+	user := &repository.User{Username: "migrated", WalletAddr: "MigratedWallet"}
+	if err := NewStore(db).Users.Save(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	// Migrating an up-to-date database changes nothing
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var applied int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("%d migrations recorded after reopening, want %d", applied, len(migrations))
+	}
+	if _, err := NewStore(db).Users.GetByID(ctx, user.ID); err != nil {
+		t.Errorf("user saved before reopening: %v", err)
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, filepath.Join(t.TempDir(), "aiw3.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	before, err := SchemaVersion(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broken := migration{Version: before + 1, Name: "broken",
+		SQL: "CREATE TABLE half_applied (id INTEGER);\nINSERT INTO missing_table VALUES (1);"}
+	if err := applyMigration(ctx, db, broken); err == nil {
+		t.Fatal("broken migration applied")
+	}
+	if version, err := SchemaVersion(ctx, db); err != nil || version != before {
+		t.Errorf("schema version %d, %v after the failure; want %d", version, err, before)
+	}
+	var tables int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_applied'`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Error("table created by the failed migration was kept")
+	}
+}
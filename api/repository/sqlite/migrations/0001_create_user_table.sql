@@ -0,0 +1,20 @@
+-- Base user table (mirrors the lastmemefi-api User model fields used by this API)
+CREATE TABLE user (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  accessToken VARCHAR(255) NOT NULL DEFAULT '',
+  twitterAccessToken VARCHAR(255) NOT NULL DEFAULT '',
+  username VARCHAR(100) NOT NULL DEFAULT '',
+  nickname VARCHAR(100) NOT NULL DEFAULT '',
+  wallet_address VARCHAR(44) NULL UNIQUE,
+  email VARCHAR(255) NOT NULL DEFAULT '',
+  bio VARCHAR(500) NOT NULL DEFAULT '',
+  profile_photo_url VARCHAR(500) NOT NULL DEFAULT '',
+  banner_url VARCHAR(500) NOT NULL DEFAULT '',
+  profile_avatar_id INT NULL,
+  createdAt DATETIME NOT NULL,
+  updatedAt DATETIME NOT NULL
+);
+
+CREATE INDEX idx_user_access_token ON user (accessToken);
+CREATE INDEX idx_user_twitter_access_token ON user (twitterAccessToken);
+CREATE INDEX idx_user_profile_avatar_id ON user (profile_avatar_id);
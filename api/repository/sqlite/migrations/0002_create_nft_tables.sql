@@ -0,0 +1,92 @@
+-- Migration 1 of the data model: NFT tables
+
+CREATE TABLE usernft (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  nft_mint_address VARCHAR(44) NOT NULL UNIQUE,
+  nft_level TINYINT NOT NULL CHECK (nft_level >= 1),
+  nft_name VARCHAR(100) NOT NULL,
+  ata_address VARCHAR(44) NOT NULL DEFAULT '',
+  metadata_pda VARCHAR(44) NOT NULL DEFAULT '',
+  metadata_uri VARCHAR(500),
+  image_uri VARCHAR(500),
+  status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'burned')),
+  benefits_activated BOOLEAN NOT NULL DEFAULT FALSE,
+  claimed_at DATETIME,
+  last_upgraded_at DATETIME,
+  burned_at DATETIME NULL,
+  createdAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_usernft_user_id ON usernft (user_id);
+CREATE INDEX idx_usernft_nft_level ON usernft (nft_level);
+CREATE INDEX idx_usernft_status ON usernft (status);
+
+CREATE TABLE usernftqualification (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  target_level TINYINT NOT NULL CHECK (target_level >= 1),
+  current_volume DECIMAL(30,10) DEFAULT 0,
+  required_volume DECIMAL(30,10) NOT NULL,
+  badges_owned INT DEFAULT 0,
+  badges_activated INT DEFAULT 0,
+  badges_required INT DEFAULT 0,
+  is_qualified BOOLEAN DEFAULT FALSE,
+  last_checked_at DATETIME,
+  createdAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+  UNIQUE (user_id, target_level)
+);
+
+CREATE INDEX idx_usernftqualification_qualified ON usernftqualification (is_qualified);
+CREATE INDEX idx_usernftqualification_last_checked ON usernftqualification (last_checked_at);
+
+-- Badges owned by users; badge_definition_id links to the catalog created in a later migration
+CREATE TABLE badge (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  badge_definition_id INT NOT NULL,
+  badge_type VARCHAR(32) NOT NULL DEFAULT 'micro_badge' CHECK (badge_type IN ('micro_badge', 'achievement_badge', 'event_badge', 'special_badge')),
+  badge_name VARCHAR(100) NOT NULL,
+  badge_identifier VARCHAR(100) NOT NULL UNIQUE,
+  metadata_uri VARCHAR(500),
+  status VARCHAR(16) NOT NULL DEFAULT 'owned',
+  earned_at DATETIME,
+  activated_at DATETIME NULL,
+  consumed_at DATETIME NULL,
+  createdAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+  UNIQUE (user_id, badge_definition_id)
+);
+
+CREATE INDEX idx_badge_user_id ON badge (user_id);
+CREATE INDEX idx_badge_badge_type ON badge (badge_type);
+CREATE INDEX idx_badge_status ON badge (status);
+
+CREATE TABLE nftupgraderequest (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  from_level TINYINT NOT NULL CHECK (from_level >= 1),
+  to_level TINYINT NOT NULL CHECK (to_level >= 2),
+  old_nft_mint VARCHAR(44) NOT NULL,
+  new_nft_mint VARCHAR(44) NULL,
+  burn_transaction VARCHAR(88) NULL,
+  mint_transaction VARCHAR(88) NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'burn_confirmed', 'mint_confirmed', 'completed', 'failed')),
+  error_message VARCHAR(500) NULL,
+  createdAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_nftupgraderequest_user_id ON nftupgraderequest (user_id);
+CREATE INDEX idx_nftupgraderequest_status ON nftupgraderequest (status);
+CREATE INDEX idx_nftupgraderequest_created_at ON nftupgraderequest (createdAt);
@@ -0,0 +1,9 @@
+-- Migration 2 of the data model: NFT-related user columns
+
+ALTER TABLE user ADD COLUMN current_nft_level TINYINT DEFAULT 0;
+ALTER TABLE user ADD COLUMN last_active_nft_id INT NULL REFERENCES usernft(id) ON DELETE SET NULL;
+ALTER TABLE user ADD COLUMN cached_trading_volume DECIMAL(30,10) DEFAULT 0;
+ALTER TABLE user ADD COLUMN last_volume_update DATETIME NULL;
+
+CREATE INDEX idx_user_nft_level ON user (current_nft_level);
+CREATE INDEX idx_user_cached_volume ON user (cached_trading_volume);
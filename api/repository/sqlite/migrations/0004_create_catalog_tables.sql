@@ -0,0 +1,88 @@
+-- Badge catalog, tasks, competition NFTs and profile avatars
+
+CREATE TABLE badgedefinition (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  nft_level TINYINT NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  description VARCHAR(500) NOT NULL DEFAULT '',
+  category VARCHAR(100) NOT NULL DEFAULT '',
+  level INT NOT NULL DEFAULT 1,
+  icon_url VARCHAR(500) NOT NULL DEFAULT '',
+  task_id INT NOT NULL DEFAULT 0,
+  contribution_value REAL NOT NULL DEFAULT 0,
+  createdAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_badgedefinition_nft_level ON badgedefinition (nft_level);
+
+CREATE TABLE badgerequirement (
+  badge_definition_id INT NOT NULL,
+  position INT NOT NULL,
+  type VARCHAR(64) NOT NULL,
+  value INT NOT NULL,
+
+  PRIMARY KEY (badge_definition_id, position),
+  FOREIGN KEY (badge_definition_id) REFERENCES badgedefinition(id) ON DELETE CASCADE
+);
+
+CREATE TABLE task (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(100) NOT NULL,
+  type VARCHAR(64) NOT NULL,
+  badge_definition_id INT NOT NULL DEFAULT 0,
+  createdAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE taskprogress (
+  user_id INT NOT NULL,
+  task_id INT NOT NULL,
+  progress INT NOT NULL DEFAULT 0,
+  completed BOOLEAN NOT NULL DEFAULT FALSE,
+  completed_at DATETIME NULL,
+  updatedAt DATETIME NOT NULL,
+
+  PRIMARY KEY (user_id, task_id),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+  FOREIGN KEY (task_id) REFERENCES task(id) ON DELETE CASCADE
+);
+
+CREATE TABLE competitionnft (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  nft_name VARCHAR(100) NOT NULL,
+  image_url VARCHAR(500) NOT NULL DEFAULT '',
+  nft_mint_address VARCHAR(44) NOT NULL UNIQUE,
+  ata_address VARCHAR(44) NOT NULL DEFAULT '',
+  metadata_pda VARCHAR(44) NOT NULL DEFAULT '',
+  metadata_uri VARCHAR(500) NOT NULL DEFAULT '',
+  image_uri VARCHAR(500) NOT NULL DEFAULT '',
+  transaction_id VARCHAR(88) NOT NULL DEFAULT '',
+  competition_id INT NOT NULL,
+  competition_name VARCHAR(200) NOT NULL DEFAULT '',
+  competition_type VARCHAR(64) NOT NULL DEFAULT '',
+  rank INT NOT NULL,
+  trading_fee_reduction INT NOT NULL DEFAULT 0,
+  benefits_activated BOOLEAN NOT NULL DEFAULT FALSE,
+  minted_at DATETIME NOT NULL,
+  createdAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updatedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_competitionnft_user_id ON competitionnft (user_id);
+CREATE INDEX idx_competitionnft_competition_id ON competitionnft (competition_id);
+
+CREATE TABLE profileavatar (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(100) NOT NULL,
+  image_url VARCHAR(500) NOT NULL,
+  ipfs_hash VARCHAR(100) NOT NULL,
+  category VARCHAR(50) NOT NULL DEFAULT 'default',
+  description VARCHAR(500) NULL,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  createdAt DATETIME NOT NULL,
+  updatedAt DATETIME NOT NULL
+);
@@ -0,0 +1,2 @@
+-- A user holds at most one active tiered NFT; upgrades burn the current NFT before minting the next
+CREATE UNIQUE INDEX idx_usernft_one_active ON usernft (user_id) WHERE status = 'active';
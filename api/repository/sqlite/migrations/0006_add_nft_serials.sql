@@ -0,0 +1,11 @@
+-- Per-level serial numbers for tiered NFT names (AIW3-L{level}-{name}-#{serial}) and the mint transaction
+
+ALTER TABLE usernft ADD COLUMN serial_number INT NOT NULL DEFAULT 0;
+ALTER TABLE usernft ADD COLUMN transaction_id VARCHAR(128) NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX idx_usernft_level_serial ON usernft (nft_level, serial_number) WHERE serial_number > 0;
+
+CREATE TABLE sequence (
+  name VARCHAR(64) PRIMARY KEY,
+  value INT NOT NULL
+);
@@ -0,0 +1,20 @@
+-- Burn-then-mint upgrade saga: link requests to NFT rows, remember the badges to consume and
+-- keep a durable, append-only log of every completed step so failed upgrades can be resumed
+
+ALTER TABLE nftupgraderequest ADD COLUMN old_nft_id INT NOT NULL DEFAULT 0;
+ALTER TABLE nftupgraderequest ADD COLUMN new_nft_id INT NULL;
+ALTER TABLE nftupgraderequest ADD COLUMN serial_number INT NOT NULL DEFAULT 0;
+ALTER TABLE nftupgraderequest ADD COLUMN badge_ids TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE nftupgraderequest ADD COLUMN retry_count INT NOT NULL DEFAULT 0;
+
+CREATE TABLE nftupgradestep (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  upgrade_request_id INT NOT NULL,
+  step VARCHAR(32) NOT NULL,
+  detail VARCHAR(500) NOT NULL DEFAULT '',
+  createdAt DATETIME NOT NULL,
+
+  FOREIGN KEY (upgrade_request_id) REFERENCES nftupgraderequest(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_nftupgradestep_request ON nftupgradestep (upgrade_request_id);
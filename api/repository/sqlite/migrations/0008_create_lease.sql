@@ -0,0 +1,8 @@
+-- Expiring exclusive leases, e.g. the per-user lock held while an NFT claim, upgrade or award runs.
+-- expires_at is Unix milliseconds so expiry can be compared in SQL.
+
+CREATE TABLE lease (
+  name VARCHAR(128) PRIMARY KEY,
+  owner VARCHAR(64) NOT NULL,
+  expires_at INTEGER NOT NULL
+);
@@ -0,0 +1,16 @@
+-- First responses to requests sent with an Idempotency-Key, replayed to retries until expires_at.
+-- status_code is 0 while the first request is still being processed; expires_at is Unix milliseconds.
+
+CREATE TABLE idempotencykey (
+  scope VARCHAR(64) NOT NULL,
+  idempotency_key VARCHAR(255) NOT NULL,
+  request_hash VARCHAR(64) NOT NULL,
+  status_code INT NOT NULL DEFAULT 0,
+  content_type VARCHAR(128) NOT NULL DEFAULT '',
+  body BLOB,
+  created_at DATETIME NOT NULL,
+  expires_at INTEGER NOT NULL,
+  PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX idx_idempotencykey_expires_at ON idempotencykey (expires_at);
@@ -0,0 +1,4 @@
+-- Badge statuses follow the badge lifecycle vocabulary: owned, activated, consumed.
+-- Earlier versions stored earned badges as 'earned' (or 'available').
+
+UPDATE badge SET status = 'owned' WHERE status IN ('earned', 'available');
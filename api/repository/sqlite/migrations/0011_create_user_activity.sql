@@ -0,0 +1,12 @@
+-- Per-user activity counters recorded by the platform (invites, strategies, followers, ...).
+-- Task verifiers read them to measure badge task progress on the server.
+
+CREATE TABLE useractivity (
+  user_id INT NOT NULL,
+  metric VARCHAR(64) NOT NULL,
+  value INTEGER NOT NULL DEFAULT 0,
+  updatedAt DATETIME NOT NULL,
+
+  PRIMARY KEY (user_id, metric),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
@@ -0,0 +1,24 @@
+-- Anti-gaming decisions on badge task completions: allowed, rejected (with the rule and reason) or held
+-- for review. Held completions form the review queue until an admin approves or rejects them.
+-- created_at is Unix milliseconds so rate-limit and replay windows can be compared in SQL.
+
+CREATE TABLE taskguarddecision (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  task_id INT NOT NULL,
+  outcome VARCHAR(16) NOT NULL,
+  rule VARCHAR(32) NOT NULL DEFAULT '',
+  reason VARCHAR(500) NOT NULL DEFAULT '',
+  evidence_hash VARCHAR(64) NOT NULL DEFAULT '',
+  progress INT NOT NULL DEFAULT 0,
+  review_status VARCHAR(16) NOT NULL DEFAULT '',
+  reviewed_by VARCHAR(100) NOT NULL DEFAULT '',
+  review_note VARCHAR(500) NOT NULL DEFAULT '',
+  reviewed_at DATETIME NULL,
+  created_at INTEGER NOT NULL,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_taskguarddecision_user_task ON taskguarddecision (user_id, task_id, created_at);
+CREATE INDEX idx_taskguarddecision_review_status ON taskguarddecision (review_status);
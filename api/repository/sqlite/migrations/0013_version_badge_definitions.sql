@@ -0,0 +1,39 @@
+-- Admin-managed badge catalog: ordering within a level, retirement and versioned definitions.
+-- A user's badge keeps the version of the definition it was earned under.
+
+ALTER TABLE badgedefinition ADD COLUMN sort_order INT NOT NULL DEFAULT 0;
+ALTER TABLE badgedefinition ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE badgedefinition ADD COLUMN retired_at DATETIME NULL;
+
+UPDATE badgedefinition SET sort_order = id;
+
+ALTER TABLE badge ADD COLUMN definition_version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE badgedefinitionversion (
+  badge_definition_id INT NOT NULL,
+  version INT NOT NULL,
+  nft_level TINYINT NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  description VARCHAR(500) NOT NULL DEFAULT '',
+  category VARCHAR(100) NOT NULL DEFAULT '',
+  level INT NOT NULL DEFAULT 1,
+  icon_url VARCHAR(500) NOT NULL DEFAULT '',
+  task_id INT NOT NULL DEFAULT 0,
+  contribution_value REAL NOT NULL DEFAULT 0,
+  sort_order INT NOT NULL DEFAULT 0,
+  requirements TEXT NOT NULL DEFAULT '[]',
+  createdAt DATETIME NOT NULL,
+
+  PRIMARY KEY (badge_definition_id, version),
+  FOREIGN KEY (badge_definition_id) REFERENCES badgedefinition(id) ON DELETE CASCADE
+);
+
+-- Existing definitions become version 1
+INSERT INTO badgedefinitionversion (badge_definition_id, version, nft_level, name, description, category, level,
+  icon_url, task_id, contribution_value, sort_order, requirements, createdAt)
+SELECT d.id, 1, d.nft_level, d.name, d.description, d.category, d.level, d.icon_url, d.task_id,
+  d.contribution_value, d.sort_order,
+  (SELECT json_group_array(json_object('type', r.type, 'value', r.value))
+     FROM (SELECT type, value FROM badgerequirement WHERE badge_definition_id = d.id ORDER BY position) r),
+  d.updatedAt
+FROM badgedefinition d;
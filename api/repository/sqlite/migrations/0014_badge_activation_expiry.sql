@@ -0,0 +1,10 @@
+-- Badge activations expire after a window set per NFT level or per badge.
+-- expires_at is Unix milliseconds so the sweeper can compare it in SQL.
+
+ALTER TABLE badgedefinition ADD COLUMN activation_window_hours INT NULL;
+ALTER TABLE badgedefinitionversion ADD COLUMN activation_window_hours INT NULL;
+
+ALTER TABLE badge ADD COLUMN expires_at INTEGER NULL;
+ALTER TABLE badge ADD COLUMN expiry_notified_at DATETIME NULL;
+
+CREATE INDEX idx_badge_status_expires_at ON badge (status, expires_at);
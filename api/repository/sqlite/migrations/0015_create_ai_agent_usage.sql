@@ -0,0 +1,15 @@
+-- Weekly AI agent usage per user: uses consumed and refunded in each quota week, with the NFT level and
+-- quota in force at the last consume. week_start is Unix milliseconds of the reset that opened the week.
+
+CREATE TABLE aiagentusage (
+  user_id INT NOT NULL,
+  week_start INTEGER NOT NULL,
+  level INT NOT NULL DEFAULT 0,
+  quota INT NOT NULL DEFAULT 0,
+  consumed INT NOT NULL DEFAULT 0,
+  refunded INT NOT NULL DEFAULT 0,
+  updatedAt DATETIME NOT NULL,
+
+  PRIMARY KEY (user_id, week_start),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
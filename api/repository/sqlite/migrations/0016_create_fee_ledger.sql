@@ -0,0 +1,31 @@
+-- Append-only fee ledger: one row per executed trade with the fee charged after the NFT fee reduction in
+-- effect at trade time and the undiscounted fee. Rows are never updated; (platform, trade_id) keeps a
+-- trade reported twice from being counted twice. executed_at and recorded_at are Unix milliseconds.
+
+CREATE TABLE feeledger (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  platform VARCHAR(32) NOT NULL,
+  trade_id VARCHAR(128) NOT NULL,
+  platform_wallet VARCHAR(64) NOT NULL DEFAULT '',
+  notional REAL NOT NULL,
+  fee_rate REAL NOT NULL,
+  undiscounted_fee REAL NOT NULL,
+  charged_fee REAL NOT NULL,
+  fee_saved REAL NOT NULL,
+  fee_reduction INT NOT NULL DEFAULT 0,
+  nft_type VARCHAR(16) NOT NULL DEFAULT '',
+  nft_id INT NOT NULL DEFAULT 0,
+  executed_at INTEGER NOT NULL,
+  recorded_at INTEGER NOT NULL,
+
+  UNIQUE (platform, trade_id),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_feeledger_user_executed ON feeledger (user_id, executed_at);
+
+CREATE TRIGGER feeledger_append_only BEFORE UPDATE ON feeledger
+BEGIN
+  SELECT RAISE(ABORT, 'feeledger is append-only');
+END;
@@ -0,0 +1,60 @@
+-- Trading volume: raw trades as ingested through the platform adapters, their per-day and per-platform
+-- rollups, and the drift reconciliation finds between the two. user.cached_trading_volume is the rollup
+-- total. Times are Unix milliseconds; day is midnight UTC.
+
+CREATE TABLE volumetrade (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  platform VARCHAR(32) NOT NULL,
+  trade_id VARCHAR(128) NOT NULL,
+  source VARCHAR(32) NOT NULL,
+  notional REAL NOT NULL,
+  executed_at INTEGER NOT NULL,
+  ingested_at INTEGER NOT NULL,
+
+  UNIQUE (platform, trade_id),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_volumetrade_user_executed ON volumetrade (user_id, executed_at);
+
+CREATE TABLE volumerollup (
+  user_id INT NOT NULL,
+  day INTEGER NOT NULL,
+  platform VARCHAR(32) NOT NULL,
+  volume REAL NOT NULL DEFAULT 0,
+  trades INT NOT NULL DEFAULT 0,
+  updated_at INTEGER NOT NULL,
+
+  PRIMARY KEY (user_id, day, platform),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE TABLE volumedrift (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  kind VARCHAR(16) NOT NULL,
+  user_id INT NOT NULL,
+  day INTEGER NOT NULL DEFAULT 0,
+  platform VARCHAR(32) NOT NULL DEFAULT '',
+  stored_volume REAL NOT NULL,
+  raw_volume REAL NOT NULL,
+  stored_trades INT NOT NULL DEFAULT 0,
+  raw_trades INT NOT NULL DEFAULT 0,
+  detected_at INTEGER NOT NULL,
+  resolved_at INTEGER NULL,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_volumedrift_user ON volumedrift (user_id, resolved_at);
+
+-- Volume accrued before trades were ingested becomes one opening-balance trade per user, so the
+-- rollups and the cached volume can be recomputed from raw trades from now on
+INSERT INTO volumetrade (user_id, platform, trade_id, source, notional, executed_at, ingested_at)
+SELECT id, 'other', 'opening-balance-' || id, 'opening_balance', cached_trading_volume,
+  CAST(strftime('%s', createdAt) AS INTEGER) * 1000, CAST(strftime('%s', 'now') AS INTEGER) * 1000
+FROM user WHERE cached_trading_volume > 0;
+
+INSERT INTO volumerollup (user_id, day, platform, volume, trades, updated_at)
+SELECT user_id, executed_at - executed_at % 86400000, platform, SUM(notional), COUNT(*), MAX(ingested_at)
+FROM volumetrade GROUP BY user_id, executed_at - executed_at % 86400000, platform;
@@ -0,0 +1,20 @@
+-- Exchange accounts bound by users so their trades are pulled into trading volume and the fee ledger.
+-- credentials holds the API key sealed with AIW3_EXCHANGE_KEY; cursor is the adapter's position in the
+-- account's trade history. synced_at is Unix milliseconds.
+
+CREATE TABLE exchangeaccount (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  platform VARCHAR(32) NOT NULL,
+  account_id VARCHAR(128) NOT NULL,
+  credentials TEXT NOT NULL DEFAULT '',
+  cursor VARCHAR(512) NOT NULL DEFAULT '',
+  synced_at INTEGER NULL,
+  sync_error VARCHAR(500) NOT NULL DEFAULT '',
+  createdAt DATETIME NOT NULL,
+  updatedAt DATETIME NOT NULL,
+
+  UNIQUE (user_id, platform),
+  UNIQUE (platform, account_id),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
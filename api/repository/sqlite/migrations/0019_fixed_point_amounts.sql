@@ -0,0 +1,128 @@
+-- Volumes, fees and savings become fixed-point: integer counts of 10^-8 USDT (money.Amount units), so
+-- sums are exact and no longer drift. Fee rates stay REAL. SQLite cannot change a column's type, so the
+-- fee ledger and volume tables are rebuilt with their rows converted; user.cached_trading_volume, the
+-- rollup total in whole USDT, is replaced by user.trading_volume, the exact rollup total in units.
+
+CREATE TABLE feeledger_units (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  platform VARCHAR(32) NOT NULL,
+  trade_id VARCHAR(128) NOT NULL,
+  platform_wallet VARCHAR(64) NOT NULL DEFAULT '',
+  notional INTEGER NOT NULL,
+  fee_rate REAL NOT NULL,
+  undiscounted_fee INTEGER NOT NULL,
+  charged_fee INTEGER NOT NULL,
+  fee_saved INTEGER NOT NULL,
+  fee_reduction INT NOT NULL DEFAULT 0,
+  nft_type VARCHAR(16) NOT NULL DEFAULT '',
+  nft_id INT NOT NULL DEFAULT 0,
+  executed_at INTEGER NOT NULL,
+  recorded_at INTEGER NOT NULL,
+
+  UNIQUE (platform, trade_id),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+INSERT INTO feeledger_units (id, user_id, platform, trade_id, platform_wallet, notional, fee_rate,
+  undiscounted_fee, charged_fee, fee_saved, fee_reduction, nft_type, nft_id, executed_at, recorded_at)
+SELECT id, user_id, platform, trade_id, platform_wallet, CAST(ROUND(notional * 100000000) AS INTEGER), fee_rate,
+  CAST(ROUND(undiscounted_fee * 100000000) AS INTEGER), CAST(ROUND(charged_fee * 100000000) AS INTEGER),
+  CAST(ROUND(undiscounted_fee * 100000000) AS INTEGER) - CAST(ROUND(charged_fee * 100000000) AS INTEGER),
+  fee_reduction, nft_type, nft_id, executed_at, recorded_at
+FROM feeledger;
+
+DROP TABLE feeledger;
+ALTER TABLE feeledger_units RENAME TO feeledger;
+
+CREATE INDEX idx_feeledger_user_executed ON feeledger (user_id, executed_at);
+
+CREATE TRIGGER feeledger_append_only BEFORE UPDATE ON feeledger
+BEGIN
+  SELECT RAISE(ABORT, 'feeledger is append-only');
+END;
+
+CREATE TABLE volumetrade_units (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  platform VARCHAR(32) NOT NULL,
+  trade_id VARCHAR(128) NOT NULL,
+  source VARCHAR(32) NOT NULL,
+  notional INTEGER NOT NULL,
+  executed_at INTEGER NOT NULL,
+  ingested_at INTEGER NOT NULL,
+
+  UNIQUE (platform, trade_id),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+INSERT INTO volumetrade_units (id, user_id, platform, trade_id, source, notional, executed_at, ingested_at)
+SELECT id, user_id, platform, trade_id, source, CAST(ROUND(notional * 100000000) AS INTEGER), executed_at,
+  ingested_at
+FROM volumetrade;
+
+DROP TABLE volumetrade;
+ALTER TABLE volumetrade_units RENAME TO volumetrade;
+
+CREATE INDEX idx_volumetrade_user_executed ON volumetrade (user_id, executed_at);
+
+-- Rollups are summed again from the converted trades, so each rollup is exactly the sum of its trades
+CREATE TABLE volumerollup_units (
+  user_id INT NOT NULL,
+  day INTEGER NOT NULL,
+  platform VARCHAR(32) NOT NULL,
+  volume INTEGER NOT NULL DEFAULT 0,
+  trades INT NOT NULL DEFAULT 0,
+  updated_at INTEGER NOT NULL,
+
+  PRIMARY KEY (user_id, day, platform),
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+INSERT INTO volumerollup_units (user_id, day, platform, volume, trades, updated_at)
+SELECT t.user_id, t.executed_at - t.executed_at % 86400000 AS trade_day, t.platform, SUM(t.notional), COUNT(*),
+  COALESCE(MAX(r.updated_at), MAX(t.ingested_at))
+FROM volumetrade t
+LEFT JOIN volumerollup r ON r.user_id = t.user_id AND r.day = t.executed_at - t.executed_at % 86400000
+  AND r.platform = t.platform
+GROUP BY t.user_id, trade_day, t.platform;
+
+DROP TABLE volumerollup;
+ALTER TABLE volumerollup_units RENAME TO volumerollup;
+
+CREATE TABLE volumedrift_units (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  kind VARCHAR(16) NOT NULL,
+  user_id INT NOT NULL,
+  day INTEGER NOT NULL DEFAULT 0,
+  platform VARCHAR(32) NOT NULL DEFAULT '',
+  stored_volume INTEGER NOT NULL,
+  raw_volume INTEGER NOT NULL,
+  stored_trades INT NOT NULL DEFAULT 0,
+  raw_trades INT NOT NULL DEFAULT 0,
+  detected_at INTEGER NOT NULL,
+  resolved_at INTEGER NULL,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+INSERT INTO volumedrift_units (id, kind, user_id, day, platform, stored_volume, raw_volume, stored_trades,
+  raw_trades, detected_at, resolved_at)
+SELECT id, kind, user_id, day, platform, CAST(ROUND(stored_volume * 100000000) AS INTEGER),
+  CAST(ROUND(raw_volume * 100000000) AS INTEGER), stored_trades, raw_trades, detected_at, resolved_at
+FROM volumedrift;
+
+DROP TABLE volumedrift;
+ALTER TABLE volumedrift_units RENAME TO volumedrift;
+
+CREATE INDEX idx_volumedrift_user ON volumedrift (user_id, resolved_at);
+
+ALTER TABLE user ADD COLUMN trading_volume INTEGER NOT NULL DEFAULT 0;
+
+UPDATE user SET trading_volume = COALESCE((SELECT SUM(volume) FROM volumerollup
+  WHERE volumerollup.user_id = user.id), 0);
+
+DROP INDEX idx_user_cached_volume;
+ALTER TABLE user DROP COLUMN cached_trading_volume;
+
+CREATE INDEX idx_user_trading_volume ON user (trading_volume);
@@ -0,0 +1,11 @@
+-- Trades settled in SOL, USDC and other assets are converted to USDT with the asset's price at trade time.
+-- Each trade keeps its notional in the asset it settled in and the price it was converted at, so volume can
+-- be traced back to the price used. Trades recorded before were taken as USDT, USDC counting at par.
+
+ALTER TABLE volumetrade ADD COLUMN asset VARCHAR(16) NOT NULL DEFAULT 'USDT';
+ALTER TABLE volumetrade ADD COLUMN asset_notional INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE volumetrade ADD COLUMN price INTEGER NOT NULL DEFAULT 100000000;
+ALTER TABLE volumetrade ADD COLUMN price_source VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE volumetrade ADD COLUMN priced_at INTEGER NULL;
+
+UPDATE volumetrade SET asset_notional = notional;
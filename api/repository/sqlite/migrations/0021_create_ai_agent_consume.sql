@@ -0,0 +1,13 @@
+-- Each AI agent consume is recorded under an ID returned to the caller. A refund names the consume it gives
+-- back, and refundedAt marks it so the same uses are never refunded twice.
+
+CREATE TABLE aiagentconsume (
+  id VARCHAR(64) PRIMARY KEY,
+  user_id INT NOT NULL,
+  week_start INTEGER NOT NULL,
+  uses INT NOT NULL,
+  createdAt DATETIME NOT NULL,
+  refundedAt DATETIME NULL,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
@@ -0,0 +1,24 @@
+-- When NFT benefits were activated, so a trade reported late gets the fee reduction in effect when it was
+-- executed. Each activation opens an interval and a deactivation or burn closes it; activated_at and
+-- deactivated_at are Unix milliseconds. NFTs activated before intervals were kept count from their mint.
+
+CREATE TABLE nftbenefitsinterval (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INT NOT NULL,
+  nft_type VARCHAR(16) NOT NULL,
+  nft_id INT NOT NULL,
+  activated_at INTEGER NOT NULL,
+  deactivated_at INTEGER NULL,
+
+  FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_nftbenefitsinterval_user ON nftbenefitsinterval (user_id, activated_at);
+
+INSERT INTO nftbenefitsinterval (user_id, nft_type, nft_id, activated_at)
+  SELECT user_id, 'tiered', id, CAST(strftime('%s', claimed_at) AS INTEGER) * 1000
+  FROM usernft WHERE benefits_activated = 1 AND claimed_at IS NOT NULL;
+
+INSERT INTO nftbenefitsinterval (user_id, nft_type, nft_id, activated_at)
+  SELECT user_id, 'competition', id, CAST(strftime('%s', minted_at) AS INTEGER) * 1000
+  FROM competitionnft WHERE benefits_activated = 1;
@@ -0,0 +1,10 @@
+-- Sequence values assigned to an operation's key, so a retried claim keeps the serial number its first
+-- attempt took (and the chain minted under it)
+
+CREATE TABLE sequenceassignment (
+  name VARCHAR(64) NOT NULL,
+  assignment_key VARCHAR(128) NOT NULL,
+  value INT NOT NULL,
+
+  PRIMARY KEY (name, assignment_key)
+);
@@ -0,0 +1,2224 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// ==========================================
+// SQLITE STORE
+// ==========================================
+
+// Open opens (creating if needed) the SQLite database at path and migrates it to the latest schema.
+// Pass a path to a new file to start from an empty database.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer; serializing connections avoids SQLITE_BUSY under concurrent handlers
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	if err := Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewStore returns a repository.Store backed by a migrated SQLite database
+func NewStore(db *sql.DB) *repository.Store {
+	return &repository.Store{
+		Users:           &userRepository{db: db},
+		TieredNfts:      &tieredNftRepository{db: db},
+		Upgrades:        &upgradeRepository{db: db},
+		CompetitionNfts: &competitionNftRepository{db: db},
+		BenefitsHistory: &benefitsHistoryRepository{db: db},
+		Badges:          &badgeRepository{db: db},
+		Tasks:           &taskRepository{db: db},
+		Avatars:         &avatarRepository{db: db},
+		Sequences:       &sequenceRepository{db: db},
+		Activity:        &activityRepository{db: db},
+		TaskGuard:       &taskGuardRepository{db: db},
+		AiQuota:         &aiQuotaRepository{db: db},
+		FeeLedger:       &feeLedgerRepository{db: db},
+		Volume:          &volumeRepository{db: db},
+		Exchanges:       &exchangeAccountRepository{db: db},
+		Leases:          &leaseRepository{db: db},
+		Idempotency:     &idempotencyRepository{db: db},
+	}
+}
+
+// ==========================================
+// COLUMN HELPERS
+// ==========================================
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func nullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt(i *int) sql.NullInt64 {
+	if i == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*i), Valid: true}
+}
+
+func parseNullInt(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	value := int(n.Int64)
+	return &value
+}
+
+// mapError translates driver errors into repository errors
+func mapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return repository.ErrNotFound
+	case strings.Contains(err.Error(), "UNIQUE constraint failed"):
+		return repository.ErrConflict
+	case strings.Contains(err.Error(), "FOREIGN KEY constraint failed"):
+		return repository.ErrNotFound
+	default:
+		return err
+	}
+}
+
+// requireAffected returns ErrNotFound when an update or delete matched no rows
+func requireAffected(result sql.Result, err error) error {
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// ==========================================
+// USER REPOSITORY
+// ==========================================
+
+type userRepository struct {
+	db *sql.DB
+}
+
+const userColumns = `id, accessToken, twitterAccessToken, username, nickname, wallet_address, email, bio,
+	profile_photo_url, banner_url, profile_avatar_id, trading_volume, createdAt, updatedAt`
+
+func scanUser(row rowScanner) (*repository.User, error) {
+	var user repository.User
+	var wallet sql.NullString
+	var avatarID sql.NullInt64
+	var createdAt, updatedAt string
+	if err := row.Scan(&user.ID, &user.AccessToken, &user.TwitterAccessToken, &user.Username, &user.Nickname,
+		&wallet, &user.Email, &user.Bio, &user.ProfilePhotoURL, &user.BannerURL, &avatarID, &user.TradingVolume,
+		&createdAt, &updatedAt); err != nil {
+		return nil, mapError(err)
+	}
+
+	user.WalletAddr = wallet.String
+	if avatarID.Valid {
+		id := int(avatarID.Int64)
+		user.ProfileAvatarID = &id
+	}
+	var err error
+	if user.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if user.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) queryUsers(ctx context.Context, query string, args ...any) ([]repository.User, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []repository.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id int) (*repository.User, error) {
+	return scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM user WHERE id = ?`, id))
+}
+
+func (r *userRepository) GetByAccessToken(ctx context.Context, accessToken string) (*repository.User, error) {
+	if accessToken == "" {
+		return nil, repository.ErrNotFound
+	}
+	// Mirrors isAuthenticated.js: match either accessToken or twitterAccessToken
+	return scanUser(r.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM user WHERE accessToken = ? OR twitterAccessToken = ? ORDER BY id LIMIT 1`,
+		accessToken, accessToken))
+}
+
+func (r *userRepository) GetByWallet(ctx context.Context, walletAddr string) (*repository.User, error) {
+	return scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM user WHERE wallet_address = ?`, walletAddr))
+}
+
+func (r *userRepository) List(ctx context.Context) ([]repository.User, error) {
+	return r.queryUsers(ctx, `SELECT `+userColumns+` FROM user ORDER BY id`)
+}
+
+func (r *userRepository) ListByAvatar(ctx context.Context, avatarID int) ([]repository.User, error) {
+	return r.queryUsers(ctx, `SELECT `+userColumns+` FROM user WHERE profile_avatar_id = ? ORDER BY id`, avatarID)
+}
+
+func (r *userRepository) Save(ctx context.Context, user *repository.User) error {
+	args := []any{user.AccessToken, user.TwitterAccessToken, user.Username, user.Nickname,
+		nullString(user.WalletAddr), user.Email, user.Bio, user.ProfilePhotoURL, user.BannerURL,
+		nullInt(user.ProfileAvatarID), user.TradingVolume, formatTime(user.CreatedAt), formatTime(user.UpdatedAt)}
+
+	if user.ID == 0 {
+		result, err := r.db.ExecContext(ctx, `INSERT INTO user (accessToken, twitterAccessToken, username, nickname,
+			wallet_address, email, bio, profile_photo_url, banner_url, profile_avatar_id, trading_volume,
+			createdAt, updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, args...)
+		if err != nil {
+			return mapError(err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		user.ID = int(id)
+		return nil
+	}
+
+	// Upsert keeps explicit IDs (seed data, imported users) stable
+	_, err := r.db.ExecContext(ctx, `INSERT INTO user (id, accessToken, twitterAccessToken, username, nickname,
+		wallet_address, email, bio, profile_photo_url, banner_url, profile_avatar_id, trading_volume,
+		createdAt, updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET accessToken = excluded.accessToken,
+			twitterAccessToken = excluded.twitterAccessToken, username = excluded.username,
+			nickname = excluded.nickname, wallet_address = excluded.wallet_address, email = excluded.email,
+			bio = excluded.bio, profile_photo_url = excluded.profile_photo_url, banner_url = excluded.banner_url,
+			profile_avatar_id = excluded.profile_avatar_id, trading_volume = excluded.trading_volume,
+			createdAt = excluded.createdAt, updatedAt = excluded.updatedAt`,
+		append([]any{user.ID}, args...)...)
+	return mapError(err)
+}
+
+// ==========================================
+// TIERED NFT REPOSITORY
+// ==========================================
+
+type tieredNftRepository struct {
+	db *sql.DB
+}
+
+const tieredNftColumns = `id, user_id, nft_level, nft_name, status, nft_mint_address, ata_address, metadata_pda,
+	metadata_uri, image_uri, serial_number, transaction_id, benefits_activated, claimed_at, burned_at`
+
+func scanTieredNft(row rowScanner) (*repository.TieredNft, error) {
+	var nft repository.TieredNft
+	var metadataURI, imageURI, mintedAt, burnedAt sql.NullString
+	if err := row.Scan(&nft.ID, &nft.UserID, &nft.Level, &nft.Name, &nft.Status, &nft.MintAddress, &nft.ATAAddress,
+		&nft.MetadataPDA, &metadataURI, &imageURI, &nft.SerialNumber, &nft.TransactionID, &nft.BenefitsActivated,
+		&mintedAt, &burnedAt); err != nil {
+		return nil, mapError(err)
+	}
+
+	nft.MetadataURI = metadataURI.String
+	nft.ImageURI = imageURI.String
+	minted, err := parseNullTime(mintedAt)
+	if err != nil {
+		return nil, err
+	}
+	if minted != nil {
+		nft.MintedAt = *minted
+	}
+	if nft.BurnedAt, err = parseNullTime(burnedAt); err != nil {
+		return nil, err
+	}
+	return &nft, nil
+}
+
+func (r *tieredNftRepository) GetByID(ctx context.Context, id int) (*repository.TieredNft, error) {
+	return scanTieredNft(r.db.QueryRowContext(ctx, `SELECT `+tieredNftColumns+` FROM usernft WHERE id = ?`, id))
+}
+
+func (r *tieredNftRepository) ListByUser(ctx context.Context, userID int) ([]repository.TieredNft, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+tieredNftColumns+` FROM usernft WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nfts := []repository.TieredNft{}
+	for rows.Next() {
+		nft, err := scanTieredNft(rows)
+		if err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, *nft)
+	}
+	return nfts, rows.Err()
+}
+
+func (r *tieredNftRepository) Create(ctx context.Context, nft *repository.TieredNft) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := formatTime(time.Now())
+	result, err := tx.ExecContext(ctx, `INSERT INTO usernft (user_id, nft_mint_address, nft_level, nft_name,
+		ata_address, metadata_pda, metadata_uri, image_uri, serial_number, transaction_id, status,
+		benefits_activated, claimed_at, burned_at, createdAt, updatedAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nft.UserID, nft.MintAddress, nft.Level, nft.Name, nft.ATAAddress, nft.MetadataPDA, nft.MetadataURI,
+		nft.ImageURI, nft.SerialNumber, nft.TransactionID, nft.Status, nft.BenefitsActivated,
+		formatTime(nft.MintedAt), nullTime(nft.BurnedAt), now, now)
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if nft.BenefitsActivated {
+		if err := recordBenefits(ctx, tx, nft.UserID, repository.NftTypeTiered, int(id), true, nft.MintedAt); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	nft.ID = int(id)
+	return nil
+}
+
+func (r *tieredNftRepository) Update(ctx context.Context, nft *repository.TieredNft) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var activated bool
+	if err := tx.QueryRowContext(ctx, `SELECT benefits_activated FROM usernft WHERE id = ?`,
+		nft.ID).Scan(&activated); err != nil {
+		return mapError(err)
+	}
+	if err := requireAffected(tx.ExecContext(ctx, `UPDATE usernft SET user_id = ?, nft_mint_address = ?, nft_level = ?,
+		nft_name = ?, ata_address = ?, metadata_pda = ?, metadata_uri = ?, image_uri = ?, serial_number = ?,
+		transaction_id = ?, status = ?, benefits_activated = ?, claimed_at = ?, burned_at = ?, updatedAt = ?
+		WHERE id = ?`,
+		nft.UserID, nft.MintAddress, nft.Level, nft.Name, nft.ATAAddress, nft.MetadataPDA, nft.MetadataURI,
+		nft.ImageURI, nft.SerialNumber, nft.TransactionID, nft.Status, nft.BenefitsActivated,
+		formatTime(nft.MintedAt), nullTime(nft.BurnedAt), formatTime(time.Now()), nft.ID)); err != nil {
+		return err
+	}
+	if activated != nft.BenefitsActivated {
+		at := time.Now()
+		if !nft.BenefitsActivated && nft.BurnedAt != nil {
+			at = *nft.BurnedAt
+		}
+		if err := recordBenefits(ctx, tx, nft.UserID, repository.NftTypeTiered, nft.ID, nft.BenefitsActivated, at); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ==========================================
+// UPGRADE REPOSITORY
+// ==========================================
+
+type upgradeRepository struct {
+	db *sql.DB
+}
+
+const upgradeColumns = `id, user_id, from_level, to_level, old_nft_id, old_nft_mint, new_nft_id, new_nft_mint,
+	serial_number, badge_ids, burn_transaction, mint_transaction, status, retry_count, error_message,
+	createdAt, updatedAt`
+
+func scanUpgrade(row rowScanner) (*repository.UpgradeRequest, error) {
+	var req repository.UpgradeRequest
+	var newNftID sql.NullInt64
+	var newNftMint, burnTx, mintTx, errorMessage sql.NullString
+	var badgeIDs, createdAt, updatedAt string
+	if err := row.Scan(&req.ID, &req.UserID, &req.FromLevel, &req.ToLevel, &req.OldNftID, &req.OldNftMint,
+		&newNftID, &newNftMint, &req.SerialNumber, &badgeIDs, &burnTx, &mintTx, &req.Status, &req.RetryCount,
+		&errorMessage, &createdAt, &updatedAt); err != nil {
+		return nil, mapError(err)
+	}
+
+	req.NewNftID = int(newNftID.Int64)
+	req.NewNftMint = newNftMint.String
+	req.BurnTransaction = burnTx.String
+	req.MintTransaction = mintTx.String
+	req.ErrorMessage = errorMessage.String
+	if err := json.Unmarshal([]byte(badgeIDs), &req.BadgeIDs); err != nil {
+		return nil, fmt.Errorf("upgrade request %d: badge_ids: %w", req.ID, err)
+	}
+	var err error
+	if req.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if req.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *upgradeRepository) queryUpgrades(ctx context.Context, query string, args ...any) ([]repository.UpgradeRequest, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []repository.UpgradeRequest{}
+	for rows.Next() {
+		req, err := scanUpgrade(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *req)
+	}
+	return requests, rows.Err()
+}
+
+func (r *upgradeRepository) Get(ctx context.Context, id int) (*repository.UpgradeRequest, error) {
+	return scanUpgrade(r.db.QueryRowContext(ctx, `SELECT `+upgradeColumns+` FROM nftupgraderequest WHERE id = ?`, id))
+}
+
+func (r *upgradeRepository) ListByUser(ctx context.Context, userID int) ([]repository.UpgradeRequest, error) {
+	return r.queryUpgrades(ctx, `SELECT `+upgradeColumns+` FROM nftupgraderequest WHERE user_id = ? ORDER BY id`, userID)
+}
+
+func (r *upgradeRepository) ListInFlight(ctx context.Context) ([]repository.UpgradeRequest, error) {
+	return r.queryUpgrades(ctx, `SELECT `+upgradeColumns+` FROM nftupgraderequest
+		WHERE status NOT IN (?, ?) OR (status = ? AND COALESCE(burn_transaction, '') != '') ORDER BY id`,
+		repository.UpgradeStatusCompleted, repository.UpgradeStatusFailed, repository.UpgradeStatusFailed)
+}
+
+func (r *upgradeRepository) Create(ctx context.Context, req *repository.UpgradeRequest) error {
+	badgeIDs, err := json.Marshal(nonNilInts(req.BadgeIDs))
+	if err != nil {
+		return err
+	}
+	result, err := r.db.ExecContext(ctx, `INSERT INTO nftupgraderequest (user_id, from_level, to_level, old_nft_id,
+		old_nft_mint, new_nft_id, new_nft_mint, serial_number, badge_ids, burn_transaction, mint_transaction, status,
+		retry_count, error_message, createdAt, updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		req.UserID, req.FromLevel, req.ToLevel, req.OldNftID, req.OldNftMint, nullInt(nonZero(req.NewNftID)),
+		nullString(req.NewNftMint), req.SerialNumber, string(badgeIDs), nullString(req.BurnTransaction),
+		nullString(req.MintTransaction), req.Status, req.RetryCount, nullString(req.ErrorMessage),
+		formatTime(req.CreatedAt), formatTime(req.UpdatedAt))
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	req.ID = int(id)
+	return nil
+}
+
+func (r *upgradeRepository) Update(ctx context.Context, req *repository.UpgradeRequest) error {
+	badgeIDs, err := json.Marshal(nonNilInts(req.BadgeIDs))
+	if err != nil {
+		return err
+	}
+	return requireAffected(r.db.ExecContext(ctx, `UPDATE nftupgraderequest SET user_id = ?, from_level = ?,
+		to_level = ?, old_nft_id = ?, old_nft_mint = ?, new_nft_id = ?, new_nft_mint = ?, serial_number = ?,
+		badge_ids = ?, burn_transaction = ?, mint_transaction = ?, status = ?, retry_count = ?, error_message = ?,
+		createdAt = ?, updatedAt = ? WHERE id = ?`,
+		req.UserID, req.FromLevel, req.ToLevel, req.OldNftID, req.OldNftMint, nullInt(nonZero(req.NewNftID)),
+		nullString(req.NewNftMint), req.SerialNumber, string(badgeIDs), nullString(req.BurnTransaction),
+		nullString(req.MintTransaction), req.Status, req.RetryCount, nullString(req.ErrorMessage),
+		formatTime(req.CreatedAt), formatTime(req.UpdatedAt), req.ID))
+}
+
+func (r *upgradeRepository) AppendStep(ctx context.Context, step *repository.UpgradeStep) error {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO nftupgradestep (upgrade_request_id, step, detail, createdAt)
+		VALUES (?, ?, ?, ?)`, step.UpgradeRequestID, step.Step, step.Detail, formatTime(step.CreatedAt))
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	step.ID = int(id)
+	return nil
+}
+
+func (r *upgradeRepository) ListSteps(ctx context.Context, upgradeRequestID int) ([]repository.UpgradeStep, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, upgrade_request_id, step, detail, createdAt FROM nftupgradestep
+		WHERE upgrade_request_id = ? ORDER BY id`, upgradeRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	steps := []repository.UpgradeStep{}
+	for rows.Next() {
+		var step repository.UpgradeStep
+		var createdAt string
+		if err := rows.Scan(&step.ID, &step.UpgradeRequestID, &step.Step, &step.Detail, &createdAt); err != nil {
+			return nil, err
+		}
+		if step.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
+
+// nonNilInts makes an empty slice encode as [] rather than null
+func nonNilInts(values []int) []int {
+	if values == nil {
+		return []int{}
+	}
+	return values
+}
+
+// ==========================================
+// COMPETITION NFT REPOSITORY
+// ==========================================
+
+type competitionNftRepository struct {
+	db *sql.DB
+}
+
+const competitionNftColumns = `id, user_id, nft_name, image_url, nft_mint_address, ata_address, metadata_pda,
+	metadata_uri, image_uri, transaction_id, competition_id, competition_name, competition_type, rank,
+	trading_fee_reduction, benefits_activated, minted_at`
+
+func scanCompetitionNft(row rowScanner) (*repository.CompetitionNft, error) {
+	var nft repository.CompetitionNft
+	var mintedAt string
+	if err := row.Scan(&nft.ID, &nft.UserID, &nft.Name, &nft.ImageURL, &nft.MintAddress, &nft.ATAAddress,
+		&nft.MetadataPDA, &nft.MetadataURI, &nft.ImageURI, &nft.TransactionID, &nft.CompetitionID,
+		&nft.CompetitionName, &nft.CompetitionType, &nft.Rank, &nft.TradingFeeReduction, &nft.BenefitsActivated,
+		&mintedAt); err != nil {
+		return nil, mapError(err)
+	}
+
+	var err error
+	if nft.MintedAt, err = parseTime(mintedAt); err != nil {
+		return nil, err
+	}
+	return &nft, nil
+}
+
+func (r *competitionNftRepository) queryNfts(ctx context.Context, query string, args ...any) ([]repository.CompetitionNft, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nfts := []repository.CompetitionNft{}
+	for rows.Next() {
+		nft, err := scanCompetitionNft(rows)
+		if err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, *nft)
+	}
+	return nfts, rows.Err()
+}
+
+func (r *competitionNftRepository) GetByID(ctx context.Context, id int) (*repository.CompetitionNft, error) {
+	return scanCompetitionNft(r.db.QueryRowContext(ctx, `SELECT `+competitionNftColumns+` FROM competitionnft WHERE id = ?`, id))
+}
+
+func (r *competitionNftRepository) List(ctx context.Context) ([]repository.CompetitionNft, error) {
+	return r.queryNfts(ctx, `SELECT `+competitionNftColumns+` FROM competitionnft ORDER BY id`)
+}
+
+func (r *competitionNftRepository) ListByUser(ctx context.Context, userID int) ([]repository.CompetitionNft, error) {
+	return r.queryNfts(ctx, `SELECT `+competitionNftColumns+` FROM competitionnft WHERE user_id = ? ORDER BY id`, userID)
+}
+
+func (r *competitionNftRepository) Create(ctx context.Context, nft *repository.CompetitionNft) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := formatTime(time.Now())
+	result, err := tx.ExecContext(ctx, `INSERT INTO competitionnft (user_id, nft_name, image_url, nft_mint_address,
+		ata_address, metadata_pda, metadata_uri, image_uri, transaction_id, competition_id, competition_name,
+		competition_type, rank, trading_fee_reduction, benefits_activated, minted_at, createdAt, updatedAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nft.UserID, nft.Name, nft.ImageURL, nft.MintAddress, nft.ATAAddress, nft.MetadataPDA, nft.MetadataURI,
+		nft.ImageURI, nft.TransactionID, nft.CompetitionID, nft.CompetitionName, nft.CompetitionType, nft.Rank,
+		nft.TradingFeeReduction, nft.BenefitsActivated, formatTime(nft.MintedAt), now, now)
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if nft.BenefitsActivated {
+		if err := recordBenefits(ctx, tx, nft.UserID, repository.NftTypeCompetition, int(id), true, nft.MintedAt); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	nft.ID = int(id)
+	return nil
+}
+
+func (r *competitionNftRepository) Update(ctx context.Context, nft *repository.CompetitionNft) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var activated bool
+	if err := tx.QueryRowContext(ctx, `SELECT benefits_activated FROM competitionnft WHERE id = ?`,
+		nft.ID).Scan(&activated); err != nil {
+		return mapError(err)
+	}
+	if err := requireAffected(tx.ExecContext(ctx, `UPDATE competitionnft SET user_id = ?, nft_name = ?, image_url = ?,
+		nft_mint_address = ?, ata_address = ?, metadata_pda = ?, metadata_uri = ?, image_uri = ?,
+		transaction_id = ?, competition_id = ?, competition_name = ?, competition_type = ?, rank = ?,
+		trading_fee_reduction = ?, benefits_activated = ?, minted_at = ?, updatedAt = ? WHERE id = ?`,
+		nft.UserID, nft.Name, nft.ImageURL, nft.MintAddress, nft.ATAAddress, nft.MetadataPDA, nft.MetadataURI,
+		nft.ImageURI, nft.TransactionID, nft.CompetitionID, nft.CompetitionName, nft.CompetitionType, nft.Rank,
+		nft.TradingFeeReduction, nft.BenefitsActivated, formatTime(nft.MintedAt), formatTime(time.Now()), nft.ID)); err != nil {
+		return err
+	}
+	if activated != nft.BenefitsActivated {
+		if err := recordBenefits(ctx, tx, nft.UserID, repository.NftTypeCompetition, nft.ID, nft.BenefitsActivated,
+			time.Now()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ==========================================
+// BENEFITS HISTORY REPOSITORY
+// ==========================================
+
+type benefitsHistoryRepository struct {
+	db *sql.DB
+}
+
+// recordBenefits opens an interval for the NFT when its benefits were activated and closes its open one
+// when they were deactivated
+func recordBenefits(ctx context.Context, tx *sql.Tx, userID int, nftType string, nftID int, activated bool, at time.Time) error {
+	if activated {
+		_, err := tx.ExecContext(ctx, `INSERT INTO nftbenefitsinterval (user_id, nft_type, nft_id, activated_at)
+			VALUES (?, ?, ?, ?)`, userID, nftType, nftID, at.UnixMilli())
+		return mapError(err)
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE nftbenefitsinterval SET deactivated_at = ?
+		WHERE nft_type = ? AND nft_id = ? AND deactivated_at IS NULL`, at.UnixMilli(), nftType, nftID)
+	return mapError(err)
+}
+
+func (r *benefitsHistoryRepository) ListByUser(ctx context.Context, userID int) ([]repository.BenefitsInterval, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, user_id, nft_type, nft_id, activated_at, deactivated_at
+		FROM nftbenefitsinterval WHERE user_id = ? ORDER BY activated_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	intervals := []repository.BenefitsInterval{}
+	for rows.Next() {
+		var interval repository.BenefitsInterval
+		var activatedAt int64
+		var deactivatedAt sql.NullInt64
+		if err := rows.Scan(&interval.ID, &interval.UserID, &interval.NftType, &interval.NftID, &activatedAt,
+			&deactivatedAt); err != nil {
+			return nil, err
+		}
+		interval.ActivatedAt = time.UnixMilli(activatedAt).UTC()
+		if deactivatedAt.Valid {
+			at := time.UnixMilli(deactivatedAt.Int64).UTC()
+			interval.DeactivatedAt = &at
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, rows.Err()
+}
+
+// ==========================================
+// BADGE REPOSITORY
+// ==========================================
+
+type badgeRepository struct {
+	db *sql.DB
+}
+
+const badgeDefinitionColumns = `id, nft_level, name, description, category, level, icon_url, task_id, contribution_value,
+	sort_order, version, retired_at, activation_window_hours`
+
+func scanBadgeDefinition(row rowScanner) (*repository.BadgeDefinition, error) {
+	var def repository.BadgeDefinition
+	var retiredAt sql.NullString
+	var windowHours sql.NullInt64
+	if err := row.Scan(&def.ID, &def.NftLevel, &def.Name, &def.Description, &def.Category, &def.Level,
+		&def.IconURL, &def.TaskID, &def.ContributionValue, &def.SortOrder, &def.Version, &retiredAt,
+		&windowHours); err != nil {
+		return nil, mapError(err)
+	}
+
+	var err error
+	if def.RetiredAt, err = parseNullTime(retiredAt); err != nil {
+		return nil, err
+	}
+	def.ActivationWindowHours = parseNullInt(windowHours)
+	return &def, nil
+}
+
+// loadRequirements fills the requirements of the given definitions in a single query
+func (r *badgeRepository) loadRequirements(ctx context.Context, defs []repository.BadgeDefinition) error {
+	index := make(map[int]int, len(defs))
+	for i := range defs {
+		index[defs[i].ID] = i
+		defs[i].Requirements = []repository.Requirement{}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT badge_definition_id, type, value FROM badgerequirement
+		ORDER BY badge_definition_id, position`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var defID int
+		var req repository.Requirement
+		if err := rows.Scan(&defID, &req.Type, &req.Value); err != nil {
+			return err
+		}
+		if i, ok := index[defID]; ok {
+			defs[i].Requirements = append(defs[i].Requirements, req)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *badgeRepository) ListDefinitions(ctx context.Context) ([]repository.BadgeDefinition, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+badgeDefinitionColumns+` FROM badgedefinition
+		ORDER BY nft_level, sort_order, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := []repository.BadgeDefinition{}
+	for rows.Next() {
+		def, err := scanBadgeDefinition(rows)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, *def)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.loadRequirements(ctx, defs); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+func (r *badgeRepository) GetDefinition(ctx context.Context, id int) (*repository.BadgeDefinition, error) {
+	def, err := scanBadgeDefinition(r.db.QueryRowContext(ctx,
+		`SELECT `+badgeDefinitionColumns+` FROM badgedefinition WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	defs := []repository.BadgeDefinition{*def}
+	if err := r.loadRequirements(ctx, defs); err != nil {
+		return nil, err
+	}
+	return &defs[0], nil
+}
+
+func (r *badgeRepository) SaveDefinition(ctx context.Context, def *repository.BadgeDefinition) error {
+	if def.Version == 0 {
+		def.Version = 1
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := formatTime(time.Now())
+	args := []any{def.NftLevel, def.Name, def.Description, def.Category, def.Level, def.IconURL, def.TaskID,
+		def.ContributionValue, def.SortOrder, def.Version, nullTime(def.RetiredAt), nullInt(def.ActivationWindowHours),
+		now, now}
+	if def.ID == 0 {
+		result, err := tx.ExecContext(ctx, `INSERT INTO badgedefinition (nft_level, name, description, category,
+			level, icon_url, task_id, contribution_value, sort_order, version, retired_at, activation_window_hours,
+			createdAt, updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, args...)
+		if err != nil {
+			return mapError(err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		def.ID = int(id)
+	} else {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO badgedefinition (id, nft_level, name, description, category,
+			level, icon_url, task_id, contribution_value, sort_order, version, retired_at, activation_window_hours,
+			createdAt, updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET nft_level = excluded.nft_level, name = excluded.name,
+				description = excluded.description, category = excluded.category, level = excluded.level,
+				icon_url = excluded.icon_url, task_id = excluded.task_id,
+				contribution_value = excluded.contribution_value, sort_order = excluded.sort_order,
+				version = excluded.version, retired_at = excluded.retired_at,
+				activation_window_hours = excluded.activation_window_hours, updatedAt = excluded.updatedAt`,
+			append([]any{def.ID}, args...)...); err != nil {
+			return mapError(err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM badgerequirement WHERE badge_definition_id = ?`, def.ID); err != nil {
+		return err
+	}
+	for i, req := range def.Requirements {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO badgerequirement (badge_definition_id, position, type, value)
+			VALUES (?, ?, ?, ?)`, def.ID, i, req.Type, req.Value); err != nil {
+			return err
+		}
+	}
+
+	requirements, err := json.Marshal(toRequirementJSON(def.Requirements))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO badgedefinitionversion (badge_definition_id, version, nft_level,
+		name, description, category, level, icon_url, task_id, contribution_value, sort_order, requirements,
+		activation_window_hours, createdAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (badge_definition_id, version) DO UPDATE SET nft_level = excluded.nft_level,
+			name = excluded.name, description = excluded.description, category = excluded.category,
+			level = excluded.level, icon_url = excluded.icon_url, task_id = excluded.task_id,
+			contribution_value = excluded.contribution_value, sort_order = excluded.sort_order,
+			requirements = excluded.requirements, activation_window_hours = excluded.activation_window_hours`,
+		def.ID, def.Version, def.NftLevel, def.Name, def.Description, def.Category, def.Level, def.IconURL,
+		def.TaskID, def.ContributionValue, def.SortOrder, string(requirements), nullInt(def.ActivationWindowHours),
+		now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// requirementJSON is how badgedefinitionversion.requirements stores a requirement
+type requirementJSON struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+func toRequirementJSON(requirements []repository.Requirement) []requirementJSON {
+	result := make([]requirementJSON, 0, len(requirements))
+	for _, req := range requirements {
+		result = append(result, requirementJSON{Type: req.Type, Value: req.Value})
+	}
+	return result
+}
+
+const badgeDefinitionVersionColumns = `badge_definition_id, version, nft_level, name, description, category, level,
+	icon_url, task_id, contribution_value, sort_order, requirements, activation_window_hours`
+
+func scanBadgeDefinitionVersion(row rowScanner) (*repository.BadgeDefinition, error) {
+	var def repository.BadgeDefinition
+	var requirements string
+	var windowHours sql.NullInt64
+	if err := row.Scan(&def.ID, &def.Version, &def.NftLevel, &def.Name, &def.Description, &def.Category,
+		&def.Level, &def.IconURL, &def.TaskID, &def.ContributionValue, &def.SortOrder, &requirements,
+		&windowHours); err != nil {
+		return nil, mapError(err)
+	}
+	def.ActivationWindowHours = parseNullInt(windowHours)
+
+	var stored []requirementJSON
+	if err := json.Unmarshal([]byte(requirements), &stored); err != nil {
+		return nil, err
+	}
+	def.Requirements = make([]repository.Requirement, 0, len(stored))
+	for _, req := range stored {
+		def.Requirements = append(def.Requirements, repository.Requirement{Type: req.Type, Value: req.Value})
+	}
+	return &def, nil
+}
+
+func (r *badgeRepository) ListDefinitionVersions(ctx context.Context, id int) ([]repository.BadgeDefinition, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+badgeDefinitionVersionColumns+` FROM badgedefinitionversion
+		WHERE badge_definition_id = ? ORDER BY version`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := []repository.BadgeDefinition{}
+	for rows.Next() {
+		def, err := scanBadgeDefinitionVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, *def)
+	}
+	return defs, rows.Err()
+}
+
+func (r *badgeRepository) GetDefinitionVersion(ctx context.Context, id, version int) (*repository.BadgeDefinition, error) {
+	return scanBadgeDefinitionVersion(r.db.QueryRowContext(ctx, `SELECT `+badgeDefinitionVersionColumns+`
+		FROM badgedefinitionversion WHERE badge_definition_id = ? AND version = ?`, id, version))
+}
+
+const userBadgeColumns = `user_id, badge_definition_id, status, definition_version, earned_at, activated_at, consumed_at,
+	expires_at, expiry_notified_at`
+
+func scanUserBadge(row rowScanner) (*repository.UserBadge, error) {
+	var badge repository.UserBadge
+	var earnedAt, activatedAt, consumedAt, notifiedAt sql.NullString
+	var expiresAt sql.NullInt64
+	if err := row.Scan(&badge.UserID, &badge.BadgeID, &badge.Status, &badge.DefinitionVersion, &earnedAt,
+		&activatedAt, &consumedAt, &expiresAt, &notifiedAt); err != nil {
+		return nil, mapError(err)
+	}
+	if expiresAt.Valid {
+		t := time.UnixMilli(expiresAt.Int64).UTC()
+		badge.ExpiresAt = &t
+	}
+
+	var err error
+	if badge.EarnedAt, err = parseNullTime(earnedAt); err != nil {
+		return nil, err
+	}
+	if badge.ActivatedAt, err = parseNullTime(activatedAt); err != nil {
+		return nil, err
+	}
+	if badge.ConsumedAt, err = parseNullTime(consumedAt); err != nil {
+		return nil, err
+	}
+	if badge.ExpiryNotifiedAt, err = parseNullTime(notifiedAt); err != nil {
+		return nil, err
+	}
+	return &badge, nil
+}
+
+func (r *badgeRepository) queryUserBadges(ctx context.Context, query string, args ...any) ([]repository.UserBadge, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	badges := []repository.UserBadge{}
+	for rows.Next() {
+		badge, err := scanUserBadge(rows)
+		if err != nil {
+			return nil, err
+		}
+		badges = append(badges, *badge)
+	}
+	return badges, rows.Err()
+}
+
+func (r *badgeRepository) ListByUser(ctx context.Context, userID int) ([]repository.UserBadge, error) {
+	return r.queryUserBadges(ctx, `SELECT `+userBadgeColumns+` FROM badge WHERE user_id = ?
+		ORDER BY badge_definition_id`, userID)
+}
+
+func (r *badgeRepository) ListHolders(ctx context.Context, badgeID int) ([]repository.UserBadge, error) {
+	return r.queryUserBadges(ctx, `SELECT `+userBadgeColumns+` FROM badge WHERE badge_definition_id = ?
+		ORDER BY user_id`, badgeID)
+}
+
+func (r *badgeRepository) GetUserBadge(ctx context.Context, userID, badgeID int) (*repository.UserBadge, error) {
+	return scanUserBadge(r.db.QueryRowContext(ctx, `SELECT `+userBadgeColumns+` FROM badge
+		WHERE user_id = ? AND badge_definition_id = ?`, userID, badgeID))
+}
+
+func (r *badgeRepository) SaveUserBadge(ctx context.Context, badge *repository.UserBadge) error {
+	def, err := r.GetDefinition(ctx, badge.BadgeID)
+	if err != nil {
+		return err
+	}
+
+	version := badge.DefinitionVersion
+	if version == 0 {
+		version = 1
+	}
+	var expiresAt sql.NullInt64
+	if badge.ExpiresAt != nil {
+		expiresAt = sql.NullInt64{Int64: badge.ExpiresAt.UnixMilli(), Valid: true}
+	}
+	now := formatTime(time.Now())
+	_, err = r.db.ExecContext(ctx, `INSERT INTO badge (user_id, badge_definition_id, badge_name, badge_identifier,
+		status, definition_version, earned_at, activated_at, consumed_at, expires_at, expiry_notified_at,
+		createdAt, updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, badge_definition_id) DO UPDATE SET status = excluded.status,
+			earned_at = excluded.earned_at, activated_at = excluded.activated_at,
+			consumed_at = excluded.consumed_at, expires_at = excluded.expires_at,
+			expiry_notified_at = excluded.expiry_notified_at, updatedAt = excluded.updatedAt`,
+		badge.UserID, badge.BadgeID, def.Name, fmt.Sprintf("user-%d-badge-%d", badge.UserID, badge.BadgeID),
+		badge.Status, version, nullTime(badge.EarnedAt), nullTime(badge.ActivatedAt), nullTime(badge.ConsumedAt),
+		expiresAt, nullTime(badge.ExpiryNotifiedAt), now, now)
+	return mapError(err)
+}
+
+func (r *badgeRepository) ListExpiringActivations(ctx context.Context, before time.Time) ([]repository.UserBadge, error) {
+	return r.queryUserBadges(ctx, `SELECT `+userBadgeColumns+` FROM badge
+		WHERE status = 'activated' AND expires_at IS NOT NULL AND expires_at <= ?
+		ORDER BY expires_at, user_id, badge_definition_id`, before.UnixMilli())
+}
+
+// ==========================================
+// TASK REPOSITORY
+// ==========================================
+
+type taskRepository struct {
+	db *sql.DB
+}
+
+func (r *taskRepository) ListTasks(ctx context.Context) ([]repository.Task, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, type, badge_definition_id FROM task ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []repository.Task{}
+	for rows.Next() {
+		var task repository.Task
+		if err := rows.Scan(&task.ID, &task.Name, &task.Type, &task.BadgeID); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+func (r *taskRepository) GetTask(ctx context.Context, id int) (*repository.Task, error) {
+	var task repository.Task
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, type, badge_definition_id FROM task WHERE id = ?`, id).
+		Scan(&task.ID, &task.Name, &task.Type, &task.BadgeID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &task, nil
+}
+
+func (r *taskRepository) SaveTask(ctx context.Context, task *repository.Task) error {
+	now := formatTime(time.Now())
+	if task.ID == 0 {
+		result, err := r.db.ExecContext(ctx, `INSERT INTO task (name, type, badge_definition_id, createdAt, updatedAt)
+			VALUES (?, ?, ?, ?, ?)`, task.Name, task.Type, task.BadgeID, now, now)
+		if err != nil {
+			return mapError(err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		task.ID = int(id)
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO task (id, name, type, badge_definition_id, createdAt, updatedAt)
+		VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = excluded.name, type = excluded.type,
+			badge_definition_id = excluded.badge_definition_id, updatedAt = excluded.updatedAt`,
+		task.ID, task.Name, task.Type, task.BadgeID, now, now)
+	return mapError(err)
+}
+
+func scanTaskProgress(row rowScanner) (*repository.TaskProgress, error) {
+	var progress repository.TaskProgress
+	var completedAt sql.NullString
+	var updatedAt string
+	if err := row.Scan(&progress.UserID, &progress.TaskID, &progress.Progress, &progress.Completed,
+		&completedAt, &updatedAt); err != nil {
+		return nil, mapError(err)
+	}
+
+	var err error
+	if progress.CompletedAt, err = parseNullTime(completedAt); err != nil {
+		return nil, err
+	}
+	if progress.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (r *taskRepository) ListProgressByUser(ctx context.Context, userID int) ([]repository.TaskProgress, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id, task_id, progress, completed, completed_at, updatedAt
+		FROM taskprogress WHERE user_id = ? ORDER BY task_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := []repository.TaskProgress{}
+	for rows.Next() {
+		p, err := scanTaskProgress(rows)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, *p)
+	}
+	return progress, rows.Err()
+}
+
+func (r *taskRepository) GetProgress(ctx context.Context, userID, taskID int) (*repository.TaskProgress, error) {
+	return scanTaskProgress(r.db.QueryRowContext(ctx, `SELECT user_id, task_id, progress, completed, completed_at,
+		updatedAt FROM taskprogress WHERE user_id = ? AND task_id = ?`, userID, taskID))
+}
+
+func (r *taskRepository) SaveProgress(ctx context.Context, progress *repository.TaskProgress) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO taskprogress (user_id, task_id, progress, completed, completed_at,
+		updatedAt) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (user_id, task_id) DO UPDATE SET
+			progress = excluded.progress, completed = excluded.completed, completed_at = excluded.completed_at,
+			updatedAt = excluded.updatedAt`,
+		progress.UserID, progress.TaskID, progress.Progress, progress.Completed, nullTime(progress.CompletedAt),
+		formatTime(progress.UpdatedAt))
+	return mapError(err)
+}
+
+// ==========================================
+// ACTIVITY REPOSITORY
+// ==========================================
+
+type activityRepository struct {
+	db *sql.DB
+}
+
+func (r *activityRepository) ListByUser(ctx context.Context, userID int) ([]repository.Activity, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id, metric, value, updatedAt FROM useractivity
+		WHERE user_id = ? ORDER BY metric`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := []repository.Activity{}
+	for rows.Next() {
+		var counter repository.Activity
+		var updatedAt string
+		if err := rows.Scan(&counter.UserID, &counter.Metric, &counter.Value, &updatedAt); err != nil {
+			return nil, err
+		}
+		if counter.UpdatedAt, err = parseTime(updatedAt); err != nil {
+			return nil, err
+		}
+		activity = append(activity, counter)
+	}
+	return activity, rows.Err()
+}
+
+func (r *activityRepository) Get(ctx context.Context, userID int, metric string) (int, error) {
+	var value int
+	err := r.db.QueryRowContext(ctx, `SELECT value FROM useractivity WHERE user_id = ? AND metric = ?`,
+		userID, metric).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return value, mapError(err)
+}
+
+func (r *activityRepository) Add(ctx context.Context, userID int, metric string, delta int) (int, error) {
+	var value int
+	err := r.db.QueryRowContext(ctx, `INSERT INTO useractivity (user_id, metric, value, updatedAt) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, metric) DO UPDATE SET value = value + excluded.value, updatedAt = excluded.updatedAt
+		RETURNING value`, userID, metric, delta, formatTime(time.Now())).Scan(&value)
+	return value, mapError(err)
+}
+
+func (r *activityRepository) Set(ctx context.Context, userID int, metric string, value int) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO useractivity (user_id, metric, value, updatedAt) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, metric) DO UPDATE SET value = excluded.value, updatedAt = excluded.updatedAt`,
+		userID, metric, value, formatTime(time.Now()))
+	return mapError(err)
+}
+
+// ==========================================
+// TASK GUARD REPOSITORY
+// ==========================================
+
+type taskGuardRepository struct {
+	db *sql.DB
+}
+
+const guardDecisionColumns = `id, user_id, task_id, outcome, rule, reason, evidence_hash, progress, review_status,
+	reviewed_by, review_note, reviewed_at, created_at`
+
+func scanGuardDecision(row rowScanner) (*repository.GuardDecision, error) {
+	var decision repository.GuardDecision
+	var reviewedAt sql.NullString
+	var createdAt int64
+	if err := row.Scan(&decision.ID, &decision.UserID, &decision.TaskID, &decision.Outcome, &decision.Rule,
+		&decision.Reason, &decision.EvidenceHash, &decision.Progress, &decision.ReviewStatus, &decision.ReviewedBy,
+		&decision.ReviewNote, &reviewedAt, &createdAt); err != nil {
+		return nil, mapError(err)
+	}
+	var err error
+	if decision.ReviewedAt, err = parseNullTime(reviewedAt); err != nil {
+		return nil, err
+	}
+	decision.CreatedAt = time.UnixMilli(createdAt).UTC()
+	return &decision, nil
+}
+
+func (r *taskGuardRepository) Record(ctx context.Context, decision *repository.GuardDecision) error {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO taskguarddecision (user_id, task_id, outcome, rule, reason,
+		evidence_hash, progress, review_status, reviewed_by, review_note, reviewed_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		decision.UserID, decision.TaskID, decision.Outcome, decision.Rule, decision.Reason, decision.EvidenceHash,
+		decision.Progress, decision.ReviewStatus, decision.ReviewedBy, decision.ReviewNote,
+		nullTime(decision.ReviewedAt), decision.CreatedAt.UnixMilli())
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	decision.ID = int(id)
+	return nil
+}
+
+func (r *taskGuardRepository) Get(ctx context.Context, id int) (*repository.GuardDecision, error) {
+	return scanGuardDecision(r.db.QueryRowContext(ctx, `SELECT `+guardDecisionColumns+`
+		FROM taskguarddecision WHERE id = ?`, id))
+}
+
+func (r *taskGuardRepository) List(ctx context.Context, filter repository.GuardDecisionFilter) ([]repository.GuardDecision, error) {
+	where, args := guardFilterClause(filter)
+	query := `SELECT ` + guardDecisionColumns + ` FROM taskguarddecision` + where + ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decisions := []repository.GuardDecision{}
+	for rows.Next() {
+		decision, err := scanGuardDecision(rows)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, *decision)
+	}
+	return decisions, rows.Err()
+}
+
+func (r *taskGuardRepository) Count(ctx context.Context, filter repository.GuardDecisionFilter) (int, error) {
+	where, args := guardFilterClause(filter)
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM taskguarddecision`+where, args...).Scan(&count)
+	return count, mapError(err)
+}
+
+func (r *taskGuardRepository) Resolve(ctx context.Context, id int, reviewStatus, reviewedBy, note string, at time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE taskguarddecision SET review_status = ?, reviewed_by = ?,
+		review_note = ?, reviewed_at = ? WHERE id = ? AND review_status = ?`,
+		reviewStatus, reviewedBy, note, formatTime(at), id, repository.ReviewPending)
+	if err != nil {
+		return mapError(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 1 {
+		return err
+	}
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	return repository.ErrConflict
+}
+
+// guardFilterClause builds the WHERE clause of a decision filter
+func guardFilterClause(filter repository.GuardDecisionFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	add := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if filter.UserID != 0 {
+		add("user_id = ?", filter.UserID)
+	}
+	if filter.TaskID != 0 {
+		add("task_id = ?", filter.TaskID)
+	}
+	if filter.Outcome != "" {
+		add("outcome = ?", filter.Outcome)
+	}
+	if filter.ReviewStatus != "" {
+		add("review_status = ?", filter.ReviewStatus)
+	}
+	if filter.EvidenceHash != "" {
+		add("evidence_hash = ?", filter.EvidenceHash)
+	}
+	if !filter.Since.IsZero() {
+		add("created_at >= ?", filter.Since.UnixMilli())
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ==========================================
+// AI QUOTA REPOSITORY
+// ==========================================
+
+type aiQuotaRepository struct {
+	db *sql.DB
+}
+
+const aiAgentUsageColumns = `user_id, week_start, level, quota, consumed, refunded, updatedAt`
+
+func scanAiAgentUsage(row rowScanner) (*repository.AiAgentUsage, error) {
+	var usage repository.AiAgentUsage
+	var weekStart int64
+	var updatedAt string
+	if err := row.Scan(&usage.UserID, &weekStart, &usage.Level, &usage.Quota, &usage.Consumed, &usage.Refunded,
+		&updatedAt); err != nil {
+		return nil, mapError(err)
+	}
+	usage.WeekStart = time.UnixMilli(weekStart).UTC()
+	var err error
+	if usage.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func (r *aiQuotaRepository) Consume(ctx context.Context, consumeID string, userID int, weekStart time.Time, uses, level, quota int) (*repository.AiAgentUsage, bool, error) {
+	if uses > quota {
+		usage, err := r.Get(ctx, userID, weekStart)
+		return usage, false, err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	now := formatTime(time.Now())
+	usage, err := scanAiAgentUsage(tx.QueryRowContext(ctx, `INSERT INTO aiagentusage
+		(user_id, week_start, level, quota, consumed, refunded, updatedAt) VALUES (?, ?, ?, ?, ?, 0, ?)
+		ON CONFLICT (user_id, week_start) DO UPDATE SET consumed = consumed + excluded.consumed,
+			level = excluded.level, quota = excluded.quota, updatedAt = excluded.updatedAt
+		WHERE aiagentusage.consumed - aiagentusage.refunded + excluded.consumed <= excluded.quota
+		RETURNING `+aiAgentUsageColumns,
+		userID, weekStart.UnixMilli(), level, quota, uses, now))
+	if errors.Is(err, repository.ErrNotFound) {
+		// The store has a single connection; give it back before reading the usage
+		tx.Rollback()
+		usage, err := r.Get(ctx, userID, weekStart)
+		return usage, false, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO aiagentconsume (id, user_id, week_start, uses, createdAt)
+		VALUES (?, ?, ?, ?, ?)`, consumeID, userID, weekStart.UnixMilli(), uses, now); err != nil {
+		return nil, false, mapError(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return usage, true, nil
+}
+
+func (r *aiQuotaRepository) Refund(ctx context.Context, consumeID string) (*repository.AiAgentUsage, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	now := formatTime(time.Now())
+	var userID, uses int
+	var weekStart int64
+	err = tx.QueryRowContext(ctx, `UPDATE aiagentconsume SET refundedAt = ?
+		WHERE id = ? AND refundedAt IS NULL RETURNING user_id, week_start, uses`,
+		now, consumeID).Scan(&userID, &weekStart, &uses)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Refunded before, or never consumed
+		if err := tx.QueryRowContext(ctx, `SELECT user_id, week_start FROM aiagentconsume WHERE id = ?`,
+			consumeID).Scan(&userID, &weekStart); err != nil {
+			return nil, false, mapError(err)
+		}
+		tx.Rollback()
+		usage, err := r.Get(ctx, userID, time.UnixMilli(weekStart))
+		return usage, false, err
+	}
+	if err != nil {
+		return nil, false, mapError(err)
+	}
+	usage, err := scanAiAgentUsage(tx.QueryRowContext(ctx, `UPDATE aiagentusage
+		SET refunded = refunded + ?, updatedAt = ?
+		WHERE user_id = ? AND week_start = ?
+		RETURNING `+aiAgentUsageColumns,
+		uses, now, userID, weekStart))
+	if err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return usage, true, nil
+}
+
+func (r *aiQuotaRepository) Get(ctx context.Context, userID int, weekStart time.Time) (*repository.AiAgentUsage, error) {
+	usage, err := scanAiAgentUsage(r.db.QueryRowContext(ctx, `SELECT `+aiAgentUsageColumns+` FROM aiagentusage
+		WHERE user_id = ? AND week_start = ?`, userID, weekStart.UnixMilli()))
+	if errors.Is(err, repository.ErrNotFound) {
+		return &repository.AiAgentUsage{UserID: userID, WeekStart: weekStart.UTC()}, nil
+	}
+	return usage, err
+}
+
+func (r *aiQuotaRepository) ListByUser(ctx context.Context, userID, limit int) ([]repository.AiAgentUsage, error) {
+	query := `SELECT ` + aiAgentUsageColumns + ` FROM aiagentusage WHERE user_id = ? ORDER BY week_start DESC`
+	args := []any{userID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	weeks := []repository.AiAgentUsage{}
+	for rows.Next() {
+		usage, err := scanAiAgentUsage(rows)
+		if err != nil {
+			return nil, err
+		}
+		weeks = append(weeks, *usage)
+	}
+	return weeks, rows.Err()
+}
+
+// ==========================================
+// FEE LEDGER REPOSITORY
+// ==========================================
+
+type feeLedgerRepository struct {
+	db *sql.DB
+}
+
+const feeSavingColumns = `id, user_id, platform, trade_id, platform_wallet, notional, fee_rate, undiscounted_fee,
+	charged_fee, fee_saved, fee_reduction, nft_type, nft_id, executed_at, recorded_at`
+
+func scanFeeSaving(row rowScanner) (*repository.FeeSaving, error) {
+	var entry repository.FeeSaving
+	var executedAt, recordedAt int64
+	if err := row.Scan(&entry.ID, &entry.UserID, &entry.Platform, &entry.TradeID, &entry.PlatformWallet,
+		&entry.Notional, &entry.FeeRate, &entry.UndiscountedFee, &entry.ChargedFee, &entry.FeeSaved,
+		&entry.FeeReduction, &entry.NftType, &entry.NftID, &executedAt, &recordedAt); err != nil {
+		return nil, mapError(err)
+	}
+	entry.ExecutedAt = time.UnixMilli(executedAt).UTC()
+	entry.RecordedAt = time.UnixMilli(recordedAt).UTC()
+	return &entry, nil
+}
+
+func (r *feeLedgerRepository) Append(ctx context.Context, entry *repository.FeeSaving) error {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO feeledger (user_id, platform, trade_id, platform_wallet,
+		notional, fee_rate, undiscounted_fee, charged_fee, fee_saved, fee_reduction, nft_type, nft_id,
+		executed_at, recorded_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.UserID, entry.Platform, entry.TradeID, entry.PlatformWallet, entry.Notional, entry.FeeRate,
+		entry.UndiscountedFee, entry.ChargedFee, entry.FeeSaved, entry.FeeReduction, entry.NftType, entry.NftID,
+		entry.ExecutedAt.UnixMilli(), entry.RecordedAt.UnixMilli())
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = int(id)
+	return nil
+}
+
+func (r *feeLedgerRepository) ListByUser(ctx context.Context, userID, limit, offset int) ([]repository.FeeSaving, error) {
+	query := `SELECT ` + feeSavingColumns + ` FROM feeledger WHERE user_id = ? ORDER BY executed_at DESC, id DESC`
+	args := []any{userID}
+	if limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, offset)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	entries := []repository.FeeSaving{}
+	for rows.Next() {
+		entry, err := scanFeeSaving(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+func (r *feeLedgerRepository) CountByUser(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM feeledger WHERE user_id = ?`, userID).Scan(&count)
+	return count, mapError(err)
+}
+
+func (r *feeLedgerRepository) TotalsByUser(ctx context.Context, userID int) ([]repository.PlatformFeeSaved, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT platform, platform_wallet, SUM(fee_saved), COUNT(*) FROM feeledger
+		WHERE user_id = ? GROUP BY platform, platform_wallet ORDER BY platform, platform_wallet`, userID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	totals := []repository.PlatformFeeSaved{}
+	for rows.Next() {
+		var total repository.PlatformFeeSaved
+		if err := rows.Scan(&total.Platform, &total.PlatformWallet, &total.FeeSaved, &total.Trades); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	return totals, rows.Err()
+}
+
+// ==========================================
+// VOLUME REPOSITORY
+// ==========================================
+
+type volumeRepository struct {
+	db *sql.DB
+}
+
+// msPerDay truncates Unix milliseconds to their UTC day
+const msPerDay = 24 * 60 * 60 * 1000
+
+// qualifyingVolumeExpr is the users' rollup total; volumes are integer money units, so the sum is exact
+const qualifyingVolumeExpr = `(SELECT COALESCE(SUM(volume), 0) FROM volumerollup
+	WHERE volumerollup.user_id = user.id)`
+
+func (r *volumeRepository) RecordTrade(ctx context.Context, trade *repository.VolumeTrade) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	executedAt := trade.ExecutedAt.UnixMilli()
+	var pricedAt sql.NullInt64
+	if trade.PricedAt != nil {
+		pricedAt = sql.NullInt64{Int64: trade.PricedAt.UnixMilli(), Valid: true}
+	}
+	// asset_notional is in units of the asset column's currency
+	result, err := tx.ExecContext(ctx, `INSERT INTO volumetrade (user_id, platform, trade_id, source, notional,
+		executed_at, ingested_at, asset, asset_notional, price, price_source, priced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		trade.UserID, trade.Platform, trade.TradeID, trade.Source, trade.Notional, executedAt,
+		trade.IngestedAt.UnixMilli(), trade.Asset, trade.AssetNotional.Units(), trade.Price, trade.PriceSource, pricedAt)
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO volumerollup (user_id, day, platform, volume, trades, updated_at)
+		VALUES (?, ?, ?, ?, 1, ?)
+		ON CONFLICT (user_id, day, platform) DO UPDATE SET volume = volume + excluded.volume,
+			trades = trades + 1, updated_at = excluded.updated_at`,
+		trade.UserID, executedAt-executedAt%msPerDay, trade.Platform, trade.Notional,
+		trade.IngestedAt.UnixMilli()); err != nil {
+		return mapError(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	trade.ID = int(id)
+	return nil
+}
+
+const volumeTradeColumns = `id, user_id, platform, trade_id, source, notional, executed_at, ingested_at, asset,
+	asset_notional, price, price_source, priced_at`
+
+func scanVolumeTrade(row rowScanner) (*repository.VolumeTrade, error) {
+	var trade repository.VolumeTrade
+	var executedAt, ingestedAt, assetNotional int64
+	var pricedAt sql.NullInt64
+	if err := row.Scan(&trade.ID, &trade.UserID, &trade.Platform, &trade.TradeID, &trade.Source, &trade.Notional,
+		&executedAt, &ingestedAt, &trade.Asset, &assetNotional, &trade.Price, &trade.PriceSource, &pricedAt); err != nil {
+		return nil, mapError(err)
+	}
+	trade.ExecutedAt = time.UnixMilli(executedAt).UTC()
+	trade.IngestedAt = time.UnixMilli(ingestedAt).UTC()
+	trade.AssetNotional = money.Currency(trade.Asset).Units(assetNotional)
+	if pricedAt.Valid {
+		at := time.UnixMilli(pricedAt.Int64).UTC()
+		trade.PricedAt = &at
+	}
+	return &trade, nil
+}
+
+func (r *volumeRepository) ListTrades(ctx context.Context, userID, limit, offset int) ([]repository.VolumeTrade, error) {
+	query := `SELECT ` + volumeTradeColumns + ` FROM volumetrade WHERE user_id = ? ORDER BY executed_at DESC, id DESC`
+	args := []any{userID}
+	if limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, offset)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	trades := []repository.VolumeTrade{}
+	for rows.Next() {
+		trade, err := scanVolumeTrade(rows)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, *trade)
+	}
+	return trades, rows.Err()
+}
+
+func (r *volumeRepository) CountTrades(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM volumetrade WHERE user_id = ?`, userID).Scan(&count)
+	return count, mapError(err)
+}
+
+func (r *volumeRepository) RefreshQualifyingVolume(ctx context.Context, userID int) (money.Amount, error) {
+	var volume money.Amount
+	err := r.db.QueryRowContext(ctx, `UPDATE user SET trading_volume = `+qualifyingVolumeExpr+`,
+		updatedAt = ? WHERE id = ? RETURNING trading_volume`,
+		formatTime(time.Now()), userID).Scan(&volume)
+	return volume, mapError(err)
+}
+
+func (r *volumeRepository) Rebuild(ctx context.Context, userID int) (money.Amount, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM volumerollup WHERE user_id = ?`, userID); err != nil {
+		return money.Amount{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO volumerollup (user_id, day, platform, volume, trades, updated_at)
+		SELECT user_id, executed_at - executed_at % ?, platform, SUM(notional), COUNT(*), ?
+		FROM volumetrade WHERE user_id = ? GROUP BY executed_at - executed_at % ?, platform`,
+		msPerDay, now.UnixMilli(), userID, msPerDay); err != nil {
+		return money.Amount{}, mapError(err)
+	}
+	var volume money.Amount
+	if err := tx.QueryRowContext(ctx, `UPDATE user SET trading_volume = `+qualifyingVolumeExpr+`,
+		updatedAt = ? WHERE id = ? RETURNING trading_volume`,
+		formatTime(now), userID).Scan(&volume); err != nil {
+		return money.Amount{}, mapError(err)
+	}
+	return volume, tx.Commit()
+}
+
+func (r *volumeRepository) ListRollups(ctx context.Context, userID int) ([]repository.VolumeRollup, error) {
+	query := `SELECT user_id, day, platform, volume, trades, updated_at FROM volumerollup`
+	return r.queryRollups(ctx, query, userID, ``)
+}
+
+func (r *volumeRepository) SumTrades(ctx context.Context, userID int) ([]repository.VolumeRollup, error) {
+	query := fmt.Sprintf(`SELECT user_id, executed_at - executed_at %% %d AS day, platform, SUM(notional), COUNT(*),
+		0 FROM volumetrade`, msPerDay)
+	return r.queryRollups(ctx, query, userID, ` GROUP BY user_id, day, platform`)
+}
+
+// queryRollups runs a rollup-shaped query for one user, or every user when userID is 0
+func (r *volumeRepository) queryRollups(ctx context.Context, query string, userID int, groupBy string) ([]repository.VolumeRollup, error) {
+	var args []any
+	if userID != 0 {
+		query += ` WHERE user_id = ?`
+		args = append(args, userID)
+	}
+	rows, err := r.db.QueryContext(ctx, query+groupBy+` ORDER BY user_id, day, platform`, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	rollups := []repository.VolumeRollup{}
+	for rows.Next() {
+		var rollup repository.VolumeRollup
+		var day, updatedAt int64
+		if err := rows.Scan(&rollup.UserID, &day, &rollup.Platform, &rollup.Volume, &rollup.Trades,
+			&updatedAt); err != nil {
+			return nil, err
+		}
+		rollup.Day = time.UnixMilli(day).UTC()
+		if updatedAt != 0 {
+			rollup.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+		}
+		rollups = append(rollups, rollup)
+	}
+	return rollups, rows.Err()
+}
+
+const volumeDriftColumns = `id, kind, user_id, day, platform, stored_volume, raw_volume, stored_trades,
+	raw_trades, detected_at, resolved_at`
+
+func scanVolumeDrift(row rowScanner) (*repository.VolumeDrift, error) {
+	var drift repository.VolumeDrift
+	var day, detectedAt int64
+	var resolvedAt sql.NullInt64
+	if err := row.Scan(&drift.ID, &drift.Kind, &drift.UserID, &day, &drift.Platform, &drift.StoredVolume,
+		&drift.RawVolume, &drift.StoredTrades, &drift.RawTrades, &detectedAt, &resolvedAt); err != nil {
+		return nil, mapError(err)
+	}
+	if day != 0 {
+		drift.Day = time.UnixMilli(day).UTC()
+	}
+	drift.DetectedAt = time.UnixMilli(detectedAt).UTC()
+	if resolvedAt.Valid {
+		at := time.UnixMilli(resolvedAt.Int64).UTC()
+		drift.ResolvedAt = &at
+	}
+	return &drift, nil
+}
+
+func (r *volumeRepository) AddDrift(ctx context.Context, drift *repository.VolumeDrift) error {
+	var day int64
+	if !drift.Day.IsZero() {
+		day = drift.Day.UnixMilli()
+	}
+	var resolvedAt sql.NullInt64
+	if drift.ResolvedAt != nil {
+		resolvedAt = sql.NullInt64{Int64: drift.ResolvedAt.UnixMilli(), Valid: true}
+	}
+	result, err := r.db.ExecContext(ctx, `INSERT INTO volumedrift (kind, user_id, day, platform, stored_volume,
+		raw_volume, stored_trades, raw_trades, detected_at, resolved_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		drift.Kind, drift.UserID, day, drift.Platform, drift.StoredVolume, drift.RawVolume, drift.StoredTrades,
+		drift.RawTrades, drift.DetectedAt.UnixMilli(), resolvedAt)
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	drift.ID = int(id)
+	return nil
+}
+
+func (r *volumeRepository) ListDrifts(ctx context.Context, filter repository.VolumeDriftFilter) ([]repository.VolumeDrift, error) {
+	var conditions []string
+	var args []any
+	if filter.UserID != 0 {
+		conditions = append(conditions, `user_id = ?`)
+		args = append(args, filter.UserID)
+	}
+	if filter.OpenOnly {
+		conditions = append(conditions, `resolved_at IS NULL`)
+	}
+	query := `SELECT ` + volumeDriftColumns + ` FROM volumedrift`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+	query += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	drifts := []repository.VolumeDrift{}
+	for rows.Next() {
+		drift, err := scanVolumeDrift(rows)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, *drift)
+	}
+	return drifts, rows.Err()
+}
+
+func (r *volumeRepository) ResolveDrift(ctx context.Context, id int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE volumedrift SET resolved_at = ? WHERE id = ? AND resolved_at IS NULL`,
+		at.UnixMilli(), id)
+	return mapError(err)
+}
+
+// ==========================================
+// EXCHANGE ACCOUNT REPOSITORY
+// ==========================================
+
+type exchangeAccountRepository struct {
+	db *sql.DB
+}
+
+const exchangeAccountColumns = `id, user_id, platform, account_id, credentials, cursor, synced_at, sync_error,
+	createdAt, updatedAt`
+
+func scanExchangeAccount(row rowScanner) (*repository.ExchangeAccount, error) {
+	var account repository.ExchangeAccount
+	var syncedAt sql.NullInt64
+	var createdAt, updatedAt string
+	if err := row.Scan(&account.ID, &account.UserID, &account.Platform, &account.AccountID, &account.Credentials,
+		&account.Cursor, &syncedAt, &account.SyncError, &createdAt, &updatedAt); err != nil {
+		return nil, mapError(err)
+	}
+	if syncedAt.Valid {
+		at := time.UnixMilli(syncedAt.Int64).UTC()
+		account.SyncedAt = &at
+	}
+	var err error
+	if account.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if account.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *exchangeAccountRepository) Create(ctx context.Context, account *repository.ExchangeAccount) error {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO exchangeaccount (user_id, platform, account_id, credentials,
+		cursor, sync_error, createdAt, updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		account.UserID, account.Platform, account.AccountID, account.Credentials, account.Cursor, account.SyncError,
+		formatTime(account.CreatedAt), formatTime(account.UpdatedAt))
+	if err != nil {
+		return mapError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	account.ID = int(id)
+	return nil
+}
+
+func (r *exchangeAccountRepository) Get(ctx context.Context, userID int, platform string) (*repository.ExchangeAccount, error) {
+	return scanExchangeAccount(r.db.QueryRowContext(ctx, `SELECT `+exchangeAccountColumns+` FROM exchangeaccount
+		WHERE user_id = ? AND platform = ?`, userID, platform))
+}
+
+func (r *exchangeAccountRepository) ListByUser(ctx context.Context, userID int) ([]repository.ExchangeAccount, error) {
+	return r.list(ctx, ` WHERE user_id = ?`, userID)
+}
+
+func (r *exchangeAccountRepository) List(ctx context.Context) ([]repository.ExchangeAccount, error) {
+	return r.list(ctx, ``)
+}
+
+func (r *exchangeAccountRepository) list(ctx context.Context, where string, args ...any) ([]repository.ExchangeAccount, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+exchangeAccountColumns+` FROM exchangeaccount`+where+
+		` ORDER BY id`, args...)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	accounts := []repository.ExchangeAccount{}
+	for rows.Next() {
+		account, err := scanExchangeAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *exchangeAccountRepository) UpdateSync(ctx context.Context, account *repository.ExchangeAccount) error {
+	var syncedAt sql.NullInt64
+	if account.SyncedAt != nil {
+		syncedAt = sql.NullInt64{Int64: account.SyncedAt.UnixMilli(), Valid: true}
+	}
+	result, err := r.db.ExecContext(ctx, `UPDATE exchangeaccount SET cursor = ?, synced_at = ?, sync_error = ?,
+		updatedAt = ? WHERE id = ?`,
+		account.Cursor, syncedAt, account.SyncError, formatTime(account.UpdatedAt), account.ID)
+	if err != nil {
+		return mapError(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 1 {
+		return err
+	}
+	return repository.ErrNotFound
+}
+
+func (r *exchangeAccountRepository) Delete(ctx context.Context, userID int, platform string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM exchangeaccount WHERE user_id = ? AND platform = ?`,
+		userID, platform)
+	if err != nil {
+		return mapError(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 1 {
+		return err
+	}
+	return repository.ErrNotFound
+}
+
+// ==========================================
+// AVATAR REPOSITORY
+// ==========================================
+
+type avatarRepository struct {
+	db *sql.DB
+}
+
+const avatarColumns = `id, name, image_url, ipfs_hash, category, description, is_active, createdAt, updatedAt`
+
+func scanAvatar(row rowScanner) (*repository.Avatar, error) {
+	var avatar repository.Avatar
+	var description sql.NullString
+	var createdAt, updatedAt string
+	if err := row.Scan(&avatar.ID, &avatar.Name, &avatar.ImageURL, &avatar.IpfsHash, &avatar.Category,
+		&description, &avatar.IsActive, &createdAt, &updatedAt); err != nil {
+		return nil, mapError(err)
+	}
+
+	if description.Valid {
+		avatar.Description = &description.String
+	}
+	var err error
+	if avatar.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if avatar.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &avatar, nil
+}
+
+func (r *avatarRepository) List(ctx context.Context) ([]repository.Avatar, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+avatarColumns+` FROM profileavatar ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	avatars := []repository.Avatar{}
+	for rows.Next() {
+		avatar, err := scanAvatar(rows)
+		if err != nil {
+			return nil, err
+		}
+		avatars = append(avatars, *avatar)
+	}
+	return avatars, rows.Err()
+}
+
+func (r *avatarRepository) GetByID(ctx context.Context, id int) (*repository.Avatar, error) {
+	return scanAvatar(r.db.QueryRowContext(ctx, `SELECT `+avatarColumns+` FROM profileavatar WHERE id = ?`, id))
+}
+
+func (r *avatarRepository) Create(ctx context.Context, avatar *repository.Avatar) error {
+	var description sql.NullString
+	if avatar.Description != nil {
+		description = sql.NullString{String: *avatar.Description, Valid: true}
+	}
+
+	// A zero ID lets SQLite assign the next one
+	result, err := r.db.ExecContext(ctx, `INSERT INTO profileavatar (id, name, image_url, ipfs_hash, category,
+		description, is_active, createdAt, updatedAt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullInt(nonZero(avatar.ID)), avatar.Name, avatar.ImageURL, avatar.IpfsHash, avatar.Category, description,
+		avatar.IsActive, formatTime(avatar.CreatedAt), formatTime(avatar.UpdatedAt))
+	if err != nil {
+		if strings.Contains(err.Error(), "PRIMARY KEY") || strings.Contains(err.Error(), "UNIQUE") {
+			return repository.ErrConflict
+		}
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	avatar.ID = int(id)
+	return nil
+}
+
+func (r *avatarRepository) Update(ctx context.Context, avatar *repository.Avatar) error {
+	var description sql.NullString
+	if avatar.Description != nil {
+		description = sql.NullString{String: *avatar.Description, Valid: true}
+	}
+	return requireAffected(r.db.ExecContext(ctx, `UPDATE profileavatar SET name = ?, image_url = ?, ipfs_hash = ?,
+		category = ?, description = ?, is_active = ?, createdAt = ?, updatedAt = ? WHERE id = ?`,
+		avatar.Name, avatar.ImageURL, avatar.IpfsHash, avatar.Category, description, avatar.IsActive,
+		formatTime(avatar.CreatedAt), formatTime(avatar.UpdatedAt), avatar.ID))
+}
+
+func (r *avatarRepository) Delete(ctx context.Context, id int) error {
+	return requireAffected(r.db.ExecContext(ctx, `DELETE FROM profileavatar WHERE id = ?`, id))
+}
+
+// ==========================================
+// SEQUENCE REPOSITORY
+// ==========================================
+
+type sequenceRepository struct {
+	db *sql.DB
+}
+
+func (r *sequenceRepository) Next(ctx context.Context, name string) (int, error) {
+	var value int
+	err := r.db.QueryRowContext(ctx, `INSERT INTO sequence (name, value) VALUES (?, 1)
+		ON CONFLICT (name) DO UPDATE SET value = value + 1 RETURNING value`, name).Scan(&value)
+	return value, mapError(err)
+}
+
+func (r *sequenceRepository) Assign(ctx context.Context, name, key string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var value int
+	err = tx.QueryRowContext(ctx, `SELECT value FROM sequenceassignment WHERE name = ? AND assignment_key = ?`,
+		name, key).Scan(&value)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	if err := tx.QueryRowContext(ctx, `INSERT INTO sequence (name, value) VALUES (?, 1)
+		ON CONFLICT (name) DO UPDATE SET value = value + 1 RETURNING value`, name).Scan(&value); err != nil {
+		return 0, mapError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO sequenceassignment (name, assignment_key, value) VALUES (?, ?, ?)`,
+		name, key, value); err != nil {
+		return 0, mapError(err)
+	}
+	return value, tx.Commit()
+}
+
+// ==========================================
+// LEASE REPOSITORY
+// ==========================================
+
+type leaseRepository struct {
+	db *sql.DB
+}
+
+func (r *leaseRepository) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, `INSERT INTO lease (name, owner, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+		WHERE lease.owner = excluded.owner OR lease.expires_at <= ?`,
+		name, owner, now.Add(ttl).UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return false, mapError(err)
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *leaseRepository) Renew(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, `UPDATE lease SET expires_at = ? WHERE name = ? AND owner = ? AND expires_at > ?`,
+		now.Add(ttl).UnixMilli(), name, owner, now.UnixMilli())
+	if err != nil {
+		return false, mapError(err)
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *leaseRepository) Release(ctx context.Context, name, owner string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM lease WHERE name = ? AND owner = ?`, name, owner)
+	return mapError(err)
+}
+
+func (r *leaseRepository) ListActive(ctx context.Context) ([]repository.Lease, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT name, owner, expires_at FROM lease WHERE expires_at > ? ORDER BY name`,
+		time.Now().UnixMilli())
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	leases := []repository.Lease{}
+	for rows.Next() {
+		var lease repository.Lease
+		var expiresAt int64
+		if err := rows.Scan(&lease.Name, &lease.Owner, &expiresAt); err != nil {
+			return nil, err
+		}
+		lease.ExpiresAt = time.UnixMilli(expiresAt).UTC()
+		leases = append(leases, lease)
+	}
+	return leases, rows.Err()
+}
+
+// ==========================================
+// IDEMPOTENCY REPOSITORY
+// ==========================================
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *repository.IdempotencyRecord) error {
+	res, err := r.db.ExecContext(ctx, `INSERT INTO idempotencykey
+		(scope, idempotency_key, request_hash, status_code, content_type, body, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE SET request_hash = excluded.request_hash,
+			status_code = excluded.status_code, content_type = excluded.content_type, body = excluded.body,
+			created_at = excluded.created_at, expires_at = excluded.expires_at
+		WHERE idempotencykey.expires_at <= ?`,
+		record.Scope, record.Key, record.RequestHash, record.StatusCode, record.ContentType, record.Body,
+		formatTime(record.CreatedAt), record.ExpiresAt.UnixMilli(), time.Now().UnixMilli())
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrConflict
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, scope, key string) (*repository.IdempotencyRecord, error) {
+	record := repository.IdempotencyRecord{Scope: scope, Key: key}
+	var createdAt string
+	var expiresAt int64
+	err := r.db.QueryRowContext(ctx, `SELECT request_hash, status_code, content_type, body, created_at, expires_at
+		FROM idempotencykey WHERE scope = ? AND idempotency_key = ? AND expires_at > ?`,
+		scope, key, time.Now().UnixMilli()).
+		Scan(&record.RequestHash, &record.StatusCode, &record.ContentType, &record.Body, &createdAt, &expiresAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if record.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	record.ExpiresAt = time.UnixMilli(expiresAt).UTC()
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *repository.IdempotencyRecord) error {
+	return requireAffected(r.db.ExecContext(ctx, `UPDATE idempotencykey SET status_code = ?, content_type = ?, body = ?
+		WHERE scope = ? AND idempotency_key = ?`,
+		record.StatusCode, record.ContentType, record.Body, record.Scope, record.Key))
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotencykey WHERE scope = ? AND idempotency_key = ?`, scope, key)
+	return mapError(err)
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotencykey WHERE expires_at <= ?`, now.UnixMilli())
+	if err != nil {
+		return 0, mapError(err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// nonZero returns nil for a zero ID so the database assigns one
+func nonZero(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// TestRecordsOfUnknownUsersAreNotFound relies on the foreign keys Open enables; the memory store does
+// not check the user of a record, so this is not part of the shared contract
+func TestRecordsOfUnknownUsersAreNotFound(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, filepath.Join(t.TempDir(), "aiw3.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := NewStore(db)
+	now := time.Now()
+
+	account := &repository.ExchangeAccount{UserID: 404, Platform: "okx", AccountID: "OKX-1", Credentials: "sealed",
+		CreatedAt: now, UpdatedAt: now}
+	if err := store.Exchanges.Create(ctx, account); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("account of an unknown user: %v, want ErrNotFound", err)
+	}
+	trade := &repository.VolumeTrade{UserID: 404, Platform: "okx", TradeID: "T-1", Source: "webhook",
+		Notional: money.USDT.Whole(1000), ExecutedAt: now, IngestedAt: now, Asset: "USDT",
+		AssetNotional: money.USDT.Whole(1000), Price: money.USDT.Whole(1)}
+	if err := store.Volume.RecordTrade(ctx, trade); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("trade of an unknown user: %v, want ErrNotFound", err)
+	}
+}
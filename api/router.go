@@ -1,52 +1,133 @@
 package main
 
 import (
+	"net/http"
+
+	"github.com/aiw3/nft-solana-api/admin"
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/antigaming"
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/badges"
+	"github.com/aiw3/nft-solana-api/chain"
+	"github.com/aiw3/nft-solana-api/coordinator"
+	"github.com/aiw3/nft-solana-api/exchanges"
+	"github.com/aiw3/nft-solana-api/idempotency"
 	"github.com/aiw3/nft-solana-api/nfts"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tasks"
+	"github.com/aiw3/nft-solana-api/volume"
+	"github.com/swaggest/rest/nethttp"
 	"github.com/swaggest/rest/web"
+	"github.com/swaggest/usecase"
 )
 
 // ==========================================
 // API ROUTES SETUP
 // ==========================================
 
-func setupAPIRoutes(s *web.Service) {
+func setupAPIRoutes(s *web.Service, store *repository.Store, chainClient chain.Client, jobs *coordinator.Coordinator,
+	verifiers *tasks.Registry, guard *antigaming.Guard, quotas *aiquota.Service, services *auth.ServiceCredentials,
+	volumes *volume.Service, adapters *exchanges.Registry, sealer *exchanges.Sealer) {
+	// Mutating NFT and badge endpoints replay their first response to retries sent with the same Idempotency-Key
+	retrySafe := s.With(idempotency.Middleware(store.Idempotency, idempotency.DefaultTTL))
+	postIdempotent := func(pattern string, uc usecase.Interactor) {
+		retrySafe.Method(http.MethodPost, pattern, nethttp.NewHandler(uc))
+	}
+
 	// ==========================================
 	// 🎯 FRONTEND USER ENDPOINTS (NFT Related)
 	// ==========================================
 
 	// NFT Data & Management
-	s.Get("/api/user/nft-info", nfts.GetUserNftInfo()) // Complete NFT portfolio + badge summary
+	s.Get("/api/user/nft-info", nfts.GetUserNftInfo(store, quotas))                    // Complete NFT portfolio + badge summary
+	postIdempotent("/api/user/nft/claim", nfts.ClaimNft(store, chainClient, jobs))     // Claim Level 1 NFT
+	postIdempotent("/api/user/nft/upgrade", nfts.UpgradeNft(store, chainClient, jobs)) // Upgrade to higher level (resumes unfinished upgrades)
+	s.Get("/api/user/nft/can-upgrade", nfts.CanUpgradeNFT(store))                      // Check upgrade eligibility (same engine as nft-info)
+	s.Get("/api/user/nft/upgrade", nfts.GetUpgradeStatus(store))                       // Latest upgrade with its step log
+	postIdempotent("/api/user/nft/activate", nfts.ActivateNft(store, jobs))            // Activate NFT benefits (one per kind, best fee reduction applies)
 	//s.Get("/api/user/nft-avatars", nfts.GetNftAvatars())       // Available NFT avatars for profile
-	//s.Post("/api/user/nft/claim", nfts.ClaimNFT())             // Claim Level 1 NFT
-	//s.Get("/api/user/nft/can-upgrade", nfts.CanUpgradeNFT())   // Check upgrade eligibility
-	//s.Post("/api/user/nft/upgrade", nfts.UpgradeNFT())         // Upgrade to higher level
-	//s.Post("/api/user/nft/activate", nfts.ActivateTieredNFT()) // Activate NFT benefits
-	//
-	//// Badge Data & Management
-	//s.Get("/api/user/badges", badges.GetUserBadges())          // Complete badge portfolio
-	//s.Get("/api/badges/{level}", badges.GetBadgesByLevel())    // Level-specific badges
-	//s.Post("/api/user/badge/activate", badges.ActivateBadge()) // Activate earned badge
-	//
-	//// Badge Task System
-	//s.Post("/api/badge/task-complete", badges.CompleteTask())       // Complete badge task with anti-gaming
-	//s.Get("/api/badge/status", badges.GetBadgeStatus())             // Get badge status and progress
-	//s.Post("/api/badge/activate", badges.ActivateBadgeForUpgrade()) // Activate badge for NFT upgrades
-	//s.Get("/api/badge/list", badges.GetBadgeList())                 // Get all available badges
-	//
-	//// ==========================================
-	//// 👑 ADMIN ENDPOINTS
-	//// ==========================================
-	//
-	//// NFT Management
-	//s.Post("/api/admin/nft/upload-image", admin.UploadTierImage())     // Upload NFT images to IPFS
-	//s.Get("/api/admin/users/nft-status", admin.GetAllUsersNftStatus()) // User NFT status overview
-	//
-	//// Competition Management
-	//s.Post("/api/admin/competition-nfts/award", admin.AwardCompetitionNFTs()) // Award competition NFTs
-	//
-	//// Avatar Management
-	//s.Post("/api/admin/profile-avatars/upload", admin.UploadAvatar())        // Upload profile avatars
-	//s.Get("/api/admin/profile-avatars/list", admin.ListAvatars())            // List profile avatars
-	//s.Put("/api/admin/profile-avatars/{id}/update", admin.UpdateAvatar())    // Update profile avatar
-	//s.Delete("/api/admin/profile-avatars/{id}/delete", admin.DeleteAvatar()) // Delete profile avatar
+
+	// AI Agent Quota (consume is called by the AI agent service with the user's token)
+	s.Get("/api/user/ai-agent/quota", nfts.GetAiAgentQuota(store, quotas))                      // Weekly quota, used and left
+	postIdempotent("/api/user/ai-agent/quota/consume", nfts.ConsumeAiAgentQuota(store, quotas)) // Consume uses atomically
+
+	// Exchange Accounts (trades of bound accounts sync into trading volume and the fee ledger)
+	postIdempotent("/api/user/exchange-accounts", nfts.BindExchangeAccount(store, adapters, sealer)) // Bind an account with a read-only API key
+	s.Get("/api/user/exchange-accounts", nfts.ListExchangeAccounts(store))                           // Bound accounts and their sync state
+	s.Delete("/api/user/exchange-accounts/{platform}", nfts.UnbindExchangeAccount(store))            // Unbind an account and discard its credentials
+
+	// Badge Data & Management
+	s.Get("/api/user/badges", badges.GetUserBadges(store))                  // Complete badge portfolio
+	s.Get("/api/badges/{level}", badges.GetBadgesByLevel(store))            // Level-specific badges
+	postIdempotent("/api/user/badge/activate", badges.ActivateBadge(store)) // Activate earned badge
+
+	// Badge Task System
+	postIdempotent("/api/badge/task-complete", badges.CompleteTask(store, verifiers, guard)) // Complete badge task with anti-gaming
+	s.Get("/api/badge/status", badges.GetBadgeStatus(store))                                 // Get badge status and progress
+	postIdempotent("/api/badge/activate", badges.ActivateBadgeForUpgrade(store))             // Activate badge for NFT upgrades
+	s.Get("/api/badge/list", badges.GetBadgeList(store))                                     // Get all available badges
+
+	// ==========================================
+	// 🔒 INTERNAL SERVICE ENDPOINTS
+	// ==========================================
+
+	// Entitlements (service token auth; trading engine, AI agent, community and strategy services)
+	s.Get("/api/internal/entitlements", nfts.GetEntitlements(store, quotas, services)) // Batch entitlements by user ID or wallet
+
+	// Activity (community, strategy and social services report what users did for badge tasks)
+	postIdempotent("/api/internal/activity", badges.RecordActivity(store, services)) // Record activity badge tasks are verified against
+
+	// AI Agent Quota (the AI agent service gives back the uses of a consume it could not answer)
+	postIdempotent("/api/internal/ai-agent/quota/refund", nfts.RefundAiAgentQuota(store, quotas, services)) // Refund one consume once
+
+	// Fee Ledger (trading services report executed trades)
+	postIdempotent("/api/internal/trades", nfts.IngestTrades(store, services)) // Record trades and the fee saved on each
+
+	// Trading Volume (platform webhooks deliver trades through the trading services)
+	postIdempotent("/api/internal/volume/{platform}/trades", nfts.IngestVolumeWebhook(volumes, services))        // Record trades for trading volume
+	postIdempotent("/api/internal/volume/solana/transactions", nfts.IngestSolanaTransactions(volumes, services)) // Record DEX swaps from confirmed transactions
+
+	// ==========================================
+	// 👑 ADMIN ENDPOINTS
+	// ==========================================
+
+	// NFT Management
+	s.Post("/api/admin/nft/upload-image", admin.UploadTierImage())          // Upload NFT images to IPFS
+	s.Get("/api/admin/users/nft-status", admin.GetAllUsersNftStatus(store)) // User NFT status overview
+	s.Get("/api/admin/nft/jobs", admin.GetNftJobStats(jobs))                // NFT job queue depth and in-flight jobs
+
+	// AI Agent Usage
+	s.Get("/api/admin/users/{userId}/ai-agent/usage", admin.GetUserAiAgentUsage(store, quotas)) // Current quota and usage per week
+
+	// Fee Ledger
+	s.Get("/api/admin/users/{userId}/fee-ledger", admin.GetUserFeeLedger(store)) // Fee saved per platform and ledger entries
+
+	// Trading Volume
+	s.Get("/api/admin/users/{userId}/volume", admin.GetUserTradingVolume(store))                                   // Qualifying volume, daily rollups and open drifts
+	s.Get("/api/admin/users/{userId}/volume/trades", admin.ListUserVolumeTrades(store))                            // Raw trades with the prices they were converted at
+	postIdempotent("/api/admin/users/{userId}/volume/recompute", admin.RecomputeUserTradingVolume(store, volumes)) // Rebuild rollups and volume from raw trades
+	s.Get("/api/admin/volume/drifts", admin.ListVolumeDrifts(store))                                               // Reconciliation drifts
+	postIdempotent("/api/admin/volume/reconcile", admin.ReconcileVolume(volumes))                                  // Reconcile now
+
+	// Competition Management
+	postIdempotent("/api/admin/competition-nfts/award", admin.AwardCompetitionNFTs(store, jobs)) // Award competition NFTs
+
+	// Badge Catalog
+	s.Get("/api/admin/badges", admin.ListCatalogBadges(store))                       // Badge definitions with tasks, order and versions
+	postIdempotent("/api/admin/badges", admin.CreateCatalogBadge(store, verifiers))  // Create a badge and its task
+	s.Put("/api/admin/badges/{id}", admin.UpdateCatalogBadge(store, verifiers))      // Update a badge as a new version
+	postIdempotent("/api/admin/badges/{id}/retire", admin.RetireCatalogBadge(store)) // Retire a badge; holders keep it
+	postIdempotent("/api/admin/badges/reorder", admin.ReorderCatalogBadges(store))   // Reorder the badges of an NFT level
+	s.Get("/api/admin/badges/{id}/versions", admin.ListCatalogBadgeVersions(store))  // Version history and holders per version
+
+	// Badge Task Guard
+	s.Get("/api/admin/task-guard/decisions", admin.ListGuardDecisions(store))                     // Decision log; outcome=review&reviewStatus=pending is the review queue
+	postIdempotent("/api/admin/task-guard/reviews/{id}/resolve", admin.ResolveGuardReview(store)) // Approve or reject a held completion
+	s.Get("/api/admin/task-guard/rules", admin.GetGuardRules(guard))                              // Rules in force
+
+	// Avatar Management
+	s.Post("/api/admin/profile-avatars/upload", admin.UploadAvatar(store))        // Upload profile avatars
+	s.Get("/api/admin/profile-avatars/list", admin.ListAvatars(store))            // List profile avatars
+	s.Put("/api/admin/profile-avatars/{id}/update", admin.UpdateAvatar(store))    // Update profile avatar
+	s.Delete("/api/admin/profile-avatars/{id}/delete", admin.DeleteAvatar(store)) // Delete profile avatar
 }
@@ -0,0 +1,1548 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: aiw3/v1/nft.proto
+
+package aiw3v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetNftStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetNftStatusRequest) Reset() {
+	*x = GetNftStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetNftStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNftStatusRequest) ProtoMessage() {}
+
+func (x *GetNftStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNftStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetNftStatusRequest) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{0}
+}
+
+type GetNftStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status *NftStatus `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *GetNftStatusResponse) Reset() {
+	*x = GetNftStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetNftStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNftStatusResponse) ProtoMessage() {}
+
+func (x *GetNftStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNftStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetNftStatusResponse) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetNftStatusResponse) GetStatus() *NftStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+// NftStatus is the caller's NFT portfolio (GetUserNftInfoData)
+type NftStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId     int64  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	WalletAddr string `protobuf:"bytes,2,opt,name=wallet_addr,json=walletAddr,proto3" json:"wallet_addr,omitempty"`
+	// Level of the active tiered NFT, 0 without one
+	ActiveNftLevel int32 `protobuf:"varint,3,opt,name=active_nft_level,json=activeNftLevel,proto3" json:"active_nft_level,omitempty"`
+	// Target level of the next upgrade; unset at the highest level
+	NextNftLevel    *int32 `protobuf:"varint,4,opt,name=next_nft_level,json=nextNftLevel,proto3,oneof" json:"next_nft_level,omitempty"`
+	UpgradeEligible bool   `protobuf:"varint,5,opt,name=upgrade_eligible,json=upgradeEligible,proto3" json:"upgrade_eligible,omitempty"`
+	PendingUpgrade  bool   `protobuf:"varint,6,opt,name=pending_upgrade,json=pendingUpgrade,proto3" json:"pending_upgrade,omitempty"`
+	// One entry per level in the tier catalog
+	TieredNfts      []*TieredNft      `protobuf:"bytes,7,rep,name=tiered_nfts,json=tieredNfts,proto3" json:"tiered_nfts,omitempty"`
+	CompetitionNfts []*CompetitionNft `protobuf:"bytes,8,rep,name=competition_nfts,json=competitionNfts,proto3" json:"competition_nfts,omitempty"`
+}
+
+func (x *NftStatus) Reset() {
+	*x = NftStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NftStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NftStatus) ProtoMessage() {}
+
+func (x *NftStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NftStatus.ProtoReflect.Descriptor instead.
+func (*NftStatus) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NftStatus) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *NftStatus) GetWalletAddr() string {
+	if x != nil {
+		return x.WalletAddr
+	}
+	return ""
+}
+
+func (x *NftStatus) GetActiveNftLevel() int32 {
+	if x != nil {
+		return x.ActiveNftLevel
+	}
+	return 0
+}
+
+func (x *NftStatus) GetNextNftLevel() int32 {
+	if x != nil && x.NextNftLevel != nil {
+		return *x.NextNftLevel
+	}
+	return 0
+}
+
+func (x *NftStatus) GetUpgradeEligible() bool {
+	if x != nil {
+		return x.UpgradeEligible
+	}
+	return false
+}
+
+func (x *NftStatus) GetPendingUpgrade() bool {
+	if x != nil {
+		return x.PendingUpgrade
+	}
+	return false
+}
+
+func (x *NftStatus) GetTieredNfts() []*TieredNft {
+	if x != nil {
+		return x.TieredNfts
+	}
+	return nil
+}
+
+func (x *NftStatus) GetCompetitionNfts() []*CompetitionNft {
+	if x != nil {
+		return x.CompetitionNfts
+	}
+	return nil
+}
+
+// TieredNft is one level of the tier catalog as seen by the user
+type TieredNft struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Unset for levels the user never minted
+	Id    *int64 `protobuf:"varint,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	Level int32  `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	Name  string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Locked, Unlockable, Active or Burned
+	Status   string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	MintedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=minted_at,json=mintedAt,proto3" json:"minted_at,omitempty"`
+	BurnedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=burned_at,json=burnedAt,proto3" json:"burned_at,omitempty"`
+	// Unset for levels the user never minted
+	MintAddress string `protobuf:"bytes,7,opt,name=mint_address,json=mintAddress,proto3" json:"mint_address,omitempty"`
+	// Unset for levels the user never minted
+	Benefits *Benefits `protobuf:"bytes,8,opt,name=benefits,proto3" json:"benefits,omitempty"`
+}
+
+func (x *TieredNft) Reset() {
+	*x = TieredNft{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TieredNft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TieredNft) ProtoMessage() {}
+
+func (x *TieredNft) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TieredNft.ProtoReflect.Descriptor instead.
+func (*TieredNft) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TieredNft) GetId() int64 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *TieredNft) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *TieredNft) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TieredNft) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TieredNft) GetMintedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MintedAt
+	}
+	return nil
+}
+
+func (x *TieredNft) GetBurnedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.BurnedAt
+	}
+	return nil
+}
+
+func (x *TieredNft) GetMintAddress() string {
+	if x != nil {
+		return x.MintAddress
+	}
+	return ""
+}
+
+func (x *TieredNft) GetBenefits() *Benefits {
+	if x != nil {
+		return x.Benefits
+	}
+	return nil
+}
+
+// CompetitionNft is a competition NFT owned by the user
+type CompetitionNft struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	MintAddress     string                 `protobuf:"bytes,3,opt,name=mint_address,json=mintAddress,proto3" json:"mint_address,omitempty"`
+	CompetitionId   int64                  `protobuf:"varint,4,opt,name=competition_id,json=competitionId,proto3" json:"competition_id,omitempty"`
+	CompetitionName string                 `protobuf:"bytes,5,opt,name=competition_name,json=competitionName,proto3" json:"competition_name,omitempty"`
+	Rank            int32                  `protobuf:"varint,6,opt,name=rank,proto3" json:"rank,omitempty"`
+	MintedAt        *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=minted_at,json=mintedAt,proto3" json:"minted_at,omitempty"`
+	Benefits        *Benefits              `protobuf:"bytes,8,opt,name=benefits,proto3" json:"benefits,omitempty"`
+}
+
+func (x *CompetitionNft) Reset() {
+	*x = CompetitionNft{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompetitionNft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompetitionNft) ProtoMessage() {}
+
+func (x *CompetitionNft) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompetitionNft.ProtoReflect.Descriptor instead.
+func (*CompetitionNft) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CompetitionNft) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CompetitionNft) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CompetitionNft) GetMintAddress() string {
+	if x != nil {
+		return x.MintAddress
+	}
+	return ""
+}
+
+func (x *CompetitionNft) GetCompetitionId() int64 {
+	if x != nil {
+		return x.CompetitionId
+	}
+	return 0
+}
+
+func (x *CompetitionNft) GetCompetitionName() string {
+	if x != nil {
+		return x.CompetitionName
+	}
+	return ""
+}
+
+func (x *CompetitionNft) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *CompetitionNft) GetMintedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MintedAt
+	}
+	return nil
+}
+
+func (x *CompetitionNft) GetBenefits() *Benefits {
+	if x != nil {
+		return x.Benefits
+	}
+	return nil
+}
+
+// Benefits is the activation state and fee reduction of an NFT (BenefitsActivation)
+type Benefits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Activated bool `protobuf:"varint,1,opt,name=activated,proto3" json:"activated,omitempty"`
+	// Whether this NFT provides the fee reduction applied to the user's trades
+	FeeReductionInEffect         bool  `protobuf:"varint,2,opt,name=fee_reduction_in_effect,json=feeReductionInEffect,proto3" json:"fee_reduction_in_effect,omitempty"`
+	TradingFeeReduction          int32 `protobuf:"varint,3,opt,name=trading_fee_reduction,json=tradingFeeReduction,proto3" json:"trading_fee_reduction,omitempty"`
+	EffectiveTradingFeeReduction int32 `protobuf:"varint,4,opt,name=effective_trading_fee_reduction,json=effectiveTradingFeeReduction,proto3" json:"effective_trading_fee_reduction,omitempty"`
+}
+
+func (x *Benefits) Reset() {
+	*x = Benefits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Benefits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Benefits) ProtoMessage() {}
+
+func (x *Benefits) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Benefits.ProtoReflect.Descriptor instead.
+func (*Benefits) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Benefits) GetActivated() bool {
+	if x != nil {
+		return x.Activated
+	}
+	return false
+}
+
+func (x *Benefits) GetFeeReductionInEffect() bool {
+	if x != nil {
+		return x.FeeReductionInEffect
+	}
+	return false
+}
+
+func (x *Benefits) GetTradingFeeReduction() int32 {
+	if x != nil {
+		return x.TradingFeeReduction
+	}
+	return 0
+}
+
+func (x *Benefits) GetEffectiveTradingFeeReduction() int32 {
+	if x != nil {
+		return x.EffectiveTradingFeeReduction
+	}
+	return 0
+}
+
+// GetEntitlementsRequest looks up at most 100 users by ID and wallet
+type GetEntitlementsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserIds []int64  `protobuf:"varint,1,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	Wallets []string `protobuf:"bytes,2,rep,name=wallets,proto3" json:"wallets,omitempty"`
+}
+
+func (x *GetEntitlementsRequest) Reset() {
+	*x = GetEntitlementsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetEntitlementsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntitlementsRequest) ProtoMessage() {}
+
+func (x *GetEntitlementsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntitlementsRequest.ProtoReflect.Descriptor instead.
+func (*GetEntitlementsRequest) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetEntitlementsRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+func (x *GetEntitlementsRequest) GetWallets() []string {
+	if x != nil {
+		return x.Wallets
+	}
+	return nil
+}
+
+type GetEntitlementsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// One entry per user found, user IDs first, then wallets; duplicates are returned once
+	Entitlements []*UserEntitlements `protobuf:"bytes,1,rep,name=entitlements,proto3" json:"entitlements,omitempty"`
+	// Lookups matching no user, as userId:<id> or wallet:<address>
+	NotFound []string `protobuf:"bytes,2,rep,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	// How long the answer may be cached, as the HTTP Cache-Control max-age
+	MaxAgeSeconds int32 `protobuf:"varint,3,opt,name=max_age_seconds,json=maxAgeSeconds,proto3" json:"max_age_seconds,omitempty"`
+}
+
+func (x *GetEntitlementsResponse) Reset() {
+	*x = GetEntitlementsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetEntitlementsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntitlementsResponse) ProtoMessage() {}
+
+func (x *GetEntitlementsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntitlementsResponse.ProtoReflect.Descriptor instead.
+func (*GetEntitlementsResponse) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetEntitlementsResponse) GetEntitlements() []*UserEntitlements {
+	if x != nil {
+		return x.Entitlements
+	}
+	return nil
+}
+
+func (x *GetEntitlementsResponse) GetNotFound() []string {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
+func (x *GetEntitlementsResponse) GetMaxAgeSeconds() int32 {
+	if x != nil {
+		return x.MaxAgeSeconds
+	}
+	return 0
+}
+
+// UserEntitlements is what a user is entitled to right now
+type UserEntitlements struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId         int64  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	WalletAddr     string `protobuf:"bytes,2,opt,name=wallet_addr,json=walletAddr,proto3" json:"wallet_addr,omitempty"`
+	ActiveNftLevel int32  `protobuf:"varint,3,opt,name=active_nft_level,json=activeNftLevel,proto3" json:"active_nft_level,omitempty"`
+	// Effective trading fee reduction percentage, 0 when no NFT is activated
+	TradingFeeReduction int32 `protobuf:"varint,4,opt,name=trading_fee_reduction,json=tradingFeeReduction,proto3" json:"trading_fee_reduction,omitempty"`
+	// Activated NFT providing the fee reduction; unset when none is activated
+	FeeReductionSource *EntitlementSource `protobuf:"bytes,5,opt,name=fee_reduction_source,json=feeReductionSource,proto3" json:"fee_reduction_source,omitempty"`
+	// Unset when the active NFT level includes no AI agent access
+	AiAgent                *AiAgentEntitlement    `protobuf:"bytes,6,opt,name=ai_agent,json=aiAgent,proto3" json:"ai_agent,omitempty"`
+	ExclusiveBackground    bool                   `protobuf:"varint,7,opt,name=exclusive_background,json=exclusiveBackground,proto3" json:"exclusive_background,omitempty"`
+	StrategyRecommendation bool                   `protobuf:"varint,8,opt,name=strategy_recommendation,json=strategyRecommendation,proto3" json:"strategy_recommendation,omitempty"`
+	StrategyPriority       bool                   `protobuf:"varint,9,opt,name=strategy_priority,json=strategyPriority,proto3" json:"strategy_priority,omitempty"`
+	CommunityTopPin        bool                   `protobuf:"varint,10,opt,name=community_top_pin,json=communityTopPin,proto3" json:"community_top_pin,omitempty"`
+	ComputedAt             *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=computed_at,json=computedAt,proto3" json:"computed_at,omitempty"`
+}
+
+func (x *UserEntitlements) Reset() {
+	*x = UserEntitlements{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserEntitlements) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserEntitlements) ProtoMessage() {}
+
+func (x *UserEntitlements) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserEntitlements.ProtoReflect.Descriptor instead.
+func (*UserEntitlements) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UserEntitlements) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UserEntitlements) GetWalletAddr() string {
+	if x != nil {
+		return x.WalletAddr
+	}
+	return ""
+}
+
+func (x *UserEntitlements) GetActiveNftLevel() int32 {
+	if x != nil {
+		return x.ActiveNftLevel
+	}
+	return 0
+}
+
+func (x *UserEntitlements) GetTradingFeeReduction() int32 {
+	if x != nil {
+		return x.TradingFeeReduction
+	}
+	return 0
+}
+
+func (x *UserEntitlements) GetFeeReductionSource() *EntitlementSource {
+	if x != nil {
+		return x.FeeReductionSource
+	}
+	return nil
+}
+
+func (x *UserEntitlements) GetAiAgent() *AiAgentEntitlement {
+	if x != nil {
+		return x.AiAgent
+	}
+	return nil
+}
+
+func (x *UserEntitlements) GetExclusiveBackground() bool {
+	if x != nil {
+		return x.ExclusiveBackground
+	}
+	return false
+}
+
+func (x *UserEntitlements) GetStrategyRecommendation() bool {
+	if x != nil {
+		return x.StrategyRecommendation
+	}
+	return false
+}
+
+func (x *UserEntitlements) GetStrategyPriority() bool {
+	if x != nil {
+		return x.StrategyPriority
+	}
+	return false
+}
+
+func (x *UserEntitlements) GetCommunityTopPin() bool {
+	if x != nil {
+		return x.CommunityTopPin
+	}
+	return false
+}
+
+func (x *UserEntitlements) GetComputedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ComputedAt
+	}
+	return nil
+}
+
+type EntitlementSource struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// tiered or competition
+	NftType   string `protobuf:"bytes,1,opt,name=nft_type,json=nftType,proto3" json:"nft_type,omitempty"`
+	UserNftId int64  `protobuf:"varint,2,opt,name=user_nft_id,json=userNftId,proto3" json:"user_nft_id,omitempty"`
+}
+
+func (x *EntitlementSource) Reset() {
+	*x = EntitlementSource{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntitlementSource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntitlementSource) ProtoMessage() {}
+
+func (x *EntitlementSource) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntitlementSource.ProtoReflect.Descriptor instead.
+func (*EntitlementSource) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *EntitlementSource) GetNftType() string {
+	if x != nil {
+		return x.NftType
+	}
+	return ""
+}
+
+func (x *EntitlementSource) GetUserNftId() int64 {
+	if x != nil {
+		return x.UserNftId
+	}
+	return 0
+}
+
+type AiAgentEntitlement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	WeeklyTotalAvailable int32                  `protobuf:"varint,1,opt,name=weekly_total_available,json=weeklyTotalAvailable,proto3" json:"weekly_total_available,omitempty"`
+	WeeklyUsed           int32                  `protobuf:"varint,2,opt,name=weekly_used,json=weeklyUsed,proto3" json:"weekly_used,omitempty"`
+	WeeklyRemaining      int32                  `protobuf:"varint,3,opt,name=weekly_remaining,json=weeklyRemaining,proto3" json:"weekly_remaining,omitempty"`
+	ResetsAt             *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=resets_at,json=resetsAt,proto3" json:"resets_at,omitempty"`
+}
+
+func (x *AiAgentEntitlement) Reset() {
+	*x = AiAgentEntitlement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AiAgentEntitlement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AiAgentEntitlement) ProtoMessage() {}
+
+func (x *AiAgentEntitlement) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AiAgentEntitlement.ProtoReflect.Descriptor instead.
+func (*AiAgentEntitlement) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AiAgentEntitlement) GetWeeklyTotalAvailable() int32 {
+	if x != nil {
+		return x.WeeklyTotalAvailable
+	}
+	return 0
+}
+
+func (x *AiAgentEntitlement) GetWeeklyUsed() int32 {
+	if x != nil {
+		return x.WeeklyUsed
+	}
+	return 0
+}
+
+func (x *AiAgentEntitlement) GetWeeklyRemaining() int32 {
+	if x != nil {
+		return x.WeeklyRemaining
+	}
+	return 0
+}
+
+func (x *AiAgentEntitlement) GetResetsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ResetsAt
+	}
+	return nil
+}
+
+type ConsumeAiAgentQuotaRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Uses to consume, 1 when unset
+	Uses int32 `protobuf:"varint,1,opt,name=uses,proto3" json:"uses,omitempty"`
+}
+
+func (x *ConsumeAiAgentQuotaRequest) Reset() {
+	*x = ConsumeAiAgentQuotaRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConsumeAiAgentQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeAiAgentQuotaRequest) ProtoMessage() {}
+
+func (x *ConsumeAiAgentQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeAiAgentQuotaRequest.ProtoReflect.Descriptor instead.
+func (*ConsumeAiAgentQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ConsumeAiAgentQuotaRequest) GetUses() int32 {
+	if x != nil {
+		return x.Uses
+	}
+	return 0
+}
+
+type ConsumeAiAgentQuotaResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Quota left after the uses were consumed
+	Quota *AiAgentQuota `protobuf:"bytes,1,opt,name=quota,proto3" json:"quota,omitempty"`
+	// ID of the consume; the AI agent service refunds it with POST /api/internal/ai-agent/quota/refund
+	ConsumeId string `protobuf:"bytes,2,opt,name=consume_id,json=consumeId,proto3" json:"consume_id,omitempty"`
+}
+
+func (x *ConsumeAiAgentQuotaResponse) Reset() {
+	*x = ConsumeAiAgentQuotaResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConsumeAiAgentQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeAiAgentQuotaResponse) ProtoMessage() {}
+
+func (x *ConsumeAiAgentQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeAiAgentQuotaResponse.ProtoReflect.Descriptor instead.
+func (*ConsumeAiAgentQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ConsumeAiAgentQuotaResponse) GetQuota() *AiAgentQuota {
+	if x != nil {
+		return x.Quota
+	}
+	return nil
+}
+
+func (x *ConsumeAiAgentQuotaResponse) GetConsumeId() string {
+	if x != nil {
+		return x.ConsumeId
+	}
+	return ""
+}
+
+// AiAgentQuota is the caller's AI agent quota for the current week
+type AiAgentQuota struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NftLevel             int32                  `protobuf:"varint,1,opt,name=nft_level,json=nftLevel,proto3" json:"nft_level,omitempty"`
+	WeeklyTotalAvailable int32                  `protobuf:"varint,2,opt,name=weekly_total_available,json=weeklyTotalAvailable,proto3" json:"weekly_total_available,omitempty"`
+	WeeklyUsed           int32                  `protobuf:"varint,3,opt,name=weekly_used,json=weeklyUsed,proto3" json:"weekly_used,omitempty"`
+	WeeklyRemaining      int32                  `protobuf:"varint,4,opt,name=weekly_remaining,json=weeklyRemaining,proto3" json:"weekly_remaining,omitempty"`
+	WeekStart            *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=week_start,json=weekStart,proto3" json:"week_start,omitempty"`
+	ResetsAt             *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=resets_at,json=resetsAt,proto3" json:"resets_at,omitempty"`
+	// When quotas reset, e.g. "Monday 00:00 UTC"
+	ResetPolicy string `protobuf:"bytes,7,opt,name=reset_policy,json=resetPolicy,proto3" json:"reset_policy,omitempty"`
+}
+
+func (x *AiAgentQuota) Reset() {
+	*x = AiAgentQuota{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_aiw3_v1_nft_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AiAgentQuota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AiAgentQuota) ProtoMessage() {}
+
+func (x *AiAgentQuota) ProtoReflect() protoreflect.Message {
+	mi := &file_aiw3_v1_nft_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AiAgentQuota.ProtoReflect.Descriptor instead.
+func (*AiAgentQuota) Descriptor() ([]byte, []int) {
+	return file_aiw3_v1_nft_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AiAgentQuota) GetNftLevel() int32 {
+	if x != nil {
+		return x.NftLevel
+	}
+	return 0
+}
+
+func (x *AiAgentQuota) GetWeeklyTotalAvailable() int32 {
+	if x != nil {
+		return x.WeeklyTotalAvailable
+	}
+	return 0
+}
+
+func (x *AiAgentQuota) GetWeeklyUsed() int32 {
+	if x != nil {
+		return x.WeeklyUsed
+	}
+	return 0
+}
+
+func (x *AiAgentQuota) GetWeeklyRemaining() int32 {
+	if x != nil {
+		return x.WeeklyRemaining
+	}
+	return 0
+}
+
+func (x *AiAgentQuota) GetWeekStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WeekStart
+	}
+	return nil
+}
+
+func (x *AiAgentQuota) GetResetsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ResetsAt
+	}
+	return nil
+}
+
+func (x *AiAgentQuota) GetResetPolicy() string {
+	if x != nil {
+		return x.ResetPolicy
+	}
+	return ""
+}
+
+var File_aiw3_v1_nft_proto protoreflect.FileDescriptor
+
+var file_aiw3_v1_nft_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x61, 0x69, 0x77, 0x33, 0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x66, 0x74, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x07, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x15, 0x0a,
+	0x13, 0x47, 0x65, 0x74, 0x4e, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x42, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x4e, 0x66, 0x74, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61,
+	0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xfa, 0x02, 0x0a, 0x09, 0x4e, 0x66, 0x74,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x1f, 0x0a, 0x0b, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41, 0x64, 0x64, 0x72,
+	0x12, 0x28, 0x0a, 0x10, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x6e, 0x66, 0x74, 0x5f, 0x6c,
+	0x65, 0x76, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x61, 0x63, 0x74, 0x69,
+	0x76, 0x65, 0x4e, 0x66, 0x74, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x29, 0x0a, 0x0e, 0x6e, 0x65,
+	0x78, 0x74, 0x5f, 0x6e, 0x66, 0x74, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x48, 0x00, 0x52, 0x0c, 0x6e, 0x65, 0x78, 0x74, 0x4e, 0x66, 0x74, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x10, 0x75, 0x70, 0x67, 0x72, 0x61, 0x64, 0x65,
+	0x5f, 0x65, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0f, 0x75, 0x70, 0x67, 0x72, 0x61, 0x64, 0x65, 0x45, 0x6c, 0x69, 0x67, 0x69, 0x62, 0x6c, 0x65,
+	0x12, 0x27, 0x0a, 0x0f, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x75, 0x70, 0x67, 0x72,
+	0x61, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x70, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x55, 0x70, 0x67, 0x72, 0x61, 0x64, 0x65, 0x12, 0x33, 0x0a, 0x0b, 0x74, 0x69, 0x65,
+	0x72, 0x65, 0x64, 0x5f, 0x6e, 0x66, 0x74, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x65, 0x72, 0x65, 0x64, 0x4e,
+	0x66, 0x74, 0x52, 0x0a, 0x74, 0x69, 0x65, 0x72, 0x65, 0x64, 0x4e, 0x66, 0x74, 0x73, 0x12, 0x42,
+	0x0a, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x66,
+	0x74, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x66,
+	0x74, 0x52, 0x0f, 0x63, 0x6f, 0x6d, 0x70, 0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x66,
+	0x74, 0x73, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x6e, 0x66, 0x74, 0x5f,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22, 0xad, 0x02, 0x0a, 0x09, 0x54, 0x69, 0x65, 0x72, 0x65, 0x64,
+	0x4e, 0x66, 0x74, 0x12, 0x13, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x48,
+	0x00, 0x52, 0x02, 0x69, 0x64, 0x88, 0x01, 0x01, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x6d, 0x69,
+	0x6e, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6d, 0x69, 0x6e, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x12, 0x37, 0x0a, 0x09, 0x62, 0x75, 0x72, 0x6e, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x08, 0x62, 0x75, 0x72, 0x6e, 0x65, 0x64, 0x41, 0x74, 0x12, 0x21, 0x0a, 0x0c,
+	0x6d, 0x69, 0x6e, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x2d, 0x0a, 0x08, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x65, 0x6e, 0x65,
+	0x66, 0x69, 0x74, 0x73, 0x52, 0x08, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x74, 0x73, 0x42, 0x05,
+	0x0a, 0x03, 0x5f, 0x69, 0x64, 0x22, 0xa5, 0x02, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x65, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x66, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c,
+	0x6d, 0x69, 0x6e, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x70, 0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x70, 0x65, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x65, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0f, 0x63, 0x6f, 0x6d, 0x70, 0x65, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x04, 0x72, 0x61, 0x6e, 0x6b, 0x12, 0x37, 0x0a, 0x09, 0x6d, 0x69, 0x6e, 0x74, 0x65, 0x64, 0x5f,
+	0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6d, 0x69, 0x6e, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x2d,
+	0x0a, 0x08, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x65, 0x6e, 0x65, 0x66,
+	0x69, 0x74, 0x73, 0x52, 0x08, 0x62, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x74, 0x73, 0x22, 0xda, 0x01,
+	0x0a, 0x08, 0x42, 0x65, 0x6e, 0x65, 0x66, 0x69, 0x74, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x63,
+	0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61,
+	0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64, 0x12, 0x35, 0x0a, 0x17, 0x66, 0x65, 0x65, 0x5f,
+	0x72, 0x65, 0x64, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x6e, 0x5f, 0x65, 0x66, 0x66,
+	0x65, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x66, 0x65, 0x65, 0x52, 0x65,
+	0x64, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x45, 0x66, 0x66, 0x65, 0x63, 0x74, 0x12,
+	0x32, 0x0a, 0x15, 0x74, 0x72, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x72,
+	0x65, 0x64, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x13,
+	0x74, 0x72, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x46, 0x65, 0x65, 0x52, 0x65, 0x64, 0x75, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x45, 0x0a, 0x1f, 0x65, 0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65,
+	0x5f, 0x74, 0x72, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x66, 0x65, 0x65, 0x5f, 0x72, 0x65, 0x64,
+	0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x1c, 0x65, 0x66,
+	0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x54, 0x72, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x46, 0x65,
+	0x65, 0x52, 0x65, 0x64, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x4d, 0x0a, 0x16, 0x47, 0x65,
+	0x74, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x07, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x73, 0x22, 0x9d, 0x01, 0x0a, 0x17, 0x47, 0x65,
+	0x74, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x0c, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x69,
+	0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x0c, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x66, 0x6f, 0x75, 0x6e,
+	0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x46, 0x6f, 0x75, 0x6e,
+	0x64, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x41,
+	0x67, 0x65, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0xb2, 0x04, 0x0a, 0x10, 0x55, 0x73,
+	0x65, 0x72, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x17,
+	0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x61, 0x6c, 0x6c, 0x65,
+	0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x77, 0x61,
+	0x6c, 0x6c, 0x65, 0x74, 0x41, 0x64, 0x64, 0x72, 0x12, 0x28, 0x0a, 0x10, 0x61, 0x63, 0x74, 0x69,
+	0x76, 0x65, 0x5f, 0x6e, 0x66, 0x74, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x4e, 0x66, 0x74, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x12, 0x32, 0x0a, 0x15, 0x74, 0x72, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x66, 0x65,
+	0x65, 0x5f, 0x72, 0x65, 0x64, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x13, 0x74, 0x72, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x46, 0x65, 0x65, 0x52, 0x65, 0x64,
+	0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x4c, 0x0a, 0x14, 0x66, 0x65, 0x65, 0x5f, 0x72, 0x65,
+	0x64, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x45,
+	0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x52, 0x12, 0x66, 0x65, 0x65, 0x52, 0x65, 0x64, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x61, 0x69, 0x5f, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x69, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x52, 0x07, 0x61, 0x69, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x12, 0x31, 0x0a, 0x14,
+	0x65, 0x78, 0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x67, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x65, 0x78, 0x63, 0x6c,
+	0x75, 0x73, 0x69, 0x76, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12,
+	0x37, 0x0a, 0x17, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x5f, 0x72, 0x65, 0x63, 0x6f,
+	0x6d, 0x6d, 0x65, 0x6e, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x16, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x6d, 0x6d,
+	0x65, 0x6e, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2b, 0x0a, 0x11, 0x73, 0x74, 0x72, 0x61,
+	0x74, 0x65, 0x67, 0x79, 0x5f, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x10, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x50, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x2a, 0x0a, 0x11, 0x63, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69,
+	0x74, 0x79, 0x5f, 0x74, 0x6f, 0x70, 0x5f, 0x70, 0x69, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0f, 0x63, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x74, 0x79, 0x54, 0x6f, 0x70, 0x50, 0x69,
+	0x6e, 0x12, 0x3b, 0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x4e,
+	0x0a, 0x11, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x66, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x66, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1e,
+	0x0a, 0x0b, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x6e, 0x66, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x75, 0x73, 0x65, 0x72, 0x4e, 0x66, 0x74, 0x49, 0x64, 0x22, 0xcf,
+	0x01, 0x0a, 0x12, 0x41, 0x69, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x34, 0x0a, 0x16, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x5f,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x14, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x54, 0x6f, 0x74,
+	0x61, 0x6c, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x77,
+	0x65, 0x65, 0x6b, 0x6c, 0x79, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x55, 0x73, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10,
+	0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x5f, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x52, 0x65,
+	0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x65, 0x74,
+	0x73, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x74, 0x73, 0x41, 0x74,
+	0x22, 0x30, 0x0a, 0x1a, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x41, 0x69, 0x41, 0x67, 0x65,
+	0x6e, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x75, 0x73,
+	0x65, 0x73, 0x22, 0x69, 0x0a, 0x1b, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x41, 0x69, 0x41,
+	0x67, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x2b, 0x0a, 0x05, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x69, 0x41, 0x67, 0x65,
+	0x6e, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x05, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x1d,
+	0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x49, 0x64, 0x22, 0xc4, 0x02,
+	0x0a, 0x0c, 0x41, 0x69, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x1b,
+	0x0a, 0x09, 0x6e, 0x66, 0x74, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x6e, 0x66, 0x74, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x34, 0x0a, 0x16, 0x77,
+	0x65, 0x65, 0x6b, 0x6c, 0x79, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x61, 0x76, 0x61, 0x69,
+	0x6c, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x14, 0x77, 0x65, 0x65,
+	0x6b, 0x6c, 0x79, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c,
+	0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x5f, 0x75, 0x73, 0x65, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x55, 0x73,
+	0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x5f, 0x72, 0x65, 0x6d,
+	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x77, 0x65,
+	0x65, 0x6b, 0x6c, 0x79, 0x52, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x39, 0x0a,
+	0x0a, 0x77, 0x65, 0x65, 0x6b, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x77,
+	0x65, 0x65, 0x6b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x37, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x74, 0x73, 0x41,
+	0x74, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x73, 0x65, 0x74, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x74, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x32, 0x91, 0x02, 0x0a, 0x0a, 0x4e, 0x66, 0x74, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x4b, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x4e, 0x66, 0x74, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x1c, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x4e, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1d, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4e,
+	0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x54, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x73, 0x12, 0x1f, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x60, 0x0a, 0x13, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d,
+	0x65, 0x41, 0x69, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x23, 0x2e,
+	0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x41,
+	0x69, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x24, 0x2e, 0x61, 0x69, 0x77, 0x33, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e,
+	0x73, 0x75, 0x6d, 0x65, 0x41, 0x69, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x6f, 0x74, 0x61,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x32, 0x5a, 0x30, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x69, 0x77, 0x33, 0x2f, 0x6e, 0x66, 0x74, 0x2d,
+	0x73, 0x6f, 0x6c, 0x61, 0x6e, 0x61, 0x2d, 0x61, 0x70, 0x69, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x61,
+	0x69, 0x77, 0x33, 0x76, 0x31, 0x3b, 0x61, 0x69, 0x77, 0x33, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_aiw3_v1_nft_proto_rawDescOnce sync.Once
+	file_aiw3_v1_nft_proto_rawDescData = file_aiw3_v1_nft_proto_rawDesc
+)
+
+func file_aiw3_v1_nft_proto_rawDescGZIP() []byte {
+	file_aiw3_v1_nft_proto_rawDescOnce.Do(func() {
+		file_aiw3_v1_nft_proto_rawDescData = protoimpl.X.CompressGZIP(file_aiw3_v1_nft_proto_rawDescData)
+	})
+	return file_aiw3_v1_nft_proto_rawDescData
+}
+
+var file_aiw3_v1_nft_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_aiw3_v1_nft_proto_goTypes = []any{
+	(*GetNftStatusRequest)(nil),         // 0: aiw3.v1.GetNftStatusRequest
+	(*GetNftStatusResponse)(nil),        // 1: aiw3.v1.GetNftStatusResponse
+	(*NftStatus)(nil),                   // 2: aiw3.v1.NftStatus
+	(*TieredNft)(nil),                   // 3: aiw3.v1.TieredNft
+	(*CompetitionNft)(nil),              // 4: aiw3.v1.CompetitionNft
+	(*Benefits)(nil),                    // 5: aiw3.v1.Benefits
+	(*GetEntitlementsRequest)(nil),      // 6: aiw3.v1.GetEntitlementsRequest
+	(*GetEntitlementsResponse)(nil),     // 7: aiw3.v1.GetEntitlementsResponse
+	(*UserEntitlements)(nil),            // 8: aiw3.v1.UserEntitlements
+	(*EntitlementSource)(nil),           // 9: aiw3.v1.EntitlementSource
+	(*AiAgentEntitlement)(nil),          // 10: aiw3.v1.AiAgentEntitlement
+	(*ConsumeAiAgentQuotaRequest)(nil),  // 11: aiw3.v1.ConsumeAiAgentQuotaRequest
+	(*ConsumeAiAgentQuotaResponse)(nil), // 12: aiw3.v1.ConsumeAiAgentQuotaResponse
+	(*AiAgentQuota)(nil),                // 13: aiw3.v1.AiAgentQuota
+	(*timestamppb.Timestamp)(nil),       // 14: google.protobuf.Timestamp
+}
+var file_aiw3_v1_nft_proto_depIdxs = []int32{
+	2,  // 0: aiw3.v1.GetNftStatusResponse.status:type_name -> aiw3.v1.NftStatus
+	3,  // 1: aiw3.v1.NftStatus.tiered_nfts:type_name -> aiw3.v1.TieredNft
+	4,  // 2: aiw3.v1.NftStatus.competition_nfts:type_name -> aiw3.v1.CompetitionNft
+	14, // 3: aiw3.v1.TieredNft.minted_at:type_name -> google.protobuf.Timestamp
+	14, // 4: aiw3.v1.TieredNft.burned_at:type_name -> google.protobuf.Timestamp
+	5,  // 5: aiw3.v1.TieredNft.benefits:type_name -> aiw3.v1.Benefits
+	14, // 6: aiw3.v1.CompetitionNft.minted_at:type_name -> google.protobuf.Timestamp
+	5,  // 7: aiw3.v1.CompetitionNft.benefits:type_name -> aiw3.v1.Benefits
+	8,  // 8: aiw3.v1.GetEntitlementsResponse.entitlements:type_name -> aiw3.v1.UserEntitlements
+	9,  // 9: aiw3.v1.UserEntitlements.fee_reduction_source:type_name -> aiw3.v1.EntitlementSource
+	10, // 10: aiw3.v1.UserEntitlements.ai_agent:type_name -> aiw3.v1.AiAgentEntitlement
+	14, // 11: aiw3.v1.UserEntitlements.computed_at:type_name -> google.protobuf.Timestamp
+	14, // 12: aiw3.v1.AiAgentEntitlement.resets_at:type_name -> google.protobuf.Timestamp
+	13, // 13: aiw3.v1.ConsumeAiAgentQuotaResponse.quota:type_name -> aiw3.v1.AiAgentQuota
+	14, // 14: aiw3.v1.AiAgentQuota.week_start:type_name -> google.protobuf.Timestamp
+	14, // 15: aiw3.v1.AiAgentQuota.resets_at:type_name -> google.protobuf.Timestamp
+	0,  // 16: aiw3.v1.NftService.GetNftStatus:input_type -> aiw3.v1.GetNftStatusRequest
+	6,  // 17: aiw3.v1.NftService.GetEntitlements:input_type -> aiw3.v1.GetEntitlementsRequest
+	11, // 18: aiw3.v1.NftService.ConsumeAiAgentQuota:input_type -> aiw3.v1.ConsumeAiAgentQuotaRequest
+	1,  // 19: aiw3.v1.NftService.GetNftStatus:output_type -> aiw3.v1.GetNftStatusResponse
+	7,  // 20: aiw3.v1.NftService.GetEntitlements:output_type -> aiw3.v1.GetEntitlementsResponse
+	12, // 21: aiw3.v1.NftService.ConsumeAiAgentQuota:output_type -> aiw3.v1.ConsumeAiAgentQuotaResponse
+	19, // [19:22] is the sub-list for method output_type
+	16, // [16:19] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_aiw3_v1_nft_proto_init() }
+func file_aiw3_v1_nft_proto_init() {
+	if File_aiw3_v1_nft_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_aiw3_v1_nft_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*GetNftStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*GetNftStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*NftStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*TieredNft); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*CompetitionNft); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Benefits); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*GetEntitlementsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*GetEntitlementsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*UserEntitlements); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*EntitlementSource); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*AiAgentEntitlement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*ConsumeAiAgentQuotaRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*ConsumeAiAgentQuotaResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_aiw3_v1_nft_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*AiAgentQuota); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_aiw3_v1_nft_proto_msgTypes[2].OneofWrappers = []any{}
+	file_aiw3_v1_nft_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_aiw3_v1_nft_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_aiw3_v1_nft_proto_goTypes,
+		DependencyIndexes: file_aiw3_v1_nft_proto_depIdxs,
+		MessageInfos:      file_aiw3_v1_nft_proto_msgTypes,
+	}.Build()
+	File_aiw3_v1_nft_proto = out.File
+	file_aiw3_v1_nft_proto_rawDesc = nil
+	file_aiw3_v1_nft_proto_goTypes = nil
+	file_aiw3_v1_nft_proto_depIdxs = nil
+}
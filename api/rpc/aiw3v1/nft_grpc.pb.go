@@ -0,0 +1,202 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: aiw3/v1/nft.proto
+
+package aiw3v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	NftService_GetNftStatus_FullMethodName        = "/aiw3.v1.NftService/GetNftStatus"
+	NftService_GetEntitlements_FullMethodName     = "/aiw3.v1.NftService/GetEntitlements"
+	NftService_ConsumeAiAgentQuota_FullMethodName = "/aiw3.v1.NftService/ConsumeAiAgentQuota"
+)
+
+// NftServiceClient is the client API for NftService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NftService mirrors the NFT status, entitlements and AI agent quota endpoints of the HTTP API for internal
+// Go services. Calls carry the same bearer tokens as HTTP in the "authorization" metadata: GetNftStatus and
+// ConsumeAiAgentQuota take the user's token, GetEntitlements a service token.
+type NftServiceClient interface {
+	// GetNftStatus returns the caller's tiered and competition NFTs with upgrade state (GET /api/user/nft-info)
+	GetNftStatus(ctx context.Context, in *GetNftStatusRequest, opts ...grpc.CallOption) (*GetNftStatusResponse, error)
+	// GetEntitlements returns what users are entitled to right now (GET /api/internal/entitlements)
+	GetEntitlements(ctx context.Context, in *GetEntitlementsRequest, opts ...grpc.CallOption) (*GetEntitlementsResponse, error)
+	// ConsumeAiAgentQuota consumes AI agent uses from the caller's weekly quota
+	// (POST /api/user/ai-agent/quota/consume); an "idempotency-key" metadata entry makes retries safe
+	ConsumeAiAgentQuota(ctx context.Context, in *ConsumeAiAgentQuotaRequest, opts ...grpc.CallOption) (*ConsumeAiAgentQuotaResponse, error)
+}
+
+type nftServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNftServiceClient(cc grpc.ClientConnInterface) NftServiceClient {
+	return &nftServiceClient{cc}
+}
+
+func (c *nftServiceClient) GetNftStatus(ctx context.Context, in *GetNftStatusRequest, opts ...grpc.CallOption) (*GetNftStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNftStatusResponse)
+	err := c.cc.Invoke(ctx, NftService_GetNftStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nftServiceClient) GetEntitlements(ctx context.Context, in *GetEntitlementsRequest, opts ...grpc.CallOption) (*GetEntitlementsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetEntitlementsResponse)
+	err := c.cc.Invoke(ctx, NftService_GetEntitlements_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nftServiceClient) ConsumeAiAgentQuota(ctx context.Context, in *ConsumeAiAgentQuotaRequest, opts ...grpc.CallOption) (*ConsumeAiAgentQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConsumeAiAgentQuotaResponse)
+	err := c.cc.Invoke(ctx, NftService_ConsumeAiAgentQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NftServiceServer is the server API for NftService service.
+// All implementations must embed UnimplementedNftServiceServer
+// for forward compatibility
+//
+// NftService mirrors the NFT status, entitlements and AI agent quota endpoints of the HTTP API for internal
+// Go services. Calls carry the same bearer tokens as HTTP in the "authorization" metadata: GetNftStatus and
+// ConsumeAiAgentQuota take the user's token, GetEntitlements a service token.
+type NftServiceServer interface {
+	// GetNftStatus returns the caller's tiered and competition NFTs with upgrade state (GET /api/user/nft-info)
+	GetNftStatus(context.Context, *GetNftStatusRequest) (*GetNftStatusResponse, error)
+	// GetEntitlements returns what users are entitled to right now (GET /api/internal/entitlements)
+	GetEntitlements(context.Context, *GetEntitlementsRequest) (*GetEntitlementsResponse, error)
+	// ConsumeAiAgentQuota consumes AI agent uses from the caller's weekly quota
+	// (POST /api/user/ai-agent/quota/consume); an "idempotency-key" metadata entry makes retries safe
+	ConsumeAiAgentQuota(context.Context, *ConsumeAiAgentQuotaRequest) (*ConsumeAiAgentQuotaResponse, error)
+	mustEmbedUnimplementedNftServiceServer()
+}
+
+// UnimplementedNftServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedNftServiceServer struct {
+}
+
+func (UnimplementedNftServiceServer) GetNftStatus(context.Context, *GetNftStatusRequest) (*GetNftStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNftStatus not implemented")
+}
+func (UnimplementedNftServiceServer) GetEntitlements(context.Context, *GetEntitlementsRequest) (*GetEntitlementsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEntitlements not implemented")
+}
+func (UnimplementedNftServiceServer) ConsumeAiAgentQuota(context.Context, *ConsumeAiAgentQuotaRequest) (*ConsumeAiAgentQuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConsumeAiAgentQuota not implemented")
+}
+func (UnimplementedNftServiceServer) mustEmbedUnimplementedNftServiceServer() {}
+
+// UnsafeNftServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NftServiceServer will
+// result in compilation errors.
+type UnsafeNftServiceServer interface {
+	mustEmbedUnimplementedNftServiceServer()
+}
+
+func RegisterNftServiceServer(s grpc.ServiceRegistrar, srv NftServiceServer) {
+	s.RegisterService(&NftService_ServiceDesc, srv)
+}
+
+func _NftService_GetNftStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNftStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NftServiceServer).GetNftStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NftService_GetNftStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NftServiceServer).GetNftStatus(ctx, req.(*GetNftStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NftService_GetEntitlements_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEntitlementsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NftServiceServer).GetEntitlements(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NftService_GetEntitlements_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NftServiceServer).GetEntitlements(ctx, req.(*GetEntitlementsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NftService_ConsumeAiAgentQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsumeAiAgentQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NftServiceServer).ConsumeAiAgentQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NftService_ConsumeAiAgentQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NftServiceServer).ConsumeAiAgentQuota(ctx, req.(*ConsumeAiAgentQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NftService_ServiceDesc is the grpc.ServiceDesc for NftService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NftService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aiw3.v1.NftService",
+	HandlerType: (*NftServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNftStatus",
+			Handler:    _NftService_GetNftStatus_Handler,
+		},
+		{
+			MethodName: "GetEntitlements",
+			Handler:    _NftService_GetEntitlements_Handler,
+		},
+		{
+			MethodName: "ConsumeAiAgentQuota",
+			Handler:    _NftService_ConsumeAiAgentQuota_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "aiw3/v1/nft.proto",
+}
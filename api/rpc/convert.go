@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/nfts"
+	"github.com/aiw3/nft-solana-api/rpc/aiw3v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ==========================================
+// DOMAIN TO PROTOBUF MAPPING
+// ==========================================
+
+// toNftStatus converts the NFT info of the HTTP API to its protobuf shape
+func toNftStatus(data nfts.GetUserNftInfoData) *aiw3v1.NftStatus {
+	status := &aiw3v1.NftStatus{
+		UserId:          data.UserBasicInfo.UserID,
+		WalletAddr:      data.UserBasicInfo.WalletAddr,
+		ActiveNftLevel:  int32(data.ActiveNftLevel),
+		UpgradeEligible: data.UpgradeEligible,
+		PendingUpgrade:  data.PendingUpgrade,
+	}
+	if data.NextNftLevel != nil {
+		next := int32(*data.NextNftLevel)
+		status.NextNftLevel = &next
+	}
+	for _, nft := range data.TieredNfts {
+		status.TieredNfts = append(status.TieredNfts, toTieredNft(nft))
+	}
+	for _, nft := range data.CompetitionNfts {
+		status.CompetitionNfts = append(status.CompetitionNfts, &aiw3v1.CompetitionNft{
+			Id:              nft.ID,
+			Name:            nft.Name,
+			MintAddress:     nft.OnChainInfo.MintAddress,
+			CompetitionId:   nft.CompetitionInfo.ID,
+			CompetitionName: nft.CompetitionInfo.Name,
+			Rank:            int32(nft.CompetitionInfo.Rank),
+			MintedAt:        timestamppb.New(nft.MintedAt),
+			Benefits:        toBenefits(nft.BenefitsStats.BenefitsActivation, nft.BenefitsStats.TradingFeeReduction),
+		})
+	}
+	return status
+}
+
+func toTieredNft(nft nfts.TieredNft) *aiw3v1.TieredNft {
+	entry := &aiw3v1.TieredNft{
+		Level:    int32(nft.Level),
+		Name:     nft.Name,
+		Status:   nft.Status,
+		MintedAt: toTimestamp(nft.MintedAt),
+		BurnedAt: toTimestamp(nft.BurnedAt),
+	}
+	if nft.ID != nil {
+		id := int64(*nft.ID)
+		entry.Id = &id
+	}
+	if nft.OnChainInfo != nil {
+		entry.MintAddress = nft.OnChainInfo.MintAddress
+	}
+	if nft.BenefitsStats != nil {
+		entry.Benefits = toBenefits(nft.BenefitsStats.BenefitsActivation, nft.BenefitsStats.TradingFeeReduction)
+	}
+	return entry
+}
+
+func toBenefits(activation nfts.BenefitsActivation, feeReduction int) *aiw3v1.Benefits {
+	return &aiw3v1.Benefits{
+		Activated:                    activation.Activated,
+		FeeReductionInEffect:         activation.FeeReductionInEffect,
+		TradingFeeReduction:          int32(feeReduction),
+		EffectiveTradingFeeReduction: int32(activation.EffectiveTradingFeeReduction),
+	}
+}
+
+// toUserEntitlements converts entitlements of the HTTP API to their protobuf shape
+func toUserEntitlements(entitlements nfts.UserEntitlements) *aiw3v1.UserEntitlements {
+	entry := &aiw3v1.UserEntitlements{
+		UserId:                 int64(entitlements.UserID),
+		WalletAddr:             entitlements.WalletAddr,
+		ActiveNftLevel:         int32(entitlements.ActiveNftLevel),
+		TradingFeeReduction:    int32(entitlements.TradingFeeReduction),
+		ExclusiveBackground:    entitlements.ExclusiveBackground,
+		StrategyRecommendation: entitlements.StrategyRecommendation,
+		StrategyPriority:       entitlements.StrategyPriority,
+		CommunityTopPin:        entitlements.CommunityTopPin,
+		ComputedAt:             parseTimestamp(entitlements.ComputedAt),
+	}
+	if source := entitlements.FeeReductionSource; source != nil {
+		entry.FeeReductionSource = &aiw3v1.EntitlementSource{NftType: source.NftType, UserNftId: int64(source.UserNftID)}
+	}
+	if ai := entitlements.AiAgent; ai != nil {
+		entry.AiAgent = &aiw3v1.AiAgentEntitlement{
+			WeeklyTotalAvailable: int32(ai.WeeklyTotalAvailable),
+			WeeklyUsed:           int32(ai.WeeklyUsed),
+			WeeklyRemaining:      int32(ai.WeeklyRemaining),
+			ResetsAt:             parseTimestamp(ai.ResetsAt),
+		}
+	}
+	return entry
+}
+
+// toAiAgentQuota converts a quota to its protobuf shape
+func toAiAgentQuota(quota *aiquota.Quota, schedule aiquota.Schedule) *aiw3v1.AiAgentQuota {
+	return &aiw3v1.AiAgentQuota{
+		NftLevel:             int32(quota.Level),
+		WeeklyTotalAvailable: int32(quota.WeeklyTotal),
+		WeeklyUsed:           int32(quota.Used),
+		WeeklyRemaining:      int32(quota.Remaining),
+		WeekStart:            timestamppb.New(quota.WeekStart),
+		ResetsAt:             timestamppb.New(quota.ResetsAt),
+		ResetPolicy:          schedule.String(),
+	}
+}
+
+// toTimestamp converts an optional time, nil when unset
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// parseTimestamp converts an RFC 3339 timestamp of the HTTP API, nil when it is empty or malformed
+func parseTimestamp(value string) *timestamppb.Timestamp {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return timestamppb.New(t)
+}
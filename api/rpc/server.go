@@ -0,0 +1,226 @@
+// Package rpc serves the gRPC mirror of the NFT status, entitlements and AI agent quota endpoints.
+// The service is defined in proto/aiw3/v1/nft.proto; run `go generate ./rpc` after changing it.
+package rpc
+
+//go:generate sh -c "cd .. && buf generate"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/idempotency"
+	"github.com/aiw3/nft-solana-api/nfts"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/rpc/aiw3v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// metadataAuthorization carries the same bearer token as the HTTP Authorization header
+	metadataAuthorization = "authorization"
+	// metadataIdempotencyKey carries the same key as the HTTP Idempotency-Key header
+	metadataIdempotencyKey = "idempotency-key"
+	// metadataReplayed is set to "true" on responses replayed for an idempotency key
+	metadataReplayed = "idempotent-replayed"
+	// errorDomain identifies this service in ErrorInfo details
+	errorDomain = "nft.aiw3.com"
+)
+
+// Server implements aiw3v1.NftServiceServer on the same domain functions as the HTTP handlers
+type Server struct {
+	aiw3v1.UnimplementedNftServiceServer
+	store    *repository.Store
+	quotas   *aiquota.Service
+	services *auth.ServiceCredentials
+}
+
+// NewServer creates the NFT service
+func NewServer(store *repository.Store, quotas *aiquota.Service, services *auth.ServiceCredentials) *Server {
+	return &Server{store: store, quotas: quotas, services: services}
+}
+
+// NewGRPCServer returns a gRPC server with the NFT service and server reflection registered. Serve it on
+// a TCP listener, or on a bufconn listener to call it in-process.
+func NewGRPCServer(store *repository.Store, quotas *aiquota.Service, services *auth.ServiceCredentials,
+	opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	aiw3v1.RegisterNftServiceServer(server, NewServer(store, quotas, services))
+	reflection.Register(server)
+	return server
+}
+
+// GetNftStatus returns the caller's NFTs and upgrade state, authenticated with the user's token
+func (s *Server) GetNftStatus(ctx context.Context, req *aiw3v1.GetNftStatusRequest) (*aiw3v1.GetNftStatusResponse, error) {
+	user, err := auth.ExtractUserFromAuthHeader(ctx, s.store.Users, authorization(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	data, err := nfts.BuildUserNftInfo(ctx, s.store, s.quotas, user)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return &aiw3v1.GetNftStatusResponse{Status: toNftStatus(data)}, nil
+}
+
+// GetEntitlements returns what users are entitled to right now, authenticated with a service token.
+// How long the answer may be cached is in max_age_seconds and in the cache-control header metadata.
+func (s *Server) GetEntitlements(ctx context.Context, req *aiw3v1.GetEntitlementsRequest) (*aiw3v1.GetEntitlementsResponse, error) {
+	if _, err := s.services.ExtractServiceFromAuthHeader(authorization(ctx)); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	lookups := len(req.GetUserIds()) + len(req.GetWallets())
+	if lookups == 0 {
+		return nil, status.Error(codes.InvalidArgument, "At least one user ID or wallet is required")
+	}
+	if lookups > nfts.MaxEntitlementLookups {
+		return nil, status.Errorf(codes.InvalidArgument, "At most %d users can be looked up at once", nfts.MaxEntitlementLookups)
+	}
+
+	userIDs := make([]int, len(req.GetUserIds()))
+	for i, id := range req.GetUserIds() {
+		userIDs[i] = int(id)
+	}
+	data, maxAge, err := nfts.LookupEntitlements(ctx, s.store, s.quotas, userIDs, req.GetWallets())
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	maxAgeSeconds := int32(maxAge / time.Second)
+	grpc.SetHeader(ctx, metadata.Pairs("cache-control", fmt.Sprintf("private, max-age=%d", maxAgeSeconds)))
+	resp := &aiw3v1.GetEntitlementsResponse{NotFound: data.NotFound, MaxAgeSeconds: maxAgeSeconds}
+	for _, entitlements := range data.Entitlements {
+		resp.Entitlements = append(resp.Entitlements, toUserEntitlements(entitlements))
+	}
+	return resp, nil
+}
+
+// ConsumeAiAgentQuota consumes AI agent uses of the caller, authenticated with the user's token. With an
+// idempotency-key metadata entry, a retry returns the first successful response instead of consuming again.
+func (s *Server) ConsumeAiAgentQuota(ctx context.Context, req *aiw3v1.ConsumeAiAgentQuotaRequest) (*aiw3v1.ConsumeAiAgentQuotaResponse, error) {
+	token := authorization(ctx)
+	user, err := auth.ExtractUserFromAuthHeader(ctx, s.store.Users, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	uses := int(req.GetUses())
+	if uses == 0 {
+		uses = 1
+	}
+	consume := func() (*aiw3v1.ConsumeAiAgentQuotaResponse, error) {
+		quota, consumeID, err := s.quotas.Consume(ctx, user.ID, uses)
+		if err != nil {
+			return nil, aiAgentQuotaError(err)
+		}
+		return &aiw3v1.ConsumeAiAgentQuotaResponse{Quota: toAiAgentQuota(quota, s.quotas.Schedule()),
+			ConsumeId: consumeID}, nil
+	}
+
+	key := firstMetadata(ctx, metadataIdempotencyKey)
+	if key == "" {
+		return consume()
+	}
+	request, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	body, replayed, err := idempotency.Do(ctx, s.store.Idempotency, idempotency.DefaultTTL, token, key,
+		append([]byte(aiw3v1.NftService_ConsumeAiAgentQuota_FullMethodName), request...),
+		func() ([]byte, error) {
+			resp, err := consume()
+			if err != nil {
+				return nil, err
+			}
+			return proto.Marshal(resp)
+		})
+	if err != nil {
+		return nil, idempotencyError(err)
+	}
+	resp := &aiw3v1.ConsumeAiAgentQuotaResponse{}
+	if err := proto.Unmarshal(body, resp); err != nil {
+		return nil, internalError(err)
+	}
+	if replayed {
+		grpc.SetHeader(ctx, metadata.Pairs(metadataReplayed, "true"))
+	}
+	return resp, nil
+}
+
+// ==========================================
+// METADATA AND ERROR HELPERS
+// ==========================================
+
+// authorization returns the bearer token metadata, "" when the call has none
+func authorization(ctx context.Context) string {
+	return firstMetadata(ctx, metadataAuthorization)
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// aiAgentQuotaCodes maps refused quota operations to gRPC codes, as nfts maps them to envelope codes
+var aiAgentQuotaCodes = map[aiquota.ErrorCode]codes.Code{
+	aiquota.ErrCodeInvalidUses:     codes.InvalidArgument,
+	aiquota.ErrCodeNotIncluded:     codes.PermissionDenied,
+	aiquota.ErrCodeExhausted:       codes.ResourceExhausted,
+	aiquota.ErrCodeConsumeNotFound: codes.NotFound,
+	aiquota.ErrCodeAlreadyRefunded: codes.FailedPrecondition,
+}
+
+// aiAgentQuotaError converts a refused quota operation to a status carrying the error code and the quota
+// left in ErrorInfo details; other errors are internal
+func aiAgentQuotaError(err error) error {
+	var quotaErr *aiquota.Error
+	if !errors.As(err, &quotaErr) {
+		return internalError(err)
+	}
+	info := &errdetails.ErrorInfo{Reason: string(quotaErr.Code), Domain: errorDomain}
+	if quotaErr.Quota != nil {
+		info.Metadata = map[string]string{
+			"weeklyTotalAvailable": strconv.Itoa(quotaErr.Quota.WeeklyTotal),
+			"weeklyRemaining":      strconv.Itoa(quotaErr.Quota.Remaining),
+			"resetsAt":             quotaErr.Quota.ResetsAt.UTC().Format(time.RFC3339),
+		}
+	}
+	st, detailErr := status.New(aiAgentQuotaCodes[quotaErr.Code], quotaErr.Message).WithDetails(info)
+	if detailErr != nil {
+		return status.Error(aiAgentQuotaCodes[quotaErr.Code], quotaErr.Message)
+	}
+	return st.Err()
+}
+
+// idempotencyError converts a refused idempotency key to a status; errors of the call itself pass through
+func idempotencyError(err error) error {
+	switch {
+	case errors.Is(err, idempotency.ErrKeyTooLong):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, idempotency.ErrKeyReused):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, idempotency.ErrInProgress), errors.Is(err, idempotency.ErrIncomplete):
+		return status.Error(codes.Aborted, err.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return internalError(err)
+}
+
+func internalError(err error) error {
+	return status.Error(codes.Internal, err.Error())
+}
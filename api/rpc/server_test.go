@@ -0,0 +1,169 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/aiw3/nft-solana-api/aiquota"
+	"github.com/aiw3/nft-solana-api/auth"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+	"github.com/aiw3/nft-solana-api/repository/seed"
+	"github.com/aiw3/nft-solana-api/rpc/aiw3v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Tokens of the seeded user and of a development service credential
+const (
+	seededUser   = 12345
+	userToken    = "Bearer test_token_123"
+	serviceToken = "Bearer service_token_trading"
+	adminToken   = "Bearer admin_token_123"
+)
+
+// dial serves the NFT service over an in-process bufconn listener on a seeded memory store
+func dial(t *testing.T) aiw3v1.NftServiceClient {
+	t.Helper()
+	store := memory.NewStore()
+	if err := seed.Load(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+	server := NewGRPCServer(store, aiquota.New(store, aiquota.DefaultSchedule()), auth.DevelopmentServiceCredentials())
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return aiw3v1.NewNftServiceClient(conn)
+}
+
+// withToken returns a context sending token as the authorization metadata, none when it is ""
+func withToken(token string) context.Context {
+	if token == "" {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), metadataAuthorization, token)
+}
+
+func TestMethodsAcceptOnlyTheirKindOfToken(t *testing.T) {
+	client := dial(t)
+	calls := map[string]func(ctx context.Context) error{
+		"GetNftStatus": func(ctx context.Context) error {
+			_, err := client.GetNftStatus(ctx, &aiw3v1.GetNftStatusRequest{})
+			return err
+		},
+		"ConsumeAiAgentQuota": func(ctx context.Context) error {
+			_, err := client.ConsumeAiAgentQuota(ctx, &aiw3v1.ConsumeAiAgentQuotaRequest{})
+			return err
+		},
+		"GetEntitlements": func(ctx context.Context) error {
+			_, err := client.GetEntitlements(ctx, &aiw3v1.GetEntitlementsRequest{UserIds: []int64{seededUser}})
+			return err
+		},
+	}
+	tests := []struct {
+		method string
+		token  string
+		want   codes.Code
+	}{
+		// Users call about themselves with their own token
+		{"GetNftStatus", userToken, codes.OK},
+		{"GetNftStatus", serviceToken, codes.Unauthenticated},
+		{"GetNftStatus", "", codes.Unauthenticated},
+		{"ConsumeAiAgentQuota", userToken, codes.OK},
+		{"ConsumeAiAgentQuota", serviceToken, codes.Unauthenticated},
+		{"ConsumeAiAgentQuota", "Bearer unknown", codes.Unauthenticated},
+		// Services look up any user with a service token
+		{"GetEntitlements", serviceToken, codes.OK},
+		{"GetEntitlements", userToken, codes.Unauthenticated},
+		{"GetEntitlements", adminToken, codes.Unauthenticated},
+		{"GetEntitlements", "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		name := tt.method + " with no token"
+		if tt.token != "" {
+			name = tt.method + " with " + tt.token
+		}
+		t.Run(name, func(t *testing.T) {
+			if got := status.Code(calls[tt.method](withToken(tt.token))); got != tt.want {
+				t.Errorf("code %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallsAnswerForTheAuthenticatedCaller(t *testing.T) {
+	client := dial(t)
+
+	nftStatus, err := client.GetNftStatus(withToken(userToken), &aiw3v1.GetNftStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nftStatus.GetStatus().GetUserId() != seededUser {
+		t.Errorf("status of user %d, want the token's user %d", nftStatus.GetStatus().GetUserId(), seededUser)
+	}
+
+	consumed, err := client.ConsumeAiAgentQuota(withToken(userToken), &aiw3v1.ConsumeAiAgentQuotaRequest{Uses: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consumed.GetConsumeId() == "" || consumed.GetQuota().GetWeeklyUsed() != 2 {
+		t.Errorf("consume %q with %d used, want 2 uses consumed", consumed.GetConsumeId(),
+			consumed.GetQuota().GetWeeklyUsed())
+	}
+
+	var header metadata.MD
+	entitlements, err := client.GetEntitlements(withToken(serviceToken),
+		&aiw3v1.GetEntitlementsRequest{UserIds: []int64{seededUser}, Wallets: []string{"UnknownWallet"}},
+		grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entitlements.GetEntitlements()) != 1 || entitlements.GetEntitlements()[0].GetUserId() != seededUser {
+		t.Errorf("entitlements %v, want user %d's", entitlements.GetEntitlements(), seededUser)
+	}
+	if len(entitlements.GetNotFound()) != 1 || entitlements.GetNotFound()[0] != "wallet:UnknownWallet" {
+		t.Errorf("not found %v, want the unknown wallet", entitlements.GetNotFound())
+	}
+	if got := header.Get("cache-control"); len(got) != 1 || got[0] == "" {
+		t.Errorf("cache-control %v, want the max age", got)
+	}
+}
+
+func TestConsumeReplaysIdempotencyKey(t *testing.T) {
+	client := dial(t)
+	ctx := metadata.AppendToOutgoingContext(withToken(userToken), metadataIdempotencyKey, "consume-1")
+
+	var firstHeader, retryHeader metadata.MD
+	first, err := client.ConsumeAiAgentQuota(ctx, &aiw3v1.ConsumeAiAgentQuotaRequest{Uses: 1}, grpc.Header(&firstHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry, err := client.ConsumeAiAgentQuota(ctx, &aiw3v1.ConsumeAiAgentQuotaRequest{Uses: 1}, grpc.Header(&retryHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retry.GetConsumeId() != first.GetConsumeId() || retry.GetQuota().GetWeeklyUsed() != 1 {
+		t.Errorf("retry consumed %q with %d used, want the first consume %q replayed", retry.GetConsumeId(),
+			retry.GetQuota().GetWeeklyUsed(), first.GetConsumeId())
+	}
+	if len(firstHeader.Get(metadataReplayed)) != 0 || len(retryHeader.Get(metadataReplayed)) != 1 {
+		t.Errorf("replayed headers %v and %v, want only the retry marked", firstHeader.Get(metadataReplayed),
+			retryHeader.Get(metadataReplayed))
+	}
+
+	if _, err := client.ConsumeAiAgentQuota(ctx, &aiw3v1.ConsumeAiAgentQuotaRequest{Uses: 3}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("key reused for another request: %v, want FailedPrecondition", err)
+	}
+}
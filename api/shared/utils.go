@@ -4,6 +4,8 @@ import (
 	"errors"
 	"strings"
 	"time"
+
+	"github.com/aiw3/nft-solana-api/tiers"
 )
 
 // ==========================================
@@ -25,6 +27,20 @@ func GetCurrentTimestamp() string {
 	return time.Now().UTC().Format(time.RFC3339)
 }
 
+// FormatTimestamp formats a stored time in the same ISO format as GetCurrentTimestamp
+func FormatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// FormatTimestampPtr formats an optional stored time, returning nil when it is unset
+func FormatTimestampPtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := FormatTimestamp(*t)
+	return &formatted
+}
+
 // ==========================================
 // AUTHENTICATION HELPER FUNCTIONS
 // ==========================================
@@ -67,9 +83,9 @@ func ValidatePaginationParams(limit, offset int) (int, int) {
 	return limit, offset
 }
 
-// ValidateNftLevel validates NFT level is within valid range (1-5)
+// ValidateNftLevel validates NFT level is defined in the tier catalog
 func ValidateNftLevel(level int) bool {
-	return level >= 1 && level <= 5
+	return tiers.Current().Valid(level)
 }
 
 // ==========================================
@@ -0,0 +1,15 @@
+//go:build !dev
+
+package main
+
+// Production builds leave the recorded stand-ins out; build with -tags dev to use them
+
+// exchangeStandIn starts the recorded exchange stand-in and returns its URL; false without the dev tag
+func exchangeStandIn() (string, bool) {
+	return "", false
+}
+
+// pythStandIn starts the recorded Pyth stand-in and returns its URL; false without the dev tag
+func pythStandIn() (string, bool) {
+	return "", false
+}
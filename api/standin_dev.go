@@ -0,0 +1,18 @@
+//go:build dev
+
+package main
+
+import (
+	"github.com/aiw3/nft-solana-api/exchanges/exchangetest"
+	"github.com/aiw3/nft-solana-api/prices/pythtest"
+)
+
+// exchangeStandIn starts the recorded exchange stand-in and returns its URL
+func exchangeStandIn() (string, bool) {
+	return exchangetest.NewServer().URL, true
+}
+
+// pythStandIn starts the recorded Pyth stand-in and returns its URL
+func pythStandIn() (string, bool) {
+	return pythtest.NewServer().URL, true
+}
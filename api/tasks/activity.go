@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// ACTIVITY PRODUCERS
+// ==========================================
+
+// ErrUnreportedMetric is returned for activity other services cannot report, e.g. trade_count, which volume
+// ingestion counts from the recorded trades
+var ErrUnreportedMetric = errors.New("activity metric cannot be reported")
+
+// reportedMetrics are the counters other services report as users act on the platform: the tutorials
+// completed in the app, invites and referrals from the community service, strategies from the strategy
+// service and group joins and followers from the social service. Counters are added to;
+// follower_count is set to the current number of followers.
+var reportedMetrics = map[string]bool{
+	TypeTutorialComplete:         true,
+	TypeStrategyTutorialComplete: true,
+	TypeGroupTutorialComplete:    true,
+	TypeInviteFriend:             true,
+	TypeReferralFirstTrade:       true,
+	TypeStrategyCreate:           true,
+	TypeGroupJoin:                true,
+	TypeFollowerCount:            true,
+}
+
+// ReportedMetrics lists the metrics RecordActivity accepts in alphabetical order
+func ReportedMetrics() []string {
+	metrics := make([]string, 0, len(reportedMetrics))
+	for metric := range reportedMetrics {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+// RecordActivity records count occurrences of a reported metric that happened at the given time and
+// returns the counter's new value. The first group join also starts MetricGroupJoinedAt at that time.
+func RecordActivity(ctx context.Context, store *repository.Store, userID int, metric string, count int,
+	at time.Time) (int, error) {
+	if !reportedMetrics[metric] {
+		return 0, fmt.Errorf("%w: %q", ErrUnreportedMetric, metric)
+	}
+	if metric == TypeFollowerCount {
+		return count, store.Activity.Set(ctx, userID, metric, count)
+	}
+
+	value, err := store.Activity.Add(ctx, userID, metric, count)
+	if err != nil || metric != TypeGroupJoin {
+		return value, err
+	}
+	joinedAt, err := store.Activity.Get(ctx, userID, MetricGroupJoinedAt)
+	if err != nil || joinedAt != 0 {
+		return value, err
+	}
+	return value, store.Activity.Set(ctx, userID, MetricGroupJoinedAt, int(at.Unix()))
+}
+
+// RecordTrade counts a trade recorded for the user's trading volume
+func RecordTrade(ctx context.Context, store *repository.Store, userID int) error {
+	_, err := store.Activity.Add(ctx, userID, TypeTradeCount, 1)
+	return err
+}
@@ -0,0 +1,104 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+)
+
+// report is an activity reported by another service
+type report struct {
+	metric string
+	count  int
+	at     time.Time
+}
+
+func TestRecordActivity(t *testing.T) {
+	ctx := context.Background()
+	joinedAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		reports []report
+		metric  string
+		want    int
+		wantErr error
+	}{
+		{
+			name:    "invites add up",
+			reports: []report{{TypeInviteFriend, 1, joinedAt}, {TypeInviteFriend, 2, joinedAt}},
+			metric:  TypeInviteFriend,
+			want:    3,
+		},
+		{
+			name:    "followers are set",
+			reports: []report{{TypeFollowerCount, 18, joinedAt}, {TypeFollowerCount, 7, joinedAt}},
+			metric:  TypeFollowerCount,
+			want:    7,
+		},
+		{
+			name:    "first group join starts membership",
+			reports: []report{{TypeGroupJoin, 1, joinedAt}, {TypeGroupJoin, 1, joinedAt.Add(24 * time.Hour)}},
+			metric:  MetricGroupJoinedAt,
+			want:    int(joinedAt.Unix()),
+		},
+		{
+			name:    "trades are counted by volume ingestion",
+			reports: []report{{TypeTradeCount, 5, joinedAt}},
+			metric:  TypeTradeCount,
+			wantErr: ErrUnreportedMetric,
+		},
+		{
+			name:    "derived metrics are not reported",
+			reports: []report{{MetricGroupJoinedAt, 1, joinedAt}},
+			metric:  MetricGroupJoinedAt,
+			wantErr: ErrUnreportedMetric,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			var err error
+			for _, report := range tt.reports {
+				if _, err = RecordActivity(ctx, store, 1, report.metric, report.count, report.at); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error %v, want %v", err, tt.wantErr)
+			}
+			if got, _ := store.Activity.Get(ctx, 1, tt.metric); got != tt.want {
+				t.Errorf("%s = %d, want %d", tt.metric, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCounterVerifierMeasuresRecordedActivity(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	user := &repository.User{ID: 1}
+	if _, err := RecordActivity(ctx, store, user.ID, TypeStrategyCreate, 1, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordTrade(ctx, store, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := DefaultRegistry()
+	for taskType, want := range map[string]int{TypeStrategyCreate: 1, TypeTradeCount: 1, TypeInviteFriend: 0} {
+		verifier, _ := registry.Lookup(taskType)
+		got, err := verifier.Measure(ctx, store, user, Evidence{})
+		if err != nil || got != want {
+			t.Errorf("%s measured %d, %v; want %d", taskType, got, err, want)
+		}
+	}
+	verifier, _ := registry.Lookup(TypeStrategyCreate)
+	var evidenceErr *EvidenceError
+	if _, err := verifier.Measure(ctx, store, user, Evidence{"count": float64(2)}); !errors.As(err, &evidenceErr) {
+		t.Errorf("claim above the recorded count: %v, want an evidence error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// TASK VERIFIER REGISTRY
+// ==========================================
+
+// Evidence is the task-specific data a client sends with a completion (CompleteTaskRequest.Data)
+type Evidence map[string]interface{}
+
+// Verifier measures one requirement type from server-side data. It returns the user's current value,
+// which is compared with the requirement's Value, and rejects evidence that contradicts the server.
+type Verifier interface {
+	Measure(ctx context.Context, store *repository.Store, user *repository.User, evidence Evidence) (int, error)
+}
+
+// VerifierFunc adapts a function to a Verifier
+type VerifierFunc func(ctx context.Context, store *repository.Store, user *repository.User, evidence Evidence) (int, error)
+
+func (f VerifierFunc) Measure(ctx context.Context, store *repository.Store, user *repository.User, evidence Evidence) (int, error) {
+	return f(ctx, store, user, evidence)
+}
+
+// ErrUnknownTaskType is returned for a requirement type without a registered verifier
+var ErrUnknownTaskType = errors.New("no verifier is registered for the task type")
+
+// EvidenceError rejects client evidence that is malformed or contradicts server-side data
+type EvidenceError struct {
+	Key     string
+	Message string
+}
+
+func (e *EvidenceError) Error() string {
+	return fmt.Sprintf("Evidence %q rejected: %s", e.Key, e.Message)
+}
+
+// Registry maps task types to their verifiers
+type Registry struct {
+	verifiers map[string]Verifier
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{verifiers: map[string]Verifier{}}
+}
+
+// Register adds the verifier of a task type; registering a type twice panics
+func (r *Registry) Register(taskType string, verifier Verifier) {
+	if _, ok := r.verifiers[taskType]; ok {
+		panic(fmt.Sprintf("tasks: verifier for %q registered twice", taskType))
+	}
+	r.verifiers[taskType] = verifier
+}
+
+// Lookup returns the verifier of a task type
+func (r *Registry) Lookup(taskType string) (Verifier, bool) {
+	verifier, ok := r.verifiers[taskType]
+	return verifier, ok
+}
+
+// Types returns the registered task types in alphabetical order
+func (r *Registry) Types() []string {
+	types := make([]string, 0, len(r.verifiers))
+	for taskType := range r.verifiers {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ==========================================
+// REQUIREMENT EVALUATION
+// ==========================================
+
+// RequirementProgress is the measurement of one requirement, in the BadgeRequirement type/value shape
+type RequirementProgress struct {
+	Type    string `json:"type" example:"invite_friend" description:"Requirement (task) type"`
+	Value   int    `json:"value" example:"2" description:"Required value"`
+	Current int    `json:"current" example:"1" description:"Value measured on the server"`
+	Met     bool   `json:"met" example:"false" description:"Whether the current value reaches the required value"`
+}
+
+// Evaluation is the combined result of a task's requirements
+type Evaluation struct {
+	Requirements []RequirementProgress
+	Progress     int  // 0-100, the average of the requirements' progress
+	Completed    bool // every requirement is met
+}
+
+// Evaluate measures every requirement; a task is completed when all of them are met. Requirements are data
+// (type and value), so a badge combining e.g. volume_reached and trade_count needs no new code.
+func (r *Registry) Evaluate(ctx context.Context, store *repository.Store, user *repository.User,
+	requirements []repository.Requirement, evidence Evidence) (Evaluation, error) {
+	if len(requirements) == 0 {
+		return Evaluation{}, errors.New("a task needs at least one requirement")
+	}
+
+	result := Evaluation{Requirements: make([]RequirementProgress, 0, len(requirements)), Completed: true}
+	total := 0
+	for _, requirement := range requirements {
+		verifier, ok := r.Lookup(requirement.Type)
+		if !ok {
+			return Evaluation{}, fmt.Errorf("%w: %s", ErrUnknownTaskType, requirement.Type)
+		}
+		current, err := verifier.Measure(ctx, store, user, evidence)
+		if err != nil {
+			return Evaluation{}, err
+		}
+
+		progress := RequirementProgress{
+			Type:    requirement.Type,
+			Value:   requirement.Value,
+			Current: current,
+			Met:     current >= requirement.Value,
+		}
+		result.Requirements = append(result.Requirements, progress)
+		result.Completed = result.Completed && progress.Met
+
+		switch {
+		case progress.Met:
+			total += 100
+		case current > 0:
+			total += current * 100 / requirement.Value
+		}
+	}
+	result.Progress = total / len(requirements)
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package tasks_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/prices"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+	"github.com/aiw3/nft-solana-api/tasks"
+	"github.com/aiw3/nft-solana-api/volume"
+)
+
+// TestIngestCountsRecordedTrades checks the trade_count counter is produced by volume ingestion, which
+// records trades, rather than reported by other services
+func TestIngestCountsRecordedTrades(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	user := &repository.User{Username: "trader", WalletAddr: "TraderWallet"}
+	if err := store.Users.Save(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	isPlatform := func(platform string) bool { return platform == "okx" }
+	service := volume.New(store, isPlatform, prices.Par(money.USDT))
+
+	executedAt := time.Now().Add(-time.Hour)
+	trades := []volume.Trade{
+		{Platform: "okx", TradeID: "okx-1", UserID: user.ID, Notional: money.USDT.Whole(100), ExecutedAt: executedAt},
+		{Platform: "okx", TradeID: "okx-2", UserID: user.ID, Notional: money.USDT.Whole(250), ExecutedAt: executedAt},
+		{Platform: "okx", TradeID: "okx-3", UserID: user.ID, Notional: money.USDT.Whole(-1), ExecutedAt: executedAt},
+	}
+
+	// Ingesting the same trades again records only duplicates, which are not counted twice
+	for i := 0; i < 2; i++ {
+		if _, err := service.Ingest(ctx, volume.SourceWebhook, trades); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count, err := store.Activity.Get(ctx, user.ID, tasks.TypeTradeCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("trade count %d, want the 2 recorded trades", count)
+	}
+}
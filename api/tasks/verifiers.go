@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// BUILT-IN VERIFIERS
+// ==========================================
+
+// Task types backed by an activity counter of the same name
+const (
+	TypeTutorialComplete         = "tutorial_complete"
+	TypeStrategyTutorialComplete = "strategy_tutorial_complete"
+	TypeGroupTutorialComplete    = "group_tutorial_complete"
+	TypeTradeCount               = "trade_count"
+	TypeInviteFriend             = "invite_friend"
+	TypeReferralFirstTrade       = "referral_first_trade"
+	TypeStrategyCreate           = "strategy_create"
+	TypeGroupJoin                = "group_join"
+	TypeFollowerCount            = "follower_count"
+)
+
+// Task types measured from other server-side data
+const (
+	TypeVolumeReached       = "volume_reached"        // user's trading volume in USDT
+	TypeProfileComplete     = "profile_complete"      // 1 once nickname, bio, email and photo are set
+	TypeGroupMembershipDays = "group_membership_days" // days since MetricGroupJoinedAt
+)
+
+// MetricGroupJoinedAt is the activity counter holding the Unix time the user joined a trading group
+const MetricGroupJoinedAt = "group_joined_at"
+
+// DefaultRegistry returns a registry with a verifier for every task type of the badge catalog
+func DefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for _, taskType := range []string{
+		TypeTutorialComplete,
+		TypeStrategyTutorialComplete,
+		TypeGroupTutorialComplete,
+		TypeTradeCount,
+		TypeInviteFriend,
+		TypeReferralFirstTrade,
+		TypeStrategyCreate,
+		TypeGroupJoin,
+		TypeFollowerCount,
+	} {
+		registry.Register(taskType, CounterVerifier(taskType, "count"))
+	}
+	registry.Register(TypeVolumeReached, VerifierFunc(verifyVolume))
+	registry.Register(TypeProfileComplete, VerifierFunc(verifyProfile))
+	registry.Register(TypeGroupMembershipDays, GroupMembershipVerifier(time.Now))
+	return registry
+}
+
+// CounterVerifier measures an activity counter. When the evidence carries evidenceKey, the claimed
+// count must not exceed the recorded one.
+func CounterVerifier(metric, evidenceKey string) Verifier {
+	return VerifierFunc(func(ctx context.Context, store *repository.Store, user *repository.User, evidence Evidence) (int, error) {
+		value, err := store.Activity.Get(ctx, user.ID, metric)
+		if err != nil {
+			return 0, err
+		}
+		if err := checkClaim(evidence, evidenceKey, value); err != nil {
+			return 0, err
+		}
+		return value, nil
+	})
+}
+
+// verifyVolume measures the user's trading volume in whole USDT, rounded down; a claimed "volume" must
+// not exceed it
+func verifyVolume(ctx context.Context, store *repository.Store, user *repository.User, evidence Evidence) (int, error) {
+	volume := int(user.TradingVolume.Whole())
+	if err := checkClaim(evidence, "volume", volume); err != nil {
+		return 0, err
+	}
+	return volume, nil
+}
+
+// verifyProfile returns 1 once the profile fields shown on the user's page are filled in
+func verifyProfile(ctx context.Context, store *repository.Store, user *repository.User, evidence Evidence) (int, error) {
+	if user.Nickname == "" || user.Bio == "" || user.Email == "" || user.ProfilePhotoURL == "" {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// GroupMembershipVerifier measures whole days since the user joined a trading group
+func GroupMembershipVerifier(now func() time.Time) Verifier {
+	return VerifierFunc(func(ctx context.Context, store *repository.Store, user *repository.User, evidence Evidence) (int, error) {
+		joinedAt, err := store.Activity.Get(ctx, user.ID, MetricGroupJoinedAt)
+		if err != nil || joinedAt == 0 {
+			return 0, err
+		}
+		days := int(now().Sub(time.Unix(int64(joinedAt), 0)) / (24 * time.Hour))
+		if days < 0 {
+			days = 0
+		}
+		if err := checkClaim(evidence, "days", days); err != nil {
+			return 0, err
+		}
+		return days, nil
+	})
+}
+
+// checkClaim rejects a numeric claim in the evidence that is higher than the server-side value
+func checkClaim(evidence Evidence, key string, actual int) error {
+	raw, ok := evidence[key]
+	if !ok {
+		return nil
+	}
+	claimed, ok := raw.(float64) // JSON numbers decode as float64
+	if !ok || claimed != math.Trunc(claimed) {
+		return &EvidenceError{Key: key, Message: "must be a whole number"}
+	}
+	if int(claimed) > actual {
+		return &EvidenceError{Key: key, Message: fmt.Sprintf("claims %d but %d is recorded", int(claimed), actual)}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package tiers
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"gopkg.in/yaml.v2"
+)
+
+// ==========================================
+// TIER CATALOG
+// ==========================================
+
+// Tier describes a single tiered NFT level and the benefits it grants
+type Tier struct {
+	Level                  int              `json:"level" yaml:"level"`
+	Name                   string           `json:"name" yaml:"name"`
+	ShortName              string           `json:"shortName" yaml:"shortName"` // used in on-chain names, e.g. AIW3-L3-Hunter-#1234
+	Rarity                 string           `json:"rarity" yaml:"rarity"`
+	TradingVolumeThreshold money.USDTAmount `json:"tradingVolumeThreshold" yaml:"tradingVolumeThreshold"`
+	RequiredBadges         int              `json:"requiredBadges" yaml:"requiredBadges"`
+	TradingFeeReduction    int              `json:"tradingFeeReduction" yaml:"tradingFeeReduction"` // percent
+	AiAgentWeeklyQuota     int              `json:"aiAgentWeeklyQuota" yaml:"aiAgentWeeklyQuota"`
+	ExclusiveBackground    bool             `json:"exclusiveBackground" yaml:"exclusiveBackground"`
+	StrategyRecommendation bool             `json:"strategyRecommendation" yaml:"strategyRecommendation"`
+	StrategyPriority       bool             `json:"strategyPriority" yaml:"strategyPriority"`
+	BadgeActivationHours   int              `json:"badgeActivationHours" yaml:"badgeActivationHours"` // how long an activated badge of this level counts, 0 = no expiry
+}
+
+// TierCatalog is the ordered set of tiered NFT levels
+type TierCatalog struct {
+	Tiers []Tier `json:"tiers" yaml:"tiers"`
+}
+
+//go:embed default.yaml
+var defaultCatalog []byte
+
+// Default returns the catalog shipped with the service (tiers/default.yaml)
+func Default() *TierCatalog {
+	catalog, err := Parse(defaultCatalog, ".yaml")
+	if err != nil {
+		panic(fmt.Sprintf("tiers: invalid embedded catalog: %v", err))
+	}
+	return catalog
+}
+
+// Load reads and validates a catalog file; the format is chosen by extension (.yaml, .yml or .json)
+func Load(path string) (*TierCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := Parse(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return catalog, nil
+}
+
+// Parse decodes and validates a catalog in the format given by ext (.yaml, .yml or .json)
+func Parse(data []byte, ext string) (*TierCatalog, error) {
+	var catalog TierCatalog
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		if err := yaml.UnmarshalStrict(data, &catalog); err != nil {
+			return nil, err
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&catalog); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tier catalog format %q", ext)
+	}
+
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// Validate checks that levels are consecutive from 1 and that requirements and benefits never decrease
+func (c *TierCatalog) Validate() error {
+	if len(c.Tiers) == 0 {
+		return errors.New("tier catalog must define at least one level")
+	}
+
+	names := map[string]bool{}
+	for i, tier := range c.Tiers {
+		switch {
+		case tier.Level != i+1:
+			return fmt.Errorf("tier %d: levels must be consecutive starting at 1, got level %d", i+1, tier.Level)
+		case strings.TrimSpace(tier.Name) == "":
+			return fmt.Errorf("level %d: name is required", tier.Level)
+		case names[tier.Name]:
+			return fmt.Errorf("level %d: duplicate name %q", tier.Level, tier.Name)
+		case tier.TradingVolumeThreshold.Sign() < 0:
+			return fmt.Errorf("level %d: trading volume threshold must not be negative", tier.Level)
+		case tier.RequiredBadges < 0:
+			return fmt.Errorf("level %d: required badges must not be negative", tier.Level)
+		case tier.TradingFeeReduction < 0 || tier.TradingFeeReduction > 100:
+			return fmt.Errorf("level %d: trading fee reduction must be between 0 and 100", tier.Level)
+		case tier.AiAgentWeeklyQuota < 0:
+			return fmt.Errorf("level %d: AI agent weekly quota must not be negative", tier.Level)
+		case tier.BadgeActivationHours < 0:
+			return fmt.Errorf("level %d: badge activation hours must not be negative", tier.Level)
+		}
+		names[tier.Name] = true
+
+		if i == 0 {
+			continue
+		}
+		prev := c.Tiers[i-1]
+		higher, err := tier.TradingVolumeThreshold.Cmp(prev.TradingVolumeThreshold.Amount)
+		if err != nil {
+			return fmt.Errorf("level %d: trading volume threshold: %w", tier.Level, err)
+		}
+		switch {
+		case higher <= 0:
+			return fmt.Errorf("level %d: trading volume threshold must be higher than level %d", tier.Level, prev.Level)
+		case tier.RequiredBadges < prev.RequiredBadges:
+			return fmt.Errorf("level %d: required badges must not be lower than level %d", tier.Level, prev.Level)
+		case tier.TradingFeeReduction < prev.TradingFeeReduction:
+			return fmt.Errorf("level %d: trading fee reduction must not be lower than level %d", tier.Level, prev.Level)
+		}
+	}
+	return nil
+}
+
+// Tier returns the definition of a level
+func (c *TierCatalog) Tier(level int) (Tier, bool) {
+	if !c.Valid(level) {
+		return Tier{}, false
+	}
+	return c.Tiers[level-1], true
+}
+
+// Valid reports whether level is defined in the catalog
+func (c *TierCatalog) Valid(level int) bool {
+	return level >= 1 && level <= len(c.Tiers)
+}
+
+// MaxLevel returns the highest level in the catalog
+func (c *TierCatalog) MaxLevel() int {
+	return len(c.Tiers)
+}
+
+// Next returns the level above the given one; level 0 (no NFT) yields level 1
+func (c *TierCatalog) Next(level int) (Tier, bool) {
+	return c.Tier(level + 1)
+}
+
+// ==========================================
+// ACTIVE CATALOG
+// ==========================================
+
+var current atomic.Pointer[TierCatalog]
+
+func init() {
+	current.Store(Default())
+}
+
+// Current returns the catalog in use by the service
+func Current() *TierCatalog {
+	return current.Load()
+}
+
+// SetCurrent replaces the catalog in use; call it at startup after Load
+func SetCurrent(catalog *TierCatalog) {
+	current.Store(catalog)
+}
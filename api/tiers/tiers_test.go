@@ -0,0 +1,128 @@
+package tiers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// catalogYAML builds a YAML catalog with one tier per line of "level name threshold badges fee"
+func catalogYAML(tiers ...string) string {
+	var b strings.Builder
+	b.WriteString("tiers:\n")
+	for _, tier := range tiers {
+		fields := strings.Fields(tier)
+		b.WriteString("  - level: " + fields[0] + "\n    name: " + fields[1] + "\n    tradingVolumeThreshold: " +
+			fields[2] + "\n    requiredBadges: " + fields[3] + "\n    tradingFeeReduction: " + fields[4] + "\n")
+	}
+	return b.String()
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	catalog := Default()
+	if catalog.MaxLevel() != 5 {
+		t.Fatalf("%d levels, want 5", catalog.MaxLevel())
+	}
+	wantThresholds := []int64{100000, 500000, 5000000, 10000000, 50000000}
+	for i, tier := range catalog.Tiers {
+		if tier.TradingVolumeThreshold.Amount != money.USDT.Whole(wantThresholds[i]) {
+			t.Errorf("level %d: threshold %s, want %d", tier.Level, tier.TradingVolumeThreshold, wantThresholds[i])
+		}
+		if tier.ShortName == "" {
+			t.Errorf("level %d: no short name for on-chain names", tier.Level)
+		}
+	}
+	if first, ok := catalog.Next(0); !ok || first.Level != 1 || first.Name != "Tech Chicken" || first.RequiredBadges != 0 {
+		t.Errorf("next after no NFT: %+v, want Tech Chicken without badges", first)
+	}
+	if second, _ := catalog.Tier(2); second.RequiredBadges != 2 || second.BadgeActivationHours != 720 {
+		t.Errorf("level 2: %d badges activated for %dh, want 2 for 720h", second.RequiredBadges,
+			second.BadgeActivationHours)
+	}
+	if _, ok := catalog.Next(5); ok {
+		t.Error("a level above the highest")
+	}
+	for _, level := range []int{0, 6, -1} {
+		if catalog.Valid(level) {
+			t.Errorf("level %d valid", level)
+		}
+	}
+	if Current().MaxLevel() != catalog.MaxLevel() {
+		t.Errorf("current catalog has %d levels, want the default's %d", Current().MaxLevel(), catalog.MaxLevel())
+	}
+}
+
+func TestParseRejectsInvalidCatalogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		ext     string
+		wantErr string // "" when the catalog is valid
+	}{
+		{"valid", catalogYAML("1 Chicken 100 0 10", "2 Ape 500 2 20"), ".yaml", ""},
+		{"equal badges and fee", catalogYAML("1 Chicken 100 2 10", "2 Ape 500 2 10"), ".yml", ""},
+		{"empty catalog", "tiers: []\n", ".yaml", "at least one level"},
+		{"no tiers", "{}\n", ".yaml", "at least one level"},
+		{"level skipped", catalogYAML("1 Chicken 100 0 10", "3 Ape 500 2 20"), ".yaml", "consecutive"},
+		{"not starting at 1", catalogYAML("2 Ape 500 2 20"), ".yaml", "consecutive"},
+		{"levels out of order", catalogYAML("2 Ape 500 2 20", "1 Chicken 100 0 10"), ".yaml", "consecutive"},
+		{"equal thresholds", catalogYAML("1 Chicken 100 0 10", "2 Ape 100 2 20"), ".yaml", "must be higher than level 1"},
+		{"lower threshold", catalogYAML("1 Chicken 500 0 10", "2 Ape 100 2 20"), ".yaml", "must be higher than level 1"},
+		{"fewer badges", catalogYAML("1 Chicken 100 2 10", "2 Ape 500 1 20"), ".yaml", "required badges"},
+		{"lower fee reduction", catalogYAML("1 Chicken 100 0 20", "2 Ape 500 2 10"), ".yaml", "fee reduction"},
+		{"fee reduction above 100", catalogYAML("1 Chicken 100 0 101"), ".yaml", "between 0 and 100"},
+		{"negative threshold", catalogYAML("1 Chicken -1 0 10"), ".yaml", "must not be negative"},
+		{"duplicate name", catalogYAML("1 Chicken 100 0 10", "2 Chicken 500 2 20"), ".yaml", "duplicate name"},
+		{"unknown field", "tiers:\n  - level: 1\n    name: Chicken\n    discount: 10\n", ".yaml", "discount"},
+		{"json", `{"tiers":[{"level":1,"name":"Chicken","tradingVolumeThreshold":100}]}`, ".JSON", ""},
+		{"json level skipped", `{"tiers":[{"level":2,"name":"Ape"}]}`, ".json", "consecutive"},
+		{"json unknown field", `{"tiers":[{"level":1,"name":"Chicken","discount":10}]}`, ".json", "discount"},
+		{"unsupported format", "level = 1", ".toml", "unsupported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, err := Parse([]byte(tt.data), tt.ext)
+			if tt.wantErr == "" {
+				if err != nil || catalog == nil {
+					t.Fatalf("Parse: %v, want the catalog", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse: %v, want an error about %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPicksFormatByExtension(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tiers.json")
+	if err := os.WriteFile(path, []byte(`{"tiers":[{"level":1,"name":"Chicken","tradingVolumeThreshold":"100"},
+		{"level":2,"name":"Ape","tradingVolumeThreshold":"500","requiredBadges":2,"badgeActivationHours":24}]}`),
+		0o600); err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tier, ok := catalog.Tier(2); !ok || tier.TradingVolumeThreshold.Amount != money.USDT.Whole(500) ||
+		tier.BadgeActivationHours != 24 {
+		t.Errorf("level 2: %+v, want 500 USDT and a 24h activation window", tier)
+	}
+
+	invalid := filepath.Join(dir, "tiers.yaml")
+	if err := os.WriteFile(invalid, []byte(catalogYAML("2 Ape 500 2 20")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(invalid); err == nil || !strings.HasPrefix(err.Error(), invalid) {
+		t.Errorf("Load: %v, want an error naming the file", err)
+	}
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Load of a missing file: %v, want not exist", err)
+	}
+}
@@ -0,0 +1,342 @@
+package volume
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+)
+
+// ==========================================
+// PLATFORM ADAPTERS
+// ==========================================
+
+// Adapter reads the trades of one platform from a source's payload. Adapters only decode: the service
+// validates the trades and records them, so an adapter can be checked against a local fixture file.
+type Adapter interface {
+	Platform() string
+	// Source names the adapter kind trades are recorded with, e.g. csv or webhook
+	Source() string
+	// Decode reads every trade of the payload. Rows it cannot read are returned with Invalid set;
+	// an error means the payload as a whole is unreadable.
+	Decode(r io.Reader) ([]Trade, error)
+}
+
+// CSVColumns names the CSV header columns an adapter reads; empty names use the defaults
+type CSVColumns struct {
+	TradeID    string // default trade_id
+	UserID     string // default user_id; a row needs a user ID or a wallet
+	WalletAddr string // default wallet
+	Notional   string // default notional, in the row's asset
+	ExecutedAt string // default executed_at, RFC 3339 or Unix milliseconds
+	Asset      string // default asset, e.g. SOL; optional, rows without one are in USDT
+}
+
+func (c CSVColumns) withDefaults() CSVColumns {
+	defaults := CSVColumns{TradeID: "trade_id", UserID: "user_id", WalletAddr: "wallet", Notional: "notional",
+		ExecutedAt: "executed_at", Asset: "asset"}
+	if c.TradeID == "" {
+		c.TradeID = defaults.TradeID
+	}
+	if c.UserID == "" {
+		c.UserID = defaults.UserID
+	}
+	if c.WalletAddr == "" {
+		c.WalletAddr = defaults.WalletAddr
+	}
+	if c.Notional == "" {
+		c.Notional = defaults.Notional
+	}
+	if c.ExecutedAt == "" {
+		c.ExecutedAt = defaults.ExecutedAt
+	}
+	if c.Asset == "" {
+		c.Asset = defaults.Asset
+	}
+	return c
+}
+
+// assetPattern matches the asset codes trades can settle in, such as SOL or USDC
+var assetPattern = regexp.MustCompile(`^[A-Z0-9]{1,16}$`)
+
+// parseAsset reads the asset a trade settled in; "" is USDT
+func parseAsset(code string) (money.Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return money.USDT, nil
+	}
+	if !assetPattern.MatchString(code) {
+		return "", fmt.Errorf("asset %q must be 1 to 16 letters or digits", code)
+	}
+	return money.Currency(code), nil
+}
+
+// CSVAdapter reads trade exports with a header row
+type CSVAdapter struct {
+	platform string
+	columns  CSVColumns
+}
+
+// NewCSVAdapter creates an adapter for the platform's CSV exports
+func NewCSVAdapter(platform string, columns CSVColumns) *CSVAdapter {
+	return &CSVAdapter{platform: platform, columns: columns.withDefaults()}
+}
+
+func (a *CSVAdapter) Platform() string { return a.platform }
+
+func (a *CSVAdapter) Source() string { return SourceCSV }
+
+func (a *CSVAdapter) Decode(r io.Reader) ([]Trade, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("csv: missing header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("csv: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	column := func(name string) int {
+		if i, ok := index[strings.ToLower(name)]; ok {
+			return i
+		}
+		return -1
+	}
+	tradeID, userID, wallet := column(a.columns.TradeID), column(a.columns.UserID), column(a.columns.WalletAddr)
+	notional, executedAt, asset := column(a.columns.Notional), column(a.columns.ExecutedAt), column(a.columns.Asset)
+	switch {
+	case tradeID < 0, notional < 0, executedAt < 0:
+		return nil, fmt.Errorf("csv: header needs %s, %s and %s columns", a.columns.TradeID, a.columns.Notional,
+			a.columns.ExecutedAt)
+	case userID < 0 && wallet < 0:
+		return nil, fmt.Errorf("csv: header needs a %s or %s column", a.columns.UserID, a.columns.WalletAddr)
+	}
+
+	trades := []Trade{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return trades, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv: %w", err)
+		}
+		field := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		trade := Trade{Platform: a.platform, TradeID: field(tradeID), WalletAddr: field(wallet)}
+		if value := field(userID); value != "" {
+			if trade.UserID, err = strconv.Atoi(value); err != nil {
+				trade.Invalid = fmt.Sprintf("%s %q is not a number", a.columns.UserID, value)
+			}
+		}
+		currency, err := parseAsset(field(asset))
+		if err != nil {
+			trade.Invalid = err.Error()
+		}
+		if trade.Notional, err = currency.Parse(field(notional)); err != nil {
+			trade.Invalid = fmt.Sprintf("%s %q is not a decimal", a.columns.Notional, field(notional))
+		}
+		if trade.ExecutedAt, err = parseExecutedAt(field(executedAt)); err != nil {
+			trade.Invalid = fmt.Sprintf("%s %q must be RFC 3339 or Unix milliseconds", a.columns.ExecutedAt,
+				field(executedAt))
+		}
+		trades = append(trades, trade)
+	}
+}
+
+func parseExecutedAt(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// WebhookTrade is one trade of a webhook payload
+type WebhookTrade struct {
+	TradeID    string       `json:"tradeId" required:"true" example:"okx-8837461234" description:"The platform's trade identifier"`
+	UserID     int          `json:"userId,omitempty" example:"12345" description:"User the trade belongs to; alternatively walletAddr"`
+	WalletAddr string       `json:"walletAddr,omitempty" example:"9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM" description:"Wallet of the user the trade belongs to"`
+	Notional   money.Amount `json:"notional" required:"true" example:"25000" description:"Trade value in the trade's asset, a number or a decimal string"`
+	Asset      string       `json:"asset,omitempty" example:"USDT" description:"Asset the trade settled in, e.g. SOL or USDC; USDT when omitted. Other assets are converted to USDT at their price at executedAt"`
+	ExecutedAt string       `json:"executedAt" required:"true" example:"2024-02-20T14:30:00Z" description:"When the trade executed, RFC 3339"`
+}
+
+// WebhookPayload is the body platforms post trades in
+type WebhookPayload struct {
+	Trades []WebhookTrade `json:"trades" required:"true" minItems:"1" description:"Executed trades"`
+}
+
+// Decode converts the payload's trades; trades with a malformed asset or executedAt are marked invalid
+func (p WebhookPayload) Decode(platform string) []Trade {
+	trades := make([]Trade, 0, len(p.Trades))
+	for _, t := range p.Trades {
+		trade := Trade{Platform: platform, TradeID: t.TradeID, UserID: t.UserID, WalletAddr: t.WalletAddr}
+		currency, err := parseAsset(t.Asset)
+		if err != nil {
+			trade.Invalid = err.Error()
+		}
+		// Notional is read as USDT; it is in the trade's asset, so a currency it is labeled with must be that asset
+		if label := t.Notional.Currency(); label != "" && label != money.USDT && label != currency {
+			trade.Invalid = fmt.Sprintf("notional is in %s but asset is %s", label, currency)
+		}
+		trade.Notional = currency.Units(t.Notional.Units())
+		executedAt, err := time.Parse(time.RFC3339, t.ExecutedAt)
+		if err != nil {
+			trade.Invalid = "executedAt must be an RFC 3339 timestamp"
+		}
+		trade.ExecutedAt = executedAt
+		trades = append(trades, trade)
+	}
+	return trades
+}
+
+// WebhookAdapter reads webhook payloads, as posted to the internal volume endpoint or saved to a file
+type WebhookAdapter struct {
+	platform string
+}
+
+// NewWebhookAdapter creates an adapter for the platform's webhook payloads
+func NewWebhookAdapter(platform string) *WebhookAdapter {
+	return &WebhookAdapter{platform: platform}
+}
+
+func (a *WebhookAdapter) Platform() string { return a.platform }
+
+func (a *WebhookAdapter) Source() string { return SourceWebhook }
+
+func (a *WebhookAdapter) Decode(r io.Reader) ([]Trade, error) {
+	var payload WebhookPayload
+	if err := json.NewDecoder(r).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("webhook payload: %w", err)
+	}
+	return payload.Decode(a.platform), nil
+}
+
+// ==========================================
+// FILE INBOX
+// ==========================================
+
+// Suffixes an inbox file is renamed with once it has been read
+const (
+	suffixImported = ".imported"
+	suffixFailed   = ".failed"
+)
+
+// Inbox imports trade files dropped in a directory. A file's platform is its name up to the first
+// "-", "_" or "." (okx-2024-02-20.csv is an OKX export); .csv files are read with the CSV adapter and
+// .json files as webhook payloads. Each file is renamed with .imported, or .failed when it could not be
+// read, so it is imported once.
+type Inbox struct {
+	dir     string
+	service *Service
+	columns CSVColumns
+}
+
+// NewInbox creates an inbox for dir; CSV files are read with columns
+func NewInbox(dir string, service *Service, columns CSVColumns) *Inbox {
+	return &Inbox{dir: dir, service: service, columns: columns}
+}
+
+// Scan imports every file waiting in the inbox and returns how many were imported
+func (b *Inbox) Scan(ctx context.Context) (int, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return 0, err
+	}
+	imported := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		adapter := b.adapter(name)
+		if adapter == nil {
+			continue
+		}
+		path := filepath.Join(b.dir, name)
+		result, err := b.importFile(ctx, adapter, path)
+		if err != nil {
+			log.Printf("volume inbox: %s: %v", name, err)
+			if err := os.Rename(path, path+suffixFailed); err != nil {
+				return imported, err
+			}
+			continue
+		}
+		log.Printf("volume inbox: %s: %d trades recorded, %d duplicates, %d rejected",
+			name, result.Recorded, result.Duplicates, result.Rejected)
+		if err := os.Rename(path, path+suffixImported); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// adapter picks the adapter for a file name, nil for files the inbox does not read
+func (b *Inbox) adapter(name string) Adapter {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != ".csv" && ext != ".json" {
+		return nil
+	}
+	platform := strings.ToLower(name[:strings.IndexAny(name, "-_.")])
+	if !b.service.IsPlatform(platform) {
+		return nil
+	}
+	switch ext {
+	case ".csv":
+		return NewCSVAdapter(platform, b.columns)
+	case ".json":
+		return NewWebhookAdapter(platform)
+	}
+	return nil
+}
+
+func (b *Inbox) importFile(ctx context.Context, adapter Adapter, path string) (Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+
+	trades, err := adapter.Decode(file)
+	if err != nil {
+		return Result{}, err
+	}
+	return b.service.Ingest(ctx, adapter.Source(), trades)
+}
+
+// Run scans the inbox every interval until ctx ends
+func (b *Inbox) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := b.Scan(ctx); err != nil {
+				log.Printf("volume inbox: scan: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package volume
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+func TestWebhookNotionalLabeledWithAnotherAsset(t *testing.T) {
+	trades, err := NewWebhookAdapter("okx").Decode(strings.NewReader(`{"trades":[
+		{"tradeId":"plain","userId":1,"notional":"2.5","asset":"SOL","executedAt":"2024-02-20T12:15:00Z"},
+		{"tradeId":"labeled","userId":1,"notional":"SOL:2.5","asset":"sol","executedAt":"2024-02-20T12:15:00Z"},
+		{"tradeId":"mislabeled","userId":1,"notional":"SOL:2.5","asset":"USDC","executedAt":"2024-02-20T12:15:00Z"},
+		{"tradeId":"labeled-without-asset","userId":1,"notional":"SOL:2.5","executedAt":"2024-02-20T12:15:00Z"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"plain": false, "labeled": false, "mislabeled": true, "labeled-without-asset": true}
+	for _, trade := range trades {
+		if (trade.Invalid != "") != want[trade.TradeID] {
+			t.Errorf("%s: invalid %q, want invalid %v", trade.TradeID, trade.Invalid, want[trade.TradeID])
+		}
+		if trade.Invalid == "" && trade.Notional != sol.MustParse("2.5") {
+			t.Errorf("%s: notional %s %s, want SOL:2.5", trade.TradeID, trade.Notional, trade.Notional.Currency())
+		}
+	}
+	if len(trades) != len(want) {
+		t.Errorf("%d trades, want %d", len(trades), len(want))
+	}
+}
+
+// decodeFixture reads a testdata file with the adapter
+func decodeFixture(t *testing.T, adapter Adapter, name string) []Trade {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	trades, err := adapter.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trades
+}
+
+func TestAdaptersDecodeFixtures(t *testing.T) {
+	at := func(value string) time.Time {
+		executedAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return executedAt
+	}
+	tests := []struct {
+		fixture string
+		adapter Adapter
+		want    []Trade // Invalid is only compared for being set
+	}{
+		{"okx-2024-02-20.csv", NewCSVAdapter("okx", CSVColumns{}), []Trade{
+			{Platform: "okx", TradeID: "okx-8837461234", UserID: 12345, Notional: money.USDT.Whole(25000),
+				ExecutedAt: at("2024-02-20T14:30:00Z")},
+			// Unix milliseconds
+			{Platform: "okx", TradeID: "okx-8837461235", WalletAddr: "8VzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtBWWN",
+				Notional: money.USDT.MustParse("12500.5"), ExecutedAt: at("2024-02-20T14:40:00Z")},
+			{Platform: "okx", TradeID: "okx-8837461236", UserID: 12345, ExecutedAt: at("2024-02-20T15:00:00Z"),
+				Invalid: `notional "not-a-number" is not a decimal`},
+			// The service finds the repeated row a duplicate; the adapter reads it like any other
+			{Platform: "okx", TradeID: "okx-8837461234", UserID: 12345, Notional: money.USDT.Whole(25000),
+				ExecutedAt: at("2024-02-20T14:30:00Z")},
+		}},
+		{"jupiter-2024-11-27.csv", NewCSVAdapter("jupiter", CSVColumns{}), []Trade{
+			{Platform: "jupiter", TradeID: "jup-4kX9mQ2v", UserID: 12345, Notional: sol.MustParse("12.5"),
+				ExecutedAt: at("2024-11-27T13:05:00Z")},
+			{Platform: "jupiter", TradeID: "jup-4kX9mQ2w", WalletAddr: "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+				Notional: money.USDC.Whole(1800), ExecutedAt: at("2024-11-27T14:00:00Z")},
+			// No asset is USDT
+			{Platform: "jupiter", TradeID: "jup-4kX9mQ2x", UserID: 12345, Notional: money.USDT.MustParse("950.25"),
+				ExecutedAt: at("2024-11-27T14:40:00Z")},
+			// Any asset code is read; the service rejects the ones it has no price for
+			{Platform: "jupiter", TradeID: "jup-4kX9mQ2y", UserID: 12345, Notional: money.Currency("WIF").Whole(3200),
+				ExecutedAt: at("2024-11-27T15:10:00Z")},
+		}},
+		{"bybit-webhook.json", NewWebhookAdapter("bybit"), []Trade{
+			{Platform: "bybit", TradeID: "bybit-55120001", UserID: 12345, Notional: money.USDT.Whole(40000),
+				ExecutedAt: at("2024-02-21T09:15:00Z")},
+			{Platform: "bybit", TradeID: "bybit-55120002", WalletAddr: "7YcCbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+				Notional: money.USDT.Whole(1500), ExecutedAt: at("2024-02-21T10:00:00Z")},
+			{Platform: "bybit", TradeID: "bybit-55120003", UserID: 424242, Notional: money.USDT.Whole(900),
+				ExecutedAt: at("2024-02-21T11:00:00Z")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			trades := decodeFixture(t, tt.adapter, tt.fixture)
+			if len(trades) != len(tt.want) {
+				t.Fatalf("%d trades, want %d", len(trades), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := trades[i]
+				if got.Invalid != want.Invalid {
+					t.Errorf("%s: invalid %q, want %q", want.TradeID, got.Invalid, want.Invalid)
+				}
+				if want.Invalid != "" {
+					continue
+				}
+				if got.Platform != want.Platform || got.TradeID != want.TradeID || got.UserID != want.UserID ||
+					got.WalletAddr != want.WalletAddr || !got.ExecutedAt.Equal(want.ExecutedAt) {
+					t.Errorf("trade %+v, want %+v", got, want)
+				}
+				if got.Notional != want.Notional {
+					t.Errorf("%s: notional %s %s, want %s %s", want.TradeID, got.Notional, got.Notional.Currency(),
+						want.Notional, want.Notional.Currency())
+				}
+			}
+		})
+	}
+}
+
+// TestIngestFixture ingests the OKX export twice: its malformed row is rejected and the rows already
+// recorded are duplicates
+func TestIngestFixture(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			service, _ := newService(t, store)
+			known := &repository.User{ID: 12345, Username: "known", WalletAddr: "8VzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtBWWN"}
+			if err := store.Users.Save(ctx, known); err != nil {
+				t.Fatal(err)
+			}
+			trades := decodeFixture(t, NewCSVAdapter("okx", CSVColumns{}), "okx-2024-02-20.csv")
+
+			result, err := service.Ingest(ctx, SourceCSV, trades)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Recorded != 2 || result.Duplicates != 1 || result.Rejected != 1 {
+				t.Errorf("first import: %d recorded, %d duplicates, %d rejected; want 2, 1 and 1", result.Recorded,
+					result.Duplicates, result.Rejected)
+			}
+			if result, err = service.Ingest(ctx, SourceCSV, trades); err != nil {
+				t.Fatal(err)
+			}
+			if result.Recorded != 0 || result.Duplicates != 3 || result.Rejected != 1 {
+				t.Errorf("second import: %d recorded, %d duplicates, %d rejected; want 0, 3 and 1", result.Recorded,
+					result.Duplicates, result.Rejected)
+			}
+
+			user, err := store.Users.GetByID(ctx, known.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.TradingVolume != money.USDT.MustParse("37500.5") {
+				t.Errorf("trading volume %s, want 37500.5", user.TradingVolume)
+			}
+		})
+	}
+}
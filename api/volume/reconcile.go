@@ -0,0 +1,224 @@
+package volume
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+)
+
+// ==========================================
+// RECONCILIATION
+// ==========================================
+
+const (
+	// reconcileLease keeps two instances from reconciling the same night
+	reconcileLease = "volume:reconcile"
+	// reconcileLeaseTTL outlasts a reconciliation, so instances waking a little later skip the night
+	reconcileLeaseTTL = time.Hour
+)
+
+// Report sums up a reconciliation
+type Report struct {
+	CheckedRollups int       `json:"checkedRollups" example:"1840" description:"Rollups and raw trade sums compared"`
+	CheckedUsers   int       `json:"checkedUsers" example:"7" description:"Users whose trading volume was compared with their raw trades"`
+	NewDrifts      int       `json:"newDrifts" example:"1" description:"Drifts found for the first time"`
+	OpenDrifts     int       `json:"openDrifts" example:"2" description:"Drifts still open after this reconciliation"`
+	Resolved       int       `json:"resolved" example:"0" description:"Open drifts no longer found"`
+	StartedAt      time.Time `json:"startedAt" example:"2024-02-21T03:00:00Z"`
+	FinishedAt     time.Time `json:"finishedAt" example:"2024-02-21T03:00:02Z"`
+}
+
+// driftKey identifies a drift across reconciliations
+type driftKey struct {
+	kind     string
+	userID   int
+	day      int64
+	platform string
+}
+
+func keyOf(drift repository.VolumeDrift) driftKey {
+	key := driftKey{kind: drift.Kind, userID: drift.UserID, platform: drift.Platform}
+	if !drift.Day.IsZero() {
+		key.day = drift.Day.UnixMilli()
+	}
+	return key
+}
+
+// Reconcile compares every rollup with the sum of its raw trades and every user's trading volume with
+// the total of their raw trades. Volumes are exact, so any difference is drift. A difference becomes an open drift; open drifts that are no longer
+// found are resolved. Nothing is corrected: an admin recomputes the user's volume after reviewing it.
+func (s *Service) Reconcile(ctx context.Context) (Report, error) {
+	report := Report{StartedAt: s.now().UTC()}
+	rollups, err := s.store.Volume.ListRollups(ctx, 0)
+	if err != nil {
+		return report, err
+	}
+	sums, err := s.store.Volume.SumTrades(ctx, 0)
+	if err != nil {
+		return report, err
+	}
+	users, err := s.store.Users.List(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	found := map[driftKey]repository.VolumeDrift{}
+	stored := map[driftKey]repository.VolumeRollup{}
+	for _, rollup := range rollups {
+		stored[driftKey{kind: repository.VolumeDriftRollup, userID: rollup.UserID, day: rollup.Day.UnixMilli(),
+			platform: rollup.Platform}] = rollup
+	}
+	raw := map[driftKey]repository.VolumeRollup{}
+	rawTotals := map[int]money.Amount{}
+	rawTrades := map[int]int{}
+	for _, sum := range sums {
+		raw[driftKey{kind: repository.VolumeDriftRollup, userID: sum.UserID, day: sum.Day.UnixMilli(),
+			platform: sum.Platform}] = sum
+		total, err := rawTotals[sum.UserID].Add(sum.Volume)
+		if err != nil {
+			return report, fmt.Errorf("user %d: %w", sum.UserID, err)
+		}
+		rawTotals[sum.UserID] = total
+		rawTrades[sum.UserID] += sum.Trades
+	}
+
+	for key := range union(stored, raw) {
+		report.CheckedRollups++
+		rollup, sum := stored[key], raw[key]
+		cmp, err := rollup.Volume.Cmp(sum.Volume)
+		if err != nil {
+			return report, fmt.Errorf("user %d: %w", key.userID, err)
+		}
+		if rollup.Trades == sum.Trades && cmp == 0 {
+			continue
+		}
+		found[key] = repository.VolumeDrift{
+			Kind:         repository.VolumeDriftRollup,
+			UserID:       key.userID,
+			Day:          time.UnixMilli(key.day).UTC(),
+			Platform:     key.platform,
+			StoredVolume: rollup.Volume,
+			RawVolume:    sum.Volume,
+			StoredTrades: rollup.Trades,
+			RawTrades:    sum.Trades,
+		}
+	}
+	for _, user := range users {
+		report.CheckedUsers++
+		cmp, err := user.TradingVolume.Cmp(rawTotals[user.ID])
+		if err != nil {
+			return report, fmt.Errorf("user %d: %w", user.ID, err)
+		}
+		if cmp == 0 {
+			continue
+		}
+		drift := repository.VolumeDrift{
+			Kind:         repository.VolumeDriftQualifying,
+			UserID:       user.ID,
+			StoredVolume: user.TradingVolume,
+			RawVolume:    rawTotals[user.ID],
+			RawTrades:    rawTrades[user.ID],
+		}
+		found[keyOf(drift)] = drift
+	}
+
+	open, err := s.store.Volume.ListDrifts(ctx, repository.VolumeDriftFilter{OpenOnly: true})
+	if err != nil {
+		return report, err
+	}
+	now := s.now().UTC()
+	for _, drift := range open {
+		if _, ok := found[keyOf(drift)]; ok {
+			delete(found, keyOf(drift))
+			report.OpenDrifts++
+			continue
+		}
+		if err := s.store.Volume.ResolveDrift(ctx, drift.ID, now); err != nil {
+			return report, err
+		}
+		report.Resolved++
+	}
+	for _, drift := range found {
+		drift.DetectedAt = now
+		if err := s.store.Volume.AddDrift(ctx, &drift); err != nil {
+			return report, err
+		}
+		report.NewDrifts++
+		report.OpenDrifts++
+	}
+	report.FinishedAt = s.now().UTC()
+	return report, nil
+}
+
+func union(a, b map[driftKey]repository.VolumeRollup) map[driftKey]bool {
+	keys := make(map[driftKey]bool, len(a))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	return keys
+}
+
+// ParseReconcileTime parses the "HH:MM" UTC time of day reconciliation runs at
+func ParseReconcileTime(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("reconcile time %q: must be HH:MM", value)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// RunNightly reconciles every day at the given offset after midnight UTC until ctx ends. Instances
+// sharing a store take a lease first, so only one of them reconciles each night.
+func (s *Service) RunNightly(ctx context.Context, at time.Duration) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		log.Printf("volume reconcile: lease owner: %v", err)
+		return
+	}
+	owner := hex.EncodeToString(buf)
+
+	for {
+		timer := time.NewTimer(time.Until(nextRun(s.now(), at)))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		acquired, err := s.store.Leases.Acquire(ctx, reconcileLease, owner, reconcileLeaseTTL)
+		if err != nil {
+			log.Printf("volume reconcile: take lease: %v", err)
+			continue
+		}
+		if !acquired {
+			continue
+		}
+		report, err := s.Reconcile(ctx)
+		if err != nil {
+			log.Printf("volume reconcile: %v", err)
+			continue
+		}
+		log.Printf("volume reconcile: %d rollups and %d users checked, %d new drifts, %d open, %d resolved",
+			report.CheckedRollups, report.CheckedUsers, report.NewDrifts, report.OpenDrifts, report.Resolved)
+	}
+}
+
+// nextRun returns the first time after now at the offset after a midnight UTC
+func nextRun(now time.Time, at time.Duration) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,142 @@
+package volume
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/sqlite"
+)
+
+// ingestTrades records two okx trades of the user on different days
+func ingestTrades(t *testing.T, service *Service, user *repository.User) {
+	t.Helper()
+	trades := []Trade{
+		{Platform: "okx", TradeID: "okx-1", UserID: user.ID, Notional: money.USDT.Whole(100), ExecutedAt: tradedAt},
+		{Platform: "okx", TradeID: "okx-2", UserID: user.ID, Notional: money.USDT.Whole(250),
+			ExecutedAt: tradedAt.Add(24 * time.Hour)},
+	}
+	if _, err := service.Ingest(context.Background(), SourceWebhook, trades); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconcileOpensAndResolvesQualifyingDrift(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			service, user := newService(t, store)
+			ingestTrades(t, service, user)
+
+			report, err := service.Reconcile(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.CheckedRollups != 2 || report.CheckedUsers != 1 || report.NewDrifts != 0 || report.OpenDrifts != 0 {
+				t.Errorf("report %+v, want 2 rollups and 1 user checked without drift", report)
+			}
+
+			// The stored trading volume no longer matches the raw trades
+			stored, err := store.Users.GetByID(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored.TradingVolume = money.USDT.Whole(1000)
+			if err := store.Users.Save(ctx, stored); err != nil {
+				t.Fatal(err)
+			}
+			for run, want := range []Report{{NewDrifts: 1, OpenDrifts: 1}, {OpenDrifts: 1}} {
+				report, err := service.Reconcile(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if report.NewDrifts != want.NewDrifts || report.OpenDrifts != want.OpenDrifts || report.Resolved != 0 {
+					t.Errorf("run %d: %d new, %d open and %d resolved drifts; want %d new and %d open", run+1,
+						report.NewDrifts, report.OpenDrifts, report.Resolved, want.NewDrifts, want.OpenDrifts)
+				}
+			}
+			drifts, err := store.Volume.ListDrifts(ctx, repository.VolumeDriftFilter{OpenOnly: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(drifts) != 1 || drifts[0].Kind != repository.VolumeDriftQualifying ||
+				drifts[0].StoredVolume != money.USDT.Whole(1000) || drifts[0].RawVolume != money.USDT.Whole(350) ||
+				drifts[0].RawTrades != 2 {
+				t.Fatalf("open drifts %+v, want the qualifying drift of 1000 stored against 350 over 2 trades", drifts)
+			}
+			// Reconciling corrects nothing
+			if stored, err = store.Users.GetByID(ctx, user.ID); err != nil || stored.TradingVolume != money.USDT.Whole(1000) {
+				t.Errorf("trading volume %s (%v), want it left at 1000", stored.TradingVolume, err)
+			}
+
+			// Once the volume matches the raw trades again, the next run resolves the drift
+			if _, err := store.Volume.RefreshQualifyingVolume(ctx, user.ID); err != nil {
+				t.Fatal(err)
+			}
+			if report, err = service.Reconcile(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if report.Resolved != 1 || report.OpenDrifts != 0 || report.NewDrifts != 0 {
+				t.Errorf("report after the recompute %+v, want the drift resolved", report)
+			}
+		})
+	}
+}
+
+// TestReconcileFindsRollupDrift changes a rollup behind the store's back, which only the SQL store allows
+func TestReconcileFindsRollupDrift(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "aiw3.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store := sqlite.NewStore(db)
+	service, user := newService(t, store)
+	ingestTrades(t, service, user)
+
+	day := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+	if _, err := db.ExecContext(ctx, `UPDATE volumerollup SET trades = trades + 1 WHERE user_id = ? AND day = ?`,
+		user.ID, day.UnixMilli()); err != nil {
+		t.Fatal(err)
+	}
+	report, err := service.Reconcile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CheckedRollups != 2 || report.NewDrifts != 1 {
+		t.Errorf("report %+v, want 2 rollups checked and 1 new drift", report)
+	}
+	drifts, err := store.Volume.ListDrifts(ctx, repository.VolumeDriftFilter{OpenOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 1 || drifts[0].Kind != repository.VolumeDriftRollup || !drifts[0].Day.Equal(day) ||
+		drifts[0].Platform != "okx" || drifts[0].StoredTrades != 2 || drifts[0].RawTrades != 1 {
+		t.Fatalf("open drifts %+v, want the okx rollup of %s counting 2 trades against 1", drifts, day.Format(time.DateOnly))
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	at := 3 * time.Hour
+	tests := []struct {
+		now, want string
+	}{
+		{"2024-02-20T01:00:00Z", "2024-02-20T03:00:00Z"},
+		{"2024-02-20T03:00:00Z", "2024-02-21T03:00:00Z"},
+		{"2024-02-20T23:30:00+02:00", "2024-02-21T03:00:00Z"},
+	}
+	for _, tt := range tests {
+		now, _ := time.Parse(time.RFC3339, tt.now)
+		want, _ := time.Parse(time.RFC3339, tt.want)
+		if got := nextRun(now, at); !got.Equal(want) {
+			t.Errorf("next run after %s: %s, want %s", tt.now, got, tt.want)
+		}
+	}
+	if _, err := ParseReconcileTime("25:00"); err == nil {
+		t.Error("reconcile time 25:00 accepted")
+	}
+}
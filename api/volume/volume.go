@@ -0,0 +1,259 @@
+// Package volume keeps users' trading volume. Trades reach it through platform adapters (CSV files
+// dropped in an inbox, webhook payloads); every raw trade is stored and added to its user's per-day,
+// per-platform rollup, and the rollup total is the qualifying volume tier decisions read from
+// User.TradingVolume. Trades settled in other assets than USDT are converted with the asset's price at
+// trade time, quoted by a prices.PriceOracle, and keep the price they were converted at. Reconciliation
+// compares the rollups with the raw trades and flags drift.
+package volume
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/prices"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/tasks"
+)
+
+const (
+	// maxTradeClockSkew tolerates platforms whose clocks run slightly ahead
+	maxTradeClockSkew = 5 * time.Minute
+	// maxTradeIDLength fits the volumetrade table
+	maxTradeIDLength = 128
+)
+
+// Sources trades are ingested through
+const (
+	SourceCSV            = "csv"
+	SourceWebhook        = "webhook"
+	SourceExchange       = "exchange"        // pulled from a bound exchange account
+	SourceOnChain        = "onchain"         // swaps found in confirmed Solana transactions
+	SourceOpeningBalance = "opening_balance" // volume accrued before trades were ingested
+)
+
+// Outcomes of an ingested trade
+const (
+	TradeRecorded  = "recorded"
+	TradeDuplicate = "duplicate"
+	TradeRejected  = "rejected"
+)
+
+// Trade is a trade as read by an adapter; the user is identified by ID or wallet. Notional is in the
+// asset the trade settled in, its currency; Normalize converts it to USDT.
+type Trade struct {
+	Platform   string
+	TradeID    string
+	UserID     int
+	WalletAddr string
+	Notional   money.Amount
+	ExecutedAt time.Time
+	// Invalid says why the adapter could not read the trade, "" when it could
+	Invalid string
+	// Settled is the notional in the asset the trade settled in and Price that asset's price at trade
+	// time; Normalize sets them when it converts the notional to USDT
+	Settled money.Amount
+	Price   *prices.Price
+}
+
+// Outcome is what became of one ingested trade
+type Outcome struct {
+	TradeID string `json:"tradeId" example:"okx-8837461234"`
+	Status  string `json:"status" example:"recorded" enum:"recorded,duplicate,rejected"`
+	Reason  string `json:"reason,omitempty" example:"User not found" description:"Why the trade was rejected"`
+	// Trades settled in another asset than USDT say what they were converted at
+	Asset       string        `json:"asset,omitempty" example:"SOL" description:"Asset the trade settled in, when not USDT"`
+	Notional    *money.Amount `json:"notional,omitempty" example:"370.3" description:"Notional converted to USDT"`
+	Price       *money.Amount `json:"price,omitempty" example:"148.12" description:"USDT price of the asset at trade time the notional was converted at"`
+	PriceSource string        `json:"priceSource,omitempty" example:"pyth" description:"Oracle that quoted the price"`
+}
+
+// Result sums up an ingestion
+type Result struct {
+	Recorded   int       `json:"recorded" example:"2"`
+	Duplicates int       `json:"duplicates" example:"1"`
+	Rejected   int       `json:"rejected" example:"0"`
+	Outcomes   []Outcome `json:"outcomes"`
+}
+
+// Service ingests trades and recomputes and reconciles trading volume
+type Service struct {
+	store      *repository.Store
+	isPlatform func(platform string) bool
+	oracle     prices.PriceOracle
+	now        func() time.Time
+}
+
+// New creates the volume service; isPlatform reports the trading platforms trades are accepted for and
+// oracle prices the trades settled in other assets than USDT
+func New(store *repository.Store, isPlatform func(platform string) bool, oracle prices.PriceOracle) *Service {
+	return &Service{store: store, isPlatform: isPlatform, oracle: oracle, now: time.Now}
+}
+
+// IsPlatform reports whether trades are accepted for the platform
+func (s *Service) IsPlatform(platform string) bool {
+	return s.isPlatform(platform)
+}
+
+// Ingest records trades that came through source. Trades already recorded for their platform are
+// duplicates, so a file or payload can be ingested again. The qualifying volume of every user with a
+// recorded trade is refreshed once all trades are in.
+func (s *Service) Ingest(ctx context.Context, source string, trades []Trade) (Result, error) {
+	result := Result{Outcomes: make([]Outcome, 0, len(trades))}
+	touched := map[int]bool{}
+	for _, trade := range trades {
+		outcome, userID, err := s.ingest(ctx, source, trade)
+		if err != nil {
+			return result, err
+		}
+		switch outcome.Status {
+		case TradeRecorded:
+			result.Recorded++
+			touched[userID] = true
+		case TradeDuplicate:
+			result.Duplicates++
+		default:
+			result.Rejected++
+		}
+		result.Outcomes = append(result.Outcomes, outcome)
+	}
+
+	for userID := range touched {
+		if _, err := s.store.Volume.RefreshQualifyingVolume(ctx, userID); err != nil {
+			return result, fmt.Errorf("refresh volume of user %d: %w", userID, err)
+		}
+	}
+	return result, nil
+}
+
+// Normalize converts the trade's notional to USDT with the price of the asset it settled in at
+// ExecutedAt, recording the asset's notional in Settled and the price in Price. Trades in USDT, and
+// trades already converted, are returned as they are. A trade there is no price for is returned with
+// Invalid set; an error means the oracle could not be asked, so the trade can be retried.
+func (s *Service) Normalize(ctx context.Context, trade Trade) (Trade, error) {
+	asset := trade.Notional.Currency()
+	if trade.Invalid != "" || asset == "" || asset == money.USDT || trade.ExecutedAt.IsZero() {
+		return trade, nil
+	}
+	price, err := s.oracle.PriceAt(ctx, asset, trade.ExecutedAt)
+	if errors.Is(err, prices.ErrNoPrice) {
+		trade.Invalid = fmt.Sprintf("No %s price at %s to convert the notional to USDT", asset,
+			trade.ExecutedAt.UTC().Format(time.RFC3339))
+		return trade, nil
+	}
+	if err != nil {
+		return trade, fmt.Errorf("price %s: %w", asset, err)
+	}
+	trade.Settled = trade.Notional
+	trade.Price = &price
+	trade.Notional = trade.Notional.Convert(price.Value, money.HalfEven)
+	return trade, nil
+}
+
+// ingest validates a trade and records it, returning the user it was recorded for
+func (s *Service) ingest(ctx context.Context, source string, trade Trade) (Outcome, int, error) {
+	outcome := Outcome{TradeID: trade.TradeID, Status: TradeRejected}
+	reject := func(reason string) (Outcome, int, error) {
+		outcome.Reason = reason
+		return outcome, 0, nil
+	}
+
+	trade, err := s.Normalize(ctx, trade)
+	if err != nil {
+		return outcome, 0, err
+	}
+	now := s.now()
+	switch {
+	case trade.Invalid != "":
+		return reject(trade.Invalid)
+	case !s.isPlatform(trade.Platform):
+		return reject(fmt.Sprintf("Unknown platform %q", trade.Platform))
+	case trade.TradeID == "" || len(trade.TradeID) > maxTradeIDLength:
+		return reject(fmt.Sprintf("tradeId must be 1 to %d characters", maxTradeIDLength))
+	case trade.Notional.Sign() <= 0:
+		return reject("notional must be positive")
+	case trade.ExecutedAt.IsZero():
+		return reject("executedAt is required")
+	case trade.ExecutedAt.After(now.Add(maxTradeClockSkew)):
+		return reject("executedAt is in the future")
+	}
+
+	var user *repository.User
+	switch {
+	case trade.UserID != 0:
+		user, err = s.store.Users.GetByID(ctx, trade.UserID)
+	case trade.WalletAddr != "":
+		user, err = s.store.Users.GetByWallet(ctx, trade.WalletAddr)
+	default:
+		return reject("userId or walletAddr is required")
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return reject("User not found")
+	}
+	if err != nil {
+		return outcome, 0, err
+	}
+	if trade.UserID != 0 && trade.WalletAddr != "" && trade.WalletAddr != user.WalletAddr {
+		return reject("walletAddr does not belong to userId")
+	}
+
+	record := &repository.VolumeTrade{
+		UserID:        user.ID,
+		Platform:      trade.Platform,
+		TradeID:       trade.TradeID,
+		Source:        source,
+		Notional:      trade.Notional,
+		ExecutedAt:    trade.ExecutedAt.UTC(),
+		IngestedAt:    now.UTC(),
+		Asset:         string(money.USDT),
+		AssetNotional: trade.Notional,
+		Price:         money.USDT.Whole(1),
+	}
+	if trade.Price != nil {
+		pricedAt := trade.Price.At.UTC()
+		record.Asset, record.AssetNotional = string(trade.Price.Asset), trade.Settled
+		record.Price, record.PriceSource, record.PricedAt = trade.Price.Value, trade.Price.Source, &pricedAt
+	}
+	err = s.store.Volume.RecordTrade(ctx, record)
+	if errors.Is(err, repository.ErrConflict) {
+		outcome.Status = TradeDuplicate
+		return outcome, 0, nil
+	}
+	if err != nil {
+		return outcome, 0, err
+	}
+	if source != SourceOpeningBalance {
+		if err := tasks.RecordTrade(ctx, s.store, user.ID); err != nil {
+			return outcome, 0, err
+		}
+	}
+	outcome.Status = TradeRecorded
+	if trade.Price != nil {
+		outcome.Asset, outcome.Notional = record.Asset, &record.Notional
+		outcome.Price, outcome.PriceSource = &record.Price, record.PriceSource
+	}
+	return outcome, user.ID, nil
+}
+
+// Recompute rebuilds the user's rollups from their raw trades and sets their qualifying volume from them.
+// Open drifts of the user are resolved, since the stored volume now matches the raw trades.
+// It returns the recomputed volume and how many drifts were resolved.
+func (s *Service) Recompute(ctx context.Context, userID int) (money.Amount, int, error) {
+	volume, err := s.store.Volume.Rebuild(ctx, userID)
+	if err != nil {
+		return money.Amount{}, 0, err
+	}
+	drifts, err := s.store.Volume.ListDrifts(ctx, repository.VolumeDriftFilter{UserID: userID, OpenOnly: true})
+	if err != nil {
+		return volume, 0, err
+	}
+	now := s.now().UTC()
+	for _, drift := range drifts {
+		if err := s.store.Volume.ResolveDrift(ctx, drift.ID, now); err != nil {
+			return volume, 0, err
+		}
+	}
+	return volume, len(drifts), nil
+}
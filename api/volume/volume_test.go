@@ -0,0 +1,140 @@
+package volume
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aiw3/nft-solana-api/money"
+	"github.com/aiw3/nft-solana-api/prices"
+	"github.com/aiw3/nft-solana-api/repository"
+	"github.com/aiw3/nft-solana-api/repository/memory"
+	"github.com/aiw3/nft-solana-api/repository/sqlite"
+)
+
+const (
+	sol  money.Currency = "SOL"
+	bonk money.Currency = "BONK"
+	jup  money.Currency = "JUP"
+)
+
+// recordedPrices quotes SOL and BONK from 2024-02-20T12:00:00Z
+const recordedPrices = `asset,time,price
+SOL,2024-02-20T12:00:00Z,148.12
+BONK,2024-02-20T12:00:00Z,0.5
+`
+
+var tradedAt = time.Date(2024, 2, 20, 12, 15, 0, 0, time.UTC)
+
+func newService(t *testing.T, store *repository.Store) (*Service, *repository.User) {
+	t.Helper()
+	user := &repository.User{Username: "trader", WalletAddr: "TraderWallet"}
+	if err := store.Users.Save(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	oracle, err := prices.ParseCSV(strings.NewReader(recordedPrices))
+	if err != nil {
+		t.Fatal(err)
+	}
+	isPlatform := func(platform string) bool { return platform == "okx" }
+	return New(store, isPlatform, prices.Chain(prices.Par(money.USDT), oracle)), user
+}
+
+func testStores(t *testing.T) map[string]*repository.Store {
+	t.Helper()
+	db, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "aiw3.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return map[string]*repository.Store{"memory": memory.NewStore(), "sqlite": sqlite.NewStore(db)}
+}
+
+func TestNormalize(t *testing.T) {
+	service, _ := newService(t, memory.NewStore())
+	tests := []struct {
+		name     string
+		notional money.Amount
+		at       time.Time
+		want     money.Amount
+		invalid  bool
+	}{
+		{"SOL at its price", sol.MustParse("2.5"), tradedAt, money.USDT.MustParse("370.3"), false},
+		{"half a unit rounds down to even", bonk.Units(1), tradedAt, money.USDT.Units(0), false},
+		{"one and a half units round up to even", bonk.Units(3), tradedAt, money.USDT.Units(2), false},
+		{"two and a half units round down to even", bonk.Units(5), tradedAt, money.USDT.Units(2), false},
+		{"USDT as it is", money.USDT.MustParse("99.5"), tradedAt, money.USDT.MustParse("99.5"), false},
+		{"no price that long after", sol.Whole(1), tradedAt.Add(2 * time.Hour), sol.Whole(1), true},
+		{"no price for the asset", jup.Whole(1), tradedAt, jup.Whole(1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trade, err := service.Normalize(context.Background(), Trade{Notional: tt.notional, ExecutedAt: tt.at})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trade.Notional != tt.want {
+				t.Errorf("notional %s, want %s", trade.Notional, tt.want)
+			}
+			if (trade.Invalid != "") != tt.invalid {
+				t.Errorf("invalid %q, want invalid %v", trade.Invalid, tt.invalid)
+			}
+			converted := tt.notional.Currency() != money.USDT && !tt.invalid
+			if converted && (trade.Settled != tt.notional || trade.Price == nil) {
+				t.Errorf("settled %s with price %+v, want %s and its price recorded", trade.Settled, trade.Price, tt.notional)
+			}
+		})
+	}
+}
+
+func TestListTradesReadsBackPrices(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			service, user := newService(t, store)
+			trades := []Trade{
+				{Platform: "okx", TradeID: "okx-usdt", UserID: user.ID, Notional: money.USDT.Whole(100), ExecutedAt: tradedAt},
+				{Platform: "okx", TradeID: "okx-sol", UserID: user.ID, Notional: sol.MustParse("2.5"),
+					ExecutedAt: tradedAt.Add(time.Minute)},
+			}
+			if _, err := service.Ingest(ctx, SourceWebhook, trades); err != nil {
+				t.Fatal(err)
+			}
+
+			count, err := store.Volume.CountTrades(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listed, err := store.Volume.ListTrades(ctx, user.ID, 1, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 2 || len(listed) != 1 {
+				t.Fatalf("%d trades counted, %d listed; want 2 counted and the latest listed", count, len(listed))
+			}
+			trade := listed[0]
+			if trade.TradeID != "okx-sol" || trade.Asset != string(sol) || trade.Notional != money.USDT.MustParse("370.3") {
+				t.Errorf("trade %s of %s %s, want okx-sol of 370.3 USDT settled in SOL", trade.TradeID, trade.Notional, trade.Asset)
+			}
+			if trade.AssetNotional != sol.MustParse("2.5") {
+				t.Errorf("asset notional %s, want SOL:2.5", trade.AssetNotional)
+			}
+			wantPricedAt := time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)
+			if trade.Price != money.USDT.MustParse("148.12") || trade.PriceSource != prices.SourceCSV ||
+				trade.PricedAt == nil || !trade.PricedAt.Equal(wantPricedAt) {
+				t.Errorf("price %s from %q at %v, want 148.12 from csv at %s", trade.Price, trade.PriceSource, trade.PricedAt, wantPricedAt)
+			}
+
+			listed, err = store.Volume.ListTrades(ctx, user.ID, 10, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(listed) != 1 || listed[0].TradeID != "okx-usdt" || listed[0].Asset != string(money.USDT) ||
+				listed[0].AssetNotional != money.USDT.Whole(100) || listed[0].PricedAt != nil {
+				t.Errorf("second page %+v, want the USDT trade without a price time", listed)
+			}
+		})
+	}
+}